@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdAnnotateIndex creates a new cobra.Command for the annotate-index subcommand.
+func NewCmdAnnotateIndex(options *[]crane.Option) *cobra.Command {
+	var newTag, digest string
+	var fromConfig bool
+	var p v1.Platform
+
+	cmd := &cobra.Command{
+		Use:   "annotate-index INDEX",
+		Short: "Fix the platform of a child of a remote index.",
+		Long: `Fix the platform of a child of a remote index.
+
+Sets the platform fields given by flags on the descriptor of the child with the given digest, leaving
+its other fields, and the other children, as they are. With --from-config, the platform is first
+read from the child image's config file. The fixed index is pushed to INDEX, or to --tag.`,
+		Example: `  # Add the missing variant of an arm64 image
+  crane annotate-index example.com/app:v1 --digest sha256:... --variant v8
+
+  # Take the platform from the image's config, pushing the fixed index to a new tag
+  crane annotate-index example.com/app:v1 --digest sha256:... --from-config -t example.com/app:v1-fixed`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o := crane.GetOptions(*options...)
+			baseRef := args[0]
+
+			h, err := v1.NewHash(digest)
+			if err != nil {
+				return fmt.Errorf("parsing --digest: %w", err)
+			}
+			ref, err := name.ParseReference(baseRef, o.Name...)
+			if err != nil {
+				return err
+			}
+			desc, err := remote.Get(ref, o.Remote...)
+			if err != nil {
+				return fmt.Errorf("pulling %s: %w", baseRef, err)
+			}
+			if !desc.MediaType.IsIndex() {
+				return fmt.Errorf("expected %s to be an index, got %q", baseRef, desc.MediaType)
+			}
+			base, err := desc.ImageIndex()
+			if err != nil {
+				return err
+			}
+			im, err := base.IndexManifest()
+			if err != nil {
+				return err
+			}
+
+			var child *v1.Descriptor
+			for i := range im.Manifests {
+				if im.Manifests[i].Digest == h {
+					child = &im.Manifests[i]
+					break
+				}
+			}
+			if child == nil {
+				return fmt.Errorf("%s has no child %s", baseRef, h)
+			}
+
+			platform := child.Platform.DeepCopy()
+			if fromConfig {
+				if !child.MediaType.IsImage() {
+					return fmt.Errorf("--from-config: child %s is a %q, not an image", h, child.MediaType)
+				}
+				img, err := base.Image(h)
+				if err != nil {
+					return err
+				}
+				cf, err := img.ConfigFile()
+				if err != nil {
+					return err
+				}
+				platform = cf.Platform()
+			}
+			if platform == nil {
+				platform = &v1.Platform{}
+			}
+			flags := cmd.Flags()
+			if flags.Changed("os") {
+				platform.OS = p.OS
+			}
+			if flags.Changed("arch") {
+				platform.Architecture = p.Architecture
+			}
+			if flags.Changed("variant") {
+				platform.Variant = p.Variant
+			}
+			if flags.Changed("os-version") {
+				platform.OSVersion = p.OSVersion
+			}
+			if flags.Changed("os-features") {
+				platform.OSFeatures = p.OSFeatures
+			}
+			if platform.OS == "" || platform.Architecture == "" {
+				return fmt.Errorf("the platform of %s needs an --os and --arch, got %q", h, platform)
+			}
+
+			idx := mutate.ManifestPlatform(base, match.Digests(h), platform)
+			idxDigest, err := idx.Digest()
+			if err != nil {
+				return err
+			}
+
+			if newTag != "" {
+				ref, err = name.ParseReference(newTag, o.Name...)
+				if err != nil {
+					return fmt.Errorf("parsing reference %s: %w", newTag, err)
+				}
+			} else if _, ok := ref.(name.Digest); ok {
+				ref = ref.Context().Digest(idxDigest.String())
+			}
+
+			if err := remote.WriteIndex(ref, idx, o.Remote...); err != nil {
+				return fmt.Errorf("pushing index %s: %w", ref, err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), ref.Context().Digest(idxDigest.String()))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&newTag, "tag", "t", "", "(Optional) Tag to push the fixed index to, instead of INDEX")
+	cmd.Flags().StringVar(&digest, "digest", "", "Digest of the child whose platform to fix")
+	cmd.Flags().BoolVar(&fromConfig, "from-config", false, "(Optional) Start from the platform in the child image's config file, rather than its descriptor")
+	cmd.Flags().StringVar(&p.OS, "os", "", "(Optional) Operating system to set, e.g. linux")
+	cmd.Flags().StringVar(&p.Architecture, "arch", "", "(Optional) Architecture to set, e.g. arm64")
+	cmd.Flags().StringVar(&p.Variant, "variant", "", "(Optional) Variant to set, e.g. v8, or empty to remove it")
+	cmd.Flags().StringVar(&p.OSVersion, "os-version", "", "(Optional) OS version to set, e.g. 10.0.17763.1234")
+	cmd.Flags().StringSliceVar(&p.OSFeatures, "os-features", nil, "(Optional) OS features to set, e.g. win32k")
+	cmd.MarkFlagRequired("digest")
+	return cmd
+}
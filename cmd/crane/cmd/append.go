@@ -24,7 +24,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/types"
-	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 )
 
@@ -57,7 +57,7 @@ container image.`,
 					base = mutate.ConfigMediaType(base, types.OCIConfigJSON)
 				}
 			} else {
-				base, err = crane.Pull(baseRef, *options...)
+				base, err = crane.Pull(source(cmd, baseRef), *options...)
 				if err != nil {
 					return fmt.Errorf("pulling %s: %w", baseRef, err)
 				}
@@ -79,10 +79,10 @@ container image.`,
 					return err
 				}
 				anns := map[string]string{
-					specsv1.AnnotationBaseImageDigest: baseDigest.String(),
+					imagespec.AnnotationBaseImageDigest: baseDigest.String(),
 				}
 				if _, ok := ref.(name.Tag); ok {
-					anns[specsv1.AnnotationBaseImageName] = ref.Name()
+					anns[imagespec.AnnotationBaseImageName] = ref.Name()
 				}
 				img = mutate.Annotations(img, anns).(v1.Image)
 			}
@@ -0,0 +1,158 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdArtifact creates a new cobra.Command for the artifact subcommand.
+func NewCmdArtifact(options *[]crane.Option) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "artifact",
+		Short: "Push or pull generic OCI artifacts.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Usage()
+		},
+	}
+	cmd.AddCommand(NewCmdArtifactPush(options), NewCmdArtifactPull(options))
+	return cmd
+}
+
+// NewCmdArtifactPush creates a new cobra.Command for the artifact push subcommand.
+func NewCmdArtifactPush(options *[]crane.Option) *cobra.Command {
+	var artifactType, config, mediaType string
+	var annotations, titles map[string]string
+
+	cmd := &cobra.Command{
+		Use:   "push REFERENCE FILE[:MEDIATYPE]...",
+		Short: "Push files as the layers of an OCI artifact.",
+		Long: `Push files as the layers of an OCI artifact.
+
+Each file becomes a layer annotated with its title (by default, its base name),
+which "crane artifact pull" uses to name the file. Unless --config is given, the
+artifact has an empty config and --artifact-type is required.`,
+		Example: `  # Push some files with the default media type
+  crane artifact push example.com/files:v1 a.json b.json --artifact-type application/vnd.example+json
+
+  # Push a WASM module, titled "module.wasm"
+  crane artifact push example.com/module:v1 build/out.wasm:application/wasm \
+    --artifact-type application/vnd.example.wasm --title build/out.wasm=module.wasm
+
+  # Push a Helm chart with its config
+  crane artifact push example.com/chart:v1 chart.tgz:application/vnd.cncf.helm.chart.content.v1.tar+gzip \
+    --config config.json:application/vnd.cncf.helm.config.v1+json`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o := crane.GetOptions(*options...)
+			ref, err := name.ParseReference(args[0], o.Name...)
+			if err != nil {
+				return err
+			}
+
+			a := &remote.OCIArtifact{
+				ArtifactType: types.MediaType(artifactType),
+				Annotations:  annotations,
+			}
+			if config != "" {
+				f := parseArtifactFile(config, "")
+				if f.MediaType == "" {
+					return fmt.Errorf("--config must be FILE:MEDIATYPE, got %q", config)
+				}
+				b, err := os.ReadFile(f.Path)
+				if err != nil {
+					return err
+				}
+				a.Config = static.NewLayer(b, f.MediaType)
+			}
+			for _, arg := range args[1:] {
+				f := parseArtifactFile(arg, types.MediaType(mediaType))
+				f.Title = titles[f.Path]
+				l, err := crane.ArtifactLayer(f)
+				if err != nil {
+					return err
+				}
+				a.Layers = append(a.Layers, l)
+			}
+
+			if err := remote.WriteArtifact(ref, a, o.Remote...); err != nil {
+				return fmt.Errorf("pushing %s: %w", ref, err)
+			}
+			d, err := a.Digest()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), ref.Context().Digest(d.String()))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&artifactType, "artifact-type", "", "The artifactType of the manifest; required without --config")
+	cmd.Flags().StringVar(&config, "config", "", "(Optional) FILE:MEDIATYPE to use as the config blob")
+	cmd.Flags().StringVar(&mediaType, "media-type", string(crane.DefaultArtifactFileMediaType), "Media type of files that don't specify one")
+	cmd.Flags().StringToStringVar(&titles, "title", nil, "Titles to use instead of the base name of files, as FILE=TITLE")
+	cmd.Flags().StringToStringVarP(&annotations, "annotation", "a", nil, "Annotations to set on the manifest")
+
+	return cmd
+}
+
+// NewCmdArtifactPull creates a new cobra.Command for the artifact pull subcommand.
+func NewCmdArtifactPull(options *[]crane.Option) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pull REFERENCE DIRECTORY [TITLE...]",
+		Short: "Pull the files of an OCI artifact into a directory.",
+		Long: `Pull the files of an OCI artifact into a directory.
+
+Each layer is written to a file named after its title annotation, or the hex
+portion of its digest if it has none. If any titles are given, only those
+files are pulled.`,
+		Example: `  # Pull every file
+  crane artifact pull example.com/files:v1 ./out
+
+  # Pull just one file
+  crane artifact pull example.com/files:v1 ./out a.json`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths, err := crane.PullArtifact(source(cmd, args[0]), args[1], args[2:], *options...)
+			if err != nil {
+				return err
+			}
+			for _, p := range paths {
+				fmt.Fprintln(cmd.OutOrStdout(), p)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+// parseArtifactFile parses a FILE[:MEDIATYPE] argument. Anything after the
+// last colon is only treated as a media type if it contains a slash, so file
+// names containing colons still work.
+func parseArtifactFile(arg string, mt types.MediaType) crane.ArtifactFile {
+	if i := strings.LastIndex(arg, ":"); i > 0 && strings.Contains(arg[i+1:], "/") {
+		return crane.ArtifactFile{Path: arg[:i], MediaType: types.MediaType(arg[i+1:])}
+	}
+	return crane.ArtifactFile{Path: arg, MediaType: mt}
+}
@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/docker/cli/cli/config"
 	"github.com/docker/cli/cli/config/types"
@@ -40,7 +42,7 @@ func NewCmdAuth(options []crane.Option, argv ...string) *cobra.Command {
 		Args:  cobra.NoArgs,
 		RunE:  func(cmd *cobra.Command, _ []string) error { return cmd.Usage() },
 	}
-	cmd.AddCommand(NewCmdAuthGet(options, argv...), NewCmdAuthLogin(argv...), NewCmdAuthLogout(argv...), NewCmdAuthToken(options))
+	cmd.AddCommand(NewCmdAuthGet(options, argv...), NewCmdAuthLogin(argv...), NewCmdAuthLogout(argv...), NewCmdAuthToken(options), NewCmdAuthDiagnose(options))
 	return cmd
 }
 
@@ -313,3 +315,167 @@ func NewCmdAuthLogout(argv ...string) *cobra.Command {
 	}
 	return cmd
 }
+
+// NewCmdAuthDiagnose creates a new cobra.Command for the auth diagnose subcommand.
+func NewCmdAuthDiagnose(options []crane.Option) *cobra.Command {
+	var push bool
+	cmd := &cobra.Command{
+		Use:   "diagnose REGISTRY|REPO",
+		Short: "Diagnose authentication with a registry",
+		Long: `Diagnose authentication with a registry.
+
+Runs each step of authenticating with the registry, and with the repository if
+one is given, printing the outcome of each: finding credentials, pinging the
+registry, exchanging the credentials for a token, checking the token's
+validity against the local clock, and using the token. The first step that
+fails is explained.`,
+		Example: `  # Check that the configured credentials can pull from a repository
+  crane auth diagnose gcr.io/example/image
+
+  # Check that they can push to it
+  crane auth diagnose --push gcr.io/example/image`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o := crane.GetOptions(options...)
+			d := &diagnosis{w: cmd.OutOrStdout()}
+
+			var (
+				target authn.Resource
+				reg    name.Registry
+				scopes []string
+				path   = "/v2/"
+			)
+			if strings.Contains(args[0], "/") {
+				repo, err := name.NewRepository(args[0], o.Name...)
+				if err != nil {
+					return err
+				}
+				target, reg, path = repo, repo.Registry, fmt.Sprintf("/v2/%s/tags/list", repo.RepositoryStr())
+				scopes = []string{repo.Scope(transport.PullScope)}
+				if push {
+					scopes[0] = repo.Scope(transport.PushScope)
+				}
+			} else {
+				r, err := name.NewRegistry(args[0], o.Name...)
+				if err != nil {
+					return err
+				}
+				target, reg = r, r
+			}
+			ctx := cmd.Context()
+
+			auth, err := authn.Resolve(ctx, o.Keychain, target)
+			if err != nil {
+				return d.fail("credentials", err, "Finding credentials failed. If a credential helper is configured, check that it is installed and logged in.")
+			}
+			cfg, err := authn.Authorization(ctx, auth)
+			if err != nil {
+				return d.fail("credentials", err, "The credentials could not be read.")
+			}
+			switch {
+			case auth == authn.Anonymous:
+				d.ok("credentials", "none found for %s, using anonymous access", reg)
+			case cfg.RegistryToken != "":
+				d.ok("credentials", "registry token")
+			case cfg.IdentityToken != "":
+				d.ok("credentials", "identity token")
+			default:
+				d.ok("credentials", "username %q", cfg.Username)
+			}
+
+			t := transport.NewLogger(o.Transport)
+			pr, err := transport.Ping(ctx, reg, t)
+			if err != nil {
+				return d.fail("ping", err, "The registry could not be reached. Check its name, and DNS, proxies and firewalls.")
+			}
+			scheme := "https"
+			if pr.Insecure {
+				scheme = "http"
+			}
+			switch strings.ToLower(pr.Scheme) {
+			case "":
+				d.ok("ping", "%s://%s/v2/ requires no authentication", scheme, reg.RegistryStr())
+			case "bearer":
+				d.ok("ping", "%s://%s/v2/ requires a token from %s", scheme, reg.RegistryStr(), pr.Parameters["realm"])
+			default:
+				d.ok("ping", "%s://%s/v2/ requires %s authentication", scheme, reg.RegistryStr(), pr.Scheme)
+			}
+
+			tok := &transport.Token{}
+			if strings.ToLower(pr.Scheme) == "bearer" {
+				tok, err = transport.Exchange(ctx, reg, auth, t, scopes, pr)
+				if err != nil {
+					return d.fail("token", err, explainToken(err, pr.Parameters["realm"], auth == authn.Anonymous))
+				}
+				d.ok("token", "issued for %v", scopes)
+
+				if err := tok.CheckTime(time.Now()); err != nil {
+					return d.fail("clock", err, "The token is not valid by the local clock, which is probably out of sync with the token server's. Sync it, e.g. with NTP.")
+				}
+				d.ok("clock", "in sync with the token server")
+			}
+
+			rt, err := transport.FromToken(reg, auth, t, pr, tok)
+			if err != nil {
+				return err
+			}
+			u := fmt.Sprintf("%s://%s%s", scheme, reg.RegistryStr(), path)
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := (&http.Client{Transport: rt}).Do(req)
+			if err != nil {
+				return d.fail("access", err, "The registry could not be reached.")
+			}
+			defer resp.Body.Close()
+			if err := transport.CheckError(resp, http.StatusOK); err != nil {
+				switch {
+				case errors.Is(err, transport.ErrNotFound):
+					d.ok("access", "GET %s: authenticated, but the repository does not exist", u)
+					return nil
+				case errors.Is(err, transport.ErrDenied), strings.Contains(resp.Header.Get("WWW-Authenticate"), "insufficient_scope"):
+					return d.fail("access", fmt.Errorf("%w: %w", transport.ErrInsufficientScope, err), fmt.Sprintf("The credentials are valid, but don't grant access to %s.", target))
+				case errors.Is(err, transport.ErrUnauthorized):
+					return d.fail("access", err, "The registry rejected the token its token server issued. The registry and token server may be misconfigured, or the credentials don't grant access.")
+				}
+				return d.fail("access", err, "The registry rejected the request.")
+			}
+			d.ok("access", "GET %s: %s", u, resp.Status)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&push, "push", false, "(Optional) Check push access to REPO, rather than pull access")
+	return cmd
+}
+
+// diagnosis prints the outcomes of the steps of crane auth diagnose.
+type diagnosis struct {
+	w io.Writer
+}
+
+func (d *diagnosis) ok(step, format string, args ...any) {
+	fmt.Fprintf(d.w, "%s: %s\n", step, fmt.Sprintf(format, args...))
+}
+
+// fail prints that step failed with err, and why, and returns err.
+func (d *diagnosis) fail(step string, err error, explanation string) error {
+	fmt.Fprintf(d.w, "%s: FAIL\n  %s\n", step, explanation)
+	return fmt.Errorf("%s: %w", step, err)
+}
+
+// explainToken explains the failed token exchange err with the token server
+// realm.
+func explainToken(err error, realm string, anonymous bool) string {
+	switch {
+	case errors.Is(err, transport.ErrBadCredentials) && anonymous:
+		return fmt.Sprintf("The token server %s requires credentials, and none were found. Log in with `crane auth login`.", realm)
+	case errors.Is(err, transport.ErrBadCredentials):
+		return fmt.Sprintf("The token server %s rejected the credentials. Check them, or log in again with `crane auth login`.", realm)
+	case errors.Is(err, transport.ErrInsufficientScope):
+		return fmt.Sprintf("The token server %s accepted the credentials, but refused the requested access.", realm)
+	case errors.Is(err, transport.ErrTokenServerUnreachable):
+		return fmt.Sprintf("The token server %s could not be reached. Check DNS, proxies and firewalls.", realm)
+	}
+	return fmt.Sprintf("The token server %s failed to issue a token.", realm)
+}
@@ -30,7 +30,7 @@ func NewCmdBlob(options *[]crane.Option) *cobra.Command {
 		Example: "crane blob ubuntu@sha256:4c1d20cdee96111c8acf1858b62655a37ce81ae48648993542b7ac363ac5c0e5 > blob.tar.gz",
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			src := args[0]
+			src := source(cmd, args[0])
 			layer, err := crane.PullLayer(src, *options...)
 			if err != nil {
 				return fmt.Errorf("pulling layer %s: %w", src, err)
@@ -0,0 +1,64 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdCleanTags creates a new cobra.Command for the clean-tags subcommand.
+func NewCmdCleanTags(options *[]crane.Option) *cobra.Command {
+	var c crane.TagCleanup
+	jobs := 0
+	cmd := &cobra.Command{
+		Use:   "clean-tags REPO",
+		Short: "Delete tags that match patterns and are older than a given age",
+		Long: `Delete tags that match patterns and are older than a given age.
+
+The age of a tag is derived from the org.opencontainers.image.created annotation
+on its manifest, if present, or else from its config file. Tags of unknown age are
+never deleted by --older-than. Tags matching --protect are never deleted.
+
+The deleted tags are printed to stdout.`,
+		Example: `  # Delete pull request tags that are more than a week old
+  crane clean-tags example.com/app --pattern 'pr-*' --older-than 168h
+
+  # See which tags would be deleted, keeping releases
+  crane clean-tags example.com/app --pattern '*' --protect 'v*' --protect latest --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(c.Patterns) == 0 {
+				return errors.New("at least one --pattern is required (use '*' for every tag)")
+			}
+			opts := append(*options, crane.WithJobs(jobs))
+			deleted, err := crane.CleanTags(args[0], c, opts...)
+			for _, tag := range deleted {
+				fmt.Fprintln(cmd.OutOrStdout(), tag)
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringSliceVar(&c.Patterns, "pattern", nil, "Glob for tags to delete (may be repeated)")
+	cmd.Flags().StringSliceVar(&c.Protect, "protect", nil, "Glob for tags to never delete (may be repeated)")
+	cmd.Flags().DurationVar(&c.OlderThan, "older-than", 0, "Only delete tags whose image was created longer ago than this (e.g. 168h)")
+	cmd.Flags().BoolVar(&c.DryRun, "dry-run", false, "Print the tags that would be deleted without deleting them")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "(Optional) The maximum number of concurrent deletes, defaults to GOMAXPROCS")
+
+	return cmd
+}
@@ -28,7 +28,7 @@ func NewCmdConfig(options *[]crane.Option) *cobra.Command {
 		Short: "Get the config of an image",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := crane.Config(args[0], *options...)
+			cfg, err := crane.Config(source(cmd, args[0]), *options...)
 			if err != nil {
 				return fmt.Errorf("fetching config: %w", err)
 			}
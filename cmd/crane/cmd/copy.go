@@ -15,9 +15,16 @@
 package cmd
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"runtime"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
 )
 
@@ -25,17 +32,51 @@ import (
 func NewCmdCopy(options *[]crane.Option) *cobra.Command {
 	allTags := false
 	noclobber := false
+	verify := false
+	noProgress := false
+	tagsFile := ""
+	platforms := &platformsValue{}
 	jobs := runtime.GOMAXPROCS(0)
 	cmd := &cobra.Command{
 		Use:     "copy SRC DST",
 		Aliases: []string{"cp"},
 		Short:   "Efficiently copy a remote image from src to dst while retaining the digest value",
-		Args:    cobra.ExactArgs(2),
-		RunE: func(_ *cobra.Command, args []string) error {
-			opts := append(*options, crane.WithJobs(jobs), crane.WithNoClobber(noclobber))
-			src, dst := args[0], args[1]
+		Long: `Efficiently copy a remote image from src to dst while retaining the digest value.
+
+With --platform, only the image for that platform is copied from an index,
+and pushed to dst as an image rather than an index, e.g. for destinations that
+only ever run that platform.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, stop := trackProgress(*options, noProgress)
+			defer stop()
+			opts = append(opts, crane.WithJobs(jobs), crane.WithNoClobber(noclobber))
+			if verify {
+				opts = append(opts, crane.WithDigestVerification())
+			}
+			src, dst := source(cmd, args[0]), args[1]
+			if len(platforms.platforms) != 0 {
+				if allTags {
+					return errors.New("--index-platforms can't be used with --all-tags")
+				}
+				return crane.CopyPlatforms(src, dst, platforms.platforms, opts...)
+			}
+			if tagsFile != "" {
+				if !allTags {
+					return errors.New("--tags-file can only be used with --all-tags")
+				}
+				tags, err := readTags(tagsFile)
+				if err != nil {
+					return err
+				}
+				opts = append(opts, crane.WithTags(tags...))
+			}
 			if allTags {
-				return crane.CopyRepository(src, dst, opts...)
+				err := crane.CopyRepository(src, dst, opts...)
+				if errors.Is(err, remote.ErrTagListingUnsupported) {
+					return fmt.Errorf("%w\nuse --tags-file to give the tags to copy", err)
+				}
+				return err
 			}
 
 			return crane.Copy(src, dst, opts...)
@@ -43,8 +84,39 @@ func NewCmdCopy(options *[]crane.Option) *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&allTags, "all-tags", "a", false, "(Optional) if true, copy all tags from SRC to DST")
+	cmd.Flags().StringVar(&tagsFile, "tags-file", "", "(Optional) With --all-tags, copy the tags listed in this file, one per line, or - for stdin, instead of listing SRC's tags")
 	cmd.Flags().BoolVarP(&noclobber, "no-clobber", "n", false, "(Optional) if true, avoid overwriting existing tags in DST")
+	cmd.Flags().BoolVar(&verify, "verify-digest", false, "(Optional) if true, fail unless every manifest in DST has the same digest as in SRC")
+	cmd.Flags().Var(platforms, "index-platforms", "(Optional) Only copy the children of an index that match these platforms (e.g. linux/amd64,linux/arm64), rewriting the index to list only those")
 	cmd.Flags().IntVarP(&jobs, "jobs", "j", 0, "(Optional) The maximum number of concurrent copies, defaults to GOMAXPROCS")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "(Optional) if true, log progress periodically instead of drawing progress bars")
 
 	return cmd
 }
+
+// readTags reads the --tags-file, skipping blank lines and # comments.
+func readTags(file string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	tags := []string{}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		tag := strings.TrimSpace(s.Text())
+		if tag == "" || strings.HasPrefix(tag, "#") {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+	return tags, nil
+}
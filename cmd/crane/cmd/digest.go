@@ -42,7 +42,7 @@ func NewCmdDigest(options *[]crane.Option) *cobra.Command {
 				return errors.New("cannot specify --full-ref with --tarball")
 			}
 
-			digest, err := getDigest(tarball, args, options)
+			digest, err := getDigest(cmd, tarball, args, options)
 			if err != nil {
 				return err
 			}
@@ -65,12 +65,12 @@ func NewCmdDigest(options *[]crane.Option) *cobra.Command {
 	return cmd
 }
 
-func getDigest(tarball string, args []string, options *[]crane.Option) (string, error) {
+func getDigest(cmd *cobra.Command, tarball string, args []string, options *[]crane.Option) (string, error) {
 	if tarball != "" {
 		return getTarballDigest(tarball, args, options)
 	}
 
-	return crane.Digest(args[0], *options...)
+	return crane.Digest(source(cmd, args[0]), *options...)
 }
 
 func getTarballDigest(tarball string, args []string, options *[]crane.Option) (string, error) {
@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+// NewCmdDigests creates a new cobra.Command for the digests subcommand.
+func NewCmdDigests(options *[]crane.Option) *cobra.Command {
+	var file, cacheFile string
+	var asJSON bool
+	var jobs int
+	var ttl time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "digests [IMAGE...]",
+		Short: "Resolve the digests of many images at once",
+		Example: `  # Resolve every reference in a file, one per line
+  crane digests -f images.txt
+
+  # Same, but reuse results from a local cache for up to an hour
+  crane digests -f images.txt --cache ~/.cache/crane-digests.json --cache-ttl 1h
+
+  # Read references from stdin and print JSON
+  cat images.txt | crane digests -f - --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			refs := args
+			if file != "" {
+				fromFile, err := readRefs(file, cmd.InOrStdin())
+				if err != nil {
+					return err
+				}
+				refs = append(refs, fromFile...)
+			}
+			if len(refs) == 0 {
+				return errors.New("no image references given; pass them as arguments or with --file")
+			}
+
+			cache, err := loadDigestCache(cacheFile, ttl)
+			if err != nil {
+				return err
+			}
+
+			// Share a single Puller so we only authenticate once per repository.
+			o := crane.GetOptions(*options...)
+			puller, err := remote.NewPuller(o.Remote...)
+			if err != nil {
+				return err
+			}
+			opts := append([]crane.Option{}, *options...)
+			opts = append(opts, func(o *crane.Options) {
+				o.Remote = append(o.Remote, remote.Reuse(puller))
+			})
+
+			digests := make([]string, len(refs))
+			var g errgroup.Group
+			g.SetLimit(max(jobs, 1))
+			for i, ref := range refs {
+				i, ref := i, ref
+				key := ref
+				if o.Platform != nil {
+					// The same tag can resolve differently per platform.
+					key = ref + " " + o.Platform.String()
+				}
+				g.Go(func() error {
+					if d, ok := cache.get(key); ok {
+						digests[i] = d
+						return nil
+					}
+					d, err := crane.Digest(source(cmd, ref), opts...)
+					if err != nil {
+						return fmt.Errorf("resolving %s: %w", ref, err)
+					}
+					digests[i] = d
+					cache.put(key, d)
+					return nil
+				})
+			}
+			if err := g.Wait(); err != nil {
+				return err
+			}
+
+			if err := cache.save(); err != nil {
+				logs.Warn.Printf("failed to save digest cache: %v", err)
+			}
+
+			if asJSON {
+				type resolved struct {
+					Ref    string `json:"ref"`
+					Digest string `json:"digest"`
+				}
+				out := make([]resolved, len(refs))
+				for i := range refs {
+					out[i] = resolved{Ref: refs[i], Digest: digests[i]}
+				}
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(out)
+			}
+			for i := range refs {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", refs[i], digests[i])
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to a file of newline-separated image references, or - for stdin")
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print results as JSON")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 4, "The maximum number of concurrent requests")
+	cmd.Flags().StringVar(&cacheFile, "cache", "", "(Optional) path to a file used to cache resolved digests between runs")
+	cmd.Flags().DurationVar(&ttl, "cache-ttl", time.Hour, "How long cached tag resolutions remain valid")
+
+	return cmd
+}
+
+// readRefs reads newline-separated references from path (or r, if path is
+// "-"), ignoring blank lines and # comments.
+func readRefs(path string, r io.Reader) ([]string, error) {
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	refs := []string{}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	return refs, s.Err()
+}
+
+type digestCacheEntry struct {
+	Digest   string    `json:"digest"`
+	Resolved time.Time `json:"resolved"`
+}
+
+// digestCache is a simple file-backed cache of reference -> digest.
+type digestCache struct {
+	path    string
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]digestCacheEntry
+}
+
+func loadDigestCache(path string, ttl time.Duration) (*digestCache, error) {
+	c := &digestCache{
+		path:    path,
+		ttl:     ttl,
+		entries: map[string]digestCacheEntry{},
+	}
+	if path == "" {
+		return c, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.entries); err != nil {
+		logs.Warn.Printf("ignoring corrupt digest cache %s: %v", path, err)
+		c.entries = map[string]digestCacheEntry{}
+	}
+	return c, nil
+}
+
+func (c *digestCache) get(ref string) (string, bool) {
+	if c.path == "" {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[ref]
+	if !ok || time.Since(e.Resolved) > c.ttl {
+		return "", false
+	}
+	return e.Digest, true
+}
+
+func (c *digestCache) put(ref, digest string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[ref] = digestCacheEntry{Digest: digest, Resolved: time.Now()}
+}
+
+func (c *digestCache) save() error {
+	if c.path == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ref, e := range c.entries {
+		if time.Since(e.Resolved) > c.ttl {
+			delete(c.entries, ref)
+		}
+	}
+	b, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, b, 0o600)
+}
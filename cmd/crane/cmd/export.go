@@ -15,12 +15,15 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 
+	"github.com/google/go-containerregistry/pkg/compression"
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/spf13/cobra"
@@ -28,9 +31,25 @@ import (
 
 // NewCmdExport creates a new cobra.Command for the export subcommand.
 func NewCmdExport(options *[]crane.Option) *cobra.Command {
-	return &cobra.Command{
+	var (
+		format     string
+		gz, zst    bool
+		sorted     bool
+		provenance string
+	)
+
+	cmd := &cobra.Command{
 		Use:   "export IMAGE|- TARBALL|-",
 		Short: "Export filesystem of a container image as a tarball",
+		Long: `Export filesystem of a container image as a tarball.
+
+The tarball can be compressed with --gzip or --zstd. With --sort, its entries are
+sorted by name, so that images with the same files export to the same tarball
+however their layers are arranged. With --provenance, a JSON file mapping each
+entry to the digest of the layer it comes from is written alongside it.
+
+With --format=oci, the whole image is written to an OCI image layout directory
+instead. With --format=docker, it is written as a "docker save" tarball.`,
 		Example: `  # Write tarball to stdout
   crane export ubuntu -
 
@@ -38,19 +57,47 @@ func NewCmdExport(options *[]crane.Option) *cobra.Command {
   crane export ubuntu ubuntu.tar
 
   # Read image from stdin
-  crane export - ubuntu.tar`,
+  crane export - ubuntu.tar
+
+  # Write a reproducible, compressed tarball, and where each file came from
+  crane export ubuntu ubuntu.tar.zst --zstd --sort --provenance ubuntu.provenance.json
+
+  # Write an OCI image layout for a vulnerability scanner
+  crane export ubuntu ./ubuntu --format oci`,
 		Args: cobra.RangeArgs(1, 2),
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			src, dst := args[0], "-"
 			if len(args) > 1 {
 				dst = args[1]
 			}
 
-			f, err := openFile(dst)
-			if err != nil {
-				return fmt.Errorf("failed to open %s: %w", dst, err)
+			switch format {
+			case "filesystem", "docker":
+			case "oci":
+				if dst == "-" {
+					return errors.New("--format=oci requires a destination directory")
+				}
+			default:
+				return fmt.Errorf("unexpected --format: %q (valid values are: filesystem, oci, and docker)", format)
+			}
+			if format == "docker" && src == "-" {
+				return errors.New("--format=docker requires an image reference")
+			}
+			var exportOpts []crane.Option
+			switch {
+			case gz && zst:
+				return errors.New("--gzip and --zstd are mutually exclusive")
+			case gz:
+				exportOpts = append(exportOpts, crane.WithExportCompression(compression.GZip))
+			case zst:
+				exportOpts = append(exportOpts, crane.WithExportCompression(compression.ZStd))
+			}
+			if sorted {
+				exportOpts = append(exportOpts, crane.WithSortedExport())
+			}
+			if format != "filesystem" && (len(exportOpts) != 0 || provenance != "") {
+				return errors.New("--gzip, --zstd, --sort and --provenance require --format=filesystem")
 			}
-			defer f.Close()
 
 			var img v1.Image
 			if src == "-" {
@@ -69,8 +116,12 @@ func NewCmdExport(options *[]crane.Option) *cobra.Command {
 				if err != nil {
 					return fmt.Errorf("reading tarball from stdin: %w", err)
 				}
+			} else if dimg, ok := crane.FromDaemon(src, *options...); ok {
+				// The daemon has images under the names they were given,
+				// so it's asked for src as is.
+				img = dimg
 			} else {
-				desc, err := crane.Get(src, *options...)
+				desc, err := crane.Get(source(cmd, src), *options...)
 				if err != nil {
 					return fmt.Errorf("pulling %s: %w", src, err)
 				}
@@ -87,9 +138,49 @@ func NewCmdExport(options *[]crane.Option) *cobra.Command {
 				}
 			}
 
-			return crane.Export(img, f)
+			if format == "oci" {
+				return crane.SaveOCI(img, dst)
+			}
+
+			f, err := openFile(dst)
+			if err != nil {
+				return fmt.Errorf("failed to open %s: %w", dst, err)
+			}
+			defer f.Close()
+
+			if format == "docker" {
+				o := crane.GetOptions(*options...)
+				ref, err := name.ParseReference(src, o.Name...)
+				if err != nil {
+					return err
+				}
+				return tarball.Write(ref, img, f)
+			}
+			if provenance != "" {
+				pf, err := os.Create(provenance)
+				if err != nil {
+					return err
+				}
+				defer pf.Close()
+				exportOpts = append(exportOpts, crane.WithExportProvenance(pf))
+			}
+			if err := crane.Export(img, f, append(*options, exportOpts...)...); err != nil {
+				if provenance != "" {
+					// Don't leave an empty or partial provenance file.
+					os.Remove(provenance)
+				}
+				return err
+			}
+			return nil
 		},
 	}
+	cmd.Flags().StringVar(&format, "format", "filesystem", fmt.Sprintf("Format to export the image in (%q, %q, or %q)", "filesystem", "oci", "docker"))
+	cmd.Flags().BoolVar(&gz, "gzip", false, "(Optional) Compress the tarball with gzip")
+	cmd.Flags().BoolVar(&zst, "zstd", false, "(Optional) Compress the tarball with zstd")
+	cmd.Flags().BoolVar(&sorted, "sort", false, "(Optional) Sort the entries of the tarball by name")
+	cmd.Flags().StringVar(&provenance, "provenance", "", "(Optional) Path to write a JSON file mapping each entry of the tarball to the digest of its layer")
+
+	return cmd
 }
 
 func openFile(s string) (*os.File, error) {
@@ -56,7 +56,7 @@ func NewCmdFlatten(options *[]crane.Option) *cobra.Command {
 				dst = src
 			}
 
-			ref, err := name.ParseReference(src, o.Name...)
+			ref, err := name.ParseReference(source(cmd, src), o.Name...)
 			if err != nil {
 				log.Fatalf("parsing %s: %v", src, err)
 			}
@@ -18,15 +18,23 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
 )
 
-func NewCmdLayout() *cobra.Command {
+func NewCmdLayout(options *[]crane.Option) *cobra.Command {
 	cmd := &cobra.Command{
-		Use: "layout",
+		Use:   "layout",
+		Short: "Work with local oci-layouts",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Usage()
+		},
 	}
-	cmd.AddCommand(newCmdGc())
+	cmd.AddCommand(newCmdGc(), newCmdLayoutPush(options))
 	return cmd
 }
 
@@ -64,3 +72,42 @@ func newCmdGc() *cobra.Command {
 
 	return cmd
 }
+
+// newCmdLayoutPush creates a new cobra.Command for the layout push subcommand.
+func newCmdLayoutPush(options *[]crane.Option) *cobra.Command {
+	byDigest := false
+	noProgress := false
+	cmd := &cobra.Command{
+		Use:   "push OCI-LAYOUT REPO",
+		Short: "Push every manifest in a local oci-layout to a repository",
+		Long: `Push every manifest in a local oci-layout to a repository.
+
+Manifests with an "org.opencontainers.image.ref.name" annotation in the layout's index.json,
+as written by "crane pull --format=oci --annotate-ref", are pushed to the tag it names.
+Other manifests are pushed by digest.`,
+		Example: `  # Push a layout's images to their tags in another repository
+  crane layout push ./layout registry.example.com/app`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			opts, stop := trackProgress(*options, noProgress)
+			defer stop()
+			o := crane.GetOptions(opts...)
+			p, err := layout.FromPath(args[0])
+			if err != nil {
+				return err
+			}
+			repo, err := name.NewRepository(args[1], o.Name...)
+			if err != nil {
+				return err
+			}
+			if byDigest {
+				return remote.WriteLayout(p, repo, nil, o.Remote...)
+			}
+			return remote.WriteLayoutTags(p, repo, nil, o.Remote...)
+		},
+	}
+	cmd.Flags().BoolVar(&byDigest, "by-digest", false, "Push every manifest by digest, ignoring ref.name annotations")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "(Optional) if true, log progress periodically instead of drawing progress bars")
+
+	return cmd
+}
@@ -17,6 +17,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/logs"
@@ -41,13 +42,80 @@ func NewCmdIndex(options *[]crane.Option) *cobra.Command {
 			cmd.Usage()
 		},
 	}
-	cmd.AddCommand(NewCmdIndexFilter(options), NewCmdIndexAppend(options))
+	cmd.AddCommand(NewCmdIndexCreate(options), NewCmdIndexFilter(options), NewCmdIndexAppend(options))
+	return cmd
+}
+
+// NewCmdIndexCreate creates a new cobra.Command for the index create subcommand.
+func NewCmdIndexCreate(options *[]crane.Option) *cobra.Command {
+	var newTag string
+	var newManifests []string
+	var docker, flatten bool
+	var meta indexMetadata
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a remote index from manifests.",
+		Long: `This sub-command pushes a new index containing the given manifests.
+
+The platform for each manifest is inferred from the config file or omitted if that is infeasible.`,
+		Example: `  # Create a multi-arch index from per-arch images
+  crane index create -m example.com/app:amd64 -m example.com/app:arm64 -t example.com/app:multi
+
+  # Same as above, but as a Docker manifest list
+  crane index create --docker -m example.com/app:amd64 -m example.com/app:arm64 -t example.com/app:multi`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if newTag == "" {
+				return errors.New("--tag must be specified")
+			}
+			if len(newManifests) == 0 {
+				return errors.New("at least one --manifest must be specified")
+			}
+			o := crane.GetOptions(*options...)
+
+			ref, err := name.ParseReference(newTag, o.Name...)
+			if err != nil {
+				return fmt.Errorf("parsing reference %s: %w", newTag, err)
+			}
+
+			var base v1.ImageIndex = empty.Index
+			if docker {
+				base = mutate.IndexMediaType(base, types.DockerManifestList)
+			}
+			adds, err := indexAddenda(cmd, o, newManifests, flatten)
+			if err != nil {
+				return err
+			}
+
+			idx, err := meta.apply(o, mutate.AppendManifests(base, adds...))
+			if err != nil {
+				return err
+			}
+			digest, err := idx.Digest()
+			if err != nil {
+				return err
+			}
+			if err := remote.WriteIndex(ref, idx, o.Remote...); err != nil {
+				return fmt.Errorf("pushing index %s: %w", newTag, err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), ref.Context().Digest(digest.String()))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&newTag, "tag", "t", "", "Tag to apply to resulting index")
+	cmd.Flags().StringSliceVarP(&newManifests, "manifest", "m", []string{}, "References to manifests to include in the index")
+	cmd.Flags().BoolVar(&docker, "docker", false, "If true, the index will have Docker media types instead of OCI")
+	cmd.Flags().BoolVar(&flatten, "flatten", true, "If true, including an index will include each of its children rather than the index itself")
+	meta.addFlags(cmd)
+
 	return cmd
 }
 
 // NewCmdIndexFilter creates a new cobra.Command for the index filter subcommand.
 func NewCmdIndexFilter(options *[]crane.Option) *cobra.Command {
 	var newTag string
+	var meta indexMetadata
 	platforms := &platformsValue{}
 
 	cmd := &cobra.Command{
@@ -66,11 +134,17 @@ func NewCmdIndexFilter(options *[]crane.Option) *cobra.Command {
 			o := crane.GetOptions(*options...)
 			baseRef := args[0]
 
+			// The filtered index is written back to baseRef, not wherever
+			// it is read from.
 			ref, err := name.ParseReference(baseRef, o.Name...)
 			if err != nil {
 				return err
 			}
-			desc, err := remote.Get(ref, o.Remote...)
+			src, err := name.ParseReference(source(cmd, baseRef), o.Name...)
+			if err != nil {
+				return err
+			}
+			desc, err := remote.Get(src, o.Remote...)
 			if err != nil {
 				return fmt.Errorf("pulling %s: %w", baseRef, err)
 			}
@@ -82,7 +156,10 @@ func NewCmdIndexFilter(options *[]crane.Option) *cobra.Command {
 				return nil
 			}
 
-			idx := filterIndex(base, platforms.platforms)
+			idx, err := meta.apply(o, filterIndex(base, platforms.platforms))
+			if err != nil {
+				return err
+			}
 
 			digest, err := idx.Digest()
 			if err != nil {
@@ -111,6 +188,7 @@ func NewCmdIndexFilter(options *[]crane.Option) *cobra.Command {
 
 	// Consider reusing the persistent flag for this, it's separate so we can have multiple values.
 	cmd.Flags().Var(platforms, "platform", "Specifies the platform(s) to keep from base in the form os/arch[/variant][:osversion][,<platform>] (e.g. linux/amd64).")
+	meta.addFlags(cmd)
 
 	return cmd
 }
@@ -120,6 +198,7 @@ func NewCmdIndexAppend(options *[]crane.Option) *cobra.Command {
 	var baseRef, newTag string
 	var newManifests []string
 	var dockerEmptyBase, flatten bool
+	var meta indexMetadata
 
 	cmd := &cobra.Command{
 		Use:   "append",
@@ -160,7 +239,11 @@ The platform for appended manifests is inferred from the config file or omitted
 				if err != nil {
 					return err
 				}
-				desc, err := remote.Get(ref, o.Remote...)
+				src, err := name.ParseReference(source(cmd, baseRef), o.Name...)
+				if err != nil {
+					return err
+				}
+				desc, err := remote.Get(src, o.Remote...)
 				if err != nil {
 					return fmt.Errorf("pulling %s: %w", baseRef, err)
 				}
@@ -173,81 +256,15 @@ The platform for appended manifests is inferred from the config file or omitted
 				}
 			}
 
-			adds := make([]mutate.IndexAddendum, 0, len(newManifests))
-
-			for _, m := range newManifests {
-				ref, err := name.ParseReference(m, o.Name...)
-				if err != nil {
-					return err
-				}
-				desc, err := remote.Get(ref, o.Remote...)
-				if err != nil {
-					return err
-				}
-				if desc.MediaType.IsImage() {
-					img, err := desc.Image()
-					if err != nil {
-						return err
-					}
-
-					cf, err := img.ConfigFile()
-					if err != nil {
-						return err
-					}
-					newDesc, err := partial.Descriptor(img)
-					if err != nil {
-						return err
-					}
-					newDesc.Platform = cf.Platform()
-					adds = append(adds, mutate.IndexAddendum{
-						Add:        img,
-						Descriptor: *newDesc,
-					})
-				} else if desc.MediaType.IsIndex() {
-					idx, err := desc.ImageIndex()
-					if err != nil {
-						return err
-					}
-					if flatten {
-						im, err := idx.IndexManifest()
-						if err != nil {
-							return err
-						}
-						for _, child := range im.Manifests {
-							switch {
-							case child.MediaType.IsImage():
-								img, err := idx.Image(child.Digest)
-								if err != nil {
-									return err
-								}
-								adds = append(adds, mutate.IndexAddendum{
-									Add:        img,
-									Descriptor: child,
-								})
-							case child.MediaType.IsIndex():
-								idx, err := idx.ImageIndex(child.Digest)
-								if err != nil {
-									return err
-								}
-								adds = append(adds, mutate.IndexAddendum{
-									Add:        idx,
-									Descriptor: child,
-								})
-							default:
-								return fmt.Errorf("unexpected child %q with media type %q", child.Digest, child.MediaType)
-							}
-						}
-					} else {
-						adds = append(adds, mutate.IndexAddendum{
-							Add: idx,
-						})
-					}
-				} else {
-					return fmt.Errorf("saw unexpected MediaType %q for %q", desc.MediaType, m)
-				}
+			adds, err := indexAddenda(cmd, o, newManifests, flatten)
+			if err != nil {
+				return err
 			}
 
-			idx := mutate.AppendManifests(base, adds...)
+			idx, err := meta.apply(o, mutate.AppendManifests(base, adds...))
+			if err != nil {
+				return err
+			}
 			digest, err := idx.Digest()
 			if err != nil {
 				return err
@@ -275,10 +292,139 @@ The platform for appended manifests is inferred from the config file or omitted
 	cmd.Flags().StringSliceVarP(&newManifests, "manifest", "m", []string{}, "References to manifests to append to the base index")
 	cmd.Flags().BoolVar(&dockerEmptyBase, "docker-empty-base", false, "If true, empty base index will have Docker media types instead of OCI")
 	cmd.Flags().BoolVar(&flatten, "flatten", true, "If true, appending an index will append each of its children rather than the index itself")
+	meta.addFlags(cmd)
 
 	return cmd
 }
 
+// indexMetadata holds the flags that set the index-level fields of an index
+// (see mutate.IndexMetadata).
+type indexMetadata struct {
+	artifactType string
+	subject      string
+	created      string
+	annotations  map[string]string
+}
+
+func (m *indexMetadata) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&m.artifactType, "artifact-type", "", "(Optional) The artifactType of the index")
+	cmd.Flags().StringVar(&m.subject, "subject", "", "(Optional) Reference to the manifest the index refers to, as its subject")
+	cmd.Flags().StringVar(&m.created, "created", "", "(Optional) When the index was created, in RFC 3339 format or \"now\", to record in its org.opencontainers.image.created annotation")
+	cmd.Flags().StringToStringVarP(&m.annotations, "annotation", "a", nil, "(Optional) Annotations to add to the index")
+}
+
+// apply sets the fields given by flags on idx.
+func (m *indexMetadata) apply(o crane.Options, idx v1.ImageIndex) (v1.ImageIndex, error) {
+	if m.artifactType == "" && m.subject == "" && m.created == "" && len(m.annotations) == 0 {
+		return idx, nil
+	}
+	opts := mutate.IndexMetadataOptions{
+		ArtifactType: types.MediaType(m.artifactType),
+		Annotations:  m.annotations,
+	}
+	if m.subject != "" {
+		ref, err := name.ParseReference(m.subject, o.Name...)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --subject: %w", err)
+		}
+		if opts.Subject, err = remote.Head(ref, o.Remote...); err != nil {
+			return nil, fmt.Errorf("resolving --subject %s: %w", ref, err)
+		}
+	}
+	switch m.created {
+	case "":
+	case "now":
+		opts.Created = time.Now()
+	default:
+		t, err := time.Parse(time.RFC3339, m.created)
+		if err != nil {
+			return nil, fmt.Errorf("parsing --created: %w", err)
+		}
+		opts.Created = t
+	}
+	return mutate.IndexMetadata(idx, opts)
+}
+
+// indexAddenda resolves manifests to addenda for mutate.AppendManifests,
+// inferring the platform of images from their config files. If flatten is
+// true, the children of indexes are added rather than the indexes themselves.
+func indexAddenda(cmd *cobra.Command, o crane.Options, manifests []string, flatten bool) ([]mutate.IndexAddendum, error) {
+	adds := make([]mutate.IndexAddendum, 0, len(manifests))
+
+	for _, m := range manifests {
+		ref, err := name.ParseReference(source(cmd, m), o.Name...)
+		if err != nil {
+			return nil, err
+		}
+		desc, err := remote.Get(ref, o.Remote...)
+		if err != nil {
+			return nil, err
+		}
+		if desc.MediaType.IsImage() {
+			img, err := desc.Image()
+			if err != nil {
+				return nil, err
+			}
+
+			cf, err := img.ConfigFile()
+			if err != nil {
+				return nil, err
+			}
+			newDesc, err := partial.Descriptor(img)
+			if err != nil {
+				return nil, err
+			}
+			newDesc.Platform = cf.Platform()
+			adds = append(adds, mutate.IndexAddendum{
+				Add:        img,
+				Descriptor: *newDesc,
+			})
+		} else if desc.MediaType.IsIndex() {
+			idx, err := desc.ImageIndex()
+			if err != nil {
+				return nil, err
+			}
+			if flatten {
+				im, err := idx.IndexManifest()
+				if err != nil {
+					return nil, err
+				}
+				for _, child := range im.Manifests {
+					switch {
+					case child.MediaType.IsImage():
+						img, err := idx.Image(child.Digest)
+						if err != nil {
+							return nil, err
+						}
+						adds = append(adds, mutate.IndexAddendum{
+							Add:        img,
+							Descriptor: child,
+						})
+					case child.MediaType.IsIndex():
+						idx, err := idx.ImageIndex(child.Digest)
+						if err != nil {
+							return nil, err
+						}
+						adds = append(adds, mutate.IndexAddendum{
+							Add:        idx,
+							Descriptor: child,
+						})
+					default:
+						return nil, fmt.Errorf("unexpected child %q with media type %q", child.Digest, child.MediaType)
+					}
+				}
+			} else {
+				adds = append(adds, mutate.IndexAddendum{
+					Add: idx,
+				})
+			}
+		} else {
+			return nil, fmt.Errorf("saw unexpected MediaType %q for %q", desc.MediaType, m)
+		}
+	}
+	return adds, nil
+}
+
 func filterIndex(idx v1.ImageIndex, platforms []v1.Platform) v1.ImageIndex {
 	matcher := not(satisfiesPlatforms(platforms))
 	return mutate.RemoveManifests(idx, matcher)
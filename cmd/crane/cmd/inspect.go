@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdInspect creates a new cobra.Command for the inspect subcommand.
+func NewCmdInspect(options *[]crane.Option) *cobra.Command {
+	return &cobra.Command{
+		Use:   "inspect IMAGE",
+		Short: "Describe an image or index in a single JSON document",
+		Long: `Describe an image or index in a single JSON document.
+
+The document has the descriptor, manifest and annotations of IMAGE, and its total compressed
+size. For an image, it also has the config file, platform and layers (with their sizes and
+diffIDs); for an index, it has the children, with their platforms and sizes. With --platform,
+the image for that platform is described rather than the index.`,
+		Example: `  # Describe an image
+  crane inspect ubuntu --platform linux/amd64
+
+  # List the platforms of an index
+  crane inspect ubuntu | jq -r '.manifests[].platform | "\(.os)/\(.architecture)"'`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in, err := crane.Inspect(source(cmd, args[0]), *options...)
+			if err != nil {
+				return err
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(in)
+		},
+	}
+}
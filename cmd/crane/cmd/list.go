@@ -16,35 +16,78 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/yaml.v3"
 )
 
 // NewCmdList creates a new cobra.Command for the ls subcommand.
 func NewCmdList(options *[]crane.Option) *cobra.Command {
-	var fullRef, omitDigestTags bool
+	var fullRef, omitDigestTags, digests, created bool
+	var output string
+	jobs := runtime.GOMAXPROCS(0)
 	cmd := &cobra.Command{
 		Use:   "ls REPO",
 		Short: "List the tags in a repo",
-		Args:  cobra.ExactArgs(1),
+		Example: `  # List tags with their digests and creation times, as JSON
+  crane ls --digests --created --output=json ubuntu`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			o := crane.GetOptions(*options...)
 
-			return list(cmd.Context(), cmd.OutOrStdout(), args[0], fullRef, omitDigestTags, o)
+			lo := listOptions{
+				fullRef:        fullRef,
+				omitDigestTags: omitDigestTags,
+				digests:        digests,
+				created:        created,
+				output:         output,
+				jobs:           jobs,
+			}
+			return list(cmd.Context(), cmd.OutOrStdout(), source(cmd, args[0]), lo, o)
 		},
 	}
 	cmd.Flags().BoolVar(&fullRef, "full-ref", false, "(Optional) if true, print the full image reference")
 	cmd.Flags().BoolVarP(&omitDigestTags, "omit-digest-tags", "O", false, "(Optional), if true, omit digest tags (e.g., ':sha256-...')")
+	cmd.Flags().BoolVar(&digests, "digests", false, "(Optional) if true, also print the digest of each tag")
+	cmd.Flags().BoolVar(&created, "created", false, "(Optional) if true, also print the creation time of each tag's image, from its config")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "(Optional) format to print tags in: json or yaml, rather than one per line")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", jobs, "(Optional) the maximum number of concurrent requests for --digests and --created")
 	return cmd
 }
 
-func list(ctx context.Context, w io.Writer, src string, fullRef, omitDigestTags bool, o crane.Options) error {
+type listOptions struct {
+	fullRef, omitDigestTags bool
+	digests, created        bool
+	output                  string
+	jobs                    int
+}
+
+// listEntry is a tag in the output of ls.
+type listEntry struct {
+	Tag     string     `json:"tag" yaml:"tag"`
+	Ref     string     `json:"ref" yaml:"ref"`
+	Digest  string     `json:"digest,omitempty" yaml:"digest,omitempty"`
+	Created *time.Time `json:"created,omitempty" yaml:"created,omitempty"`
+}
+
+func list(ctx context.Context, w io.Writer, src string, lo listOptions, o crane.Options) error {
+	switch lo.output {
+	case "", "json", "yaml":
+	default:
+		return fmt.Errorf("unexpected --output: %q (valid values are: json and yaml)", lo.output)
+	}
+
 	repo, err := name.NewRepository(src, o.Name...)
 	if err != nil {
 		return fmt.Errorf("parsing repo %q: %w", src, err)
@@ -60,22 +103,147 @@ func list(ctx context.Context, w io.Writer, src string, fullRef, omitDigestTags
 		return fmt.Errorf("reading tags for %s: %w", repo, err)
 	}
 
+	out := &listWriter{w: w, lo: lo}
 	for lister.HasNext() {
 		tags, err := lister.Next(ctx)
 		if err != nil {
 			return err
 		}
+		var entries []listEntry
 		for _, tag := range tags.Tags {
-			if omitDigestTags && strings.HasPrefix(tag, "sha256-") {
+			if lo.omitDigestTags && strings.HasPrefix(tag, "sha256-") {
 				continue
 			}
+			entries = append(entries, listEntry{Tag: tag, Ref: repo.Tag(tag).String()})
+		}
+		if err := describeTags(ctx, puller, repo, entries, lo); err != nil {
+			return err
+		}
 
-			if fullRef {
-				fmt.Fprintln(w, repo.Tag(tag))
-			} else {
-				fmt.Fprintln(w, tag)
+		// Print each page as it comes, for repos with many tags.
+		for _, e := range entries {
+			if err := out.write(e); err != nil {
+				return err
 			}
 		}
 	}
-	return nil
+	return out.close()
+}
+
+// listWriter prints the entries of ls as they come, as lines or as a JSON or
+// YAML list.
+type listWriter struct {
+	w  io.Writer
+	lo listOptions
+	n  int
+}
+
+func (lw *listWriter) write(e listEntry) error {
+	defer func() { lw.n++ }()
+	switch lw.lo.output {
+	case "json":
+		b, err := json.MarshalIndent(e, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if lw.n == 0 {
+			sep = "[\n  "
+		}
+		_, err = fmt.Fprintf(lw.w, "%s%s", sep, b)
+		return err
+	case "yaml":
+		// Lists of one entry concatenate into a list of them all.
+		b, err := yaml.Marshal([]listEntry{e})
+		if err != nil {
+			return err
+		}
+		_, err = lw.w.Write(b)
+		return err
+	}
+
+	line := e.Tag
+	if lw.lo.fullRef {
+		line = e.Ref
+	}
+	if lw.lo.digests {
+		line += "\t" + e.Digest
+	}
+	if lw.lo.created {
+		line += "\t"
+		if e.Created != nil {
+			line += e.Created.Format(time.RFC3339)
+		}
+	}
+	_, err := fmt.Fprintln(lw.w, line)
+	return err
+}
+
+// close ends the list.
+func (lw *listWriter) close() error {
+	var err error
+	switch {
+	case lw.lo.output == "json" && lw.n == 0:
+		_, err = fmt.Fprintln(lw.w, "[]")
+	case lw.lo.output == "json":
+		_, err = fmt.Fprint(lw.w, "\n]\n")
+	case lw.lo.output == "yaml" && lw.n == 0:
+		_, err = fmt.Fprintln(lw.w, "[]")
+	}
+	return err
+}
+
+// describeTags fills in the digests and creation times of entries, as lo
+// asks for.
+func describeTags(ctx context.Context, puller *remote.Puller, repo name.Repository, entries []listEntry, lo listOptions) error {
+	if !lo.digests && !lo.created {
+		return nil
+	}
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(lo.jobs, 1))
+	for i := range entries {
+		e := &entries[i]
+		ref := repo.Tag(e.Tag)
+		g.Go(func() error {
+			if !lo.created {
+				desc, err := puller.Head(ctx, ref)
+				if err != nil {
+					return fmt.Errorf("getting digest of %s: %w", ref, err)
+				}
+				e.Digest = desc.Digest.String()
+				return nil
+			}
+
+			desc, err := puller.Get(ctx, ref)
+			if err != nil {
+				return fmt.Errorf("getting %s: %w", ref, err)
+			}
+			if lo.digests {
+				e.Digest = desc.Digest.String()
+			}
+			if !desc.MediaType.IsImage() && !desc.MediaType.IsIndex() {
+				// Other artifacts have no creation time.
+				return nil
+			}
+			// Indexes may have no image for the platform, and artifacts
+			// (like the sha256-... tags of signatures) may have configs
+			// that aren't config files, so they just have no creation time.
+			img, err := desc.Image()
+			if err != nil {
+				logs.Debug.Printf("not listing creation time of %s: %v", ref, err)
+				return nil
+			}
+			cfg, err := img.ConfigFile()
+			if err != nil {
+				logs.Debug.Printf("not listing creation time of %s: %v", ref, err)
+				return nil
+			}
+			if !cfg.Created.IsZero() {
+				created := cfg.Created.UTC()
+				e.Created = &created
+			}
+			return nil
+		})
+	}
+	return g.Wait()
 }
@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"gopkg.in/yaml.v3"
+)
+
+// rawManifest is a manifest to push as is.
+type rawManifest struct {
+	b  []byte
+	mt types.MediaType
+}
+
+func (r rawManifest) RawManifest() ([]byte, error)        { return r.b, nil }
+func (r rawManifest) MediaType() (types.MediaType, error) { return r.mt, nil }
+
+func TestListCreated(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	repo, err := name.NewRepository(strings.TrimPrefix(s.URL, "http://") + "/list")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	img, err = mutate.CreatedAt(img, v1.Time{Time: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(repo.Tag("image"), img); err != nil {
+		t.Fatal(err)
+	}
+
+	// An index whose children have no platform, so none is picked.
+	idx, err := random.Index(1024, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(repo.Tag("index"), idx); err != nil {
+		t.Fatal(err)
+	}
+
+	// A signature-like artifact whose config isn't a config file.
+	cfg := static.NewLayer([]byte("not a config file"), "application/vnd.example.config")
+	if err := remote.WriteLayer(repo, cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfgDigest, err := cfg.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := json.Marshal(v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		Config:        v1.Descriptor{MediaType: "application/vnd.example.config", Size: 17, Digest: cfgDigest},
+		Layers:        []v1.Descriptor{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Put(repo.Tag("sha256-abc.sig"), rawManifest{m, types.OCIManifestSchema1}); err != nil {
+		t.Fatal(err)
+	}
+
+	o := crane.GetOptions()
+
+	var out bytes.Buffer
+	if err := list(context.Background(), &out, repo.String(), listOptions{created: true, output: "json", jobs: 2}, o); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var entries []listEntry
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("parsing %s: %v", out.String(), err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3: %s", len(entries), out.String())
+	}
+	for _, e := range entries {
+		if e.Tag == "image" {
+			if e.Created == nil || !e.Created.Equal(created) {
+				t.Errorf("%s: created = %v, want %v", e.Tag, e.Created, created)
+			}
+		} else if e.Created != nil {
+			t.Errorf("%s: created = %v, want none", e.Tag, e.Created)
+		}
+	}
+
+	// Lists with nothing in them are still lists.
+	sigs := repo.Registry.Repo("sigs")
+	if err := remote.Write(sigs.Tag("sha256-abc.sig"), img); err != nil {
+		t.Fatal(err)
+	}
+	for _, output := range []string{"json", "yaml"} {
+		var out bytes.Buffer
+		if err := list(context.Background(), &out, sigs.String(), listOptions{output: output, omitDigestTags: true}, o); err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		if got, want := out.String(), "[]\n"; got != want {
+			t.Errorf("--output=%s: got %q, want %q", output, got, want)
+		}
+	}
+
+	out.Reset()
+	if err := list(context.Background(), &out, repo.String(), listOptions{output: "yaml"}, o); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	entries = nil
+	if err := yaml.Unmarshal(out.Bytes(), &entries); err != nil || len(entries) != 3 {
+		t.Errorf("--output=yaml: got %q, want 3 entries (%v)", out.String(), err)
+	}
+}
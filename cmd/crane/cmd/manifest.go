@@ -15,20 +15,30 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/google/go-containerregistry/internal/jsonpatch"
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/spf13/cobra"
 )
 
 // NewCmdManifest creates a new cobra.Command for the manifest subcommand.
 func NewCmdManifest(options *[]crane.Option) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "manifest IMAGE",
 		Short: "Get the manifest of an image",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			src := args[0]
+			src := source(cmd, args[0])
 			manifest, err := crane.Manifest(src, *options...)
 			if err != nil {
 				return fmt.Errorf("fetching manifest %s: %w", src, err)
@@ -37,4 +47,116 @@ func NewCmdManifest(options *[]crane.Option) *cobra.Command {
 			return nil
 		},
 	}
+	cmd.AddCommand(NewCmdManifestEdit(options))
+	return cmd
+}
+
+// NewCmdManifestEdit creates a new cobra.Command for the manifest edit subcommand.
+func NewCmdManifestEdit(options *[]crane.Option) *cobra.Command {
+	var (
+		patchFile, dst string
+		push           bool
+	)
+	cmd := &cobra.Command{
+		Use:   "edit IMAGE",
+		Short: "Apply a JSON Patch or JSON Merge Patch to the manifest of an image",
+		Long: `Apply a JSON Patch (RFC 6902) or JSON Merge Patch (RFC 7386) to the manifest of an image.
+
+A patch that is a JSON array is treated as a JSON Patch, and a patch that is a
+JSON object is treated as a JSON Merge Patch.
+
+Without --push, the patched manifest is printed. With --push, it is uploaded
+and the new reference is printed. Note that object keys in the patched manifest
+are sorted, so its digest will change even if the patch is a no-op.`,
+		Example: `  # Preview adding an annotation
+  echo '{"annotations":{"org.opencontainers.image.source":"https://example.com"}}' | crane manifest edit ubuntu --patch -
+
+  # Fix the mediaType field and push the result to a new tag
+  crane manifest edit ubuntu --patch fix.json --push --tag ubuntu:fixed`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if patchFile == "" {
+				return errors.New("--patch is required")
+			}
+			if dst != "" && !push {
+				return errors.New("--tag requires --push")
+			}
+			patch, err := readPatch(patchFile, cmd.InOrStdin())
+			if err != nil {
+				return err
+			}
+
+			src := args[0]
+			o := crane.GetOptions(*options...)
+			ref, err := name.ParseReference(src, o.Name...)
+			if err != nil {
+				return err
+			}
+			desc, err := remote.Get(ref, o.Remote...)
+			if err != nil {
+				return fmt.Errorf("fetching manifest %s: %w", src, err)
+			}
+			patched, err := jsonpatch.Apply(desc.Manifest, patch)
+			if err != nil {
+				return fmt.Errorf("patching manifest %s: %w", src, err)
+			}
+			if !push {
+				fmt.Fprintln(cmd.OutOrStdout(), string(patched))
+				return nil
+			}
+
+			// Prefer the (possibly patched) mediaType field, falling back to
+			// the original Content-Type.
+			mt := desc.MediaType
+			wmt := struct {
+				MediaType types.MediaType `json:"mediaType"`
+			}{}
+			if err := json.Unmarshal(patched, &wmt); err == nil && wmt.MediaType != "" {
+				mt = wmt.MediaType
+			}
+
+			digest, _, err := v1.SHA256(bytes.NewReader(patched))
+			if err != nil {
+				return err
+			}
+			var dstRef name.Reference = ref.Context().Digest(digest.String())
+			if dst != "" {
+				if dstRef, err = name.ParseReference(dst, o.Name...); err != nil {
+					return err
+				}
+			} else if _, ok := ref.(name.Tag); ok {
+				dstRef = ref
+			}
+
+			if err := remote.Put(dstRef, &patchedManifest{body: patched, mediaType: mt}, o.Remote...); err != nil {
+				return fmt.Errorf("pushing %s: %w", dstRef, err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), dstRef.Context().Digest(digest.String()))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&patchFile, "patch", "p", "", "Path to a JSON Patch or JSON Merge Patch file, or - for stdin")
+	cmd.Flags().BoolVar(&push, "push", false, "Push the patched manifest instead of printing it")
+	cmd.Flags().StringVarP(&dst, "tag", "t", "", "Tag to apply to the patched manifest. If not provided, uses the original tag, or pushes by digest if IMAGE was a digest.")
+	return cmd
+}
+
+func readPatch(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(path)
+}
+
+type patchedManifest struct {
+	body      []byte
+	mediaType types.MediaType
+}
+
+func (m *patchedManifest) RawManifest() ([]byte, error) {
+	return m.body, nil
+}
+
+func (m *patchedManifest) MediaType() (types.MediaType, error) {
+	return m.mediaType, nil
 }
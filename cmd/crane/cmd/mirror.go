@@ -0,0 +1,208 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultMirrorInterval is how often --watch reconciles a mirror whose config
+// doesn't say.
+const defaultMirrorInterval = time.Hour
+
+// mirrorConfig is the file read by crane mirror.
+type mirrorConfig struct {
+	Jobs     int           `yaml:"jobs"`
+	Interval time.Duration `yaml:"interval"`
+	Mirrors  []mirrorEntry `yaml:"mirrors"`
+}
+
+type mirrorEntry struct {
+	Source      string        `yaml:"source"`
+	Destination string        `yaml:"destination"`
+	Tags        []string      `yaml:"tags"`
+	Exclude     []string      `yaml:"exclude"`
+	Platforms   []string      `yaml:"platforms"`
+	Interval    time.Duration `yaml:"interval"`
+
+	mapping crane.MirrorMapping
+}
+
+// NewCmdMirror creates a new cobra.Command for the mirror subcommand.
+func NewCmdMirror(options *[]crane.Option) *cobra.Command {
+	var (
+		file  string
+		watch bool
+	)
+	cmd := &cobra.Command{
+		Use:   "mirror -f CONFIG",
+		Short: "Mirror repositories as declared in a config file",
+		Long: `Mirror repositories as declared in a config file.
+
+Each entry under mirrors copies the tags of a source repository to a destination
+repository. Tags that already match are skipped, and tags are never deleted from
+the destination. The copied tags are printed to stdout.
+
+With --watch, each entry is reconciled again every interval (by default ` + defaultMirrorInterval.String() + `)
+until crane is interrupted. Errors are logged rather than stopping the mirror.
+
+The config file is YAML:
+
+  jobs: 4          # tags copied at once per entry (default GOMAXPROCS)
+  interval: 1h     # how often --watch reconciles each entry
+  mirrors:
+  - source: docker.io/library/alpine
+    destination: registry.example.com/mirror/alpine
+    tags: ["3.*", latest]   # globs; all tags if empty
+    exclude: ["*-rc*"]      # globs for tags to skip
+    platforms: [linux/amd64, linux/arm64]  # filter indexes (see copy --index-platforms)
+    interval: 10m           # overrides the top-level interval`,
+		Example: `  # Mirror once
+  crane mirror -f mirror.yaml
+
+  # Keep mirroring until interrupted
+  crane mirror -f mirror.yaml --watch`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := loadMirrorConfig(file)
+			if err != nil {
+				return err
+			}
+			opts := append(*options, crane.WithJobs(cfg.Jobs))
+
+			var mu sync.Mutex
+			run := func(e mirrorEntry) error {
+				m := e.mapping
+				m.Source = source(cmd, m.Source)
+				copied, err := crane.Mirror(m, opts...)
+				mu.Lock()
+				defer mu.Unlock()
+				for _, tag := range copied {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s:%s\n", e.Destination, tag)
+				}
+				return err
+			}
+
+			if !watch {
+				var errs []error
+				for _, e := range cfg.Mirrors {
+					errs = append(errs, run(e))
+				}
+				return errors.Join(errs...)
+			}
+
+			var wg sync.WaitGroup
+			for _, e := range cfg.Mirrors {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					watchMirror(cmd.Context(), e, run)
+				}()
+			}
+			wg.Wait()
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to the mirror config file (- for stdin)")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep reconciling each mirror on its interval until interrupted")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+// watchMirror runs e every e.Interval until ctx is done.
+func watchMirror(ctx context.Context, e mirrorEntry, run func(mirrorEntry) error) {
+	t := time.NewTicker(e.Interval)
+	defer t.Stop()
+	for {
+		if err := run(e); err != nil {
+			logs.Warn.Printf("mirroring %s to %s: %v", e.Source, e.Destination, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func loadMirrorConfig(file string) (*mirrorConfig, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	cfg := &mirrorConfig{}
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	if len(cfg.Mirrors) == 0 {
+		return nil, fmt.Errorf("%s doesn't declare any mirrors", file)
+	}
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultMirrorInterval
+	}
+
+	for i := range cfg.Mirrors {
+		e := &cfg.Mirrors[i]
+		if e.Source == "" || e.Destination == "" {
+			return nil, fmt.Errorf("mirror %d: source and destination are required", i)
+		}
+		for _, p := range append(e.Tags, e.Exclude...) {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("mirror %d: invalid pattern %q: %w", i, p, err)
+			}
+		}
+		var platforms platformsValue
+		for _, p := range e.Platforms {
+			if err := platforms.Set(p); err != nil {
+				return nil, fmt.Errorf("mirror %d: %w", i, err)
+			}
+		}
+		if e.Interval < 0 || cfg.Interval < 0 {
+			return nil, fmt.Errorf("mirror %d: interval must be positive", i)
+		}
+		if e.Interval == 0 {
+			e.Interval = cfg.Interval
+		}
+		e.mapping = crane.MirrorMapping{
+			Source:      e.Source,
+			Destination: e.Destination,
+			Tags:        e.Tags,
+			Exclude:     e.Exclude,
+			Platforms:   platforms.platforms,
+		}
+	}
+	return cfg, nil
+}
@@ -39,6 +39,12 @@ func NewCmdMutate(options *[]crane.Option) *cobra.Command {
 	var user string
 	var workdir string
 	var ports []string
+	var volumes []string
+	var stopSignal string
+	var health v1.HealthConfig
+	var healthCmd string
+	var removeUser, removeWorkdir, removeStopSignal, removeHealthcheck bool
+	var removePorts, removeVolumes []string
 	var newPlatform string
 
 	mutateCmd := &cobra.Command{
@@ -46,11 +52,13 @@ func NewCmdMutate(options *[]crane.Option) *cobra.Command {
 		Short: "Modify image labels and annotations. The container must be pushed to a registry, and the manifest is updated there.",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(c *cobra.Command, args []string) error {
-			// Pull image and get config.
+			// Pull image and get config. It's written back to ref, not
+			// wherever ref is read from.
 			ref := args[0]
+			src := source(c, ref)
 
 			if len(annotations) != 0 {
-				desc, err := crane.Head(ref, *options...)
+				desc, err := crane.Head(src, *options...)
 				if err != nil {
 					return err
 				}
@@ -62,10 +70,24 @@ func NewCmdMutate(options *[]crane.Option) *cobra.Command {
 			if newRepo != "" && newRef != "" {
 				return errors.New("repository can't be set when a tag is specified")
 			}
+			for _, conflict := range [][2]string{
+				{"user", "remove-user"},
+				{"workdir", "remove-workdir"},
+				{"stop-signal", "remove-stop-signal"},
+				{"health-cmd", "remove-healthcheck"},
+				{"health-interval", "remove-healthcheck"},
+				{"health-timeout", "remove-healthcheck"},
+				{"health-start-period", "remove-healthcheck"},
+				{"health-retries", "remove-healthcheck"},
+			} {
+				if c.Flags().Changed(conflict[0]) && c.Flags().Changed(conflict[1]) {
+					return fmt.Errorf("--%s can't be used with --%s", conflict[0], conflict[1])
+				}
+			}
 
-			img, err := crane.Pull(ref, *options...)
+			img, err := crane.Pull(src, *options...)
 			if err != nil {
-				return fmt.Errorf("pulling %s: %w", ref, err)
+				return fmt.Errorf("pulling %s: %w", src, err)
 			}
 			if len(newLayers) != 0 {
 				img, err = crane.Append(img, newLayers...)
@@ -116,11 +138,17 @@ func NewCmdMutate(options *[]crane.Option) *cobra.Command {
 			if len(user) > 0 {
 				cfg.Config.User = user
 			}
+			if removeUser {
+				cfg.Config.User = ""
+			}
 
 			// Set workdir.
 			if len(workdir) > 0 {
 				cfg.Config.WorkingDir = workdir
 			}
+			if removeWorkdir {
+				cfg.Config.WorkingDir = ""
+			}
 
 			// Set ports
 			if len(ports) > 0 {
@@ -130,6 +158,38 @@ func NewCmdMutate(options *[]crane.Option) *cobra.Command {
 				}
 				cfg.Config.ExposedPorts = portMap
 			}
+			for _, port := range removePorts {
+				delete(cfg.Config.ExposedPorts, port)
+				if !strings.Contains(port, "/") {
+					// Ports without a protocol are TCP.
+					delete(cfg.Config.ExposedPorts, port+"/tcp")
+				}
+			}
+
+			// Set volumes.
+			for _, volume := range volumes {
+				if cfg.Config.Volumes == nil {
+					cfg.Config.Volumes = map[string]struct{}{}
+				}
+				cfg.Config.Volumes[volume] = struct{}{}
+			}
+			for _, volume := range removeVolumes {
+				delete(cfg.Config.Volumes, volume)
+			}
+
+			// Set stop signal.
+			if len(stopSignal) > 0 {
+				cfg.Config.StopSignal = stopSignal
+			}
+			if removeStopSignal {
+				cfg.Config.StopSignal = ""
+			}
+
+			// Set healthcheck.
+			setHealthcheck(c, cfg, healthCmd, health)
+			if removeHealthcheck {
+				cfg.Config.Healthcheck = nil
+			}
 
 			// Set platform
 			if len(newPlatform) > 0 {
@@ -196,11 +256,52 @@ func NewCmdMutate(options *[]crane.Option) *cobra.Command {
 	mutateCmd.Flags().StringVarP(&user, "user", "u", "", "New user to set")
 	mutateCmd.Flags().StringVarP(&workdir, "workdir", "w", "", "New working dir to set")
 	mutateCmd.Flags().StringSliceVar(&ports, "exposed-ports", nil, "New ports to expose")
+	mutateCmd.Flags().StringSliceVar(&volumes, "volume", nil, "New volumes to add")
+	mutateCmd.Flags().StringVar(&stopSignal, "stop-signal", "", "New stop signal to set (e.g. SIGINT)")
+	mutateCmd.Flags().StringVar(&healthCmd, "health-cmd", "", "New healthcheck command to set, run with the container's shell")
+	mutateCmd.Flags().DurationVar(&health.Interval, "health-interval", 0, "New time between healthchecks to set")
+	mutateCmd.Flags().DurationVar(&health.Timeout, "health-timeout", 0, "New maximum time for a healthcheck to set")
+	mutateCmd.Flags().DurationVar(&health.StartPeriod, "health-start-period", 0, "New time for the container to start before healthchecks count to set")
+	mutateCmd.Flags().IntVar(&health.Retries, "health-retries", 0, "New number of failed healthchecks before the container is unhealthy to set")
+	mutateCmd.Flags().BoolVar(&removeUser, "remove-user", false, "Remove the user, so the container runs as root")
+	mutateCmd.Flags().BoolVar(&removeWorkdir, "remove-workdir", false, "Remove the working dir")
+	mutateCmd.Flags().StringSliceVar(&removePorts, "remove-exposed-ports", nil, "Exposed ports to remove")
+	mutateCmd.Flags().StringSliceVar(&removeVolumes, "remove-volume", nil, "Volumes to remove")
+	mutateCmd.Flags().BoolVar(&removeStopSignal, "remove-stop-signal", false, "Remove the stop signal")
+	mutateCmd.Flags().BoolVar(&removeHealthcheck, "remove-healthcheck", false, "Remove the healthcheck")
 	// Using "set-platform" to avoid clobbering "platform" persistent flag.
 	mutateCmd.Flags().StringVar(&newPlatform, "set-platform", "", "New platform to set in the form os/arch[/variant][:osversion] (e.g. linux/amd64)")
 	return mutateCmd
 }
 
+// setHealthcheck sets the parts of cfg's healthcheck whose flags were given.
+func setHealthcheck(c *cobra.Command, cfg *v1.ConfigFile, cmd string, health v1.HealthConfig) {
+	flags := c.Flags()
+	if !flags.Changed("health-cmd") && !flags.Changed("health-interval") && !flags.Changed("health-timeout") &&
+		!flags.Changed("health-start-period") && !flags.Changed("health-retries") {
+		return
+	}
+	if cfg.Config.Healthcheck == nil {
+		cfg.Config.Healthcheck = &v1.HealthConfig{}
+	}
+	hc := cfg.Config.Healthcheck
+	if flags.Changed("health-cmd") {
+		hc.Test = []string{"CMD-SHELL", cmd}
+	}
+	if flags.Changed("health-interval") {
+		hc.Interval = health.Interval
+	}
+	if flags.Changed("health-timeout") {
+		hc.Timeout = health.Timeout
+	}
+	if flags.Changed("health-start-period") {
+		hc.StartPeriod = health.StartPeriod
+	}
+	if flags.Changed("health-retries") {
+		hc.Retries = health.Retries
+	}
+}
+
 // validateKeyVals ensures no values are empty, returns error if they are
 func validateKeyVals(kvPairs map[string]string) error {
 	for label, value := range kvPairs {
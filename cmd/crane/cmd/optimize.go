@@ -0,0 +1,56 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"runtime"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdOptimize creates a new cobra.Command for the optimize subcommand.
+func NewCmdOptimize(options *[]crane.Option) *cobra.Command {
+	var (
+		files  []string
+		format = string(crane.Estargz)
+		jobs   = runtime.GOMAXPROCS(0)
+	)
+	cmd := &cobra.Command{
+		Use:     "optimize SRC DST",
+		Aliases: []string{"opt"},
+		Short:   "Rewrite a remote image's layers so that it can be pulled lazily",
+		Long: `Rewrite the layers of a remote image or index as eStargz or zstd:chunked, and push it to DST.
+
+Runtimes that support these formats (e.g. stargz-snapshotter) can start containers
+from the optimized image before it has been fully pulled, fetching files as they
+are needed. The files passed to --prioritize are fetched first.`,
+		Example: `  # Optimize an image, fetching its entrypoint first
+  crane optimize ubuntu gcr.io/my-project/ubuntu:esgz --prioritize /bin/bash
+
+  # Use zstd:chunked, which also makes an index's images use OCI manifests
+  crane optimize ubuntu gcr.io/my-project/ubuntu:zstd --format zstd:chunked`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := append(*options, crane.WithJobs(jobs))
+			return crane.Optimize(source(cmd, args[0]), args[1], crane.OptimizeFormat(format), files, opts...)
+		},
+	}
+	cmd.Flags().StringSliceVar(&files, "prioritize", nil, "Files to place at the front of their layers, to be fetched first")
+	cmd.Flags().StringVar(&format, "format", format, "The format to rewrite layers into: estargz or zstd:chunked")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", jobs, "The maximum number of layers to rewrite at once")
+
+	return cmd
+}
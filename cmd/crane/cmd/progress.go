@@ -0,0 +1,256 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	// barInterval is how often progress bars are redrawn.
+	barInterval = 200 * time.Millisecond
+
+	// logInterval is how often progress is logged when bars aren't drawn.
+	logInterval = 5 * time.Second
+
+	barWidth = 30
+)
+
+// trackProgress returns options that report the progress of the blobs
+// written with them (see crane.WithProgress): as a bar with a rate and ETA
+// on stderr, if stdout and stderr are terminals and noBars is false, or else
+// as periodic log lines. The returned func stops reporting and must be called.
+func trackProgress(options []crane.Option, noBars bool) ([]crane.Option, func()) {
+	updates := make(chan v1.Update, 64)
+	p := newProgress(os.Stderr, !noBars && isTerminal(os.Stdout) && isTerminal(os.Stderr) && os.Getenv("TERM") != "dumb")
+	go p.run(updates)
+
+	opts := append(options[:len(options):len(options)], crane.WithProgress(updates))
+	return opts, p.close
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// progress reports on the updates it's sent.
+type progress struct {
+	mu     sync.Mutex
+	out    io.Writer
+	logOut io.Writer
+	bars   bool
+	start  time.Time
+
+	// latest is the most recent update, and started is whether there has
+	// been one.
+	latest  v1.Update
+	started bool
+
+	// drawn is whether the bar is on the screen.
+	drawn bool
+
+	// logged is how many bytes had been written at the last log line.
+	logged int64
+
+	stop, done chan struct{}
+	closeOnce  sync.Once
+}
+
+func newProgress(out io.Writer, bars bool) *progress {
+	p := &progress{
+		out:   out,
+		bars:  bars,
+		start: time.Now(),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	if bars {
+		// Log lines would otherwise be drawn over.
+		p.logOut = logs.Progress.Writer()
+		logs.Progress.SetOutput(p)
+	}
+	return p
+}
+
+// run reports on updates until p is closed. Some writers close updates when
+// they finish, and some don't.
+func (p *progress) run(updates <-chan v1.Update) {
+	defer close(p.done)
+	interval := logInterval
+	if p.bars {
+		interval = barInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case u, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			p.update(u)
+		case <-p.stop:
+			p.drain(updates)
+			p.report(true)
+			return
+		case <-ticker.C:
+			p.report(false)
+		}
+	}
+}
+
+// drain records the updates already sent, and then discards the rest so
+// nothing still writing blocks on them.
+func (p *progress) drain(updates <-chan v1.Update) {
+	for {
+		select {
+		case u, ok := <-updates:
+			if !ok {
+				return
+			}
+			p.update(u)
+		default:
+			if updates != nil {
+				go func() {
+					for range updates {
+					}
+				}()
+			}
+			return
+		}
+	}
+}
+
+// update records u. Updates that report errors (or the end of a tarball)
+// don't carry anything new.
+func (p *progress) update(u v1.Update) {
+	if u.Error != nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.latest, p.started = u, true
+}
+
+func (p *progress) close() {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		<-p.done
+		if p.bars {
+			logs.Progress.SetOutput(p.logOut)
+		}
+	})
+}
+
+// Write implements io.Writer, for logs.Progress, writing b above the bar.
+func (p *progress) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clear()
+	n, err := p.logOut.Write(b)
+	p.draw(false)
+	return n, err
+}
+
+func (p *progress) report(final bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.started {
+		return
+	}
+	if p.bars {
+		p.clear()
+		p.draw(final)
+		if final {
+			// Leave the bar where it is.
+			p.drawn = false
+		}
+		return
+	}
+	if p.latest.Complete == p.logged && !final {
+		return
+	}
+	p.logged = p.latest.Complete
+	logs.Progress.Print(p.summary(final))
+}
+
+// clear erases the bar, leaving the cursor where it started.
+func (p *progress) clear() {
+	if p.drawn {
+		fmt.Fprint(p.out, "\033[1A\033[2K")
+		p.drawn = false
+	}
+}
+
+func (p *progress) draw(final bool) {
+	if !p.bars || !p.started {
+		return
+	}
+	fmt.Fprintf(p.out, "%s %s\n", bar(p.latest.Complete, p.latest.Total), p.summary(final))
+	p.drawn = true
+}
+
+// summary describes the progress so far. The total only accounts for the
+// blobs that have started, so the ETA may grow.
+func (p *progress) summary(final bool) string {
+	complete, total := p.latest.Complete, p.latest.Total
+	elapsed := time.Since(p.start)
+	rate := float64(complete) / elapsed.Seconds()
+
+	var sb strings.Builder
+	if final {
+		fmt.Fprintf(&sb, "Transferred %s in %s", formatBytes(complete), elapsed.Round(time.Second))
+	} else {
+		fmt.Fprintf(&sb, "%s/%s", formatBytes(complete), formatBytes(total))
+	}
+	fmt.Fprintf(&sb, " at %s/s", formatBytes(int64(rate)))
+	if !final && rate > 0 && total > complete {
+		eta := time.Duration(float64(total-complete) / rate * float64(time.Second))
+		fmt.Fprintf(&sb, ", ETA %s", eta.Round(time.Second))
+	}
+	return sb.String()
+}
+
+func bar(complete, total int64) string {
+	filled := 0
+	if total > 0 {
+		filled = int(min(complete, total) * barWidth / total)
+	}
+	return "[" + strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled) + "]"
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
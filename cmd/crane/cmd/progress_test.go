@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestTrackProgress(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+
+	var buf bytes.Buffer
+	out := logs.Progress.Writer()
+	logs.Progress.SetOutput(&buf)
+	defer logs.Progress.SetOutput(out)
+
+	img, err := random.Image(4096, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, dst := host+"/progress/src", host+"/progress/dst"
+
+	for _, tc := range []struct {
+		name string
+		do   func(opts []crane.Option) error
+	}{{
+		// Push closes the channel when it's done.
+		name: "push",
+		do: func(opts []crane.Option) error {
+			return crane.Push(img, src, opts...)
+		},
+	}, {
+		// Copy doesn't.
+		name: "copy",
+		do: func(opts []crane.Option) error {
+			return crane.Copy(src, dst, opts...)
+		},
+	}, {
+		name: "save",
+		do: func(opts []crane.Option) error {
+			return crane.MultiSave(map[string]v1.Image{src: img}, filepath.Join(t.TempDir(), "img.tar"), opts...)
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			opts, stop := trackProgress(nil, true)
+			err := tc.do(opts)
+			stop()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); !strings.Contains(got, "Transferred ") || strings.Contains(got, "Transferred 0 B") {
+				t.Errorf("got %q, want a summary of the bytes transferred", got)
+			}
+		})
+	}
+}
+
+func TestProgressBars(t *testing.T) {
+	var bars, logged bytes.Buffer
+	out := logs.Progress.Writer()
+	logs.Progress.SetOutput(&logged)
+	defer logs.Progress.SetOutput(out)
+
+	p := newProgress(&bars, true)
+	updates := make(chan v1.Update)
+	go p.run(updates)
+	updates <- v1.Update{Total: 2048, Complete: 1024}
+	updates <- v1.Update{Error: errors.New("oops")}
+	p.report(false)
+	logs.Progress.Print("hello")
+	p.close()
+
+	if got := logged.String(); !strings.HasSuffix(got, "hello\n") {
+		t.Errorf("logged %q, want the log line", got)
+	}
+	got := bars.String()
+	for _, want := range []string{
+		"[" + strings.Repeat("=", barWidth/2) + strings.Repeat(" ", barWidth/2) + "] 1.0 KiB/2.0 KiB",
+		"\033[1A\033[2K",
+		"Transferred 1.0 KiB",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("drew %q, want %q", got, want)
+		}
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/layout"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 )
 
@@ -32,19 +33,30 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 	var (
 		cachePath, format string
 		annotateRef       bool
+		noProgress        bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "pull IMAGE TARBALL",
 		Short: "Pull remote images by reference and store their contents locally",
 		Args:  cobra.MinimumNArgs(2),
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			imageMap := map[string]v1.Image{}
 			indexMap := map[string]v1.ImageIndex{}
 			srcList, path := args[:len(args)-1], args[len(args)-1]
-			o := crane.GetOptions(*options...)
+			opts, stop := trackProgress(*options, noProgress)
+			defer stop()
+			o := crane.GetOptions(opts...)
 			for _, src := range srcList {
-				ref, err := name.ParseReference(src, o.Name...)
+				// The daemon has images under the names they were given.
+				if img, ok := crane.FromDaemon(src, opts...); ok {
+					imageMap[src] = img
+					continue
+				}
+
+				// Images are saved under the names they were asked for,
+				// even if they are pulled from a mirror.
+				ref, err := name.ParseReference(source(cmd, src), o.Name...)
 				if err != nil {
 					return fmt.Errorf("parsing reference %q: %w", src, err)
 				}
@@ -77,7 +89,7 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 
 			switch format {
 			case "tarball":
-				if err := crane.MultiSave(imageMap, path); err != nil {
+				if err := crane.MultiSave(imageMap, path, opts...); err != nil {
 					return fmt.Errorf("saving tarball %s: %w", path, err)
 				}
 			case "legacy":
@@ -101,7 +113,7 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 							return err
 						}
 						opts = append(opts, layout.WithAnnotations(map[string]string{
-							"org.opencontainers.image.ref.name": parsed.Name(),
+							imagespec.AnnotationRefName: parsed.Name(),
 						}))
 					}
 					if err = p.AppendImage(img, opts...); err != nil {
@@ -117,7 +129,7 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 							return err
 						}
 						opts = append(opts, layout.WithAnnotations(map[string]string{
-							"org.opencontainers.image.ref.name": parsed.Name(),
+							imagespec.AnnotationRefName: parsed.Name(),
 						}))
 					}
 					if err := p.AppendIndex(idx, opts...); err != nil {
@@ -133,6 +145,7 @@ func NewCmdPull(options *[]crane.Option) *cobra.Command {
 	cmd.Flags().StringVarP(&cachePath, "cache_path", "c", "", "Path to cache image layers")
 	cmd.Flags().StringVar(&format, "format", "tarball", fmt.Sprintf("Format in which to save images (%q, %q, or %q)", "tarball", "legacy", "oci"))
 	cmd.Flags().BoolVar(&annotateRef, "annotate-ref", false, "Preserves image reference used to pull as an annotation when used with --format=oci")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "(Optional) if true, log progress periodically instead of drawing progress bars (progress is only reported for --format=tarball)")
 
 	return cmd
 }
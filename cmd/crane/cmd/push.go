@@ -31,20 +31,23 @@ import (
 func NewCmdPush(options *[]crane.Option) *cobra.Command {
 	index := false
 	imageRefs := ""
+	noProgress := false
 	cmd := &cobra.Command{
 		Use:   "push PATH IMAGE",
 		Short: "Push local image contents to a remote registry",
-		Long:  `If the PATH is a directory, it will be read as an OCI image layout. Otherwise, PATH is assumed to be a docker-style tarball.`,
+		Long:  `If the PATH is a directory, it will be read as an OCI image layout. If it is an http or https URL, the docker-style tarball there is streamed from it. Otherwise, PATH is assumed to be a docker-style tarball.`,
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path, tag := args[0], args[1]
 
-			img, err := loadImage(path, index)
+			img, err := loadImage(path, index, *options...)
 			if err != nil {
 				return err
 			}
 
-			o := crane.GetOptions(*options...)
+			opts, stop := trackProgress(*options, noProgress)
+			defer stop()
+			o := crane.GetOptions(opts...)
 			ref, err := name.ParseReference(tag, o.Name...)
 			if err != nil {
 				return err
@@ -84,17 +87,18 @@ func NewCmdPush(options *[]crane.Option) *cobra.Command {
 	}
 	cmd.Flags().BoolVar(&index, "index", false, "push a collection of images as a single index, currently required if PATH contains multiple images")
 	cmd.Flags().StringVar(&imageRefs, "image-refs", "", "path to file where a list of the published image references will be written")
+	cmd.Flags().BoolVar(&noProgress, "no-progress", false, "(Optional) if true, log progress periodically instead of drawing progress bars")
 	return cmd
 }
 
-func loadImage(path string, index bool) (partial.WithRawManifest, error) {
-	stat, err := os.Stat(path)
-	if err != nil {
-		return nil, err
-	}
-
-	if !stat.IsDir() {
-		img, err := crane.Load(path)
+func loadImage(path string, index bool, opts ...crane.Option) (partial.WithRawManifest, error) {
+	// Anything that isn't a directory is a tarball, either on disk or at a
+	// URL, which crane.Load tells apart.
+	if stat, err := os.Stat(path); err != nil || !stat.IsDir() {
+		if index {
+			return nil, fmt.Errorf("--index requires %s to be an OCI layout", path)
+		}
+		img, err := crane.Load(path, opts...)
 		if err != nil {
 			return nil, fmt.Errorf("loading %s as tarball: %w", path, err)
 		}
@@ -24,18 +24,30 @@ import (
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
-	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/spf13/cobra"
 )
 
 // NewCmdRebase creates a new cobra.Command for the rebase subcommand.
 func NewCmdRebase(options *[]crane.Option) *cobra.Command {
 	var orig, oldBase, newBase, rebased string
+	var auto bool
 
 	rebaseCmd := &cobra.Command{
 		Use:   "rebase",
 		Short: "Rebase an image onto a new base image",
-		Args:  cobra.MinimumNArgs(1),
+		Long: `Rebase an image onto a new base image.
+
+If --old_base or --new_base are not given, they are read from the image's
+org.opencontainers.image.base.name and base.digest annotations, which are
+updated on the rebased image.
+
+With --auto, both are read from the annotations: the image is rebased from the
+recorded base digest onto whatever the recorded base name resolves to now, and
+nothing is pushed if that's the base the image already has.`,
+		Example: `  # Rebase onto the latest version of the base recorded in the image
+  crane rebase example.com/app:v1 --auto`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if orig == "" {
 				orig = args[0]
@@ -63,7 +75,12 @@ func NewCmdRebase(options *[]crane.Option) *cobra.Command {
 				log.Fatalf("parsing %s: %v", rebased, err)
 			}
 
-			desc, err := crane.Head(orig, *options...)
+			// Images are read from where the config file's rewrites say,
+			// but the rebased image is pushed to, and annotated with,
+			// the references as they were given.
+			src := func(ref string) string { return source(cmd, ref) }
+
+			desc, err := crane.Head(src(orig), *options...)
 			if err != nil {
 				log.Fatalf("checking %s: %v", orig, err)
 			}
@@ -71,34 +88,34 @@ func NewCmdRebase(options *[]crane.Option) *cobra.Command {
 				log.Fatalf("rebasing an index is not yet supported")
 			}
 
-			origImg, err := crane.Pull(orig, *options...)
-			if err != nil {
-				return err
-			}
-			origMf, err := origImg.Manifest()
+			origImg, err := crane.Pull(src(orig), *options...)
 			if err != nil {
 				return err
 			}
-			anns := origMf.Annotations
-			if newBase == "" && anns != nil {
-				newBase = anns[specsv1.AnnotationBaseImageName]
-			}
-			if newBase == "" {
-				return errors.New("could not determine new base image from annotations")
-			}
-			newBaseRef, err := name.ParseReference(newBase)
-			if err != nil {
-				return err
-			}
-			if oldBase == "" && anns != nil {
-				oldBaseDigest := anns[specsv1.AnnotationBaseImageDigest]
-				oldBase = newBaseRef.Context().Digest(oldBaseDigest).String()
-			}
-			if oldBase == "" {
-				return errors.New("could not determine old base image by digest from annotations")
-			}
-
-			rebasedImg, err := rebaseImage(origImg, oldBase, newBase, *options...)
+			if auto {
+				if oldBase != "" || newBase != "" {
+					return errors.New("--auto cannot be used with --old_base or --new_base")
+				}
+				base, baseDigest, err := mutate.BaseImage(origImg)
+				if err != nil {
+					return fmt.Errorf("--auto: %w", err)
+				}
+				latest, err := crane.Head(src(base), *options...)
+				if err != nil {
+					return fmt.Errorf("checking %s: %w", base, err)
+				}
+				if latest.Digest == baseDigest {
+					logs.Progress.Printf("%s is already based on the latest %s", orig, base)
+					origDigest, err := origImg.Digest()
+					if err != nil {
+						return err
+					}
+					fmt.Fprintln(cmd.OutOrStdout(), r.Context().Digest(origDigest.String()))
+					return nil
+				}
+			}
+
+			rebasedImg, err := rebaseImage(origImg, oldBase, newBase, src, *options...)
 			if err != nil {
 				return fmt.Errorf("rebasing image: %w", err)
 			}
@@ -132,6 +149,7 @@ func NewCmdRebase(options *[]crane.Option) *cobra.Command {
 	rebaseCmd.Flags().StringVar(&newBase, "new_base", "", "New base image to insert")
 	rebaseCmd.Flags().StringVar(&rebased, "rebased", "", "Tag to apply to rebased image (DEPRECATED: use --tag)")
 	rebaseCmd.Flags().StringVarP(&rebased, "tag", "t", "", "Tag to apply to rebased image")
+	rebaseCmd.Flags().BoolVar(&auto, "auto", false, "Rebase onto the current version of the base image recorded in the image's annotations")
 	return rebaseCmd
 }
 
@@ -142,44 +160,46 @@ func NewCmdRebase(options *[]crane.Option) *cobra.Command {
 // annotations in the original image. If those annotations are not found,
 // rebaseImage returns an error.
 //
+// The bases are read from src(ref), so that src can rewrite them.
+//
 // If rebasing is successful, base image annotations are set on the resulting
 // image to facilitate implicit rebasing next time.
-func rebaseImage(orig v1.Image, oldBase, newBase string, opt ...crane.Option) (v1.Image, error) {
+func rebaseImage(orig v1.Image, oldBase, newBase string, src func(string) string, opt ...crane.Option) (v1.Image, error) {
 	m, err := orig.Manifest()
 	if err != nil {
 		return nil, err
 	}
-	if newBase == "" && m.Annotations != nil {
-		newBase = m.Annotations[specsv1.AnnotationBaseImageName]
-		if newBase != "" {
-			logs.Debug.Printf("Detected new base from %q annotation: %s", specsv1.AnnotationBaseImageName, newBase)
-		}
+	// Either annotation may be missing, when the other base is given.
+	annotatedBase, annotatedDigest, err := v1.Annotations(m.Annotations).BaseImage()
+	if err != nil {
+		return nil, err
+	}
+	if newBase == "" && annotatedBase != "" {
+		newBase = annotatedBase
+		logs.Debug.Printf("Detected new base from %q annotation: %s", imagespec.AnnotationBaseImageName, newBase)
 	}
 	if newBase == "" {
-		return nil, fmt.Errorf("either new base or %q annotation is required", specsv1.AnnotationBaseImageName)
+		return nil, fmt.Errorf("either new base or %q annotation is required", imagespec.AnnotationBaseImageName)
 	}
-	newBaseImg, err := crane.Pull(newBase, opt...)
+	newBaseImg, err := crane.Pull(src(newBase), opt...)
 	if err != nil {
 		return nil, err
 	}
 
-	if oldBase == "" && m.Annotations != nil {
-		oldBase = m.Annotations[specsv1.AnnotationBaseImageDigest]
-		if oldBase != "" {
-			newBaseRef, err := name.ParseReference(newBase)
-			if err != nil {
-				return nil, err
-			}
-
-			oldBase = newBaseRef.Context().Digest(oldBase).String()
-			logs.Debug.Printf("Detected old base from %q annotation: %s", specsv1.AnnotationBaseImageDigest, oldBase)
+	if oldBase == "" && annotatedDigest != (v1.Hash{}) {
+		newBaseRef, err := name.ParseReference(newBase)
+		if err != nil {
+			return nil, err
 		}
+
+		oldBase = newBaseRef.Context().Digest(annotatedDigest.String()).String()
+		logs.Debug.Printf("Detected old base from %q annotation: %s", imagespec.AnnotationBaseImageDigest, oldBase)
 	}
 	if oldBase == "" {
-		return nil, fmt.Errorf("either old base or %q annotation is required", specsv1.AnnotationBaseImageDigest)
+		return nil, fmt.Errorf("either old base or %q annotation is required", imagespec.AnnotationBaseImageDigest)
 	}
 
-	oldBaseImg, err := crane.Pull(oldBase, opt...)
+	oldBaseImg, err := crane.Pull(src(oldBase), opt...)
 	if err != nil {
 		return nil, err
 	}
@@ -189,11 +209,11 @@ func rebaseImage(orig v1.Image, oldBase, newBase string, opt ...crane.Option) (v
 	// platform-specific image to rebase.
 	// crane.Digest will pull a platform-specific image, so use crane.Head
 	// here instead.
-	newBaseDesc, err := crane.Head(newBase, opt...)
+	newBaseDesc, err := crane.Head(src(newBase), opt...)
 	if err != nil {
 		return nil, err
 	}
-	newBaseDigest := newBaseDesc.Digest.String()
+	newBaseDigest := newBaseDesc.Digest
 
 	rebased, err := mutate.Rebase(orig, oldBaseImg, newBaseImg)
 	if err != nil {
@@ -201,10 +221,7 @@ func rebaseImage(orig v1.Image, oldBase, newBase string, opt ...crane.Option) (v
 	}
 
 	// Update base image annotations for the new image manifest.
-	logs.Debug.Printf("Setting annotation %q: %q", specsv1.AnnotationBaseImageDigest, newBaseDigest)
-	logs.Debug.Printf("Setting annotation %q: %q", specsv1.AnnotationBaseImageName, newBase)
-	return mutate.Annotations(rebased, map[string]string{
-		specsv1.AnnotationBaseImageDigest: newBaseDigest,
-		specsv1.AnnotationBaseImageName:   newBase,
-	}).(v1.Image), nil
+	logs.Debug.Printf("Setting annotation %q: %q", imagespec.AnnotationBaseImageDigest, newBaseDigest)
+	logs.Debug.Printf("Setting annotation %q: %q", imagespec.AnnotationBaseImageName, newBase)
+	return mutate.WithBaseImage(rebased, newBase, newBaseDigest), nil
 }
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestRebaseImageWithOnlyBaseName(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+
+	push := func(img v1.Image, ref string) {
+		t.Helper()
+		if err := crane.Push(img, ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldBase, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	push(oldBase, host+"/base:old")
+	newBase, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	push(newBase, host+"/base:latest")
+
+	top, err := random.Layer(1024, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := mutate.AppendLayers(oldBase, top)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The base's digest isn't recorded, so the old base has to be given.
+	orig = mutate.Annotations(orig, map[string]string{
+		imagespec.AnnotationBaseImageName: host + "/base:latest",
+	}).(v1.Image)
+
+	rebased, err := rebaseImage(orig, host+"/base:old", "", identity)
+	if err != nil {
+		t.Fatalf("rebaseImage: %v", err)
+	}
+	layers, err := rebased.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	newLayers, err := newBase.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 2 {
+		t.Fatalf("rebased image has %d layers, want 2", len(layers))
+	}
+	for i, want := range []v1.Layer{newLayers[0], top} {
+		got, err := layers[i].Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantDigest, err := want.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != wantDigest {
+			t.Errorf("layer %d = %s, want %s", i, got, wantDigest)
+		}
+	}
+
+	// Without the digest annotation or an old base, there's nothing to go on.
+	if _, err := rebaseImage(orig, "", "", identity); err == nil {
+		t.Error("rebaseImage without an old base: got nil, want an error")
+	}
+}
+
+func identity(ref string) string { return ref }
@@ -15,6 +15,7 @@
 package cmd
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"net/http"
@@ -24,12 +25,14 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/docker/cli/cli/config"
 	"github.com/google/go-containerregistry/internal/cmd"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"github.com/spf13/cobra"
 )
 
@@ -38,14 +41,24 @@ const (
 	short = "Crane is a tool for managing container images"
 )
 
-var Root = New(use, short, []crane.Option{})
+// Root is crane's top-level command. It is New plus the update command,
+// which replaces the running binary with crane, so isn't part of New, which
+// other binaries such as krane share.
+var Root = func() *cobra.Command {
+	root := New(use, short, []crane.Option{})
+	root.AddCommand(NewCmdUpdate("crane"))
+	return root
+}()
 
 // New returns a top-level command for crane. This is mostly exposed
 // to share code with gcrane.
 func New(use, short string, options []crane.Option) *cobra.Command {
 	verbose := false
 	insecure := false
+	insecureRegistries := []string{}
 	ndlayers := false
+	preferDaemon := false
+	authTimeout := time.Duration(0)
 	platform := &platformValue{}
 
 	wt := &warnTransport{}
@@ -56,7 +69,23 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 		RunE:              func(cmd *cobra.Command, _ []string) error { return cmd.Usage() },
 		DisableAutoGenTag: true,
 		SilenceUsage:      true,
-		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := settingsPath()
+			if err != nil {
+				return err
+			}
+			s, err := loadSettings(path)
+			if err != nil {
+				return err
+			}
+			// Commands apply the rewrites to the references they read; see source.
+			cmd.SetContext(context.WithValue(cmd.Context(), settingsKey{}, s))
+			if s.Platform != "" && !cmd.Flags().Changed("platform") {
+				if err := platform.Set(s.Platform); err != nil {
+					return err
+				}
+			}
+
 			options = append(options, crane.WithContext(cmd.Context()))
 			// TODO(jonjohnsonjr): crane.Verbose option?
 			if verbose {
@@ -68,6 +97,15 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 			if ndlayers {
 				options = append(options, crane.WithNondistributable())
 			}
+			if preferDaemon {
+				options = append(options, crane.WithPreferDaemon())
+			}
+			if authTimeout != 0 {
+				if authTimeout < 0 {
+					return fmt.Errorf("--auth-timeout must be positive, got %v", authTimeout)
+				}
+				options = append(options, crane.WithAuthTimeout(authTimeout))
+			}
 			if Version != "" {
 				binary := "crane"
 				if len(os.Args[0]) != 0 {
@@ -78,12 +116,27 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 
 			options = append(options, crane.WithPlatform(platform.platform))
 
-			transport := remote.DefaultTransport.(*http.Transport).Clone()
-			transport.TLSClientConfig = &tls.Config{
+			if dir := os.Getenv("CRANE_CACHE"); dir != "" {
+				options = append(options, crane.WithLayerCache(dir))
+			} else if s.Cache != "" {
+				options = append(options, crane.WithLayerCache(s.Cache))
+			}
+			if len(s.CredentialHelpers) != 0 {
+				kc := crane.GetOptions(options...).Keychain
+				options = append(options, crane.WithAuthFromKeychain(s.keychain(kc)))
+			}
+
+			base := remote.DefaultTransport.(*http.Transport).Clone()
+			base.TLSClientConfig = &tls.Config{
 				InsecureSkipVerify: insecure, //nolint: gosec
 			}
 
-			var rt http.RoundTripper = transport
+			var rt http.RoundTripper = base
+			if regs := append(insecureRegistries, s.InsecureRegistries...); len(regs) != 0 {
+				if rt, err = transport.NewInsecureRegistries(base, regs...); err != nil {
+					return err
+				}
+			}
 
 			// Add any http headers if they are set in the config file.
 			cf, err := config.Load(os.Getenv("DOCKER_CONFIG"))
@@ -101,6 +154,7 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 			rt = wt
 
 			options = append(options, crane.WithTransport(rt))
+			return nil
 		},
 		PersistentPostRun: func(_ *cobra.Command, _ []string) {
 			wt.Report() // Report any collected warnings.
@@ -108,34 +162,47 @@ func New(use, short string, options []crane.Option) *cobra.Command {
 	}
 
 	root.AddCommand(
+		NewCmdAnnotateIndex(&options),
 		NewCmdAppend(&options),
+		NewCmdArtifact(&options),
 		NewCmdAuth(options, "crane", "auth"),
 		NewCmdBlob(&options),
 		NewCmdCatalog(&options, "crane"),
+		NewCmdCleanTags(&options),
 		NewCmdConfig(&options),
 		NewCmdCopy(&options),
 		NewCmdDelete(&options),
 		NewCmdDigest(&options),
+		NewCmdDigests(&options),
 		cmd.NewCmdEdit(&options),
 		NewCmdExport(&options),
 		NewCmdFlatten(&options),
 		NewCmdIndex(&options),
+		NewCmdInspect(&options),
 		NewCmdList(&options),
 		NewCmdManifest(&options),
+		NewCmdMirror(&options),
 		NewCmdMutate(&options),
+		NewCmdOptimize(&options),
 		NewCmdPull(&options),
 		NewCmdPush(&options),
 		NewCmdRebase(&options),
+		NewCmdSBOM(&options),
+		NewCmdSettings(),
 		NewCmdTag(&options),
 		NewCmdValidate(&options),
+		NewCmdVerifyCopy(&options),
 		NewCmdVersion(),
 		NewCmdRegistry(),
-		NewCmdLayout(),
+		NewCmdLayout(&options),
 	)
 
 	root.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable debug logs")
 	root.PersistentFlags().BoolVar(&insecure, "insecure", false, "Allow image references to be fetched without TLS")
+	root.PersistentFlags().StringSliceVar(&insecureRegistries, "insecure-registry", nil, "Skip TLS verification for these registries only, which may contain wildcards (e.g. *.internal)")
 	root.PersistentFlags().BoolVar(&ndlayers, "allow-nondistributable-artifacts", false, "Allow pushing non-distributable (foreign) layers")
+	root.PersistentFlags().BoolVar(&preferDaemon, "prefer-daemon", false, "Read images from the local docker daemon when it has them, instead of pulling them (only for commands that read whole images, e.g. pull, export, append, mutate and rebase)")
+	root.PersistentFlags().DurationVar(&authTimeout, "auth-timeout", 0, "How long to wait for credentials for each registry, e.g. from a credential helper, before failing (e.g. 30s)")
 	root.PersistentFlags().Var(platform, "platform", "Specifies the platform in the form os/arch[/variant][:osversion] (e.g. linux/amd64).")
 
 	return root
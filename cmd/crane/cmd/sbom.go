@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdSBOM creates a new cobra.Command for the sbom subcommand.
+func NewCmdSBOM(options *[]crane.Option) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sbom",
+		Short: "Attach or fetch SBOMs stored as OCI referrers of an image.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Usage()
+		},
+	}
+	cmd.AddCommand(NewCmdSBOMAttach(options), NewCmdSBOMGet(options))
+	return cmd
+}
+
+// NewCmdSBOMAttach creates a new cobra.Command for the sbom attach subcommand.
+func NewCmdSBOMAttach(options *[]crane.Option) *cobra.Command {
+	var file, sbomType string
+
+	cmd := &cobra.Command{
+		Use:   "attach IMAGE",
+		Short: "Attach an SBOM to an image as an OCI referrer artifact.",
+		Example: `  # Attach an SPDX SBOM to an image
+  crane sbom attach example.com/app:v1 --file sbom.spdx.json --type spdx
+
+  # Attach an SBOM with an arbitrary media type
+  crane sbom attach example.com/app:v1 --file sbom.json --type application/vnd.example.sbom+json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if file == "" {
+				return errors.New("--file is required")
+			}
+			mt, err := crane.SBOMMediaType(sbomType)
+			if err != nil {
+				return err
+			}
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", file, err)
+			}
+			ref, err := crane.AttachSBOM(args[0], b, mt, *options...)
+			if err != nil {
+				return fmt.Errorf("attaching SBOM to %s: %w", args[0], err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), ref)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Path to the SBOM file to attach")
+	cmd.Flags().StringVar(&sbomType, "type", "spdx", "SBOM format (spdx, cyclonedx, syft) or a media type")
+
+	return cmd
+}
+
+// NewCmdSBOMGet creates a new cobra.Command for the sbom get subcommand.
+func NewCmdSBOMGet(options *[]crane.Option) *cobra.Command {
+	var sbomType string
+	var list bool
+
+	cmd := &cobra.Command{
+		Use:   "get IMAGE",
+		Short: "Fetch an SBOM attached to an image.",
+		Example: `  # Print the SBOM attached to an image
+  crane sbom get example.com/app:v1
+
+  # List the digests of all attached CycloneDX SBOMs
+  crane sbom get example.com/app:v1 --type cyclonedx --list`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var mt types.MediaType
+			if sbomType != "" {
+				var err error
+				mt, err = crane.SBOMMediaType(sbomType)
+				if err != nil {
+					return err
+				}
+			}
+			if list {
+				sboms, err := crane.SBOMs(source(cmd, args[0]), mt, *options...)
+				if err != nil {
+					return err
+				}
+				for _, desc := range sboms {
+					fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", desc.Digest, desc.ArtifactType)
+				}
+				return nil
+			}
+			b, err := crane.SBOM(source(cmd, args[0]), mt, *options...)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(b)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&sbomType, "type", "", "Only consider SBOMs of this format (spdx, cyclonedx, syft) or media type")
+	cmd.Flags().BoolVar(&list, "list", false, "List attached SBOMs instead of printing one")
+
+	return cmd
+}
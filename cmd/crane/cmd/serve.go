@@ -37,8 +37,8 @@ func NewCmdRegistry() *cobra.Command {
 }
 
 func newCmdServe() *cobra.Command {
-	var address, disk string
-	var blobsToDisk bool
+	var address, disk, durability string
+	var blobsToDisk, sharded bool
 	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Serve a registry implementation",
@@ -82,7 +82,20 @@ Contents are can be stored in memory (when the process exits, pushed data is los
 
 			if diskp != "" {
 				log.Printf("storing blobs in %s", diskp)
-				bh = registry.NewDiskBlobHandler(diskp)
+				opts := []registry.DiskOption{}
+				if sharded {
+					opts = append(opts, registry.WithSharding())
+				}
+				switch durability {
+				case "none":
+				case "file":
+					opts = append(opts, registry.WithDurability(registry.DurabilityFile))
+				case "full":
+					opts = append(opts, registry.WithDurability(registry.DurabilityFull))
+				default:
+					return fmt.Errorf("--disk-durability must be none, file or full, got %q", durability)
+				}
+				bh = registry.NewDiskBlobHandler(diskp, opts...)
 			}
 
 			s := &http.Server{
@@ -111,6 +124,8 @@ Contents are can be stored in memory (when the process exits, pushed data is los
 	cmd.Flags().MarkHidden("blobs-to-disk")
 	cmd.Flags().MarkDeprecated("blobs-to-disk", "and will stop working in a future release. use --disk=$(mktemp -d) instead.")
 	cmd.Flags().StringVarP(&disk, "disk", "", "", "Path to a directory where blobs will be stored")
+	cmd.Flags().BoolVar(&sharded, "disk-sharded", false, "Store blobs on disk in sharded directories (sha256/ab/cd/abcd...)")
+	cmd.Flags().StringVar(&durability, "disk-durability", "none", "How blobs on disk are synced: none, file (sync each blob before it is visible) or full (also sync its directory)")
 	cmd.Flags().StringVar(&address, "address", "", "Address to listen on")
 
 	return cmd
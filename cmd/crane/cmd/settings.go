@@ -0,0 +1,321 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// NewCmdSettings creates a new cobra.Command for the settings subcommand.
+func NewCmdSettings() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "settings",
+		Short: "View or change crane's config file",
+		Long: `View or change crane's config file.
+
+The config file lives at $CRANE_CONFIG, or crane/config.yaml under
+$XDG_CONFIG_HOME (~/.config by default), and sets defaults for flags that
+would otherwise be repeated, e.g.:
+
+  platform: linux/arm64
+  insecure-registries: [registry.local:5000]
+  rewrites:
+    docker.io/library/: mirror.gcr.io/library/
+  cache: /var/cache/crane
+  credential-helpers:
+    us-docker.pkg.dev: gcloud
+
+Flags take precedence over the config file.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, _ []string) {
+			cmd.Usage()
+		},
+	}
+	cmd.AddCommand(newCmdSettingsView(), newCmdSettingsSet())
+	return cmd
+}
+
+// These manage the config file, which may be what's broken, so they skip
+// root's PersistentPreRunE, which loads it.
+func skipSettings(*cobra.Command, []string) error { return nil }
+
+func newCmdSettingsView() *cobra.Command {
+	return &cobra.Command{
+		Use:               "view",
+		Short:             "Print crane's config file",
+		Args:              cobra.NoArgs,
+		PersistentPreRunE: skipSettings,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			path, err := settingsPath()
+			if err != nil {
+				return err
+			}
+			s, err := loadSettings(path)
+			if err != nil {
+				return err
+			}
+			b, err := yaml.Marshal(s)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "# %s\n%s", path, b)
+			return nil
+		},
+	}
+}
+
+func newCmdSettingsSet() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Set a value in crane's config file",
+		Long: fmt.Sprintf(`Set a value in crane's config file. An empty VALUE unsets KEY.
+
+KEY is one of %s.`, strings.Join(settingsKeys, ", ")),
+		Example: `  # Pull images for arm64 by default
+  crane settings set platform linux/arm64
+
+  # Pull Docker Hub images from a mirror
+  crane settings set rewrites.docker.io/library/ mirror.gcr.io/library/
+
+  # Stop using a credential helper
+  crane settings set credential-helpers.gcr.io ""`,
+		Args:              cobra.ExactArgs(2),
+		PersistentPreRunE: skipSettings,
+		RunE: func(_ *cobra.Command, args []string) error {
+			path, err := settingsPath()
+			if err != nil {
+				return err
+			}
+			s, err := loadSettings(path)
+			if err != nil {
+				return err
+			}
+			if err := s.set(args[0], args[1]); err != nil {
+				return err
+			}
+			return s.save(path)
+		},
+	}
+}
+
+// settings are the defaults that crane reads from its config file, so that
+// they needn't be passed as flags every time.
+type settings struct {
+	// Platform is the default for --platform.
+	Platform string `yaml:"platform,omitempty"`
+
+	// InsecureRegistries are registries whose TLS certificates aren't
+	// verified, like --insecure-registry.
+	InsecureRegistries []string `yaml:"insecure-registries,omitempty"`
+
+	// Rewrites maps prefixes of image references to their replacements,
+	// e.g. "docker.io/library/" to "mirror.gcr.io/library/". They apply to
+	// the references that commands read from, as they are written, but not
+	// to those they push to; the longest matching prefix wins.
+	Rewrites map[string]string `yaml:"rewrites,omitempty"`
+
+	// Cache is a directory to cache layers in, like $CRANE_CACHE.
+	Cache string `yaml:"cache,omitempty"`
+
+	// CredentialHelpers maps registries to the credential helper to get
+	// their credentials from, e.g. "gcr.io" to "gcloud", which runs
+	// docker-credential-gcloud. Other registries use the usual keychain.
+	CredentialHelpers map[string]string `yaml:"credential-helpers,omitempty"`
+}
+
+// settingsKeys are the keys that `crane settings set` understands. Keys of
+// maps are followed by a dot and the map key.
+var settingsKeys = []string{"platform", "insecure-registries", "rewrites.PREFIX", "cache", "credential-helpers.REGISTRY"}
+
+// settingsPath returns where the config file is: $CRANE_CONFIG, or
+// config.yaml in the crane directory under $XDG_CONFIG_HOME or ~/.config.
+func settingsPath() (string, error) {
+	if p := os.Getenv("CRANE_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "crane", "config.yaml"), nil
+}
+
+// loadSettings reads the config file at path. It is not an error for it not
+// to exist.
+func loadSettings(path string) (*settings, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &settings{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	s := &settings{}
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(s); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if s.Platform != "" {
+		if _, err := parsePlatform(s.Platform); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+	return s, nil
+}
+
+// save writes s to path, creating its directory if needed.
+func (s *settings) save(path string) error {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// set sets key to value, or unsets it if value is empty.
+func (s *settings) set(key, value string) error {
+	name, sub, isMap := strings.Cut(key, ".")
+	switch {
+	case name == "rewrites" && sub != "":
+		setMapKey(&s.Rewrites, sub, value)
+	case name == "credential-helpers" && sub != "":
+		setMapKey(&s.CredentialHelpers, sub, value)
+	case isMap:
+		return fmt.Errorf("unknown key %q, want one of %s", key, strings.Join(settingsKeys, ", "))
+	case name == "platform":
+		if value != "" {
+			if _, err := parsePlatform(value); err != nil {
+				return err
+			}
+		}
+		s.Platform = value
+	case name == "insecure-registries":
+		s.InsecureRegistries = nil
+		if value != "" {
+			s.InsecureRegistries = strings.Split(value, ",")
+		}
+	case name == "cache":
+		s.Cache = value
+	default:
+		return fmt.Errorf("unknown key %q, want one of %s", key, strings.Join(settingsKeys, ", "))
+	}
+	return nil
+}
+
+func setMapKey(m *map[string]string, key, value string) {
+	if value == "" {
+		delete(*m, key)
+		return
+	}
+	if *m == nil {
+		*m = map[string]string{}
+	}
+	(*m)[key] = value
+}
+
+// settingsKey is the context key that the root command stores the loaded
+// settings under, for source.
+type settingsKey struct{}
+
+// source returns ref as rewritten by the config file, for commands to call
+// on the references they read from. References that are pushed to, tagged or
+// deleted aren't rewritten, so that a pull mirror never receives them.
+func source(cmd *cobra.Command, ref string) string {
+	ctx := cmd.Context()
+	if ctx == nil {
+		return ref
+	}
+	s, ok := ctx.Value(settingsKey{}).(*settings)
+	if !ok {
+		return ref
+	}
+	return s.rewrite(ref)
+}
+
+// rewrite applies s.Rewrites to ref.
+func (s *settings) rewrite(ref string) string {
+	best := ""
+	for from := range s.Rewrites {
+		if strings.HasPrefix(ref, from) && len(from) > len(best) {
+			best = from
+		}
+	}
+	if best == "" {
+		return ref
+	}
+	out := s.Rewrites[best] + strings.TrimPrefix(ref, best)
+	logs.Debug.Printf("Rewrote %s to %s", ref, out)
+	return out
+}
+
+// keychain returns a keychain that uses s.CredentialHelpers for the
+// registries they list, and inner for any others.
+func (s *settings) keychain(inner authn.Keychain) authn.Keychain {
+	if len(s.CredentialHelpers) == 0 {
+		return inner
+	}
+	kc := helperKeychain{inner: inner, helpers: map[string]authn.Keychain{}}
+	for reg, helper := range s.CredentialHelpers {
+		kc.helpers[registryStr(reg)] = authn.NewExternalHelperKeychain(helper)
+	}
+	return kc
+}
+
+// registryStr returns reg as the registry of an image reference would have it,
+// e.g. index.docker.io for docker.io.
+func registryStr(reg string) string {
+	r, err := name.NewRegistry(reg)
+	if err != nil {
+		return reg
+	}
+	return r.RegistryStr()
+}
+
+type helperKeychain struct {
+	inner   authn.Keychain
+	helpers map[string]authn.Keychain
+}
+
+func (kc helperKeychain) Resolve(r authn.Resource) (authn.Authenticator, error) {
+	return kc.ResolveContext(context.Background(), r)
+}
+
+func (kc helperKeychain) ResolveContext(ctx context.Context, r authn.Resource) (authn.Authenticator, error) {
+	if h, ok := kc.helpers[r.RegistryStr()]; ok {
+		return authn.Resolve(ctx, h, r)
+	}
+	return authn.Resolve(ctx, kc.inner, r)
+}
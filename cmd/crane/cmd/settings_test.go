@@ -0,0 +1,173 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// runCrane runs crane with args, returning its stdout.
+func runCrane(t *testing.T, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	root := New("crane", "", nil)
+	root.SetArgs(args)
+	root.SetOut(&out)
+	if err := root.ExecuteContext(context.Background()); err != nil {
+		t.Fatalf("crane %s: %v", strings.Join(args, " "), err)
+	}
+	return out.String()
+}
+
+func TestSettingsRewrites(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+
+	push := func(img v1.Image, ref string) {
+		t.Helper()
+		if err := crane.Push(img, ref); err != nil {
+			t.Fatal(err)
+		}
+	}
+	oldBase, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newBase, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, err := random.Layer(1024, "application/vnd.oci.image.layer.v1.tar+gzip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, err := mutate.AppendLayers(oldBase, layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := random.Index(1024, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	push(oldBase, host+"/base:old")
+	push(newBase, host+"/base:new")
+	push(app, host+"/app:v1")
+	idxRef, err := name.ParseReference(host + "/idx:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(idxRef, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Everything is read from mirror.invalid, which only exists by way of
+	// the rewrite.
+	dir := t.TempDir()
+	t.Setenv("CRANE_CONFIG", filepath.Join(dir, "config.yaml"))
+	runCrane(t, "settings", "set", "rewrites.mirror.invalid/", host+"/")
+	if got := runCrane(t, "settings", "view"); !strings.Contains(got, "mirror.invalid/: "+host+"/") {
+		t.Errorf("settings view: got %q, want the rewrite", got)
+	}
+
+	var empty bytes.Buffer
+	if err := tar.NewWriter(&empty).Close(); err != nil {
+		t.Fatal(err)
+	}
+	emptyLayer := filepath.Join(dir, "layer.tar")
+	if err := os.WriteFile(emptyLayer, empty.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	mirrorConfig := filepath.Join(dir, "mirror.yaml")
+	if err := os.WriteFile(mirrorConfig, []byte(fmt.Sprintf("mirrors:\n- source: mirror.invalid/app\n  destination: %s/out/mirror\n", host)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		args []string
+		dst  string
+	}{
+		{args: []string{"config", "mirror.invalid/app:v1"}},
+		{args: []string{"append", "--base", "mirror.invalid/app:v1", "-f", emptyLayer, "-t", host + "/out/append"}, dst: host + "/out/append"},
+		{args: []string{"mutate", "mirror.invalid/app:v1", "-l", "a=b", "-t", host + "/out/mutate"}, dst: host + "/out/mutate"},
+		{args: []string{"flatten", "mirror.invalid/app:v1", "-t", host + "/out/flatten"}, dst: host + "/out/flatten"},
+		{args: []string{"rebase", "mirror.invalid/app:v1", "--old_base", "mirror.invalid/base:old", "--new_base", "mirror.invalid/base:new", "-t", host + "/out/rebase"}, dst: host + "/out/rebase"},
+		{args: []string{"index", "append", "mirror.invalid/idx:v1", "-m", "mirror.invalid/app:v1", "-t", host + "/out/index"}, dst: host + "/out/index"},
+		{args: []string{"mirror", "-f", mirrorConfig}, dst: host + "/out/mirror:v1"},
+	} {
+		t.Run(tc.args[0], func(t *testing.T) {
+			runCrane(t, tc.args...)
+			if tc.dst == "" {
+				return
+			}
+			if _, err := crane.Head(tc.dst); err != nil {
+				t.Errorf("crane %s didn't push %s: %v", tc.args[0], tc.dst, err)
+			}
+		})
+	}
+
+	// The daemon is asked for images by the names they were given. It has
+	// one that the registry doesn't.
+	tag, err := name.NewTag("mirror.invalid/daemon:v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved bytes.Buffer
+	if err := tarball.Write(tag, app, &saved); err != nil {
+		t.Fatal(err)
+	}
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/images/"+tag.Name()+"/json":
+			fmt.Fprint(w, "{}")
+		case r.URL.Path == "/images/get" && r.URL.Query().Get("names") == tag.Name():
+			w.Write(saved.Bytes())
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer daemon.Close()
+	t.Setenv("DOCKER_HOST", "tcp://"+strings.TrimPrefix(daemon.URL, "http://"))
+	runCrane(t, "pull", "--prefer-daemon", "--no-progress", tag.String(), filepath.Join(dir, "daemon.tar"))
+
+	// The rebased image records its base as it was given, not rewritten.
+	m, err := crane.Manifest(host + "/out/rebase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "mirror.invalid/base:new"; !strings.Contains(string(m), want) {
+		t.Errorf("rebased manifest %s doesn't name its base %s", m, want)
+	}
+}
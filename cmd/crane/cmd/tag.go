@@ -15,13 +15,22 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 // NewCmdTag creates a new cobra.Command for the tag subcommand.
 func NewCmdTag(options *[]crane.Option) *cobra.Command {
-	return &cobra.Command{
+	var retagAll string
+	var dryRun bool
+
+	cmd := &cobra.Command{
 		Use:   "tag IMG TAG",
 		Short: "Efficiently tag a remote image",
 		Long: `Tag remote image without downloading it.
@@ -34,13 +43,91 @@ crane cp registry.example.com/library/ubuntu:v0 registry.example.com/library/ubu
 crane tag registry.example.com/library/ubuntu:v0 v1
 ` + "```" + `
 
-2. We can skip layer existence checks because we know the manifest already exists. This makes "tag" slightly faster than "copy".`,
+2. We can skip layer existence checks because we know the manifest already exists. This makes "tag" slightly faster than "copy".
+
+With --retag-all, the tags to add are read from a YAML or JSON file ("-" for stdin) instead:
+` + "```" + `
+retags:
+- source: registry.example.com/app:rc1
+  destination: v1 # a tag in the source's repository
+- source: registry.example.com/app:rc1
+  destination: registry.example.com/release/app:v1
+` + "```" + `
+
+Destinations must be on the same registry as their sources. Across repositories, blobs are mounted
+(or uploaded, if the registry won't mount them), destinations that already point at the source are
+skipped, and the tags that were added are printed. With --dry-run, the tags that would be added are printed and nothing is pushed.`,
 		Example: `# Add a v1 tag to ubuntu
-crane tag ubuntu v1`,
-		Args: cobra.ExactArgs(2),
-		RunE: func(_ *cobra.Command, args []string) error {
+crane tag ubuntu v1
+
+# Preview, then promote, a release
+crane tag --retag-all release.yaml --dry-run
+crane tag --retag-all release.yaml`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if retagAll != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			if dryRun {
+				return errors.New("--dry-run requires --retag-all")
+			}
+			return cobra.ExactArgs(2)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if retagAll != "" {
+				retags, err := loadRetags(retagAll)
+				if err != nil {
+					return err
+				}
+				tagged, err := crane.RetagAll(retags, dryRun, *options...)
+				for _, t := range tagged {
+					fmt.Fprintln(cmd.OutOrStdout(), t)
+				}
+				return err
+			}
 			img, tag := args[0], args[1]
 			return crane.Tag(img, tag, *options...)
 		},
 	}
+	cmd.Flags().StringVar(&retagAll, "retag-all", "", "(Optional) YAML or JSON file of retags to apply, or - for stdin")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "(Optional) With --retag-all, print the tags that would be added without pushing them")
+	return cmd
+}
+
+type retagConfig struct {
+	Retags []struct {
+		Source      string `yaml:"source"`
+		Destination string `yaml:"destination"`
+	} `yaml:"retags"`
+}
+
+// loadRetags reads the --retag-all file.
+func loadRetags(file string) ([]crane.Retag, error) {
+	var r io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	cfg := &retagConfig{}
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	if len(cfg.Retags) == 0 {
+		return nil, fmt.Errorf("%s doesn't declare any retags", file)
+	}
+
+	retags := make([]crane.Retag, 0, len(cfg.Retags))
+	for i, e := range cfg.Retags {
+		if e.Source == "" || e.Destination == "" {
+			return nil, fmt.Errorf("retag %d: source and destination are required", i)
+		}
+		retags = append(retags, crane.Retag{Source: e.Source, Destination: e.Destination})
+	}
+	return retags, nil
 }
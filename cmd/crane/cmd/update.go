@@ -0,0 +1,362 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/semver"
+)
+
+// UpdateKey is the public key that the checksums of releases are signed
+// with, as base64-encoded PKIX DER, for the update subcommand. It can be set
+// via:
+// -ldflags="-X 'github.com/google/go-containerregistry/cmd/crane/cmd.UpdateKey=$KEY'"
+var UpdateKey string
+
+const (
+	releasesURL = "https://api.github.com/repos/google/go-containerregistry/releases"
+	// The archives of each release are listed, with their SHA-256 digests,
+	// in checksumsFile, which is signed by the key in UpdateKey.
+	checksumsFile = "checksums.txt"
+)
+
+// NewCmdUpdate creates a new cobra.Command for the update subcommand, which
+// replaces the running binary, named binary in release archives.
+func NewCmdUpdate(binary string) *cobra.Command {
+	var version string
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: fmt.Sprintf("Update %s to the latest release", binary),
+		Long: fmt.Sprintf(`Update %[1]s to the latest release, or the one given by --version.
+
+The release archive for this platform is downloaded from GitHub and checked against the
+release's %[2]s, whose signature is verified with the public key built into %[1]s.
+The running %[1]s is then replaced with the one from the archive. Builds without a
+key, e.g. from source, can't update themselves.`, binary, checksumsFile),
+		Example: fmt.Sprintf(`  # See if there is a newer release
+  %[1]s update --check
+
+  # Update to the latest release
+  %[1]s update
+
+  # Install a particular release
+  %[1]s update --version v0.20.2`, binary),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			out := cmd.OutOrStdout()
+
+			rel, err := getRelease(ctx, version)
+			if err != nil {
+				return err
+			}
+			if check {
+				fmt.Fprintln(out, checkMessage(binary, Version, rel.TagName))
+				return nil
+			}
+			if version == "" && compareVersions(Version, rel.TagName) >= 0 {
+				fmt.Fprintf(out, "%s %s is up to date\n", binary, Version)
+				return nil
+			}
+
+			key, err := updateKey()
+			if err != nil {
+				return err
+			}
+			checksums, err := rel.download(ctx, checksumsFile)
+			if err != nil {
+				return err
+			}
+			sig, err := rel.download(ctx, checksumsFile+".sig")
+			if err != nil {
+				return err
+			}
+			if err := verifyChecksums(key, checksums, sig); err != nil {
+				return err
+			}
+
+			archive := releaseArchive(runtime.GOOS, runtime.GOARCH)
+			want, err := findChecksum(checksums, archive)
+			if err != nil {
+				return err
+			}
+			b, err := rel.download(ctx, archive)
+			if err != nil {
+				return err
+			}
+			h := crypto.SHA256.New()
+			h.Write(b)
+			if got := hex.EncodeToString(h.Sum(nil)); got != want {
+				return fmt.Errorf("%s: sha256 is %s, but %s says %s", archive, got, checksumsFile, want)
+			}
+
+			exe := binary
+			if runtime.GOOS == "windows" {
+				exe += ".exe"
+			}
+			bin, err := extractFile(b, exe)
+			if err != nil {
+				return fmt.Errorf("%s: %w", archive, err)
+			}
+			path, err := replaceExecutable(bin)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "Updated %s from %s to %s\n", path, Version, rel.TagName)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&version, "version", "", "(Optional) Release to install, e.g. v0.20.2, instead of the latest")
+	cmd.Flags().BoolVar(&check, "check", false, "(Optional) if true, only print whether there is a newer release")
+
+	return cmd
+}
+
+// compareVersions compares the semantic versions current and latest, with
+// or without a leading "v", as semver.Compare does. It returns -2 if current
+// isn't a valid semantic version, e.g. "(devel)" for a build from source.
+func compareVersions(current, latest string) int {
+	current, latest = "v"+strings.TrimPrefix(current, "v"), "v"+strings.TrimPrefix(latest, "v")
+	if !semver.IsValid(current) {
+		return -2
+	}
+	return semver.Compare(current, latest)
+}
+
+// checkMessage describes how the running binary's version, current, compares
+// with latest, the latest release.
+func checkMessage(binary, current, latest string) string {
+	switch compareVersions(current, latest) {
+	case -2:
+		return fmt.Sprintf("%s %q isn't a release, the latest release is %s", binary, current, latest)
+	case -1:
+		return fmt.Sprintf("%s %s is available, this is %s", binary, latest, current)
+	case 0:
+		return fmt.Sprintf("%s %s is up to date", binary, current)
+	default:
+		return fmt.Sprintf("%s %s is newer than the latest release, %s", binary, current, latest)
+	}
+}
+
+type release struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// getRelease returns the release tagged version, or the latest release.
+func getRelease(ctx context.Context, version string) (*release, error) {
+	u := releasesURL + "/latest"
+	if version != "" {
+		u = releasesURL + "/tags/" + version
+	}
+	b, err := httpGet(ctx, u)
+	if err != nil {
+		return nil, fmt.Errorf("finding release: %w", err)
+	}
+	rel := &release{}
+	if err := json.Unmarshal(b, rel); err != nil {
+		return nil, fmt.Errorf("parsing release: %w", err)
+	}
+	return rel, nil
+}
+
+// download returns the contents of the release asset called name.
+func (r *release) download(ctx context.Context, name string) ([]byte, error) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			b, err := httpGet(ctx, a.URL)
+			if err != nil {
+				return nil, fmt.Errorf("downloading %s: %w", name, err)
+			}
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no %s", r.TagName, name)
+}
+
+func httpGet(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// updateKey parses UpdateKey.
+func updateKey() (*ecdsa.PublicKey, error) {
+	if UpdateKey == "" {
+		return nil, errors.New("this build has no key to verify releases with; download one from https://github.com/google/go-containerregistry/releases instead")
+	}
+	der, err := base64.StdEncoding.DecodeString(UpdateKey)
+	if err != nil {
+		return nil, fmt.Errorf("decoding UpdateKey: %w", err)
+	}
+	pub, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("parsing UpdateKey: %w", err)
+	}
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("UpdateKey is a %T, not an ECDSA key", pub)
+	}
+	return key, nil
+}
+
+// verifyChecksums checks sig, a base64-encoded ASN.1 ECDSA signature of the
+// SHA-256 digest of checksums, as `cosign sign-blob` writes.
+func verifyChecksums(key *ecdsa.PublicKey, checksums, sig []byte) error {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("decoding signature of %s: %w", checksumsFile, err)
+	}
+	h := crypto.SHA256.New()
+	h.Write(checksums)
+	if !ecdsa.VerifyASN1(key, h.Sum(nil), raw) {
+		return fmt.Errorf("the signature of %s doesn't match, refusing to update", checksumsFile)
+	}
+	return nil
+}
+
+// releaseArchive returns the name of the release archive for goos and
+// goarch, as .goreleaser.yml names them.
+func releaseArchive(goos, goarch string) string {
+	switch goarch {
+	case "amd64":
+		goarch = "x86_64"
+	case "386":
+		goarch = "i386"
+	case "arm":
+		goarch = "armv6"
+	}
+	return fmt.Sprintf("go-containerregistry_%s%s_%s.tar.gz", strings.ToUpper(goos[:1]), goos[1:], goarch)
+}
+
+// findChecksum returns the hex SHA-256 digest of name in checksums, which
+// has a "DIGEST  NAME" line for each file.
+func findChecksum(checksums []byte, name string) (string, error) {
+	s := bufio.NewScanner(bytes.NewReader(checksums))
+	for s.Scan() {
+		if fields := strings.Fields(s.Text()); len(fields) == 2 && fields[1] == name {
+			return fields[0], nil
+		}
+	}
+	if err := s.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s has no checksum for %s; is there a release for this platform?", checksumsFile, name)
+}
+
+// extractFile returns the contents of the file called name in the gzipped
+// tarball b.
+func extractFile(b []byte, name string) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no %s in archive", name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+// replaceExecutable atomically replaces the running executable with bin,
+// returning its path.
+func replaceExecutable(bin []byte) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return "", err
+	}
+	fi, err := os.Stat(exe)
+	if err != nil {
+		return "", err
+	}
+
+	// Write next to exe, so that renaming over it is atomic.
+	f, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".new-*")
+	if err != nil {
+		return "", fmt.Errorf("can't write next to %s: %w", exe, err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(bin); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(f.Name(), fi.Mode().Perm()); err != nil {
+		return "", err
+	}
+
+	if runtime.GOOS == "windows" {
+		// Running executables can't be replaced on Windows, but can be
+		// renamed out of the way.
+		old := exe + ".old"
+		os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return "", err
+		}
+	}
+	if err := os.Rename(f.Name(), exe); err != nil {
+		return "", err
+	}
+	return exe, nil
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+)
+
+// sign signs b as `cosign sign-blob` does.
+func sign(t *testing.T, key *ecdsa.PrivateKey, b []byte) []byte {
+	t.Helper()
+	h := crypto.SHA256.New()
+	h.Write(b)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, h.Sum(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(base64.StdEncoding.EncodeToString(sig) + "\n")
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksums := []byte("abc123  go-containerregistry_Linux_x86_64.tar.gz\n")
+	sig := sign(t, key, checksums)
+
+	for _, tc := range []struct {
+		desc      string
+		key       *ecdsa.PublicKey
+		checksums []byte
+		sig       []byte
+		wantErr   bool
+	}{{
+		desc:      "good signature",
+		key:       &key.PublicKey,
+		checksums: checksums,
+		sig:       sig,
+	}, {
+		desc:      "tampered checksums",
+		key:       &key.PublicKey,
+		checksums: []byte("def456  go-containerregistry_Linux_x86_64.tar.gz\n"),
+		sig:       sig,
+		wantErr:   true,
+	}, {
+		desc:      "wrong key",
+		key:       &other.PublicKey,
+		checksums: checksums,
+		sig:       sig,
+		wantErr:   true,
+	}, {
+		desc:      "signature isn't base64",
+		key:       &key.PublicKey,
+		checksums: checksums,
+		sig:       []byte("not base64!"),
+		wantErr:   true,
+	}, {
+		desc:      "empty signature",
+		key:       &key.PublicKey,
+		checksums: checksums,
+		wantErr:   true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := verifyChecksums(tc.key, tc.checksums, tc.sig)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("verifyChecksums() = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindChecksum(t *testing.T) {
+	checksums := []byte(`1111  go-containerregistry_Linux_x86_64.tar.gz
+2222  go-containerregistry_Linux_arm64.tar.gz
+3333 4444  go-containerregistry_Darwin_arm64.tar.gz
+
+5555  go-containerregistry_Windows_x86_64.tar.gz
+`)
+	for _, tc := range []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "go-containerregistry_Linux_x86_64.tar.gz", want: "1111"},
+		{name: "go-containerregistry_Linux_arm64.tar.gz", want: "2222"},
+		{name: "go-containerregistry_Windows_x86_64.tar.gz", want: "5555"},
+		// Malformed lines don't match.
+		{name: "go-containerregistry_Darwin_arm64.tar.gz", wantErr: true},
+		// Nor do prefixes of names.
+		{name: "go-containerregistry_Linux_x86", wantErr: true},
+		{name: "go-containerregistry_Linux_s390x.tar.gz", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := findChecksum(checksums, tc.name)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("findChecksum() = %v, wantErr %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("findChecksum() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+// TestReleaseArchive checks releaseArchive against the archive
+// name_template in .goreleaser.yml:
+//
+//	{{ .ProjectName }}_{{ title .Os }}_<x86_64 for amd64, i386 for 386, or .Arch>{{ if .Arm }}v{{ .Arm }}{{ end }}
+//
+// where .Arm is goreleaser's default GOARM, 6.
+func TestReleaseArchive(t *testing.T) {
+	for _, tc := range []struct {
+		goos, goarch string
+		want         string
+	}{
+		{"linux", "amd64", "go-containerregistry_Linux_x86_64.tar.gz"},
+		{"linux", "arm64", "go-containerregistry_Linux_arm64.tar.gz"},
+		{"linux", "arm", "go-containerregistry_Linux_armv6.tar.gz"},
+		{"linux", "386", "go-containerregistry_Linux_i386.tar.gz"},
+		{"linux", "s390x", "go-containerregistry_Linux_s390x.tar.gz"},
+		{"linux", "ppc64le", "go-containerregistry_Linux_ppc64le.tar.gz"},
+		{"darwin", "arm64", "go-containerregistry_Darwin_arm64.tar.gz"},
+		{"windows", "amd64", "go-containerregistry_Windows_x86_64.tar.gz"},
+		{"windows", "arm", "go-containerregistry_Windows_armv6.tar.gz"},
+	} {
+		if got := releaseArchive(tc.goos, tc.goarch); got != tc.want {
+			t.Errorf("releaseArchive(%s, %s) = %s, want %s", tc.goos, tc.goarch, got, tc.want)
+		}
+	}
+}
+
+// archive returns a gzipped tarball of files, with a directory entry.
+func archive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	if err := tw.WriteHeader(&tar.Header{Name: "crane/", Typeflag: tar.TypeDir, Mode: 0o755}); err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0o755, Size: int64(len(contents))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCheckMessage(t *testing.T) {
+	for _, tc := range []struct {
+		current, latest string
+		want            string
+	}{
+		{"v0.20.2", "v0.20.2", "crane v0.20.2 is up to date"},
+		{"0.20.2", "v0.20.2", "crane 0.20.2 is up to date"},
+		{"v0.20.1", "v0.20.2", "crane v0.20.2 is available, this is v0.20.1"},
+		{"v0.9.0", "v0.20.2", "crane v0.20.2 is available, this is v0.9.0"},
+		{"v0.21.0", "v0.20.2", "crane v0.21.0 is newer than the latest release, v0.20.2"},
+		{"v0.20.3-0.20260101000000-abcdef123456", "v0.20.2", "crane v0.20.3-0.20260101000000-abcdef123456 is newer than the latest release, v0.20.2"},
+		{"(devel)", "v0.20.2", `crane "(devel)" isn't a release, the latest release is v0.20.2`},
+		{"", "v0.20.2", `crane "" isn't a release, the latest release is v0.20.2`},
+	} {
+		if got := checkMessage("crane", tc.current, tc.latest); got != tc.want {
+			t.Errorf("checkMessage(%q, %q) = %q, want %q", tc.current, tc.latest, got, tc.want)
+		}
+	}
+}
+
+func TestExtractFile(t *testing.T) {
+	b := archive(t, map[string]string{
+		"LICENSE":        "license",
+		"bin/crane":      "crane binary",
+		"gcrane":         "gcrane binary",
+		"crane.exe.sha1": "not it",
+	})
+
+	for _, tc := range []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		// The directory called crane is skipped for the file.
+		{name: "crane", want: "crane binary"},
+		{name: "gcrane", want: "gcrane binary"},
+		{name: "crane.exe", wantErr: true},
+		{name: "krane", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := extractFile(b, tc.name)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("extractFile() = %v, wantErr %t", err, tc.wantErr)
+			}
+			if string(got) != tc.want {
+				t.Errorf("extractFile() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := extractFile([]byte("not gzip"), "crane"); err == nil {
+		t.Error("extractFile(not gzip) = nil, want an error")
+	}
+}
@@ -15,6 +15,7 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -28,12 +29,16 @@ func (ps *platformsValue) Set(platform string) error {
 	if ps.platforms == nil {
 		ps.platforms = []v1.Platform{}
 	}
-	p, err := parsePlatform(platform)
-	if err != nil {
-		return err
+	for _, platform := range strings.Split(platform, ",") {
+		p, err := parsePlatform(platform)
+		if err != nil {
+			return err
+		}
+		if p == nil {
+			return fmt.Errorf("platform %q must name specific platforms", platform)
+		}
+		ps.platforms = append(ps.platforms, *p)
 	}
-	pv := platformValue{p}
-	ps.platforms = append(ps.platforms, *pv.platform)
 	return nil
 }
 
@@ -18,6 +18,7 @@ import (
 	"fmt"
 
 	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/validate"
 	"github.com/spf13/cobra"
@@ -26,24 +27,60 @@ import (
 // NewCmdValidate creates a new cobra.Command for the validate subcommand.
 func NewCmdValidate(options *[]crane.Option) *cobra.Command {
 	var (
-		tarballPath, remoteRef string
-		fast                   bool
+		tarballPath, remoteRef  string
+		fast, strict, streaming bool
 	)
 
 	validateCmd := &cobra.Command{
-		Use:   "validate",
+		Use:   "validate [REF]",
 		Short: "Validate that an image is well-formed",
-		Args:  cobra.NoArgs,
-		RunE: func(cmd *cobra.Command, _ []string) error {
+		Long: `Validate that an image is well-formed.
+
+With REF (or --remote), the remote image or index is downloaded and every
+digest, diffID, size and manifest linkage is verified, recursing into the
+children of an index. --fast skips downloading and digesting layer contents.`,
+		Example: `  # Verify every blob of a remote image or index
+  crane validate gcr.io/example/image:tag
+
+  # Only check that the manifests, config and layer sizes line up
+  crane validate --fast gcr.io/example/image:tag
+
+  # Validate an image tarball
+  crane validate --tarball image.tar`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if remoteRef != "" {
+					return fmt.Errorf("cannot use both REF and --remote")
+				}
+				remoteRef = source(cmd, args[0])
+			}
+			if tarballPath == "" && remoteRef == "" {
+				return fmt.Errorf("one of REF, --remote or --tarball is required")
+			}
+
+			opt := []validate.Option{}
+			if fast {
+				opt = append(opt, validate.Fast)
+			}
+			if strict {
+				opt = append(opt, validate.Strict)
+			}
+			if streaming {
+				opt = append(opt, validate.Streaming, validate.WithLayerResults(func(r validate.LayerResult) {
+					if r.Err != nil {
+						logs.Progress.Printf("layer[%d] %s: FAIL", r.Index, r.Digest)
+						return
+					}
+					logs.Progress.Printf("layer[%d] %s: OK", r.Index, r.Digest)
+				}))
+			}
+
 			if tarballPath != "" {
 				img, err := tarball.ImageFromPath(tarballPath, nil)
 				if err != nil {
 					return fmt.Errorf("failed to read image %s: %w", tarballPath, err)
 				}
-				opt := []validate.Option{}
-				if fast {
-					opt = append(opt, validate.Fast)
-				}
 				if err := validate.Image(img, opt...); err != nil {
 					fmt.Fprintf(cmd.OutOrStdout(), "FAIL: %s: %v\n", tarballPath, err)
 					return err
@@ -59,10 +96,6 @@ func NewCmdValidate(options *[]crane.Option) *cobra.Command {
 
 				o := crane.GetOptions(*options...)
 
-				opt := []validate.Option{}
-				if fast {
-					opt = append(opt, validate.Fast)
-				}
 				if rmt.MediaType.IsIndex() && o.Platform == nil {
 					idx, err := rmt.ImageIndex()
 					if err != nil {
@@ -89,8 +122,10 @@ func NewCmdValidate(options *[]crane.Option) *cobra.Command {
 		},
 	}
 	validateCmd.Flags().StringVar(&tarballPath, "tarball", "", "Path to tarball to validate")
-	validateCmd.Flags().StringVar(&remoteRef, "remote", "", "Name of remote image to validate")
+	validateCmd.Flags().StringVar(&remoteRef, "remote", "", "Name of remote image to validate (same as REF)")
 	validateCmd.Flags().BoolVar(&fast, "fast", false, "Skip downloading/digesting layers")
+	validateCmd.Flags().BoolVar(&streaming, "streaming", false, "Read each layer once with bounded memory, reporting progress as each layer is validated")
+	validateCmd.Flags().BoolVar(&strict, "strict", false, "Also validate OCI manifests, indexes and config files against the OCI JSON schemas")
 
 	return validateCmd
 }
@@ -0,0 +1,57 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/spf13/cobra"
+)
+
+// NewCmdVerifyCopy creates a new cobra.Command for the verify-copy subcommand.
+func NewCmdVerifyCopy(options *[]crane.Option) *cobra.Command {
+	checkBlobs := 0
+	cmd := &cobra.Command{
+		Use:   "verify-copy SRC DST",
+		Short: "Check that DST is a faithful copy of SRC",
+		Long: `Check that DST is a faithful copy of SRC.
+
+Walks the manifest trees of both, comparing the digests, sizes and media types of manifests,
+configs and layers, and checking that the blobs DST refers to exist. Differences are printed
+one per line, and the command fails if there are any.`,
+		Example: `  # Verify a mirrored image, downloading 2 blobs of each image to check their contents
+  crane verify-copy --check-blobs=2 ubuntu registry.example.com/mirror/ubuntu`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, dst := source(cmd, args[0]), args[1]
+			found, err := crane.VerifyCopy(src, dst, checkBlobs, *options...)
+			if err != nil {
+				return err
+			}
+			for _, d := range found {
+				fmt.Fprintln(cmd.OutOrStdout(), d)
+			}
+			if len(found) != 0 {
+				return fmt.Errorf("%s differs from %s in %d ways", dst, src, len(found))
+			}
+			logs.Progress.Printf("%s is a faithful copy of %s", dst, src)
+			return nil
+		},
+	}
+	cmd.Flags().IntVar(&checkBlobs, "check-blobs", 0, "Number of blobs of each image to download from DST and check against their digests, or -1 for all")
+	return cmd
+}
@@ -16,10 +16,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 
 	"github.com/google/go-containerregistry/cmd/crane/cmd"
+	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/logs"
 )
 
@@ -32,6 +34,11 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 	if err := cmd.Root.ExecuteContext(ctx); err != nil {
+		if errors.Is(err, authn.ErrHelperFailed) {
+			// Rather than fall back to anonymous access, which would fail in
+			// more confusing ways, point at the broken configuration.
+			logs.Warn.Printf("A credential helper failed. Check that it is installed and logged in, and the credsStore and credHelpers in ~/.docker/config.json (or $DOCKER_CONFIG/config.json).")
+		}
 		cancel()
 		os.Exit(1)
 	}
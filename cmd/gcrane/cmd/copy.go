@@ -15,6 +15,7 @@
 package cmd
 
 import (
+	"errors"
 	"runtime"
 
 	"github.com/google/go-containerregistry/pkg/gcrane"
@@ -25,6 +26,7 @@ import (
 func NewCmdCopy() *cobra.Command {
 	recursive := false
 	jobs := 1
+	var include, exclude []string
 	cmd := &cobra.Command{
 		Use:     "copy SRC DST",
 		Aliases: []string{"cp"},
@@ -34,13 +36,24 @@ func NewCmdCopy() *cobra.Command {
 			src, dst := args[0], args[1]
 			ctx := cc.Context()
 			if recursive {
-				return gcrane.CopyRepository(ctx, src, dst, gcrane.WithJobs(jobs), gcrane.WithUserAgent(userAgent()), gcrane.WithContext(ctx))
+				return gcrane.CopyRepository(ctx, src, dst,
+					gcrane.WithJobs(jobs),
+					gcrane.WithUserAgent(userAgent()),
+					gcrane.WithContext(ctx),
+					gcrane.WithInclude(include...),
+					gcrane.WithExclude(exclude...),
+				)
+			}
+			if len(include) != 0 || len(exclude) != 0 {
+				return errors.New("--include and --exclude require --recursive")
 			}
 			return gcrane.Copy(src, dst, gcrane.WithUserAgent(userAgent()), gcrane.WithContext(ctx))
 		},
 	}
 
 	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Whether to recurse through repos")
+	cmd.Flags().StringSliceVar(&include, "include", nil, "With --recursive, only copy repositories (or repo:tag) matching these globs, e.g. gcr.io/proj/team-*")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "With --recursive, skip repositories (or repo:tag) matching these globs; takes precedence over --include")
 	cmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.GOMAXPROCS(0), "The maximum number of concurrent copies")
 
 	return cmd
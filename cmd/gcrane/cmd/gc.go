@@ -16,32 +16,88 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/gcrane"
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/google"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/spf13/cobra"
 )
 
 // NewCmdGc creates a new cobra.Command for the gc subcommand.
 func NewCmdGc() *cobra.Command {
 	recursive := false
+	var (
+		policy    gcrane.RetentionPolicy
+		tagFilter string
+		dryRun    bool
+		del       bool
+	)
 	cmd := &cobra.Command{
 		Use:   "gc",
-		Short: "List images that are not tagged",
-		Args:  cobra.ExactArgs(1),
+		Short: "List or delete images that are not tagged",
+		Long: `List images that are not tagged, or that a retention policy doesn't keep.
+
+By default, every untagged image is garbage. --keep, --tag-filter and --older-than
+change which images are kept. Images that are referenced by an index that is kept
+are always kept, too.
+
+The garbage is printed, one reference per line. With --dry-run, the size, upload
+time and tags of each image are printed as well. With --delete, the images (and
+their tags) are deleted from the registry.`,
+		Example: `  # List untagged images
+  gcrane gc gcr.io/project/app
+
+  # See what a cleanup job would delete: anything more than 30 days old, apart
+  # from the 10 newest images and images with release tags
+  gcrane gc -r gcr.io/project --keep 10 --older-than 720h --tag-filter '^(pr|dev)-' --dry-run`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cc *cobra.Command, args []string) error {
-			return gc(cc.Context(), args[0], recursive)
+			if dryRun && del {
+				return errors.New("--dry-run and --delete are mutually exclusive")
+			}
+			if tagFilter != "" {
+				re, err := regexp.Compile(tagFilter)
+				if err != nil {
+					return fmt.Errorf("parsing --tag-filter: %w", err)
+				}
+				policy.TagFilter = re
+			}
+			g := &collector{ctx: cc.Context(), policy: policy, dryRun: dryRun, delete: del}
+			return g.gc(args[0], recursive)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Whether to recurse through repos")
+	cmd.Flags().IntVar(&policy.Keep, "keep", 0, "Keep this many of the most recently uploaded images in each repo, tagged or not")
+	cmd.Flags().StringVar(&tagFilter, "tag-filter", "", "Regular expression; tagged images are garbage if every one of their tags matches it")
+	cmd.Flags().DurationVar(&policy.OlderThan, "older-than", 0, "Keep images uploaded more recently than this (e.g. 720h)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the size, upload time and tags of each image that would be deleted")
+	cmd.Flags().BoolVar(&del, "delete", false, "Delete the garbage, rather than just printing it")
 
 	return cmd
 }
 
-func gc(ctx context.Context, root string, recursive bool) error {
+// collector lists (and maybe deletes) the garbage in repositories.
+type collector struct {
+	ctx    context.Context
+	policy gcrane.RetentionPolicy
+	dryRun bool
+	delete bool
+
+	freed int64
+}
+
+func (g *collector) gc(root string, recursive bool) error {
 	repo, err := name.NewRepository(root)
 	if err != nil {
 		return err
@@ -50,27 +106,138 @@ func gc(ctx context.Context, root string, recursive bool) error {
 	opts := []google.Option{
 		google.WithAuthFromKeychain(gcrane.Keychain),
 		google.WithUserAgent(userAgent()),
-		google.WithContext(ctx),
+		google.WithContext(g.ctx),
 	}
 
 	if recursive {
-		return google.Walk(repo, printUntaggedImages, opts...)
+		err = google.Walk(repo, g.collect, opts...)
+	} else {
+		tags, lerr := google.List(repo, opts...)
+		err = g.collect(repo, tags, lerr)
 	}
+	if g.dryRun {
+		fmt.Printf("total\t%d\n", g.freed)
+	}
+	return err
+}
 
-	tags, err := google.List(repo, opts...)
-	return printUntaggedImages(repo, tags, err)
+func (g *collector) remoteOptions() []remote.Option {
+	return []remote.Option{
+		remote.WithAuthFromKeychain(gcrane.Keychain),
+		remote.WithUserAgent(userAgent()),
+		remote.WithContext(g.ctx),
+	}
 }
 
-func printUntaggedImages(repo name.Repository, tags *google.Tags, err error) error {
+func (g *collector) collect(repo name.Repository, tags *google.Tags, err error) error {
 	if err != nil {
 		return err
 	}
 
-	for digest, manifest := range tags.Manifests {
-		if len(manifest.Tags) == 0 {
-			fmt.Printf("%s@%s\n", repo, digest)
+	garbage := g.policy.Garbage(tags)
+	if len(garbage) == 0 {
+		return nil
+	}
+	kept, err := g.keptChildren(repo, tags, garbage)
+	if err != nil {
+		return err
+	}
+
+	if g.delete {
+		// Delete indexes before their children, which registries may refuse
+		// to delete while an index refers to them.
+		sort.SliceStable(garbage, func(i, j int) bool {
+			return types.MediaType(tags.Manifests[garbage[i]].MediaType).IsIndex() &&
+				!types.MediaType(tags.Manifests[garbage[j]].MediaType).IsIndex()
+		})
+	}
+
+	for _, digest := range garbage {
+		if kept[digest] {
+			continue
+		}
+		m := tags.Manifests[digest]
+		ref := repo.Digest(digest)
+		switch {
+		case g.dryRun:
+			g.freed += int64(m.Size)
+			fmt.Printf("%s\t%d\t%s\t%s\n", ref, m.Size, m.Uploaded.UTC().Format(time.RFC3339), strings.Join(m.Tags, ","))
+		case g.delete:
+			if err := g.deleteImage(ref, m.Tags); err != nil {
+				return err
+			}
+			fmt.Println(ref)
+		default:
+			fmt.Println(ref)
+		}
+	}
+
+	return nil
+}
+
+// keptChildren returns the digests of the descendants of the indexes in tags
+// that aren't garbage, including the children of nested indexes.
+func (g *collector) keptChildren(repo name.Repository, tags *google.Tags, garbage []string) (map[string]bool, error) {
+	isGarbage := make(map[string]bool, len(garbage))
+	for _, digest := range garbage {
+		isGarbage[digest] = true
+	}
+
+	kept := map[string]bool{}
+	for digest, m := range tags.Manifests {
+		if isGarbage[digest] || !types.MediaType(m.MediaType).IsIndex() {
+			continue
+		}
+		idx, err := remote.Index(repo.Digest(digest), g.remoteOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		if err := keepChildren(idx, kept); err != nil {
+			return nil, err
 		}
 	}
+	return kept, nil
+}
 
+// keepChildren adds the digests of idx's children to kept, recursing into
+// the children that are themselves indexes.
+func keepChildren(idx v1.ImageIndex, kept map[string]bool) error {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, child := range im.Manifests {
+		if kept[child.Digest.String()] {
+			continue
+		}
+		kept[child.Digest.String()] = true
+		if !child.MediaType.IsIndex() {
+			continue
+		}
+		childIdx, err := idx.ImageIndex(child.Digest)
+		if err != nil {
+			return err
+		}
+		if err := keepChildren(childIdx, kept); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteImage deletes ref's tags, which GCR and Artifact Registry require
+// before the manifest itself can be deleted, and then ref.
+func (g *collector) deleteImage(ref name.Digest, tags []string) error {
+	for _, tag := range tags {
+		t := ref.Context().Tag(tag)
+		logs.Progress.Printf("Deleting %s", t)
+		if err := remote.Delete(t, g.remoteOptions()...); err != nil {
+			return fmt.Errorf("deleting %s: %w", t, err)
+		}
+	}
+	logs.Progress.Printf("Deleting %s", ref)
+	if err := remote.Delete(ref, g.remoteOptions()...); err != nil {
+		return fmt.Errorf("deleting %s: %w", ref, err)
+	}
 	return nil
 }
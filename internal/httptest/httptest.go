@@ -37,6 +37,36 @@ import (
 func NewTLSServer(domain string, handler http.Handler) (*httptest.Server, error) {
 	s := httptest.NewUnstartedServer(handler)
 
+	c, err := Certificate(domain)
+	if err != nil {
+		return nil, err
+	}
+	s.TLS = &tls.Config{
+		Certificates: []tls.Certificate{c},
+	}
+	s.StartTLS()
+
+	certpool := x509.NewCertPool()
+	certpool.AddCert(s.Certificate())
+
+	t := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs: certpool,
+		},
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return net.Dial(s.Listener.Addr().Network(), s.Listener.Addr().String())
+		},
+	}
+	s.Client().Transport = t
+
+	return s, nil
+}
+
+// Certificate generates a self-signed certificate, valid for an hour, for
+// the loopback addresses and the given hosts, which may be domains or IP
+// addresses. Its Leaf is set, so it can be added to a certificate pool to
+// trust it.
+func Certificate(hosts ...string) (tls.Certificate, error) {
 	template := x509.Certificate{
 		SerialNumber: big.NewInt(1),
 		NotBefore:    time.Now().Add(-1 * time.Hour),
@@ -45,60 +75,51 @@ func NewTLSServer(domain string, handler http.Handler) (*httptest.Server, error)
 			net.IPv4(127, 0, 0, 1),
 			net.IPv6loopback,
 		},
-		DNSNames: []string{domain},
 
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
 		IsCA:                  true,
 	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
 
 	priv, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
 	if err != nil {
-		return nil, err
+		return tls.Certificate{}, err
 	}
 
 	b, err := x509.CreateCertificate(rand.Reader, &template, &template, &priv.PublicKey, priv)
 	if err != nil {
-		return nil, err
+		return tls.Certificate{}, err
 	}
 
 	pc := &bytes.Buffer{}
 	if err := pem.Encode(pc, &pem.Block{Type: "CERTIFICATE", Bytes: b}); err != nil {
-		return nil, err
+		return tls.Certificate{}, err
 	}
 
 	ek, err := x509.MarshalECPrivateKey(priv)
 	if err != nil {
-		return nil, err
+		return tls.Certificate{}, err
 	}
 
 	pk := &bytes.Buffer{}
 	if err := pem.Encode(pk, &pem.Block{Type: "EC PRIVATE KEY", Bytes: ek}); err != nil {
-		return nil, err
+		return tls.Certificate{}, err
 	}
 
 	c, err := tls.X509KeyPair(pc.Bytes(), pk.Bytes())
 	if err != nil {
-		return nil, err
+		return tls.Certificate{}, err
 	}
-	s.TLS = &tls.Config{
-		Certificates: []tls.Certificate{c},
-	}
-	s.StartTLS()
-
-	certpool := x509.NewCertPool()
-	certpool.AddCert(s.Certificate())
-
-	t := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			RootCAs: certpool,
-		},
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			return net.Dial(s.Listener.Addr().Network(), s.Listener.Addr().String())
-		},
+	if c.Leaf, err = x509.ParseCertificate(b); err != nil {
+		return tls.Certificate{}, err
 	}
-	s.Client().Transport = t
-
-	return s, nil
+	return c, nil
 }
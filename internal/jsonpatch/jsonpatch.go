@@ -0,0 +1,323 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jsonpatch applies JSON Patch (RFC 6902) and JSON Merge Patch
+// (RFC 7386) documents.
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Apply applies patch to doc. If patch is a JSON array it is treated as a
+// JSON Patch, and if it is a JSON object it is treated as a JSON Merge Patch.
+//
+// Object keys in the result are sorted.
+func Apply(doc, patch []byte) ([]byte, error) {
+	switch p := bytes.TrimSpace(patch); {
+	case bytes.HasPrefix(p, []byte("[")):
+		return ApplyPatch(doc, patch)
+	case bytes.HasPrefix(p, []byte("{")):
+		return ApplyMergePatch(doc, patch)
+	}
+	return nil, errors.New("patch must be a JSON array (JSON Patch) or object (JSON Merge Patch)")
+}
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) to doc.
+func ApplyMergePatch(doc, patch []byte) ([]byte, error) {
+	d, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("decoding document: %w", err)
+	}
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("decoding merge patch: %w", err)
+	}
+	return encode(merge(d, p))
+}
+
+func merge(target, patch any) any {
+	pm, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	tm, ok := target.(map[string]any)
+	if !ok {
+		tm = map[string]any{}
+	}
+	for k, v := range pm {
+		if v == nil {
+			delete(tm, k)
+		} else {
+			tm[k] = merge(tm[k], v)
+		}
+	}
+	return tm
+}
+
+// Operation is a single JSON Patch operation.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyPatch applies a JSON Patch (RFC 6902) to doc.
+func ApplyPatch(doc, patch []byte) ([]byte, error) {
+	d, err := decode(doc)
+	if err != nil {
+		return nil, fmt.Errorf("decoding document: %w", err)
+	}
+	var ops []Operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("decoding patch: %w", err)
+	}
+	for i, op := range ops {
+		if d, err = apply(d, op); err != nil {
+			return nil, fmt.Errorf("patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return encode(d)
+}
+
+func apply(doc any, op Operation) (any, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, errors.New(`missing "value"`)
+		}
+		v, err := decode(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		switch op.Op {
+		case "add":
+			return add(doc, op.Path, v)
+		case "replace":
+			if doc, _, err = remove(doc, op.Path); err != nil {
+				return nil, err
+			}
+			return add(doc, op.Path, v)
+		default:
+			got, err := get(doc, op.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(got, v) {
+				return nil, errors.New("test failed")
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err := remove(doc, op.Path)
+		return doc, err
+	case "move":
+		if op.Path == op.From || strings.HasPrefix(op.Path, op.From+"/") {
+			if op.Path == op.From {
+				return doc, nil
+			}
+			return nil, errors.New("cannot move a value into itself")
+		}
+		doc, v, err := remove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, v)
+	case "copy":
+		v, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		// Round-trip to get a deep copy.
+		b, err := encode(v)
+		if err != nil {
+			return nil, err
+		}
+		if v, err = decode(b); err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, v)
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into unescaped tokens.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func index(tok string, n int) (int, error) {
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || (len(tok) > 1 && tok[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", tok)
+	}
+	if i >= n {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func get(doc any, path string) (any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, tok := range tokens {
+		switch c := cur.(type) {
+		case map[string]any:
+			v, ok := c[tok]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", path)
+			}
+			cur = v
+		case []any:
+			i, err := index(tok, len(c))
+			if err != nil {
+				return nil, err
+			}
+			cur = c[i]
+		default:
+			return nil, fmt.Errorf("%q not found", path)
+		}
+	}
+	return cur, nil
+}
+
+// update calls f with the container of the value at path and the last
+// token of path, replacing the container with what f returns.
+func update(doc any, path string, f func(parent any, tok string) (any, error)) (any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("cannot modify the document root")
+	}
+	parentPath := path[:strings.LastIndex(path, "/")]
+	parent, err := get(doc, parentPath)
+	if err != nil {
+		return nil, err
+	}
+	np, err := f(parent, tokens[len(tokens)-1])
+	if err != nil {
+		return nil, err
+	}
+	if parentPath == "" {
+		return np, nil
+	}
+	// Slices may have been reallocated, so store the new parent.
+	return update(doc, parentPath, func(grandparent any, tok string) (any, error) {
+		switch g := grandparent.(type) {
+		case map[string]any:
+			g[tok] = np
+		case []any:
+			i, err := index(tok, len(g))
+			if err != nil {
+				return nil, err
+			}
+			g[i] = np
+		}
+		return grandparent, nil
+	})
+}
+
+func add(doc any, path string, v any) (any, error) {
+	if path == "" {
+		return v, nil
+	}
+	return update(doc, path, func(parent any, tok string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			p[tok] = v
+			return p, nil
+		case []any:
+			if tok == "-" {
+				return append(p, v), nil
+			}
+			i, err := index(tok, len(p)+1)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, nil)
+			copy(p[i+1:], p[i:])
+			p[i] = v
+			return p, nil
+		}
+		return nil, fmt.Errorf("cannot add to %q", path)
+	})
+}
+
+func remove(doc any, path string) (any, any, error) {
+	var removed any
+	doc, err := update(doc, path, func(parent any, tok string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			v, ok := p[tok]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", path)
+			}
+			removed = v
+			delete(p, tok)
+			return p, nil
+		case []any:
+			i, err := index(tok, len(p))
+			if err != nil {
+				return nil, err
+			}
+			removed = p[i]
+			return append(p[:i:i], p[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%q not found", path)
+	})
+	return doc, removed, err
+}
+
+func decode(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	// Keep numbers (e.g. sizes) exactly as they were.
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func encode(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
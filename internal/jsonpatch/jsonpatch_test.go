@@ -0,0 +1,74 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonpatch
+
+import "testing"
+
+const doc = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"size":1234567890123,"digest":"sha256:abc"},"layers":[{"digest":"sha256:1"},{"digest":"sha256:2"}],"annotations":{"a/b":"<c>"}}`
+
+func TestApply(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr bool
+	}{{
+		name:  "merge patch",
+		patch: `{"mediaType":"application/vnd.docker.distribution.manifest.v2+json","annotations":{"a/b":null,"foo":"bar"}}`,
+		want:  `{"annotations":{"foo":"bar"},"config":{"digest":"sha256:abc","size":1234567890123},"layers":[{"digest":"sha256:1"},{"digest":"sha256:2"}],"mediaType":"application/vnd.docker.distribution.manifest.v2+json","schemaVersion":2}`,
+	}, {
+		name:  "json patch",
+		patch: `[{"op":"replace","path":"/mediaType","value":"x"},{"op":"add","path":"/annotations/foo","value":"bar"},{"op":"remove","path":"/annotations/a~1b"}]`,
+		want:  `{"annotations":{"foo":"bar"},"config":{"digest":"sha256:abc","size":1234567890123},"layers":[{"digest":"sha256:1"},{"digest":"sha256:2"}],"mediaType":"x","schemaVersion":2}`,
+	}, {
+		name:  "array operations",
+		patch: `[{"op":"add","path":"/layers/0","value":{"digest":"sha256:0"}},{"op":"add","path":"/layers/-","value":{"digest":"sha256:3"}},{"op":"remove","path":"/layers/1"},{"op":"move","from":"/layers/0","path":"/layers/-"}]`,
+		want:  `{"annotations":{"a/b":"<c>"},"config":{"digest":"sha256:abc","size":1234567890123},"layers":[{"digest":"sha256:2"},{"digest":"sha256:3"},{"digest":"sha256:0"}],"mediaType":"application/vnd.oci.image.manifest.v1+json","schemaVersion":2}`,
+	}, {
+		name:  "copy and test",
+		patch: `[{"op":"test","path":"/schemaVersion","value":2},{"op":"copy","from":"/config","path":"/subject"}]`,
+		want:  `{"annotations":{"a/b":"<c>"},"config":{"digest":"sha256:abc","size":1234567890123},"layers":[{"digest":"sha256:1"},{"digest":"sha256:2"}],"mediaType":"application/vnd.oci.image.manifest.v1+json","schemaVersion":2,"subject":{"digest":"sha256:abc","size":1234567890123}}`,
+	}, {
+		name:    "test fails",
+		patch:   `[{"op":"test","path":"/schemaVersion","value":1}]`,
+		wantErr: true,
+	}, {
+		name:    "missing path",
+		patch:   `[{"op":"remove","path":"/nope"}]`,
+		wantErr: true,
+	}, {
+		name:    "index out of range",
+		patch:   `[{"op":"replace","path":"/layers/2","value":{}}]`,
+		wantErr: true,
+	}, {
+		name:    "unknown op",
+		patch:   `[{"op":"frob","path":"/layers"}]`,
+		wantErr: true,
+	}, {
+		name:    "not a patch",
+		patch:   `"nope"`,
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Apply([]byte(doc), []byte(tc.patch))
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Apply: err = %v, wantErr %t", err, tc.wantErr)
+			}
+			if string(got) != tc.want {
+				t.Errorf("Apply:\ngot  %s\nwant %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/google/go-containerregistry/internal/retry/wait"
 )
@@ -57,6 +58,9 @@ type Predicate func(error) (retry bool)
 // Retry retries a given function, f, until a predicate is satisfied, using
 // exponential backoff. If the predicate is never satisfied, it will return the
 // last error returned by f.
+//
+// The predicate isn't consulted after the last attempt, since there is
+// nothing left to retry.
 func Retry(f func() error, p Predicate, backoff wait.Backoff) (err error) {
 	if f == nil {
 		return fmt.Errorf("nil f passed to retry")
@@ -65,9 +69,11 @@ func Retry(f func() error, p Predicate, backoff wait.Backoff) (err error) {
 		return fmt.Errorf("nil p passed to retry")
 	}
 
+	attempts := 0
 	condition := func() (bool, error) {
 		err = f()
-		if p(err) {
+		attempts++
+		if attempts < backoff.Steps && p(err) {
 			return false, nil
 		}
 		return true, err
@@ -81,6 +87,30 @@ type contextKey string
 
 var key = contextKey("never")
 
+// Budget limits the number of retries made by the predicates it wraps, in
+// total. It is safe for concurrent use.
+type Budget struct {
+	remaining atomic.Int64
+}
+
+// NewBudget returns a Budget of n retries.
+func NewBudget(n int) *Budget {
+	b := &Budget{}
+	b.remaining.Store(int64(n))
+	return b
+}
+
+// Wrap returns a Predicate that retries what p retries, until the budget has
+// been spent.
+func (b *Budget) Wrap(p Predicate) Predicate {
+	return func(err error) bool {
+		if !p(err) {
+			return false
+		}
+		return b.remaining.Add(-1) >= 0
+	}
+}
+
 // Never returns a context that signals something should not be retried.
 // This is a hack and can be used to communicate across package boundaries
 // to avoid retry amplification.
@@ -98,3 +98,30 @@ func TestNil(t *testing.T) {
 		t.Errorf("got nil when passing in nil p")
 	}
 }
+
+func TestBudget(t *testing.T) {
+	b := NewBudget(3)
+	p := b.Wrap(IsNotNil)
+
+	// Each call retries until the budget is spent, and the predicate isn't
+	// consulted after the last attempt.
+	for i, want := range []int{3, 2, 1} {
+		count := 0
+		Retry(func() error {
+			count++
+			return fmt.Errorf("oops")
+		}, p, Backoff{Steps: 3})
+		if count != want {
+			t.Errorf("call %d: got %d attempts, want %d", i, count, want)
+		}
+	}
+
+	// Errors the wrapped predicate doesn't retry don't spend the budget.
+	b = NewBudget(1)
+	if b.Wrap(IsTemporary)(fmt.Errorf("not temporary")) {
+		t.Error("retried an error that IsTemporary doesn't retry")
+	}
+	if !b.Wrap(IsNotNil)(fmt.Errorf("oops")) {
+		t.Error("budget was spent by an error that wasn't retried")
+	}
+}
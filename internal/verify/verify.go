@@ -50,13 +50,27 @@ func (v Error) Error() string {
 		v.want.Algorithm, v.gotSize, v.got, v.want)
 }
 
+// Got returns the digest of the content that was actually read.
+func (v Error) Got() string {
+	return v.got
+}
+
+// SizeError provides information about the failed size verification.
+type SizeError struct {
+	Got, Want int64
+}
+
+func (v SizeError) Error() string {
+	return fmt.Sprintf("error verifying size; got %d, want %d", v.Got, v.Want)
+}
+
 // Read implements io.Reader
 func (vc *verifyReader) Read(b []byte) (int, error) {
 	n, err := vc.inner.Read(b)
 	vc.gotSize += int64(n)
 	if err == io.EOF {
 		if vc.wantSize != SizeUnknown && vc.gotSize != vc.wantSize {
-			return n, fmt.Errorf("error verifying size; got %d, want %d", vc.gotSize, vc.wantSize)
+			return n, SizeError{Got: vc.gotSize, Want: vc.wantSize}
 		}
 		got := hex.EncodeToString(vc.hasher.Sum(nil))
 		if want := vc.expected.Hex; got != want {
@@ -84,6 +98,12 @@ func ReadCloser(r io.ReadCloser, size int64, h v1.Hash) (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
+	return HashReadCloser(r, size, h, w), nil
+}
+
+// HashReadCloser is like ReadCloser, but hashes the contents with w, which
+// must implement h.Algorithm.
+func HashReadCloser(r io.ReadCloser, size int64, h v1.Hash, w hash.Hash) io.ReadCloser {
 	r2 := io.TeeReader(r, w) // pass all writes to the hasher.
 	if size != SizeUnknown {
 		r2 = io.LimitReader(r2, size) // if we know the size, limit to that size.
@@ -96,7 +116,7 @@ func ReadCloser(r io.ReadCloser, size int64, h v1.Hash) (io.ReadCloser, error) {
 			wantSize: size,
 		},
 		CloseFunc: r.Close,
-	}, nil
+	}
 }
 
 // Descriptor verifies that the embedded Data field matches the Size and Digest
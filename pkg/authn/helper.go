@@ -0,0 +1,220 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+const (
+	defaultHelperTimeout  = 30 * time.Second
+	defaultHelperCacheTTL = 5 * time.Minute
+
+	// The message credential helpers print when they have no credentials.
+	// https://github.com/docker/docker-credential-helpers/blob/master/credentials/error.go
+	helperNotFoundMessage = "credentials not found in native keychain"
+)
+
+var (
+	// ErrHelperNotFound is wrapped by the HelperError returned when the
+	// credential helper binary can't be found.
+	ErrHelperNotFound = errors.New("credential helper not found")
+
+	// ErrHelperFailed matches every HelperError with errors.Is, so callers
+	// can tell a credential helper that is broken or misconfigured apart from
+	// one that has no credentials, which resolves to Anonymous.
+	ErrHelperFailed = errors.New("credential helper failed")
+)
+
+// HelperError is returned when a credential helper fails.
+type HelperError struct {
+	// Helper is the name of the helper binary, e.g. docker-credential-gcloud,
+	// or the type of the Helper passed to NewKeychainFromHelper.
+	Helper string
+
+	// ServerURL is the registry credentials were requested for.
+	ServerURL string
+
+	// Output is what the helper wrote to stdout and stderr, if anything.
+	Output string
+
+	Err error
+}
+
+func (e *HelperError) Error() string {
+	msg := fmt.Sprintf("%s get %s: %v", e.Helper, e.ServerURL, e.Err)
+	if e.Output != "" {
+		msg += ": " + e.Output
+	}
+	return msg
+}
+
+func (e *HelperError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrHelperFailed.
+func (e *HelperError) Is(target error) bool {
+	return target == ErrHelperFailed
+}
+
+// ExternalHelperOption is a functional option for NewExternalHelperKeychain.
+type ExternalHelperOption func(*externalHelper)
+
+// WithHelperTimeout sets how long the helper may run before it is killed.
+//
+// The default timeout is 30 seconds.
+func WithHelperTimeout(d time.Duration) ExternalHelperOption {
+	return func(h *externalHelper) {
+		h.timeout = d
+	}
+}
+
+// WithHelperCacheTTL sets how long credentials returned by the helper are
+// reused before the helper is run again. A TTL of zero disables caching.
+//
+// The default TTL is 5 minutes.
+func WithHelperCacheTTL(d time.Duration) ExternalHelperOption {
+	return func(h *externalHelper) {
+		h.ttl = d
+	}
+}
+
+// NewExternalHelperKeychain returns a Keychain that runs the Docker credential
+// helper binary docker-credential-<name> (e.g. "gcloud", "ecr-login" or
+// "osxkeychain") to get credentials for each registry.
+//
+// Registries the helper has no credentials for resolve to Anonymous. If the
+// helper is missing, times out or otherwise fails, a *HelperError is returned.
+func NewExternalHelperKeychain(name string, opts ...ExternalHelperOption) Keychain {
+	h := &externalHelper{
+		name:    "docker-credential-" + strings.TrimPrefix(name, "docker-credential-"),
+		timeout: defaultHelperTimeout,
+		ttl:     defaultHelperCacheTTL,
+		cache:   map[string]helperCacheEntry{},
+		now:     time.Now,
+	}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+type helperCacheEntry struct {
+	auth    Authenticator
+	expires time.Time
+}
+
+type externalHelper struct {
+	name    string
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	cache map[string]helperCacheEntry
+
+	// for testing
+	now func() time.Time
+}
+
+func (h *externalHelper) Resolve(r Resource) (Authenticator, error) {
+	return h.ResolveContext(context.Background(), r)
+}
+
+func (h *externalHelper) ResolveContext(ctx context.Context, r Resource) (Authenticator, error) {
+	serverURL := r.RegistryStr()
+	if serverURL == name.DefaultRegistry {
+		// Helpers store Docker Hub's credentials under its legacy key, as
+		// the config file does.
+		serverURL = DefaultAuthKey
+	}
+
+	h.mu.Lock()
+	e, ok := h.cache[serverURL]
+	h.mu.Unlock()
+	if ok && h.now().Before(e.expires) {
+		return e.auth, nil
+	}
+
+	auth, err := h.get(ctx, serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.ttl > 0 {
+		h.mu.Lock()
+		h.cache[serverURL] = helperCacheEntry{auth: auth, expires: h.now().Add(h.ttl)}
+		h.mu.Unlock()
+	}
+	return auth, nil
+}
+
+// get runs the helper using the credential helper protocol:
+// https://github.com/docker/docker-credential-helpers#development
+func (h *externalHelper) get(ctx context.Context, serverURL string) (Authenticator, error) {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, h.name, "get")
+	cmd.Stdin = strings.NewReader(serverURL)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	herr := &HelperError{Helper: h.name, ServerURL: serverURL}
+	if err := cmd.Run(); err != nil {
+		herr.Output = strings.TrimSpace(stdout.String() + stderr.String())
+		switch {
+		case errors.Is(err, exec.ErrNotFound):
+			herr.Err = ErrHelperNotFound
+		case ctx.Err() != nil:
+			herr.Err = ctx.Err()
+		case strings.Contains(herr.Output, helperNotFoundMessage):
+			return Anonymous, nil
+		default:
+			herr.Err = err
+		}
+		return nil, herr
+	}
+
+	var creds struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &creds); err != nil {
+		herr.Err = fmt.Errorf("parsing output: %w", err)
+		return nil, herr
+	}
+
+	// If the secret being stored is an identity token, the Username should be set to <token>
+	// ref: https://docs.docker.com/engine/reference/commandline/login/#credential-helper-protocol
+	if creds.Username == "<token>" {
+		return FromConfig(AuthConfig{Username: creds.Username, IdentityToken: creds.Secret}), nil
+	}
+	return FromConfig(AuthConfig{Username: creds.Username, Password: creds.Secret}), nil
+}
@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// installHelper puts a docker-credential-<name> shell script on $PATH.
+func installHelper(t *testing.T, name, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("helper scripts are shell scripts")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker-credential-"+name), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return dir
+}
+
+func TestExternalHelperKeychain(t *testing.T) {
+	repo := name.MustParseReference("example.com/my/repo").Context()
+	other := name.MustParseReference("other.example.com/my/repo").Context()
+
+	dir := installHelper(t, "test", `
+read server
+echo x >> "$(dirname "$0")/calls"
+case "$server" in
+example.com) echo '{"ServerURL":"example.com","Username":"user","Secret":"pass"}' ;;
+token.example.com) echo '{"ServerURL":"token.example.com","Username":"<token>","Secret":"idtoken"}' ;;
+https://index.docker.io/v1/) echo '{"ServerURL":"https://index.docker.io/v1/","Username":"hub","Secret":"hubpass"}' ;;
+*) echo "credentials not found in native keychain"; exit 1 ;;
+esac
+`)
+	calls := func() int {
+		b, _ := os.ReadFile(filepath.Join(dir, "calls"))
+		return strings.Count(string(b), "x")
+	}
+
+	kc := NewExternalHelperKeychain("test")
+	clock := time.Now()
+	kc.(*externalHelper).now = func() time.Time { return clock }
+
+	auth, err := kc.Resolve(repo)
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	cfg, err := auth.Authorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Username != "user" || cfg.Password != "pass" {
+		t.Errorf("Authorization: got %+v", cfg)
+	}
+
+	// Cached.
+	if _, err := kc.Resolve(repo); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls(); got != 1 {
+		t.Errorf("helper ran %d times, want 1", got)
+	}
+
+	// Expired.
+	clock = clock.Add(defaultHelperCacheTTL + time.Second)
+	if _, err := kc.Resolve(repo); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls(); got != 2 {
+		t.Errorf("helper ran %d times, want 2", got)
+	}
+
+	// Identity tokens.
+	auth, err = kc.Resolve(name.MustParseReference("token.example.com/repo").Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err := auth.Authorization(); err != nil {
+		t.Fatal(err)
+	} else if cfg.IdentityToken != "idtoken" || cfg.Password != "" {
+		t.Errorf("Authorization: got %+v", cfg)
+	}
+
+	// Docker Hub's credentials are stored under DefaultAuthKey.
+	for _, reg := range []string{"index.docker.io", "docker.io"} {
+		r, err := name.NewRegistry(reg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		auth, err := kc.Resolve(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg, err := auth.Authorization(); err != nil {
+			t.Fatal(err)
+		} else if cfg.Username != "hub" || cfg.Password != "hubpass" {
+			t.Errorf("Authorization(%s): got %+v", reg, cfg)
+		}
+	}
+
+	// No credentials.
+	if auth, err := kc.Resolve(other); err != nil {
+		t.Fatal(err)
+	} else if auth != Anonymous {
+		t.Errorf("Resolve: got %v, want Anonymous", auth)
+	}
+}
+
+func TestExternalHelperKeychainErrors(t *testing.T) {
+	repo := name.MustParseReference("example.com/my/repo").Context()
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := NewExternalHelperKeychain("does-not-exist").Resolve(repo)
+		var herr *HelperError
+		if !errors.As(err, &herr) {
+			t.Fatalf("expected *HelperError, got %T: %v", err, err)
+		}
+		if !errors.Is(err, ErrHelperNotFound) {
+			t.Errorf("expected ErrHelperNotFound, got %v", err)
+		}
+		if herr.Helper != "docker-credential-does-not-exist" || herr.ServerURL != "example.com" {
+			t.Errorf("HelperError: got %+v", herr)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		installHelper(t, "slow", "exec sleep 5\n")
+		_, err := NewExternalHelperKeychain("slow", WithHelperTimeout(50*time.Millisecond)).Resolve(repo)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		installHelper(t, "broken", "echo 'oh no' >&2; exit 3\n")
+		_, err := NewExternalHelperKeychain("broken").Resolve(repo)
+		var herr *HelperError
+		if !errors.As(err, &herr) {
+			t.Fatalf("expected *HelperError, got %T: %v", err, err)
+		}
+		if herr.Output != "oh no" {
+			t.Errorf("Output: got %q, want %q", herr.Output, "oh no")
+		}
+		if !errors.Is(err, ErrHelperFailed) {
+			t.Errorf("expected ErrHelperFailed, got %v", err)
+		}
+	})
+
+	t.Run("no caching", func(t *testing.T) {
+		dir := installHelper(t, "counting", `echo x >> "$(dirname "$0")/calls"; echo '{"Username":"u","Secret":"p"}'`+"\n")
+		kc := NewExternalHelperKeychain("docker-credential-counting", WithHelperCacheTTL(0))
+		for i := 0; i < 2; i++ {
+			if _, err := kc.Resolve(repo); err != nil {
+				t.Fatal(err)
+			}
+		}
+		b, _ := os.ReadFile(filepath.Join(dir, "calls"))
+		if got := strings.Count(string(b), "x"); got != 2 {
+			t.Errorf("helper ran %d times, want 2", got)
+		}
+	})
+}
@@ -16,8 +16,11 @@ package authn
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -78,6 +81,57 @@ func Resolve(ctx context.Context, keychain Keychain, target Resource) (Authentic
 	return keychain.Resolve(target)
 }
 
+// ResolveWithTimeout is like [Resolve], but gives up after d if d is
+// positive, returning an error that reports the timeout.
+func ResolveWithTimeout(ctx context.Context, keychain Keychain, target Resource, d time.Duration) (Authenticator, error) {
+	if d <= 0 {
+		return Resolve(ctx, keychain, target)
+	}
+
+	kctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	// The keychain may not respect kctx, so don't wait on it past the deadline.
+	type result struct {
+		auth Authenticator
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		auth, err := Resolve(kctx, keychain, target)
+		results <- result{auth, err}
+	}()
+
+	select {
+	case r := <-results:
+		if r.err != nil && ctx.Err() == nil && errors.Is(kctx.Err(), context.DeadlineExceeded) {
+			return nil, &timeoutError{target: target, d: d, err: r.err}
+		}
+		return r.auth, r.err
+	case <-kctx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, &timeoutError{target: target, d: d, err: kctx.Err()}
+	}
+}
+
+// timeoutError is returned by ResolveWithTimeout when the keychain runs out
+// of time.
+type timeoutError struct {
+	target Resource
+	d      time.Duration
+	err    error
+}
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("resolving credentials for %s timed out after %v: %v", e.target, e.d, e.err)
+}
+
+func (e *timeoutError) Unwrap() error {
+	return e.err
+}
+
 // ResolveContext implements ContextKeychain.
 func (dk *defaultKeychain) Resolve(target Resource) (Authenticator, error) {
 	return dk.ResolveContext(context.Background(), target)
@@ -155,6 +209,9 @@ func (dk *defaultKeychain) ResolveContext(_ context.Context, target Resource) (A
 
 		cfg, err = cf.GetAuthConfig(key)
 		if err != nil {
+			if helper := configuredHelper(cf, target.RegistryStr()); helper != "" {
+				return nil, &HelperError{Helper: helper, ServerURL: key, Err: err}
+			}
 			return nil, err
 		}
 		// cf.GetAuthConfig automatically sets the ServerAddress attribute. Since
@@ -178,6 +235,23 @@ func (dk *defaultKeychain) ResolveContext(_ context.Context, target Resource) (A
 	}), nil
 }
 
+// configuredHelper returns the credential helper that cf configures for
+// registry, if any.
+func configuredHelper(cf *configfile.ConfigFile, registry string) string {
+	if h := cf.CredentialHelpers[registry]; h != "" {
+		return "docker-credential-" + h
+	}
+	if registry == name.DefaultRegistry {
+		if h := cf.CredentialHelpers[DefaultAuthKey]; h != "" {
+			return "docker-credential-" + h
+		}
+	}
+	if cf.CredentialsStore != "" {
+		return "docker-credential-" + cf.CredentialsStore
+	}
+	return ""
+}
+
 // fileExists returns true if the given path exists and is not a directory.
 func fileExists(path string) bool {
 	fi, err := os.Stat(path)
@@ -193,21 +267,61 @@ type Helper interface {
 	Get(serverURL string) (string, string, error)
 }
 
+// ContextHelper is a Helper that can be cancelled. Keychains from
+// NewKeychainFromHelper call GetContext instead of Get when the helper
+// implements it. Otherwise they still stop waiting for Get once the context
+// is done, though Get keeps running in the background.
+type ContextHelper interface {
+	Helper
+	GetContext(ctx context.Context, serverURL string) (string, string, error)
+}
+
+// HelperKeychainOption is a functional option for NewKeychainFromHelper.
+type HelperKeychainOption func(*wrapper)
+
+// WithHelperErrors makes the keychain return a *HelperError when the helper
+// fails, rather than resolving to Anonymous. Errors that say the helper has
+// no credentials for the registry still resolve to Anonymous.
+//
+// By default, every error resolves to Anonymous, so that helpers for cloud
+// registries can be combined in a multi-keychain and fail quietly where they
+// don't apply.
+func WithHelperErrors() HelperKeychainOption {
+	return func(w *wrapper) {
+		w.reportErrors = true
+	}
+}
+
 // NewKeychainFromHelper returns a Keychain based on a Docker credential helper
 // implementation that can Get username and password credentials for a given
 // server URL.
-func NewKeychainFromHelper(h Helper) Keychain { return wrapper{h} }
+func NewKeychainFromHelper(h Helper, opts ...HelperKeychainOption) Keychain {
+	w := wrapper{h: h}
+	for _, o := range opts {
+		o(&w)
+	}
+	return w
+}
 
-type wrapper struct{ h Helper }
+type wrapper struct {
+	h            Helper
+	reportErrors bool
+}
 
 func (w wrapper) Resolve(r Resource) (Authenticator, error) {
 	return w.ResolveContext(context.Background(), r)
 }
 
-func (w wrapper) ResolveContext(_ context.Context, r Resource) (Authenticator, error) {
-	u, p, err := w.h.Get(r.RegistryStr())
+func (w wrapper) ResolveContext(ctx context.Context, r Resource) (Authenticator, error) {
+	u, p, err := w.get(ctx, r.RegistryStr())
 	if err != nil {
-		return Anonymous, nil
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		if !w.reportErrors || strings.Contains(err.Error(), helperNotFoundMessage) {
+			return Anonymous, nil
+		}
+		return nil, &HelperError{Helper: fmt.Sprintf("%T", w.h), ServerURL: r.RegistryStr(), Err: err}
 	}
 	// If the secret being stored is an identity token, the Username should be set to <token>
 	// ref: https://docs.docker.com/engine/reference/commandline/login/#credential-helper-protocol
@@ -224,6 +338,35 @@ func RefreshingKeychain(inner Keychain, duration time.Duration) Keychain {
 	}
 }
 
+// get calls the helper, returning early if ctx is done first.
+func (w wrapper) get(ctx context.Context, serverURL string) (string, string, error) {
+	if ch, ok := w.h.(ContextHelper); ok {
+		return ch.GetContext(ctx, serverURL)
+	}
+	if ctx.Done() == nil {
+		return w.h.Get(serverURL)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", "", err
+	}
+
+	type result struct {
+		u, p string
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		u, p, err := w.h.Get(serverURL)
+		results <- result{u, p, err}
+	}()
+	select {
+	case r := <-results:
+		return r.u, r.p, r.err
+	case <-ctx.Done():
+		return "", "", ctx.Err()
+	}
+}
+
 type refreshingKeychain struct {
 	keychain Keychain
 	duration time.Duration
@@ -15,6 +15,7 @@
 package authn
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -337,6 +338,22 @@ func (h helper) Get(serverURL string) (string, string, error) {
 	return h.u, h.p, h.err
 }
 
+// hungHelper never returns from Get, like a helper stuck on a metadata
+// server.
+type hungHelper struct{}
+
+func (hungHelper) Get(string) (string, string, error) {
+	select {}
+}
+
+// contextHelper returns ctx's error from GetContext.
+type contextHelper struct{ hungHelper }
+
+func (contextHelper) GetContext(ctx context.Context, _ string) (string, string, error) {
+	<-ctx.Done()
+	return "", "", ctx.Err()
+}
+
 func TestNewKeychainFromHelper(t *testing.T) {
 	var repo = name.MustParseReference("example.com/my/repo").Context()
 
@@ -392,6 +409,60 @@ func TestNewKeychainFromHelper(t *testing.T) {
 			t.Errorf("Resolve: got %v, want %v", auth, Anonymous)
 		}
 	})
+
+	t.Run("failure; with helper errors", func(t *testing.T) {
+		kc := NewKeychainFromHelper(helper{"", "", errors.New("oh no bad")}, WithHelperErrors())
+		_, err := kc.Resolve(repo)
+		var herr *HelperError
+		if !errors.As(err, &herr) {
+			t.Fatalf("Resolve: expected *HelperError, got %T: %v", err, err)
+		}
+		if !errors.Is(err, ErrHelperFailed) {
+			t.Errorf("Resolve: expected ErrHelperFailed, got %v", err)
+		}
+		if herr.ServerURL != "example.com" {
+			t.Errorf("ServerURL: got %q, want %q", herr.ServerURL, "example.com")
+		}
+	})
+
+	for _, h := range []Helper{hungHelper{}, contextHelper{}} {
+		t.Run(fmt.Sprintf("cancelled; %T", h), func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			kc := NewKeychainFromHelper(h)
+			if _, err := Resolve(ctx, kc, repo); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Resolve: got %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+
+	t.Run("no credentials; with helper errors", func(t *testing.T) {
+		kc := NewKeychainFromHelper(helper{"", "", errors.New("credentials not found in native keychain")}, WithHelperErrors())
+		auth, err := kc.Resolve(repo)
+		if err != nil {
+			t.Fatalf("Resolve(%q): %v", repo, err)
+		}
+		if auth != Anonymous {
+			t.Errorf("Resolve: got %v, want %v", auth, Anonymous)
+		}
+	})
+}
+
+func TestDefaultKeychainHelperFailure(t *testing.T) {
+	installHelper(t, "broken", "echo 'oh no' >&2; exit 3\n")
+	setupConfigFile(t, `{"credsStore":"broken"}`)
+
+	_, err := DefaultKeychain.Resolve(testRegistry)
+	var herr *HelperError
+	if !errors.As(err, &herr) {
+		t.Fatalf("Resolve: expected *HelperError, got %T: %v", err, err)
+	}
+	if !errors.Is(err, ErrHelperFailed) {
+		t.Errorf("Resolve: expected ErrHelperFailed, got %v", err)
+	}
+	if herr.Helper != "docker-credential-broken" {
+		t.Errorf("Helper: got %q, want %q", herr.Helper, "docker-credential-broken")
+	}
 }
 
 func TestConfigFileIsADir(t *testing.T) {
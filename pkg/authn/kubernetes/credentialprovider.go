@@ -0,0 +1,318 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// The types below mirror those of the kubelet's credential provider API:
+// https://kubernetes.io/docs/reference/config-api/kubelet-credentialprovider.v1/
+
+// credentialProviderConfig is the kubelet's CredentialProviderConfig.
+type credentialProviderConfig struct {
+	Providers []credentialProvider `json:"providers"`
+}
+
+// credentialProvider configures a credential provider plugin.
+type credentialProvider struct {
+	Name                 string                         `json:"name"`
+	MatchImages          []string                       `json:"matchImages"`
+	DefaultCacheDuration *metav1.Duration               `json:"defaultCacheDuration,omitempty"`
+	APIVersion           string                         `json:"apiVersion"`
+	Args                 []string                       `json:"args,omitempty"`
+	Env                  []execEnvVar                   `json:"env,omitempty"`
+	TokenAttributes      *serviceAccountTokenAttributes `json:"tokenAttributes,omitempty"`
+}
+
+type execEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// serviceAccountTokenAttributes configures the service account token that a
+// plugin is given, for plugins that exchange it for registry credentials.
+type serviceAccountTokenAttributes struct {
+	ServiceAccountTokenAudience          string   `json:"serviceAccountTokenAudience"`
+	RequireServiceAccount                *bool    `json:"requireServiceAccount,omitempty"`
+	RequiredServiceAccountAnnotationKeys []string `json:"requiredServiceAccountAnnotationKeys,omitempty"`
+	OptionalServiceAccountAnnotationKeys []string `json:"optionalServiceAccountAnnotationKeys,omitempty"`
+}
+
+type credentialProviderRequest struct {
+	APIVersion                string            `json:"apiVersion"`
+	Kind                      string            `json:"kind"`
+	Image                     string            `json:"image"`
+	ServiceAccountToken       string            `json:"serviceAccountToken,omitempty"`
+	ServiceAccountAnnotations map[string]string `json:"serviceAccountAnnotations,omitempty"`
+}
+
+type credentialProviderResponse struct {
+	APIVersion    string                      `json:"apiVersion"`
+	Kind          string                      `json:"kind"`
+	CacheKeyType  string                      `json:"cacheKeyType"`
+	CacheDuration *metav1.Duration            `json:"cacheDuration,omitempty"`
+	Auth          map[string]authn.AuthConfig `json:"auth"`
+}
+
+// providerKeychain resolves credentials with credential provider plugins.
+type providerKeychain struct {
+	client         kubernetes.Interface
+	namespace      string
+	serviceAccount string
+	plugins        []*plugin
+}
+
+// newProviderKeychain reads the CredentialProviderConfig file named in opt.
+func newProviderKeychain(client kubernetes.Interface, opt Options) (*providerKeychain, error) {
+	b, err := os.ReadFile(opt.CredentialProviderConfig)
+	if err != nil {
+		return nil, err
+	}
+	var cfg credentialProviderConfig
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", opt.CredentialProviderConfig, err)
+	}
+	pk := &providerKeychain{
+		client:         client,
+		namespace:      opt.Namespace,
+		serviceAccount: opt.ServiceAccountName,
+	}
+	for _, p := range cfg.Providers {
+		if p.Name == "" || strings.ContainsAny(p.Name, `/\`) || p.Name == "." || p.Name == ".." {
+			return nil, fmt.Errorf("%s: invalid credential provider name %q", opt.CredentialProviderConfig, p.Name)
+		}
+		if len(p.MatchImages) == 0 {
+			return nil, fmt.Errorf("%s: credential provider %s has no matchImages", opt.CredentialProviderConfig, p.Name)
+		}
+		if p.APIVersion == "" {
+			return nil, fmt.Errorf("%s: credential provider %s has no apiVersion", opt.CredentialProviderConfig, p.Name)
+		}
+		if p.TokenAttributes != nil && p.TokenAttributes.ServiceAccountTokenAudience == "" {
+			return nil, fmt.Errorf("%s: credential provider %s has no serviceAccountTokenAudience", opt.CredentialProviderConfig, p.Name)
+		}
+		pk.plugins = append(pk.plugins, &plugin{
+			credentialProvider: p,
+			path:               filepath.Join(opt.CredentialProviderBinDir, p.Name),
+			cache:              map[string]cacheEntry{},
+		})
+	}
+	return pk, nil
+}
+
+// Resolve implements authn.Keychain.
+func (pk *providerKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	return pk.ResolveContext(context.Background(), target)
+}
+
+// ResolveContext implements authn.ContextKeychain.
+func (pk *providerKeychain) ResolveContext(ctx context.Context, target authn.Resource) (authn.Authenticator, error) {
+	image := target.String()
+	for _, p := range pk.plugins {
+		if !p.matches(image) {
+			continue
+		}
+		kr, err := p.keyring(ctx, pk, target)
+		if err != nil {
+			return nil, fmt.Errorf("credential provider %s: %w", p.Name, err)
+		}
+		auth, err := authn.Resolve(ctx, kr, target)
+		if err != nil {
+			return nil, err
+		}
+		if auth != authn.Anonymous {
+			return auth, nil
+		}
+	}
+	return authn.Anonymous, nil
+}
+
+// plugin is a credential provider plugin, with the responses it has given.
+type plugin struct {
+	credentialProvider
+	path string
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	keyring *keyring
+	expires time.Time
+}
+
+func (p *plugin) matches(image string) bool {
+	for _, m := range p.MatchImages {
+		if ok, _ := urlsMatchStr(m, image); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// keyring returns the credentials the plugin gives for target, from the cache
+// if it can.
+func (p *plugin) keyring(ctx context.Context, pk *providerKeychain, target authn.Resource) (*keyring, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Responses are cached by image, by registry or globally, as the plugin
+	// asks.
+	now := time.Now()
+	for _, key := range []string{"image:" + target.String(), "registry:" + target.RegistryStr(), "global:"} {
+		if e, ok := p.cache[key]; ok {
+			if now.Before(e.expires) {
+				return e.keyring, nil
+			}
+			delete(p.cache, key)
+		}
+	}
+
+	resp, err := p.exec(ctx, pk, target.String())
+	if err != nil {
+		return nil, err
+	}
+	kr := &keyring{
+		index: make([]string, 0),
+		creds: make(map[string][]authn.AuthConfig),
+	}
+	for registry, v := range resp.Auth {
+		if err := kr.add(registry, v); err != nil {
+			return nil, err
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(kr.index)))
+
+	d := time.Duration(0)
+	if resp.CacheDuration != nil {
+		d = resp.CacheDuration.Duration
+	} else if p.DefaultCacheDuration != nil {
+		d = p.DefaultCacheDuration.Duration
+	}
+	if d > 0 {
+		var key string
+		switch resp.CacheKeyType {
+		case "Image":
+			key = "image:" + target.String()
+		case "Registry":
+			key = "registry:" + target.RegistryStr()
+		case "Global":
+			key = "global:"
+		default:
+			return nil, fmt.Errorf("invalid cacheKeyType %q", resp.CacheKeyType)
+		}
+		p.cache[key] = cacheEntry{keyring: kr, expires: now.Add(d)}
+	}
+	return kr, nil
+}
+
+// exec runs the plugin for image.
+func (p *plugin) exec(ctx context.Context, pk *providerKeychain, image string) (*credentialProviderResponse, error) {
+	req := credentialProviderRequest{
+		APIVersion: p.APIVersion,
+		Kind:       "CredentialProviderRequest",
+		Image:      image,
+	}
+	if p.TokenAttributes != nil {
+		token, annotations, err := pk.serviceAccountToken(ctx, p.TokenAttributes)
+		if err != nil {
+			return nil, err
+		}
+		req.ServiceAccountToken, req.ServiceAccountAnnotations = token, annotations
+	}
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.CommandContext(ctx, p.path, p.Args...) //nolint:gosec
+	cmd.Env = os.Environ()
+	for _, e := range p.Env {
+		cmd.Env = append(cmd.Env, e.Name+"="+e.Value)
+	}
+	cmd.Stdin = bytes.NewReader(in)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var resp credentialProviderResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+	if resp.Kind != "CredentialProviderResponse" {
+		return nil, fmt.Errorf("response has kind %q, want CredentialProviderResponse", resp.Kind)
+	}
+	if resp.APIVersion != p.APIVersion {
+		return nil, fmt.Errorf("response has apiVersion %q, want %q", resp.APIVersion, p.APIVersion)
+	}
+	return &resp, nil
+}
+
+// serviceAccountToken requests a token for the keychain's service account
+// with the audience in attrs, returning it with the service account's
+// annotations that attrs asks for.
+func (pk *providerKeychain) serviceAccountToken(ctx context.Context, attrs *serviceAccountTokenAttributes) (string, map[string]string, error) {
+	if pk.serviceAccount == NoServiceAccount {
+		if attrs.RequireServiceAccount == nil || *attrs.RequireServiceAccount {
+			return "", nil, fmt.Errorf("a service account token is required, but there is no service account")
+		}
+		return "", nil, nil
+	}
+	sa, err := pk.client.CoreV1().ServiceAccounts(pk.namespace).Get(ctx, pk.serviceAccount, metav1.GetOptions{})
+	if err != nil {
+		return "", nil, err
+	}
+	annotations := map[string]string{}
+	for _, k := range attrs.RequiredServiceAccountAnnotationKeys {
+		v, ok := sa.Annotations[k]
+		if !ok {
+			return "", nil, fmt.Errorf("serviceaccount %s/%s has no annotation %s", pk.namespace, pk.serviceAccount, k)
+		}
+		annotations[k] = v
+	}
+	for _, k := range attrs.OptionalServiceAccountAnnotationKeys {
+		if v, ok := sa.Annotations[k]; ok {
+			annotations[k] = v
+		}
+	}
+
+	tr, err := pk.client.CoreV1().ServiceAccounts(pk.namespace).CreateToken(ctx, pk.serviceAccount, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences: []string{attrs.ServiceAccountTokenAudience},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return "", nil, fmt.Errorf("requesting a token for serviceaccount %s/%s: %w", pk.namespace, pk.serviceAccount, err)
+	}
+	return tr.Status.Token, annotations, nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	fakeclient "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// writePlugin writes a credential provider plugin to dir that records each
+// request it gets in dir/requests and responds with response.
+func writePlugin(t *testing.T, dir, name, response string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	script := "#!/bin/sh\ncat >> " + filepath.Join(dir, "requests") + "\necho >> " + filepath.Join(dir, "requests") + "\ncat <<'EOF'\n" + response + "\nEOF\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeProviderConfig(t *testing.T, dir, config string) string {
+	t.Helper()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func pluginRequests(t *testing.T, dir string) []credentialProviderRequest {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join(dir, "requests"))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	var reqs []credentialProviderRequest
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var req credentialProviderRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			t.Fatalf("parsing request %q: %v", line, err)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs
+}
+
+func TestCredentialProvider(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "example-provider", `{
+  "apiVersion": "credentialprovider.kubelet.k8s.io/v1",
+  "kind": "CredentialProviderResponse",
+  "cacheKeyType": "Registry",
+  "cacheDuration": "1h",
+  "auth": {"*.example.com": {"username": "plugin", "password": "secret"}}
+}`)
+	config := writeProviderConfig(t, dir, `apiVersion: kubelet.config.k8s.io/v1
+kind: CredentialProviderConfig
+providers:
+- name: example-provider
+  matchImages: ["*.example.com"]
+  defaultCacheDuration: 10m
+  apiVersion: credentialprovider.kubelet.k8s.io/v1
+`)
+
+	client := fakeclient.NewSimpleClientset(
+		&corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "default"},
+		},
+		dockerConfigJSONSecretType.Create(t, "default", "secret", "fromsecret.example.com", authn.AuthConfig{
+			Username: "secret-user",
+			Password: "secret-password",
+		}),
+	)
+	kc, err := New(context.Background(), client, Options{
+		ImagePullSecrets:         []string{"secret"},
+		CredentialProviderConfig: config,
+		CredentialProviderBinDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+
+	plugin := &authn.Basic{Username: "plugin", Password: "secret"}
+	testResolve(t, kc, repo(t, "registry.example.com/foo"), plugin)
+	testResolve(t, kc, repo(t, "registry.example.com/bar"), plugin)
+	testResolve(t, kc, repo(t, "fromsecret.example.com/foo"), &authn.Basic{Username: "secret-user", Password: "secret-password"})
+	testResolve(t, kc, repo(t, "gcr.io/foo"), authn.Anonymous)
+
+	// The plugin only ran once, for registry.example.com: the pull secret had
+	// credentials for fromsecret.example.com, gcr.io doesn't match, and the
+	// response was cached for the registry.
+	reqs := pluginRequests(t, dir)
+	if len(reqs) != 1 {
+		t.Fatalf("plugin ran %d times, want 1: %v", len(reqs), reqs)
+	}
+	if want := (credentialProviderRequest{
+		APIVersion: "credentialprovider.kubelet.k8s.io/v1",
+		Kind:       "CredentialProviderRequest",
+		Image:      "registry.example.com/foo",
+	}); reqs[0].APIVersion != want.APIVersion || reqs[0].Kind != want.Kind || reqs[0].Image != want.Image || reqs[0].ServiceAccountToken != "" {
+		t.Errorf("request = %+v, want %+v", reqs[0], want)
+	}
+}
+
+func TestCredentialProviderServiceAccountToken(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "token-provider", `{
+  "apiVersion": "credentialprovider.kubelet.k8s.io/v1",
+  "kind": "CredentialProviderResponse",
+  "cacheKeyType": "Image",
+  "auth": {"registry.example.com": {"username": "oauth2accesstoken", "password": "exchanged"}}
+}`)
+	config := writeProviderConfig(t, dir, `apiVersion: kubelet.config.k8s.io/v1
+kind: CredentialProviderConfig
+providers:
+- name: token-provider
+  matchImages: ["registry.example.com"]
+  apiVersion: credentialprovider.kubelet.k8s.io/v1
+  tokenAttributes:
+    serviceAccountTokenAudience: registry.example.com
+    requireServiceAccount: true
+    requiredServiceAccountAnnotationKeys: ["example.com/identity"]
+    optionalServiceAccountAnnotationKeys: ["example.com/missing"]
+`)
+
+	client := fakeclient.NewSimpleClientset(&corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "builder",
+			Namespace:   "ns",
+			Annotations: map[string]string{"example.com/identity": "builder@example.com", "other": "ignored"},
+		},
+	})
+	var audiences []string
+	client.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, k8sruntime.Object, error) {
+		ca, ok := action.(k8stesting.CreateAction)
+		if !ok || action.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		tr := ca.GetObject().(*authenticationv1.TokenRequest)
+		audiences = tr.Spec.Audiences
+		tr.Status.Token = "projected-token"
+		return true, tr, nil
+	})
+
+	kc, err := New(context.Background(), client, Options{
+		Namespace:                "ns",
+		ServiceAccountName:       "builder",
+		CredentialProviderConfig: config,
+		CredentialProviderBinDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	testResolve(t, kc, repo(t, "registry.example.com/foo"), &authn.Basic{Username: "oauth2accesstoken", Password: "exchanged"})
+
+	if len(audiences) != 1 || audiences[0] != "registry.example.com" {
+		t.Errorf("token audiences = %v, want [registry.example.com]", audiences)
+	}
+	reqs := pluginRequests(t, dir)
+	if len(reqs) != 1 {
+		t.Fatalf("plugin ran %d times, want 1: %v", len(reqs), reqs)
+	}
+	if reqs[0].ServiceAccountToken != "projected-token" {
+		t.Errorf("serviceAccountToken = %q, want projected-token", reqs[0].ServiceAccountToken)
+	}
+	if got := reqs[0].ServiceAccountAnnotations; len(got) != 1 || got["example.com/identity"] != "builder@example.com" {
+		t.Errorf("serviceAccountAnnotations = %v, want only example.com/identity", got)
+	}
+
+	// Without a service account, the plugin can't be given a token.
+	kc, err = New(context.Background(), client, Options{
+		Namespace:                "ns",
+		ServiceAccountName:       NoServiceAccount,
+		CredentialProviderConfig: config,
+		CredentialProviderBinDir: dir,
+	})
+	if err != nil {
+		t.Fatalf("New() = %v", err)
+	}
+	if _, err := kc.Resolve(repo(t, "registry.example.com/foo")); err == nil {
+		t.Error("Resolve() without a service account succeeded, want an error")
+	}
+}
+
+func TestCredentialProviderInvalidConfig(t *testing.T) {
+	for _, config := range []string{
+		"providers:\n- name: ../escape\n  matchImages: [\"*\"]\n  apiVersion: credentialprovider.kubelet.k8s.io/v1\n",
+		"providers:\n- name: nomatch\n  apiVersion: credentialprovider.kubelet.k8s.io/v1\n",
+		"providers:\n- name: noversion\n  matchImages: [\"*\"]\n",
+		"providers:\n- name: noaudience\n  matchImages: [\"*\"]\n  apiVersion: credentialprovider.kubelet.k8s.io/v1\n  tokenAttributes: {}\n",
+	} {
+		dir := t.TempDir()
+		if _, err := New(context.Background(), fakeclient.NewSimpleClientset(), Options{
+			ServiceAccountName:       NoServiceAccount,
+			CredentialProviderConfig: writeProviderConfig(t, dir, config),
+			CredentialProviderBinDir: dir,
+		}); err == nil {
+			t.Errorf("New() with config %q succeeded, want an error", config)
+		}
+	}
+}
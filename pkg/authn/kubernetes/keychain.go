@@ -64,6 +64,19 @@ type Options struct {
 	// attribute of the ServiceAccount resource. Ignored if ServiceAccountName is set
 	// to NoServiceAccount.
 	UseMountSecrets bool
+
+	// CredentialProviderConfig is the path to a kubelet CredentialProviderConfig
+	// file, as passed to the kubelet's --image-credential-provider-config flag.
+	// If set, the credential provider plugins it configures are invoked, as
+	// the kubelet invokes them, for images that no pull secret has credentials
+	// for. Plugins that ask for a service account token are given one for
+	// ServiceAccountName, requested with the TokenRequest API.
+	CredentialProviderConfig string
+
+	// CredentialProviderBinDir is the directory holding the credential
+	// provider plugins, as passed to the kubelet's
+	// --image-credential-provider-bin-dir flag.
+	CredentialProviderBinDir string
 }
 
 // New returns a new authn.Keychain suitable for resolving image references as
@@ -82,6 +95,8 @@ func New(ctx context.Context, client kubernetes.Interface, opt Options) (authn.K
 	//  1) The explicit authentication from imagePullSecrets on Pod
 	//  2) The semi-implicit authentication where imagePullSecrets are on the
 	//    Pod's service account.
+	//  3) The implicit authentication of the kubelet's credential provider
+	//    plugins.
 
 	// First, fetch all of the explicitly declared pull secrets
 	var pullSecrets []corev1.Secret
@@ -133,7 +148,20 @@ func New(ctx context.Context, client kubernetes.Interface, opt Options) (authn.K
 		}
 	}
 
-	return NewFromPullSecrets(ctx, pullSecrets)
+	kc, err := NewFromPullSecrets(ctx, pullSecrets)
+	if err != nil {
+		return nil, err
+	}
+
+	// Last, fall back on the credential provider plugins, if any.
+	if opt.CredentialProviderConfig == "" {
+		return kc, nil
+	}
+	providers, err := newProviderKeychain(client, opt)
+	if err != nil {
+		return nil, err
+	}
+	return authn.NewMultiKeychain(kc, providers), nil
 }
 
 // NewInCluster returns a new authn.Keychain suitable for resolving image references as
@@ -180,38 +208,9 @@ func NewFromPullSecrets(ctx context.Context, secrets []corev1.Secret) (authn.Key
 		}
 
 		for registry, v := range cfg.Auths {
-			value := registry
-			if !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
-				value = "https://" + value
-			}
-			parsed, err := url.Parse(value)
-			if err != nil {
-				return nil, fmt.Errorf("Entry %q in dockercfg invalid (%w)", value, err)
-			}
-
-			// The docker client allows exact matches:
-			//    foo.bar.com/namespace
-			// Or hostname matches:
-			//    foo.bar.com
-			// It also considers /v2/  and /v1/ equivalent to the hostname
-			// See ResolveAuthConfig in docker/registry/auth.go.
-			effectivePath := parsed.Path
-			if strings.HasPrefix(effectivePath, "/v2/") || strings.HasPrefix(effectivePath, "/v1/") {
-				effectivePath = effectivePath[3:]
-			}
-			var key string
-			if (len(effectivePath) > 0) && (effectivePath != "/") {
-				key = parsed.Host + effectivePath
-			} else {
-				key = parsed.Host
-			}
-
-			if _, ok := keyring.creds[key]; !ok {
-				keyring.index = append(keyring.index, key)
+			if err := keyring.add(registry, v); err != nil {
+				return nil, err
 			}
-
-			keyring.creds[key] = append(keyring.creds[key], v)
-
 		}
 
 		// We reverse sort in to give more specific (aka longer) keys priority
@@ -226,6 +225,43 @@ type keyring struct {
 	creds map[string][]authn.AuthConfig
 }
 
+// add adds the credentials v for registry, a key of a dockercfg, to the
+// keyring. Callers sort the index afterwards.
+func (keyring *keyring) add(registry string, v authn.AuthConfig) error {
+	value := registry
+	if !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
+		value = "https://" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("Entry %q in dockercfg invalid (%w)", value, err)
+	}
+
+	// The docker client allows exact matches:
+	//    foo.bar.com/namespace
+	// Or hostname matches:
+	//    foo.bar.com
+	// It also considers /v2/  and /v1/ equivalent to the hostname
+	// See ResolveAuthConfig in docker/registry/auth.go.
+	effectivePath := parsed.Path
+	if strings.HasPrefix(effectivePath, "/v2/") || strings.HasPrefix(effectivePath, "/v1/") {
+		effectivePath = effectivePath[3:]
+	}
+	var key string
+	if (len(effectivePath) > 0) && (effectivePath != "/") {
+		key = parsed.Host + effectivePath
+	} else {
+		key = parsed.Host
+	}
+
+	if _, ok := keyring.creds[key]; !ok {
+		keyring.index = append(keyring.index, key)
+	}
+
+	keyring.creds[key] = append(keyring.creds[key], v)
+	return nil
+}
+
 func (keyring *keyring) Resolve(target authn.Resource) (authn.Authenticator, error) {
 	image := target.String()
 	auths := []authn.AuthConfig{}
@@ -14,10 +14,16 @@
 
 package authn
 
-import "context"
+import (
+	"context"
+	"errors"
+	"time"
+)
 
 type multiKeychain struct {
 	keychains []Keychain
+	parallel  bool
+	timeout   time.Duration
 }
 
 // Assert that our multi-keychain implements Keychain.
@@ -28,14 +34,51 @@ func NewMultiKeychain(kcs ...Keychain) Keychain {
 	return &multiKeychain{keychains: kcs}
 }
 
+// MultiKeychainOption is a functional option for NewMultiKeychainWithOptions.
+type MultiKeychainOption func(*multiKeychain)
+
+// WithParallelResolution consults every keychain at once instead of one at a
+// time. The first keychain to return a non-anonymous Authenticator wins,
+// regardless of its position in the list. Errors are only returned if no
+// keychain has credentials for the target.
+func WithParallelResolution() MultiKeychainOption {
+	return func(mk *multiKeychain) {
+		mk.parallel = true
+	}
+}
+
+// WithKeychainTimeout bounds how long each keychain may take to resolve a
+// target. A keychain that runs out of time is treated as having no
+// credentials, so a hung credential helper doesn't block the others.
+//
+// By default, keychains may take as long as they like.
+func WithKeychainTimeout(d time.Duration) MultiKeychainOption {
+	return func(mk *multiKeychain) {
+		mk.timeout = d
+	}
+}
+
+// NewMultiKeychainWithOptions is like NewMultiKeychain, but accepts options
+// that control how the keychains are consulted.
+func NewMultiKeychainWithOptions(kcs []Keychain, opts ...MultiKeychainOption) Keychain {
+	mk := &multiKeychain{keychains: kcs}
+	for _, o := range opts {
+		o(mk)
+	}
+	return mk
+}
+
 // Resolve implements Keychain.
 func (mk *multiKeychain) Resolve(target Resource) (Authenticator, error) {
 	return mk.ResolveContext(context.Background(), target)
 }
 
 func (mk *multiKeychain) ResolveContext(ctx context.Context, target Resource) (Authenticator, error) {
+	if mk.parallel {
+		return mk.resolveParallel(ctx, target)
+	}
 	for _, kc := range mk.keychains {
-		auth, err := Resolve(ctx, kc, target)
+		auth, err := mk.resolve(ctx, kc, target)
 		if err != nil {
 			return nil, err
 		}
@@ -45,3 +88,53 @@ func (mk *multiKeychain) ResolveContext(ctx context.Context, target Resource) (A
 	}
 	return Anonymous, nil
 }
+
+type keychainResult struct {
+	index int
+	auth  Authenticator
+	err   error
+}
+
+func (mk *multiKeychain) resolveParallel(ctx context.Context, target Resource) (Authenticator, error) {
+	// Stop the losers once we have a winner.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan keychainResult, len(mk.keychains))
+	for i, kc := range mk.keychains {
+		go func() {
+			auth, err := mk.resolve(ctx, kc, target)
+			results <- keychainResult{index: i, auth: auth, err: err}
+		}()
+	}
+
+	errs := make([]error, len(mk.keychains))
+	for range mk.keychains {
+		r := <-results
+		if r.err != nil {
+			errs[r.index] = r.err
+			continue
+		}
+		if r.auth != Anonymous {
+			return r.auth, nil
+		}
+	}
+
+	// Report the error from the earliest keychain, as serial resolution would.
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return Anonymous, nil
+}
+
+// resolve calls Resolve, giving up after mk.timeout if one is set.
+func (mk *multiKeychain) resolve(ctx context.Context, kc Keychain, target Resource) (Authenticator, error) {
+	auth, err := ResolveWithTimeout(ctx, kc, target, mk.timeout)
+	var te *timeoutError
+	if errors.As(err, &te) {
+		return Anonymous, nil
+	}
+	return auth, err
+}
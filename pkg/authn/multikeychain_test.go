@@ -15,7 +15,10 @@
 package authn
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/name"
 )
@@ -96,3 +99,128 @@ func (fk fixedKeychain) Resolve(target Resource) (Authenticator, error) {
 	}
 	return Anonymous, nil
 }
+
+// hungKeychain never resolves until release is closed, ignoring any context.
+type hungKeychain struct {
+	release chan struct{}
+}
+
+func (hk hungKeychain) Resolve(Resource) (Authenticator, error) {
+	<-hk.release
+	return &Basic{Username: "late", Password: "secret"}, nil
+}
+
+type errKeychain struct {
+	err error
+}
+
+func (ek errKeychain) Resolve(Resource) (Authenticator, error) {
+	return nil, ek.err
+}
+
+func TestMultiKeychainOptions(t *testing.T) {
+	one := &Basic{Username: "one", Password: "secret"}
+	two := &Basic{Username: "two", Password: "secret"}
+
+	regOne, _ := name.NewRegistry("one.gcr.io", name.StrictValidation)
+	regTwo, _ := name.NewRegistry("two.gcr.io", name.StrictValidation)
+
+	hung := hungKeychain{release: make(chan struct{})}
+	t.Cleanup(func() { close(hung.release) })
+	oops := errors.New("oops")
+
+	tests := []struct {
+		name    string
+		reg     name.Registry
+		kcs     []Keychain
+		opts    []MultiKeychainOption
+		want    Authenticator
+		wantErr error
+	}{{
+		name: "parallel skips hung keychain",
+		reg:  regOne,
+		kcs:  []Keychain{hung, fixedKeychain{regOne: one}},
+		opts: []MultiKeychainOption{WithParallelResolution()},
+		want: one,
+	}, {
+		name: "parallel prefers credentials over errors",
+		reg:  regTwo,
+		kcs:  []Keychain{errKeychain{oops}, fixedKeychain{regTwo: two}},
+		opts: []MultiKeychainOption{WithParallelResolution()},
+		want: two,
+	}, {
+		name:    "parallel returns error without credentials",
+		reg:     regTwo,
+		kcs:     []Keychain{fixedKeychain{regOne: one}, errKeychain{oops}},
+		opts:    []MultiKeychainOption{WithParallelResolution()},
+		wantErr: oops,
+	}, {
+		name: "parallel no match",
+		reg:  regTwo,
+		kcs:  []Keychain{fixedKeychain{regOne: one}, fixedKeychain{regOne: two}},
+		opts: []MultiKeychainOption{WithParallelResolution()},
+		want: Anonymous,
+	}, {
+		name: "serial timeout skips hung keychain",
+		reg:  regTwo,
+		kcs:  []Keychain{hung, fixedKeychain{regTwo: two}},
+		opts: []MultiKeychainOption{WithKeychainTimeout(10 * time.Millisecond)},
+		want: two,
+	}, {
+		name: "parallel timeout without credentials",
+		reg:  regOne,
+		kcs:  []Keychain{hung, fixedKeychain{regTwo: two}},
+		opts: []MultiKeychainOption{WithParallelResolution(), WithKeychainTimeout(10 * time.Millisecond)},
+		want: Anonymous,
+	}, {
+		name:    "serial error",
+		reg:     regOne,
+		kcs:     []Keychain{errKeychain{oops}, fixedKeychain{regOne: one}},
+		opts:    []MultiKeychainOption{WithKeychainTimeout(time.Minute)},
+		wantErr: oops,
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kc := NewMultiKeychainWithOptions(test.kcs, test.opts...)
+			got, err := kc.Resolve(test.reg)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Resolve() = %v, wanted %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("Resolve() = %v, wanted %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMultiKeychainTimeoutCanceled(t *testing.T) {
+	hung := hungKeychain{release: make(chan struct{})}
+	defer close(hung.release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reg, _ := name.NewRegistry("one.gcr.io", name.StrictValidation)
+	kc := NewMultiKeychainWithOptions([]Keychain{hung}, WithKeychainTimeout(time.Minute))
+	if _, err := Resolve(ctx, kc, reg); !errors.Is(err, context.Canceled) {
+		t.Errorf("Resolve() = %v, wanted %v", err, context.Canceled)
+	}
+}
+
+func TestResolveWithTimeout(t *testing.T) {
+	hung := hungKeychain{release: make(chan struct{})}
+	defer close(hung.release)
+
+	reg, _ := name.NewRegistry("one.gcr.io", name.StrictValidation)
+	_, err := ResolveWithTimeout(context.Background(), hung, reg, 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ResolveWithTimeout() = %v, wanted %v", err, context.DeadlineExceeded)
+	}
+
+	want := &Basic{Username: "one", Password: "secret"}
+	got, err := ResolveWithTimeout(context.Background(), fixedKeychain{reg: want}, reg, time.Minute)
+	if err != nil || got != want {
+		t.Errorf("ResolveWithTimeout() = %v, %v; wanted %v", got, err, want)
+	}
+}
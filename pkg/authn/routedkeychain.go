@@ -0,0 +1,98 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path"
+	"sort"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+type route struct {
+	pattern  string
+	keychain Keychain
+}
+
+type routedKeychain struct {
+	// Most specific first.
+	routes []route
+}
+
+// Assert that our routed keychain implements ContextKeychain.
+var _ (ContextKeychain) = (*routedKeychain)(nil)
+
+// NewRoutedKeychain returns a Keychain that consults only the keychain whose
+// registry pattern matches the target, so that e.g. a keychain that asks a
+// cloud's metadata server is only used for that cloud's registries:
+//
+//	kc, err := authn.NewRoutedKeychain(map[string]authn.Keychain{
+//		"*.amazonaws.com": ecrKeychain,
+//		"*.pkg.dev":       google.Keychain,
+//		"*":               authn.DefaultKeychain,
+//	})
+//
+// Patterns are registries, with ports if they aren't the default (e.g.
+// "registry.internal:5000"), and may contain path.Match wildcards; patterns
+// without a port match every port. When several patterns match, the longest
+// wins, so "*" can be used for everything else. Targets that match no pattern
+// are anonymous. To try several keychains for a pattern, route it to
+// NewMultiKeychain.
+func NewRoutedKeychain(routes map[string]Keychain) (Keychain, error) {
+	rk := &routedKeychain{}
+	for pattern, kc := range routes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("bad registry pattern %q: %w", pattern, err)
+		}
+		if r, err := name.NewRegistry(pattern); err == nil {
+			// Match Docker Hub, whose registry isn't docker.io.
+			pattern = r.RegistryStr()
+		}
+		rk.routes = append(rk.routes, route{pattern: pattern, keychain: kc})
+	}
+	sort.Slice(rk.routes, func(i, j int) bool {
+		if a, b := rk.routes[i].pattern, rk.routes[j].pattern; len(a) != len(b) {
+			return len(a) > len(b)
+		}
+		return rk.routes[i].pattern < rk.routes[j].pattern
+	})
+	return rk, nil
+}
+
+// Resolve implements Keychain.
+func (rk *routedKeychain) Resolve(target Resource) (Authenticator, error) {
+	return rk.ResolveContext(context.Background(), target)
+}
+
+// ResolveContext implements ContextKeychain.
+func (rk *routedKeychain) ResolveContext(ctx context.Context, target Resource) (Authenticator, error) {
+	reg := target.RegistryStr()
+	host := reg
+	if h, _, err := net.SplitHostPort(reg); err == nil {
+		host = h
+	}
+	for _, r := range rk.routes {
+		if ok, _ := path.Match(r.pattern, reg); ok {
+			return Resolve(ctx, r.keychain, target)
+		}
+		if ok, _ := path.Match(r.pattern, host); ok {
+			return Resolve(ctx, r.keychain, target)
+		}
+	}
+	return Anonymous, nil
+}
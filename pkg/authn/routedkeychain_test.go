@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// anyKeychain resolves every target to auth.
+type anyKeychain struct {
+	auth Authenticator
+}
+
+func (ak anyKeychain) Resolve(Resource) (Authenticator, error) {
+	return ak.auth, nil
+}
+
+func TestRoutedKeychain(t *testing.T) {
+	ecr := &Basic{Username: "ecr", Password: "secret"}
+	hub := &Basic{Username: "hub", Password: "secret"}
+	local := &Basic{Username: "local", Password: "secret"}
+	other := &Basic{Username: "other", Password: "secret"}
+
+	kc, err := NewRoutedKeychain(map[string]Keychain{
+		"*.amazonaws.com":      anyKeychain{ecr},
+		"docker.io":            anyKeychain{hub},
+		"localhost":            anyKeychain{local},
+		"*.internal":           errKeychain{errors.New("should not be consulted")},
+		"registry.example.com": anyKeychain{other},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		reg  string
+		want Authenticator
+	}{
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com", ecr},
+		{"index.docker.io", hub},
+		{"docker.io", hub},
+		{"localhost:5000", local},
+		{"registry.example.com", other},
+		{"gcr.io", Anonymous},
+	} {
+		t.Run(tc.reg, func(t *testing.T) {
+			reg, err := name.NewRegistry(tc.reg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := kc.Resolve(reg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("Resolve(%s) = %v, want %v", tc.reg, got, tc.want)
+			}
+		})
+	}
+
+	t.Run("longest pattern wins", func(t *testing.T) {
+		reg, err := name.NewRegistry("1.dkr.ecr.us-east-1.amazonaws.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		kc, err := NewRoutedKeychain(map[string]Keychain{
+			"*":                         errKeychain{errors.New("fallback won")},
+			"*.amazonaws.com":           errKeychain{errors.New("less specific pattern won")},
+			"*.us-east-1.amazonaws.com": anyKeychain{ecr},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := kc.Resolve(reg); err != nil || got != ecr {
+			t.Errorf("Resolve() = %v, %v; want %v", got, err, ecr)
+		}
+	})
+
+	if _, err := NewRoutedKeychain(map[string]Keychain{"[": DefaultKeychain}); err == nil {
+		t.Error("NewRoutedKeychain([) = nil, want an error")
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// DefaultArtifactFileMediaType is the media type given to artifact files that
+// don't specify one.
+const DefaultArtifactFileMediaType types.MediaType = "application/octet-stream"
+
+// ArtifactFile describes a file to push as a layer of an OCI artifact.
+type ArtifactFile struct {
+	// Path is the file to read.
+	Path string
+
+	// MediaType is the layer's media type. It defaults to
+	// DefaultArtifactFileMediaType.
+	MediaType types.MediaType
+
+	// Title is the layer's org.opencontainers.image.title annotation, which
+	// is the name PullArtifact uses for the file. It defaults to the base
+	// name of Path.
+	Title string
+}
+
+// PushArtifact pushes files as the layers of an OCI artifact with an empty
+// config, and returns the digest reference of the pushed manifest.
+func PushArtifact(dst string, artifactType types.MediaType, files []ArtifactFile, opt ...Option) (name.Digest, error) {
+	o := makeOptions(opt...)
+	ref, err := name.ParseReference(dst, o.Name...)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("parsing reference %q: %w", dst, err)
+	}
+
+	a := &remote.OCIArtifact{ArtifactType: artifactType}
+	for _, f := range files {
+		l, err := ArtifactLayer(f)
+		if err != nil {
+			return name.Digest{}, err
+		}
+		a.Layers = append(a.Layers, l)
+	}
+	if err := remote.WriteArtifact(ref, a, o.Remote...); err != nil {
+		return name.Digest{}, fmt.Errorf("pushing %s: %w", ref, err)
+	}
+	d, err := a.Digest()
+	if err != nil {
+		return name.Digest{}, err
+	}
+	return ref.Context().Digest(d.String()), nil
+}
+
+// ArtifactLayer reads f into a layer annotated with its title.
+func ArtifactLayer(f ArtifactFile) (v1.Layer, error) {
+	b, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	mt := f.MediaType
+	if mt == "" {
+		mt = DefaultArtifactFileMediaType
+	}
+	title := f.Title
+	if title == "" {
+		title = filepath.Base(f.Path)
+	}
+	if err := validTitle(title); err != nil {
+		return nil, err
+	}
+	return &titledLayer{Layer: static.NewLayer(b, mt), title: title}, nil
+}
+
+// titledLayer adds a title annotation to a layer's descriptor.
+type titledLayer struct {
+	v1.Layer
+	title string
+}
+
+// Descriptor implements partial.withDescriptor.
+func (l *titledLayer) Descriptor() (*v1.Descriptor, error) {
+	desc, err := partial.Descriptor(l.Layer)
+	if err != nil {
+		return nil, err
+	}
+	desc.Annotations = map[string]string{specsv1.AnnotationTitle: l.title}
+	return desc, nil
+}
+
+// UncompressedSize implements partial.WithUncompressedSize.
+func (l *titledLayer) UncompressedSize() (int64, error) {
+	return partial.UncompressedSize(l.Layer)
+}
+
+// Exists implements partial.WithExists.
+func (l *titledLayer) Exists() (bool, error) {
+	return partial.Exists(l.Layer)
+}
+
+// validTitle checks that title can safely be used as a file name.
+func validTitle(title string) error {
+	if title == "" || title == "." || title == ".." || filepath.Base(title) != title {
+		return fmt.Errorf("invalid file title %q", title)
+	}
+	return nil
+}
+
+// PullArtifact writes the layers of the OCI artifact at src to files in dir,
+// named after their org.opencontainers.image.title annotations. If titles is
+// not empty, only the layers with those titles are written, and it is an error
+// for any of them to be missing. Otherwise, layers without a title are written
+// to files named after their digest.
+//
+// It returns the paths of the written files.
+func PullArtifact(src, dir string, titles []string, opt ...Option) ([]string, error) {
+	o := makeOptions(opt...)
+	ref, err := name.ParseReference(src, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", src, err)
+	}
+	a, err := remote.Artifact(ref, o.Remote...)
+	if err != nil {
+		return nil, fmt.Errorf("pulling %s: %w", ref, err)
+	}
+
+	want := map[string]bool{}
+	for _, t := range titles {
+		want[t] = true
+	}
+
+	type file struct {
+		layer v1.Layer
+		name  string
+	}
+	files := []file{}
+	for _, l := range a.Layers {
+		desc, err := partial.Descriptor(l)
+		if err != nil {
+			return nil, err
+		}
+		title := desc.Annotations[specsv1.AnnotationTitle]
+		if len(titles) != 0 {
+			if !want[title] {
+				continue
+			}
+			delete(want, title)
+		}
+		if title == "" {
+			title = desc.Digest.Hex
+		} else if err := validTitle(title); err != nil {
+			return nil, fmt.Errorf("layer %s: %w", desc.Digest, err)
+		}
+		files = append(files, file{layer: l, name: title})
+	}
+	for t := range want {
+		return nil, fmt.Errorf("%s has no file titled %q", ref, t)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		path := filepath.Join(dir, f.name)
+		if err := writeLayerFile(f.layer, path); err != nil {
+			return nil, fmt.Errorf("writing %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeLayerFile(l v1.Layer, path string) error {
+	rc, err := l.Compressed()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, rc); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,125 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	specsv1 "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestArtifact(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := fmt.Sprintf("%s/test/artifact:v1", u.Host)
+
+	src := t.TempDir()
+	files := map[string]string{
+		"a.json":  `{"a":1}`,
+		"b.wasm":  "\x00asm",
+		"renamed": "renamed contents",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const artifactType = "application/vnd.example+json"
+	if _, err := crane.PushArtifact(ref, artifactType, []crane.ArtifactFile{
+		{Path: filepath.Join(src, "a.json")},
+		{Path: filepath.Join(src, "b.wasm"), MediaType: "application/wasm"},
+		{Path: filepath.Join(src, "renamed"), Title: "c.txt"},
+	}); err != nil {
+		t.Fatalf("PushArtifact: %v", err)
+	}
+
+	b, err := crane.Manifest(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := v1.ParseManifest(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ArtifactType != artifactType {
+		t.Errorf("ArtifactType: got %q, want %q", m.ArtifactType, artifactType)
+	}
+	if m.Config.MediaType != types.OCIEmptyJSON {
+		t.Errorf("Config.MediaType: got %q, want %q", m.Config.MediaType, types.OCIEmptyJSON)
+	}
+	for i, want := range []struct {
+		title string
+		mt    types.MediaType
+	}{
+		{"a.json", crane.DefaultArtifactFileMediaType},
+		{"b.wasm", "application/wasm"},
+		{"c.txt", crane.DefaultArtifactFileMediaType},
+	} {
+		l := m.Layers[i]
+		if got := l.Annotations[specsv1.AnnotationTitle]; got != want.title {
+			t.Errorf("Layers[%d] title: got %q, want %q", i, got, want.title)
+		}
+		if l.MediaType != want.mt {
+			t.Errorf("Layers[%d] MediaType: got %q, want %q", i, l.MediaType, want.mt)
+		}
+	}
+
+	dst := t.TempDir()
+	paths, err := crane.PullArtifact(ref, dst, nil)
+	if err != nil {
+		t.Fatalf("PullArtifact: %v", err)
+	}
+	if len(paths) != 3 {
+		t.Errorf("PullArtifact: got %d files, want 3", len(paths))
+	}
+	for title, name := range map[string]string{"a.json": "a.json", "b.wasm": "b.wasm", "c.txt": "renamed"} {
+		got, err := os.ReadFile(filepath.Join(dst, title))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != files[name] {
+			t.Errorf("%s: got %q, want %q", title, got, files[name])
+		}
+	}
+
+	one := t.TempDir()
+	if paths, err := crane.PullArtifact(ref, one, []string{"b.wasm"}); err != nil {
+		t.Fatalf("PullArtifact(b.wasm): %v", err)
+	} else if len(paths) != 1 || paths[0] != filepath.Join(one, "b.wasm") {
+		t.Errorf("PullArtifact(b.wasm): got %v", paths)
+	}
+	if _, err := crane.PullArtifact(ref, one, []string{"missing"}); err == nil {
+		t.Error("PullArtifact(missing): expected error")
+	}
+
+	if _, err := crane.ArtifactLayer(crane.ArtifactFile{Path: filepath.Join(src, "a.json"), Title: "../escape"}); err == nil {
+		t.Error("ArtifactLayer: expected error for a title that escapes the directory")
+	}
+}
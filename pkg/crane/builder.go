@@ -0,0 +1,160 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/sync/errgroup"
+)
+
+// Builder builds many images on top of one base image and pushes them, e.g.
+// an image for each of the services in a repository.
+//
+// The base image is pulled once, and what is pushed is shared between
+// builds: the base image's layers are uploaded once per registry, before the
+// first build pushed there, and mounted into the other repositories on it,
+// and other blobs are checked for and uploaded at most once per repository.
+//
+// A Builder is safe for concurrent use.
+type Builder struct {
+	base   v1.Image
+	o      Options
+	pusher *remote.Pusher
+
+	// map[string]*baseUpload, by registry.
+	uploads sync.Map
+}
+
+type baseUpload struct {
+	once sync.Once
+	err  error
+}
+
+// NewBuilder returns a Builder that builds images on top of the remote image
+// base.
+func NewBuilder(base string, opt ...Option) (*Builder, error) {
+	img, err := Pull(base, opt...)
+	if err != nil {
+		return nil, fmt.Errorf("pulling %s: %w", base, err)
+	}
+	return NewBuilderFromImage(img, opt...)
+}
+
+// NewBuilderFromImage returns a Builder that builds images on top of base.
+func NewBuilderFromImage(base v1.Image, opt ...Option) (*Builder, error) {
+	o := makeOptions(opt...)
+
+	// Fetch these now, so that builds don't each have to.
+	if _, err := base.Manifest(); err != nil {
+		return nil, fmt.Errorf("getting base manifest: %w", err)
+	}
+	if _, err := base.ConfigFile(); err != nil {
+		return nil, fmt.Errorf("getting base config: %w", err)
+	}
+	if _, err := base.Layers(); err != nil {
+		return nil, fmt.Errorf("getting base layers: %w", err)
+	}
+
+	pusher, err := remote.NewPusher(o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	return &Builder{
+		base:   base,
+		o:      o,
+		pusher: pusher,
+	}, nil
+}
+
+// Base returns the base image.
+func (b *Builder) Base() v1.Image {
+	return b.base
+}
+
+// Append returns a Build of the base image with layers appended.
+func (b *Builder) Append(layers ...v1.Layer) *Build {
+	img, err := mutate.AppendLayers(b.base, layers...)
+	return &Build{b: b, img: img, err: err}
+}
+
+// Build is an image built by a Builder.
+type Build struct {
+	b   *Builder
+	img v1.Image
+	err error
+}
+
+// Image returns the built image.
+func (bd *Build) Image() (v1.Image, error) {
+	return bd.img, bd.err
+}
+
+// Push pushes the built image to dst, returning its digest.
+func (bd *Build) Push(dst string) (name.Digest, error) {
+	if bd.err != nil {
+		return name.Digest{}, bd.err
+	}
+	ref, err := name.ParseReference(dst, bd.b.o.Name...)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("parsing reference %q: %w", dst, err)
+	}
+	if err := bd.b.uploadBase(bd.b.o.ctx, ref.Context()); err != nil {
+		return name.Digest{}, fmt.Errorf("uploading base layers to %s: %w", ref.Context(), err)
+	}
+	if err := bd.b.pusher.Push(bd.b.o.ctx, ref, bd.img); err != nil {
+		return name.Digest{}, fmt.Errorf("pushing %s: %w", ref, err)
+	}
+	// Streamed layers only have digests once they have been pushed.
+	d, err := bd.img.Digest()
+	if err != nil {
+		return name.Digest{}, err
+	}
+	return ref.Context().Digest(d.String()), nil
+}
+
+// uploadBase uploads the base image's layers to repo, unless they have
+// already been uploaded to its registry, so that concurrent builds pushed to
+// the registry can all mount them rather than each upload them.
+func (b *Builder) uploadBase(ctx context.Context, repo name.Repository) error {
+	v, _ := b.uploads.LoadOrStore(repo.RegistryStr(), &baseUpload{})
+	u := v.(*baseUpload)
+	u.once.Do(func() {
+		layers, err := b.base.Layers()
+		if err != nil {
+			u.err = err
+			return
+		}
+		var g errgroup.Group
+		g.SetLimit(b.o.jobs)
+		for _, l := range layers {
+			g.Go(func() error {
+				return b.pusher.Upload(ctx, repo, l)
+			})
+		}
+		u.err = g.Wait()
+	})
+	if u.err != nil {
+		// Allow the next build pushed to the registry to retry.
+		b.uploads.CompareAndDelete(repo.RegistryStr(), u)
+	}
+	return u.err
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestBuilder(t *testing.T) {
+	var mu sync.Mutex
+	events := map[registry.EventType]int{}
+	pulled := map[v1.Hash]int{}
+	src := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithEventHandler(func(e registry.Event) {
+			mu.Lock()
+			defer mu.Unlock()
+			events[e.Type]++
+			if e.Type == registry.BlobPull {
+				pulled[e.Digest]++
+			}
+		}),
+	))
+	defer src.Close()
+	dst := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer dst.Close()
+	su, err := url.Parse(src.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	du, err := url.Parse(dst.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseRef := su.Host + "/base:latest"
+	if err := crane.Push(base, baseRef); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	events = map[registry.EventType]int{}
+	mu.Unlock()
+
+	b, err := crane.NewBuilder(baseRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var g errgroup.Group
+	for i := 0; i < 5; i++ {
+		g.Go(func() error {
+			l, err := random.Layer(1024, "")
+			if err != nil {
+				return err
+			}
+			dst := fmt.Sprintf("%s/service%d:latest", du.Host, i)
+			d, err := b.Append(l).Push(dst)
+			if err != nil {
+				return err
+			}
+			img, err := crane.Pull(d.String())
+			if err != nil {
+				return err
+			}
+			layers, err := img.Layers()
+			if err != nil {
+				return err
+			}
+			if len(layers) != 4 {
+				return fmt.Errorf("%s has %d layers, want 4", d, len(layers))
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if events[registry.ManifestPull] != 1 {
+		t.Errorf("base manifest pulled %d times, want 1", events[registry.ManifestPull])
+	}
+	// Each base layer is pulled once, for the first push to dst, and mounted
+	// for the rest.
+	layers, err := base.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pulled[h] != 1 {
+			t.Errorf("base layer %s pulled %d times, want 1", h, pulled[h])
+		}
+	}
+}
+
+func TestBuilderMountsAcrossRepositories(t *testing.T) {
+	src := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer src.Close()
+
+	var mu sync.Mutex
+	pushed := map[v1.Hash]int{}
+	mounts := []url.Values{}
+	reg := registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.ProfileHarbor(),
+		registry.WithEventHandler(func(e registry.Event) {
+			if e.Type == registry.BlobPush {
+				mu.Lock()
+				defer mu.Unlock()
+				pushed[e.Digest]++
+			}
+		}),
+	)
+	dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The registry stores blobs once for all its repositories, so don't
+		// let pushes to one repository find blobs pushed to another.
+		if r.Method == http.MethodHead && strings.Contains(r.URL.Path, "/blobs/") {
+			http.NotFound(w, r)
+			return
+		}
+		// The first push can only try to mount the base layers from the
+		// base's registry, so only record mounts into the others.
+		if r.Method == http.MethodPost && r.URL.Query().Has("mount") && !strings.HasPrefix(r.URL.Path, "/v2/service0/") {
+			mu.Lock()
+			mounts = append(mounts, r.URL.Query())
+			mu.Unlock()
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer dst.Close()
+	su, err := url.Parse(src.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	du, err := url.Parse(dst.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseRef := su.Host + "/base:latest"
+	if err := crane.Push(base, baseRef); err != nil {
+		t.Fatal(err)
+	}
+	b, err := crane.NewBuilder(baseRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		l, err := random.Layer(1024, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := b.Append(l).Push(fmt.Sprintf("%s/service%d:latest", du.Host, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Each base layer is uploaded once, and mounted from a repository it was
+	// pushed to, not the base's registry, for the other pushes.
+	mu.Lock()
+	defer mu.Unlock()
+	layers, err := base.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pushed[h] != 1 {
+			t.Errorf("base layer %s uploaded %d times, want 1", h, pushed[h])
+		}
+	}
+	if len(mounts) != 2*len(layers) {
+		t.Errorf("got %d mounts, want %d", len(mounts), 2*len(layers))
+	}
+	for _, q := range mounts {
+		if from, origin := q.Get("from"), q.Get("origin"); !strings.HasPrefix(from, "service") || (origin != "" && origin != du.Host) {
+			t.Errorf("%s mounted from %s/%s, want a repository on %s", q.Get("mount"), origin, from, du.Host)
+		}
+	}
+}
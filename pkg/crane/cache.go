@@ -0,0 +1,65 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// WithLayerCache is a functional option that caches layers in the directory
+// at path. Layers pulled by Pull and Copy are read from the cache if present,
+// and written to it as they are downloaded, so repeated operations on images
+// that share layers only download each layer once.
+func WithLayerCache(path string) Option {
+	return func(o *Options) {
+		o.cache = cache.NewFilesystemCache(path)
+	}
+}
+
+// cacheImage wraps img with the layer cache, if any.
+func (o *Options) cacheImage(img v1.Image) v1.Image {
+	if o.cache == nil {
+		return img
+	}
+	return cache.Image(img, o.cache)
+}
+
+// cacheCopy returns what should be pushed to dst to copy desc, which was
+// fetched from src, going through the layer cache, if any.
+func (o *Options) cacheCopy(desc *remote.Descriptor, src, dst name.Reference) (remote.Taggable, error) {
+	// Copies within a registry can mount blobs without downloading them, and
+	// the cache would hide that they are mountable.
+	if o.cache == nil || src.Context().Registry == dst.Context().Registry {
+		return desc, nil
+	}
+	switch {
+	case desc.MediaType.IsIndex():
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return nil, err
+		}
+		return cache.ImageIndex(idx, o.cache), nil
+	case desc.MediaType.IsImage():
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+		return o.cacheImage(img), nil
+	}
+	return desc, nil
+}
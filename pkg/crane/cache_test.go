@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestWithLayerCache(t *testing.T) {
+	var mu sync.Mutex
+	pulled := map[v1.Hash]int{}
+	src := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithEventHandler(func(e registry.Event) {
+			if e.Type == registry.BlobPull {
+				mu.Lock()
+				defer mu.Unlock()
+				pulled[e.Digest]++
+			}
+		}),
+	))
+	defer src.Close()
+	dst := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer dst.Close()
+	su, err := url.Parse(src.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	du, err := url.Parse(dst.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := fmt.Sprintf("%s/cache/test:latest", su.Host)
+	if err := crane.Push(img, ref); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := crane.WithLayerCache(t.TempDir())
+
+	// Pulling populates the cache as layers are read.
+	pimg, err := crane.Pull(ref, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := pimg.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		rc, err := l.Compressed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			t.Fatal(err)
+		}
+		if err := rc.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Copying to another registry reads layers from the cache.
+	if err := crane.Copy(ref, fmt.Sprintf("%s/cache/copy:latest", du.Host), opt); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		d, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mu.Lock()
+		if got := pulled[d]; got != 1 {
+			t.Errorf("layer %s pulled %d times, want 1", d, got)
+		}
+		mu.Unlock()
+	}
+	copied, err := crane.Pull(fmt.Sprintf("%s/cache/copy:latest", du.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := copied.Digest(); err != nil {
+		t.Fatal(err)
+	} else if got != want {
+		t.Errorf("copied digest: got %s, want %s", got, want)
+	}
+}
@@ -33,3 +33,17 @@ func Catalog(src string, opt ...Option) (res []string, err error) {
 	// crane.WithContext.
 	return remote.Catalog(context.Background(), reg, o.Remote...)
 }
+
+// CatalogWalk calls fn with each page of the repositories in a registry's
+// catalog as it arrives, rather than returning them all at once. It stops at
+// the first error, from the registry or from fn, and when the context passed
+// with WithContext is done.
+func CatalogWalk(src string, fn func(repos []string) error, opt ...Option) error {
+	o := makeOptions(opt...)
+	reg, err := name.NewRegistry(src, o.Name...)
+	if err != nil {
+		return err
+	}
+
+	return remote.CatalogWalk(o.ctx, reg, fn, o.Remote...)
+}
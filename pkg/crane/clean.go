@@ -0,0 +1,182 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/sync/errgroup"
+)
+
+// TagCleanup describes which tags CleanTags deletes.
+type TagCleanup struct {
+	// Patterns are globs (see path.Match) matched against tag names. Only
+	// tags that match at least one pattern are deleted. If there are no
+	// patterns, every tag may be deleted.
+	Patterns []string
+
+	// Protect are globs for tags that are never deleted, even if they match
+	// Patterns.
+	Protect []string
+
+	// OlderThan, if non-zero, only deletes tags whose image was created more
+	// than OlderThan ago. The creation time is read from the manifest's
+	// org.opencontainers.image.created annotation, if present, or else from
+	// the image's config file. Tags whose creation time is unknown (or is the
+	// zero time or the Unix epoch, as in many reproducible builds) are kept.
+	OlderThan time.Duration
+
+	// DryRun, if true, reports which tags would be deleted without deleting
+	// them.
+	DryRun bool
+}
+
+// CleanTags deletes the tags in repo selected by c, returning the tags that
+// were (or, for a dry run, would be) deleted, sorted.
+//
+// Tags are deleted from the registry by tag, not by digest, so images that
+// are referenced by other tags or by digest are left alone. Some registries
+// don't support deleting tags.
+func CleanTags(repo string, c TagCleanup, opt ...Option) ([]string, error) {
+	o := makeOptions(opt...)
+	r, err := name.NewRepository(repo, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing repo %q: %w", repo, err)
+	}
+	for _, p := range append(c.Patterns, c.Protect...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	puller, err := remote.NewPuller(o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	pusher, err := remote.NewPusher(o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := puller.List(o.ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags in %s: %w", r, err)
+	}
+
+	var (
+		mu      sync.Mutex
+		deleted = []string{}
+	)
+	g, ctx := errgroup.WithContext(o.ctx)
+	g.SetLimit(o.jobs)
+	for _, tag := range tags {
+		if !c.selects(tag) {
+			continue
+		}
+		ref := r.Tag(tag)
+		g.Go(func() error {
+			if c.OlderThan > 0 {
+				desc, err := puller.Get(ctx, ref)
+				if err != nil {
+					return fmt.Errorf("fetching %s: %w", ref, err)
+				}
+				created, err := createdTime(desc)
+				if err != nil {
+					return fmt.Errorf("reading creation time of %s: %w", ref, err)
+				}
+				if created.IsZero() || created.Unix() == 0 {
+					logs.Warn.Printf("keeping %s: creation time unknown", ref)
+					return nil
+				}
+				if time.Since(created) <= c.OlderThan {
+					return nil
+				}
+			}
+
+			if c.DryRun {
+				logs.Progress.Printf("Would delete %s", ref)
+			} else {
+				logs.Progress.Printf("Deleting %s", ref)
+				if err := pusher.Delete(ctx, ref); err != nil {
+					return fmt.Errorf("deleting %s: %w", ref, err)
+				}
+			}
+			mu.Lock()
+			deleted = append(deleted, tag)
+			mu.Unlock()
+			return nil
+		})
+	}
+	err = g.Wait()
+	sort.Strings(deleted)
+	return deleted, err
+}
+
+// selects reports whether tag matches c's patterns and isn't protected.
+func (c TagCleanup) selects(tag string) bool {
+	for _, p := range c.Protect {
+		if ok, _ := path.Match(p, tag); ok {
+			return false
+		}
+	}
+	if len(c.Patterns) == 0 {
+		return true
+	}
+	for _, p := range c.Patterns {
+		if ok, _ := path.Match(p, tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// createdTime returns when the image or index desc was created, or the zero
+// time if that's unknown.
+func createdTime(desc *remote.Descriptor) (time.Time, error) {
+	var annotated struct {
+		Annotations v1.Annotations `json:"annotations"`
+	}
+	if err := json.Unmarshal(desc.Manifest, &annotated); err == nil {
+		if t, err := annotated.Annotations.Created(); err == nil && !t.IsZero() {
+			return t, nil
+		}
+	}
+
+	if !desc.MediaType.IsImage() && !desc.MediaType.IsIndex() {
+		return time.Time{}, nil
+	}
+	// For indexes, this resolves to the image for the default platform.
+	img, err := desc.Image()
+	if err != nil {
+		if desc.MediaType.IsIndex() {
+			// There may be no image for the default platform.
+			return time.Time{}, nil
+		}
+		return time.Time{}, err
+	}
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cf.Created.Time, nil
+}
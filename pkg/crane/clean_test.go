@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestCleanTags(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := u.Host + "/clean"
+
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	image := func(created time.Time, annotated *time.Time) v1.Image {
+		img, err := random.Image(10, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err = mutate.CreatedAt(img, v1.Time{Time: created})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if annotated != nil {
+			img = mutate.Annotations(img, map[string]string{
+				"org.opencontainers.image.created": annotated.Format(time.RFC3339),
+			}).(v1.Image)
+		}
+		return img
+	}
+	for tag, img := range map[string]v1.Image{
+		"pr-1": image(old, nil),
+		"pr-2": image(time.Now(), nil),
+		"pr-3": image(time.Now(), &old),
+		"pr-4": image(time.Unix(0, 0), nil),
+		"v1":   image(old, nil),
+		"main": image(old, nil),
+	} {
+		if err := crane.Push(img, repo+":"+tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := crane.TagCleanup{
+		Patterns:  []string{"pr-*", "v*"},
+		Protect:   []string{"v*"},
+		OlderThan: 7 * 24 * time.Hour,
+		DryRun:    true,
+	}
+	want := []string{"pr-1", "pr-3"}
+	all := []string{"main", "pr-1", "pr-2", "pr-3", "pr-4", "v1"}
+
+	got, err := crane.CleanTags(repo, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CleanTags (dry run): (-want +got) %s", diff)
+	}
+	if tags, err := crane.ListTags(repo); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(all, tags); diff != "" {
+		t.Errorf("dry run deleted tags: (-want +got) %s", diff)
+	}
+
+	c.DryRun = false
+	got, err = crane.CleanTags(repo, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("CleanTags: (-want +got) %s", diff)
+	}
+	if tags, err := crane.ListTags(repo); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff([]string{"main", "pr-2", "pr-4", "v1"}, tags); diff != "" {
+		t.Errorf("remaining tags: (-want +got) %s", diff)
+	}
+
+	if _, err := crane.CleanTags(repo, crane.TagCleanup{Patterns: []string{"["}}); err == nil {
+		t.Error("CleanTags: expected error for invalid pattern")
+	}
+}
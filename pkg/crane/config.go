@@ -14,11 +14,23 @@
 
 package crane
 
+import (
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
 // Config returns the config file for the remote image ref.
 func Config(ref string, opt ...Option) ([]byte, error) {
-	i, _, err := getImage(ref, opt...)
+	o := makeOptions(opt...)
+	r, err := name.ParseReference(ref, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", ref, err)
+	}
+	_, raw, err := remote.Config(r, o.Remote...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("reading config %q: %w", r, err)
 	}
-	return i.RawConfigFile()
+	return raw, nil
 }
@@ -17,16 +17,26 @@ package crane
 import (
 	"errors"
 	"fmt"
-	"net/http"
 
 	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
-	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 	"golang.org/x/sync/errgroup"
 )
 
 // Copy copies a remote image or index from src to dst.
+//
+// Every manifest is pushed as the exact bytes read from src, so dst has the
+// same digests as src (unless a platform is set, in which case only the
+// matching image is copied). Use WithDigestVerification to fail if that
+// doesn't hold.
+//
+// Blobs are mounted when src and dst are in the same registry, and otherwise
+// streamed from src to dst without being stored locally, so memory use
+// doesn't grow with their size (see the README of the remote package).
 func Copy(src, dst string, opt ...Option) error {
 	o := makeOptions(opt...)
 	srcRef, err := name.ParseReference(src, o.Name...)
@@ -48,12 +58,7 @@ func Copy(src, dst string, opt ...Option) error {
 		if o.noclobber {
 			logs.Progress.Printf("Checking existing tag %v", tag)
 			head, err := puller.Head(o.ctx, tag)
-			var terr *transport.Error
-			if errors.As(err, &terr) {
-				if terr.StatusCode != http.StatusNotFound && terr.StatusCode != http.StatusForbidden {
-					return err
-				}
-			} else if err != nil {
+			if err != nil && !errors.Is(err, remote.ErrNotFound) && !errors.Is(err, remote.ErrDenied) {
 				return err
 			}
 
@@ -74,8 +79,20 @@ func Copy(src, dst string, opt ...Option) error {
 		return fmt.Errorf("fetching %q: %w", src, err)
 	}
 
+	if len(o.indexPlatforms) != 0 {
+		t, err := o.platformCopy(desc, srcRef, dstRef)
+		if err != nil {
+			return err
+		}
+		return pusher.Push(o.ctx, dstRef, t)
+	}
+
 	if o.Platform == nil {
-		return pusher.Push(o.ctx, dstRef, desc)
+		t, err := o.cacheCopy(desc, srcRef, dstRef)
+		if err != nil {
+			return err
+		}
+		return pusher.Push(o.ctx, dstRef, t)
 	}
 
 	// If platform is explicitly set, don't copy the whole index, just the appropriate image.
@@ -83,10 +100,86 @@ func Copy(src, dst string, opt ...Option) error {
 	if err != nil {
 		return err
 	}
+	if srcRef.Context().Registry != dstRef.Context().Registry {
+		img = o.cacheImage(img)
+	}
 	return pusher.Push(o.ctx, dstRef, img)
 }
 
-// CopyRepository copies every tag from src to dst.
+// CopyPlatforms copies the children of the remote index src that match any of
+// platforms to dst, along with an index that lists only those children.
+//
+// The children keep their digests, but the index is rewritten, so its digest
+// in dst differs from src unless every child matches. If src is an image
+// rather than an index, it is copied unchanged if it matches one of platforms.
+func CopyPlatforms(src, dst string, platforms []v1.Platform, opt ...Option) error {
+	if len(platforms) == 0 {
+		return errors.New("no platforms to copy")
+	}
+	return Copy(src, dst, append(opt, func(o *Options) {
+		o.indexPlatforms = platforms
+	})...)
+}
+
+// platformCopy returns what CopyPlatforms should push to dst for desc.
+func (o *Options) platformCopy(desc *remote.Descriptor, src, dst name.Reference) (remote.Taggable, error) {
+	matches := func(p *v1.Platform) bool {
+		if p == nil {
+			return false
+		}
+		for _, want := range o.indexPlatforms {
+			if p.Satisfies(want) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !desc.MediaType.IsIndex() {
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+		cf, err := img.ConfigFile()
+		if err != nil {
+			return nil, err
+		}
+		if p := cf.Platform(); !matches(p) {
+			return nil, fmt.Errorf("image %s has platform %v, which doesn't match any of the requested platforms", src, p)
+		}
+		return o.cacheCopy(desc, src, dst)
+	}
+
+	idx, err := desc.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	kept := 0
+	for _, child := range im.Manifests {
+		if matches(child.Platform) {
+			kept++
+		}
+	}
+	if kept == 0 {
+		return nil, fmt.Errorf("no children of index %s match the requested platforms", src)
+	}
+	logs.Progress.Printf("Copying %d of %d manifests from %v", kept, len(im.Manifests), src)
+
+	if o.cache != nil && src.Context().Registry != dst.Context().Registry {
+		idx = cache.ImageIndex(idx, o.cache)
+	}
+	return mutate.RemoveManifests(idx, func(child v1.Descriptor) bool {
+		return !matches(child.Platform)
+	}), nil
+}
+
+// CopyRepository copies every tag from src to dst, or the tags given to
+// WithTags. Registries that don't list tags return an error matching
+// remote.ErrTagListingUnsupported, unless the tags are given.
 func CopyRepository(src, dst string, opt ...Option) error {
 	o := makeOptions(opt...)
 
@@ -106,20 +199,19 @@ func CopyRepository(src, dst string, opt ...Option) error {
 	}
 
 	ignoredTags := map[string]struct{}{}
+	// Whether to check each tag in dst, because dst can't be listed.
+	checkTags := false
 	if o.noclobber {
 		// TODO: It would be good to propagate noclobber down into remote so we can use Etags.
 		have, err := puller.List(o.ctx, dstRepo)
-		if err != nil {
-			var terr *transport.Error
-			if errors.As(err, &terr) {
-				// Some registries create repository on first push, so listing tags will fail.
-				// If we see 404 or 403, assume we failed because the repository hasn't been created yet.
-				if !(terr.StatusCode == http.StatusNotFound || terr.StatusCode == http.StatusForbidden) {
-					return err
-				}
-			} else {
-				return err
-			}
+		switch {
+		case errors.Is(err, remote.ErrTagListingUnsupported):
+			logs.Warn.Printf("Can't list the tags in %s, checking them one at a time: %v", dstRepo, err)
+			checkTags = true
+		// Some registries create repository on first push, so listing tags will fail.
+		// If we see 404 or 403, assume we failed because the repository hasn't been created yet.
+		case err != nil && !errors.Is(err, remote.ErrNotFound) && !errors.Is(err, remote.ErrDenied):
+			return err
 		}
 		for _, tag := range have {
 			ignoredTags[tag] = struct{}{}
@@ -131,21 +223,11 @@ func CopyRepository(src, dst string, opt ...Option) error {
 		return err
 	}
 
-	lister, err := puller.Lister(o.ctx, srcRepo)
-	if err != nil {
-		return err
-	}
-
 	g, ctx := errgroup.WithContext(o.ctx)
 	g.SetLimit(o.jobs)
 
-	for lister.HasNext() {
-		tags, err := lister.Next(ctx)
-		if err != nil {
-			return err
-		}
-
-		for _, tag := range tags.Tags {
+	copyTags := func(tags []string) {
+		for _, tag := range tags {
 			tag := tag
 
 			if o.noclobber {
@@ -165,17 +247,48 @@ func CopyRepository(src, dst string, opt ...Option) error {
 					return fmt.Errorf("failed to parse tag: %w", err)
 				}
 
+				if checkTags {
+					if _, err := puller.Head(ctx, dstTag); err == nil {
+						logs.Progress.Printf("Skipping %s due to no-clobber", tag)
+						return nil
+					} else if !errors.Is(err, remote.ErrNotFound) {
+						return err
+					}
+				}
+
 				logs.Progress.Printf("Fetching %s", srcTag)
 				desc, err := puller.Get(ctx, srcTag)
 				if err != nil {
 					return err
 				}
 
+				t, err := o.cacheCopy(desc, srcTag, dstTag)
+				if err != nil {
+					return err
+				}
+
 				logs.Progress.Printf("Pushing %s", dstTag)
-				return pusher.Push(ctx, dstTag, desc)
+				return pusher.Push(ctx, dstTag, t)
 			})
 		}
 	}
 
+	if o.tags != nil {
+		copyTags(o.tags)
+		return g.Wait()
+	}
+
+	lister, err := puller.Lister(o.ctx, srcRepo)
+	if err != nil {
+		return err
+	}
+	for lister.HasNext() {
+		tags, err := lister.Next(ctx)
+		if err != nil {
+			return err
+		}
+		copyTags(tags.Tags)
+	}
+
 	return g.Wait()
 }
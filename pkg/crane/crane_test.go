@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -28,6 +29,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/crane"
 	"github.com/google/go-containerregistry/pkg/name"
@@ -210,6 +212,16 @@ func TestCraneRegistry(t *testing.T) {
 	if len(repos) != 2 {
 		t.Fatalf("wanted 2 repos, got %d", len(repos))
 	}
+	var walked []string
+	if err := crane.CatalogWalk(u.Host, func(page []string) error {
+		walked = append(walked, page...)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(repos, walked); diff != "" {
+		t.Errorf("CatalogWalk() (-want +got) = %s", diff)
+	}
 
 	// Test pushing layer
 	layer, err = img.LayerByDigest(manifest.Layers[1].Digest)
@@ -341,6 +353,145 @@ func TestWithPlatform(t *testing.T) {
 	}
 }
 
+func TestCopyPlatforms(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adds := []mutate.IndexAddendum{}
+	digests := map[string]v1.Hash{}
+	for _, plat := range []string{"linux/amd64", "linux/arm64", "linux/s390x", "windows/amd64"} {
+		p, err := v1.ParsePlatform(plat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := random.Image(1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf, err := img.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf.OS, cf.Architecture = p.OS, p.Architecture
+		if img, err = mutate.ConfigFile(img, cf); err != nil {
+			t.Fatal(err)
+		}
+		if digests[plat], err = img.Digest(); err != nil {
+			t.Fatal(err)
+		}
+		adds = append(adds, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: p}})
+	}
+
+	src := fmt.Sprintf("%s/test/multi", u.Host)
+	ref, err := name.ParseReference(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, mutate.AppendManifests(empty.Index, adds...)); err != nil {
+		t.Fatal(err)
+	}
+
+	platforms := []v1.Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm64"}}
+	dst := fmt.Sprintf("%s/test/edge", u.Host)
+	if err := crane.CopyPlatforms(src, dst, platforms); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := crane.Manifest(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := v1.ParseIndexManifest(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, m := range im.Manifests {
+		got = append(got, m.Platform.String())
+		if want := digests[m.Platform.String()]; m.Digest != want {
+			t.Errorf("%s digest = %s, want %s", m.Platform, m.Digest, want)
+		}
+	}
+	if diff := cmp.Diff([]string{"linux/amd64", "linux/arm64"}, got); diff != "" {
+		t.Errorf("copied platforms (-want +got): %s", diff)
+	}
+	if _, err := crane.Manifest(dst + "@" + digests["linux/s390x"].String()); err == nil {
+		t.Error("linux/s390x was copied")
+	}
+
+	// An image is copied as-is if it matches.
+	img := src + "@" + digests["linux/arm64"].String()
+	if err := crane.CopyPlatforms(img, dst+":arm64", platforms); err != nil {
+		t.Errorf("CopyPlatforms(image) = %v", err)
+	}
+
+	// Copying nothing is an error.
+	if err := crane.CopyPlatforms(src, dst+":none", []v1.Platform{{OS: "plan9", Architecture: "amd64"}}); err == nil {
+		t.Error("CopyPlatforms() with no matching platforms succeeded")
+	}
+	if err := crane.CopyPlatforms(img, dst+":none", []v1.Platform{{OS: "windows"}}); err == nil {
+		t.Error("CopyPlatforms(image) with no matching platforms succeeded")
+	}
+}
+
+func TestCopyRepositoryWithoutTagListing(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	// Like registries with write-only repositories, don't list tags.
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/tags/list") {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, dst := u.Host+"/test/src", u.Host+"/test/dst"
+
+	imgs := map[string]v1.Image{}
+	for _, tag := range []string{"a", "b", "c"} {
+		img, err := random.Image(1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crane.Push(img, src+":"+tag); err != nil {
+			t.Fatal(err)
+		}
+		imgs[tag] = img
+	}
+	// Already in dst, so not copied with WithNoClobber.
+	if err := crane.Push(imgs["a"], dst+":c"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := crane.CopyRepository(src, dst); !errors.Is(err, remote.ErrTagListingUnsupported) {
+		t.Fatalf("CopyRepository() = %v, want ErrTagListingUnsupported", err)
+	}
+
+	if err := crane.CopyRepository(src, dst, crane.WithTags("a", "c"), crane.WithNoClobber(true)); err != nil {
+		t.Fatalf("CopyRepository(WithTags) = %v", err)
+	}
+	for tag, want := range map[string]v1.Image{"a": imgs["a"], "c": imgs["a"]} {
+		got, err := crane.Pull(dst + ":" + tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := compare.Images(got, want); err != nil {
+			t.Errorf("%s:%s: %v", dst, tag, err)
+		}
+	}
+	if _, err := crane.Digest(dst + ":b"); err == nil {
+		t.Errorf("CopyRepository(WithTags) copied %s:b", dst)
+	}
+}
+
 func TestCraneTarball(t *testing.T) {
 	t.Parallel()
 	// Write an image as a tarball.
@@ -560,6 +711,8 @@ func TestBadInputs(t *testing.T) {
 		{"Append(_, invalid)", e(crane.Append(nil, invalid))},
 		{"Catalog(invalid)", e(crane.Catalog(invalid))},
 		{"Catalog(404)", e(crane.Catalog(u.Host))},
+		{"CatalogWalk(invalid)", crane.CatalogWalk(invalid, nil)},
+		{"CatalogWalk(404)", crane.CatalogWalk(u.Host, nil)},
 		{"PullLayer(invalid)", e(crane.PullLayer(invalid))},
 		{"LoadTag(_, invalid)", e(crane.LoadTag("", invalid))},
 		{"LoadTag(invalid, 404)", e(crane.LoadTag(invalid, valid404))},
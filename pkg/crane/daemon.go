@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// WithPreferDaemon is a functional option that makes Pull look for the image
+// in the local docker daemon first, falling back to the registry if the
+// daemon doesn't have it or can't be reached. Callers that read images some
+// other way can use FromDaemon to do the same.
+//
+// Get, Manifest, Config, Digest and Copy always read from the registry,
+// since they deal in manifests, which the daemon doesn't keep.
+//
+// The daemon is found using DOCKER_HOST (with DOCKER_TLS_VERIFY and
+// DOCKER_CERT_PATH for TCP), and only unix sockets and TCP are supported:
+// named pipes and docker contexts aren't, and the registry is used instead. Images read from the daemon have the same config and layer
+// contents as the registry's, but their manifest (and so their digest) may
+// differ, since the daemon doesn't keep the original.
+func WithPreferDaemon() Option {
+	return func(o *Options) {
+		o.preferDaemon = true
+	}
+}
+
+// errNoDaemonImage is returned when the daemon doesn't have an image.
+var errNoDaemonImage = errors.New("image not found in daemon")
+
+// daemonImage reads ref from the local docker daemon.
+//
+// This talks to the Engine API directly rather than using pkg/v1/daemon so
+// that crane doesn't depend on the docker client (see cmd/crane's
+// depcheck_test.go).
+func daemonImage(ctx context.Context, ref name.Reference) (v1.Image, error) {
+	client, base, err := daemonClient()
+	if err != nil {
+		return nil, err
+	}
+
+	get := func(path string, query url.Values) (*http.Response, error) {
+		u := base + path
+		if query != nil {
+			u += "?" + query.Encode()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			resp.Body.Close()
+			return nil, errNoDaemonImage
+		}
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+			return nil, fmt.Errorf("daemon returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+		}
+		return resp, nil
+	}
+
+	// Check that the image exists before asking for the (expensive) export.
+	resp, err := get("/images/"+ref.Name()+"/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	resp, err = get("/images/get", url.Values{"names": {ref.Name()}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Spool the export to disk, since images can be much larger than memory
+	// and tarball.Image reads it more than once.
+	f, err := os.CreateTemp("", "crane-daemon-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	sf := &spoolFile{path: f.Name()}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		sf.remove()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		sf.remove()
+		return nil, err
+	}
+	// The image owns the file through its opener, and removes it once the
+	// image is no longer referenced. The file isn't held open in between, so
+	// it can be removed on every platform.
+	runtime.SetFinalizer(sf, (*spoolFile).remove)
+
+	// The export only contains this image, so there's no need to pick it out
+	// by tag (and digest references have no tag to pick it by).
+	img, err := tarball.Image(sf.open, nil)
+	if err != nil {
+		runtime.SetFinalizer(sf, nil)
+		sf.remove()
+		return nil, err
+	}
+	return img, nil
+}
+
+// spoolFile is a daemon export spooled to disk.
+type spoolFile struct {
+	path string
+}
+
+func (sf *spoolFile) open() (io.ReadCloser, error) {
+	return os.Open(sf.path)
+}
+
+func (sf *spoolFile) remove() {
+	if err := os.Remove(sf.path); err != nil {
+		logs.Debug.Printf("removing %s: %v", sf.path, err)
+	}
+}
+
+// daemonClient returns a client for the daemon at DOCKER_HOST and the base
+// URL to use with it.
+func daemonClient() (*http.Client, string, error) {
+	host := os.Getenv("DOCKER_HOST")
+	if host == "" {
+		host = "unix:///var/run/docker.sock"
+	}
+	proto, addr, ok := strings.Cut(host, "://")
+	if !ok {
+		return nil, "", fmt.Errorf("invalid DOCKER_HOST %q", host)
+	}
+
+	switch proto {
+	case "unix":
+		return &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", addr)
+				},
+			},
+		}, "http://docker", nil
+	case "tcp":
+		if os.Getenv("DOCKER_TLS_VERIFY") == "" {
+			return &http.Client{}, "http://" + addr, nil
+		}
+		cfg, err := daemonTLSConfig()
+		if err != nil {
+			return nil, "", err
+		}
+		return &http.Client{
+			Transport: &http.Transport{TLSClientConfig: cfg},
+		}, "https://" + addr, nil
+	}
+	return nil, "", fmt.Errorf("unsupported DOCKER_HOST protocol %q", proto)
+}
+
+// daemonTLSConfig returns the TLS config for DOCKER_TLS_VERIFY, using the
+// ca.pem, cert.pem and key.pem in DOCKER_CERT_PATH (or ~/.docker), as the
+// docker CLI does.
+func daemonTLSConfig() (*tls.Config, error) {
+	dir := os.Getenv("DOCKER_CERT_PATH")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	ca, err := os.ReadFile(filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", filepath.Join(dir, "ca.pem"))
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem"))
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// FromDaemon returns the image src from the local docker daemon if
+// WithPreferDaemon is set and the daemon has a matching image. Otherwise it
+// returns false, and src should be read from the registry.
+func FromDaemon(src string, opt ...Option) (v1.Image, bool) {
+	o := makeOptions(opt...)
+	ref, err := name.ParseReference(src, o.Name...)
+	if err != nil {
+		return nil, false
+	}
+	img := o.pullFromDaemon(ref)
+	return img, img != nil
+}
+
+// pullFromDaemon returns ref from the daemon if o prefers the daemon and the
+// daemon has a matching image, or nil otherwise.
+func (o *Options) pullFromDaemon(ref name.Reference) v1.Image {
+	if !o.preferDaemon {
+		return nil
+	}
+	img, err := daemonImage(o.ctx, ref)
+	if err != nil {
+		logs.Debug.Printf("not using daemon for %s: %v", ref, err)
+		return nil
+	}
+	if o.Platform != nil {
+		cf, err := img.ConfigFile()
+		if err != nil {
+			logs.Debug.Printf("not using daemon for %s: %v", ref, err)
+			return nil
+		}
+		if p := cf.Platform(); p == nil || !p.Satisfies(*o.Platform) {
+			logs.Debug.Printf("not using daemon for %s: platform %v does not match %v", ref, p, o.Platform)
+			return nil
+		}
+	}
+	logs.Progress.Printf("Using %s from the docker daemon", ref)
+	return img
+}
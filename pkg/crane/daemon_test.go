@@ -0,0 +1,248 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func TestWithPreferDaemon(t *testing.T) {
+	reg := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer reg.Close()
+	u, err := url.Parse(reg.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := fmt.Sprintf("%s/daemon/test:local", u.Host)
+	remote := fmt.Sprintf("%s/daemon/test:remote", u.Host)
+
+	localImg, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteImg, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(remoteImg, remote); err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := name.NewTag(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved bytes.Buffer
+	if err := tarball.Write(tag, localImg, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	// A fake daemon that only has the local image.
+	daemon := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/images/"+tag.Name()+"/json":
+			fmt.Fprint(w, "{}")
+		case r.URL.Path == "/images/get" && r.URL.Query().Get("names") == tag.Name():
+			w.Write(saved.Bytes())
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer daemon.Close()
+	du, err := url.Parse(daemon.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_HOST", "tcp://"+du.Host)
+
+	for _, tc := range []struct {
+		name string
+		ref  string
+		opts []crane.Option
+		want string
+	}{
+		{name: "in daemon", ref: local, opts: []crane.Option{crane.WithPreferDaemon()}, want: "daemon"},
+		{name: "not in daemon", ref: remote, opts: []crane.Option{crane.WithPreferDaemon()}, want: "registry"},
+		{name: "daemon not preferred", ref: remote, want: "registry"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			img, err := crane.Pull(tc.ref, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := remoteImg
+			if tc.want == "daemon" {
+				want = localImg
+			}
+			got, err := img.ConfigName()
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantCfg, err := want.ConfigName()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != wantCfg {
+				t.Errorf("ConfigName: got %s, want %s from the %s", got, wantCfg, tc.want)
+			}
+		})
+	}
+
+	// Images from the daemon are read back from where the export was spooled.
+	img, ok := crane.FromDaemon(local, crane.WithPreferDaemon())
+	if !ok {
+		t.Fatal("FromDaemon: daemon image not found")
+	}
+	if err := validate.Image(img); err != nil {
+		t.Errorf("validate.Image: %v", err)
+	}
+	if _, ok := crane.FromDaemon(local); ok {
+		t.Error("FromDaemon without WithPreferDaemon: got an image")
+	}
+
+	// Without the daemon option, a missing registry image is an error even
+	// though the daemon has it.
+	if _, err := crane.Pull(local); err == nil {
+		t.Error("Pull without WithPreferDaemon: expected error")
+	}
+
+	// An unreachable daemon falls back to the registry.
+	t.Setenv("DOCKER_HOST", "unix:///does/not/exist.sock")
+	if _, err := crane.Pull(remote, crane.WithPreferDaemon()); err != nil {
+		t.Errorf("Pull with unreachable daemon: %v", err)
+	}
+}
+
+func TestWithPreferDaemonTLS(t *testing.T) {
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag("daemon/test:tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var saved bytes.Buffer
+	if err := tarball.Write(tag, img, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	// One self-signed certificate serves as the CA, the daemon's certificate
+	// and the client's.
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "daemon"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	dir := t.TempDir()
+	for name, b := range map[string][]byte{"ca.pem": certPEM, "cert.pem": certPEM, "key.pem": keyPEM} {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(certPEM)
+
+	daemon := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/images/"+tag.Name()+"/json":
+			fmt.Fprint(w, "{}")
+		case r.URL.Path == "/images/get":
+			w.Write(saved.Bytes())
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	daemon.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	}
+	daemon.StartTLS()
+	defer daemon.Close()
+	du, err := url.Parse(daemon.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_HOST", "tcp://"+du.Host)
+	t.Setenv("DOCKER_TLS_VERIFY", "1")
+	t.Setenv("DOCKER_CERT_PATH", dir)
+
+	got, ok := crane.FromDaemon(tag.String(), crane.WithPreferDaemon())
+	if !ok {
+		t.Fatal("FromDaemon: daemon image not found")
+	}
+	if err := validate.Image(got); err != nil {
+		t.Errorf("validate.Image: %v", err)
+	}
+
+	// Without the client certificate, the daemon isn't used.
+	t.Setenv("DOCKER_CERT_PATH", t.TempDir())
+	if _, ok := crane.FromDaemon(tag.String(), crane.WithPreferDaemon()); ok {
+		t.Error("FromDaemon without certificates: got an image")
+	}
+}
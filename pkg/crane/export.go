@@ -15,16 +15,66 @@
 package crane
 
 import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"os"
+	"sort"
 
+	"github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/klauspost/compress/zstd"
 )
 
+// WithExportCompression compresses the tarball written by Export with c,
+// compression.GZip or compression.ZStd. By default, it isn't compressed.
+func WithExportCompression(c compression.Compression) Option {
+	return func(o *Options) {
+		o.exportCompression = c
+	}
+}
+
+// WithSortedExport makes Export write the entries of the tarball sorted by
+// name, followed by the hard links sorted by name, so that images with the
+// same files export to the same tarball however their layers are arranged.
+// The filesystem is spooled to a temporary file to sort it.
+func WithSortedExport() Option {
+	return func(o *Options) {
+		o.exportSorted = true
+	}
+}
+
+// WithExportProvenance makes Export write a JSON object to w, once the
+// tarball has been written, that maps the name of each entry in the tarball
+// to the digest of the layer it comes from.
+func WithExportProvenance(w io.Writer) Option {
+	return func(o *Options) {
+		o.exportProvenance = w
+	}
+}
+
 // Export writes the filesystem contents (as a tarball) of img to w.
 // If img has a single layer, just write the (uncompressed) contents to w so
-// that this "just works" for images that just wrap a single blob.
-func Export(img v1.Image, w io.Writer) error {
+// that this "just works" for images that just wrap a single blob. Those
+// contents aren't a tarball, so WithSortedExport and WithExportProvenance
+// can't be used with them.
+func Export(img v1.Image, w io.Writer, opt ...Option) (err error) {
+	o := makeOptions(opt...)
+
+	cw, err := compressWriter(w, o.exportCompression)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := cw.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
 	layers, err := img.Layers()
 	if err != nil {
 		return err
@@ -40,15 +90,128 @@ func Export(img v1.Image, w io.Writer) error {
 		if !mt.IsLayer() {
 			// ...and isn't an OCI mediaType, we don't have to flatten it.
 			// This lets export work for single layer, non-tarball images.
+			if o.exportSorted || o.exportProvenance != nil {
+				return fmt.Errorf("can't sort or record provenance: the image's only layer is %s, not a tarball", mt)
+			}
 			rc, err := l.Uncompressed()
 			if err != nil {
 				return err
 			}
-			_, err = io.Copy(w, rc)
+			_, err = io.Copy(cw, rc)
 			return err
 		}
 	}
-	fs := mutate.Extract(img)
-	_, err = io.Copy(w, fs)
-	return err
+
+	var fs io.ReadCloser
+	provenance := map[string]v1.Hash{}
+	if o.exportProvenance != nil {
+		digests := make([]v1.Hash, len(layers))
+		for i, l := range layers {
+			if digests[i], err = l.Digest(); err != nil {
+				return err
+			}
+		}
+		fs = mutate.ExtractProvenance(img, func(name string, layer int) {
+			provenance[name] = digests[layer]
+		})
+	} else {
+		fs = mutate.Extract(img)
+	}
+	defer fs.Close()
+
+	if o.exportSorted {
+		err = writeSorted(fs, cw)
+	} else {
+		_, err = io.Copy(cw, fs)
+	}
+	if err != nil {
+		return err
+	}
+
+	if o.exportProvenance != nil {
+		enc := json.NewEncoder(o.exportProvenance)
+		enc.SetIndent("", "  ")
+		return enc.Encode(provenance)
+	}
+	return nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func compressWriter(w io.Writer, c compression.Compression) (io.WriteCloser, error) {
+	switch c {
+	case "", compression.None:
+		return nopWriteCloser{w}, nil
+	case compression.GZip:
+		return gzip.NewWriter(w), nil
+	case compression.ZStd:
+		return zstd.NewWriter(w)
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s", c)
+	}
+}
+
+// writeSorted copies the tarball in r to w, with its entries sorted by name
+// and hard links last, so that they follow their targets.
+func writeSorted(r io.Reader, w io.Writer) error {
+	spool, err := os.CreateTemp("", "crane-export")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	type entry struct {
+		header *tar.Header
+		offset int64
+	}
+	var entries []entry
+	cr := &countingReader{r: io.TeeReader(r, spool)}
+	tr := tar.NewReader(cr)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		// The tar reader doesn't read ahead, so the data starts here.
+		entries = append(entries, entry{header: header, offset: cr.n})
+		if _, err := io.Copy(io.Discard, tr); err != nil {
+			return err
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		li, lj := entries[i].header.Typeflag == tar.TypeLink, entries[j].header.Typeflag == tar.TypeLink
+		if li != lj {
+			return lj
+		}
+		return entries[i].header.Name < entries[j].header.Name
+	})
+
+	tw := tar.NewWriter(w)
+	for _, e := range entries {
+		if err := tw.WriteHeader(e.header); err != nil {
+			return err
+		}
+		if e.header.Size > 0 {
+			if _, err := io.Copy(tw, io.NewSectionReader(spool, e.offset, e.header.Size)); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Close()
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
 }
@@ -15,13 +15,22 @@
 package crane
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/compression"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/static"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/klauspost/compress/zstd"
 )
 
 func TestExport(t *testing.T) {
@@ -38,4 +47,98 @@ func TestExport(t *testing.T) {
 	if got := buf.Bytes(); !bytes.Equal(got, want) {
 		t.Errorf("got: %s\nwant: %s", got, want)
 	}
+
+	// It isn't a tarball, so it can't be sorted or have provenance.
+	for _, opt := range []Option{WithSortedExport(), WithExportProvenance(io.Discard)} {
+		if err := Export(img, io.Discard, opt); err == nil {
+			t.Error("Export() of a non-tarball layer with tarball options succeeded")
+		}
+	}
+}
+
+func TestExportOptions(t *testing.T) {
+	bottom, err := Layer(map[string][]byte{"b": []byte("old"), "a/x": []byte("x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	top, err := Layer(map[string][]byte{"c": []byte("c"), "b": []byte("new")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(empty.Image, bottom, top)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf, prov bytes.Buffer
+	if err := Export(img, &buf, WithExportCompression(compression.GZip), WithSortedExport(), WithExportProvenance(&prov)); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(sorted))
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+		if hdr.Name == "b" {
+			if b, _ := io.ReadAll(tr); string(b) != "new" {
+				t.Errorf("b = %q, want %q", b, "new")
+			}
+		}
+	}
+	if diff := cmp.Diff([]string{"a/x", "b", "c"}, names); diff != "" {
+		t.Errorf("entries (-want +got):\n%s", diff)
+	}
+
+	var got map[string]v1.Hash
+	if err := json.Unmarshal(prov.Bytes(), &got); err != nil {
+		t.Fatalf("parsing provenance %s: %v", prov.String(), err)
+	}
+	bottomDigest, _ := bottom.Digest()
+	topDigest, _ := top.Digest()
+	if diff := cmp.Diff(map[string]v1.Hash{"a/x": bottomDigest, "b": topDigest, "c": topDigest}, got); diff != "" {
+		t.Errorf("provenance (-want +got):\n%s", diff)
+	}
+
+	// The same files in a single layer export to the same tarball.
+	flat, err := Layer(map[string][]byte{"c": []byte("c"), "b": []byte("new"), "a/x": []byte("x")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err = mutate.AppendLayers(empty.Image, flat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := Export(img, &buf, WithExportCompression(compression.ZStd), WithSortedExport()); err != nil {
+		t.Fatal(err)
+	}
+	zd, err := zstd.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zd.Close()
+	other, err := io.ReadAll(zd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sorted, other) {
+		t.Error("sorted exports of the same files differ")
+	}
+
+	if err := Export(img, io.Discard, WithExportCompression("lz4")); err == nil {
+		t.Error("Export with an unknown compression succeeded, want an error")
+	}
 }
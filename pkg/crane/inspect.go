@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"golang.org/x/sync/errgroup"
+)
+
+// Inspection is what Inspect found out about an image or index, in one
+// document.
+type Inspection struct {
+	// Name is the reference that was inspected.
+	Name string `json:"name"`
+	// Descriptor describes the manifest.
+	Descriptor v1.Descriptor `json:"descriptor"`
+	// Manifest is the manifest, as the registry served it.
+	Manifest json.RawMessage `json:"manifest"`
+	// Annotations are the annotations of the manifest.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Config, Platform and Layers are set for images.
+	Config   *v1.ConfigFile   `json:"config,omitempty"`
+	Platform *v1.Platform     `json:"platform,omitempty"`
+	Layers   []InspectedLayer `json:"layers,omitempty"`
+
+	// Manifests are the children of an index.
+	Manifests []InspectedManifest `json:"manifests,omitempty"`
+
+	// TotalSize is the compressed size of the manifest and everything it
+	// refers to, counting blobs shared between the children of an index
+	// once.
+	TotalSize int64 `json:"totalSize"`
+}
+
+// InspectedLayer describes a layer of an inspected image.
+type InspectedLayer struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	// DiffID is the digest of the uncompressed layer, from the config file.
+	DiffID      string            `json:"diffID,omitempty"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// InspectedManifest describes a child of an inspected index.
+type InspectedManifest struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *v1.Platform      `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// TotalSize is the compressed size of the child manifest and everything
+	// it refers to.
+	TotalSize int64 `json:"totalSize"`
+}
+
+// Inspect returns the descriptor, manifest and annotations of the remote
+// image or index ref, along with the config, platform and layers of an image,
+// or the children of an index, and the total compressed size of either.
+//
+// If a platform is set with WithPlatform and ref is an index, the image for
+// that platform is inspected.
+func Inspect(ref string, opt ...Option) (*Inspection, error) {
+	desc, err := getManifest(ref, opt...)
+	if err != nil {
+		return nil, err
+	}
+	o := makeOptions(opt...)
+
+	if desc.MediaType.IsIndex() && o.Platform == nil {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return nil, err
+		}
+		return inspectIndex(ref, desc.Descriptor, desc.Manifest, idx, o.jobs)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, err
+	}
+	return inspectImage(ref, img)
+}
+
+func inspectImage(ref string, img v1.Image) (*Inspection, error) {
+	d, err := partial.Descriptor(img)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := img.RawManifest()
+	if err != nil {
+		return nil, err
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	in := &Inspection{
+		Name:        ref,
+		Descriptor:  *d,
+		Manifest:    raw,
+		Annotations: m.Annotations,
+		Config:      cf,
+		Platform:    cf.Platform(),
+		TotalSize:   d.Size + m.Config.Size,
+	}
+	for i, l := range m.Layers {
+		layer := InspectedLayer{
+			MediaType:   string(l.MediaType),
+			Digest:      l.Digest.String(),
+			Size:        l.Size,
+			Annotations: l.Annotations,
+		}
+		// Foreign layers can make the manifest and rootfs disagree.
+		if len(cf.RootFS.DiffIDs) == len(m.Layers) {
+			layer.DiffID = cf.RootFS.DiffIDs[i].String()
+		}
+		in.Layers = append(in.Layers, layer)
+		in.TotalSize += l.Size
+	}
+	return in, nil
+}
+
+func inspectIndex(ref string, d v1.Descriptor, raw []byte, idx v1.ImageIndex, jobs int) (*Inspection, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	in := &Inspection{
+		Name:        ref,
+		Descriptor:  d,
+		Manifest:    raw,
+		Annotations: im.Annotations,
+		Manifests:   make([]InspectedManifest, len(im.Manifests)),
+		TotalSize:   d.Size,
+	}
+
+	// Children of an index often share blobs, which are only counted once
+	// in the index's total.
+	var mu sync.Mutex
+	seen := map[v1.Hash]bool{}
+	count := func(descs ...v1.Descriptor) int64 {
+		mu.Lock()
+		defer mu.Unlock()
+		var n int64
+		for _, desc := range descs {
+			if !seen[desc.Digest] {
+				seen[desc.Digest] = true
+				n += desc.Size
+			}
+		}
+		return n
+	}
+
+	var g errgroup.Group
+	g.SetLimit(jobs)
+	sizes := make([]int64, len(im.Manifests))
+	for i, child := range im.Manifests {
+		in.Manifests[i] = InspectedManifest{
+			MediaType:   string(child.MediaType),
+			Digest:      child.Digest.String(),
+			Size:        child.Size,
+			Platform:    child.Platform,
+			Annotations: child.Annotations,
+			TotalSize:   child.Size,
+		}
+		g.Go(func() error {
+			var blobs []v1.Descriptor
+			switch {
+			case child.MediaType.IsImage():
+				img, err := idx.Image(child.Digest)
+				if err != nil {
+					return err
+				}
+				m, err := img.Manifest()
+				if err != nil {
+					return err
+				}
+				blobs = append([]v1.Descriptor{child, m.Config}, m.Layers...)
+			case child.MediaType.IsIndex():
+				sub, err := idx.ImageIndex(child.Digest)
+				if err != nil {
+					return err
+				}
+				subIn, err := inspectIndex(ref, child, nil, sub, jobs)
+				if err != nil {
+					return err
+				}
+				// Nested indexes are rare, so blobs they share with the
+				// rest of the index are counted twice.
+				in.Manifests[i].TotalSize = subIn.TotalSize
+				if count(child) != 0 {
+					sizes[i] = subIn.TotalSize
+				}
+				return nil
+			default:
+				blobs = []v1.Descriptor{child}
+			}
+			for _, b := range blobs[1:] {
+				in.Manifests[i].TotalSize += b.Size
+			}
+			sizes[i] = count(blobs...)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, fmt.Errorf("inspecting children of %s: %w", ref, err)
+	}
+	for _, n := range sizes {
+		in.TotalSize += n
+	}
+	return in, nil
+}
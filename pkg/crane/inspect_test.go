@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// compressedSize returns the compressed size of img: its manifest, config and layers.
+func compressedSize(t *testing.T, img v1.Image) int64 {
+	t.Helper()
+	m, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := img.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n += m.Config.Size
+	for _, l := range m.Layers {
+		n += l.Size
+	}
+	return n
+}
+
+func TestInspect(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := u.Host + "/test:latest"
+
+	amd64, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf, err := amd64.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf = cf.DeepCopy()
+	cf.OS, cf.Architecture = "linux", "amd64"
+	if amd64, err = mutate.ConfigFile(amd64, cf); err != nil {
+		t.Fatal(err)
+	}
+	extra, err := random.Layer(512, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// arm64 shares amd64's layers.
+	arm64, err := mutate.AppendLayers(amd64, extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arm64 = mutate.Annotations(arm64, map[string]string{"hello": "world"}).(v1.Image)
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: amd64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "amd64"}}},
+		mutate.IndexAddendum{Add: arm64, Descriptor: v1.Descriptor{Platform: &v1.Platform{OS: "linux", Architecture: "arm64"}}},
+	)
+	ref, err := name.ParseReference(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("index", func(t *testing.T) {
+		in, err := crane.Inspect(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		idxDigest, err := idx.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if in.Descriptor.Digest != idxDigest {
+			t.Errorf("Descriptor.Digest = %s, want %s", in.Descriptor.Digest, idxDigest)
+		}
+		var platforms []string
+		for _, m := range in.Manifests {
+			platforms = append(platforms, m.Platform.String())
+		}
+		if diff := cmp.Diff([]string{"linux/amd64", "linux/arm64"}, platforms); diff != "" {
+			t.Errorf("platforms (-want +got): %s", diff)
+		}
+		if got, want := in.Manifests[1].TotalSize, compressedSize(t, arm64); got != want {
+			t.Errorf("Manifests[1].TotalSize = %d, want %d", got, want)
+		}
+
+		// The layers of amd64 are shared, so are counted once.
+		idxSize, err := idx.Size()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := amd64.Manifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		mSize, err := amd64.Size()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := in.TotalSize, idxSize+compressedSize(t, arm64)+mSize+m.Config.Size; got != want {
+			t.Errorf("TotalSize = %d, want %d", got, want)
+		}
+		if in.Config != nil || in.Layers != nil {
+			t.Errorf("index has config or layers: %+v", in)
+		}
+	})
+
+	t.Run("platform", func(t *testing.T) {
+		in, err := crane.Inspect(src, crane.WithPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := arm64.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if in.Descriptor.Digest != d {
+			t.Errorf("Descriptor.Digest = %s, want %s", in.Descriptor.Digest, d)
+		}
+		if diff := cmp.Diff(map[string]string{"hello": "world"}, in.Annotations); diff != "" {
+			t.Errorf("Annotations (-want +got): %s", diff)
+		}
+		cf, err := arm64.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var diffIDs []string
+		for _, l := range in.Layers {
+			diffIDs = append(diffIDs, l.DiffID)
+		}
+		var want []string
+		for _, h := range cf.RootFS.DiffIDs {
+			want = append(want, h.String())
+		}
+		if diff := cmp.Diff(want, diffIDs); diff != "" {
+			t.Errorf("diffIDs (-want +got): %s", diff)
+		}
+		if got, want := in.TotalSize, compressedSize(t, arm64); got != want {
+			t.Errorf("TotalSize = %d, want %d", got, want)
+		}
+		if in.Manifests != nil {
+			t.Errorf("image has manifests: %+v", in.Manifests)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/sync/errgroup"
+)
+
+// MirrorMapping describes which tags Mirror copies from one repository to
+// another.
+type MirrorMapping struct {
+	// Source is the repository to copy from.
+	Source string
+
+	// Destination is the repository to copy to.
+	Destination string
+
+	// Tags are globs (see path.Match) matched against tag names in Source.
+	// Only tags that match at least one glob are mirrored. If there are no
+	// globs, every tag is mirrored.
+	Tags []string
+
+	// Exclude are globs for tags that are never mirrored, even if they match
+	// Tags.
+	Exclude []string
+
+	// Platforms, if set, limits mirrored indexes to the children that match
+	// any of them, as in CopyPlatforms.
+	Platforms []v1.Platform
+}
+
+// Mirror copies the tags of m.Source selected by m to m.Destination,
+// returning the tags that were copied, sorted.
+//
+// Tags that already point at the same manifest in m.Destination are skipped,
+// so calling Mirror repeatedly only copies what has changed in m.Source.
+// Tags are never deleted from m.Destination. A failure to copy one tag
+// doesn't stop the others from being copied; all of the failures are
+// returned together.
+func Mirror(m MirrorMapping, opt ...Option) ([]string, error) {
+	o := makeOptions(opt...)
+	o.indexPlatforms = m.Platforms
+
+	src, err := name.NewRepository(m.Source, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing repo %q: %w", m.Source, err)
+	}
+	dst, err := name.NewRepository(m.Destination, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing repo %q: %w", m.Destination, err)
+	}
+	for _, p := range append(m.Tags, m.Exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+	}
+
+	puller, err := remote.NewPuller(o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	pusher, err := remote.NewPusher(o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := puller.List(o.ctx, src)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", src, err)
+	}
+
+	var (
+		mu     sync.Mutex
+		copied []string
+		errs   []error
+	)
+	g, ctx := errgroup.WithContext(o.ctx)
+	g.SetLimit(o.jobs)
+	for _, tag := range tags {
+		if !m.selects(tag) {
+			continue
+		}
+		srcTag, dstTag := src.Tag(tag), dst.Tag(tag)
+		g.Go(func() error {
+			ok, err := o.mirrorTag(ctx, puller, pusher, srcTag, dstTag)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("mirroring %s: %w", srcTag, err))
+			} else if ok {
+				copied = append(copied, tag)
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(copied)
+	return copied, errors.Join(errs...)
+}
+
+// selects reports whether m mirrors tag.
+func (m MirrorMapping) selects(tag string) bool {
+	for _, p := range m.Exclude {
+		if ok, _ := path.Match(p, tag); ok {
+			return false
+		}
+	}
+	if len(m.Tags) == 0 {
+		return true
+	}
+	for _, p := range m.Tags {
+		if ok, _ := path.Match(p, tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorTag copies src to dst unless dst is already up to date, reporting
+// whether it copied anything.
+func (o *Options) mirrorTag(ctx context.Context, puller *remote.Puller, pusher *remote.Pusher, src, dst name.Tag) (bool, error) {
+	have, err := puller.Head(ctx, dst)
+	if err != nil {
+		if !errors.Is(err, remote.ErrNotFound) && !errors.Is(err, remote.ErrDenied) {
+			return false, err
+		}
+		have = nil
+	}
+
+	if len(o.indexPlatforms) == 0 {
+		want, err := puller.Head(ctx, src)
+		if err != nil {
+			return false, err
+		}
+		if have != nil && have.Digest == want.Digest {
+			return false, nil
+		}
+	}
+
+	desc, err := puller.Get(ctx, src)
+	if err != nil {
+		return false, err
+	}
+	var t remote.Taggable
+	if len(o.indexPlatforms) == 0 {
+		t, err = o.cacheCopy(desc, src, dst)
+	} else {
+		t, err = o.platformCopy(desc, src, dst)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if have != nil && len(o.indexPlatforms) != 0 {
+		// The filtered index only exists locally, so compare its digest.
+		b, err := t.RawManifest()
+		if err != nil {
+			return false, err
+		}
+		want, _, err := v1.SHA256(bytes.NewReader(b))
+		if err != nil {
+			return false, err
+		}
+		if have.Digest == want {
+			return false, nil
+		}
+	}
+
+	logs.Progress.Printf("Mirroring %v to %v", src, dst)
+	if err := pusher.Push(ctx, dst, t); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestMirror(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, dst := u.Host+"/upstream", u.Host+"/mirror"
+
+	push := func(tag string) {
+		img, err := random.Image(10, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := crane.Push(img, src+":"+tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tag := range []string{"v1", "v2", "v2-rc1", "dev"} {
+		push(tag)
+	}
+
+	m := crane.MirrorMapping{
+		Source:      src,
+		Destination: dst,
+		Tags:        []string{"v*"},
+		Exclude:     []string{"*-rc*"},
+	}
+	mirror := func(want ...string) {
+		t.Helper()
+		got, err := crane.Mirror(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Mirror() (-want +got) = %s", diff)
+		}
+	}
+
+	mirror("v1", "v2")
+	for _, tag := range []string{"v1", "v2"} {
+		want, err := crane.Digest(src + ":" + tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := crane.Digest(dst + ":" + tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%s: digest = %s, want %s", tag, got, want)
+		}
+	}
+	if _, err := crane.Digest(dst + ":dev"); err == nil {
+		t.Error("dev was mirrored, but doesn't match any pattern")
+	}
+
+	// Nothing has changed, so nothing is copied.
+	mirror()
+
+	push("v2")
+	mirror("v2")
+}
+
+func TestMirrorPlatforms(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, dst := u.Host+"/upstream", u.Host+"/mirror"
+
+	adds := []mutate.IndexAddendum{}
+	for _, plat := range []string{"linux/amd64", "linux/arm64"} {
+		p, err := v1.ParsePlatform(plat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := random.Image(10, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		adds = append(adds, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: p}})
+	}
+	idx := mutate.AppendManifests(empty.Index, adds...)
+	ref, err := name.ParseReference(src + ":latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	m := crane.MirrorMapping{
+		Source:      src,
+		Destination: dst,
+		Platforms:   []v1.Platform{{OS: "linux", Architecture: "arm64"}},
+	}
+	for i, want := range [][]string{{"latest"}, nil} {
+		got, err := crane.Mirror(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Mirror() #%d (-want +got) = %s", i, diff)
+		}
+	}
+
+	b, err := crane.Manifest(dst + ":latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := v1.ParseIndexManifest(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(im.Manifests) != 1 || im.Manifests[0].Platform.Architecture != "arm64" {
+		t.Errorf("mirrored index = %+v, want only linux/arm64", im.Manifests)
+	}
+}
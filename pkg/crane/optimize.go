@@ -0,0 +1,359 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/containerd/stargz-snapshotter/estargz"
+	"github.com/containerd/stargz-snapshotter/estargz/zstdchunked"
+	gestargz "github.com/google/go-containerregistry/internal/estargz"
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/sync/errgroup"
+)
+
+// OptimizeFormat is the layer format that Optimize rewrites layers into.
+type OptimizeFormat string
+
+const (
+	// Estargz layers are gzip streams that carry a table of contents, which
+	// lets runtimes like stargz-snapshotter fetch files lazily.
+	Estargz OptimizeFormat = "estargz"
+
+	// ZstdChunked layers are the zstd equivalent of Estargz, as understood by
+	// containers/storage and stargz-snapshotter. Images with ZstdChunked
+	// layers always have OCI manifests.
+	ZstdChunked OptimizeFormat = "zstd:chunked"
+)
+
+// Optimize rewrites the layers of the remote image or index src into format,
+// so that runtimes that pull lazily can start it sooner, and pushes the result
+// to dst.
+//
+// The files named in prioritize (absolute paths within the image) are placed
+// at the front of their layers, to be fetched before anything else. It is an
+// error if one of them isn't in the image.
+//
+// The files in each layer don't change, but they are reordered, and estargz
+// adds entries of its own, so the diff IDs in dst's config differ from src.
+// Layers that aren't tarballs, or aren't distributable, are left alone.
+// WithJobs limits how many layers of an image are rewritten at once; they are
+// held in memory while they are.
+func Optimize(src, dst string, format OptimizeFormat, prioritize []string, opt ...Option) error {
+	switch format {
+	case Estargz, ZstdChunked:
+	default:
+		return fmt.Errorf("unknown format %q, want %q or %q", format, Estargz, ZstdChunked)
+	}
+
+	o := makeOptions(opt...)
+	srcRef, err := name.ParseReference(src, o.Name...)
+	if err != nil {
+		return fmt.Errorf("parsing reference %q: %w", src, err)
+	}
+	dstRef, err := name.ParseReference(dst, o.Name...)
+	if err != nil {
+		return fmt.Errorf("parsing reference for %q: %w", dst, err)
+	}
+
+	logs.Progress.Printf("Optimizing from %v to %v", srcRef, dstRef)
+	desc, err := remote.Get(srcRef, o.Remote...)
+	if err != nil {
+		return fmt.Errorf("fetching %q: %w", src, err)
+	}
+
+	op := &optimizer{format: format, prioritize: prioritize, jobs: o.jobs}
+	switch {
+	case desc.MediaType.IsSchema1():
+		return errors.New("docker schema 1 images are not supported")
+	case desc.MediaType.IsIndex() && o.Platform == nil:
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		oidx, err := op.index(idx)
+		if err != nil {
+			return err
+		}
+		return remote.WriteIndex(dstRef, oidx, o.Remote...)
+	default:
+		// If platform is explicitly set, don't optimize the whole index, just
+		// the appropriate image.
+		img, err := desc.Image()
+		if err != nil {
+			return err
+		}
+		oimg, err := op.image(img)
+		if err != nil {
+			return err
+		}
+		return remote.Write(dstRef, oimg, o.Remote...)
+	}
+}
+
+type optimizer struct {
+	format     OptimizeFormat
+	prioritize []string
+	jobs       int
+}
+
+// index optimizes every image in idx, in place.
+func (opt *optimizer) index(idx v1.ImageIndex) (v1.ImageIndex, error) {
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	oidx := mutate.IndexMediaType(empty.Index, im.MediaType)
+	if len(im.Annotations) != 0 {
+		oidx = mutate.Annotations(oidx, im.Annotations).(v1.ImageIndex)
+	}
+	adds := make([]mutate.IndexAddendum, 0, len(im.Manifests))
+	for _, child := range im.Manifests {
+		var add mutate.Appendable
+		switch {
+		case child.MediaType.IsIndex():
+			cidx, err := idx.ImageIndex(child.Digest)
+			if err != nil {
+				return nil, err
+			}
+			if add, err = opt.index(cidx); err != nil {
+				return nil, err
+			}
+		case child.MediaType.IsImage():
+			img, err := idx.Image(child.Digest)
+			if err != nil {
+				return nil, err
+			}
+			logs.Progress.Printf("Optimizing %s", child.Digest)
+			if add, err = opt.image(img); err != nil {
+				return nil, fmt.Errorf("optimizing %s: %w", child.Digest, err)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected media type %s for %s", child.MediaType, child.Digest)
+		}
+
+		adds = append(adds, mutate.IndexAddendum{
+			Add: add,
+			Descriptor: v1.Descriptor{
+				Platform:    child.Platform,
+				Annotations: child.Annotations,
+			},
+		})
+	}
+	return mutate.AppendManifests(oidx, adds...), nil
+}
+
+// image rewrites the layers of img.
+func (opt *optimizer) image(img v1.Image) (v1.Image, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	if !m.Config.MediaType.IsConfig() {
+		// Artifacts (e.g. attestations) aren't run, so leave them alone.
+		return img, nil
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+
+	mt, cmt := m.MediaType, m.Config.MediaType
+	if opt.format == ZstdChunked {
+		// Docker manifests can't refer to zstd layers.
+		mt, cmt = types.OCIManifestSchema1, types.OCIConfigJSON
+	}
+
+	adds := make([]mutate.Addendum, len(layers))
+	missing := make([][]string, len(layers))
+	var g errgroup.Group
+	g.SetLimit(opt.jobs)
+	for i, layer := range layers {
+		i, layer := i, layer
+		desc := m.Layers[i]
+		g.Go(func() error {
+			add, miss, err := opt.layer(layer, desc, mt)
+			if err != nil {
+				return fmt.Errorf("optimizing layer %s: %w", desc.Digest, err)
+			}
+			adds[i], missing[i] = add, miss
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	if miss := missingFromAll(opt.prioritize, missing); len(miss) != 0 {
+		return nil, fmt.Errorf("prioritized files are missing from the image: %v", miss)
+	}
+
+	// Put the history back alongside the layers it describes, since Append
+	// adds to both.
+	ocfg := cfg.DeepCopy()
+	ocfg.RootFS.DiffIDs, ocfg.History = nil, nil
+	base := mutate.ConfigMediaType(mutate.MediaType(empty.Image, mt), cmt)
+	base, err = mutate.ConfigFile(base, ocfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Annotations) != 0 {
+		base = mutate.Annotations(base, m.Annotations).(v1.Image)
+	}
+	return mutate.Append(base, withHistory(adds, cfg.History)...)
+}
+
+// withHistory pairs adds with history, adding addenda for empty layers. If
+// history doesn't match adds, it is dropped.
+func withHistory(adds []mutate.Addendum, history []v1.History) []mutate.Addendum {
+	out := make([]mutate.Addendum, 0, len(history))
+	i := 0
+	for _, h := range history {
+		if h.EmptyLayer {
+			out = append(out, mutate.Addendum{History: h})
+			continue
+		}
+		if i == len(adds) {
+			return adds
+		}
+		add := adds[i]
+		add.History = h
+		out = append(out, add)
+		i++
+	}
+	if i != len(adds) {
+		return adds
+	}
+	return out
+}
+
+// layer rewrites layer, which desc describes, returning the prioritized
+// files that it doesn't contain.
+func (opt *optimizer) layer(layer v1.Layer, desc v1.Descriptor, mt types.MediaType) (mutate.Addendum, []string, error) {
+	switch desc.MediaType {
+	case types.DockerLayer, types.DockerUncompressedLayer, types.OCILayer, types.OCILayerZStd, types.OCIUncompressedLayer:
+	default:
+		// We don't know how to optimize it, or aren't allowed to push it.
+		return mutate.Addendum{Layer: layer, MediaType: desc.MediaType, Annotations: desc.Annotations, URLs: desc.URLs}, opt.prioritize, nil
+	}
+
+	annotations := map[string]string{}
+	for k, v := range desc.Annotations {
+		annotations[k] = v
+	}
+	// These describe the old blob, if it was already zstd:chunked.
+	delete(annotations, zstdchunked.ManifestChecksumAnnotation)
+	delete(annotations, zstdchunked.ManifestPositionAnnotation)
+
+	missing := []string{}
+	eopts := []estargz.Option{
+		estargz.WithPrioritizedFiles(opt.prioritize),
+		estargz.WithAllowPrioritizeNotFound(&missing),
+	}
+	lmt := types.DockerLayer
+	if mt == types.OCIManifestSchema1 {
+		lmt = types.OCILayer
+	}
+	if opt.format == ZstdChunked {
+		// The compressor records where it put the table of contents in
+		// annotations.
+		eopts = append(eopts, estargz.WithCompression(&zstdChunkedCompression{
+			Compressor: &zstdchunked.Compressor{
+				CompressionLevel: zstd.SpeedDefault,
+				Metadata:         annotations,
+			},
+			Decompressor: &zstdchunked.Decompressor{},
+		}))
+		lmt = types.OCILayerZStd
+	}
+
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return mutate.Addendum{}, nil, err
+	}
+	blob, toc, err := gestargz.ReadCloser(rc, eopts...)
+	if err != nil {
+		return mutate.Addendum{}, nil, err
+	}
+	b, err := io.ReadAll(blob)
+	if err != nil {
+		blob.Close()
+		return mutate.Addendum{}, nil, err
+	}
+	if err := blob.Close(); err != nil {
+		return mutate.Addendum{}, nil, err
+	}
+
+	ol, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}, tarball.WithMediaType(lmt))
+	if err != nil {
+		return mutate.Addendum{}, nil, err
+	}
+	size, err := partial.UncompressedSize(ol)
+	if err != nil {
+		return mutate.Addendum{}, nil, err
+	}
+	annotations[estargz.TOCJSONDigestAnnotation] = toc.String()
+	annotations[estargz.StoreUncompressedSizeAnnotation] = strconv.FormatInt(size, 10)
+	return mutate.Addendum{Layer: ol, MediaType: lmt, Annotations: annotations}, missing, nil
+}
+
+// zstdChunkedCompression is the estargz.Compression for ZstdChunked.
+type zstdChunkedCompression struct {
+	*zstdchunked.Compressor
+	*zstdchunked.Decompressor
+}
+
+// missingFromAll returns the files in prioritize that are in every one of
+// missing, sorted.
+func missingFromAll(prioritize []string, missing [][]string) []string {
+	counts := map[string]int{}
+	for _, files := range missing {
+		seen := map[string]bool{}
+		for _, f := range files {
+			if !seen[f] {
+				seen[f] = true
+				counts[f]++
+			}
+		}
+	}
+	out := []string{}
+	for _, f := range prioritize {
+		if counts[f] == len(missing) {
+			out = append(out, f)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/fs"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/containerd/stargz-snapshotter/estargz"
+	"github.com/containerd/stargz-snapshotter/estargz/zstdchunked"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func TestOptimize(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := u.Host + "/src:latest"
+
+	img, err := mutate.AppendLayers(empty.Image,
+		optimizeLayer(t, "etc/", "etc/config", "usr/", "usr/lib/", "usr/lib/big"),
+		optimizeLayer(t, "app/", "app/main"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, src); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		format  crane.OptimizeFormat
+		mt      types.MediaType
+		lmt     types.MediaType
+		decomps []estargz.Decompressor
+	}{{
+		format: crane.Estargz,
+		mt:     types.DockerManifestSchema2,
+		lmt:    types.DockerLayer,
+	}, {
+		format:  crane.ZstdChunked,
+		mt:      types.OCIManifestSchema1,
+		lmt:     types.OCILayerZStd,
+		decomps: []estargz.Decompressor{new(zstdchunked.Decompressor)},
+	}} {
+		t.Run(string(tc.format), func(t *testing.T) {
+			dst := u.Host + "/dst:" + string(tc.format[:4])
+			if err := crane.Optimize(src, dst, tc.format, []string{"/app/main", "etc/config"}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := crane.Pull(dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validate.Image(got); err != nil {
+				t.Fatalf("validate.Image: %v", err)
+			}
+			for _, name := range []string{"etc/config", "usr/lib/big", "app/main"} {
+				want, err := fs.ReadFile(partial.FS(img), name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got, err := fs.ReadFile(partial.FS(got), name); err != nil {
+					t.Errorf("ReadFile(%q): %v", name, err)
+				} else if !bytes.Equal(got, want) {
+					t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+				}
+			}
+			if mt, err := got.MediaType(); err != nil {
+				t.Fatal(err)
+			} else if mt != tc.mt {
+				t.Errorf("MediaType() = %s, want %s", mt, tc.mt)
+			}
+
+			m, err := got.Manifest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			glayers, err := got.Layers()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, desc := range m.Layers {
+				if desc.MediaType != tc.lmt {
+					t.Errorf("layer %d media type = %s, want %s", i, desc.MediaType, tc.lmt)
+				}
+				if desc.Annotations[estargz.TOCJSONDigestAnnotation] == "" {
+					t.Errorf("layer %d is missing the %s annotation", i, estargz.TOCJSONDigestAnnotation)
+				}
+				if tc.format == crane.ZstdChunked && desc.Annotations[zstdchunked.ManifestChecksumAnnotation] == "" {
+					t.Errorf("layer %d is missing the %s annotation", i, zstdchunked.ManifestChecksumAnnotation)
+				}
+
+				rc, err := glayers[i].Compressed()
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, err := io.ReadAll(rc)
+				rc.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				r, err := estargz.Open(io.NewSectionReader(bytes.NewReader(b), 0, int64(len(b))), estargz.WithDecompressors(tc.decomps...))
+				if err != nil {
+					t.Fatalf("layer %d: estargz.Open: %v", i, err)
+				}
+				if got, want := r.TOCDigest().String(), desc.Annotations[estargz.TOCJSONDigestAnnotation]; got != want {
+					t.Errorf("layer %d TOC digest = %s, want %s", i, got, want)
+				}
+			}
+		})
+	}
+
+	if err := crane.Optimize(src, u.Host+"/dst:missing", crane.Estargz, []string{"/nope"}); err == nil {
+		t.Error("Optimize() with a missing prioritized file succeeded, want error")
+	}
+	if err := crane.Optimize(src, u.Host+"/dst:bogus", "bogus", nil); err == nil {
+		t.Error("Optimize() with an unknown format succeeded, want error")
+	}
+}
+
+// optimizeLayer returns a layer with the given files, and directories for
+// the names that end with a slash, which estargz needs to prioritize files.
+func optimizeLayer(t *testing.T, names ...string) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range names {
+		hdr := &tar.Header{Name: name, Typeflag: tar.TypeDir, Mode: 0o755}
+		var contents []byte
+		if !strings.HasSuffix(name, "/") {
+			contents = bytes.Repeat([]byte(name), 1000)
+			hdr.Typeflag, hdr.Mode, hdr.Size = tar.TypeReg, 0o644, int64(len(contents))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(contents); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
@@ -17,11 +17,15 @@ package crane
 import (
 	"context"
 	"crypto/tls"
+	"io"
 	"net/http"
+	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/compression"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
 )
 
@@ -38,6 +42,22 @@ type Options struct {
 	jobs      int
 	noclobber bool
 	ctx       context.Context
+	cache     cache.Cache
+
+	preferDaemon bool
+
+	// Set by WithProgress.
+	progress chan<- v1.Update
+
+	// Set by CopyPlatforms.
+	indexPlatforms []v1.Platform
+	// Set by WithTags.
+	tags []string
+
+	// Set by the Export options.
+	exportCompression compression.Compression
+	exportSorted      bool
+	exportProvenance  io.Writer
 }
 
 // GetOptions exposes the underlying []remote.Option, []name.Option, and
@@ -99,6 +119,16 @@ func Insecure(o *Options) {
 	o.insecure = true
 }
 
+// WithInsecureRegistries is an Option that allows untrusted (e.g. self-signed)
+// certificates for the given registries only, unlike Insecure. Registries may
+// contain path.Match wildcards, e.g. "*.internal". It can't be combined with
+// a WithTransport that isn't an *http.Transport.
+func WithInsecureRegistries(registries ...string) Option {
+	return func(o *Options) {
+		o.Remote = append(o.Remote, remote.WithInsecureRegistries(registries...))
+	}
+}
+
 // WithPlatform is an Option to specify the platform.
 func WithPlatform(platform *v1.Platform) Option {
 	return func(o *Options) {
@@ -122,6 +152,17 @@ func WithAuthFromKeychain(keys authn.Keychain) Option {
 	}
 }
 
+// WithAuthTimeout bounds how long the keychain may take to resolve
+// credentials for each registry, separately from the transfers that follow.
+// See remote.WithAuthTimeout.
+func WithAuthTimeout(d time.Duration) Option {
+	return func(o *Options) {
+		if d > 0 {
+			o.Remote = append(o.Remote, remote.WithAuthTimeout(d))
+		}
+	}
+}
+
 // WithAuth is a functional option for overriding the default authenticator
 // for remote operations.
 //
@@ -176,3 +217,30 @@ func WithNoClobber(noclobber bool) Option {
 		o.noclobber = noclobber
 	}
 }
+
+// WithTags makes CopyRepository copy the given tags, rather than listing the
+// source repository's, for registries that don't list tags (see
+// remote.ErrTagListingUnsupported).
+func WithTags(tags ...string) Option {
+	return func(o *Options) {
+		o.tags = tags
+	}
+}
+
+// WithDigestVerification makes pushes fail if a manifest would be stored
+// under a different digest than the source's, e.g. because a registry
+// rewrote it. See remote.WithDigestVerification.
+func WithDigestVerification() Option {
+	return func(o *Options) {
+		o.Remote = append(o.Remote, remote.WithDigestVerification())
+	}
+}
+
+// WithProgress sends updates on the bytes written by Push, Copy and MultiSave
+// to updates. See remote.WithProgress and tarball.WithProgress.
+func WithProgress(updates chan<- v1.Update) Option {
+	return func(o *Options) {
+		o.progress = updates
+		o.Remote = append(o.Remote, remote.WithProgress(updates))
+	}
+}
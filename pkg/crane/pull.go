@@ -40,7 +40,15 @@ func Pull(src string, opt ...Option) (v1.Image, error) {
 		return nil, fmt.Errorf("parsing reference %q: %w", src, err)
 	}
 
-	return remote.Image(ref, o.Remote...)
+	if img := o.pullFromDaemon(ref); img != nil {
+		return img, nil
+	}
+
+	img, err := remote.Image(ref, o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	return o.cacheImage(img), nil
 }
 
 // Save writes the v1.Image img as a tarball at path with tag src.
@@ -74,8 +82,11 @@ func MultiSave(imgMap map[string]v1.Image, path string, opt ...Option) error {
 		}
 		tagToImage[tag] = img
 	}
-	// no progress channel (for now)
-	return tarball.MultiWriteToFile(path, tagToImage)
+	var opts []tarball.WriteOption
+	if o.progress != nil {
+		opts = append(opts, tarball.WithProgress(o.progress))
+	}
+	return tarball.MultiWriteToFile(path, tagToImage, opts...)
 }
 
 // PullLayer returns the given layer from a registry.
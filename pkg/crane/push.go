@@ -16,6 +16,10 @@ package crane
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -23,7 +27,8 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 )
 
-// Load reads the tarball at path as a v1.Image.
+// Load reads the tarball at path as a v1.Image. path may be an http or
+// https URL, e.g. of a tarball on a release page.
 func Load(path string, opt ...Option) (v1.Image, error) {
 	return LoadTag(path, "", opt...)
 }
@@ -31,16 +36,32 @@ func Load(path string, opt ...Option) (v1.Image, error) {
 // LoadTag reads a tag from the tarball at path as a v1.Image.
 // If tag is "", will attempt to read the tarball as a single image.
 func LoadTag(path, tag string, opt ...Option) (v1.Image, error) {
+	o := makeOptions(opt...)
+	opener := func() (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+	if isURL(path) {
+		client := http.DefaultClient
+		if o.Transport != nil {
+			client = &http.Client{Transport: o.Transport}
+		}
+		opener = tarball.URLOpener(path, client)
+	}
 	if tag == "" {
-		return tarball.ImageFromPath(path, nil)
+		return tarball.Image(opener, nil)
 	}
 
-	o := makeOptions(opt...)
 	t, err := name.NewTag(tag, o.Name...)
 	if err != nil {
 		return nil, fmt.Errorf("parsing tag %q: %w", tag, err)
 	}
-	return tarball.ImageFromPath(path, &t)
+	return tarball.Image(opener, &t)
+}
+
+// isURL reports whether path is an http or https URL, which Load fetches
+// rather than reading from disk.
+func isURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
 }
 
 // Push pushes the v1.Image img to a registry as dst.
@@ -0,0 +1,176 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Media types for the SBOM formats that crane knows about.
+const (
+	SPDXMediaType      types.MediaType = "application/spdx+json"
+	CycloneDXMediaType types.MediaType = "application/vnd.cyclonedx+json"
+	SyftMediaType      types.MediaType = "application/vnd.syft+json"
+)
+
+var sbomTypes = map[string]types.MediaType{
+	"spdx":      SPDXMediaType,
+	"cyclonedx": CycloneDXMediaType,
+	"syft":      SyftMediaType,
+}
+
+// SBOMMediaType returns the media type for the given SBOM format name
+// (one of "spdx", "cyclonedx" or "syft"). Anything that already looks like
+// a media type is returned unchanged.
+func SBOMMediaType(format string) (types.MediaType, error) {
+	if mt, ok := sbomTypes[strings.ToLower(format)]; ok {
+		return mt, nil
+	}
+	if strings.Contains(format, "/") {
+		return types.MediaType(format), nil
+	}
+	return "", fmt.Errorf("unknown SBOM type %q", format)
+}
+
+func isSBOM(mt string) bool {
+	for _, t := range sbomTypes {
+		if string(t) == mt {
+			return true
+		}
+	}
+	return false
+}
+
+// AttachSBOM pushes sbom as an OCI artifact whose subject is the manifest
+// referenced by ref. The artifact is pushed by digest to the same repository,
+// so it is discoverable via the referrers API (or the referrers tag schema,
+// for registries that don't support the API yet).
+//
+// It returns the digest reference of the pushed artifact.
+func AttachSBOM(ref string, sbom []byte, mt types.MediaType, opt ...Option) (name.Digest, error) {
+	o := makeOptions(opt...)
+	r, err := name.ParseReference(ref, o.Name...)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("parsing reference %q: %w", ref, err)
+	}
+	subject, err := remote.Head(r, o.Remote...)
+	if err != nil {
+		return name.Digest{}, fmt.Errorf("resolving %q: %w", ref, err)
+	}
+
+	img := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	img = mutate.ConfigMediaType(img, mt)
+	img, err = mutate.AppendLayers(img, static.NewLayer(sbom, mt))
+	if err != nil {
+		return name.Digest{}, err
+	}
+	img = mutate.Subject(img, *subject).(v1.Image)
+
+	d, err := img.Digest()
+	if err != nil {
+		return name.Digest{}, err
+	}
+	dst := r.Context().Digest(d.String())
+	if err := remote.Write(dst, img, o.Remote...); err != nil {
+		return name.Digest{}, fmt.Errorf("pushing SBOM: %w", err)
+	}
+	return dst, nil
+}
+
+// SBOMs returns descriptors for every SBOM artifact that refers to the
+// manifest referenced by ref. If mt is not empty, only SBOMs of that media
+// type are returned.
+func SBOMs(ref string, mt types.MediaType, opt ...Option) ([]v1.Descriptor, error) {
+	o := makeOptions(opt...)
+	r, err := name.ParseReference(ref, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", ref, err)
+	}
+	d, ok := r.(name.Digest)
+	if !ok {
+		desc, err := remote.Head(r, o.Remote...)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %q: %w", ref, err)
+		}
+		d = r.Context().Digest(desc.Digest.String())
+	}
+	idx, err := remote.Referrers(d, o.Remote...)
+	if err != nil {
+		return nil, fmt.Errorf("listing referrers of %q: %w", d, err)
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	sboms := []v1.Descriptor{}
+	for _, desc := range im.Manifests {
+		if mt != "" && desc.ArtifactType != string(mt) {
+			continue
+		}
+		if mt == "" && !isSBOM(desc.ArtifactType) {
+			continue
+		}
+		sboms = append(sboms, desc)
+	}
+	return sboms, nil
+}
+
+// SBOM returns the contents of an SBOM attached to the manifest referenced by
+// ref. If mt is not empty, only SBOMs of that media type are considered.
+// When more than one SBOM matches, the first one listed by the registry is
+// returned.
+func SBOM(ref string, mt types.MediaType, opt ...Option) ([]byte, error) {
+	sboms, err := SBOMs(ref, mt, opt...)
+	if err != nil {
+		return nil, err
+	}
+	if len(sboms) == 0 {
+		return nil, fmt.Errorf("no SBOM found for %q", ref)
+	}
+
+	o := makeOptions(opt...)
+	r, err := name.ParseReference(ref, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", ref, err)
+	}
+	img, err := remote.Image(r.Context().Digest(sboms[0].Digest.String()), o.Remote...)
+	if err != nil {
+		return nil, fmt.Errorf("reading SBOM artifact: %w", err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) != 1 {
+		return nil, fmt.Errorf("SBOM artifact %s has %d layers, expected 1", sboms[0].Digest, len(layers))
+	}
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
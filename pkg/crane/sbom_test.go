@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestSBOM(t *testing.T) {
+	for _, referrersAPI := range []bool{true, false} {
+		t.Run(fmt.Sprintf("referrersAPI=%t", referrersAPI), func(t *testing.T) {
+			s := httptest.NewServer(registry.New(registry.WithReferrersSupport(referrersAPI)))
+			defer s.Close()
+			u, err := url.Parse(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref := fmt.Sprintf("%s/test/sbom:latest", u.Host)
+
+			img, err := random.Image(1024, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := crane.Push(img, ref); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := crane.SBOM(ref, ""); err == nil {
+				t.Error("SBOM: expected error before attaching")
+			}
+
+			want := []byte(`{"spdxVersion":"SPDX-2.3"}`)
+			if _, err := crane.AttachSBOM(ref, want, crane.SPDXMediaType); err != nil {
+				t.Fatalf("AttachSBOM: %v", err)
+			}
+
+			sboms, err := crane.SBOMs(ref, "")
+			if err != nil {
+				t.Fatalf("SBOMs: %v", err)
+			}
+			if len(sboms) != 1 {
+				t.Fatalf("SBOMs: got %d, want 1", len(sboms))
+			}
+			if got := sboms[0].ArtifactType; got != string(crane.SPDXMediaType) {
+				t.Errorf("ArtifactType: got %q, want %q", got, crane.SPDXMediaType)
+			}
+
+			got, err := crane.SBOM(ref, crane.SPDXMediaType)
+			if err != nil {
+				t.Fatalf("SBOM: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("SBOM: got %q, want %q", got, want)
+			}
+
+			if sboms, err := crane.SBOMs(ref, crane.CycloneDXMediaType); err != nil {
+				t.Fatal(err)
+			} else if len(sboms) != 0 {
+				t.Errorf("SBOMs(cyclonedx): got %d, want 0", len(sboms))
+			}
+		})
+	}
+}
+
+func TestSBOMMediaType(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "spdx", want: "application/spdx+json"},
+		{in: "CycloneDX", want: "application/vnd.cyclonedx+json"},
+		{in: "application/foo+json", want: "application/foo+json"},
+		{in: "bogus", wantErr: true},
+	} {
+		got, err := crane.SBOMMediaType(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("SBOMMediaType(%q) err = %v, wantErr %t", tc.in, err, tc.wantErr)
+		}
+		if string(got) != tc.want {
+			t.Errorf("SBOMMediaType(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
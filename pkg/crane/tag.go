@@ -15,10 +15,17 @@
 package crane
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/sync/errgroup"
 )
 
 // Tag adds tag to the remote img.
@@ -37,3 +44,119 @@ func Tag(img, tag string, opt ...Option) error {
 
 	return remote.Tag(dst, desc, o.Remote...)
 }
+
+// Retag points the tag Destination at the image that Source refers to.
+type Retag struct {
+	// Source is a reference to the image or index to tag.
+	Source string
+
+	// Destination is the tag to point at it: either a full reference, which
+	// must be in the same registry as Source, or a bare tag, such as "v1",
+	// in Source's repository.
+	Destination string
+}
+
+// RetagAll applies each of retags, returning the destination tags that were
+// (or, for a dry run, would be) pointed at a new manifest, sorted. It is
+// like calling Tag for each, but credentials are resolved and tokens fetched
+// once per repository rather than once per tag, and tags are retagged in
+// parallel.
+//
+// Within a repository, only manifests are pushed. Across repositories, the
+// blobs are mounted from Source's repository, but if the registry refuses to
+// mount one it is uploaded again, as Copy would. Destinations that already
+// point at the same manifest are skipped. A failure to retag one tag doesn't
+// stop the others from being retagged; all of the failures are returned
+// together.
+func RetagAll(retags []Retag, dryRun bool, opt ...Option) ([]string, error) {
+	o := makeOptions(opt...)
+
+	type retag struct {
+		src name.Reference
+		dst name.Tag
+	}
+	var parsed []retag
+	for _, r := range retags {
+		src, err := name.ParseReference(r.Source, o.Name...)
+		if err != nil {
+			return nil, fmt.Errorf("parsing reference %q: %w", r.Source, err)
+		}
+		var dst name.Tag
+		if strings.ContainsAny(r.Destination, ":/") {
+			if dst, err = name.NewTag(r.Destination, o.Name...); err != nil {
+				return nil, fmt.Errorf("parsing tag %q: %w", r.Destination, err)
+			}
+			if dst.RegistryStr() != src.Context().RegistryStr() {
+				return nil, fmt.Errorf("can't retag %s as %s: they're in different registries, use Copy", src, dst)
+			}
+		} else {
+			dst = src.Context().Tag(r.Destination)
+			if _, err := name.NewTag(dst.String(), o.Name...); err != nil {
+				return nil, fmt.Errorf("parsing tag %q: %w", r.Destination, err)
+			}
+		}
+		parsed = append(parsed, retag{src, dst})
+	}
+
+	puller, err := remote.NewPuller(o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+	pusher, err := remote.NewPusher(o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		mu       sync.Mutex
+		retagged []string
+		errs     []error
+	)
+	g, ctx := errgroup.WithContext(o.ctx)
+	g.SetLimit(o.jobs)
+	for _, r := range parsed {
+		g.Go(func() error {
+			ok, err := retagOne(ctx, puller, pusher, r.src, r.dst, dryRun)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("retagging %s as %s: %w", r.src, r.dst, err))
+			} else if ok {
+				retagged = append(retagged, r.dst.String())
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(retagged)
+	return retagged, errors.Join(errs...)
+}
+
+// retagOne points dst at src, reporting whether it had to.
+func retagOne(ctx context.Context, puller *remote.Puller, pusher *remote.Pusher, src name.Reference, dst name.Tag, dryRun bool) (bool, error) {
+	desc, err := puller.Get(ctx, src)
+	if err != nil {
+		return false, err
+	}
+	have, err := puller.Head(ctx, dst)
+	if err == nil && have.Digest == desc.Digest {
+		return false, nil
+	} else if err != nil && !errors.Is(err, remote.ErrNotFound) {
+		return false, err
+	}
+
+	if dryRun {
+		logs.Progress.Printf("Would tag %s as %s (%s)", src, dst, desc.Digest)
+		return true, nil
+	}
+	logs.Progress.Printf("Tagging %s as %s (%s)", src, dst, desc.Digest)
+	if dst.Context().String() == src.Context().String() {
+		return true, pusher.Put(ctx, dst, desc)
+	}
+	// Push mounts the blobs that dst's repository doesn't have yet, or
+	// uploads them if the registry won't mount them.
+	return true, pusher.Push(ctx, dst, desc)
+}
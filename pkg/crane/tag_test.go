@@ -0,0 +1,109 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestRetagAll(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	// Blob uploads, other than mounts, and manifest pushes.
+	var uploads, manifests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPatch || (r.Method == http.MethodPut && r.URL.Query().Has("digest")):
+			uploads.Add(1)
+		case r.Method == http.MethodPut:
+			manifests.Add(1)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app, promoted := u.Host+"/app", u.Host+"/promoted/app"
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(img, app+":rc1"); err != nil {
+		t.Fatal(err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploads.Store(0)
+	manifests.Store(0)
+
+	retags := []crane.Retag{
+		{Source: app + ":rc1", Destination: "v1"},
+		{Source: app + "@" + digest.String(), Destination: promoted + ":v1"},
+	}
+	want := []string{app + ":v1", promoted + ":v1"}
+
+	got, err := crane.RetagAll(retags, true)
+	if err != nil {
+		t.Fatalf("RetagAll(dry run) = %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("RetagAll(dry run): (-want +got) %s", d)
+	}
+	if n := manifests.Load(); n != 0 {
+		t.Errorf("dry run pushed %d manifests", n)
+	}
+
+	if got, err = crane.RetagAll(retags, false); err != nil {
+		t.Fatalf("RetagAll() = %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("RetagAll(): (-want +got) %s", d)
+	}
+	for _, ref := range want {
+		if d, err := crane.Digest(ref); err != nil || d != digest.String() {
+			t.Errorf("Digest(%s) = %s, %v; want %s", ref, d, err, digest)
+		}
+	}
+	if n := uploads.Load(); n != 0 {
+		t.Errorf("RetagAll() uploaded %d blobs, want them mounted", n)
+	}
+
+	// Tags that are already right are skipped.
+	if got, err = crane.RetagAll(retags, false); err != nil || len(got) != 0 {
+		t.Errorf("RetagAll() again = %v, %v; want nothing retagged", got, err)
+	}
+
+	if _, err := crane.RetagAll([]crane.Retag{{Source: app + ":rc1", Destination: "example.com/app:v1"}}, false); err == nil {
+		t.Error("RetagAll() to another registry succeeded")
+	}
+	if _, err := crane.RetagAll([]crane.Retag{{Source: app + ":missing", Destination: "v2"}}, false); err == nil {
+		t.Error("RetagAll() of a missing image succeeded")
+	}
+}
@@ -0,0 +1,311 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"golang.org/x/sync/errgroup"
+)
+
+// Divergence is a difference between an image and its copy, found by
+// VerifyCopy.
+type Divergence struct {
+	// Path locates the difference in the manifest tree, e.g.
+	// "manifest > linux/amd64 > layer 2".
+	Path string
+	// Field is what differs: "digest", "size", "media type", "manifests",
+	// "layers", or "content". It is empty if the copy is missing.
+	Field string
+	// Src and Dst are the values in the source and the copy.
+	Src, Dst string
+}
+
+// String implements fmt.Stringer.
+func (d Divergence) String() string {
+	if d.Field == "" {
+		return fmt.Sprintf("%s: missing from the copy (%s)", d.Path, d.Src)
+	}
+	return fmt.Sprintf("%s: %s differs: %s != %s", d.Path, d.Field, d.Src, d.Dst)
+}
+
+// VerifyCopy walks the manifest trees of the remote image or index src and
+// its copy dst, and returns how they differ: in the digests, sizes and media
+// types of manifests, configs and layers, and in blobs missing from dst.
+//
+// If checkBlobs is positive, the contents of that many randomly chosen blobs
+// of each image in dst are downloaded and checked against their digests; if
+// it is negative, all of them are. Otherwise blobs are only checked for
+// existence.
+//
+// If src is an index, dst is an image and a platform is set with
+// WithPlatform, dst is compared with the image for that platform, as
+// Copy with WithPlatform would have copied it.
+func VerifyCopy(src, dst string, checkBlobs int, opt ...Option) ([]Divergence, error) {
+	o := makeOptions(opt...)
+	srcRef, err := name.ParseReference(src, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", src, err)
+	}
+	dstRef, err := name.ParseReference(dst, o.Name...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing reference %q: %w", dst, err)
+	}
+	puller, err := remote.NewPuller(o.Remote...)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &copyVerifier{
+		puller:     puller,
+		platform:   o.Platform,
+		checkBlobs: checkBlobs,
+		jobs:       o.jobs,
+	}
+	if err := v.manifest(o.ctx, "manifest", srcRef, dstRef, true); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(v.found, func(i, j int) bool {
+		return v.found[i].Path < v.found[j].Path
+	})
+	return v.found, nil
+}
+
+type copyVerifier struct {
+	puller     *remote.Puller
+	platform   *v1.Platform
+	checkBlobs int
+	jobs       int
+
+	mu    sync.Mutex
+	found []Divergence
+}
+
+func (v *copyVerifier) report(d Divergence) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.found = append(v.found, d)
+}
+
+// compare reports the differences between the descriptors at path.
+func (v *copyVerifier) compare(path string, src, dst v1.Descriptor) {
+	if src.Digest != dst.Digest {
+		v.report(Divergence{Path: path, Field: "digest", Src: src.Digest.String(), Dst: dst.Digest.String()})
+	}
+	if src.Size != dst.Size {
+		v.report(Divergence{Path: path, Field: "size", Src: strconv.FormatInt(src.Size, 10), Dst: strconv.FormatInt(dst.Size, 10)})
+	}
+	if src.MediaType != dst.MediaType {
+		v.report(Divergence{Path: path, Field: "media type", Src: string(src.MediaType), Dst: string(dst.MediaType)})
+	}
+}
+
+// manifest compares the manifests src and dst at path, and what they refer
+// to.
+func (v *copyVerifier) manifest(ctx context.Context, path string, src, dst name.Reference, root bool) error {
+	srcDesc, err := v.puller.Get(ctx, src)
+	if err != nil {
+		return fmt.Errorf("getting %s: %w", src, err)
+	}
+	dstDesc, err := v.puller.Get(ctx, dst)
+	if errors.Is(err, remote.ErrNotFound) {
+		v.report(Divergence{Path: path, Src: srcDesc.Digest.String()})
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("getting %s: %w", dst, err)
+	}
+
+	if root && v.platform != nil && srcDesc.MediaType.IsIndex() && dstDesc.MediaType.IsImage() {
+		// dst is a copy of one of src's images.
+		img, err := srcDesc.Image()
+		if err != nil {
+			return fmt.Errorf("getting %s image of %s: %w", v.platform, src, err)
+		}
+		h, err := img.Digest()
+		if err != nil {
+			return err
+		}
+		return v.manifest(ctx, path, src.Context().Digest(h.String()), dst, false)
+	}
+
+	v.compare(path, srcDesc.Descriptor, dstDesc.Descriptor)
+
+	switch {
+	case srcDesc.MediaType.IsIndex() && dstDesc.MediaType.IsIndex():
+		return v.index(ctx, path, src.Context(), dst.Context(), srcDesc, dstDesc)
+	case srcDesc.MediaType.IsImage() && dstDesc.MediaType.IsImage():
+		return v.image(ctx, path, dst.Context(), srcDesc, dstDesc)
+	}
+	return nil
+}
+
+func (v *copyVerifier) index(ctx context.Context, path string, srcRepo, dstRepo name.Repository, src, dst *remote.Descriptor) error {
+	srcIdx, err := v1.ParseIndexManifest(bytes.NewReader(src.Manifest))
+	if err != nil {
+		return err
+	}
+	dstIdx, err := v1.ParseIndexManifest(bytes.NewReader(dst.Manifest))
+	if err != nil {
+		return err
+	}
+	if len(srcIdx.Manifests) != len(dstIdx.Manifests) {
+		v.report(Divergence{Path: path, Field: "manifests", Src: strconv.Itoa(len(srcIdx.Manifests)), Dst: strconv.Itoa(len(dstIdx.Manifests))})
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(v.jobs, 1))
+	for i, child := range srcIdx.Manifests {
+		childPath := fmt.Sprintf("%s > manifest %d", path, i)
+		if child.Platform != nil {
+			childPath = fmt.Sprintf("%s > %s", path, child.Platform)
+		}
+		dstChild, ok := matchChild(child, dstIdx.Manifests, i)
+		if !ok {
+			v.report(Divergence{Path: childPath, Src: child.Digest.String()})
+			continue
+		}
+		v.compare(childPath, child, dstChild)
+		g.Go(func() error {
+			return v.manifest(ctx, childPath, srcRepo.Digest(child.Digest.String()), dstRepo.Digest(dstChild.Digest.String()), false)
+		})
+	}
+	return g.Wait()
+}
+
+// matchChild returns the child of an index in dst that corresponds to src,
+// the ith child in the source: the one with the same digest, or else the one
+// with the same platform, or else the ith.
+func matchChild(src v1.Descriptor, dst []v1.Descriptor, i int) (v1.Descriptor, bool) {
+	for _, d := range dst {
+		if d.Digest == src.Digest {
+			return d, true
+		}
+	}
+	if src.Platform != nil {
+		for _, d := range dst {
+			if d.Platform != nil && d.Platform.Equals(*src.Platform) {
+				return d, true
+			}
+		}
+		return v1.Descriptor{}, false
+	}
+	if i < len(dst) {
+		return dst[i], true
+	}
+	return v1.Descriptor{}, false
+}
+
+func (v *copyVerifier) image(ctx context.Context, path string, dstRepo name.Repository, src, dst *remote.Descriptor) error {
+	srcM, err := v1.ParseManifest(bytes.NewReader(src.Manifest))
+	if err != nil {
+		return err
+	}
+	dstM, err := v1.ParseManifest(bytes.NewReader(dst.Manifest))
+	if err != nil {
+		return err
+	}
+
+	type blob struct {
+		path string
+		desc v1.Descriptor
+	}
+	blobs := []blob{{path + " > config", dstM.Config}}
+	v.compare(path+" > config", srcM.Config, dstM.Config)
+	if len(srcM.Layers) != len(dstM.Layers) {
+		v.report(Divergence{Path: path, Field: "layers", Src: strconv.Itoa(len(srcM.Layers)), Dst: strconv.Itoa(len(dstM.Layers))})
+	}
+	for i, l := range dstM.Layers {
+		layerPath := fmt.Sprintf("%s > layer %d", path, i)
+		if i < len(srcM.Layers) {
+			v.compare(layerPath, srcM.Layers[i], l)
+		}
+		if len(l.URLs) != 0 {
+			// Foreign layers aren't expected to be in the registry.
+			continue
+		}
+		blobs = append(blobs, blob{layerPath, l})
+	}
+
+	check := map[int]bool{}
+	switch {
+	case v.checkBlobs < 0:
+		for i := range blobs {
+			check[i] = true
+		}
+	case v.checkBlobs > 0:
+		for _, i := range rand.Perm(len(blobs))[:min(v.checkBlobs, len(blobs))] { //nolint:gosec
+			check[i] = true
+		}
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(v.jobs, 1))
+	for i, b := range blobs {
+		g.Go(func() error {
+			return v.blob(ctx, b.path, dstRepo.Digest(b.desc.Digest.String()), b.desc, check[i])
+		})
+	}
+	return g.Wait()
+}
+
+// blob checks that the blob ref exists with desc's size and, if check is
+// true, that its contents match its digest.
+func (v *copyVerifier) blob(ctx context.Context, path string, ref name.Digest, desc v1.Descriptor, check bool) error {
+	l, err := v.puller.Layer(ctx, ref)
+	if err != nil {
+		return err
+	}
+	size, err := l.Size()
+	if errors.Is(err, remote.ErrNotFound) {
+		v.report(Divergence{Path: path, Src: desc.Digest.String()})
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("checking %s: %w", ref, err)
+	}
+	if size != desc.Size {
+		v.report(Divergence{Path: path, Field: "size", Src: strconv.FormatInt(desc.Size, 10), Dst: strconv.FormatInt(size, 10)})
+	}
+	if !check {
+		return nil
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", ref, err)
+	}
+	defer rc.Close()
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		var mismatch *remote.BlobMismatchError
+		if !errors.As(err, &mismatch) {
+			return fmt.Errorf("downloading %s: %w", ref, err)
+		}
+		got := mismatch.ActualDigest
+		if got == "" {
+			got = fmt.Sprintf("%d bytes", mismatch.ActualSize)
+		}
+		v.report(Divergence{Path: path, Field: "content", Src: desc.Digest.String(), Dst: got})
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crane_test
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestVerifyCopy(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	var mu sync.Mutex
+	var missing, corrupt string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		m, c := missing, corrupt
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/v2/dst/") {
+			if m != "" && strings.HasSuffix(r.URL.Path, "/blobs/"+m) {
+				http.Error(w, `{"errors":[{"code":"BLOB_UNKNOWN"}]}`, http.StatusNotFound)
+				return
+			}
+			if c != "" && r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/blobs/"+c) {
+				rec := httptest.NewRecorder()
+				reg.ServeHTTP(rec, r)
+				b := rec.Body.Bytes()
+				w.WriteHeader(rec.Code)
+				w.Write(bytes.ToUpper(b))
+				return
+			}
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, dst, other := u.Host+"/src:latest", u.Host+"/dst:latest", u.Host+"/other:latest"
+
+	idx, err := random.Index(1024, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srcRef, err := name.ParseReference(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(srcRef, idx); err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Copy(src, dst); err != nil {
+		t.Fatal(err)
+	}
+
+	// A faithful copy has no divergences, even checking every blob.
+	found, err := crane.VerifyCopy(src, dst, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("VerifyCopy() of a copy = %v, want none", found)
+	}
+
+	// Missing and corrupt blobs are found.
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := idx.Image(im.Manifests[0].Digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	missing, corrupt = m.Layers[0].Digest.String(), m.Layers[1].Digest.String()
+	mu.Unlock()
+	found, err = crane.VerifyCopy(src, dst, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].Field != "" || found[1].Field != "content" {
+		t.Errorf("VerifyCopy() with a missing and a corrupt blob = %v", found)
+	}
+	// Only checking for existence doesn't download the corrupt blob.
+	found, err = crane.VerifyCopy(src, dst, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Field != "" || !strings.HasSuffix(found[0].Path, "> layer 0") {
+		t.Errorf("VerifyCopy() with a missing blob = %v", found)
+	}
+	mu.Lock()
+	missing, corrupt = "", ""
+	mu.Unlock()
+
+	// Different images differ.
+	rnd, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Push(rnd, other); err != nil {
+		t.Fatal(err)
+	}
+	found, err = crane.VerifyCopy(src, other, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) == 0 || found[0].Field != "digest" {
+		t.Errorf("VerifyCopy() of another image = %v, want a digest divergence", found)
+	}
+	found, err = crane.VerifyCopy(src, u.Host+"/missing:latest", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Path != "manifest" || found[0].Field != "" {
+		t.Errorf("VerifyCopy() of a missing image = %v, want it missing", found)
+	}
+
+	// Copies of one platform are compared with that platform's image.
+	platform := v1.Platform{OS: "linux", Architecture: "arm64"}
+	pimg, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pidx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add:        pimg,
+		Descriptor: v1.Descriptor{Platform: &platform},
+	})
+	if err := remote.WriteIndex(srcRef, pidx); err != nil {
+		t.Fatal(err)
+	}
+	if err := crane.Copy(src, dst, crane.WithPlatform(&platform)); err != nil {
+		t.Fatal(err)
+	}
+	found, err = crane.VerifyCopy(src, dst, -1, crane.WithPlatform(&platform))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 0 {
+		t.Errorf("VerifyCopy() of a platform's copy = %v, want none", found)
+	}
+}
@@ -64,7 +64,17 @@ func GCRBackoff() retry.Backoff {
 func Copy(src, dst string, opts ...Option) error {
 	o := makeOptions(opts...)
 	// Just reuse crane's copy logic with gcrane's credential logic.
-	return crane.Copy(src, dst, o.crane...)
+	if err := crane.Copy(src, dst, o.crane...); err != nil {
+		// Parse the references the way crane.Copy did.
+		co := crane.GetOptions(o.crane...)
+		srcRef, serr := name.ParseReference(src, co.Name...)
+		dstRef, derr := name.ParseReference(dst, co.Name...)
+		if serr != nil || derr != nil {
+			return err
+		}
+		return withHint(err, srcRef.Context(), dstRef.Context())
+	}
+	return nil
 }
 
 // CopyRepository copies everything from the src GCR repository to the
@@ -85,8 +95,9 @@ type copier struct {
 	srcRepo name.Repository
 	dstRepo name.Repository
 
-	tasks chan task
-	opt   *options
+	tasks  chan task
+	opt    *options
+	filter *filter
 }
 
 func newCopier(src, dst string, o *options) (*copier, error) {
@@ -100,10 +111,26 @@ func newCopier(src, dst string, o *options) (*copier, error) {
 		return nil, fmt.Errorf("parsing repo %q: %w", dst, err)
 	}
 
+	f := &filter{}
+	for _, s := range o.include {
+		p, err := parsePattern(s)
+		if err != nil {
+			return nil, err
+		}
+		f.include = append(f.include, p)
+	}
+	for _, s := range o.exclude {
+		p, err := parsePattern(s)
+		if err != nil {
+			return nil, err
+		}
+		f.exclude = append(f.exclude, p)
+	}
+
 	// A queue of size 2*jobs should keep each goroutine busy.
 	tasks := make(chan task, o.jobs*2)
 
-	return &copier{srcRepo, dstRepo, tasks, o}, nil
+	return &copier{srcRepo, dstRepo, tasks, o, f}, nil
 }
 
 // recursiveCopy copies images from repo src to repo dst.
@@ -168,13 +195,21 @@ func recursiveCopy(ctx context.Context, src, dst string, o *options) error {
 // contents of newRepo, calculates the diff of what needs to be copied, then
 // starts a goroutine to copy each image we need, and waits for them to finish.
 func (c *copier) copyRepo(ctx context.Context, oldRepo name.Repository, tags *google.Tags) error {
+	if !c.filter.repo(oldRepo.String()) {
+		logs.Progress.Printf("skipping filtered repo %s", oldRepo)
+		return nil
+	}
+
 	newRepo, err := c.rename(oldRepo)
 	if err != nil {
 		return fmt.Errorf("rename failed: %w", err)
 	}
 
 	// Figure out what we actually need to copy.
-	want := tags.Manifests
+	want := c.filter.manifests(oldRepo.String(), tags.Manifests)
+	if len(want) == 0 {
+		return nil
+	}
 	have := make(map[string]google.ManifestInfo)
 	haveTags, err := google.List(newRepo, c.opt.google...)
 	if err != nil {
@@ -208,7 +243,12 @@ func (c *copier) copyRepo(ctx context.Context, oldRepo name.Repository, tags *go
 
 // copyImages starts a goroutine for each tag that points to the image
 // oldRepo@digest, or just copies the image by digest if there are no tags.
-func (c *copier) copyImages(_ context.Context, t task) error {
+func (c *copier) copyImages(ctx context.Context, t task) error {
+	return withHint(c.copyTags(ctx, t), t.oldRepo, t.newRepo)
+}
+
+// copyTags copies oldRepo@digest and its tags to newRepo.
+func (c *copier) copyTags(_ context.Context, t task) error {
 	// We only have to explicitly copy by digest if there are no tags pointing to this manifest.
 	if len(t.manifest.Tags) == 0 {
 		srcImg := fmt.Sprintf("%s@%s", t.oldRepo, t.digest)
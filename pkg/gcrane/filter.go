@@ -0,0 +1,166 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcrane
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/v1/google"
+)
+
+// pattern is a parsed include or exclude filter.
+//
+// A pattern is a glob (see path.Match) that is evaluated against the fully
+// qualified repository name, e.g. "gcr.io/my-project/team-*". A repository
+// pattern also matches every repository nested beneath a matching repository.
+// If the pattern has a tag portion, e.g. "gcr.io/my-project/app:v1.*", it only
+// matches the tags of the matching repositories.
+type pattern struct {
+	repo string
+	tag  string
+}
+
+func parsePattern(s string) (pattern, error) {
+	p := pattern{repo: s}
+	if slash := strings.LastIndex(s, "/"); slash != -1 {
+		if colon := strings.LastIndex(s[slash:], ":"); colon != -1 {
+			p.repo, p.tag = s[:slash+colon], s[slash+colon+1:]
+		}
+	}
+	if _, err := path.Match(p.repo, ""); err != nil {
+		return pattern{}, fmt.Errorf("invalid pattern %q: %w", s, err)
+	}
+	if _, err := path.Match(p.tag, ""); err != nil {
+		return pattern{}, fmt.Errorf("invalid pattern %q: %w", s, err)
+	}
+	return p, nil
+}
+
+// matchesRepo returns true if repo, or any repository that contains it,
+// matches the repository portion of p.
+func (p pattern) matchesRepo(repo string) bool {
+	for {
+		if ok, _ := path.Match(p.repo, repo); ok {
+			return true
+		}
+		i := strings.LastIndex(repo, "/")
+		if i == -1 {
+			return false
+		}
+		repo = repo[:i]
+	}
+}
+
+func (p pattern) matchesTag(tag string) bool {
+	ok, _ := path.Match(p.tag, tag)
+	return ok
+}
+
+// filter decides which repositories and tags are copied by CopyRepository.
+type filter struct {
+	include []pattern
+	exclude []pattern
+}
+
+// repo returns whether anything in repo might be copied.
+func (f *filter) repo(repo string) bool {
+	for _, p := range f.exclude {
+		if p.tag == "" && p.matchesRepo(repo) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, p := range f.include {
+		if p.matchesRepo(repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// tags returns a predicate that decides whether a tag in repo is copied, and
+// whether untagged manifests in repo are copied.
+func (f *filter) tags(repo string) (func(tag string) bool, bool) {
+	var includes, excludes []pattern
+	allTags := len(f.include) == 0
+	for _, p := range f.include {
+		if !p.matchesRepo(repo) {
+			continue
+		}
+		if p.tag == "" {
+			allTags = true
+		} else {
+			includes = append(includes, p)
+		}
+	}
+	for _, p := range f.exclude {
+		if p.tag != "" && p.matchesRepo(repo) {
+			excludes = append(excludes, p)
+		}
+	}
+
+	keep := func(tag string) bool {
+		for _, p := range excludes {
+			if p.matchesTag(tag) {
+				return false
+			}
+		}
+		if allTags {
+			return true
+		}
+		for _, p := range includes {
+			if p.matchesTag(tag) {
+				return true
+			}
+		}
+		return false
+	}
+	return keep, allTags
+}
+
+// manifests returns the subset of manifests in repo that pass the filter,
+// with any filtered tags removed.
+func (f *filter) manifests(repo string, manifests map[string]google.ManifestInfo) map[string]google.ManifestInfo {
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return manifests
+	}
+
+	keep, untagged := f.tags(repo)
+	out := make(map[string]google.ManifestInfo, len(manifests))
+	for digest, m := range manifests {
+		if len(m.Tags) == 0 {
+			if untagged {
+				out[digest] = m
+			}
+			continue
+		}
+		tags := []string{}
+		for _, tag := range m.Tags {
+			if keep(tag) {
+				tags = append(tags, tag)
+			}
+		}
+		if len(tags) == 0 {
+			continue
+		}
+		m.Tags = tags
+		out[digest] = m
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcrane
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/v1/google"
+)
+
+func TestFilter(t *testing.T) {
+	manifests := map[string]google.ManifestInfo{
+		"sha256:a": {Tags: []string{"v1.0", "latest"}},
+		"sha256:b": {Tags: []string{"v2.0"}},
+		"sha256:c": {Tags: []string{}},
+	}
+
+	for _, tc := range []struct {
+		name             string
+		include, exclude []string
+		repo             string
+		wantRepo         bool
+		want             map[string][]string
+	}{{
+		name:     "no filters",
+		repo:     "gcr.io/p/app",
+		wantRepo: true,
+		want:     map[string][]string{"sha256:a": {"v1.0", "latest"}, "sha256:b": {"v2.0"}, "sha256:c": {}},
+	}, {
+		name:     "include nested",
+		include:  []string{"gcr.io/p/team-*"},
+		repo:     "gcr.io/p/team-a/app",
+		wantRepo: true,
+		want:     map[string][]string{"sha256:a": {"v1.0", "latest"}, "sha256:b": {"v2.0"}, "sha256:c": {}},
+	}, {
+		name:     "not included",
+		include:  []string{"gcr.io/p/team-*"},
+		repo:     "gcr.io/p/other",
+		wantRepo: false,
+	}, {
+		name:     "exclude wins",
+		include:  []string{"gcr.io/p"},
+		exclude:  []string{"gcr.io/p/app"},
+		repo:     "gcr.io/p/app/sub",
+		wantRepo: false,
+	}, {
+		name:     "include tags",
+		include:  []string{"gcr.io/p/app:v*"},
+		repo:     "gcr.io/p/app",
+		wantRepo: true,
+		want:     map[string][]string{"sha256:a": {"v1.0"}, "sha256:b": {"v2.0"}},
+	}, {
+		name:     "exclude tags",
+		exclude:  []string{"gcr.io/p/*:v1.*"},
+		repo:     "gcr.io/p/app",
+		wantRepo: true,
+		want:     map[string][]string{"sha256:a": {"latest"}, "sha256:b": {"v2.0"}, "sha256:c": {}},
+	}, {
+		name:     "port in host",
+		include:  []string{"localhost:5000/p/app"},
+		repo:     "localhost:5000/p/app",
+		wantRepo: true,
+		want:     map[string][]string{"sha256:a": {"v1.0", "latest"}, "sha256:b": {"v2.0"}, "sha256:c": {}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := newCopier("gcr.io/proj", "gcr.io/dest", makeOptions(WithInclude(tc.include...), WithExclude(tc.exclude...)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.filter.repo(tc.repo); got != tc.wantRepo {
+				t.Fatalf("repo(%q) = %t, want %t", tc.repo, got, tc.wantRepo)
+			}
+			if !tc.wantRepo {
+				return
+			}
+			got := map[string][]string{}
+			for digest, m := range c.filter.manifests(tc.repo, manifests) {
+				got[digest] = m.Tags
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("manifests (-want +got): %s", diff)
+			}
+		})
+	}
+}
+
+func TestFilterBadPattern(t *testing.T) {
+	if _, err := newCopier("gcr.io/proj", "gcr.io/dest", makeOptions(WithInclude("gcr.io/p/["))); err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcrane
+
+import (
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/google"
+)
+
+// RetentionPolicy decides which images in a repository are garbage. The zero
+// value treats every untagged image as garbage.
+type RetentionPolicy struct {
+	// Keep is the number of most recently uploaded images that are kept,
+	// whether or not they are tagged.
+	Keep int
+
+	// TagFilter, if set, makes a tagged image garbage if every one of its
+	// tags matches TagFilter. Otherwise, tagged images are always kept.
+	TagFilter *regexp.Regexp
+
+	// OlderThan, if non-zero, keeps images that were uploaded less than
+	// OlderThan ago.
+	OlderThan time.Duration
+}
+
+// Garbage returns the digests of the images listed in tags that p doesn't
+// keep, from the least to the most recently uploaded.
+//
+// Images that are only referenced by an index are listed without tags, so
+// they are garbage unless something else keeps them; callers that delete
+// the garbage should exclude the children of the indexes they keep.
+func (p RetentionPolicy) Garbage(tags *google.Tags) []string {
+	digests := make([]string, 0, len(tags.Manifests))
+	for digest := range tags.Manifests {
+		digests = append(digests, digest)
+	}
+	// Newest first, so the first Keep are the ones to keep.
+	sort.Slice(digests, func(i, j int) bool {
+		a, b := tags.Manifests[digests[i]], tags.Manifests[digests[j]]
+		if !a.Uploaded.Equal(b.Uploaded) {
+			return a.Uploaded.After(b.Uploaded)
+		}
+		if !a.Created.Equal(b.Created) {
+			return a.Created.After(b.Created)
+		}
+		return digests[i] > digests[j]
+	})
+
+	var cutoff time.Time
+	if p.OlderThan != 0 {
+		cutoff = time.Now().Add(-p.OlderThan)
+	}
+
+	garbage := []string{}
+	for i, digest := range digests {
+		m := tags.Manifests[digest]
+		if i < p.Keep || !p.collectable(m.Tags) {
+			continue
+		}
+		if !cutoff.IsZero() && m.Uploaded.After(cutoff) {
+			continue
+		}
+		garbage = append(garbage, digest)
+	}
+
+	// Oldest first.
+	for i, j := 0, len(garbage)-1; i < j; i, j = i+1, j-1 {
+		garbage[i], garbage[j] = garbage[j], garbage[i]
+	}
+	return garbage
+}
+
+// collectable reports whether an image with the given tags may be garbage.
+func (p RetentionPolicy) collectable(tags []string) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	if p.TagFilter == nil {
+		return false
+	}
+	for _, tag := range tags {
+		if !p.TagFilter.MatchString(tag) {
+			return false
+		}
+	}
+	return true
+}
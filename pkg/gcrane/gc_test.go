@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcrane
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/v1/google"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(n int) time.Time {
+		return now.Add(-time.Duration(n) * 24 * time.Hour)
+	}
+	tags := &google.Tags{
+		Manifests: map[string]google.ManifestInfo{
+			"sha256:a": {Uploaded: daysAgo(90)},
+			"sha256:b": {Uploaded: daysAgo(60), Tags: []string{"pr-1"}},
+			"sha256:c": {Uploaded: daysAgo(50), Tags: []string{"pr-2", "v1.0"}},
+			"sha256:d": {Uploaded: daysAgo(40)},
+			"sha256:e": {Uploaded: daysAgo(5), Tags: []string{"pr-3"}},
+			"sha256:f": {Uploaded: daysAgo(1)},
+		},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		policy RetentionPolicy
+		want   []string
+	}{{
+		name: "untagged",
+		want: []string{"sha256:a", "sha256:d", "sha256:f"},
+	}, {
+		name:   "keep",
+		policy: RetentionPolicy{Keep: 2},
+		want:   []string{"sha256:a", "sha256:d"},
+	}, {
+		name:   "tag filter",
+		policy: RetentionPolicy{TagFilter: regexp.MustCompile(`^pr-`)},
+		want:   []string{"sha256:a", "sha256:b", "sha256:d", "sha256:e", "sha256:f"},
+	}, {
+		name:   "older than",
+		policy: RetentionPolicy{OlderThan: 30 * 24 * time.Hour, TagFilter: regexp.MustCompile(`^pr-`)},
+		want:   []string{"sha256:a", "sha256:b", "sha256:d"},
+	}, {
+		name:   "everything",
+		policy: RetentionPolicy{Keep: 4, OlderThan: 30 * 24 * time.Hour, TagFilter: regexp.MustCompile(`.`)},
+		want:   []string{"sha256:a", "sha256:b"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tc.policy.Garbage(tags)); diff != "" {
+				t.Errorf("Garbage() (-want +got) = %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcrane
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// HintError is an error from copying between Google registries, such as
+// from Container Registry (gcr.io) to Artifact Registry (pkg.dev), with a
+// hint about how to fix it, e.g. which IAM role is missing.
+type HintError struct {
+	Err  error
+	Hint string
+}
+
+// Error implements error.
+func (e *HintError) Error() string {
+	return fmt.Sprintf("%v\nhint: %s", e.Err, e.Hint)
+}
+
+// Unwrap returns the error that the hint is for.
+func (e *HintError) Unwrap() error {
+	return e.Err
+}
+
+// googleRepo is where a repository lives in Container Registry or Artifact
+// Registry.
+type googleRepo struct {
+	host string
+	// ar is whether this is Artifact Registry, rather than Container Registry.
+	ar      bool
+	project string
+	// location is the Artifact Registry location, e.g. "us-central1", or the
+	// Container Registry multi-region, e.g. "eu", or "" for gcr.io.
+	location string
+	// repo is the Artifact Registry repository.
+	repo string
+}
+
+// parseGoogleRepo returns where repo lives, if it is in Container Registry or
+// Artifact Registry.
+func parseGoogleRepo(repo name.Repository) (googleRepo, bool) {
+	host := repo.RegistryStr()
+	parts := strings.Split(repo.RepositoryStr(), "/")
+	switch {
+	case host == "gcr.io" || strings.HasSuffix(host, ".gcr.io"):
+		g := googleRepo{host: host, project: parts[0], location: strings.TrimSuffix(strings.TrimSuffix(host, "gcr.io"), ".")}
+		// Domain-scoped projects, e.g. gcr.io/example.com/my-project.
+		if strings.Contains(parts[0], ".") && len(parts) > 1 {
+			g.project = parts[0] + ":" + parts[1]
+		}
+		return g, true
+	case strings.HasSuffix(host, "-docker.pkg.dev") && len(parts) > 1:
+		return googleRepo{host: host, ar: true, project: parts[0], location: strings.TrimSuffix(host, "-docker.pkg.dev"), repo: parts[1]}, true
+	}
+	return googleRepo{}, false
+}
+
+// readRole returns the IAM role needed to pull from g.
+func (g googleRepo) readRole() string {
+	if g.ar {
+		return fmt.Sprintf("roles/artifactregistry.reader on the Artifact Registry repository %q in project %q (%s)", g.repo, g.project, g.location)
+	}
+	return fmt.Sprintf("roles/storage.objectViewer on the bucket that stores %s/%s%s (or roles/artifactregistry.reader, if the project's gcr.io repositories are hosted in Artifact Registry)", g.host, g.project, g.bucket())
+}
+
+// writeRole returns the IAM role needed to push to g.
+func (g googleRepo) writeRole() string {
+	if g.ar {
+		return fmt.Sprintf("roles/artifactregistry.writer on the Artifact Registry repository %q in project %q (%s)", g.repo, g.project, g.location)
+	}
+	return fmt.Sprintf("roles/storage.admin on the bucket that stores %s/%s%s (or roles/artifactregistry.writer, if the project's gcr.io repositories are hosted in Artifact Registry)", g.host, g.project, g.bucket())
+}
+
+// bucket describes the Cloud Storage bucket behind a Container Registry
+// host, where we can work it out.
+func (g googleRepo) bucket() string {
+	if strings.Contains(g.project, ":") {
+		return ""
+	}
+	prefix := ""
+	if g.location != "" {
+		prefix = g.location + "."
+	}
+	return fmt.Sprintf(", gs://%sartifacts.%s.appspot.com", prefix, g.project)
+}
+
+// withHint returns err as a *HintError if it is a failure to copy from src to
+// dst that we have advice for, otherwise err.
+func withHint(err error, src, dst name.Repository) error {
+	var herr *HintError
+	var terr *transport.Error
+	if err == nil || errors.As(err, &herr) || !errors.As(err, &terr) {
+		return err
+	}
+	s, sok := parseGoogleRepo(src)
+	d, dok := parseGoogleRepo(dst)
+	if !sok && !dok {
+		return err
+	}
+
+	var hint string
+	switch {
+	case terr.StatusCode == http.StatusUnauthorized || terr.StatusCode == http.StatusForbidden:
+		if failedAt(terr, dst) {
+			if !dok {
+				return err
+			}
+			hint = "the credentials gcrane is using need " + d.writeRole() + " to push there"
+		} else {
+			if !sok {
+				return err
+			}
+			hint = "the credentials gcrane is using need " + s.readRole() + " to pull from there"
+		}
+		if sok && dok && s.project != d.project {
+			hint += fmt.Sprintf(". %s and %s are in different projects (%q and %q): a service account from one project has no access to the other unless it is granted, so check that the account can read from %q as well as write to %q",
+				src, dst, s.project, d.project, s.project, d.project)
+		}
+	case dok && d.ar && failedAt(terr, dst) && hasCode(terr, transport.ManifestBlobUnknownErrorCode, transport.BlobUnknownErrorCode):
+		// Mounts answered with 202 Accepted are copied in the background.
+		hint = fmt.Sprintf("Artifact Registry may still be copying blobs that were mounted into %s from another repository; retry the copy once it has caught up (Go callers can wait for mounts with remote.WithMountWait)", dst)
+	default:
+		return err
+	}
+	return &HintError{Err: err, Hint: hint}
+}
+
+// failedAt reports whether terr is from a request to repo, rather than to
+// the other side of the copy or to storage that it redirected to.
+func failedAt(terr *transport.Error, repo name.Repository) bool {
+	if terr.Request == nil || terr.Request.URL == nil {
+		return false
+	}
+	u := terr.Request.URL
+	if u.Host != repo.RegistryStr() {
+		return false
+	}
+	if strings.HasPrefix(u.Path, "/v2/"+repo.RepositoryStr()+"/") {
+		return true
+	}
+	// Token requests name the repository in their scope.
+	for _, scope := range u.Query()["scope"] {
+		if strings.HasPrefix(scope, "repository:"+repo.RepositoryStr()+":") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCode(terr *transport.Error, codes ...transport.ErrorCode) bool {
+	for _, diag := range terr.Errors {
+		for _, code := range codes {
+			if diag.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcrane
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+func TestWithHint(t *testing.T) {
+	transportError := func(status int, method, u string, codes ...transport.ErrorCode) error {
+		pu, err := url.Parse(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		terr := &transport.Error{StatusCode: status, Request: &http.Request{Method: method, URL: pu}}
+		for _, code := range codes {
+			terr.Errors = append(terr.Errors, transport.Diagnostic{Code: code})
+		}
+		// Copy failures come back wrapped.
+		return fmt.Errorf("copying: %w", terr)
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		src, dst string
+		err      error
+		// want are substrings of the hint, or nil for no hint.
+		want []string
+	}{{
+		desc: "can't pull from gcr.io",
+		src:  "gcr.io/old-project/app",
+		dst:  "us-docker.pkg.dev/old-project/images/app",
+		err:  transportError(http.StatusForbidden, http.MethodGet, "https://storage.googleapis.com/artifacts.old-project.appspot.com/containers/images/sha256:abc"),
+		want: []string{"roles/storage.objectViewer", "gs://artifacts.old-project.appspot.com", "to pull"},
+	}, {
+		desc: "can't pull from eu.gcr.io",
+		src:  "eu.gcr.io/old-project/app",
+		dst:  "europe-docker.pkg.dev/old-project/images/app",
+		err:  transportError(http.StatusUnauthorized, http.MethodGet, "https://eu.gcr.io/v2/old-project/app/manifests/latest"),
+		want: []string{"gs://eu.artifacts.old-project.appspot.com"},
+	}, {
+		desc: "can't push to Artifact Registry",
+		src:  "gcr.io/old-project/app",
+		dst:  "us-docker.pkg.dev/new-project/images/app",
+		err:  transportError(http.StatusForbidden, http.MethodPost, "https://us-docker.pkg.dev/v2/new-project/images/app/blobs/uploads/", transport.DeniedErrorCode),
+		want: []string{`roles/artifactregistry.writer on the Artifact Registry repository "images" in project "new-project" (us)`, "different projects", `"old-project"`},
+	}, {
+		desc: "token for the destination",
+		src:  "us-docker.pkg.dev/p/images/app",
+		dst:  "us-docker.pkg.dev/p/other/app",
+		err:  transportError(http.StatusForbidden, http.MethodGet, "https://us-docker.pkg.dev/v2/token?scope=repository:p/other/app:push,pull&scope=repository:p/images/app:pull"),
+		want: []string{`roles/artifactregistry.writer on the Artifact Registry repository "other"`},
+	}, {
+		desc: "mounted blobs still copying",
+		src:  "us-docker.pkg.dev/p/images/app",
+		dst:  "us-docker.pkg.dev/p/other/app",
+		err:  transportError(http.StatusBadRequest, http.MethodPut, "https://us-docker.pkg.dev/v2/p/other/app/manifests/latest", transport.ManifestBlobUnknownErrorCode),
+		want: []string{"still be copying blobs", "remote.WithMountWait"},
+	}, {
+		desc: "not Google",
+		src:  "example.com/app",
+		dst:  "registry.example.com/app",
+		err:  transportError(http.StatusForbidden, http.MethodGet, "https://example.com/v2/app/manifests/latest"),
+	}, {
+		desc: "not a registry error",
+		src:  "gcr.io/p/app",
+		dst:  "us-docker.pkg.dev/p/images/app",
+		err:  errors.New("oops"),
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			src, err := name.NewRepository(tc.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dst, err := name.NewRepository(tc.dst)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := withHint(tc.err, src, dst)
+			var herr *HintError
+			if !errors.As(got, &herr) {
+				if tc.want != nil {
+					t.Fatalf("withHint() = %v, want a hint", got)
+				}
+				if got != tc.err {
+					t.Errorf("withHint() = %v, want %v", got, tc.err)
+				}
+				return
+			}
+			if tc.want == nil {
+				t.Fatalf("withHint() = %v, want no hint", got)
+			}
+			if !errors.Is(got, tc.err) {
+				t.Errorf("withHint() doesn't wrap %v", tc.err)
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(herr.Hint, want) {
+					t.Errorf("hint %q doesn't contain %q", herr.Hint, want)
+				}
+			}
+			// Hints aren't added twice.
+			if again := withHint(got, src, dst); again != got {
+				t.Errorf("withHint() added a second hint: %v", again)
+			}
+		})
+	}
+}
@@ -29,10 +29,12 @@ import (
 type Option func(*options)
 
 type options struct {
-	jobs   int
-	remote []remote.Option
-	google []google.Option
-	crane  []crane.Option
+	jobs    int
+	remote  []remote.Option
+	google  []google.Option
+	crane   []crane.Option
+	include []string
+	exclude []string
 }
 
 func makeOptions(opts ...Option) *options {
@@ -65,6 +67,30 @@ func WithJobs(jobs int) Option {
 	}
 }
 
+// WithInclude limits CopyRepository to repositories and tags that match at
+// least one of the given patterns.
+//
+// Patterns are globs (see path.Match) evaluated against the fully qualified
+// repository name, e.g. "gcr.io/my-project/team-*". A pattern that matches a
+// repository also matches every repository nested beneath it. A pattern with
+// a tag portion, e.g. "gcr.io/my-project/app:v1.*", only matches those tags;
+// untagged images are skipped for repositories matched only by such patterns.
+func WithInclude(patterns ...string) Option {
+	return func(o *options) {
+		o.include = append(o.include, patterns...)
+	}
+}
+
+// WithExclude prevents CopyRepository from copying repositories and tags that
+// match any of the given patterns. Excludes take precedence over includes.
+//
+// See WithInclude for the pattern syntax.
+func WithExclude(patterns ...string) Option {
+	return func(o *options) {
+		o.exclude = append(o.exclude, patterns...)
+	}
+}
+
 // WithTransport is a functional option for overriding the default transport
 // for remote operations.
 func WithTransport(t http.RoundTripper) Option {
@@ -12,7 +12,8 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package logs exposes the loggers used by this library.
+// Package logs exposes the loggers used by this library. See SetLogger to
+// send them to a structured logging backend, such as log/slog.
 package logs
 
 import (
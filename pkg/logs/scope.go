@@ -0,0 +1,148 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Scope identifies a part of this library whose debug logging can be enabled
+// independently of Debug.
+type Scope string
+
+const (
+	// Transport covers HTTP requests and responses.
+	Transport Scope = "transport"
+
+	// Write covers pushing blobs and manifests.
+	Write Scope = "write"
+
+	// Auth covers resolving credentials.
+	Auth Scope = "auth"
+)
+
+// Level is the verbosity of a Scope.
+type Level int
+
+const (
+	// LevelOff disables a scope's debug logging.
+	LevelOff Level = iota
+
+	// LevelDebug enables a scope's debug logging.
+	LevelDebug
+)
+
+// Format is how structured debug output, such as HTTP dumps, is written.
+type Format int
+
+const (
+	// FormatText writes human-readable dumps.
+	FormatText Format = iota
+
+	// FormatJSON writes one JSON object per line, which is easier to filter
+	// and to attach to bug reports.
+	FormatJSON
+)
+
+const (
+	// DebugEnv is the environment variable read at startup to enable scopes.
+	// It is a comma-separated list of scopes, e.g. "transport,write", or "all".
+	DebugEnv = "GGCR_DEBUG"
+
+	// FormatEnv is the environment variable read at startup to set the
+	// Format. The only recognized value is "json".
+	FormatEnv = "GGCR_DEBUG_FORMAT"
+)
+
+var (
+	scopeMu     sync.RWMutex
+	scopeOutput io.Writer = os.Stderr
+	scoped                = map[Scope]*log.Logger{}
+	format                = FormatText
+
+	discard = log.New(io.Discard, "", 0)
+)
+
+func init() {
+	for _, s := range strings.Split(os.Getenv(DebugEnv), ",") {
+		switch s = strings.TrimSpace(s); s {
+		case "":
+		case "all", "*":
+			for _, s := range []Scope{Transport, Write, Auth} {
+				SetLevel(s, LevelDebug)
+			}
+		default:
+			SetLevel(Scope(s), LevelDebug)
+		}
+	}
+	if strings.EqualFold(os.Getenv(FormatEnv), "json") {
+		SetFormat(FormatJSON)
+	}
+}
+
+// SetFormat sets the format of structured debug output.
+func SetFormat(f Format) {
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+	format = f
+}
+
+// GetFormat returns the format of structured debug output.
+func GetFormat() Format {
+	scopeMu.RLock()
+	defer scopeMu.RUnlock()
+	return format
+}
+
+// SetLevel sets the verbosity of scope. Enabled scopes log to os.Stderr,
+// unless changed with SetScopeOutput.
+func SetLevel(scope Scope, level Level) {
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+	if level == LevelOff {
+		delete(scoped, scope)
+		return
+	}
+	scoped[scope] = log.New(scopeOutput, "["+string(scope)+"] ", log.LstdFlags)
+}
+
+// SetScopeOutput sets where enabled scopes log to.
+func SetScopeOutput(w io.Writer) {
+	scopeMu.Lock()
+	defer scopeMu.Unlock()
+	scopeOutput = w
+	for _, l := range scoped {
+		l.SetOutput(w)
+	}
+}
+
+// DebugFor returns the logger to use for debug logging in scope: Debug, if it
+// is enabled, otherwise a logger that only writes anything if scope has been
+// enabled with SetLevel.
+func DebugFor(scope Scope) *log.Logger {
+	if Enabled(Debug) {
+		return Debug
+	}
+	scopeMu.RLock()
+	defer scopeMu.RUnlock()
+	if l, ok := scoped[scope]; ok {
+		return l
+	}
+	return discard
+}
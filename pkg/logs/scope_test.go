@@ -0,0 +1,51 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestScopes(t *testing.T) {
+	var buf bytes.Buffer
+	SetScopeOutput(&buf)
+	defer SetScopeOutput(os.Stderr)
+
+	DebugFor(Transport).Print("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("disabled scope logged %q", buf.String())
+	}
+
+	SetLevel(Transport, LevelDebug)
+	defer SetLevel(Transport, LevelOff)
+	DebugFor(Transport).Print("shown")
+	DebugFor(Write).Print("hidden")
+	if got := buf.String(); !strings.Contains(got, "[transport] ") || !strings.Contains(got, "shown") || strings.Contains(got, "hidden") {
+		t.Errorf("got %q, want only the transport message", got)
+	}
+
+	// Debug still enables everything.
+	buf.Reset()
+	Debug.SetOutput(&buf)
+	defer Debug.SetOutput(io.Discard)
+	DebugFor(Write).Print("everything")
+	if got := buf.String(); !strings.Contains(got, "everything") {
+		t.Errorf("got %q, want Debug to log every scope", got)
+	}
+}
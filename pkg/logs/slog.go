@@ -0,0 +1,161 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Logger receives structured, levelled log records from this library.
+// *slog.Logger implements it, so any slog.Handler can be plugged in with:
+//
+//	logs.SetLogger(slog.New(handler))
+type Logger interface {
+	// Enabled reports whether records at level are logged.
+	Enabled(ctx context.Context, level slog.Level) bool
+
+	// Log logs msg at level, with args as alternating keys and values or
+	// slog.Attrs, as for slog.Logger.Log.
+	Log(ctx context.Context, level slog.Level, msg string, args ...any)
+}
+
+// legacy is how SetLogger found one of the global loggers, so that it can be
+// put back.
+type legacy struct {
+	l      *log.Logger
+	out    io.Writer
+	flags  int
+	prefix string
+}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger
+	saved    []legacy
+)
+
+// SetLogger sends this library's logs to l. Warn, Progress and Debug keep
+// working: lines printed to them are logged to l at slog.LevelWarn,
+// slog.LevelInfo and slog.LevelDebug, if l has them enabled. Code that knows
+// more about what it is logging, such as the HTTP transports, uses Log to
+// attach fields to its records instead.
+//
+// SetLogger(nil) restores the global loggers to how they were.
+func SetLogger(l Logger) {
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+
+	for _, s := range saved {
+		s.l.SetOutput(s.out)
+		s.l.SetFlags(s.flags)
+		s.l.SetPrefix(s.prefix)
+	}
+	saved = nil
+	logger = l
+	if l == nil {
+		return
+	}
+
+	for _, g := range []struct {
+		l     *log.Logger
+		level slog.Level
+	}{
+		{Warn, slog.LevelWarn},
+		{Progress, slog.LevelInfo},
+		{Debug, slog.LevelDebug},
+	} {
+		saved = append(saved, legacy{l: g.l, out: g.l.Writer(), flags: g.l.Flags(), prefix: g.l.Prefix()})
+		if !l.Enabled(context.Background(), g.level) {
+			g.l.SetOutput(io.Discard)
+			continue
+		}
+		// The backend adds its own time and formatting.
+		g.l.SetFlags(0)
+		g.l.SetPrefix("")
+		g.l.SetOutput(&levelWriter{l: l, level: g.level})
+	}
+}
+
+// GetLogger returns the Logger given to SetLogger, or nil if there isn't one.
+func GetLogger() Logger {
+	loggerMu.RLock()
+	defer loggerMu.RUnlock()
+	return logger
+}
+
+// levelWriter logs each line written to it at level.
+type levelWriter struct {
+	l     Logger
+	level slog.Level
+}
+
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.l.Log(context.Background(), w.level, strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+type fieldsKey struct{}
+
+// WithFields returns a copy of ctx that carries args, as alternating keys and
+// values or slog.Attrs, which Log adds to every record logged with it. It is
+// used to attach per-request fields, such as the method, URL and attempt
+// number of an HTTP request, to everything logged while handling it.
+func WithFields(ctx context.Context, args ...any) context.Context {
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+	return context.WithValue(ctx, fieldsKey{}, append(fields[:len(fields):len(fields)], args...))
+}
+
+// Log logs msg at level, with the fields from ctx (see WithFields) and args.
+// Records go to the Logger given to SetLogger or, without one, are printed
+// as "msg key=value ..." to Warn, Progress or Debug, according to level.
+func Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	fields, _ := ctx.Value(fieldsKey{}).([]any)
+	if l := GetLogger(); l != nil {
+		if l.Enabled(ctx, level) {
+			l.Log(ctx, level, msg, append(fields[:len(fields):len(fields)], args...)...)
+		}
+		return
+	}
+
+	var out *log.Logger
+	switch {
+	case level >= slog.LevelWarn:
+		out = Warn
+	case level >= slog.LevelInfo:
+		out = Progress
+	default:
+		out = Debug
+	}
+	if !Enabled(out) {
+		return
+	}
+	r := slog.NewRecord(time.Time{}, level, msg, 0)
+	r.Add(fields...)
+	r.Add(args...)
+	var b strings.Builder
+	b.WriteString(msg)
+	r.Attrs(func(a slog.Attr) bool {
+		fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+		return true
+	})
+	out.Print(b.String())
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSetLogger(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})))
+	defer SetLogger(nil)
+
+	ctx := WithFields(context.Background(), "method", "GET")
+	Warn.Printf("oh %s", "no")
+	Progress.Print("pushed")
+	Debug.Print("hidden")
+	Log(WithFields(ctx, "attempt", 2), slog.LevelInfo, "request", "status", 200)
+	Log(ctx, slog.LevelDebug, "hidden")
+	if Enabled(Debug) {
+		t.Error("Debug is enabled, but the handler drops debug records")
+	}
+
+	var got []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("Unmarshal(%q): %v", line, err)
+		}
+		delete(rec, "time")
+		got = append(got, rec)
+	}
+	want := []map[string]any{
+		{"level": "WARN", "msg": "oh no"},
+		{"level": "INFO", "msg": "pushed"},
+		{"level": "INFO", "msg": "request", "method": "GET", "attempt": float64(2), "status": float64(200)},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("records (-want +got): %s", d)
+	}
+}
+
+func TestLogWithoutLogger(t *testing.T) {
+	var buf bytes.Buffer
+	Progress.SetOutput(&buf)
+	defer Progress.SetOutput(io.Discard)
+	flags := Progress.Flags()
+	Progress.SetFlags(0)
+	defer Progress.SetFlags(flags)
+
+	// SetLogger(nil) puts the globals back.
+	SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	SetLogger(nil)
+
+	Log(WithFields(context.Background(), "url", "https://example.com"), slog.LevelInfo, "pushed", "attempt", 1)
+	Log(context.Background(), slog.LevelDebug, "hidden")
+	if got, want := buf.String(), "pushed url=https://example.com attempt=1\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -168,6 +168,54 @@ type blobs struct {
 	uploads map[string][]byte
 	lock    sync.Mutex
 	log     *log.Logger
+
+	events func(Event)
+
+	// mount allows blobs to be mounted from other repositories.
+	mount bool
+
+	// noMonolithicPost ignores the digest of POSTs that upload a whole blob,
+	// starting an upload instead.
+	noMonolithicPost bool
+
+	// maxSize, if positive, is the largest blob that can be uploaded.
+	maxSize int64
+
+	// quota limits what each repository can hold, and usage is what they
+	// hold, by repository name.
+	quota     Quota
+	usage     map[string]*usage
+	usageLock sync.Mutex
+
+	// referenced, if set, returns a manifest that refers to the blob h, for
+	// reference counting (see WithBlobReferenceCounting).
+	referenced func(h v1.Hash) (string, bool)
+}
+
+func (b *blobs) emit(e Event) {
+	if b.events != nil {
+		b.events(e)
+	}
+}
+
+// exists reports whether repo has the blob h.
+func (b *blobs) exists(ctx context.Context, repo string, h v1.Hash) (bool, error) {
+	var err error
+	if bsh, ok := b.blobHandler.(BlobStatHandler); ok {
+		_, err = bsh.Stat(ctx, repo, h)
+	} else {
+		var rc io.ReadCloser
+		if rc, err = b.blobHandler.Get(ctx, repo, h); err == nil {
+			rc.Close()
+		}
+	}
+	if errors.Is(err, errNotFound) {
+		return false, nil
+	} else if errors.As(err, &redirectError{}) {
+		// It's elsewhere, but it exists.
+		return true, nil
+	}
+	return err == nil, err
 }
 
 func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
@@ -190,7 +238,8 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 	contentRange := req.Header.Get("Content-Range")
 	rangeHeader := req.Header.Get("Range")
 
-	repo := req.URL.Host + path.Join(elem[1:len(elem)-2]...)
+	name := path.Join(elem[1 : len(elem)-2]...)
+	repo := req.URL.Host + name
 
 	switch req.Method {
 	case http.MethodHead:
@@ -301,6 +350,8 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 			r = &buf
 		}
 
+		b.emit(Event{Type: BlobPull, Repository: name, Digest: h, Size: size})
+
 		if rangeHeader != "" {
 			start, end := int64(0), int64(0)
 			if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end); err != nil {
@@ -362,25 +413,69 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 			}
 		}
 
-		if digest != "" {
+		if mount := req.URL.Query().Get("mount"); mount != "" && b.mount {
+			h, err := v1.NewHash(mount)
+			if err != nil {
+				return regErrDigestInvalid
+			}
+			from := req.URL.Host + req.URL.Query().Get("from")
+			ok, err := b.exists(req.Context(), from, h)
+			if err != nil {
+				return regErrInternal(err)
+			}
+			if ok {
+				size := int64(0)
+				if bsh, ok := b.blobHandler.(BlobStatHandler); ok {
+					size, _ = bsh.Stat(req.Context(), from, h)
+				}
+				lr, rerr := b.admit(name, h, nil)
+				if rerr != nil {
+					return rerr
+				}
+				defer lr.cancel()
+				if rerr := lr.reserve(size); rerr != nil {
+					return rerr
+				}
+				lr.commit(h, size)
+				resp.Header().Set("Location", "/"+path.Join("v2", name, "blobs", h.String()))
+				resp.Header().Set("Docker-Content-Digest", h.String())
+				resp.WriteHeader(http.StatusCreated)
+				return nil
+			}
+			// Otherwise, start an upload, as the spec says.
+		}
+
+		if digest != "" && !b.noMonolithicPost {
 			h, err := v1.NewHash(digest)
 			if err != nil {
 				return regErrDigestInvalid
 			}
 
-			vrc, err := verify.ReadCloser(req.Body, req.ContentLength, h)
+			lr, rerr := b.admit(name, h, req.Body)
+			if rerr != nil {
+				return rerr
+			}
+			defer lr.cancel()
+			vrc, err := verify.ReadCloser(io.NopCloser(lr), req.ContentLength, h)
 			if err != nil {
 				return regErrInternal(err)
 			}
 			defer vrc.Close()
+			defer req.Body.Close()
 
 			if err = bph.Put(req.Context(), repo, h, vrc); err != nil {
 				if errors.As(err, &verify.Error{}) {
 					log.Printf("Digest mismatch: %v", err)
 					return regErrDigestMismatch
 				}
+				var lerr *limitError
+				if errors.As(err, &lerr) {
+					return lerr.rerr
+				}
 				return regErrInternal(err)
 			}
+			lr.commit(h, lr.n)
+			b.emit(Event{Type: BlobPush, Repository: name, Digest: h, Size: req.ContentLength})
 			resp.Header().Set("Docker-Content-Digest", h.String())
 			resp.WriteHeader(http.StatusCreated)
 			return nil
@@ -421,6 +516,10 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 			}
 			l := bytes.NewBuffer(b.uploads[target])
 			io.Copy(l, req.Body)
+			if b.maxSize > 0 && int64(l.Len()) > b.maxSize {
+				delete(b.uploads, target)
+				return regErrSizeInvalid("blob", b.maxSize)
+			}
 			b.uploads[target] = l.Bytes()
 			resp.Header().Set("Location", "/"+path.Join("v2", path.Join(elem[1:len(elem)-3]...), "blobs/uploads", target))
 			resp.Header().Set("Range", fmt.Sprintf("0-%d", len(l.Bytes())-1))
@@ -440,6 +539,9 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 
 		l := &bytes.Buffer{}
 		io.Copy(l, req.Body)
+		if b.maxSize > 0 && int64(l.Len()) > b.maxSize {
+			return regErrSizeInvalid("blob", b.maxSize)
+		}
 
 		b.uploads[target] = l.Bytes()
 		resp.Header().Set("Location", "/"+path.Join("v2", path.Join(elem[1:len(elem)-3]...), "blobs/uploads", target))
@@ -482,7 +584,14 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 		}
 
 		defer req.Body.Close()
-		in := io.NopCloser(io.MultiReader(bytes.NewBuffer(b.uploads[target]), req.Body))
+		repoName := path.Join(elem[1 : len(elem)-3]...)
+		lr, rerr := b.admit(repoName, h, io.MultiReader(bytes.NewBuffer(b.uploads[target]), req.Body))
+		if rerr != nil {
+			delete(b.uploads, target)
+			return rerr
+		}
+		defer lr.cancel()
+		in := io.NopCloser(lr)
 
 		size := int64(verify.SizeUnknown)
 		if req.ContentLength > 0 {
@@ -500,9 +609,16 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 				log.Printf("Digest mismatch: %v", err)
 				return regErrDigestMismatch
 			}
+			var lerr *limitError
+			if errors.As(err, &lerr) {
+				delete(b.uploads, target)
+				return lerr.rerr
+			}
 			return regErrInternal(err)
 		}
 
+		lr.commit(h, lr.n)
+		b.emit(Event{Type: BlobPush, Repository: repoName, Digest: h, Size: size})
 		delete(b.uploads, target)
 		resp.Header().Set("Docker-Content-Digest", h.String())
 		resp.WriteHeader(http.StatusCreated)
@@ -522,9 +638,20 @@ func (b *blobs) handle(resp http.ResponseWriter, req *http.Request) *regError {
 				Message: "invalid digest",
 			}
 		}
+		if b.referenced != nil {
+			if m, ok := b.referenced(h); ok {
+				return &regError{
+					Status:  http.StatusConflict,
+					Code:    "DENIED",
+					Message: fmt.Sprintf("blob %s is referenced by manifest %s", h, m),
+				}
+			}
+		}
 		if err := bdh.Delete(req.Context(), repo, h); err != nil {
 			return regErrInternal(err)
 		}
+		b.release(name, h)
+		b.emit(Event{Type: BlobDelete, Repository: name, Digest: h, Size: -1})
 		resp.WriteHeader(http.StatusAccepted)
 		return nil
 
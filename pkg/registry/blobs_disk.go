@@ -20,52 +20,204 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 
+	"github.com/google/go-containerregistry/internal/verify"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
+// Durability is how hard the disk blob handler works to make sure that a
+// blob it has accepted survives a crash.
+type Durability int
+
+const (
+	// DurabilityNone leaves flushing blobs to the operating system. A crash
+	// can lose recently written blobs, or leave them truncated.
+	DurabilityNone Durability = iota
+
+	// DurabilityFile syncs each blob's contents before moving it into place,
+	// so a blob is never visible with partial contents. A crash can still
+	// lose recently written blobs.
+	DurabilityFile
+
+	// DurabilityFull also syncs the blob's directory after moving it into
+	// place, and the parents of any directories created for it, so a blob
+	// that has been accepted is never lost.
+	DurabilityFull
+)
+
+// DiskOption is a functional option for NewDiskBlobHandler.
+type DiskOption func(*diskHandler)
+
+// WithSharding stores blobs in directories named after the first two pairs
+// of hex digits of their digests (e.g. sha256/ab/cd/abcd...), rather than all
+// in one directory, which keeps directories small enough for filesystems to
+// handle millions of blobs.
+//
+// Blobs stored without sharding can still be read, so an existing directory
+// can be switched to sharding, but they aren't moved.
+func WithSharding() DiskOption {
+	return func(m *diskHandler) {
+		m.sharded = true
+	}
+}
+
+// WithDurability sets how the disk blob handler syncs blobs as they are
+// written. The default is DurabilityNone.
+func WithDurability(d Durability) DiskOption {
+	return func(m *diskHandler) {
+		m.durability = d
+	}
+}
+
 type diskHandler struct {
-	dir string
+	dir        string
+	sharded    bool
+	durability Durability
 }
 
-func NewDiskBlobHandler(dir string) BlobHandler { return &diskHandler{dir: dir} }
+// NewDiskBlobHandler returns a BlobHandler that stores blobs in dir.
+//
+// Blobs are written to temporary files and renamed into place once their
+// contents have been verified, so concurrent uploads of the same blob are
+// safe, and readers never see a partially written blob.
+func NewDiskBlobHandler(dir string, opts ...DiskOption) BlobHandler {
+	m := &diskHandler{dir: dir}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
 
+// blobHashPath returns where h is written.
 func (m *diskHandler) blobHashPath(h v1.Hash) string {
+	if m.sharded && len(h.Hex) > 4 {
+		return filepath.Join(m.dir, h.Algorithm, h.Hex[:2], h.Hex[2:4], h.Hex)
+	}
 	return filepath.Join(m.dir, h.Algorithm, h.Hex)
 }
 
-func (m *diskHandler) Stat(_ context.Context, _ string, h v1.Hash) (int64, error) {
-	fi, err := os.Stat(m.blobHashPath(h))
+// open opens h, wherever it is.
+func (m *diskHandler) open(h v1.Hash) (*os.File, error) {
+	f, err := os.Open(m.blobHashPath(h))
+	if errors.Is(err, os.ErrNotExist) && m.sharded {
+		// It may have been written before sharding was enabled.
+		f, err = os.Open(filepath.Join(m.dir, h.Algorithm, h.Hex))
+	}
 	if errors.Is(err, os.ErrNotExist) {
-		return 0, errNotFound
-	} else if err != nil {
+		return nil, errNotFound
+	}
+	return f, err
+}
+
+func (m *diskHandler) Stat(_ context.Context, _ string, h v1.Hash) (int64, error) {
+	f, err := m.open(h)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
 		return 0, err
 	}
 	return fi.Size(), nil
 }
 func (m *diskHandler) Get(_ context.Context, _ string, h v1.Hash) (io.ReadCloser, error) {
-	return os.Open(m.blobHashPath(h))
+	return m.open(h)
 }
-func (m *diskHandler) Put(_ context.Context, _ string, h v1.Hash, rc io.ReadCloser) error {
+func (m *diskHandler) Put(_ context.Context, _ string, h v1.Hash, rc io.ReadCloser) (err error) {
 	// Put the temp file in the same directory to avoid cross-device problems
 	// during the os.Rename.  The filenames cannot conflict.
 	f, err := os.CreateTemp(m.dir, "upload-*")
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if err != nil {
+			os.Remove(f.Name())
+		}
+	}()
 
 	if err := func() error {
 		defer f.Close()
-		_, err := io.Copy(f, rc)
-		return err
+		vrc, err := verify.ReadCloser(rc, verify.SizeUnknown, h)
+		if err != nil {
+			return err
+		}
+		defer vrc.Close()
+		if _, err := io.Copy(f, vrc); err != nil {
+			return err
+		}
+		if m.durability >= DurabilityFile {
+			return f.Sync()
+		}
+		return nil
 	}(); err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Join(m.dir, h.Algorithm), os.ModePerm); err != nil {
+
+	p := m.blobHashPath(h)
+	created, err := mkdirAll(filepath.Dir(p))
+	if err != nil {
 		return err
 	}
-	return os.Rename(f.Name(), m.blobHashPath(h))
+	// If another upload of the same blob wins the race, this replaces its
+	// file with one with identical contents.
+	if err := os.Rename(f.Name(), p); err != nil {
+		return err
+	}
+	if m.durability >= DurabilityFull {
+		if err := syncDir(filepath.Dir(p)); err != nil {
+			return err
+		}
+		// Newly created directories are only durable once their parents'
+		// entries are.
+		for _, dir := range created {
+			if err := syncDir(filepath.Dir(dir)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 func (m *diskHandler) Delete(_ context.Context, _ string, h v1.Hash) error {
-	return os.Remove(m.blobHashPath(h))
+	err := os.Remove(m.blobHashPath(h))
+	if errors.Is(err, os.ErrNotExist) && m.sharded {
+		err = os.Remove(filepath.Join(m.dir, h.Algorithm, h.Hex))
+	}
+	return err
+}
+
+// mkdirAll is os.MkdirAll, but returns the directories that it created,
+// deepest first.
+func mkdirAll(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// syncDir flushes the entries of dir, so that files renamed into it persist.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		// Directories can't be synced on Windows, where renames are durable
+		// once they return.
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
 }
@@ -15,6 +15,7 @@
 package registry_test
 
 import (
+	"context"
 	"fmt"
 	"net/http/httptest"
 	"os"
@@ -26,7 +27,9 @@ import (
 	"github.com/google/go-containerregistry/pkg/registry"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/google/go-containerregistry/pkg/v1/validate"
+	"golang.org/x/sync/errgroup"
 )
 
 func TestDiskPush(t *testing.T) {
@@ -82,3 +85,126 @@ func TestDiskPush(t *testing.T) {
 		t.Logf("Found %s", dig)
 	}
 }
+
+func TestDiskSharded(t *testing.T) {
+	dir := t.TempDir()
+
+	// A blob written before sharding was enabled.
+	old, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHash, err := old.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := old.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.NewDiskBlobHandler(dir).(registry.BlobPutHandler).Put(context.Background(), "foo/bar", oldHash, rc); err != nil {
+		t.Fatal(err)
+	}
+
+	bh := registry.NewDiskBlobHandler(dir, registry.WithSharding(), registry.WithDurability(registry.DurabilityFull))
+	srv := httptest.NewServer(registry.New(registry.WithBlobHandler(bh)))
+	defer srv.Close()
+
+	ref, err := name.ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Push the same image concurrently, to race uploads of the same blobs.
+	var g errgroup.Group
+	for i := 0; i < 4; i++ {
+		g.Go(func() error {
+			return remote.Write(ref, img)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("remote.Write: %v", err)
+	}
+	got, err := remote.Image(ref)
+	if err != nil {
+		t.Fatalf("remote.Image: %v", err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Fatalf("validate.Image: %v", err)
+	}
+
+	ls, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range ls {
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(dir, h.Algorithm, h.Hex[:2], h.Hex[2:4], h.Hex)
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("os.Stat(%s): %v", p, err)
+		}
+	}
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "sha256" {
+			t.Errorf("unexpected file %s", e.Name())
+		}
+	}
+
+	// The unsharded blob is still readable.
+	l, err := remote.Layer(ref.Context().Digest(oldHash.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Layer(l); err != nil {
+		t.Errorf("validate.Layer(unsharded): %v", err)
+	}
+}
+
+func TestDiskPutVerifies(t *testing.T) {
+	dir := t.TempDir()
+	bh := registry.NewDiskBlobHandler(dir, registry.WithSharding()).(registry.BlobPutHandler)
+
+	l, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := other.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bh.Put(context.Background(), "foo/bar", h, rc); err == nil {
+		t.Fatal("Put with the wrong digest: got nil, want an error")
+	}
+	if _, err := bh.(registry.BlobStatHandler).Stat(context.Background(), "foo/bar", h); err == nil {
+		t.Error("Stat after a failed Put: got nil, want an error")
+	}
+
+	// Nothing, not even the temporary file, is left behind.
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		t.Errorf("unexpected file %s", e.Name())
+	}
+}
@@ -31,6 +31,32 @@ func TestDeps(t *testing.T) {
 			"github.com/google/go-containerregistry/pkg/v1",
 			"github.com/google/go-containerregistry/pkg/v1/types",
 
+			// pkg/v1 uses the OCI image spec's annotation keys.
+			"github.com/opencontainers/image-spec/specs-go",
+			"github.com/opencontainers/image-spec/specs-go/v1",
+			"github.com/opencontainers/go-digest",
+
+			// Upstream authenticates with the upstream registry like remote does.
+			"github.com/google/go-containerregistry/pkg/authn",
+			"github.com/google/go-containerregistry/pkg/name",
+			"github.com/google/go-containerregistry/pkg/logs",
+			"github.com/google/go-containerregistry/pkg/v1/remote/transport",
+			"github.com/google/go-containerregistry/internal/redact",
+			"github.com/google/go-containerregistry/internal/retry",
+			"github.com/google/go-containerregistry/internal/retry/wait",
+			"github.com/docker/cli/cli/config",
+			"github.com/docker/cli/cli/config/configfile",
+			"github.com/docker/cli/cli/config/credentials",
+			"github.com/docker/cli/cli/config/types",
+			"github.com/docker/distribution/registry/client/auth/challenge",
+			"github.com/docker/docker-credential-helpers/client",
+			"github.com/docker/docker-credential-helpers/credentials",
+			"github.com/mitchellh/go-homedir",
+			"github.com/pkg/errors",
+			"github.com/sirupsen/logrus",
+			"golang.org/x/sys/execabs",
+			"golang.org/x/sys/unix",
+
 			"github.com/google/go-containerregistry/internal/verify",
 			"github.com/google/go-containerregistry/internal/and",
 		),
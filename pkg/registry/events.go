@@ -0,0 +1,78 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// EventType describes what happened in an Event.
+type EventType string
+
+const (
+	// BlobPush is sent when a blob upload completes.
+	BlobPush EventType = "blob.push"
+	// BlobPull is sent when a blob is served by a GET request.
+	BlobPull EventType = "blob.pull"
+	// BlobDelete is sent when a blob is deleted.
+	BlobDelete EventType = "blob.delete"
+	// ManifestPush is sent when a manifest is uploaded.
+	ManifestPush EventType = "manifest.push"
+	// ManifestPull is sent when a manifest is served by a GET request.
+	ManifestPull EventType = "manifest.pull"
+	// ManifestDelete is sent when a manifest or tag is deleted.
+	ManifestDelete EventType = "manifest.delete"
+	// TagUpdate is sent when a tag is pointed at a manifest.
+	TagUpdate EventType = "tag.update"
+)
+
+// Event describes a successful operation against the registry.
+type Event struct {
+	Type EventType
+
+	// Repository is the repository the operation targeted, e.g. "foo/bar".
+	Repository string
+
+	// Digest is the digest of the blob or manifest.
+	Digest v1.Hash
+
+	// Tag is set for TagUpdate events, and for pushes, pulls and deletes
+	// of manifests that were addressed by tag.
+	Tag string
+
+	// MediaType is the media type of a manifest. Blobs have no media type.
+	MediaType types.MediaType
+
+	// Size is the size in bytes of the blob or manifest, or -1 if it is not
+	// known (e.g. for blob deletes, or uploads without a Content-Length).
+	Size int64
+}
+
+// WithEventHandler registers a function to be called for every push, pull,
+// tag update and delete the registry handles.
+//
+// The handler is called synchronously before the response is written, so
+// by the time a client sees a request succeed its events have been
+// delivered. It may be called concurrently from multiple requests, and may
+// call back into the registry to read what an event refers to, e.g. with a
+// GET of the manifest that was pushed. It must not push to the registry,
+// since blob pushes are reported while the upload is still locked.
+func WithEventHandler(f func(Event)) Option {
+	return func(r *registry) {
+		r.blobs.events = f
+		r.manifests.events = f
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+type eventRecorder struct {
+	sync.Mutex
+	events []registry.Event
+}
+
+func (r *eventRecorder) record(e registry.Event) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, e)
+}
+
+// of returns the recorded events of the given types.
+func (r *eventRecorder) of(types ...registry.EventType) []registry.Event {
+	r.Lock()
+	defer r.Unlock()
+	got := []registry.Event{}
+	for _, e := range r.events {
+		for _, t := range types {
+			if e.Type == t {
+				got = append(got, e)
+			}
+		}
+	}
+	return got
+}
+
+// digests returns the sorted digests of the recorded events of type t.
+func (r *eventRecorder) digests(t registry.EventType) []string {
+	got := []string{}
+	for _, e := range r.of(t) {
+		got = append(got, e.Digest.String())
+	}
+	sort.Strings(got)
+	return got
+}
+
+func (r *eventRecorder) reset() {
+	r.Lock()
+	defer r.Unlock()
+	r.events = nil
+}
+
+func TestEventHandler(t *testing.T) {
+	rec := &eventRecorder{}
+	s := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithEventHandler(rec.record),
+	))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(fmt.Sprintf("%s/foo/bar:latest", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := img.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dig, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, err := img.MediaType()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blobs := []string{cfg.String()}
+	for _, l := range layers {
+		d, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		blobs = append(blobs, d.String())
+	}
+	sort.Strings(blobs)
+
+	// Push: every blob is uploaded exactly once, then the manifest is tagged.
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	size, err := img.Size()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []registry.Event{{
+		Type:       registry.ManifestPush,
+		Repository: "foo/bar",
+		Digest:     dig,
+		Tag:        "latest",
+		MediaType:  mt,
+		Size:       size,
+	}, {
+		Type:       registry.TagUpdate,
+		Repository: "foo/bar",
+		Digest:     dig,
+		Tag:        "latest",
+		MediaType:  mt,
+		Size:       size,
+	}}
+	if d := cmp.Diff(want, rec.of(registry.ManifestPush, registry.TagUpdate)); d != "" {
+		t.Errorf("manifest events (-want +got): %s", d)
+	}
+	if d := cmp.Diff(blobs, rec.digests(registry.BlobPush)); d != "" {
+		t.Errorf("blob pushes (-want +got): %s", d)
+	}
+	rec.reset()
+
+	// Pull: the manifest and every blob are fetched.
+	rimg, err := remote.Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rlayers, err := rimg.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rimg.RawConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range rlayers {
+		rc, err := l.Compressed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			t.Fatal(err)
+		}
+		rc.Close()
+	}
+	if d := cmp.Diff([]string{dig.String()}, rec.digests(registry.ManifestPull)); d != "" {
+		t.Errorf("manifest pulls (-want +got): %s", d)
+	}
+	if d := cmp.Diff(blobs, rec.digests(registry.BlobPull)); d != "" {
+		t.Errorf("blob pulls (-want +got): %s", d)
+	}
+	rec.reset()
+
+	// Delete by digest.
+	if err := remote.Delete(ref.Context().Digest(dig.String())); err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff([]string{dig.String()}, rec.digests(registry.ManifestDelete)); d != "" {
+		t.Errorf("manifest deletes (-want +got): %s", d)
+	}
+}
+
+func TestEventHandlerReadsBack(t *testing.T) {
+	var (
+		ref  name.Reference
+		errs = make(chan error, 10)
+	)
+	s := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithEventHandler(func(e registry.Event) {
+			if e.Type != registry.ManifestPush && e.Type != registry.ManifestDelete {
+				return
+			}
+			// This would deadlock if events were sent with the manifests locked.
+			_, err := remote.Head(ref.Context().Digest(e.Digest.String()))
+			errs <- err
+		}),
+	))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err = name.ParseReference(fmt.Sprintf("%s/foo/bar:latest", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("HEAD after push: %v", err)
+	}
+	dig, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Delete(ref.Context().Digest(dig.String())); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err == nil {
+		t.Error("HEAD after delete: got nil, want an error")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// WithLatency delays every response by d, to simulate a distant registry.
+func WithLatency(d time.Duration) Option {
+	return func(r *registry) {
+		r.faults.latency = d
+	}
+}
+
+// WithBandwidthLimit limits request and response bodies to bytesPerSec
+// bytes per second each, to simulate a slow connection. The limit applies to
+// each request separately.
+func WithBandwidthLimit(bytesPerSec int64) Option {
+	return func(r *registry) {
+		r.faults.bytesPerSec = bytesPerSec
+	}
+}
+
+// WithErrorRate makes the given fraction of requests whose URL paths match
+// the regular expression path (e.g. "/blobs/") fail with status, to exercise
+// clients' retries. Failures are spread evenly rather than chosen at random,
+// so tests are deterministic: with a rate of 0.25, every fourth matching
+// request fails, starting with the fourth.
+//
+// It panics if path isn't a valid regular expression. Requests that match
+// more than one WithErrorRate are counted by the first.
+func WithErrorRate(path string, rate float64, status int) Option {
+	re := regexp.MustCompile(path)
+	return func(r *registry) {
+		r.faults.errors = append(r.faults.errors, &errorRate{path: re, rate: rate, status: status})
+	}
+}
+
+// faults are the ways the registry has been asked to misbehave.
+type faults struct {
+	latency     time.Duration
+	bytesPerSec int64
+	errors      []*errorRate
+}
+
+type errorRate struct {
+	path   *regexp.Regexp
+	rate   float64
+	status int
+
+	mu sync.Mutex
+	n  int
+}
+
+// fail reports whether this matching request should fail.
+func (e *errorRate) fail() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.n++
+	return int(float64(e.n)*e.rate) > int(float64(e.n-1)*e.rate)
+}
+
+// apply delays req, throttles it and its response, or fails it, as f says.
+// It returns the writer and request to handle req with, or an error to fail
+// it with.
+func (f *faults) apply(resp http.ResponseWriter, req *http.Request) (http.ResponseWriter, *http.Request, *regError) {
+	if f.latency > 0 {
+		if err := sleep(req.Context(), f.latency); err != nil {
+			return resp, req, regErrInternal(err)
+		}
+	}
+	for _, e := range f.errors {
+		if !e.path.MatchString(req.URL.Path) {
+			continue
+		}
+		if e.fail() {
+			return resp, req, &regError{
+				Status:  e.status,
+				Code:    "UNKNOWN",
+				Message: fmt.Sprintf("injected error (%s)", http.StatusText(e.status)),
+			}
+		}
+		break
+	}
+	if f.bytesPerSec > 0 {
+		ctx := req.Context()
+		if req.Body != nil {
+			req.Body = &throttledReader{rc: req.Body, ctx: ctx, bytesPerSec: f.bytesPerSec}
+		}
+		resp = &throttledWriter{ResponseWriter: resp, ctx: ctx, bytesPerSec: f.bytesPerSec}
+	}
+	return resp, req, nil
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// chunk is how many bytes can be transferred in a tenth of a second at
+// bytesPerSec, so that throttled transfers are smooth.
+func chunk(bytesPerSec int64) int {
+	return int(max(bytesPerSec/10, 1))
+}
+
+// throttle waits for n bytes' worth of time at bytesPerSec.
+func throttle(ctx context.Context, n int, bytesPerSec int64) error {
+	return sleep(ctx, time.Duration(n)*time.Second/time.Duration(bytesPerSec))
+}
+
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	bytesPerSec int64
+}
+
+func (w *throttledWriter) Write(b []byte) (int, error) {
+	written := 0
+	for len(b) > 0 {
+		n := min(len(b), chunk(w.bytesPerSec))
+		if err := throttle(w.ctx, n, w.bytesPerSec); err != nil {
+			return written, err
+		}
+		n, err := w.ResponseWriter.Write(b[:n])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+type throttledReader struct {
+	rc          io.ReadCloser
+	ctx         context.Context
+	bytesPerSec int64
+}
+
+func (r *throttledReader) Read(b []byte) (int, error) {
+	if len(b) > chunk(r.bytesPerSec) {
+		b = b[:chunk(r.bytesPerSec)]
+	}
+	n, err := r.rc.Read(b)
+	if n > 0 {
+		if err := throttle(r.ctx, n, r.bytesPerSec); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+func (r *throttledReader) Close() error {
+	return r.rc.Close()
+}
@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+func TestLatency(t *testing.T) {
+	s := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithLatency(100*time.Millisecond),
+	))
+	defer s.Close()
+
+	start := time.Now()
+	resp, err := http.Get(s.URL + "/v2/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("GET /v2/ took %s, want at least 100ms", elapsed)
+	}
+}
+
+func TestBandwidthLimit(t *testing.T) {
+	s := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithBandwidthLimit(10000),
+	))
+	defer s.Close()
+
+	blob := bytes.Repeat([]byte("a"), 2000)
+	h, _, err := v1.SHA256(bytes.NewReader(blob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := s.URL + "/v2/foo/blobs/uploads/?digest=" + h.String()
+
+	// 2000 bytes at 10000 bytes per second take 200ms each way.
+	start := time.Now()
+	resp, err := http.Post(u, "application/octet-stream", bytes.NewReader(blob))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("POST = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("upload took %s, want at least 150ms", elapsed)
+	}
+
+	start = time.Now()
+	resp, err = http.Get(s.URL + "/v2/foo/blobs/" + h.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, blob) {
+		t.Errorf("GET returned %d bytes, want the blob's %d", len(got), len(blob))
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("download took %s, want at least 150ms", elapsed)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	s := httptest.NewServer(registry.New(
+		registry.Logger(log.New(io.Discard, "", 0)),
+		registry.WithErrorRate("/blobs/", 0.5, http.StatusServiceUnavailable),
+	))
+	defer s.Close()
+
+	get := func(path string) int {
+		t.Helper()
+		resp, err := http.Get(s.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	blob := "/v2/foo/blobs/sha256:" + strings.Repeat("0", 64)
+	var got []int
+	for i := 0; i < 4; i++ {
+		got = append(got, get(blob))
+		// Requests that don't match are unaffected, and don't count.
+		if code := get("/v2/"); code != http.StatusOK {
+			t.Errorf("GET /v2/ = %d, want %d", code, http.StatusOK)
+		}
+	}
+	want := []int{http.StatusNotFound, http.StatusServiceUnavailable, http.StatusNotFound, http.StatusServiceUnavailable}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("GET %s #%d = %d, want %d", blob, i+1, got[i], want[i])
+		}
+	}
+}
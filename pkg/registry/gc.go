@@ -0,0 +1,168 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// WithBlobReferenceCounting makes the registry count the references that
+// manifests hold to blobs, as real registries do:
+//   - deleting a blob that a manifest in any repository refers to (as its
+//     config or a layer) fails with 409 DENIED, rather than leaving the
+//     manifest dangling;
+//   - POST /v2/_gc deletes the blobs that no manifest refers to, responding
+//     with the digests it deleted as {"deleted": [...]}.
+//
+// Only blobs uploaded or mounted since the registry started are collected.
+func WithBlobReferenceCounting(enabled bool) Option {
+	return func(r *registry) {
+		r.refCounting = enabled
+	}
+}
+
+func isGC(req *http.Request) bool {
+	return strings.TrimSuffix(req.URL.Path, "/") == "/v2/_gc"
+}
+
+// blobRefs are the blobs a manifest refers to.
+type blobRefs struct {
+	Config v1.Descriptor   `json:"config"`
+	Layers []v1.Descriptor `json:"layers"`
+}
+
+// referenced returns a manifest, as repo@digest, that refers to the blob h,
+// if there is one.
+func (m *manifests) referenced(h v1.Hash) (string, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	for repo, c := range m.manifests {
+		for target, mf := range c {
+			if !strings.HasPrefix(target, "sha256:") {
+				// Tags refer to manifests that are also stored by digest.
+				continue
+			}
+			var refs blobRefs
+			if err := json.Unmarshal(mf.blob, &refs); err != nil {
+				continue
+			}
+			if refs.Config.Digest == h {
+				return repo + "@" + target, true
+			}
+			for _, l := range refs.Layers {
+				if l.Digest == h {
+					return repo + "@" + target, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// references returns the blobs that the manifests refer to.
+func (m *manifests) references() map[v1.Hash]bool {
+	refs := map[v1.Hash]bool{}
+	for _, c := range m.manifests {
+		for _, mf := range c {
+			var br blobRefs
+			if err := json.Unmarshal(mf.blob, &br); err != nil {
+				continue
+			}
+			refs[br.Config.Digest] = true
+			for _, l := range br.Layers {
+				refs[l.Digest] = true
+			}
+		}
+	}
+	return refs
+}
+
+// handleGC deletes the blobs that no manifest refers to.
+func (r *registry) handleGC(resp http.ResponseWriter, req *http.Request) *regError {
+	if req.Method != http.MethodPost {
+		return &regError{
+			Status:  http.StatusBadRequest,
+			Code:    "METHOD_UNKNOWN",
+			Message: "We don't understand your method + url",
+		}
+	}
+	bdh, ok := r.blobs.blobHandler.(BlobDeleteHandler)
+	if !ok {
+		return regErrUnsupported
+	}
+
+	events, rerr := func() ([]Event, *regError) {
+		// Hold the manifests still, so none start referring to what's deleted.
+		r.manifests.lock.RLock()
+		defer r.manifests.lock.RUnlock()
+		refs := r.manifests.references()
+
+		r.blobs.usageLock.Lock()
+		unreferenced := map[string][]v1.Hash{}
+		for repo, u := range r.blobs.usage {
+			for h := range u.blobs {
+				if !refs[h] {
+					unreferenced[repo] = append(unreferenced[repo], h)
+				}
+			}
+		}
+		r.blobs.usageLock.Unlock()
+
+		var events []Event
+		for repo, hs := range unreferenced {
+			for _, h := range hs {
+				if err := bdh.Delete(req.Context(), req.URL.Host+repo, h); err != nil && !errors.Is(err, errNotFound) {
+					return events, regErrInternal(err)
+				}
+				r.blobs.release(repo, h)
+				events = append(events, Event{Type: BlobDelete, Repository: repo, Digest: h, Size: -1})
+			}
+		}
+		return events, nil
+	}()
+	// Report what was deleted, even if not everything could be, once the
+	// manifests are unlocked so that the event handler can read them.
+	deleted := map[v1.Hash]bool{}
+	for _, e := range events {
+		r.blobs.emit(e)
+		deleted[e.Digest] = true
+	}
+	if rerr != nil {
+		return rerr
+	}
+
+	out := struct {
+		Deleted []string `json:"deleted"`
+	}{Deleted: []string{}}
+	for h := range deleted {
+		out.Deleted = append(out.Deleted, h.String())
+	}
+	sort.Strings(out.Deleted)
+	msg, _ := json.Marshal(out)
+	resp.Header().Set("Content-Length", fmt.Sprint(len(msg)))
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	io.Copy(resp, bytes.NewReader(msg))
+	return nil
+}
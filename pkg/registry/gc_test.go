@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestBlobReferenceCounting(t *testing.T) {
+	repo := limitsServer(t, registry.WithBlobReferenceCounting(true))
+	base := "http://" + repo.RegistryStr()
+	do := func(method, path string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, base+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+	gc := func() []string {
+		t.Helper()
+		resp := do(http.MethodPost, "/v2/_gc")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("POST /v2/_gc = %d", resp.StatusCode)
+		}
+		var out struct {
+			Deleted []string `json:"deleted"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatal(err)
+		}
+		return out.Deleted
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(repo.Tag("latest"), img); err != nil {
+		t.Fatal(err)
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer := m.Layers[0].Digest.String()
+	stray := randomLayer(t, 100)
+	if err := remote.WriteLayer(repo, stray); err != nil {
+		t.Fatal(err)
+	}
+	strayDigest, err := stray.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The image's layer can't be deleted while the image refers to it.
+	if resp := do(http.MethodDelete, "/v2/test/blobs/"+layer); resp.StatusCode != http.StatusConflict {
+		t.Errorf("DELETE of a referenced blob = %d, want %d", resp.StatusCode, http.StatusConflict)
+	}
+
+	// Only the stray layer is garbage.
+	if diff := cmp.Diff([]string{strayDigest.String()}, gc()); diff != "" {
+		t.Errorf("GC (-want +got): %s", diff)
+	}
+	if resp := do(http.MethodHead, "/v2/test/blobs/"+layer); resp.StatusCode != http.StatusOK {
+		t.Errorf("HEAD of the image's layer after GC = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	// Once the image is gone, so are its blobs.
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Delete(repo.Tag("latest")); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Delete(repo.Digest(digest.String())); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{layer, m.Config.Digest.String()}
+	if want[0] > want[1] {
+		want[0], want[1] = want[1], want[0]
+	}
+	if diff := cmp.Diff(want, gc()); diff != "" {
+		t.Errorf("GC after deleting the image (-want +got): %s", diff)
+	}
+	if resp := do(http.MethodHead, "/v2/test/blobs/"+layer); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("HEAD of the image's layer after GC = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
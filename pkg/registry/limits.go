@@ -0,0 +1,237 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// WithMaxManifestSize rejects manifests larger than n bytes with 413
+// SIZE_INVALID.
+func WithMaxManifestSize(n int64) Option {
+	return func(r *registry) {
+		r.manifests.maxSize = n
+	}
+}
+
+// WithMaxBlobSize rejects blobs larger than n bytes with 413 SIZE_INVALID.
+func WithMaxBlobSize(n int64) Option {
+	return func(r *registry) {
+		r.blobs.maxSize = n
+	}
+}
+
+// Quota limits the blobs that each repository can hold.
+type Quota struct {
+	// Blobs is the most blobs a repository can hold, or 0 for no limit.
+	// Uploading more fails with 429 TOOMANYREQUESTS.
+	Blobs int
+
+	// Bytes is the most bytes of blobs a repository can hold, or 0 for no
+	// limit. Uploading more fails with 403 DENIED.
+	Bytes int64
+}
+
+// WithRepositoryQuota limits the blobs that each repository can hold to q.
+// Blobs count against the quota of every repository they are uploaded or
+// mounted to, until they are deleted from it; manifests don't count.
+func WithRepositoryQuota(q Quota) Option {
+	return func(r *registry) {
+		r.blobs.quota = q
+	}
+}
+
+// regErrSizeInvalid is the error for content larger than the registry's
+// limit of max bytes.
+func regErrSizeInvalid(what string, max int64) *regError {
+	return &regError{
+		Status:  http.StatusRequestEntityTooLarge,
+		Code:    "SIZE_INVALID",
+		Message: fmt.Sprintf("%s exceeds the maximum size of %d bytes", what, max),
+	}
+}
+
+// usage is what a repository holds, for its quota.
+type usage struct {
+	blobs map[v1.Hash]int64
+	// bytes includes what the uploads in progress have read so far, and
+	// uploads is how many there are, so that they can't exceed the quota
+	// together.
+	bytes   int64
+	uploads int
+}
+
+// admit checks that the blob h can be uploaded to repo, and reserves room for
+// it in the quota until the returned reader's commit or cancel is called. The
+// reader is r limited to the size the blob may have, and reserves the bytes
+// it reads.
+func (b *blobs) admit(repo string, h v1.Hash, r io.Reader) (*limitedReader, *regError) {
+	lr := &limitedReader{r: r, maxSize: b.maxSize, blobs: b, repo: repo}
+	if b.quota == (Quota{}) {
+		return lr, nil
+	}
+
+	b.usageLock.Lock()
+	defer b.usageLock.Unlock()
+	u := b.use(repo)
+	if _, ok := u.blobs[h]; ok {
+		// Uploading it again doesn't use any more of the quota.
+		return lr, nil
+	}
+	if b.quota.Blobs > 0 && len(u.blobs)+u.uploads >= b.quota.Blobs {
+		return nil, &regError{
+			Status:  http.StatusTooManyRequests,
+			Code:    "TOOMANYREQUESTS",
+			Message: fmt.Sprintf("repository %s is limited to %d blobs", repo, b.quota.Blobs),
+		}
+	}
+	if b.quota.Bytes > 0 && u.bytes >= b.quota.Bytes {
+		return nil, b.bytesExceeded(repo)
+	}
+	u.uploads++
+	lr.reserved = true
+	return lr, nil
+}
+
+// bytesExceeded is the error for repo using more than its quota of bytes.
+func (b *blobs) bytesExceeded(repo string) *regError {
+	return &regError{
+		Status:  http.StatusForbidden,
+		Code:    "DENIED",
+		Message: fmt.Sprintf("repository %s is limited to %d bytes of blobs", repo, b.quota.Bytes),
+	}
+}
+
+// use returns the usage of repo, with b.usageLock held.
+func (b *blobs) use(repo string) *usage {
+	if b.usage == nil {
+		b.usage = map[string]*usage{}
+	}
+	u, ok := b.usage[repo]
+	if !ok {
+		u = &usage{blobs: map[v1.Hash]int64{}}
+		b.usage[repo] = u
+	}
+	return u
+}
+
+// release stops counting the blob h against the quota of repo.
+func (b *blobs) release(repo string, h v1.Hash) {
+	b.usageLock.Lock()
+	defer b.usageLock.Unlock()
+	if u, ok := b.usage[repo]; ok {
+		u.bytes -= u.blobs[h]
+		delete(u.blobs, h)
+	}
+}
+
+// limitedReader fails once more is read from r than the maximum blob size,
+// or than is left of the repository's quota, with a *limitError.
+type limitedReader struct {
+	r io.Reader
+	n int64
+
+	// maxSize is the maximum blob size, if positive.
+	maxSize int64
+
+	// The upload is to repo in blobs. If reserved, it has room in the quota
+	// for a blob, and counts its bytes against the quota as they're read.
+	blobs    *blobs
+	repo     string
+	reserved bool
+	bytes    int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.maxSize > 0 && l.n > l.maxSize {
+		return n, &limitError{regErrSizeInvalid("blob", l.maxSize)}
+	}
+	if rerr := l.reserve(int64(n)); rerr != nil {
+		return n, &limitError{rerr}
+	}
+	return n, err
+}
+
+// reserve counts n more bytes of the upload against the quota, failing if
+// that exceeds it.
+func (l *limitedReader) reserve(n int64) *regError {
+	b := l.blobs
+	if !l.reserved || b.quota.Bytes <= 0 {
+		return nil
+	}
+	b.usageLock.Lock()
+	defer b.usageLock.Unlock()
+	u := b.use(l.repo)
+	u.bytes += n
+	l.bytes += n
+	if u.bytes > b.quota.Bytes {
+		return b.bytesExceeded(l.repo)
+	}
+	return nil
+}
+
+// commit counts the uploaded blob h, of size bytes, against the quota in
+// place of what l reserved. The blobs counted are also those that garbage
+// collection considers.
+func (l *limitedReader) commit(h v1.Hash, size int64) {
+	b := l.blobs
+	if b.quota == (Quota{}) && b.referenced == nil {
+		return
+	}
+	b.usageLock.Lock()
+	defer b.usageLock.Unlock()
+	u := b.use(l.repo)
+	l.unreserve(u)
+	if _, ok := u.blobs[h]; ok {
+		return
+	}
+	u.blobs[h] = size
+	u.bytes += size
+}
+
+// cancel gives back what l reserved, if it wasn't committed.
+func (l *limitedReader) cancel() {
+	if !l.reserved {
+		return
+	}
+	b := l.blobs
+	b.usageLock.Lock()
+	defer b.usageLock.Unlock()
+	l.unreserve(b.use(l.repo))
+}
+
+// unreserve gives back what l reserved in u, with the usage lock held.
+func (l *limitedReader) unreserve(u *usage) {
+	if !l.reserved {
+		return
+	}
+	u.uploads--
+	u.bytes -= l.bytes
+	l.reserved, l.bytes = false, 0
+}
+
+// limitError is the error of a limitedReader, which is reported to the
+// client as rerr.
+type limitError struct {
+	rerr *regError
+}
+
+func (e *limitError) Error() string { return e.rerr.Message }
@@ -0,0 +1,192 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// limitsServer serves a registry with opts, returning a repository in it.
+func limitsServer(t *testing.T, opts ...registry.Option) name.Repository {
+	t.Helper()
+	s := httptest.NewServer(registry.New(append([]registry.Option{registry.Logger(log.New(io.Discard, "", 0))}, opts...)...))
+	t.Cleanup(s.Close)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// randomLayer returns a layer of about size bytes.
+func randomLayer(t *testing.T, size int64) v1.Layer {
+	t.Helper()
+	l, err := random.Layer(size, types.OCIUncompressedLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// checkCode checks that err is a registry error with status and code.
+func checkCode(t *testing.T, what string, err error, status int, code transport.ErrorCode) {
+	t.Helper()
+	var terr *transport.Error
+	if !errors.As(err, &terr) {
+		t.Fatalf("%s = %v, want a %d %s error", what, err, status, code)
+	}
+	if terr.StatusCode != status || len(terr.Errors) == 0 || terr.Errors[0].Code != code {
+		t.Errorf("%s = %v, want a %d %s error", what, err, status, code)
+	}
+}
+
+// noRetries doesn't retry errors, so that the registry's are seen.
+var noRetries = remote.WithRetryBackoff(remote.Backoff{Steps: 1})
+
+func TestMaxBlobSize(t *testing.T) {
+	repo := limitsServer(t, registry.WithMaxBlobSize(10*1024))
+
+	if err := remote.WriteLayer(repo, randomLayer(t, 1024), noRetries); err != nil {
+		t.Errorf("WriteLayer(small) = %v", err)
+	}
+	err := remote.WriteLayer(repo, randomLayer(t, 20*1024), noRetries)
+	checkCode(t, "WriteLayer(large)", err, http.StatusRequestEntityTooLarge, transport.ErrorCode("SIZE_INVALID"))
+}
+
+func TestMaxManifestSize(t *testing.T) {
+	repo := limitsServer(t, registry.WithMaxManifestSize(1024))
+
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(repo.Tag("small"), img, noRetries); err != nil {
+		t.Errorf("Write(small) = %v", err)
+	}
+	img, err = random.Image(100, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = remote.Write(repo.Tag("large"), img, noRetries)
+	checkCode(t, "Write(large)", err, http.StatusRequestEntityTooLarge, transport.ErrorCode("SIZE_INVALID"))
+}
+
+func TestRepositoryQuota(t *testing.T) {
+	t.Run("blobs", func(t *testing.T) {
+		repo := limitsServer(t, registry.WithRepositoryQuota(registry.Quota{Blobs: 2}))
+		for i := 0; i < 2; i++ {
+			if err := remote.WriteLayer(repo, randomLayer(t, 100), noRetries); err != nil {
+				t.Fatalf("WriteLayer() #%d = %v", i, err)
+			}
+		}
+		err := remote.WriteLayer(repo, randomLayer(t, 100), noRetries)
+		checkCode(t, "WriteLayer() over quota", err, http.StatusTooManyRequests, transport.TooManyRequestsErrorCode)
+
+		// Other repositories have their own quota.
+		if err := remote.WriteLayer(repo.Registry.Repo("other"), randomLayer(t, 100), noRetries); err != nil {
+			t.Errorf("WriteLayer(other) = %v", err)
+		}
+	})
+
+	t.Run("bytes", func(t *testing.T) {
+		repo := limitsServer(t, registry.WithRepositoryQuota(registry.Quota{Bytes: 3 * 1024}))
+		l := randomLayer(t, 2*1024)
+		if err := remote.WriteLayer(repo, l, noRetries); err != nil {
+			t.Fatalf("WriteLayer() = %v", err)
+		}
+		err := remote.WriteLayer(repo, randomLayer(t, 2*1024), noRetries)
+		checkCode(t, "WriteLayer() over quota", err, http.StatusForbidden, transport.DeniedErrorCode)
+
+		// Deleting a blob frees its share of the quota.
+		digest, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest(http.MethodDelete, "http://"+repo.RegistryStr()+"/v2/test/blobs/"+digest.String(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if err := remote.WriteLayer(repo, randomLayer(t, 2*1024), noRetries); err != nil {
+			t.Errorf("WriteLayer() after deleting = %v", err)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		// Unlike the in-memory handler, the disk handler doesn't hold a lock
+		// while it reads an upload.
+		repo := limitsServer(t, registry.WithRepositoryQuota(registry.Quota{Blobs: 1}), registry.WithBlobHandler(registry.NewDiskBlobHandler(t.TempDir())))
+
+		// Start uploading a blob, and leave it half done.
+		b := []byte("some blob content")
+		h, _, err := v1.SHA256(bytes.NewReader(b))
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, pw := io.Pipe()
+		defer pw.Close()
+		done := make(chan error, 1)
+		go func() {
+			resp, err := http.Post("http://"+repo.RegistryStr()+"/v2/test/blobs/uploads/?digest="+h.String(), "application/octet-stream", pr)
+			if err == nil {
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusCreated {
+					err = fmt.Errorf("status %d", resp.StatusCode)
+				}
+			}
+			done <- err
+		}()
+		// Once the registry reads some, it has admitted the upload.
+		if _, err := pw.Write(b[:4]); err != nil {
+			t.Fatal(err)
+		}
+
+		// That upload holds the only place in the quota.
+		err = remote.WriteLayer(repo, randomLayer(t, 100), noRetries)
+		checkCode(t, "WriteLayer() during another upload", err, http.StatusTooManyRequests, transport.TooManyRequestsErrorCode)
+
+		if _, err := pw.Write(b[4:]); err != nil {
+			t.Fatal(err)
+		}
+		pw.Close()
+		if err := <-done; err != nil {
+			t.Fatalf("uploading: %v", err)
+		}
+	})
+}
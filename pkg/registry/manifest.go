@@ -21,6 +21,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"sort"
 	"strconv"
 	"strings"
@@ -49,6 +50,30 @@ type manifests struct {
 	manifests map[string]map[string]manifest
 	lock      sync.RWMutex
 	log       *log.Logger
+
+	// flatCatalog collapses nested repositories in the catalog down to
+	// their top-level namespace.
+	flatCatalog bool
+
+	// strictSubjects rejects manifests whose subject isn't in the repository.
+	strictSubjects bool
+
+	// fallbackReferrers maintains the referrers tag schema (sha256-<hex>)
+	// for manifests with a subject, as clients would when the referrers API
+	// is unavailable.
+	fallbackReferrers bool
+
+	// maxPageSize, if positive, caps the number of tags listed at once,
+	// whatever n asks for.
+	maxPageSize int
+
+	// noCatalog refuses to serve the catalog.
+	noCatalog bool
+
+	// maxSize, if positive, is the largest manifest that can be pushed.
+	maxSize int64
+
+	events func(Event)
 }
 
 func isManifest(req *http.Request) bool {
@@ -99,18 +124,21 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 
 	switch req.Method {
 	case http.MethodGet:
+		// Manifests are replaced rather than changed, so mf can be used
+		// after unlocking, which lets the event handler call back into the
+		// registry.
 		m.lock.RLock()
-		defer m.lock.RUnlock()
-
 		c, ok := m.manifests[repo]
 		if !ok {
+			m.lock.RUnlock()
 			return &regError{
 				Status:  http.StatusNotFound,
 				Code:    "NAME_UNKNOWN",
 				Message: "Unknown name",
 			}
 		}
-		m, ok := c[target]
+		mf, ok := c[target]
+		m.lock.RUnlock()
 		if !ok {
 			return &regError{
 				Status:  http.StatusNotFound,
@@ -119,12 +147,20 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 			}
 		}
 
-		h, _, _ := v1.SHA256(bytes.NewReader(m.blob))
+		h, _, _ := v1.SHA256(bytes.NewReader(mf.blob))
+		etag := fmt.Sprintf("%q", h)
+		resp.Header().Set("ETag", etag)
+		if req.Header.Get("If-None-Match") == etag {
+			resp.Header().Set("Docker-Content-Digest", h.String())
+			resp.WriteHeader(http.StatusNotModified)
+			return nil
+		}
+		m.emit(Event{Type: ManifestPull, Repository: repo, Digest: h, Tag: tagOf(target), MediaType: types.MediaType(mf.contentType), Size: int64(len(mf.blob))})
 		resp.Header().Set("Docker-Content-Digest", h.String())
-		resp.Header().Set("Content-Type", m.contentType)
-		resp.Header().Set("Content-Length", fmt.Sprint(len(m.blob)))
+		resp.Header().Set("Content-Type", mf.contentType)
+		resp.Header().Set("Content-Length", fmt.Sprint(len(mf.blob)))
 		resp.WriteHeader(http.StatusOK)
-		io.Copy(resp, bytes.NewReader(m.blob))
+		io.Copy(resp, bytes.NewReader(mf.blob))
 		return nil
 
 	case http.MethodHead:
@@ -148,6 +184,7 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 		}
 
 		h, _, _ := v1.SHA256(bytes.NewReader(m.blob))
+		resp.Header().Set("ETag", fmt.Sprintf("%q", h))
 		resp.Header().Set("Docker-Content-Digest", h.String())
 		resp.Header().Set("Content-Type", m.contentType)
 		resp.Header().Set("Content-Length", fmt.Sprint(len(m.blob)))
@@ -156,7 +193,14 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 
 	case http.MethodPut:
 		b := &bytes.Buffer{}
-		io.Copy(b, req.Body)
+		if m.maxSize > 0 {
+			io.Copy(b, io.LimitReader(req.Body, m.maxSize+1))
+			if int64(b.Len()) > m.maxSize {
+				return regErrSizeInvalid("manifest", m.maxSize)
+			}
+		} else {
+			io.Copy(b, req.Body)
+		}
 		h, _, _ := v1.SHA256(bytes.NewReader(b.Bytes()))
 		digest := h.String()
 		mf := manifest{
@@ -204,9 +248,21 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 			}
 		}
 
-		m.lock.Lock()
-		defer m.lock.Unlock()
+		subject, rdesc := referrerDescriptor(mf, h)
+		if subject != nil && m.strictSubjects {
+			m.lock.RLock()
+			_, found := m.manifests[repo][subject.Digest.String()]
+			m.lock.RUnlock()
+			if !found {
+				return &regError{
+					Status:  http.StatusNotFound,
+					Code:    "MANIFEST_UNKNOWN",
+					Message: fmt.Sprintf("Subject %q not found", subject.Digest),
+				}
+			}
+		}
 
+		m.lock.Lock()
 		if _, ok := m.manifests[repo]; !ok {
 			m.manifests[repo] = make(map[string]manifest, 2)
 		}
@@ -215,31 +271,70 @@ func (m *manifests) handle(resp http.ResponseWriter, req *http.Request) *regErro
 		// See https://docs.docker.com/engine/reference/commandline/pull/#pull-an-image-by-digest-immutable-identifier.
 		m.manifests[repo][digest] = mf
 		m.manifests[repo][target] = mf
+		e := Event{Type: ManifestPush, Repository: repo, Digest: h, Tag: tagOf(target), MediaType: types.MediaType(mf.contentType), Size: int64(len(mf.blob))}
+		events := []Event{e}
+		if e.Tag != "" {
+			e.Type = TagUpdate
+			events = append(events, e)
+		}
+		if subject != nil && m.fallbackReferrers {
+			events = append(events, m.updateFallbackReferrers(repo, subject.Digest, func(descs []v1.Descriptor) []v1.Descriptor {
+				for _, d := range descs {
+					if d.Digest == h {
+						return descs
+					}
+				}
+				return append(descs, rdesc)
+			})...)
+		}
+		m.lock.Unlock()
+		m.emit(events...)
+
 		resp.Header().Set("Docker-Content-Digest", digest)
 		resp.WriteHeader(http.StatusCreated)
 		return nil
 
 	case http.MethodDelete:
-		m.lock.Lock()
-		defer m.lock.Unlock()
-		if _, ok := m.manifests[repo]; !ok {
-			return &regError{
-				Status:  http.StatusNotFound,
-				Code:    "NAME_UNKNOWN",
-				Message: "Unknown name",
+		events, rerr := func() ([]Event, *regError) {
+			m.lock.Lock()
+			defer m.lock.Unlock()
+			if _, ok := m.manifests[repo]; !ok {
+				return nil, &regError{
+					Status:  http.StatusNotFound,
+					Code:    "NAME_UNKNOWN",
+					Message: "Unknown name",
+				}
 			}
-		}
 
-		_, ok := m.manifests[repo][target]
-		if !ok {
-			return &regError{
-				Status:  http.StatusNotFound,
-				Code:    "MANIFEST_UNKNOWN",
-				Message: "Unknown manifest",
+			mf, ok := m.manifests[repo][target]
+			if !ok {
+				return nil, &regError{
+					Status:  http.StatusNotFound,
+					Code:    "MANIFEST_UNKNOWN",
+					Message: "Unknown manifest",
+				}
 			}
-		}
 
-		delete(m.manifests[repo], target)
+			delete(m.manifests[repo], target)
+			h, _, _ := v1.SHA256(bytes.NewReader(mf.blob))
+			events := []Event{{Type: ManifestDelete, Repository: repo, Digest: h, Tag: tagOf(target), MediaType: types.MediaType(mf.contentType), Size: int64(len(mf.blob))}}
+			if subject, _ := referrerDescriptor(mf, h); subject != nil && m.fallbackReferrers && tagOf(target) == "" {
+				events = append(events, m.updateFallbackReferrers(repo, subject.Digest, func(descs []v1.Descriptor) []v1.Descriptor {
+					kept := []v1.Descriptor{}
+					for _, d := range descs {
+						if d.Digest != h {
+							kept = append(kept, d)
+						}
+					}
+					return kept
+				})...)
+			}
+			return events, nil
+		}()
+		if rerr != nil {
+			return rerr
+		}
+		m.emit(events...)
 		resp.WriteHeader(http.StatusAccepted)
 		return nil
 
@@ -281,24 +376,34 @@ func (m *manifests) handleTags(resp http.ResponseWriter, req *http.Request) *reg
 		// https://github.com/opencontainers/distribution-spec/blob/b505e9cc53ec499edbd9c1be32298388921bb705/detail.md#tags-paginated
 		// Offset using last query parameter.
 		if last := req.URL.Query().Get("last"); last != "" {
-			for i, t := range tags {
-				if t > last {
-					tags = tags[i:]
-					break
-				}
+			tags = tags[sort.SearchStrings(tags, last):]
+			if len(tags) != 0 && tags[0] == last {
+				tags = tags[1:]
 			}
 		}
 
 		// Limit using n query parameter.
+		n := -1
 		if ns := req.URL.Query().Get("n"); ns != "" {
-			if n, err := strconv.Atoi(ns); err != nil {
+			var err error
+			if n, err = strconv.Atoi(ns); err != nil {
 				return &regError{
 					Status:  http.StatusBadRequest,
 					Code:    "BAD_REQUEST",
 					Message: fmt.Sprintf("parsing n: %v", err),
 				}
-			} else if n < len(tags) {
-				tags = tags[:n]
+			}
+		}
+		if m.maxPageSize > 0 && (n < 0 || n > m.maxPageSize) {
+			n = m.maxPageSize
+		}
+		if n >= 0 && n < len(tags) {
+			tags = tags[:n]
+			if n > 0 {
+				next := url.Values{}
+				next.Set("n", strconv.Itoa(n))
+				next.Set("last", tags[n-1])
+				resp.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, req.URL.Path, next.Encode()))
 			}
 		}
 
@@ -322,6 +427,9 @@ func (m *manifests) handleTags(resp http.ResponseWriter, req *http.Request) *reg
 }
 
 func (m *manifests) handleCatalog(resp http.ResponseWriter, req *http.Request) *regError {
+	if m.noCatalog {
+		return regErrUnsupported
+	}
 	query := req.URL.Query()
 	nStr := query.Get("n")
 	n := 10000
@@ -333,17 +441,29 @@ func (m *manifests) handleCatalog(resp http.ResponseWriter, req *http.Request) *
 		m.lock.RLock()
 		defer m.lock.RUnlock()
 
-		var repos []string
-		countRepos := 0
-		// TODO: implement pagination
+		// The prefix parameter isn't part of the distribution spec, but some
+		// registries support scoping the catalog to a namespace like this.
+		prefix := query.Get("prefix")
+		seen := map[string]struct{}{}
 		for key := range m.manifests {
-			if countRepos >= n {
-				break
+			if !strings.HasPrefix(key, prefix) {
+				continue
 			}
-			countRepos++
-
+			if m.flatCatalog {
+				key, _, _ = strings.Cut(key, "/")
+			}
+			seen[key] = struct{}{}
+		}
+		repos := make([]string, 0, len(seen))
+		for key := range seen {
 			repos = append(repos, key)
 		}
+		sort.Strings(repos)
+
+		// TODO: implement pagination
+		if len(repos) > n {
+			repos = repos[:n]
+		}
 
 		repositoriesToList := catalog{
 			Repos: repos,
@@ -363,7 +483,13 @@ func (m *manifests) handleCatalog(resp http.ResponseWriter, req *http.Request) *
 	}
 }
 
-// TODO: implement handling of artifactType querystring
+// handleReferrers lists the referrers of a digest, sorted by digest.
+//
+// The artifactType query parameter filters the list, and the n query
+// parameter limits how many referrers are returned, with a Link header
+// pointing at the next page (continuing after the digest in last).
+//
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-referrers
 func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request) *regError {
 	// Ensure this is a GET request
 	if req.Method != "GET" {
@@ -400,41 +526,64 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 		}
 	}
 
+	query := req.URL.Query()
+	artifactType := query.Get("artifactType")
+	last := query.Get("last")
+	n := -1
+	if ns := query.Get("n"); ns != "" {
+		var err error
+		if n, err = strconv.Atoi(ns); err != nil || n < 0 {
+			return &regError{
+				Status:  http.StatusBadRequest,
+				Code:    "BAD_REQUEST",
+				Message: fmt.Sprintf("invalid n: %q", ns),
+			}
+		}
+	}
+
 	im := v1.IndexManifest{
 		SchemaVersion: 2,
 		MediaType:     types.OCIImageIndex,
 		Manifests:     []v1.Descriptor{},
 	}
 	for digest, manifest := range digestToManifestMap {
+		if last != "" && digest <= last {
+			continue
+		}
 		h, err := v1.NewHash(digest)
 		if err != nil {
 			continue
 		}
-		var refPointer struct {
-			Subject *v1.Descriptor `json:"subject"`
-		}
-		json.Unmarshal(manifest.blob, &refPointer)
-		if refPointer.Subject == nil {
+		subject, desc := referrerDescriptor(manifest, h)
+		if subject == nil || subject.Digest.String() != target {
 			continue
 		}
-		referenceDigest := refPointer.Subject.Digest
-		if referenceDigest.String() != target {
+		if artifactType != "" && desc.ArtifactType != artifactType {
 			continue
 		}
-		// At this point, we know the current digest references the target
-		var imageAsArtifact struct {
-			Config struct {
-				MediaType string `json:"mediaType"`
-			} `json:"config"`
-		}
-		json.Unmarshal(manifest.blob, &imageAsArtifact)
-		im.Manifests = append(im.Manifests, v1.Descriptor{
-			MediaType:    types.MediaType(manifest.contentType),
-			Size:         int64(len(manifest.blob)),
-			Digest:       h,
-			ArtifactType: imageAsArtifact.Config.MediaType,
-		})
+		im.Manifests = append(im.Manifests, desc)
+	}
+	sort.Slice(im.Manifests, func(i, j int) bool {
+		return im.Manifests[i].Digest.String() < im.Manifests[j].Digest.String()
+	})
+
+	if n == 0 {
+		im.Manifests = []v1.Descriptor{}
+	} else if n > 0 && n < len(im.Manifests) {
+		im.Manifests = im.Manifests[:n]
+		next := url.Values{}
+		next.Set("n", strconv.Itoa(n))
+		next.Set("last", im.Manifests[n-1].Digest.String())
+		if artifactType != "" {
+			next.Set("artifactType", artifactType)
+		}
+		resp.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, req.URL.Path, next.Encode()))
+	}
+
+	if artifactType != "" {
+		resp.Header().Set("OCI-Filters-Applied", "artifactType")
 	}
+
 	msg, _ := json.Marshal(&im)
 	resp.Header().Set("Content-Length", fmt.Sprint(len(msg)))
 	resp.Header().Set("Content-Type", string(types.OCIImageIndex))
@@ -442,3 +591,83 @@ func (m *manifests) handleReferrers(resp http.ResponseWriter, req *http.Request)
 	io.Copy(resp, bytes.NewReader([]byte(msg)))
 	return nil
 }
+
+// referrerDescriptor returns the subject of mf, which has digest h, and the
+// descriptor that lists mf as one of its subject's referrers. If mf has no
+// subject, it returns nil.
+func referrerDescriptor(mf manifest, h v1.Hash) (*v1.Descriptor, v1.Descriptor) {
+	var m struct {
+		ArtifactType string         `json:"artifactType"`
+		Subject      *v1.Descriptor `json:"subject"`
+		Config       struct {
+			MediaType string `json:"mediaType"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(mf.blob, &m); err != nil || m.Subject == nil {
+		return nil, v1.Descriptor{}
+	}
+	artifactType := m.ArtifactType
+	if artifactType == "" {
+		artifactType = m.Config.MediaType
+	}
+	return m.Subject, v1.Descriptor{
+		MediaType:    types.MediaType(mf.contentType),
+		Size:         int64(len(mf.blob)),
+		Digest:       h,
+		ArtifactType: artifactType,
+	}
+}
+
+// updateFallbackReferrers applies update to the referrers listed by the
+// referrers tag for subject in repo, and returns the events for it. The
+// caller must hold m.lock.
+//
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#referrers-tag-schema
+func (m *manifests) updateFallbackReferrers(repo string, subject v1.Hash, update func([]v1.Descriptor) []v1.Descriptor) []Event {
+	tag := subject.Algorithm + "-" + subject.Hex
+	im := v1.IndexManifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIImageIndex,
+	}
+	if old, ok := m.manifests[repo][tag]; ok {
+		if err := json.Unmarshal(old.blob, &im); err != nil {
+			m.log.Printf("ignoring invalid referrers tag %s/%s: %v", repo, tag, err)
+		}
+	}
+	im.Manifests = update(im.Manifests)
+	if im.Manifests == nil {
+		im.Manifests = []v1.Descriptor{}
+	}
+	sort.Slice(im.Manifests, func(i, j int) bool {
+		return im.Manifests[i].Digest.String() < im.Manifests[j].Digest.String()
+	})
+
+	b, _ := json.Marshal(&im)
+	h, _, _ := v1.SHA256(bytes.NewReader(b))
+	mf := manifest{blob: b, contentType: string(types.OCIImageIndex)}
+	m.manifests[repo][h.String()] = mf
+	m.manifests[repo][tag] = mf
+	e := Event{Type: ManifestPush, Repository: repo, Digest: h, Tag: tag, MediaType: types.OCIImageIndex, Size: int64(len(b))}
+	tagged := e
+	tagged.Type = TagUpdate
+	return []Event{e, tagged}
+}
+
+// emit calls the event handler, if there is one, with events. Callers must
+// not hold m.lock, so that handlers can call back into the registry.
+func (m *manifests) emit(events ...Event) {
+	if m.events == nil {
+		return
+	}
+	for _, e := range events {
+		m.events(e)
+	}
+}
+
+// tagOf returns target if it is a tag, or "" if it is a digest.
+func tagOf(target string) string {
+	if _, err := v1.NewHash(target); err == nil {
+		return ""
+	}
+	return target
+}
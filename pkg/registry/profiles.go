@@ -0,0 +1,174 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The profiles below emulate the quirks of popular registries that clients
+// have to cope with, so that clients can be tested against them offline.
+// They are approximations: each registry's behavior changes over time and
+// differs by account, and only the quirks listed are emulated. Options passed
+// after a profile override it.
+
+// ProfileDockerHub emulates Docker Hub:
+//   - blobs can be mounted from other repositories;
+//   - GETs of manifests are rate limited to 100 per six hours, with the
+//     RateLimit-Limit and RateLimit-Remaining headers on manifest responses
+//     and 429 TOOMANYREQUESTS once the limit is reached;
+//   - errors have the messages of the distribution registry that Docker Hub
+//     runs;
+//   - the catalog isn't served.
+func ProfileDockerHub() Option {
+	return func(r *registry) {
+		r.blobs.mount = true
+		r.rateLimit = &rateLimit{limit: 100, window: 6 * time.Hour}
+		r.manifests.noCatalog = true
+		r.rewriteError = func(_ *http.Request, rerr *regError) *regError {
+			msg, ok := distributionMessages[rerr.Code]
+			if !ok {
+				return rerr
+			}
+			return &regError{
+				Status:  rerr.Status,
+				Code:    rerr.Code,
+				Message: msg,
+			}
+		}
+	}
+}
+
+// ProfileECR emulates Amazon Elastic Container Registry:
+//   - blobs can't be mounted, so mount requests start uploads instead;
+//   - monolithic uploads in a single POST aren't supported, so POSTs with a
+//     digest start uploads instead;
+//   - pages of tags have at most 1000 entries, whatever n asks for;
+//   - errors have ECR's messages;
+//   - the catalog isn't served.
+func ProfileECR() Option {
+	return func(r *registry) {
+		r.blobs.mount = false
+		r.blobs.noMonolithicPost = true
+		r.manifests.maxPageSize = 1000
+		r.manifests.noCatalog = true
+		r.rewriteError = func(req *http.Request, rerr *regError) *regError {
+			switch rerr.Code {
+			case "NAME_UNKNOWN":
+				return &regError{
+					Status:  rerr.Status,
+					Code:    rerr.Code,
+					Message: fmt.Sprintf("The repository with name '%s' does not exist in the registry", repoOf(req)),
+				}
+			case "MANIFEST_UNKNOWN":
+				return &regError{
+					Status:  rerr.Status,
+					Code:    rerr.Code,
+					Message: "Requested image not found",
+				}
+			}
+			return rerr
+		}
+	}
+}
+
+// ProfileHarbor emulates Harbor:
+//   - blobs can be mounted from other repositories;
+//   - repositories and manifests that don't exist are reported with the code
+//     NOT_FOUND, rather than NAME_UNKNOWN or MANIFEST_UNKNOWN.
+func ProfileHarbor() Option {
+	return func(r *registry) {
+		r.blobs.mount = true
+		r.rewriteError = func(req *http.Request, rerr *regError) *regError {
+			switch rerr.Code {
+			case "NAME_UNKNOWN":
+				return &regError{
+					Status:  http.StatusNotFound,
+					Code:    "NOT_FOUND",
+					Message: fmt.Sprintf("repository %s not found", repoOf(req)),
+				}
+			case "MANIFEST_UNKNOWN":
+				return &regError{
+					Status:  http.StatusNotFound,
+					Code:    "NOT_FOUND",
+					Message: fmt.Sprintf("artifact %s:%s not found", repoOf(req), req.URL.Path[strings.LastIndex(req.URL.Path, "/")+1:]),
+				}
+			}
+			return rerr
+		}
+	}
+}
+
+// distributionMessages are the messages that the distribution registry
+// uses for some error codes.
+var distributionMessages = map[string]string{
+	"BLOB_UNKNOWN":     "blob unknown to registry",
+	"DIGEST_INVALID":   "provided digest did not match uploaded content",
+	"MANIFEST_UNKNOWN": "manifest unknown",
+	"NAME_UNKNOWN":     "repository name not known to registry",
+}
+
+// repoOf returns the repository that req is for.
+func repoOf(req *http.Request) string {
+	elem := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(elem) < 4 {
+		return ""
+	}
+	return strings.Join(elem[1:len(elem)-2], "/")
+}
+
+// rateLimit limits the number of manifest GETs in a window, as Docker Hub's
+// pull rate limit does.
+type rateLimit struct {
+	limit  int
+	window time.Duration
+
+	mu    sync.Mutex
+	start time.Time
+	used  int
+}
+
+// apply counts req if it is a manifest GET, and sets the rate limit headers
+// on responses about manifests, returning an error once the limit has been
+// reached.
+func (rl *rateLimit) apply(resp http.ResponseWriter, req *http.Request) *regError {
+	if !isManifest(req) || (req.Method != http.MethodGet && req.Method != http.MethodHead) {
+		return nil
+	}
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	if now := time.Now(); now.Sub(rl.start) > rl.window {
+		rl.start, rl.used = now, 0
+	}
+	w := int(rl.window.Seconds())
+	resp.Header().Set("RateLimit-Limit", fmt.Sprintf("%d;w=%d", rl.limit, w))
+	if req.Method == http.MethodGet {
+		if rl.used >= rl.limit {
+			resp.Header().Set("RateLimit-Remaining", fmt.Sprintf("0;w=%d", w))
+			return &regError{
+				Status:  http.StatusTooManyRequests,
+				Code:    "TOOMANYREQUESTS",
+				Message: "You have reached your pull rate limit.",
+			}
+		}
+		rl.used++
+	}
+	resp.Header().Set("RateLimit-Remaining", fmt.Sprintf("%d;w=%d", rl.limit-rl.used, w))
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// profileServer serves a registry with profile, with an image at test:latest.
+func profileServer(t *testing.T, profile registry.Option) (*httptest.Server, name.Reference) {
+	t.Helper()
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0)), profile))
+	t.Cleanup(s.Close)
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/test:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Clients must cope with every profile.
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatalf("remote.Write: %v", err)
+	}
+	if _, err := remote.Image(ref); err != nil {
+		t.Fatalf("remote.Image: %v", err)
+	}
+	return s, ref
+}
+
+// profileDo makes a request, returning the response with its body read and
+// the code of the first error in it, if any.
+func profileDo(t *testing.T, method, u string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if json.Unmarshal(b, &body) == nil && len(body.Errors) != 0 {
+		return resp, body.Errors[0].Code
+	}
+	return resp, ""
+}
+
+func layerDigest(t *testing.T, ref name.Reference) string {
+	t.Helper()
+	img, err := remote.Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m.Layers[0].Digest.String()
+}
+
+func TestProfileDockerHub(t *testing.T) {
+	s, ref := profileServer(t, registry.ProfileDockerHub())
+
+	// Mounts work.
+	resp, _ := profileDo(t, http.MethodPost, s.URL+"/v2/other/blobs/uploads/?mount="+layerDigest(t, ref)+"&from=test")
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("mount = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+
+	// Pulls are counted down, and HEADs don't count.
+	resp, _ = profileDo(t, http.MethodHead, s.URL+"/v2/test/manifests/latest")
+	before := resp.Header.Get("RateLimit-Remaining")
+	resp, _ = profileDo(t, http.MethodGet, s.URL+"/v2/test/manifests/latest")
+	after := resp.Header.Get("RateLimit-Remaining")
+	if resp.Header.Get("RateLimit-Limit") != "100;w=21600" || before == after {
+		t.Errorf("rate limit headers: limit %q, remaining %q then %q", resp.Header.Get("RateLimit-Limit"), before, after)
+	}
+	for i := 0; i < 100; i++ {
+		resp, _ = profileDo(t, http.MethodGet, s.URL+"/v2/test/manifests/latest")
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("GET after the limit = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+
+	if resp, code := profileDo(t, http.MethodGet, s.URL+"/v2/missing/tags/list"); resp.StatusCode != http.StatusNotFound || code != "NAME_UNKNOWN" {
+		t.Errorf("missing repository = %d %s, want 404 NAME_UNKNOWN", resp.StatusCode, code)
+	}
+	if resp, _ := profileDo(t, http.MethodGet, s.URL+"/v2/_catalog"); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("catalog = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestProfileECR(t *testing.T) {
+	s, ref := profileServer(t, registry.ProfileECR())
+	digest := layerDigest(t, ref)
+
+	// Mounts and monolithic POSTs start uploads instead.
+	for _, q := range []string{"mount=" + digest + "&from=test", "digest=" + digest} {
+		if resp, _ := profileDo(t, http.MethodPost, s.URL+"/v2/other/blobs/uploads/?"+q); resp.StatusCode != http.StatusAccepted {
+			t.Errorf("POST ?%s = %d, want %d", q, resp.StatusCode, http.StatusAccepted)
+		}
+	}
+
+	// Pages of tags are capped at 1000, but clients can still list them all.
+	img, err := remote.Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		if err := remote.Tag(ref.Context().Tag(fmt.Sprintf("t%04d", i)), img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, _ := profileDo(t, http.MethodGet, s.URL+"/v2/test/tags/list?n=5000")
+	if !strings.Contains(resp.Header.Get("Link"), "n=1000") {
+		t.Errorf("tags Link = %q, want the next page of 1000", resp.Header.Get("Link"))
+	}
+	tags, err := remote.List(ref.Context(), remote.WithPageSize(5000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1001 {
+		t.Errorf("remote.List() returned %d tags, want 1001", len(tags))
+	}
+
+	if resp, code := profileDo(t, http.MethodGet, s.URL+"/v2/test/manifests/missing"); resp.StatusCode != http.StatusNotFound || code != "MANIFEST_UNKNOWN" {
+		t.Errorf("missing manifest = %d %s, want 404 MANIFEST_UNKNOWN", resp.StatusCode, code)
+	}
+}
+
+func TestProfileHarbor(t *testing.T) {
+	s, ref := profileServer(t, registry.ProfileHarbor())
+
+	if resp, _ := profileDo(t, http.MethodPost, s.URL+"/v2/other/blobs/uploads/?mount="+layerDigest(t, ref)+"&from=test"); resp.StatusCode != http.StatusCreated {
+		t.Errorf("mount = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	for _, path := range []string{"/v2/test/manifests/missing", "/v2/missing/manifests/latest"} {
+		if resp, code := profileDo(t, http.MethodGet, s.URL+path); resp.StatusCode != http.StatusNotFound || code != "NOT_FOUND" {
+			t.Errorf("GET %s = %d %s, want 404 NOT_FOUND", path, resp.StatusCode, code)
+		}
+	}
+}
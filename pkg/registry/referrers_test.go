@@ -0,0 +1,231 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const (
+	subjectManifest  = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","size":2,"digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"},"layers":[]}`
+	referrerTemplate = `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","artifactType":"application/vnd.example.sig","config":{"mediaType":"application/vnd.oci.empty.v1+json","size":2,"digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"},"layers":[],"subject":{"mediaType":"application/vnd.oci.image.manifest.v1+json","size":%d,"digest":%q}}`
+)
+
+func digestOf(t *testing.T, s string) v1.Hash {
+	t.Helper()
+	h, _, err := v1.SHA256(bytes.NewReader([]byte(s)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func do(t *testing.T, method, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", string(types.OCIManifestSchema1))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestStrictSubjects(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.WithStrictSubjects(true)))
+	defer s.Close()
+
+	sub := digestOf(t, subjectManifest)
+	referrer := fmt.Sprintf(referrerTemplate, len(subjectManifest), sub)
+	u := s.URL + "/v2/foo/manifests/"
+
+	if resp := do(t, http.MethodPut, u+"referrer", referrer); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("PUT without subject: got %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if resp := do(t, http.MethodPut, u+"subject", subjectManifest); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("PUT subject: got %d", resp.StatusCode)
+	}
+	if resp := do(t, http.MethodPut, u+"referrer", referrer); resp.StatusCode != http.StatusCreated {
+		t.Errorf("PUT with subject: got %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+}
+
+func TestReferrersTag(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		opts      []registry.Option
+		maintains bool
+	}{{
+		name: "disabled",
+	}, {
+		name:      "enabled",
+		opts:      []registry.Option{registry.WithReferrersTag(true)},
+		maintains: true,
+	}, {
+		name: "referrers API",
+		opts: []registry.Option{registry.WithReferrersTag(true), registry.WithReferrersSupport(true)},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := httptest.NewServer(registry.New(append(tc.opts, registry.Logger(log.New(io.Discard, "", 0)))...))
+			defer s.Close()
+
+			sub := digestOf(t, subjectManifest)
+			referrer := fmt.Sprintf(referrerTemplate, len(subjectManifest), sub)
+			rh := digestOf(t, referrer)
+			u := s.URL + "/v2/foo/manifests/"
+			fallback := u + sub.Algorithm + "-" + sub.Hex
+
+			referrers := func() []v1.Descriptor {
+				resp := do(t, http.MethodGet, fallback, "")
+				if resp.StatusCode == http.StatusNotFound {
+					return nil
+				}
+				im, err := v1.ParseIndexManifest(resp.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return im.Manifests
+			}
+
+			do(t, http.MethodPut, u+"subject", subjectManifest)
+			do(t, http.MethodPut, u+rh.String(), referrer)
+
+			got := referrers()
+			if !tc.maintains {
+				if got != nil {
+					t.Fatalf("referrers tag: got %v, want none", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].Digest != rh || got[0].ArtifactType != "application/vnd.example.sig" {
+				b, _ := json.Marshal(got)
+				t.Fatalf("referrers tag: got %s, want %s", b, rh)
+			}
+
+			// Pushing it again doesn't add a duplicate.
+			do(t, http.MethodPut, u+"again", referrer)
+			if got := referrers(); len(got) != 1 {
+				t.Errorf("referrers tag: got %d referrers, want 1", len(got))
+			}
+
+			if resp := do(t, http.MethodDelete, u+rh.String(), ""); resp.StatusCode != http.StatusAccepted {
+				t.Fatalf("DELETE: got %d", resp.StatusCode)
+			}
+			if got := referrers(); len(got) != 0 {
+				t.Errorf("referrers tag: got %v after delete, want none", got)
+			}
+		})
+	}
+}
+
+func TestReferrersFilterAndPagination(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.WithReferrersSupport(true)))
+	defer s.Close()
+
+	sub := digestOf(t, subjectManifest)
+	u := s.URL + "/v2/foo/manifests/"
+	do(t, http.MethodPut, u+"subject", subjectManifest)
+
+	var all, sigs []string
+	for i, at := range []string{"application/vnd.example.sig", "application/vnd.example.sbom", "application/vnd.example.sig"} {
+		referrer := fmt.Sprintf(referrerTemplate, len(subjectManifest), sub)
+		referrer = strings.Replace(referrer, "application/vnd.example.sig", at, 1)
+		referrer = strings.Replace(referrer, `"layers":[]`, fmt.Sprintf(`"layers":[],"annotations":{"i":"%d"}`, i), 1)
+		h := digestOf(t, referrer)
+		if resp := do(t, http.MethodPut, u+h.String(), referrer); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("PUT referrer: got %d", resp.StatusCode)
+		}
+		all = append(all, h.String())
+		if at == "application/vnd.example.sig" {
+			sigs = append(sigs, h.String())
+		}
+	}
+	sort.Strings(all)
+	sort.Strings(sigs)
+
+	list := func(path string) ([]string, *http.Response) {
+		t.Helper()
+		resp := do(t, http.MethodGet, s.URL+path, "")
+		if resp.StatusCode != http.StatusOK {
+			return nil, resp
+		}
+		im, err := v1.ParseIndexManifest(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, desc := range im.Manifests {
+			got = append(got, desc.Digest.String())
+		}
+		return got, resp
+	}
+	base := "/v2/foo/referrers/" + sub.String()
+
+	got, resp := list(base)
+	if diff := cmp.Diff(all, got); diff != "" {
+		t.Errorf("unfiltered (-want +got): %s", diff)
+	}
+	if h := resp.Header.Get("OCI-Filters-Applied"); h != "" {
+		t.Errorf("unfiltered OCI-Filters-Applied: got %q, want none", h)
+	}
+
+	got, resp = list(base + "?artifactType=application/vnd.example.sig")
+	if diff := cmp.Diff(sigs, got); diff != "" {
+		t.Errorf("filtered (-want +got): %s", diff)
+	}
+	if h := resp.Header.Get("OCI-Filters-Applied"); h != "artifactType" {
+		t.Errorf("filtered OCI-Filters-Applied: got %q, want artifactType", h)
+	}
+
+	// Follow Link headers until we've seen everything.
+	var paged []string
+	next := base + "?n=2"
+	for pages := 0; next != ""; pages++ {
+		if pages > len(all) {
+			t.Fatal("too many pages")
+		}
+		got, resp := list(next)
+		paged = append(paged, got...)
+		next = ""
+		if link := resp.Header.Get("Link"); link != "" {
+			next = strings.TrimPrefix(strings.Split(link, ">")[0], "<")
+		}
+	}
+	if diff := cmp.Diff(all, paged); diff != "" {
+		t.Errorf("paginated (-want +got): %s", diff)
+	}
+
+	if _, resp := list(base + "?n=nope"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid n: got %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -36,7 +36,14 @@ type registry struct {
 	blobs            blobs
 	manifests        manifests
 	referrersEnabled bool
+	refCounting      bool
 	warnings         map[float64]string
+	faults           faults
+	upstream         *upstream
+
+	// Set by the Profile options.
+	rateLimit    *rateLimit
+	rewriteError func(*http.Request, *regError) *regError
 }
 
 // https://docs.docker.com/registry/spec/api/#api-version-check
@@ -51,6 +58,9 @@ func (r *registry) v2(resp http.ResponseWriter, req *http.Request) *regError {
 		}
 	}
 
+	if r.upstream != nil && r.forwards(req) {
+		return r.forward(resp, req)
+	}
 	if isBlob(req) {
 		return r.blobs.handle(resp, req)
 	}
@@ -63,6 +73,9 @@ func (r *registry) v2(resp http.ResponseWriter, req *http.Request) *regError {
 	if isCatalog(req) {
 		return r.manifests.handleCatalog(resp, req)
 	}
+	if r.refCounting && isGC(req) {
+		return r.handleGC(resp, req)
+	}
 	if r.referrersEnabled && isReferrers(req) {
 		return r.manifests.handleReferrers(resp, req)
 	}
@@ -79,7 +92,17 @@ func (r *registry) v2(resp http.ResponseWriter, req *http.Request) *regError {
 }
 
 func (r *registry) root(resp http.ResponseWriter, req *http.Request) {
-	if rerr := r.v2(resp, req); rerr != nil {
+	resp, req, rerr := r.faults.apply(resp, req)
+	if rerr == nil && r.rateLimit != nil {
+		rerr = r.rateLimit.apply(resp, req)
+	}
+	if rerr == nil {
+		rerr = r.v2(resp, req)
+	}
+	if rerr != nil && r.rewriteError != nil {
+		rerr = r.rewriteError(req, rerr)
+	}
+	if rerr != nil {
 		r.log.Printf("%s %s %d %s %s", req.Method, req.URL, rerr.Status, rerr.Code, rerr.Message)
 		rerr.Write(resp)
 		return
@@ -105,6 +128,13 @@ func New(opts ...Option) http.Handler {
 	for _, o := range opts {
 		o(r)
 	}
+	if r.referrersEnabled {
+		// Clients only use the referrers tag schema without the referrers API.
+		r.manifests.fallbackReferrers = false
+	}
+	if r.refCounting {
+		r.blobs.referenced = r.manifests.referenced
+	}
 	return http.HandlerFunc(r.root)
 }
 
@@ -128,6 +158,36 @@ func WithReferrersSupport(enabled bool) Option {
 	}
 }
 
+// WithStrictSubjects rejects manifests whose subject (OCI 1.1+) doesn't
+// refer to a manifest that is already in the repository. By default, as the
+// distribution spec requires, such manifests are accepted.
+func WithStrictSubjects(strict bool) Option {
+	return func(r *registry) {
+		r.manifests.strictSubjects = strict
+	}
+}
+
+// WithReferrersTag maintains the referrers tag schema (sha256-<hex>) for
+// manifests with a subject, adding them to the index at their subject's
+// referrers tag when they are pushed and removing them when they are deleted
+// by digest. This mimics a registry that updates the fallback tag itself,
+// and has no effect if the referrers API is enabled.
+func WithReferrersTag(enabled bool) Option {
+	return func(r *registry) {
+		r.manifests.fallbackReferrers = enabled
+	}
+}
+
+// WithNestedCatalog controls how the _catalog endpoint lists nested
+// repositories. By default every repository is listed in full (e.g. "a/b/c").
+// When nested is false, only top-level namespaces (e.g. "a") are listed, as
+// some registries do.
+func WithNestedCatalog(nested bool) Option {
+	return func(r *registry) {
+		r.manifests.flatCatalog = !nested
+	}
+}
+
 func WithWarning(prob float64, msg string) Option {
 	return func(r *registry) {
 		if r.warnings == nil {
@@ -60,6 +60,7 @@ func TestCalls(t *testing.T) {
 		Manifests     map[string]string
 		BlobStream    map[string]string
 		RequestHeader map[string]string
+		Options       []registry.Option
 
 		// Response
 		Code   int
@@ -473,6 +474,31 @@ func TestCalls(t *testing.T) {
 			URL:         "/v2/_catalog?n=1000",
 			Code:        http.StatusOK,
 		},
+		{
+			Description: "list nested repos",
+			Manifests:   map[string]string{"foo/a/manifests/latest": "foo", "foo/b/c/manifests/latest": "foo", "bar/manifests/latest": "bar"},
+			Method:      "GET",
+			URL:         "/v2/_catalog",
+			Code:        http.StatusOK,
+			Want:        `{"repositories":["bar","foo/a","foo/b/c"]}`,
+		},
+		{
+			Description: "list repos with prefix",
+			Manifests:   map[string]string{"foo/a/manifests/latest": "foo", "foo/b/c/manifests/latest": "foo", "bar/manifests/latest": "bar"},
+			Method:      "GET",
+			URL:         "/v2/_catalog?prefix=foo/",
+			Code:        http.StatusOK,
+			Want:        `{"repositories":["foo/a","foo/b/c"]}`,
+		},
+		{
+			Description: "list top-level repos",
+			Manifests:   map[string]string{"foo/a/manifests/latest": "foo", "foo/b/c/manifests/latest": "foo", "bar/manifests/latest": "bar"},
+			Options:     []registry.Option{registry.WithNestedCatalog(false)},
+			Method:      "GET",
+			URL:         "/v2/_catalog?n=1",
+			Code:        http.StatusOK,
+			Want:        `{"repositories":["bar"]}`,
+		},
 		{
 			Description: "fetch references",
 			Method:      "GET",
@@ -537,6 +563,7 @@ func TestCalls(t *testing.T) {
 		testf := func(t *testing.T) {
 
 			opts := []registry.Option{registry.WithReferrersSupport(true)}
+			opts = append(opts, tc.Options...)
 			if logger != nil {
 				opts = append(opts, registry.Logger(logger))
 			}
@@ -15,6 +15,11 @@
 package registry
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
 	"net/http/httptest"
 
 	ggcrtest "github.com/google/go-containerregistry/internal/httptest"
@@ -27,3 +32,92 @@ import (
 func TLS(domain string) (*httptest.Server, error) {
 	return ggcrtest.NewTLSServer(domain, New())
 }
+
+// Server is a registry started by ListenAndServe or ListenAndServeTLS.
+type Server struct {
+	// Addr is the address the server listens on, e.g. "127.0.0.1:49152".
+	Addr string
+
+	// URL is the base URL of the server, e.g. "https://127.0.0.1:49152".
+	URL string
+
+	// CertPool trusts the server's self-signed certificate, or is nil if the
+	// server doesn't use TLS.
+	CertPool *x509.CertPool
+
+	srv *http.Server
+}
+
+// ListenAndServe starts serving handler, or a registry from New if handler
+// is nil, over plain HTTP on addr, or on a random port of 127.0.0.1 if addr
+// is empty. Unlike http.ListenAndServe, it returns once the server is
+// listening, leaving it serving in the background until Close is called.
+func ListenAndServe(addr string, handler http.Handler) (*Server, error) {
+	return listenAndServe(addr, handler, nil)
+}
+
+// ListenAndServeTLS is like ListenAndServe, but serves HTTPS, with a
+// certificate generated for the loopback addresses and hosts, which may be
+// domains or IP addresses. The certificate is self-signed: clients have to
+// trust CertPool, e.g. by using Transport.
+//
+// References to loopback addresses use plain HTTP by default (see
+// name.Registry.Scheme), so refer to the server by one of hosts, e.g.
+// "registry.example.com/repo", and connect with Transport, which sends
+// requests for every host to the server.
+func ListenAndServeTLS(addr string, handler http.Handler, hosts ...string) (*Server, error) {
+	cert, err := ggcrtest.Certificate(hosts...)
+	if err != nil {
+		return nil, err
+	}
+	return listenAndServe(addr, handler, &cert)
+}
+
+func listenAndServe(addr string, handler http.Handler, cert *tls.Certificate) (*Server, error) {
+	if addr == "" {
+		addr = "127.0.0.1:0"
+	}
+	if handler == nil {
+		handler = New()
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Addr: l.Addr().String(),
+		URL:  "http://" + l.Addr().String(),
+		srv:  &http.Server{Handler: handler},
+	}
+	if cert != nil {
+		s.URL = "https://" + s.Addr
+		s.CertPool = x509.NewCertPool()
+		s.CertPool.AddCert(cert.Leaf)
+		s.srv.TLSConfig = &tls.Config{Certificates: []tls.Certificate{*cert}}
+		l = tls.NewListener(l, s.srv.TLSConfig)
+	}
+	// Serve only returns once Close is called.
+	go s.srv.Serve(l)
+	return s, nil
+}
+
+// Transport returns an http.Transport that sends every request to the
+// server, whatever its host, trusting the server's certificate.
+func (s *Server) Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = nil
+	t.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, s.Addr)
+	}
+	if s.CertPool != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: s.CertPool}
+	}
+	return t
+}
+
+// Close stops the server, closing its listener and connections.
+func (s *Server) Close() error {
+	return s.srv.Close()
+}
@@ -15,6 +15,9 @@
 package registry_test
 
 import (
+	"crypto/tls"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -47,3 +50,71 @@ func TestTLS(t *testing.T) {
 		t.Fatalf("Unable to write image to remote: %s", err)
 	}
 }
+
+func TestListenAndServeTLS(t *testing.T) {
+	s, err := registry.ListenAndServeTLS("", nil, "registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if !strings.HasPrefix(s.URL, "https://127.0.0.1:") {
+		t.Errorf("URL = %q, want https on a random port of 127.0.0.1", s.URL)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference("registry.example.com/foo:bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img, remote.WithTransport(s.Transport())); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	// The certificate is also valid for the server's address.
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: s.CertPool}}}
+	resp, err := client.Get(s.URL + "/v2/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("GET /v2/ = %s", resp.Status)
+	}
+
+	// Clients that don't trust it fail.
+	if _, err := http.Get(s.URL + "/v2/"); err == nil {
+		t.Error("GET with the default client succeeded")
+	}
+}
+
+func TestListenAndServe(t *testing.T) {
+	s, err := registry.ListenAndServe("", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.CertPool != nil {
+		t.Error("CertPool is set without TLS")
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(s.Addr + "/foo:bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := http.Get(s.URL + "/v2/"); err == nil {
+		t.Error("GET after Close succeeded")
+	}
+}
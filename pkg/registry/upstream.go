@@ -0,0 +1,253 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Upstream is a registry to pull manifests and blobs from when the registry
+// doesn't have them itself, so that it can stand in for a pull-through proxy
+// or mirror in tests. See WithUpstream.
+type Upstream struct {
+	// URL is the scheme and host of the upstream registry, e.g.
+	// "https://registry.example.com".
+	URL string
+
+	// Transport makes the requests to the upstream. It defaults to
+	// http.DefaultTransport.
+	Transport http.RoundTripper
+
+	// Auth says how requests for each repository are authenticated with the
+	// upstream. Keys are repository names, or prefixes ending in "/" such as
+	// "private/", and the longest match wins; "" matches every repository.
+	// Requests for repositories with no match are sent anonymously.
+	Auth map[string]UpstreamAuth
+}
+
+// upstream is an Upstream with the transports it has set up, by repository.
+type upstream struct {
+	Upstream
+
+	mu         sync.Mutex
+	transports map[string]http.RoundTripper
+}
+
+// UpstreamAuth is how requests for a repository are authenticated with the
+// upstream.
+type UpstreamAuth struct {
+	// Passthrough forwards the client's Authorization header to the
+	// upstream, and the upstream's challenges back to the client, so that
+	// the client authenticates with the upstream itself.
+	Passthrough bool
+
+	// Auth authenticates with the upstream in place of the client, if
+	// Passthrough isn't set, whether it uses basic auth or bearer tokens.
+	// It defaults to authn.Anonymous.
+	Auth authn.Authenticator
+}
+
+// WithUpstream makes the registry fetch manifests and blobs that it doesn't
+// have from u, without storing them. Pushes, tag listing and everything
+// else are still served locally.
+//
+// Clients authenticate once per registry, not per repository, so if any
+// repository uses UpstreamAuth.Passthrough, requests to /v2/ without an
+// Authorization header are forwarded to the upstream too, and its challenge
+// relayed, which tells clients how to get credentials for it.
+func WithUpstream(u Upstream) Option {
+	return func(r *registry) {
+		if u.Transport == nil {
+			u.Transport = http.DefaultTransport
+		}
+		r.upstream = &upstream{Upstream: u}
+	}
+}
+
+// auth returns how requests for repo are authenticated with the upstream.
+func (u *Upstream) auth(repo string) UpstreamAuth {
+	best, auth := -1, UpstreamAuth{}
+	for k, a := range u.Auth {
+		if k != repo && k != "" && !(strings.HasSuffix(k, "/") && strings.HasPrefix(repo, k)) {
+			continue
+		}
+		if len(k) > best {
+			best, auth = len(k), a
+		}
+	}
+	return auth
+}
+
+// passthrough reports whether any repository forwards the client's
+// credentials.
+func (u *Upstream) passthrough() bool {
+	for _, a := range u.Auth {
+		if a.Passthrough {
+			return true
+		}
+	}
+	return false
+}
+
+// transport returns the transport to pull repo from the upstream with auth,
+// setting it up the first time.
+func (u *upstream) transport(ctx context.Context, repo string, auth authn.Authenticator) (http.RoundTripper, error) {
+	u.mu.Lock()
+	t, ok := u.transports[repo]
+	u.mu.Unlock()
+	if ok {
+		return t, nil
+	}
+
+	base, err := url.Parse(u.URL)
+	if err != nil {
+		return nil, err
+	}
+	var opts []name.Option
+	if base.Scheme == "http" {
+		opts = append(opts, name.Insecure)
+	}
+	r, err := name.NewRepository(base.Host+"/"+repo, opts...)
+	if err != nil {
+		return nil, err
+	}
+	t, err = transport.NewWithContext(ctx, r.Registry, auth, u.Transport, []string{r.Scope(transport.PullScope)})
+	if err != nil {
+		return nil, err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.transports == nil {
+		u.transports = map[string]http.RoundTripper{}
+	}
+	u.transports[repo] = t
+	return t, nil
+}
+
+// forwards reports whether req is for something the upstream should serve:
+// a pull of a manifest or blob that the registry doesn't have, or, for
+// passthrough, an unauthenticated ping.
+func (r *registry) forwards(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	if req.URL.Path == "/v2/" || req.URL.Path == "/v2" {
+		return req.Header.Get("Authorization") == "" && r.upstream.passthrough()
+	}
+	repo, target, ok := pullTarget(req)
+	if !ok {
+		return false
+	}
+	if isBlob(req) {
+		h, err := v1.NewHash(target)
+		if err != nil {
+			return false
+		}
+		ok, err := r.blobs.exists(req.Context(), req.URL.Host+repo, h)
+		return err == nil && !ok
+	}
+	if isManifest(req) {
+		r.manifests.lock.RLock()
+		defer r.manifests.lock.RUnlock()
+		_, ok := r.manifests.manifests[repo][target]
+		return !ok
+	}
+	return false
+}
+
+// pullTarget returns the repository and the tag or digest that req, a
+// request for a manifest or blob, is for.
+func pullTarget(req *http.Request) (repo, target string, ok bool) {
+	elem := strings.Split(strings.TrimSuffix(req.URL.Path, "/"), "/")[1:]
+	if len(elem) < 4 || (elem[len(elem)-2] != "manifests" && elem[len(elem)-2] != "blobs") {
+		return "", "", false
+	}
+	return strings.Join(elem[1:len(elem)-2], "/"), elem[len(elem)-1], true
+}
+
+// forward serves req from the upstream, relaying its response as is.
+func (r *registry) forward(resp http.ResponseWriter, req *http.Request) *regError {
+	u, err := url.Parse(r.upstream.URL)
+	if err != nil {
+		return regErrInternal(err)
+	}
+	u = u.ResolveReference(&url.URL{Path: req.URL.Path, RawQuery: req.URL.RawQuery})
+	out, err := http.NewRequestWithContext(req.Context(), req.Method, u.String(), nil)
+	if err != nil {
+		return regErrInternal(err)
+	}
+	for _, k := range []string{"Accept", "Range", "If-None-Match"} {
+		if v := req.Header.Values(k); len(v) > 0 {
+			out.Header[k] = v
+		}
+	}
+	repo, _, ok := pullTarget(req)
+	auth := r.upstream.auth(repo)
+	t := r.upstream.Transport
+	if !ok || auth.Passthrough {
+		// Pings only reach the upstream for passthrough.
+		if v := req.Header.Get("Authorization"); v != "" {
+			out.Header.Set("Authorization", v)
+		}
+	} else {
+		if auth.Auth == nil {
+			auth.Auth = authn.Anonymous
+		}
+		if t, err = r.upstream.transport(req.Context(), repo, auth.Auth); err != nil {
+			return &regError{
+				Status:  http.StatusBadGateway,
+				Code:    "UNKNOWN",
+				Message: fmt.Sprintf("upstream: %v", err),
+			}
+		}
+	}
+
+	// Redirects, e.g. to blob storage, are relayed for the client to follow.
+	res, err := t.RoundTrip(out)
+	if err != nil {
+		return &regError{
+			Status:  http.StatusBadGateway,
+			Code:    "UNKNOWN",
+			Message: fmt.Sprintf("upstream: %v", err),
+		}
+	}
+	defer res.Body.Close()
+	for k, v := range res.Header {
+		resp.Header()[k] = v
+	}
+	if loc, err := res.Location(); err == nil {
+		resp.Header().Set("Location", loc.String())
+	}
+	if res.StatusCode == http.StatusUnauthorized && ok && !auth.Passthrough {
+		// The client can't answer a challenge for credentials it never
+		// sends upstream.
+		resp.Header().Del("WWW-Authenticate")
+	}
+	resp.WriteHeader(res.StatusCode)
+	io.Copy(resp, res.Body)
+	return nil
+}
@@ -0,0 +1,260 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func TestUpstream(t *testing.T) {
+	user := &authn.Basic{Username: "user", Password: "pass"}
+	quiet := registry.Logger(log.New(io.Discard, "", 0))
+
+	// The upstream only answers to user.
+	reg := registry.New(quiet)
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, ok := r.BasicAuth(); !ok || u != user.Username || p != user.Password {
+			w.Header().Set("WWW-Authenticate", `Basic realm="upstream"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer up.Close()
+	upURL, err := url.Parse(up.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range []string{"public/app", "private/app"} {
+		ref, err := name.ParseReference(upURL.Host + "/" + repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img, remote.WithAuth(user)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		desc string
+		auth map[string]registry.UpstreamAuth
+		repo string
+		with authn.Authenticator
+		// wantStatus is the status pulling fails with, or 0.
+		wantStatus int
+	}{{
+		desc: "passthrough",
+		auth: map[string]registry.UpstreamAuth{"": {Passthrough: true}},
+		repo: "public/app",
+		with: user,
+	}, {
+		desc:       "passthrough without credentials",
+		auth:       map[string]registry.UpstreamAuth{"": {Passthrough: true}},
+		repo:       "public/app",
+		with:       authn.Anonymous,
+		wantStatus: http.StatusUnauthorized,
+	}, {
+		desc: "substituted",
+		auth: map[string]registry.UpstreamAuth{"private/": {Auth: user}},
+		repo: "private/app",
+		with: authn.Anonymous,
+	}, {
+		desc:       "unmatched repository is anonymous",
+		auth:       map[string]registry.UpstreamAuth{"private/": {Auth: user}},
+		repo:       "public/app",
+		with:       authn.Anonymous,
+		wantStatus: http.StatusUnauthorized,
+	}, {
+		desc: "longest prefix wins",
+		auth: map[string]registry.UpstreamAuth{
+			"":            {Auth: &authn.Basic{Username: "nope", Password: "nope"}},
+			"private/app": {Auth: user},
+		},
+		repo: "private/app",
+		with: authn.Anonymous,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			proxy := httptest.NewServer(registry.New(quiet, registry.WithUpstream(registry.Upstream{URL: up.URL, Auth: tc.auth})))
+			defer proxy.Close()
+			u, err := url.Parse(proxy.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := name.ParseReference(u.Host + "/" + tc.repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := remote.Image(ref, remote.WithAuth(tc.with))
+			if err == nil {
+				err = validate.Image(got)
+			}
+			var terr *transport.Error
+			switch {
+			case tc.wantStatus == 0 && err != nil:
+				t.Fatalf("pulling through proxy: %v", err)
+			case tc.wantStatus != 0 && !errors.As(err, &terr):
+				t.Fatalf("pulling through proxy: got %v, want status %d", err, tc.wantStatus)
+			case tc.wantStatus != 0 && terr.StatusCode != tc.wantStatus:
+				t.Fatalf("pulling through proxy: got status %d, want %d", terr.StatusCode, tc.wantStatus)
+			}
+		})
+	}
+}
+
+func TestUpstreamPrefersLocal(t *testing.T) {
+	quiet := registry.Logger(log.New(io.Discard, "", 0))
+	reg := registry.New(quiet)
+	var requests atomic.Int32
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		reg.ServeHTTP(w, r)
+	}))
+	defer up.Close()
+	proxy := httptest.NewServer(registry.New(quiet, registry.WithUpstream(registry.Upstream{URL: up.URL})))
+	defer proxy.Close()
+	u, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/local/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	// Pushing asks the upstream for the blobs, but pulling shouldn't.
+	requests.Store(0)
+	got, err := remote.Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Fatal(err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("pulling made %d upstream requests, want 0", n)
+	}
+}
+
+func TestUpstreamBearer(t *testing.T) {
+	user := &authn.Basic{Username: "user", Password: "pass"}
+	quiet := registry.Logger(log.New(io.Discard, "", 0))
+
+	// The upstream wants a token for each repository, from its token
+	// server, which only answers to user.
+	reg := registry.New(quiet)
+	var realm string
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if u, p, ok := r.BasicAuth(); !ok || u != user.Username || p != user.Password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token": %q}`, r.URL.Query().Get("scope"))
+			return
+		}
+		if repo, _, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v2/"), "/manifests/"); ok || strings.Contains(r.URL.Path, "/blobs/") {
+			if !ok {
+				repo, _, _ = strings.Cut(strings.TrimPrefix(r.URL.Path, "/v2/"), "/blobs/")
+			}
+			if r.Header.Get("Authorization") != "Bearer repository:"+repo+":pull" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm=%q,scope="repository:%s:pull"`, realm, repo))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		} else if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm=%q`, realm))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer up.Close()
+	realm = up.URL + "/token"
+
+	// Seed the upstream directly.
+	direct := httptest.NewServer(reg)
+	defer direct.Close()
+	directURL, err := url.Parse(direct.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repos := []string{"team/app", "team/other"}
+	for _, repo := range repos {
+		ref, err := name.ParseReference(directURL.Host + "/" + repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proxy := httptest.NewServer(registry.New(quiet, registry.WithUpstream(registry.Upstream{
+		URL:  up.URL,
+		Auth: map[string]registry.UpstreamAuth{"": {Auth: user}},
+	})))
+	defer proxy.Close()
+	u, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, repo := range repos {
+		ref, err := name.ParseReference(u.Host + "/" + repo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := remote.Image(ref)
+		if err != nil {
+			t.Fatalf("pulling %s through proxy: %v", repo, err)
+		}
+		if err := validate.Image(got); err != nil {
+			t.Fatalf("pulling %s through proxy: %v", repo, err)
+		}
+	}
+}
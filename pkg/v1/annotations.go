@@ -0,0 +1,117 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"fmt"
+	"time"
+
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Annotations reads the well-known annotations of a Descriptor, Manifest or
+// IndexManifest, whose keys are defined by the OCI image spec in
+// github.com/opencontainers/image-spec/specs-go/v1, e.g.
+//
+//	created, err := v1.Annotations(m.Annotations).Created()
+//
+// +k8s:deepcopy-gen=false
+type Annotations map[string]string
+
+// RefName returns the org.opencontainers.image.ref.name annotation, or "".
+func (a Annotations) RefName() string {
+	return a[imagespec.AnnotationRefName]
+}
+
+// Created returns the time in the org.opencontainers.image.created
+// annotation, or the zero time if there isn't one.
+func (a Annotations) Created() (time.Time, error) {
+	s, ok := a[imagespec.AnnotationCreated]
+	if !ok {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing %s annotation: %w", imagespec.AnnotationCreated, err)
+	}
+	return t, nil
+}
+
+// Source returns the org.opencontainers.image.source annotation, or "".
+func (a Annotations) Source() string {
+	return a[imagespec.AnnotationSource]
+}
+
+// Revision returns the org.opencontainers.image.revision annotation, or "".
+func (a Annotations) Revision() string {
+	return a[imagespec.AnnotationRevision]
+}
+
+// BaseImage returns the org.opencontainers.image.base.name and
+// org.opencontainers.image.base.digest annotations. Either may be missing:
+// the name is then "", and the digest the zero Hash.
+func (a Annotations) BaseImage() (string, Hash, error) {
+	var digest Hash
+	if s, ok := a[imagespec.AnnotationBaseImageDigest]; ok {
+		var err error
+		if digest, err = NewHash(s); err != nil {
+			return "", Hash{}, fmt.Errorf("parsing %s annotation: %w", imagespec.AnnotationBaseImageDigest, err)
+		}
+	}
+	return a[imagespec.AnnotationBaseImageName], digest, nil
+}
+
+// setAnnotation sets key to value in *anns, creating the map if need be.
+func setAnnotation(anns *map[string]string, key, value string) {
+	if *anns == nil {
+		*anns = map[string]string{}
+	}
+	(*anns)[key] = value
+}
+
+// SetAnnotation sets the annotation key to value.
+func (d *Descriptor) SetAnnotation(key, value string) {
+	setAnnotation(&d.Annotations, key, value)
+}
+
+// SetAnnotation sets the annotation key to value.
+func (m *Manifest) SetAnnotation(key, value string) {
+	setAnnotation(&m.Annotations, key, value)
+}
+
+// SetAnnotation sets the annotation key to value.
+func (m *IndexManifest) SetAnnotation(key, value string) {
+	setAnnotation(&m.Annotations, key, value)
+}
+
+// SetCreated sets the org.opencontainers.image.created annotation to t.
+func (m *Manifest) SetCreated(t time.Time) {
+	m.SetAnnotation(imagespec.AnnotationCreated, t.UTC().Format(time.RFC3339))
+}
+
+// SetCreated sets the org.opencontainers.image.created annotation to t.
+func (m *IndexManifest) SetCreated(t time.Time) {
+	m.SetAnnotation(imagespec.AnnotationCreated, t.UTC().Format(time.RFC3339))
+}
+
+// SetBaseImage sets the org.opencontainers.image.base.name and
+// org.opencontainers.image.base.digest annotations. If name is "", only the
+// digest is set, e.g. for a base image referred to by digest.
+func (m *Manifest) SetBaseImage(name string, digest Hash) {
+	if name != "" {
+		m.SetAnnotation(imagespec.AnnotationBaseImageName, name)
+	}
+	m.SetAnnotation(imagespec.AnnotationBaseImageDigest, digest.String())
+}
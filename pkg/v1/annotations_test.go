@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestAnnotations(t *testing.T) {
+	digest, _, err := v1.SHA256(strings.NewReader("base"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var m v1.Manifest
+	m.SetCreated(created)
+	m.SetBaseImage("ubuntu:24.04", digest)
+	m.SetAnnotation(imagespec.AnnotationSource, "https://example.com/repo")
+	m.SetAnnotation(imagespec.AnnotationRevision, "abc123")
+
+	if diff := cmp.Diff(map[string]string{
+		"org.opencontainers.image.created":     "2026-01-02T03:04:05Z",
+		"org.opencontainers.image.base.name":   "ubuntu:24.04",
+		"org.opencontainers.image.base.digest": digest.String(),
+		"org.opencontainers.image.source":      "https://example.com/repo",
+		"org.opencontainers.image.revision":    "abc123",
+	}, m.Annotations); diff != "" {
+		t.Errorf("Annotations (-want +got): %s", diff)
+	}
+
+	a := v1.Annotations(m.Annotations)
+	if got, err := a.Created(); err != nil || !got.Equal(created) {
+		t.Errorf("Created() = %v, %v; want %v", got, err, created)
+	}
+	if name, h, err := a.BaseImage(); err != nil || name != "ubuntu:24.04" || h != digest {
+		t.Errorf("BaseImage() = %q, %v, %v; want ubuntu:24.04, %v", name, h, err, digest)
+	}
+	if got := a.Source(); got != "https://example.com/repo" {
+		t.Errorf("Source() = %q", got)
+	}
+	if got := a.Revision(); got != "abc123" {
+		t.Errorf("Revision() = %q", got)
+	}
+
+	var d v1.Descriptor
+	d.SetAnnotation(imagespec.AnnotationRefName, "latest")
+	if got := v1.Annotations(d.Annotations).RefName(); got != "latest" {
+		t.Errorf("RefName() = %q, want latest", got)
+	}
+
+	// Missing annotations are zero values, malformed ones errors.
+	var none v1.Annotations
+	if got, err := none.Created(); err != nil || !got.IsZero() {
+		t.Errorf("Created() of nothing = %v, %v; want the zero time", got, err)
+	}
+	if name, h, err := none.BaseImage(); err != nil || name != "" || h != (v1.Hash{}) {
+		t.Errorf("BaseImage() of nothing = %q, %v, %v", name, h, err)
+	}
+	bad := v1.Annotations{imagespec.AnnotationCreated: "yesterday", imagespec.AnnotationBaseImageDigest: "nope"}
+	if _, err := bad.Created(); err == nil {
+		t.Error("Created() of a malformed time succeeded")
+	}
+	if _, _, err := bad.BaseImage(); err == nil {
+		t.Error("BaseImage() of a malformed digest succeeded")
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/google/go-containerregistry/pkg/logs"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
 
@@ -131,6 +132,10 @@ func (l *lazyLayer) DiffID() (v1.Hash, error)            { return l.inner.DiffID
 func (l *lazyLayer) Digest() (v1.Hash, error)            { return l.inner.Digest() }
 func (l *lazyLayer) MediaType() (types.MediaType, error) { return l.inner.MediaType() }
 
+// Exists implements partial.WithExists, so that checking the layer doesn't
+// pull it into the cache.
+func (l *lazyLayer) Exists() (bool, error) { return partial.Exists(l.inner) }
+
 func (i *image) LayerByDigest(h v1.Hash) (v1.Layer, error) {
 	l, err := i.c.Get(h)
 	if errors.Is(err, ErrNotFound) {
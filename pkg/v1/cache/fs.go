@@ -23,6 +23,7 @@ import (
 	"runtime"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 )
 
@@ -80,6 +81,12 @@ func (l *layer) Compressed() (io.ReadCloser, error) {
 	}, nil
 }
 
+// Exists implements partial.WithExists, checking the underlying layer
+// without opening it, which would start caching it.
+func (l *layer) Exists() (bool, error) {
+	return partial.Exists(l.Layer)
+}
+
 func (l *layer) Uncompressed() (io.ReadCloser, error) {
 	f, err := l.create(l.diffID)
 	if err != nil {
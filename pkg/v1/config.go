@@ -15,8 +15,13 @@
 package v1
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -39,6 +44,125 @@ type ConfigFile struct {
 	OSVersion     string    `json:"os.version,omitempty"`
 	Variant       string    `json:"variant,omitempty"`
 	OSFeatures    []string  `json:"os.features,omitempty"`
+
+	// Extensions holds the top-level fields of the config file that aren't
+	// otherwise described here, by name, so that they survive being parsed
+	// and marshaled again, e.g. by mutate. See Extension and SetExtension.
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+// configFile is ConfigFile without its JSON methods.
+// +k8s:deepcopy-gen=false
+type configFile ConfigFile
+
+// configFileFields are the lowercased JSON names of the fields of
+// ConfigFile, which encoding/json matches case-insensitively.
+var configFileFields = func() map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(ConfigFile{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[strings.ToLower(name)] = true
+		}
+	}
+	return fields
+}()
+
+// MarshalJSON implements json.Marshaler, adding Extensions after the other
+// fields, sorted by name.
+func (cf ConfigFile) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(configFile(cf))
+	if err != nil || len(cf.Extensions) == 0 {
+		return b, err
+	}
+
+	keys := make([]string, 0, len(cf.Extensions))
+	for k := range cf.Extensions {
+		// Fields of ConfigFile always win, as they would when parsing.
+		if !configFileFields[strings.ToLower(k)] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(bytes.TrimSuffix(b, []byte("}")))
+	for _, k := range keys {
+		kb, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vb, err := json.Marshal(cf.Extensions[k])
+		if err != nil {
+			return nil, fmt.Errorf("marshaling extension %q: %w", k, err)
+		}
+		buf.WriteByte(',')
+		buf.Write(kb)
+		buf.WriteByte(':')
+		buf.Write(vb)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, keeping any unknown fields in
+// Extensions.
+func (cf *ConfigFile) UnmarshalJSON(b []byte) error {
+	var known configFile
+	if err := json.Unmarshal(b, &known); err != nil {
+		return err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+	*cf = ConfigFile(known)
+	cf.Extensions = nil
+	for k, v := range fields {
+		if configFileFields[strings.ToLower(k)] {
+			continue
+		}
+		if cf.Extensions == nil {
+			cf.Extensions = map[string]json.RawMessage{}
+		}
+		cf.Extensions[k] = v
+	}
+	return nil
+}
+
+// Extension unmarshals the extension field named key into v, reporting
+// whether the config file has it.
+func (cf *ConfigFile) Extension(key string, v any) (bool, error) {
+	raw, ok := cf.Extensions[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return true, fmt.Errorf("unmarshaling extension %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// SetExtension sets the extension field named key to v, marshaled as JSON,
+// or removes it if v is nil. It is an error for key to name one of the
+// fields of ConfigFile.
+func (cf *ConfigFile) SetExtension(key string, v any) error {
+	if configFileFields[strings.ToLower(key)] {
+		return fmt.Errorf("%q is not an extension field", key)
+	}
+	if v == nil {
+		delete(cf.Extensions, key)
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshaling extension %q: %w", key, err)
+	}
+	if cf.Extensions == nil {
+		cf.Extensions = map[string]json.RawMessage{}
+	}
+	cf.Extensions[key] = b
+	return nil
 }
 
 // Platform attempts to generates a Platform from the ConfigFile fields.
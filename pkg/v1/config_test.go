@@ -15,6 +15,7 @@
 package v1
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -36,3 +37,60 @@ func TestParseConfig(t *testing.T) {
 		t.Errorf("expected error, got: %v", got)
 	}
 }
+
+func TestConfigExtensions(t *testing.T) {
+	// encoding/json matches names case-insensitively, so "OS" is parsed into
+	// OS rather than kept as an extension.
+	raw := `{"architecture":"amd64","os":"windows","rootfs":{"type":"layers","diff_ids":null},"config":{},"container_config":{"Cmd":["sh"]},"moby.buildkit.buildinfo.v1":"e30=","OS":"linux"}`
+	cf, err := ParseConfigFile(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(cf.Extensions), 2; got != want {
+		t.Fatalf("len(Extensions) = %d, want %d: %v", got, want, cf.Extensions)
+	}
+
+	var cc Config
+	if ok, err := cf.Extension("container_config", &cc); err != nil || !ok {
+		t.Fatalf("Extension(container_config) = %t, %v", ok, err)
+	}
+	if diff := cmp.Diff([]string{"sh"}, cc.Cmd); diff != "" {
+		t.Errorf("container_config Cmd (-want +got) %s", diff)
+	}
+	if ok, err := cf.Extension("missing", &cc); err != nil || ok {
+		t.Errorf("Extension(missing) = %t, %v, want false", ok, err)
+	}
+
+	if err := cf.SetExtension("os", "windows"); err == nil {
+		t.Error("SetExtension(os) succeeded, want error")
+	}
+	if err := cf.SetExtension("moby.buildkit.buildinfo.v1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := cf.SetExtension("com.example", map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copies don't share extensions.
+	cp := cf.DeepCopy()
+	cp.Extensions["container_config"][0] = '['
+	cp.Extensions["other"] = json.RawMessage(`1`)
+
+	b, err := json.Marshal(cf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"architecture":"amd64","created":"0001-01-01T00:00:00Z","os":"linux","rootfs":{"type":"layers","diff_ids":null},"config":{},"com.example":{"a":1},"container_config":{"Cmd":["sh"]}}`
+	if got := string(b); got != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	// Configs without extensions marshal as they always have.
+	b, err = json.Marshal(ConfigFile{Architecture: "amd64"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"architecture":"amd64","created":"0001-01-01T00:00:00Z","os":"","rootfs":{"type":"","diff_ids":null},"config":{}}`; got != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+}
@@ -48,6 +48,7 @@ type imageOpener struct {
 
 	buffered bool
 	client   Client
+	known    []v1.Layer
 
 	once  sync.Once
 	bytes []byte
@@ -99,6 +100,7 @@ func Image(ref name.Reference, options ...Option) (v1.Image, error) {
 		buffered: o.buffered,
 		client:   o.client,
 		ctx:      o.ctx,
+		known:    o.known,
 	}
 
 	img := &image{
@@ -121,6 +123,10 @@ func (i *image) initialize() error {
 	// Don't re-initialize tarball if already initialized.
 	if i.tarballImage == nil {
 		i.once.Do(func() {
+			if len(i.opener.known) > 0 {
+				i.tarballImage, i.err = i.opener.sharedImage()
+				return
+			}
 			i.tarballImage, i.err = tarball.Image(i.opener.opener(), nil)
 		})
 	}
@@ -21,6 +21,8 @@ import (
 	"github.com/docker/docker/api/types"
 	api "github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 )
 
 // ImageOption is an alias for Option.
@@ -34,6 +36,7 @@ type options struct {
 	ctx      context.Context
 	client   Client
 	buffered bool
+	known    []v1.Layer
 }
 
 var defaultClient = func() (Client, error) {
@@ -75,6 +78,19 @@ func WithUnbufferedOpener() Option {
 	}
 }
 
+// WithKnownLayers tells Image that the caller already has the given layers.
+//
+// When the daemon's export contains a layer whose DiffID matches one of these,
+// its contents are discarded as they stream past and the returned image uses
+// the caller's layer instead, so only the remaining layers are held in memory.
+// The Docker Engine API has no way to exclude layers from an export, so the
+// daemon still produces the full stream once.
+func WithKnownLayers(layers ...v1.Layer) Option {
+	return func(o *options) {
+		o.known = append(o.known, layers...)
+	}
+}
+
 // WithClient is a functional option to allow injecting a docker client.
 //
 // By default, github.com/docker/docker/client.FromEnv is used.
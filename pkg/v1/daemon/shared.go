@@ -0,0 +1,339 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Layer reads the layer with the given DiffID from ref in the Docker daemon.
+//
+// The Docker Engine API cannot export a single layer, so this reads the
+// image's export only until the requested layer has been seen, and holds
+// just that layer's contents in memory.
+func Layer(ref name.Reference, diffID v1.Hash, options ...Option) (v1.Layer, error) {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := o.client.ImageSave(o.ctx, []string{ref.Name()})
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	entries, err := scanSave(rc, func(h v1.Hash) (bool, bool) {
+		return h == diffID, h == diffID
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.digest == diffID && e.contents != nil {
+			return tarball.LayerFromOpener(e.opener())
+		}
+	}
+	return nil, fmt.Errorf("layer %s not found in %s", diffID, ref)
+}
+
+// sharedImage reads the image from a single save, replacing any layer the
+// caller already has (see WithKnownLayers) with the caller's copy.
+func (i *imageOpener) sharedImage() (v1.Image, error) {
+	known := make(map[v1.Hash]v1.Layer, len(i.known))
+	for _, l := range i.known {
+		h, err := l.DiffID()
+		if err != nil {
+			return nil, err
+		}
+		known[h] = l
+	}
+
+	rc, err := i.saveImage()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	entries, err := scanSave(rc, func(h v1.Hash) (bool, bool) {
+		_, ok := known[h]
+		return !ok, false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mf, ok := entries["manifest.json"]
+	if !ok || mf.contents == nil {
+		return nil, errors.New("save output is missing manifest.json")
+	}
+	var m tarball.Manifest
+	if err := json.Unmarshal(mf.contents, &m); err != nil {
+		return nil, err
+	}
+	if len(m) != 1 {
+		return nil, fmt.Errorf("save output contains %d images, expected 1", len(m))
+	}
+	desc := m[0]
+
+	cfg := entries[path.Clean(desc.Config)].contents
+	if cfg == nil {
+		return nil, fmt.Errorf("save output is missing config %s", desc.Config)
+	}
+	cf, err := v1.ParseConfigFile(bytes.NewReader(cfg))
+	if err != nil {
+		return nil, err
+	}
+	if len(cf.RootFS.DiffIDs) != len(desc.Layers) {
+		return nil, fmt.Errorf("config has %d diff ids but manifest.json lists %d layers", len(cf.RootFS.DiffIDs), len(desc.Layers))
+	}
+
+	layers := make([]v1.Layer, len(desc.Layers))
+	for j, diffID := range cf.RootFS.DiffIDs {
+		if l, ok := known[diffID]; ok {
+			layers[j] = l
+			continue
+		}
+		e := entries[path.Clean(desc.Layers[j])]
+		if e.contents == nil {
+			return nil, fmt.Errorf("save output is missing layer %s", desc.Layers[j])
+		}
+		if layers[j], err = tarball.LayerFromOpener(e.opener()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &sharedImage{config: cfg, layers: layers}, nil
+}
+
+// saveEntry is a regular file from a "docker save" stream.
+type saveEntry struct {
+	digest v1.Hash
+	// contents is nil if the file was discarded.
+	contents []byte
+}
+
+func (e saveEntry) opener() tarball.Opener {
+	return func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(e.contents)), nil
+	}
+}
+
+// scanSave walks a "docker save" stream, calling keep with the sha256 of each
+// regular file to decide whether to retain its contents and whether to stop
+// reading. Files under blobs/sha256/ are named by their digest, so ones that
+// are not retained are skipped without being read. Symlinks resolve to the
+// entry they point at.
+func scanSave(r io.Reader, keep func(v1.Hash) (retain, done bool)) (map[string]saveEntry, error) {
+	entries := map[string]saveEntry{}
+	links := map[string]string{}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := path.Clean(hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeSymlink:
+			links[name] = path.Join(path.Dir(name), hdr.Linkname)
+			continue
+		case tar.TypeReg:
+		default:
+			continue
+		}
+
+		if h, ok := blobDigest(name); ok {
+			if retain, done := keep(h); !retain {
+				entries[name] = saveEntry{digest: h}
+				if done {
+					break
+				}
+				continue
+			}
+		}
+
+		hasher := crypto.SHA256.New()
+		b, err := io.ReadAll(io.TeeReader(tr, hasher))
+		if err != nil {
+			return nil, err
+		}
+		h := v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(hasher.Sum(nil))}
+		if want, ok := blobDigest(name); ok && want != h {
+			return nil, fmt.Errorf("%s has digest %s", name, h)
+		}
+
+		retain, done := keep(h)
+		if !retain {
+			b = nil
+		}
+		entries[name] = saveEntry{digest: h, contents: b}
+		if done {
+			break
+		}
+	}
+
+	for name, target := range links {
+		if e, ok := entries[target]; ok {
+			entries[name] = e
+		}
+	}
+	return entries, nil
+}
+
+// blobDigest returns the digest encoded in an OCI layout blob path.
+func blobDigest(name string) (v1.Hash, bool) {
+	hx, ok := strings.CutPrefix(name, "blobs/sha256/")
+	if !ok {
+		return v1.Hash{}, false
+	}
+	h, err := v1.NewHash("sha256:" + hx)
+	if err != nil {
+		return v1.Hash{}, false
+	}
+	return h, true
+}
+
+// sharedImage is a v1.Image assembled from a "docker save" stream in which
+// some layers were replaced by ones the caller already has.
+type sharedImage struct {
+	config []byte
+	layers []v1.Layer
+
+	once     sync.Once
+	manifest *v1.Manifest
+	err      error
+}
+
+var _ v1.Image = (*sharedImage)(nil)
+
+func (s *sharedImage) Layers() ([]v1.Layer, error) {
+	return append([]v1.Layer(nil), s.layers...), nil
+}
+
+func (s *sharedImage) MediaType() (types.MediaType, error) {
+	return types.DockerManifestSchema2, nil
+}
+
+func (s *sharedImage) Size() (int64, error) {
+	return partial.Size(s)
+}
+
+func (s *sharedImage) ConfigName() (v1.Hash, error) {
+	return partial.ConfigName(s)
+}
+
+func (s *sharedImage) ConfigFile() (*v1.ConfigFile, error) {
+	return partial.ConfigFile(s)
+}
+
+func (s *sharedImage) RawConfigFile() ([]byte, error) {
+	return s.config, nil
+}
+
+func (s *sharedImage) Digest() (v1.Hash, error) {
+	return partial.Digest(s)
+}
+
+func (s *sharedImage) Manifest() (*v1.Manifest, error) {
+	s.once.Do(func() {
+		s.manifest, s.err = s.computeManifest()
+	})
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.manifest.DeepCopy(), nil
+}
+
+func (s *sharedImage) computeManifest() (*v1.Manifest, error) {
+	cfgHash, cfgSize, err := v1.SHA256(bytes.NewReader(s.config))
+	if err != nil {
+		return nil, err
+	}
+
+	m := &v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.DockerManifestSchema2,
+		Config: v1.Descriptor{
+			MediaType: types.DockerConfigJSON,
+			Size:      cfgSize,
+			Digest:    cfgHash,
+		},
+		Layers: make([]v1.Descriptor, len(s.layers)),
+	}
+	for j, l := range s.layers {
+		desc, err := partial.Descriptor(l)
+		if err != nil {
+			return nil, err
+		}
+		if len(desc.Annotations) == 0 {
+			// Keep the manifest identical to its serialized form.
+			desc.Annotations = nil
+		}
+		m.Layers[j] = *desc
+	}
+	return m, nil
+}
+
+func (s *sharedImage) RawManifest() ([]byte, error) {
+	return partial.RawManifest(s)
+}
+
+func (s *sharedImage) LayerByDigest(h v1.Hash) (v1.Layer, error) {
+	for _, l := range s.layers {
+		d, err := l.Digest()
+		if err != nil {
+			return nil, err
+		}
+		if d == h {
+			return l, nil
+		}
+	}
+	return nil, fmt.Errorf("layer with digest %s not found", h)
+}
+
+func (s *sharedImage) LayerByDiffID(h v1.Hash) (v1.Layer, error) {
+	for _, l := range s.layers {
+		d, err := l.DiffID()
+		if err != nil {
+			return nil, err
+		}
+		if d == h {
+			return l, nil
+		}
+	}
+	return nil, fmt.Errorf("layer with diff id %s not found", h)
+}
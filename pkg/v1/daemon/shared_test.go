@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package daemon
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/compare"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func TestImageKnownLayers(t *testing.T) {
+	unrelated, err := random.Layer(1024, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := tarball.ImageFromPath(imagePath, nil)
+	if err != nil {
+		t.Fatalf("error loading test image: %v", err)
+	}
+	ls, err := want.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		known  []v1.Layer
+		shared bool
+	}{{
+		name:   "shared",
+		known:  ls,
+		shared: true,
+	}, {
+		name:  "unrelated",
+		known: []v1.Layer{unrelated},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &MockClient{path: imagePath, inspectResp: inspectResp}
+			got, err := Image(name.MustParseReference("unused"), WithClient(client), WithKnownLayers(tc.known...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := compare.Images(want, got); err != nil {
+				t.Errorf("compare.Images: %v", err)
+			}
+			if err := validate.Image(got); err != nil {
+				t.Errorf("validate.Image: %v", err)
+			}
+
+			gotLayers, err := got.Layers()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if shared := gotLayers[0] == ls[0]; shared != tc.shared {
+				t.Errorf("layer shared = %t, want %t", shared, tc.shared)
+			}
+		})
+	}
+}
+
+func TestLayer(t *testing.T) {
+	for _, p := range []string{imagePath, "../tarball/testdata/hello-world-v25.tar"} {
+		t.Run(p, func(t *testing.T) {
+			img, err := tarball.ImageFromPath(p, nil)
+			if err != nil {
+				t.Fatalf("error loading test image: %v", err)
+			}
+			cf, err := img.ConfigFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			diffID := cf.RootFS.DiffIDs[0]
+
+			ref := name.MustParseReference("unused")
+			l, err := Layer(ref, diffID, WithClient(&MockClient{path: p}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := l.DiffID(); err != nil {
+				t.Fatal(err)
+			} else if got != diffID {
+				t.Errorf("DiffID() = %s, want %s", got, diffID)
+			}
+			if err := validate.Layer(l); err != nil {
+				t.Errorf("validate.Layer: %v", err)
+			}
+
+			missing := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+			if _, err := Layer(ref, missing, WithClient(&MockClient{path: p})); err == nil || !strings.Contains(err.Error(), "not found") {
+				t.Errorf("Layer(missing) = %v, want not found", err)
+			}
+		})
+	}
+}
@@ -73,22 +73,22 @@ func (gk *googleKeychain) ResolveContext(ctx context.Context, target authn.Resou
 func resolve(ctx context.Context) authn.Authenticator {
 	auth, envErr := NewEnvAuthenticator(ctx)
 	if envErr == nil && auth != authn.Anonymous {
-		logs.Debug.Println("google.Keychain: using Application Default Credentials")
+		logs.DebugFor(logs.Auth).Println("google.Keychain: using Application Default Credentials")
 		return auth
 	}
 
 	auth, gErr := NewGcloudAuthenticator(ctx)
 	if gErr == nil && auth != authn.Anonymous {
-		logs.Debug.Println("google.Keychain: using gcloud fallback")
+		logs.DebugFor(logs.Auth).Println("google.Keychain: using gcloud fallback")
 		return auth
 	}
 
-	logs.Debug.Println("Failed to get any Google credentials, falling back to Anonymous")
+	logs.DebugFor(logs.Auth).Println("Failed to get any Google credentials, falling back to Anonymous")
 	if envErr != nil {
-		logs.Debug.Printf("Google env error: %v", envErr)
+		logs.DebugFor(logs.Auth).Printf("Google env error: %v", envErr)
 	}
 	if gErr != nil {
-		logs.Debug.Printf("gcloud error: %v", gErr)
+		logs.DebugFor(logs.Auth).Printf("gcloud error: %v", gErr)
 	}
 	return authn.Anonymous
 }
@@ -99,3 +99,9 @@ func isGoogle(host string) bool {
 		strings.HasSuffix(host, ".pkg.dev") ||
 		strings.HasSuffix(host, ".google.com")
 }
+
+// isArtifactRegistry reports whether host is an Artifact Registry endpoint,
+// e.g. us-docker.pkg.dev.
+func isArtifactRegistry(host string) bool {
+	return strings.HasSuffix(host, ".pkg.dev")
+}
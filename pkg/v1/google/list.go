@@ -36,6 +36,7 @@ type Option func(*lister) error
 
 type lister struct {
 	auth      authn.Authenticator
+	keychain  authn.Keychain
 	transport http.RoundTripper
 	repo      name.Repository
 	client    *http.Client
@@ -57,13 +58,22 @@ func newLister(repo name.Repository, options ...Option) (*lister, error) {
 		}
 	}
 
+	// Resolve the keychain now that we have the caller's context.
+	if l.keychain != nil {
+		auth, err := authn.Resolve(l.ctx, l.keychain, l.repo.Registry)
+		if err != nil {
+			return nil, err
+		}
+		l.auth = auth
+	}
+
 	// transport.Wrapper is a signal that consumers are opt-ing into providing their own transport without any additional wrapping.
 	// This is to allow consumers full control over the transports logic, such as providing retry logic.
 	if _, ok := l.transport.(*transport.Wrapper); !ok {
 		// Wrap the transport in something that logs requests and responses.
 		// It's expensive to generate the dumps, so skip it if we're writing
 		// to nothing.
-		if logs.Enabled(logs.Debug) {
+		if logs.Enabled(logs.DebugFor(logs.Transport)) {
 			l.transport = transport.NewLogger(l.transport)
 		}
 
@@ -101,6 +111,7 @@ func (l *lister) list(repo name.Repository) (*Tags, error) {
 		uri.RawQuery = "n=1000"
 	}
 
+	ar := isArtifactRegistry(repo.RegistryStr())
 	tags := Tags{}
 
 	// get responses until there is no next page
@@ -136,8 +147,20 @@ func (l *lister) list(repo name.Repository) (*Tags, error) {
 		}
 
 		if len(parsed.Manifests) != 0 || len(parsed.Children) != 0 {
-			// We're dealing with GCR, just return directly.
-			return &parsed, nil
+			if !ar {
+				// We're dealing with GCR, just return directly.
+				return &parsed, nil
+			}
+			// Artifact Registry paginates its extended responses, so
+			// accumulate each page.
+			tags.Name = parsed.Name
+			tags.Children = append(tags.Children, parsed.Children...)
+			if tags.Manifests == nil {
+				tags.Manifests = map[string]ManifestInfo{}
+			}
+			for digest, info := range parsed.Manifests {
+				tags.Manifests[digest] = info
+			}
 		}
 
 		// This isn't GCR, just append the tags and keep paginating.
@@ -151,7 +174,9 @@ func (l *lister) list(repo name.Repository) (*Tags, error) {
 		if uri == nil {
 			break
 		}
-		logs.Warn.Printf("saw non-google tag listing response, falling back to pagination")
+		if !ar {
+			logs.Warn.Printf("saw non-google tag listing response, falling back to pagination")
+		}
 	}
 
 	return &tags, nil
@@ -193,6 +218,27 @@ type rawManifestInfo struct {
 	Created   string   `json:"timeCreatedMs"`
 	Uploaded  string   `json:"timeUploadedMs"`
 	Tags      []string `json:"tag"`
+
+	// These are only set by Artifact Registry.
+	Updated     string            `json:"updateTime,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// arManifestInfo is the union of the shapes GCR and Artifact Registry use for
+// a manifest entry. Artifact Registry may send sizes and times as numbers
+// rather than strings, and reports times as RFC 3339 strings in the fields
+// its REST API uses.
+type arManifestInfo struct {
+	Size        json.Number       `json:"imageSizeBytes"`
+	MediaType   string            `json:"mediaType"`
+	Created     json.Number       `json:"timeCreatedMs"`
+	Uploaded    json.Number       `json:"timeUploadedMs"`
+	Tag         []string          `json:"tag"`
+	Tags        []string          `json:"tags"`
+	BuildTime   string            `json:"buildTime"`
+	UploadTime  string            `json:"uploadTime"`
+	UpdateTime  string            `json:"updateTime"`
+	Annotations map[string]string `json:"annotations"`
 }
 
 // ManifestInfo is a Manifests entry is the output of List and Walk.
@@ -202,6 +248,15 @@ type ManifestInfo struct {
 	Created   time.Time `json:"timeCreatedMs"`
 	Uploaded  time.Time `json:"timeUploadedMs"`
 	Tags      []string  `json:"tag"`
+
+	// Updated is when Artifact Registry last updated the manifest's metadata,
+	// e.g. its tags. It is zero for GCR.
+	Updated time.Time `json:"updateTime,omitempty"`
+
+	// Annotations are the manifest's annotations, as reported by Artifact
+	// Registry. These include build and source provenance, such as
+	// org.opencontainers.image.source. They are nil for GCR.
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 func fromUnixMs(ms int64) time.Time {
@@ -216,52 +271,72 @@ func toUnixMs(t time.Time) string {
 
 // MarshalJSON implements json.Marshaler
 func (m ManifestInfo) MarshalJSON() ([]byte, error) {
-	return json.Marshal(rawManifestInfo{
-		Size:      strconv.FormatUint(m.Size, 10),
-		MediaType: m.MediaType,
-		Created:   toUnixMs(m.Created),
-		Uploaded:  toUnixMs(m.Uploaded),
-		Tags:      m.Tags,
-	})
+	raw := rawManifestInfo{
+		Size:        strconv.FormatUint(m.Size, 10),
+		MediaType:   m.MediaType,
+		Created:     toUnixMs(m.Created),
+		Uploaded:    toUnixMs(m.Uploaded),
+		Tags:        m.Tags,
+		Annotations: m.Annotations,
+	}
+	if !m.Updated.IsZero() {
+		raw.Updated = m.Updated.UTC().Format(time.RFC3339Nano)
+	}
+	return json.Marshal(raw)
 }
 
 // UnmarshalJSON implements json.Unmarshaler
 func (m *ManifestInfo) UnmarshalJSON(data []byte) error {
-	raw := rawManifestInfo{}
+	raw := arManifestInfo{}
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return err
 	}
 
 	if raw.Size != "" {
-		size, err := strconv.ParseUint(raw.Size, 10, 64)
+		size, err := strconv.ParseUint(raw.Size.String(), 10, 64)
 		if err != nil {
 			return err
 		}
 		m.Size = size
 	}
 
-	if raw.Created != "" {
-		created, err := strconv.ParseInt(raw.Created, 10, 64)
-		if err != nil {
-			return err
-		}
-		m.Created = fromUnixMs(created)
+	var err error
+	if m.Created, err = parseTime(raw.Created, raw.BuildTime); err != nil {
+		return err
 	}
-
-	if raw.Uploaded != "" {
-		uploaded, err := strconv.ParseInt(raw.Uploaded, 10, 64)
-		if err != nil {
-			return err
-		}
-		m.Uploaded = fromUnixMs(uploaded)
+	if m.Uploaded, err = parseTime(raw.Uploaded, raw.UploadTime); err != nil {
+		return err
+	}
+	if m.Updated, err = parseTime("", raw.UpdateTime); err != nil {
+		return err
 	}
 
 	m.MediaType = raw.MediaType
-	m.Tags = raw.Tags
+	m.Tags = raw.Tag
+	if m.Tags == nil {
+		m.Tags = raw.Tags
+	}
+	m.Annotations = raw.Annotations
 
 	return nil
 }
 
+// parseTime parses a time given in milliseconds since the epoch, as GCR
+// does, or else in RFC 3339, as Artifact Registry does.
+func parseTime(ms json.Number, rfc3339 string) (time.Time, error) {
+	if ms != "" {
+		n, err := strconv.ParseInt(ms.String(), 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return fromUnixMs(n), nil
+	}
+	if rfc3339 != "" {
+		return time.Parse(time.RFC3339Nano, rfc3339)
+	}
+	return time.Time{}, nil
+}
+
 // Tags is the result of List and Walk.
 type Tags struct {
 	Children  []string                `json:"child"`
@@ -164,6 +164,96 @@ func TestList(t *testing.T) {
 	}
 }
 
+// rewriteHost sends every request to host, regardless of its URL.
+type rewriteHost struct {
+	host string
+}
+
+func (r *rewriteHost) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = "http", r.host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestListArtifactRegistry(t *testing.T) {
+	pages := []string{
+		`{"manifest":{"sha256:a":{"imageSizeBytes":1024,"mediaType":"application/vnd.oci.image.manifest.v1+json","buildTime":"2024-01-02T03:04:05Z","uploadTime":"2024-01-03T00:00:00.5Z","updateTime":"2024-01-04T00:00:00Z","tags":["v1"],"annotations":{"org.opencontainers.image.source":"https://github.com/example/app"}}},"tags":["v1"]}`,
+		`{"manifest":{"sha256:b":{"imageSizeBytes":"2048","mediaType":"application/vnd.oci.image.index.v1+json","timeCreatedMs":"1000","timeUploadedMs":"2000","tag":["v2"]}},"tags":["v2"]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case "/v2/project/repo/app/tags/list":
+			if r.URL.Query().Get("last") == "" {
+				w.Header().Set("Link", `</v2/project/repo/app/tags/list?last=v1>; rel="next"`)
+				w.Write([]byte(pages[0]))
+				return
+			}
+			w.Write([]byte(pages[1]))
+		default:
+			t.Fatalf("Unexpected path: %v", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := name.NewRepository("us-docker.pkg.dev/project/repo/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := List(repo, WithTransport(&rewriteHost{host: u.Host}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Tags{
+		Manifests: map[string]ManifestInfo{
+			"sha256:a": {
+				Size:      1024,
+				MediaType: "application/vnd.oci.image.manifest.v1+json",
+				Created:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+				Uploaded:  time.Date(2024, 1, 3, 0, 0, 0, 5e8, time.UTC),
+				Updated:   time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC),
+				Tags:      []string{"v1"},
+				Annotations: map[string]string{
+					"org.opencontainers.image.source": "https://github.com/example/app",
+				},
+			},
+			"sha256:b": {
+				Size:      2048,
+				MediaType: "application/vnd.oci.image.index.v1+json",
+				Created:   time.Unix(1, 0),
+				Uploaded:  time.Unix(2, 0),
+				Tags:      []string{"v2"},
+			},
+		},
+		Tags: []string{"v1", "v2"},
+	}
+	if diff := cmp.Diff(want, tags); diff != "" {
+		t.Errorf("List() wrong tags (-want +got) = %s", diff)
+	}
+
+	// The Artifact Registry fields survive a roundtrip through JSON.
+	b, err := json.Marshal(tags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundtripped := &Tags{}
+	if err := json.Unmarshal(b, roundtripped); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(tags.Manifests["sha256:a"].Annotations, roundtripped.Manifests["sha256:a"].Annotations); diff != "" {
+		t.Errorf("annotations didn't roundtrip (-want +got) = %s", diff)
+	}
+	if got, want := roundtripped.Manifests["sha256:a"].Updated, tags.Manifests["sha256:a"].Updated; !got.Equal(want) {
+		t.Errorf("Updated = %v, want %v", got, want)
+	}
+}
+
 type recorder struct {
 	Tags []*Tags
 	Errs []error
@@ -35,19 +35,17 @@ func WithTransport(t http.RoundTripper) Option {
 func WithAuth(auth authn.Authenticator) Option {
 	return func(l *lister) error {
 		l.auth = auth
+		l.keychain = nil
 		return nil
 	}
 }
 
 // WithAuthFromKeychain is a functional option for overriding the default
-// authenticator on a remote image using an authn.Keychain
+// authenticator on a remote image using an authn.Keychain. The keychain is
+// resolved with the context given by WithContext.
 func WithAuthFromKeychain(keys authn.Keychain) Option {
 	return func(l *lister) error {
-		auth, err := keys.Resolve(l.repo.Registry)
-		if err != nil {
-			return err
-		}
-		l.auth = auth
+		l.keychain = keys
 		return nil
 	}
 }
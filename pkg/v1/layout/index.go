@@ -19,7 +19,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
@@ -43,20 +42,10 @@ func ImageIndexFromPath(path string) (v1.ImageIndex, error) {
 	return lp.ImageIndex()
 }
 
-// ImageIndex returns a v1.ImageIndex for the Path.
+// ImageIndex returns a v1.ImageIndex for the Path's index.json. See
+// ImageIndexFile for layouts with other top-level index files.
 func (l Path) ImageIndex() (v1.ImageIndex, error) {
-	rawIndex, err := os.ReadFile(l.path("index.json"))
-	if err != nil {
-		return nil, err
-	}
-
-	idx := &layoutIndex{
-		mediaType: types.OCIImageIndex,
-		path:      l,
-		rawIndex:  rawIndex,
-	}
-
-	return idx, nil
+	return l.ImageIndexFile("index.json")
 }
 
 func (i *layoutIndex) MediaType() (types.MediaType, error) {
@@ -0,0 +1,200 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// IndexFiles returns the names of the top-level index files in the layout:
+// index.json, if it exists, followed by the other JSON files at the root of
+// the layout that hold image indexes, in order. Some tools write an index
+// file per image or per tag alongside (or instead of) index.json.
+func (l Path) IndexFiles() ([]string, error) {
+	entries, err := os.ReadDir(string(l))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		if e.Name() != "index.json" {
+			if ok, err := l.isIndexFile(e.Name()); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+		}
+		names = append(names, e.Name())
+	}
+	sort.SliceStable(names, func(i, j int) bool {
+		// index.json first, then the rest by name.
+		if (names[i] == "index.json") != (names[j] == "index.json") {
+			return names[i] == "index.json"
+		}
+		return names[i] < names[j]
+	})
+	return names, nil
+}
+
+// isIndexFile reports whether the file name at the root of the layout holds an
+// image index, rather than e.g. Docker's manifest.json or other metadata.
+func (l Path) isIndexFile(name string) (bool, error) {
+	b, err := os.ReadFile(l.path(name))
+	if err != nil {
+		return false, err
+	}
+	var index struct {
+		SchemaVersion int64             `json:"schemaVersion"`
+		Manifests     []json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(b, &index); err != nil {
+		return false, nil
+	}
+	return index.SchemaVersion == 2 && index.Manifests != nil, nil
+}
+
+// ImageIndexFile returns a v1.ImageIndex for the top-level index file name
+// (see IndexFiles). ImageIndex is ImageIndexFile("index.json").
+func (l Path) ImageIndexFile(name string) (v1.ImageIndex, error) {
+	if name != "index.json" {
+		if ok, err := l.isIndexFile(name); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("%s is not an image index", name)
+		}
+	}
+	rawIndex, err := os.ReadFile(l.path(name))
+	if err != nil {
+		return nil, err
+	}
+	return &layoutIndex{
+		mediaType: types.OCIImageIndex,
+		path:      l,
+		rawIndex:  rawIndex,
+	}, nil
+}
+
+// Normalize migrates the layout to the canonical form of the OCI image
+// layout specification, with a single index.json:
+//   - the descriptors in the other top-level index files are merged into
+//     index.json, and those files are removed;
+//   - index.json gets the schemaVersion and mediaType of an OCI image index;
+//   - descriptors without media types get those of the manifests they
+//     describe;
+//   - the oci-layout file is written, if it is missing.
+//
+// Blobs are left as they are.
+func (l Path) Normalize() error {
+	names, err := l.IndexFiles()
+	if err != nil {
+		return err
+	}
+
+	index := v1.IndexManifest{}
+	for _, name := range names {
+		b, err := os.ReadFile(l.path(name))
+		if err != nil {
+			return err
+		}
+		var im v1.IndexManifest
+		if err := json.Unmarshal(b, &im); err != nil {
+			return fmt.Errorf("parsing %s: %w", name, err)
+		}
+		if name == "index.json" {
+			index.Annotations = im.Annotations
+			index.Subject = im.Subject
+		}
+		for _, desc := range im.Manifests {
+			if desc.MediaType == "" {
+				if desc.MediaType, err = l.sniffMediaType(desc.Digest); err != nil {
+					return fmt.Errorf("%s: %w", name, err)
+				}
+			}
+			if !containsDescriptor(index.Manifests, desc) {
+				index.Manifests = append(index.Manifests, desc)
+			}
+		}
+	}
+	index.SchemaVersion = 2
+	index.MediaType = types.OCIImageIndex
+	if index.Manifests == nil {
+		index.Manifests = []v1.Descriptor{}
+	}
+
+	if _, err := os.Stat(l.path("oci-layout")); os.IsNotExist(err) {
+		if err := l.WriteFile("oci-layout", []byte(layoutFile), os.ModePerm); err != nil {
+			return err
+		}
+	}
+	rawIndex, err := json.MarshalIndent(index, "", "   ")
+	if err != nil {
+		return err
+	}
+	if err := l.WriteFile("index.json", rawIndex, os.ModePerm); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == "index.json" {
+			continue
+		}
+		if err := os.Remove(l.path(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sniffMediaType returns the media type of the manifest blob h.
+func (l Path) sniffMediaType(h v1.Hash) (types.MediaType, error) {
+	b, err := l.Bytes(h)
+	if err != nil {
+		return "", err
+	}
+	var m struct {
+		MediaType types.MediaType   `json:"mediaType"`
+		Manifests []json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", fmt.Errorf("parsing manifest %s: %w", h, err)
+	}
+	switch {
+	case m.MediaType != "":
+		return m.MediaType, nil
+	case m.Manifests != nil:
+		return types.OCIImageIndex, nil
+	default:
+		return types.OCIManifestSchema1, nil
+	}
+}
+
+func containsDescriptor(descs []v1.Descriptor, desc v1.Descriptor) bool {
+	for _, d := range descs {
+		if reflect.DeepEqual(d, desc) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestMultipleIndexes(t *testing.T) {
+	tmp := t.TempDir()
+	l, err := Write(tmp, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img1, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.AppendImage(img1); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another tool wrote an index file of its own, with a descriptor that
+	// lacks a media type, and Docker's manifest.json.
+	img2, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.WriteImage(img2); err != nil {
+		t.Fatal(err)
+	}
+	desc2, err := partial.Descriptor(img2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare := *desc2
+	bare.MediaType = ""
+	other, err := json.Marshal(v1.IndexManifest{SchemaVersion: 2, Manifests: []v1.Descriptor{bare}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.WriteFile("index-other.json", other, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.WriteFile("manifest.json", []byte(`[{"Config":"x"}]`), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := l.IndexFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"index.json", "index-other.json"}, names); diff != "" {
+		t.Errorf("IndexFiles() (-want +got): %s", diff)
+	}
+	ii, err := l.ImageIndexFile("index-other.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err := ii.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(im.Manifests) != 1 || im.Manifests[0].Digest != desc2.Digest {
+		t.Errorf("index-other.json has %v, want %s", im.Manifests, desc2.Digest)
+	}
+	if _, err := l.ImageIndexFile("manifest.json"); err == nil {
+		t.Error("ImageIndexFile(manifest.json) succeeded, want error")
+	}
+
+	// Layouts with only other index files have to be normalized to be
+	// read.
+	if err := os.Rename(filepath.Join(tmp, "index.json"), filepath.Join(tmp, "index-main.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromPath(tmp); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("FromPath() without index.json = %v, want not exist", err)
+	}
+
+	if err := l.Normalize(); err != nil {
+		t.Fatalf("Normalize() = %v", err)
+	}
+	if names, err := l.IndexFiles(); err != nil || len(names) != 1 || names[0] != "index.json" {
+		t.Errorf("IndexFiles() after Normalize() = %v, %v", names, err)
+	}
+	ii, err = l.ImageIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	im, err = ii.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if im.SchemaVersion != 2 || im.MediaType != types.OCIImageIndex || len(im.Manifests) != 2 {
+		t.Fatalf("index.json after Normalize() = %+v", im)
+	}
+	// Descriptors are merged in the order of the files (index-main.json, then
+	// index-other.json), and get media types.
+	desc1, err := partial.Descriptor(img1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]v1.Descriptor{*desc1, *desc2}, im.Manifests); diff != "" {
+		t.Errorf("descriptors (-want +got): %s", diff)
+	}
+	for _, desc := range im.Manifests {
+		if _, err := ii.Image(desc.Digest); err != nil {
+			t.Errorf("Image(%s) = %v", desc.Digest, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "manifest.json")); err != nil {
+		t.Errorf("manifest.json: %v", err)
+	}
+
+	// Normalizing again changes nothing.
+	before, err := os.ReadFile(filepath.Join(tmp, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.ReadFile(filepath.Join(tmp, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("Normalize() isn't idempotent: %s != %s", before, after)
+	}
+}
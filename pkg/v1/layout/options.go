@@ -21,6 +21,7 @@ type Option func(*options)
 
 type options struct {
 	descOpts []descriptorOption
+	verify   bool
 }
 
 func makeOptions(opts ...Option) *options {
@@ -69,3 +70,17 @@ func WithPlatform(platform v1.Platform) Option {
 		})
 	}
 }
+
+// WithDigestVerification hashes every blob as it is written and fails the
+// write if the digest doesn't match, instead of leaving a corrupt blob to be
+// discovered when it is read. Blobs are written to a temporary file that is
+// only renamed into place once it has been verified.
+//
+// Blobs that already exist in the layout are not re-verified. It applies to
+// AppendImage, AppendIndex, ReplaceImage and ReplaceIndex; use
+// WriteVerifiedBlob to write single blobs this way.
+func WithDigestVerification() Option {
+	return func(o *options) {
+		o.verify = true
+	}
+}
@@ -15,16 +15,25 @@
 package layout
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // FromPath reads an OCI image layout at path and constructs a layout.Path.
+//
+// Layouts without an index.json are rejected, even if they have other
+// top-level index files (see Path.IndexFiles); Path(path).Normalize migrates
+// them to the canonical form, which can be read.
 func FromPath(path string) (Path, error) {
 	// TODO: check oci-layout exists
 
 	_, err := os.Stat(filepath.Join(path, "index.json"))
 	if err != nil {
+		if names, nerr := Path(path).IndexFiles(); nerr == nil && len(names) != 0 {
+			return "", fmt.Errorf("%w; the layout has other index files (%s), which Normalize merges into index.json", err, strings.Join(names, ", "))
+		}
 		return "", err
 	}
 
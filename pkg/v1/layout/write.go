@@ -16,6 +16,7 @@ package layout
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -45,7 +46,7 @@ var renameMutex sync.Mutex
 // AppendImage writes a v1.Image to the Path and updates
 // the index.json to reference it.
 func (l Path) AppendImage(img v1.Image, options ...Option) error {
-	if err := l.WriteImage(img); err != nil {
+	if err := l.writeImage(img, makeOptions(options...)); err != nil {
 		return err
 	}
 
@@ -65,7 +66,7 @@ func (l Path) AppendImage(img v1.Image, options ...Option) error {
 // AppendIndex writes a v1.ImageIndex to the Path and updates
 // the index.json to reference it.
 func (l Path) AppendIndex(ii v1.ImageIndex, options ...Option) error {
-	if err := l.WriteIndex(ii); err != nil {
+	if err := l.writeIndex(ii, makeOptions(options...)); err != nil {
 		return err
 	}
 
@@ -107,7 +108,7 @@ func (l Path) AppendDescriptor(desc v1.Descriptor) error {
 // ReplaceImage writes a v1.Image to the Path and updates
 // the index.json to reference it, replacing any existing one that matches matcher, if found.
 func (l Path) ReplaceImage(img v1.Image, matcher match.Matcher, options ...Option) error {
-	if err := l.WriteImage(img); err != nil {
+	if err := l.writeImage(img, makeOptions(options...)); err != nil {
 		return err
 	}
 
@@ -117,7 +118,7 @@ func (l Path) ReplaceImage(img v1.Image, matcher match.Matcher, options ...Optio
 // ReplaceIndex writes a v1.ImageIndex to the Path and updates
 // the index.json to reference it, replacing any existing one that matches matcher, if found.
 func (l Path) ReplaceIndex(ii v1.ImageIndex, matcher match.Matcher, options ...Option) error {
-	if err := l.WriteIndex(ii); err != nil {
+	if err := l.writeIndex(ii, makeOptions(options...)); err != nil {
 		return err
 	}
 
@@ -197,14 +198,26 @@ func (l Path) WriteFile(name string, data []byte, perm os.FileMode) error {
 // WriteBlob copies a file to the blobs/ directory in the Path from the given ReadCloser at
 // blobs/{hash.Algorithm}/{hash.Hex}.
 func (l Path) WriteBlob(hash v1.Hash, r io.ReadCloser) error {
-	return l.writeBlob(hash, -1, r, nil)
+	return l.writeBlob(hash, -1, r, nil, false)
 }
 
-func (l Path) writeBlob(hash v1.Hash, size int64, rc io.ReadCloser, renamer func() (v1.Hash, error)) error {
+// WriteVerifiedBlob is WriteBlob, but hashes r as it is written, and fails
+// without writing anything to blobs/ if its digest isn't hash, as
+// WithDigestVerification does for images and indexes.
+func (l Path) WriteVerifiedBlob(hash v1.Hash, r io.ReadCloser) error {
+	return l.writeBlob(hash, -1, r, nil, true)
+}
+
+func (l Path) writeBlob(hash v1.Hash, size int64, rc io.ReadCloser, renamer func() (v1.Hash, error), verify bool) error {
 	defer rc.Close()
 	if hash.Hex == "" && renamer == nil {
 		panic("writeBlob called an invalid hash and no renamer")
 	}
+	if verify && renamer == nil {
+		// Write to a temporary file so nothing lands at the final path unless
+		// it checks out.
+		renamer = func() (v1.Hash, error) { return hash, nil }
+	}
 
 	dir := l.path("blobs", hash.Algorithm)
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil && !os.IsExist(err) {
@@ -236,8 +249,25 @@ func (l Path) writeBlob(hash v1.Hash, size int64, rc io.ReadCloser, renamer func
 	}
 	defer w.Close()
 
+	var src io.Reader = rc
+	var digest func() v1.Hash
+	if verify {
+		alg := hash.Algorithm
+		if alg == "" {
+			alg = "sha256"
+		}
+		hasher, err := v1.Hasher(alg)
+		if err != nil {
+			return err
+		}
+		src = io.TeeReader(rc, hasher)
+		digest = func() v1.Hash {
+			return v1.Hash{Algorithm: alg, Hex: hex.EncodeToString(hasher.Sum(nil))}
+		}
+	}
+
 	// Write to file and exit if not renaming
-	if n, err := io.Copy(w, rc); err != nil || renamer == nil {
+	if n, err := io.Copy(w, src); err != nil || renamer == nil {
 		return err
 	} else if size != -1 && n != size {
 		return fmt.Errorf("expected blob size %d, but only wrote %d", size, n)
@@ -262,6 +292,11 @@ func (l Path) writeBlob(hash v1.Hash, size int64, rc io.ReadCloser, renamer func
 	if err != nil {
 		return fmt.Errorf("error getting final digest of layer: %w", err)
 	}
+	if verify {
+		if got := digest(); got != finalHash {
+			return fmt.Errorf("blob %s has digest %s", finalHash, got)
+		}
+	}
 
 	renamePath := l.path("blobs", finalHash.Algorithm, finalHash.Hex)
 
@@ -278,7 +313,7 @@ func (l Path) writeBlob(hash v1.Hash, size int64, rc io.ReadCloser, renamer func
 // WriteBlob, it will not skip writing and exit without error when a blob file
 // exists, but does not have the correct size. (The blob hash is not
 // considered, because it may be expensive to compute.)
-func (l Path) writeLayer(layer v1.Layer) error {
+func (l Path) writeLayer(layer v1.Layer, verify bool) error {
 	d, err := layer.Digest()
 	if errors.Is(err, stream.ErrNotComputed) {
 		// Allow digest errors, since streams may not have calculated the hash
@@ -309,7 +344,7 @@ func (l Path) writeLayer(layer v1.Layer) error {
 		return err
 	}
 
-	if err := l.writeBlob(d, s, r, layer.Digest); err != nil {
+	if err := l.writeBlob(d, s, r, layer.Digest, verify); err != nil {
 		return fmt.Errorf("error writing layer: %w", err)
 	}
 	return nil
@@ -335,6 +370,10 @@ func (l Path) RemoveBlob(hash v1.Hash) error {
 // image and also update the `index.json`, call AppendImage(), which wraps this
 // and also updates the `index.json`.
 func (l Path) WriteImage(img v1.Image) error {
+	return l.writeImage(img, makeOptions())
+}
+
+func (l Path) writeImage(img v1.Image, o *options) error {
 	layers, err := img.Layers()
 	if err != nil {
 		return err
@@ -345,7 +384,7 @@ func (l Path) WriteImage(img v1.Image) error {
 	for _, layer := range layers {
 		layer := layer
 		g.Go(func() error {
-			return l.writeLayer(layer)
+			return l.writeLayer(layer, o.verify)
 		})
 	}
 	if err := g.Wait(); err != nil {
@@ -361,7 +400,7 @@ func (l Path) WriteImage(img v1.Image) error {
 	if err != nil {
 		return err
 	}
-	if err := l.WriteBlob(cfgName, io.NopCloser(bytes.NewReader(cfgBlob))); err != nil {
+	if err := l.writeBlob(cfgName, -1, io.NopCloser(bytes.NewReader(cfgBlob)), nil, o.verify); err != nil {
 		return err
 	}
 
@@ -375,7 +414,7 @@ func (l Path) WriteImage(img v1.Image) error {
 		return err
 	}
 
-	return l.WriteBlob(d, io.NopCloser(bytes.NewReader(manifest)))
+	return l.writeBlob(d, -1, io.NopCloser(bytes.NewReader(manifest)), nil, o.verify)
 }
 
 type withLayer interface {
@@ -386,7 +425,7 @@ type withBlob interface {
 	Blob(v1.Hash) (io.ReadCloser, error)
 }
 
-func (l Path) writeIndexToFile(indexFile string, ii v1.ImageIndex) error {
+func (l Path) writeIndexToFile(indexFile string, ii v1.ImageIndex, o *options) error {
 	index, err := ii.IndexManifest()
 	if err != nil {
 		return err
@@ -401,7 +440,7 @@ func (l Path) writeIndexToFile(indexFile string, ii v1.ImageIndex) error {
 			if err != nil {
 				return err
 			}
-			if err := l.WriteIndex(ii); err != nil {
+			if err := l.writeIndex(ii, o); err != nil {
 				return err
 			}
 		case types.OCIManifestSchema1, types.DockerManifestSchema2:
@@ -409,7 +448,7 @@ func (l Path) writeIndexToFile(indexFile string, ii v1.ImageIndex) error {
 			if err != nil {
 				return err
 			}
-			if err := l.WriteImage(img); err != nil {
+			if err := l.writeImage(img, o); err != nil {
 				return err
 			}
 		default:
@@ -430,7 +469,7 @@ func (l Path) writeIndexToFile(indexFile string, ii v1.ImageIndex) error {
 			if err != nil {
 				return err
 			}
-			if err := l.WriteBlob(desc.Digest, blob); err != nil {
+			if err := l.writeBlob(desc.Digest, -1, blob, nil, o.verify); err != nil {
 				return err
 			}
 		}
@@ -452,6 +491,10 @@ func (l Path) writeIndexToFile(indexFile string, ii v1.ImageIndex) error {
 // index and also update the `index.json`, call AppendIndex(), which wraps this
 // and also updates the `index.json`.
 func (l Path) WriteIndex(ii v1.ImageIndex) error {
+	return l.writeIndex(ii, makeOptions())
+}
+
+func (l Path) writeIndex(ii v1.ImageIndex, o *options) error {
 	// Always just write oci-layout file, since it's small.
 	if err := l.WriteFile("oci-layout", []byte(layoutFile), os.ModePerm); err != nil {
 		return err
@@ -463,7 +506,7 @@ func (l Path) WriteIndex(ii v1.ImageIndex) error {
 	}
 
 	indexFile := filepath.Join("blobs", h.Algorithm, h.Hex)
-	return l.writeIndexToFile(indexFile, ii)
+	return l.writeIndexToFile(indexFile, ii, o)
 }
 
 // Write constructs a Path at path from an ImageIndex.
@@ -488,5 +531,5 @@ func Write(path string, ii v1.ImageIndex) (Path, error) {
 
 	// TODO create blobs/ in case there is a blobs file which would prevent the directory from being created
 
-	return lp, lp.writeIndexToFile("index.json", ii)
+	return lp, lp.writeIndexToFile("index.json", ii, makeOptions())
 }
@@ -578,7 +578,7 @@ func TestOverwriteWithWriteLayer(t *testing.T) {
 	}
 
 	// try writing expected contents with writeLayer
-	if err := l.writeLayer(layer); err != nil {
+	if err := l.writeLayer(layer, false); err != nil {
 		t.Fatalf("error attempting to overwrite truncated layer with valid layer; (Path).writeLayer = %v", err)
 	}
 
@@ -670,3 +670,86 @@ func TestOverwriteWithReplaceImage(t *testing.T) {
 		t.Fatalf("validating image after attempting repair of truncated layer with ReplaceImage; validate.Image() = %v", err)
 	}
 }
+
+// lyingLayer claims a digest that doesn't match its contents.
+type lyingLayer struct {
+	v1.Layer
+	digest v1.Hash
+}
+
+func (l lyingLayer) Digest() (v1.Hash, error) {
+	return l.digest, nil
+}
+
+func TestWriteDigestVerification(t *testing.T) {
+	wrong := v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("0", 64)}
+
+	t.Run("blob", func(t *testing.T) {
+		l, err := Write(t.TempDir(), empty.Index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		blob := io.NopCloser(strings.NewReader("not what you expected"))
+		if err := l.WriteVerifiedBlob(wrong, blob); err == nil {
+			t.Fatal("WriteVerifiedBlob() with wrong digest succeeded")
+		}
+		entries, err := os.ReadDir(l.path("blobs", "sha256"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("blobs left behind after failed write: %v", entries)
+		}
+
+		// Without verification, the blob is written as-is.
+		blob = io.NopCloser(strings.NewReader("not what you expected"))
+		if err := l.WriteBlob(wrong, blob); err != nil {
+			t.Fatalf("WriteBlob() = %v", err)
+		}
+	})
+
+	t.Run("image", func(t *testing.T) {
+		l, err := Write(t.TempDir(), empty.Index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		layer, err := random.Layer(1024, types.OCILayer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := mutate.AppendLayers(empty.Image, lyingLayer{Layer: layer, digest: wrong})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.AppendImage(img, WithDigestVerification()); err == nil || !strings.Contains(err.Error(), wrong.String()) {
+			t.Errorf("AppendImage() = %v, want digest mismatch", err)
+		}
+		if _, err := os.Stat(l.path("blobs", wrong.Algorithm, wrong.Hex)); !os.IsNotExist(err) {
+			t.Errorf("corrupt blob written: %v", err)
+		}
+
+		img, err = random.Image(1024, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.AppendImage(img, WithDigestVerification()); err != nil {
+			t.Fatalf("AppendImage() = %v", err)
+		}
+		got, err := l.Image(mustDigest(t, img))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := validate.Image(got); err != nil {
+			t.Errorf("validate.Image() = %v", err)
+		}
+	})
+}
+
+func mustDigest(t *testing.T, img v1.Image) v1.Hash {
+	t.Helper()
+	h, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
@@ -0,0 +1,270 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutate
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/compression"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// FilterLayers returns img with the entries of its layers for which keep
+// returns false removed. keep is called once for each entry, in order from
+// the base layer up.
+//
+// Removing an entry from a layer would expose an entry with the same path in
+// a lower layer, so a whiteout for it is added to the layer if a lower layer
+// has the path. Hard links to removed entries are removed as well.
+//
+// Layers without removed entries are left as they are; the others are
+// rewritten and recompressed, and their digests and diffIDs recomputed. The
+// rewritten layers read their original layers again when they are read.
+func FilterLayers(img v1.Image, keep func(hdr *tar.Header) bool) (v1.Image, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	if len(layers) != len(m.Layers) {
+		return nil, fmt.Errorf("image has %d layers, but its manifest has %d", len(layers), len(m.Layers))
+	}
+
+	// The paths in the layers below the current one.
+	lower := map[string]bool{}
+	adds := make([]Addendum, 0, len(layers))
+	diffIDs := make([]v1.Hash, 0, len(layers))
+	for i, layer := range layers {
+		f, err := scanLayer(layer, keep, lower)
+		if err != nil {
+			return nil, fmt.Errorf("filtering layer %d: %w", i, err)
+		}
+		add := Addendum{
+			Layer:       layer,
+			URLs:        m.Layers[i].URLs,
+			Annotations: m.Layers[i].Annotations,
+			MediaType:   m.Layers[i].MediaType,
+		}
+		if len(f.drop) != 0 {
+			if add.Layer, err = f.filtered(m.Layers[i].MediaType); err != nil {
+				return nil, fmt.Errorf("filtering layer %d: %w", i, err)
+			}
+			// The rewritten layer is in the registry, wherever the original was.
+			add.URLs, add.MediaType = nil, ""
+		}
+		diffID, err := add.Layer.DiffID()
+		if err != nil {
+			return nil, err
+		}
+		adds = append(adds, add)
+		diffIDs = append(diffIDs, diffID)
+	}
+
+	mt, err := img.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	base := MediaType(empty.Image, mt)
+	base = ConfigMediaType(base, m.Config.MediaType)
+	filtered, err := Append(base, adds...)
+	if err != nil {
+		return nil, err
+	}
+	cfg := cf.DeepCopy()
+	cfg.RootFS.DiffIDs = diffIDs
+	filtered, err = ConfigFile(filtered, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(m.Annotations) != 0 {
+		filtered = Annotations(filtered, m.Annotations).(v1.Image)
+	}
+	if m.Subject != nil {
+		filtered = Subject(filtered, *m.Subject).(v1.Image)
+	}
+	return filtered, nil
+}
+
+// RemovePaths returns img without the files and directories at paths, or
+// beneath them, in any of its layers. Paths are relative to the root of the
+// image's filesystem; a leading slash is ignored. See FilterLayers.
+func RemovePaths(img v1.Image, paths []string) (v1.Image, error) {
+	remove := make([]string, 0, len(paths))
+	for _, p := range paths {
+		p = cleanPath(p)
+		if p == "" {
+			return nil, errors.New("cannot remove the root directory")
+		}
+		remove = append(remove, p)
+	}
+	return FilterLayers(img, func(hdr *tar.Header) bool {
+		name := cleanPath(hdr.Name)
+		for _, p := range remove {
+			if name == p || strings.HasPrefix(name, p+"/") {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// cleanPath returns name relative to the root of the filesystem, without
+// "./", trailing slashes or the like; the root itself is "".
+func cleanPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// filteredLayer is what FilterLayers removes from a layer.
+type filteredLayer struct {
+	orig v1.Layer
+	// The indexes of the tar entries to drop.
+	drop map[int]bool
+	// The paths to add whiteouts for.
+	whiteouts []string
+}
+
+// scanLayer decides which entries of layer to drop with keep, and what
+// whiteouts to add. lower holds the paths of the layers below, and the
+// layer's paths are added to it.
+func scanLayer(layer v1.Layer, keep func(hdr *tar.Header) bool, lower map[string]bool) (*filteredLayer, error) {
+	rc, err := layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	f := &filteredLayer{orig: layer, drop: map[int]bool{}}
+	var (
+		dropped   = map[string]bool{}
+		whiteouts = map[string]bool{}
+		kept      []string
+	)
+	tr := tar.NewReader(rc)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		name := cleanPath(hdr.Name)
+		if dir, base := path.Split(name); strings.HasPrefix(base, whiteoutPrefix) {
+			whiteouts[path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))] = true
+		}
+		if !keep(hdr) || (hdr.Typeflag == tar.TypeLink && dropped[cleanPath(hdr.Linkname)]) {
+			f.drop[i] = true
+			if !dropped[name] {
+				dropped[name] = true
+				if lower[name] {
+					f.whiteouts = append(f.whiteouts, name)
+				}
+			}
+			continue
+		}
+		if !strings.HasPrefix(path.Base(name), whiteoutPrefix) {
+			kept = append(kept, name)
+		}
+	}
+
+	// Whiteouts already in the layer don't need adding.
+	n := 0
+	for _, p := range f.whiteouts {
+		if !whiteouts[p] {
+			f.whiteouts[n] = p
+			n++
+		}
+	}
+	f.whiteouts = f.whiteouts[:n]
+
+	for _, name := range kept {
+		for p := name; p != "." && p != ""; p = path.Dir(p) {
+			lower[p] = true
+		}
+	}
+	return f, nil
+}
+
+// filtered returns the filtered layer, compressed like the original, whose
+// media type was mt.
+func (f *filteredLayer) filtered(mt types.MediaType) (v1.Layer, error) {
+	opts := []tarball.LayerOption{tarball.WithMediaType(types.DockerLayer)}
+	if strings.HasPrefix(string(mt), "application/vnd.oci.") {
+		opts = []tarball.LayerOption{tarball.WithMediaType(types.OCILayer)}
+	}
+	if mt == types.OCILayerZStd {
+		opts = append(opts, tarball.WithCompression(compression.ZStd))
+	}
+	return tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		rc, err := f.orig.Uncompressed()
+		if err != nil {
+			return nil, err
+		}
+		pr, pw := io.Pipe()
+		go func() {
+			defer rc.Close()
+			pw.CloseWithError(f.write(pw, rc))
+		}()
+		return pr, nil
+	}, opts...)
+}
+
+// write writes the filtered tar of the layer's uncompressed contents r to w.
+func (f *filteredLayer) write(w io.Writer, r io.Reader) error {
+	tw := tar.NewWriter(w)
+	tr := tar.NewReader(r)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		if f.drop[i] {
+			continue
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	for _, p := range f.whiteouts {
+		dir, base := path.Split(p)
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     path.Join(dir, whiteoutPrefix+base),
+			Typeflag: tar.TypeReg,
+			Mode:     0o644,
+		}); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
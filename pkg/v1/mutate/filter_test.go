@@ -0,0 +1,206 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutate_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// tarLayer returns a layer of the given entries: regular files, unless a
+// name ends with "/", or a hard link if it is mapped to "link:TARGET".
+func tarLayer(t *testing.T, entries ...[2]string) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e[0], Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(e[1]))}
+		switch {
+		case e[0][len(e[0])-1] == '/':
+			hdr.Typeflag, hdr.Mode, hdr.Size = tar.TypeDir, 0o755, 0
+		case len(e[1]) > 5 && e[1][:5] == "link:":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeLink, e[1][5:], 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e[1])); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// layerNames returns the names of the entries in l.
+func layerNames(t *testing.T, l v1.Layer) []string {
+	t.Helper()
+	rc, err := l.Uncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	return tarNames(t, rc)
+}
+
+func tarNames(t *testing.T, r io.Reader) []string {
+	t.Helper()
+	var names []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, hdr.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRemovePaths(t *testing.T) {
+	base := tarLayer(t,
+		[2]string{"etc/", ""},
+		[2]string{"etc/secret", "hunter2"},
+		[2]string{"etc/hosts", "localhost"},
+		[2]string{"cache/", ""},
+		[2]string{"cache/a", "a"},
+	)
+	middle := tarLayer(t, [2]string{"bin/app", "app"})
+	top := tarLayer(t,
+		[2]string{"etc/secret", "hunter3"},
+		[2]string{"etc/secret-link", "link:etc/secret"},
+		[2]string{"./cache/b", "b"},
+	)
+	img, err := mutate.AppendLayers(empty.Image, base, middle, top)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filtered, err := mutate.RemovePaths(img, []string{"/etc/secret", "cache/"})
+	if err != nil {
+		t.Fatalf("RemovePaths() = %v", err)
+	}
+	if err := validate.Image(filtered); err != nil {
+		t.Fatalf("validate.Image() = %v", err)
+	}
+
+	layers, err := filtered.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range [][]string{
+		{"etc/", "etc/hosts"},
+		{"bin/app"},
+		// Everything is removed from every layer, so no whiteouts are needed.
+		nil,
+	} {
+		if diff := cmp.Diff(want, layerNames(t, layers[i])); diff != "" {
+			t.Errorf("layer %d (-want +got): %s", i, diff)
+		}
+	}
+
+	// The middle layer had nothing removed, so it's left as it was.
+	want, err := middle.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := layers[1].Digest(); err != nil {
+		t.Fatal(err)
+	} else if got != want {
+		t.Errorf("middle layer digest = %s, want %s", got, want)
+	}
+
+	if diff := cmp.Diff([]string{"bin/app", "etc", "etc/hosts"}, tarNames(t, mutate.Extract(filtered))); diff != "" {
+		t.Errorf("filesystem (-want +got): %s", diff)
+	}
+
+	if _, err := mutate.RemovePaths(img, []string{"/"}); err == nil {
+		t.Error("RemovePaths(/) succeeded, want an error")
+	}
+}
+
+func TestFilterLayersWhiteout(t *testing.T) {
+	base := tarLayer(t, [2]string{"etc/", ""}, [2]string{"etc/config", "v1"}, [2]string{"var/cache/x", "x"})
+	top := tarLayer(t, [2]string{"etc/config", "v2"}, [2]string{"var/cache/y", "y"})
+	img, err := mutate.AppendLayers(empty.Image, base, top)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Drop the top layer's etc/config and var/cache/y, but keep the base's.
+	seen := map[string]bool{}
+	filtered, err := mutate.FilterLayers(img, func(hdr *tar.Header) bool {
+		first := !seen[hdr.Name]
+		seen[hdr.Name] = true
+		return first && hdr.Name != "var/cache/y"
+	})
+	if err != nil {
+		t.Fatalf("FilterLayers() = %v", err)
+	}
+	if err := validate.Image(filtered); err != nil {
+		t.Fatalf("validate.Image() = %v", err)
+	}
+
+	layers, err := filtered.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// etc/config would otherwise show through from the base layer; nothing
+	// in the base layer is at var/cache/y.
+	if diff := cmp.Diff([]string{"etc/.wh.config"}, layerNames(t, layers[1])); diff != "" {
+		t.Errorf("top layer (-want +got): %s", diff)
+	}
+	if diff := cmp.Diff([]string{"etc", "var/cache/x"}, tarNames(t, mutate.Extract(filtered))); diff != "" {
+		t.Errorf("filesystem (-want +got): %s", diff)
+	}
+
+	// The config still describes the image, with new diffIDs.
+	cf, err := filtered.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := layers[1].DiffID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cf.RootFS.DiffIDs[1]; got != want {
+		t.Errorf("diffIDs[1] = %s, want %s", got, want)
+	}
+}
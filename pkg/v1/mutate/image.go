@@ -39,6 +39,7 @@ type image struct {
 	diffIDMap       map[v1.Hash]v1.Layer
 	digestMap       map[v1.Hash]v1.Layer
 	subject         *v1.Descriptor
+	artifactType    *types.MediaType
 
 	sync.Mutex
 }
@@ -160,7 +161,12 @@ func (i *image) compute() error {
 			manifest.Annotations[k] = v
 		}
 	}
-	manifest.Subject = i.subject
+	if i.subject != nil {
+		manifest.Subject = i.subject
+	}
+	if i.artifactType != nil {
+		manifest.ArtifactType = string(*i.artifactType)
+	}
 
 	i.configFile = configFile
 	i.manifest = manifest
@@ -65,15 +65,20 @@ type index struct {
 	adds []IndexAddendum
 	// remove is removed before adds
 	remove match.Matcher
-
-	computed    bool
-	manifest    *v1.IndexManifest
-	annotations map[string]string
-	mediaType   *types.MediaType
-	imageMap    map[v1.Hash]v1.Image
-	indexMap    map[v1.Hash]v1.ImageIndex
-	layerMap    map[v1.Hash]v1.Layer
-	subject     *v1.Descriptor
+	// platformMatch, if set, matches descriptors in base whose platform is
+	// replaced with platform.
+	platformMatch match.Matcher
+	platform      *v1.Platform
+
+	computed     bool
+	manifest     *v1.IndexManifest
+	annotations  map[string]string
+	mediaType    *types.MediaType
+	imageMap     map[v1.Hash]v1.Image
+	indexMap     map[v1.Hash]v1.ImageIndex
+	layerMap     map[v1.Hash]v1.Layer
+	subject      *v1.Descriptor
+	artifactType *types.MediaType
 
 	sync.Mutex
 }
@@ -119,6 +124,14 @@ func (i *index) compute() error {
 		manifests = cleanedManifests
 	}
 
+	if i.platformMatch != nil {
+		for j := range manifests {
+			if i.platformMatch(manifests[j]) {
+				manifests[j].Platform = i.platform.DeepCopy()
+			}
+		}
+	}
+
 	for _, add := range i.adds {
 		desc, err := computeDescriptor(add)
 		if err != nil {
@@ -151,7 +164,12 @@ func (i *index) compute() error {
 			manifest.Annotations[k] = v
 		}
 	}
-	manifest.Subject = i.subject
+	if i.subject != nil {
+		manifest.Subject = i.subject
+	}
+	if i.artifactType != nil {
+		manifest.ArtifactType = string(*i.artifactType)
+	}
 
 	i.manifest = manifest
 	i.computed = true
@@ -18,15 +18,18 @@ import (
 	"log"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/match"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/google/go-containerregistry/pkg/v1/validate"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 func TestAppendIndex(t *testing.T) {
@@ -233,3 +236,125 @@ func TestAppend_ArtifactType(t *testing.T) {
 		})
 	}
 }
+
+func TestManifestPlatform(t *testing.T) {
+	idx, err := random.Index(1, 1, 3)
+	if err != nil {
+		t.Fatalf("random.Index: %v", err)
+	}
+	before, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatalf("IndexManifest: %v", err)
+	}
+	target := before.Manifests[1].Digest
+	arm := &v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}
+
+	fixed := mutate.ManifestPlatform(idx, match.Digests(target), arm)
+	if _, err := fixed.Image(target); err != nil {
+		t.Errorf("Image(%v): %v", target, err)
+	}
+	after, err := fixed.IndexManifest()
+	if err != nil {
+		t.Fatalf("IndexManifest: %v", err)
+	}
+	for i, desc := range after.Manifests {
+		// Children keep their digests and order.
+		if desc.Digest != before.Manifests[i].Digest {
+			t.Errorf("Manifests[%d].Digest = %v, want %v", i, desc.Digest, before.Manifests[i].Digest)
+		}
+		want := before.Manifests[i].Platform
+		if desc.Digest == target {
+			want = arm
+		}
+		if d := cmp.Diff(want, desc.Platform); d != "" {
+			t.Errorf("Manifests[%d].Platform: (-want +got) %s", i, d)
+		}
+	}
+
+	// A nil platform removes it.
+	cleared, err := mutate.ManifestPlatform(fixed, match.Digests(target), nil).IndexManifest()
+	if err != nil {
+		t.Fatalf("IndexManifest: %v", err)
+	}
+	if p := cleared.Manifests[1].Platform; p != nil {
+		t.Errorf("Manifests[1].Platform = %v, want nil", p)
+	}
+}
+
+func TestIndexMetadata(t *testing.T) {
+	base, err := random.Index(1024, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base = mutate.Annotations(base, map[string]string{"keep": "me"}).(v1.ImageIndex)
+	subject, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subj, err := partial.Descriptor(subject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	subj.Annotations = map[string]string{"not": "copied"}
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("", 3600))
+
+	idx, err := mutate.IndexMetadata(base, mutate.IndexMetadataOptions{
+		ArtifactType: "application/vnd.example.bundle",
+		Subject:      subj,
+		Created:      created,
+		Annotations:  map[string]string{"foo": "bar"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Index(idx); err != nil {
+		t.Errorf("validate.Index() = %v", err)
+	}
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if im.ArtifactType != "application/vnd.example.bundle" {
+		t.Errorf("artifactType = %q", im.ArtifactType)
+	}
+	want := &v1.Descriptor{MediaType: subj.MediaType, Size: subj.Size, Digest: subj.Digest}
+	if d := cmp.Diff(want, im.Subject); d != "" {
+		t.Errorf("subject: (-want +got) %s", d)
+	}
+	wantAnns := map[string]string{"keep": "me", "foo": "bar", imagespec.AnnotationCreated: "2026-01-02T02:04:05Z"}
+	if d := cmp.Diff(wantAnns, im.Annotations); d != "" {
+		t.Errorf("annotations: (-want +got) %s", d)
+	}
+
+	for _, tc := range []struct {
+		name string
+		base v1.ImageIndex
+		opts mutate.IndexMetadataOptions
+	}{{
+		name: "bad artifactType",
+		base: base,
+		opts: mutate.IndexMetadataOptions{ArtifactType: "sbom"},
+	}, {
+		name: "artifactType with parameters",
+		base: base,
+		opts: mutate.IndexMetadataOptions{ArtifactType: "text/plain; charset=utf-8"},
+	}, {
+		name: "subject without digest",
+		base: base,
+		opts: mutate.IndexMetadataOptions{Subject: &v1.Descriptor{MediaType: types.OCIManifestSchema1, Size: 1}},
+	}, {
+		name: "conflicting created",
+		base: base,
+		opts: mutate.IndexMetadataOptions{Created: created, Annotations: map[string]string{imagespec.AnnotationCreated: "yesterday"}},
+	}, {
+		name: "docker manifest list",
+		base: mutate.IndexMediaType(empty.Index, types.DockerManifestList),
+		opts: mutate.IndexMetadataOptions{Subject: subj},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := mutate.IndexMetadata(tc.base, tc.opts); err == nil {
+				t.Error("IndexMetadata() succeeded")
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"mime"
 	"path/filepath"
 	"strings"
 	"time"
@@ -33,6 +34,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 const whiteoutPrefix = ".wh."
@@ -104,6 +106,18 @@ func RemoveManifests(base v1.ImageIndex, matcher match.Matcher) v1.ImageIndex {
 	}
 }
 
+// ManifestPlatform sets the platform of any descriptors in base that match
+// the match.Matcher to p, or removes it if p is nil, e.g. to fix an index
+// whose children have wrong or missing platforms without rebuilding it. The
+// descriptors keep their place in the index.
+func ManifestPlatform(base v1.ImageIndex, matcher match.Matcher, p *v1.Platform) v1.ImageIndex {
+	return &index{
+		base:          base,
+		platformMatch: matcher,
+		platform:      p.DeepCopy(),
+	}
+}
+
 // Config mutates the provided v1.Image to have the provided v1.Config
 func Config(base v1.Image, cfg v1.Config) (v1.Image, error) {
 	cf, err := base.ConfigFile()
@@ -145,6 +159,34 @@ func Subject(f partial.WithRawManifest, subject v1.Descriptor) partial.WithRawMa
 	return arbitraryRawManifest{a: f, subject: &subject}
 }
 
+// ArtifactType sets the artifactType of an image or index manifest, as OCI
+// 1.1 artifacts use to say what they are. It is returned as the
+// artifactType of the manifest's descriptor, e.g. in the referrers of its
+// subject (see Subject).
+//
+// Like Subject, the input is expected to be a v1.Image or v1.ImageIndex, and
+// returns the same type:
+//
+//	img := ArtifactType(empty.Image, "application/vnd.example.sbom").(v1.Image)
+//
+// If the input is not an Image or ImageIndex, the result will
+// attempt to lazily set the artifactType in the raw manifest.
+func ArtifactType(f partial.WithRawManifest, mt types.MediaType) partial.WithRawManifest {
+	if img, ok := f.(v1.Image); ok {
+		return &image{
+			base:         img,
+			artifactType: &mt,
+		}
+	}
+	if idx, ok := f.(v1.ImageIndex); ok {
+		return &index{
+			base:         idx,
+			artifactType: &mt,
+		}
+	}
+	return arbitraryRawManifest{a: f, artifactType: &mt}
+}
+
 // Annotations mutates the annotations on an annotatable image or index manifest.
 //
 // The annotatable input is expected to be a v1.Image or v1.ImageIndex, and
@@ -179,9 +221,10 @@ func Annotations(f partial.WithRawManifest, anns map[string]string) partial.With
 }
 
 type arbitraryRawManifest struct {
-	a       partial.WithRawManifest
-	anns    map[string]string
-	subject *v1.Descriptor
+	a            partial.WithRawManifest
+	anns         map[string]string
+	subject      *v1.Descriptor
+	artifactType *types.MediaType
 }
 
 func (a arbitraryRawManifest) RawManifest() ([]byte, error) {
@@ -193,20 +236,25 @@ func (a arbitraryRawManifest) RawManifest() ([]byte, error) {
 	if err := json.Unmarshal(b, &m); err != nil {
 		return nil, err
 	}
-	if ann, ok := m["annotations"]; ok {
-		if annm, ok := ann.(map[string]string); ok {
-			for k, v := range a.anns {
-				annm[k] = v
+	if a.anns != nil {
+		if ann, ok := m["annotations"]; ok {
+			if annm, ok := ann.(map[string]string); ok {
+				for k, v := range a.anns {
+					annm[k] = v
+				}
+			} else {
+				return nil, fmt.Errorf(".annotations is not a map: %T", ann)
 			}
 		} else {
-			return nil, fmt.Errorf(".annotations is not a map: %T", ann)
+			m["annotations"] = a.anns
 		}
-	} else {
-		m["annotations"] = a.anns
 	}
 	if a.subject != nil {
 		m["subject"] = a.subject
 	}
+	if a.artifactType != nil {
+		m["artifactType"] = *a.artifactType
+	}
 	return json.Marshal(m)
 }
 
@@ -255,14 +303,27 @@ func Extract(img v1.Image) io.ReadCloser {
 		// extraction. These errors will be returned by the reader end
 		// on subsequent reads. If err == nil, the reader will return
 		// EOF.
-		pw.CloseWithError(extract(img, pw))
+		pw.CloseWithError(extract(img, pw, nil))
+	}()
+
+	return pr
+}
+
+// ExtractProvenance is like Extract, but also calls record with the name of
+// each entry in the flattened filesystem and the index of the layer (in
+// img.Layers()) that it comes from, before the entry is written.
+func ExtractProvenance(img v1.Image, record func(name string, layer int)) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(extract(img, pw, record))
 	}()
 
 	return pr
 }
 
 // Adapted from https://github.com/google/containerregistry/blob/da03b395ccdc4e149e34fbb540483efce962dc64/client/v2_2/docker_image_.py#L816
-func extract(img v1.Image, w io.Writer) error {
+func extract(img v1.Image, w io.Writer, record func(name string, layer int)) error {
 	tarWriter := tar.NewWriter(w)
 	defer tarWriter.Close()
 
@@ -277,68 +338,76 @@ func extract(img v1.Image, w io.Writer) error {
 	// whiteout layers more efficient, since we can just keep track of the removed
 	// files as we see .wh. layers and ignore those in previous layers.
 	for i := len(layers) - 1; i >= 0; i-- {
-		layer := layers[i]
-		layerReader, err := layer.Uncompressed()
-		if err != nil {
-			return fmt.Errorf("reading layer contents: %w", err)
-		}
-		defer layerReader.Close()
-		tarReader := tar.NewReader(layerReader)
-		for {
-			header, err := tarReader.Next()
-			if errors.Is(err, io.EOF) {
-				break
-			}
+		if err := func() error {
+			layer := layers[i]
+			layerReader, err := layer.Uncompressed()
 			if err != nil {
-				return fmt.Errorf("reading tar: %w", err)
-			}
-
-			// Some tools prepend everything with "./", so if we don't Clean the
-			// name, we may have duplicate entries, which angers tar-split.
-			header.Name = filepath.Clean(header.Name)
-			// force PAX format to remove Name/Linkname length limit of 100 characters
-			// required by USTAR and to not depend on internal tar package guess which
-			// prefers USTAR over PAX
-			header.Format = tar.FormatPAX
-
-			basename := filepath.Base(header.Name)
-			dirname := filepath.Dir(header.Name)
-			tombstone := strings.HasPrefix(basename, whiteoutPrefix)
-			if tombstone {
-				basename = basename[len(whiteoutPrefix):]
+				return fmt.Errorf("reading layer contents: %w", err)
 			}
+			defer layerReader.Close()
+			tarReader := tar.NewReader(layerReader)
+			for {
+				header, err := tarReader.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return fmt.Errorf("reading tar: %w", err)
+				}
 
-			// check if we have seen value before
-			// if we're checking a directory, don't filepath.Join names
-			var name string
-			if header.Typeflag == tar.TypeDir {
-				name = header.Name
-			} else {
-				name = filepath.Join(dirname, basename)
-			}
+				// Some tools prepend everything with "./", so if we don't Clean the
+				// name, we may have duplicate entries, which angers tar-split.
+				header.Name = filepath.Clean(header.Name)
+				// force PAX format to remove Name/Linkname length limit of 100 characters
+				// required by USTAR and to not depend on internal tar package guess which
+				// prefers USTAR over PAX
+				header.Format = tar.FormatPAX
+
+				basename := filepath.Base(header.Name)
+				dirname := filepath.Dir(header.Name)
+				tombstone := strings.HasPrefix(basename, whiteoutPrefix)
+				if tombstone {
+					basename = basename[len(whiteoutPrefix):]
+				}
 
-			if _, ok := fileMap[name]; ok {
-				continue
-			}
+				// check if we have seen value before
+				// if we're checking a directory, don't filepath.Join names
+				var name string
+				if header.Typeflag == tar.TypeDir {
+					name = header.Name
+				} else {
+					name = filepath.Join(dirname, basename)
+				}
 
-			// check for a whited out parent directory
-			if inWhiteoutDir(fileMap, name) {
-				continue
-			}
+				if _, ok := fileMap[name]; ok {
+					continue
+				}
 
-			// mark file as handled. non-directory implicitly tombstones
-			// any entries with a matching (or child) name
-			fileMap[name] = tombstone || !(header.Typeflag == tar.TypeDir)
-			if !tombstone {
-				if err := tarWriter.WriteHeader(header); err != nil {
-					return err
+				// check for a whited out parent directory
+				if inWhiteoutDir(fileMap, name) {
+					continue
 				}
-				if header.Size > 0 {
-					if _, err := io.CopyN(tarWriter, tarReader, header.Size); err != nil {
+
+				// mark file as handled. non-directory implicitly tombstones
+				// any entries with a matching (or child) name
+				fileMap[name] = tombstone || !(header.Typeflag == tar.TypeDir)
+				if !tombstone {
+					if record != nil {
+						record(header.Name, i)
+					}
+					if err := tarWriter.WriteHeader(header); err != nil {
 						return err
 					}
+					if header.Size > 0 {
+						if _, err := io.CopyN(tarWriter, tarReader, header.Size); err != nil {
+							return err
+						}
+					}
 				}
 			}
+			return nil
+		}(); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -554,3 +623,75 @@ func IndexMediaType(idx v1.ImageIndex, mt types.MediaType) v1.ImageIndex {
 		mediaType: &mt,
 	}
 }
+
+// IndexMetadataOptions are the index-level fields that IndexMetadata sets.
+// Zero values leave the base index's fields as they are.
+type IndexMetadataOptions struct {
+	// ArtifactType is the artifactType of the index, as for ArtifactType.
+	ArtifactType types.MediaType
+
+	// Subject is the manifest that the index refers to, as for Subject.
+	Subject *v1.Descriptor
+
+	// Created is recorded in the org.opencontainers.image.created annotation.
+	Created time.Time
+
+	// Annotations are added to the index's annotations, as for Annotations.
+	Annotations map[string]string
+}
+
+// IndexMetadata sets the artifactType, subject and annotations of idx,
+// including when it was created, in one go, checking that they're valid.
+// Docker manifest lists have no artifactType or subject, so setting them on
+// one is an error.
+func IndexMetadata(idx v1.ImageIndex, opts IndexMetadataOptions) (v1.ImageIndex, error) {
+	mt, err := idx.MediaType()
+	if err != nil {
+		return nil, err
+	}
+	if mt == types.DockerManifestList && (opts.ArtifactType != "" || opts.Subject != nil) {
+		return nil, fmt.Errorf("%s has no artifactType or subject, use an OCI index", mt)
+	}
+
+	out := &index{base: idx}
+	if opts.ArtifactType != "" {
+		if err := validMediaType(opts.ArtifactType); err != nil {
+			return nil, fmt.Errorf("invalid artifactType: %w", err)
+		}
+		out.artifactType = &opts.ArtifactType
+	}
+	if opts.Subject != nil {
+		s := opts.Subject
+		if s.Digest == (v1.Hash{}) || s.Size <= 0 {
+			return nil, errors.New("invalid subject: it needs a digest and size")
+		}
+		if err := validMediaType(s.MediaType); err != nil {
+			return nil, fmt.Errorf("invalid subject: %w", err)
+		}
+		// Only these fields belong in a subject.
+		out.subject = &v1.Descriptor{MediaType: s.MediaType, Size: s.Size, Digest: s.Digest}
+	}
+	if opts.Annotations != nil || !opts.Created.IsZero() {
+		out.annotations = maps.Clone(opts.Annotations)
+		if !opts.Created.IsZero() {
+			if _, ok := opts.Annotations[imagespec.AnnotationCreated]; ok {
+				return nil, fmt.Errorf("both Created and a %s annotation were given", imagespec.AnnotationCreated)
+			}
+			if out.annotations == nil {
+				out.annotations = map[string]string{}
+			}
+			out.annotations[imagespec.AnnotationCreated] = opts.Created.UTC().Format(time.RFC3339)
+		}
+	}
+	return out, nil
+}
+
+// validMediaType checks that mt is a media type, without parameters, e.g.
+// "application/vnd.example+json".
+func validMediaType(mt types.MediaType) error {
+	parsed, params, err := mime.ParseMediaType(string(mt))
+	if err != nil || !strings.Contains(parsed, "/") || len(params) != 0 {
+		return fmt.Errorf("%q is not a media type", mt)
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
@@ -84,6 +85,39 @@ func TestExtractOverwrittenFile(t *testing.T) {
 	}
 }
 
+func TestExtractProvenance(t *testing.T) {
+	img, err := tarball.ImageFromPath("testdata/overwritten_file.tar", nil)
+	if err != nil {
+		t.Fatalf("Error loading image: %v", err)
+	}
+	provenance := map[string]int{}
+	tr := tar.NewReader(mutate.ExtractProvenance(img, func(name string, layer int) {
+		provenance[name] = layer
+	}))
+	var names []string
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, header.Name)
+	}
+	if len(provenance) != len(names) {
+		t.Errorf("recorded %d entries, want %d: %v", len(provenance), len(names), provenance)
+	}
+	for _, name := range names {
+		layer, ok := provenance[name]
+		if !ok {
+			t.Errorf("%s wasn't recorded", name)
+		} else if name == "foo.txt" && layer != 1 {
+			// The second layer overwrites foo.txt, and the third is empty.
+			t.Errorf("%s came from layer %d, want 1", name, layer)
+		}
+	}
+}
+
 // TestExtractError tests that if there are any errors encountered
 func TestExtractError(t *testing.T) {
 	rc := mutate.Extract(invalidImage{})
@@ -267,6 +301,55 @@ func TestMutateConfig(t *testing.T) {
 	}
 }
 
+// rawConfigImage is an image with no layers and the given raw config.
+type rawConfigImage string
+
+func (r rawConfigImage) RawConfigFile() ([]byte, error) { return []byte(r), nil }
+func (rawConfigImage) MediaType() (types.MediaType, error) {
+	return types.DockerManifestSchema2, nil
+}
+func (rawConfigImage) LayerByDiffID(h v1.Hash) (partial.UncompressedLayer, error) {
+	return nil, fmt.Errorf("no layer %s", h)
+}
+
+func TestMutateConfigExtensions(t *testing.T) {
+	source, err := partial.UncompressedToImage(rawConfigImage(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]},"config":{},"container_config":{"Hostname":"builder"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, err := random.Layer(100, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := mutate.Config(source, v1.Config{Env: []string{"foo=bar"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err = mutate.AppendLayers(result, layer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cf, err := result.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cc v1.Config
+	if ok, err := cf.Extension("container_config", &cc); err != nil || !ok {
+		t.Fatalf("Extension(container_config) = %t, %v, want it kept", ok, err)
+	}
+	if cc.Hostname != "builder" {
+		t.Errorf("container_config Hostname = %q, want %q", cc.Hostname, "builder")
+	}
+	raw, err := result.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(raw), `"container_config":{"Hostname":"builder"}`) {
+		t.Errorf("RawConfigFile() = %s, want container_config kept", raw)
+	}
+}
+
 type arbitrary struct {
 }
 
@@ -307,6 +390,56 @@ func TestAnnotations(t *testing.T) {
 	}
 }
 
+func TestArtifactType(t *testing.T) {
+	const at = "application/vnd.example.sbom"
+	subject := v1.Descriptor{
+		MediaType: types.OCIManifestSchema1,
+		Size:      123,
+		Digest:    v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("a", 64)},
+	}
+
+	img := mutate.ArtifactType(mutate.Subject(mutate.MediaType(empty.Image, types.OCIManifestSchema1), subject), at).(v1.Image)
+	// Later mutations keep the subject and artifactType.
+	img = mutate.Annotations(img, map[string]string{"foo": "bar"}).(v1.Image)
+	m, err := img.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ArtifactType != at {
+		t.Errorf("ArtifactType = %q, want %q", m.ArtifactType, at)
+	}
+	if m.Subject == nil || m.Subject.Digest != subject.Digest {
+		t.Errorf("Subject = %v, want %v", m.Subject, subject)
+	}
+	desc, err := partial.Descriptor(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.ArtifactType != at {
+		t.Errorf("Descriptor().ArtifactType = %q, want %q", desc.ArtifactType, at)
+	}
+
+	idx := mutate.ArtifactType(empty.Index, at).(v1.ImageIndex)
+	im, err := idx.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if im.ArtifactType != at {
+		t.Errorf("IndexManifest().ArtifactType = %q, want %q", im.ArtifactType, at)
+	}
+	if desc, err := partial.Descriptor(idx); err != nil || desc.ArtifactType != at {
+		t.Errorf("Descriptor(index) = %v, %v; want artifactType %q", desc, err, at)
+	}
+
+	got, err := mutate.ArtifactType(arbitrary{}, at).RawManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"artifactType":"application/vnd.example.sbom","hello":"world"}`; string(got) != want {
+		t.Errorf("RawManifest() = %s, want %s", got, want)
+	}
+}
+
 func TestMutateCreatedAt(t *testing.T) {
 	source := sourceImage(t)
 	want := time.Now().Add(-2 * time.Minute)
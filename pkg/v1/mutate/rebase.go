@@ -19,6 +19,7 @@ import (
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/empty"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // Rebase returns a new v1.Image where the oldBase in orig is replaced by newBase.
@@ -64,6 +65,9 @@ func Rebase(orig, oldBase, newBase v1.Image) (v1.Image, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not get config for new base: %w", err)
 	}
+	if err := samePlatform(oldConfig, newConfig); err != nil {
+		return nil, err
+	}
 
 	// Stitch together an image that contains:
 	// - original image's config
@@ -111,6 +115,52 @@ func Rebase(orig, oldBase, newBase v1.Image) (v1.Image, error) {
 	return rebasedImage, nil
 }
 
+// samePlatform checks that a new base doesn't change the platform of the
+// image, which would leave the original layers running on a platform they
+// weren't built for. Unset fields are ignored.
+func samePlatform(oldConfig, newConfig *v1.ConfigFile) error {
+	for _, f := range []struct{ name, old, new string }{
+		{"OS", oldConfig.OS, newConfig.OS},
+		{"architecture", oldConfig.Architecture, newConfig.Architecture},
+		{"variant", oldConfig.Variant, newConfig.Variant},
+	} {
+		if f.old != "" && f.new != "" && f.old != f.new {
+			return fmt.Errorf("new base %s %q does not match old base %s %q", f.name, f.new, f.name, f.old)
+		}
+	}
+	return nil
+}
+
+// BaseImage returns the name and digest of img's base image, as recorded in
+// its org.opencontainers.image.base.name and base.digest annotations. This
+// allows an image to be rebased without knowing its old base ahead of time.
+func BaseImage(img v1.Image) (string, v1.Hash, error) {
+	m, err := img.Manifest()
+	if err != nil {
+		return "", v1.Hash{}, err
+	}
+	base, digest, err := v1.Annotations(m.Annotations).BaseImage()
+	if err != nil {
+		return "", v1.Hash{}, err
+	}
+	if base == "" {
+		return "", v1.Hash{}, fmt.Errorf("image has no %q annotation", imagespec.AnnotationBaseImageName)
+	}
+	if digest == (v1.Hash{}) {
+		return "", v1.Hash{}, fmt.Errorf("image has no %q annotation", imagespec.AnnotationBaseImageDigest)
+	}
+	return base, digest, nil
+}
+
+// WithBaseImage annotates img as being based on the image named base with
+// the given digest, so that BaseImage can find it later.
+func WithBaseImage(img v1.Image, base string, digest v1.Hash) v1.Image {
+	return Annotations(img, map[string]string{
+		imagespec.AnnotationBaseImageName:   base,
+		imagespec.AnnotationBaseImageDigest: digest.String(),
+	}).(v1.Image)
+}
+
 // createAddendums makes a list of addendums from a history and layers starting from a specific history and layer
 // indexes.
 func createAddendums(startHistory, startLayer int, history []v1.History, layers []v1.Layer) []Addendum {
@@ -177,3 +177,58 @@ func TestRebase(t *testing.T) {
 		t.Errorf("ConfigFile property OSVersion mismatch, got %q, want %q", rebasedConfig.OSVersion, newBaseConfig.OSVersion)
 	}
 }
+
+func TestRebasePlatformMismatch(t *testing.T) {
+	withArch := func(arch string) v1.Image {
+		img, err := random.Image(100, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf, err := img.ConfigFile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		cf.OS, cf.Architecture = "linux", arch
+		img, err = mutate.ConfigFile(img, cf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+	oldBase, newBase := withArch("amd64"), withArch("arm64")
+
+	if _, err := mutate.Rebase(oldBase, oldBase, newBase); err == nil {
+		t.Error("Rebase onto a base of another architecture: expected error")
+	}
+}
+
+func TestBaseImage(t *testing.T) {
+	img, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := mutate.BaseImage(img); err == nil {
+		t.Error("BaseImage without annotations: expected error")
+	}
+
+	base, err := random.Image(100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := base.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = mutate.WithBaseImage(img, "example.com/base:latest", want)
+
+	name, got, err := mutate.BaseImage(img)
+	if err != nil {
+		t.Fatalf("BaseImage: %v", err)
+	}
+	if name != "example.com/base:latest" {
+		t.Errorf("BaseImage name: got %q, want %q", name, "example.com/base:latest")
+	}
+	if got != want {
+		t.Errorf("BaseImage digest: got %s, want %s", got, want)
+	}
+}
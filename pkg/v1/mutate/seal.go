@@ -0,0 +1,246 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutate
+
+import (
+	"bytes"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Seal eagerly computes the manifest, config and layer metadata of img and
+// returns an immutable snapshot that serves them from memory.
+//
+// Each call on a chain of mutations may recompute digests of everything
+// beneath it; callers that query an image many times (e.g. servers) can Seal
+// it once instead. Layer contents are not read, and are still fetched from
+// img's layers on demand.
+//
+// If img contains a stream.Layer that has not been consumed yet, Seal returns
+// stream.ErrNotComputed.
+func Seal(img v1.Image) (v1.Image, error) {
+	if s, ok := img.(*sealedImage); ok {
+		return s, nil
+	}
+
+	s := &sealedImage{
+		base:     img,
+		byDigest: map[v1.Hash]v1.Layer{},
+		byDiffID: map[v1.Hash]v1.Layer{},
+	}
+	var err error
+	if s.mediaType, err = img.MediaType(); err != nil {
+		return nil, err
+	}
+	if s.rawManifest, err = img.RawManifest(); err != nil {
+		return nil, err
+	}
+	if s.manifest, err = v1.ParseManifest(bytes.NewReader(s.rawManifest)); err != nil {
+		return nil, err
+	}
+	if s.digest, s.size, err = v1.SHA256(bytes.NewReader(s.rawManifest)); err != nil {
+		return nil, err
+	}
+	if s.rawConfig, err = img.RawConfigFile(); err != nil {
+		return nil, err
+	}
+	// Artifacts may have configs that aren't a ConfigFile, so only surface
+	// this error to callers of ConfigFile.
+	s.configFile, s.configErr = v1.ParseConfigFile(bytes.NewReader(s.rawConfig))
+	if s.desc, err = partial.Descriptor(img); err != nil {
+		return nil, err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range layers {
+		sl, err := sealLayer(l)
+		if err != nil {
+			return nil, err
+		}
+		s.layers = append(s.layers, sl)
+		s.byDigest[sl.digest] = sl
+		if sl.diffIDErr == nil {
+			s.byDiffID[sl.diffID] = sl
+		}
+	}
+	return s, nil
+}
+
+type sealedImage struct {
+	base v1.Image
+
+	mediaType   types.MediaType
+	rawManifest []byte
+	manifest    *v1.Manifest
+	digest      v1.Hash
+	size        int64
+	rawConfig   []byte
+	configFile  *v1.ConfigFile
+	configErr   error
+	desc        *v1.Descriptor
+	layers      []v1.Layer
+	byDigest    map[v1.Hash]v1.Layer
+	byDiffID    map[v1.Hash]v1.Layer
+}
+
+var _ v1.Image = (*sealedImage)(nil)
+
+// MediaType implements v1.Image.
+func (s *sealedImage) MediaType() (types.MediaType, error) {
+	return s.mediaType, nil
+}
+
+// Size implements v1.Image.
+func (s *sealedImage) Size() (int64, error) {
+	return s.size, nil
+}
+
+// Digest implements v1.Image.
+func (s *sealedImage) Digest() (v1.Hash, error) {
+	return s.digest, nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (s *sealedImage) Descriptor() (*v1.Descriptor, error) {
+	return s.desc.DeepCopy(), nil
+}
+
+// Manifest implements v1.Image.
+func (s *sealedImage) Manifest() (*v1.Manifest, error) {
+	return s.manifest.DeepCopy(), nil
+}
+
+// RawManifest implements v1.Image.
+func (s *sealedImage) RawManifest() ([]byte, error) {
+	return bytes.Clone(s.rawManifest), nil
+}
+
+// ConfigName implements v1.Image.
+func (s *sealedImage) ConfigName() (v1.Hash, error) {
+	return s.manifest.Config.Digest, nil
+}
+
+// ConfigFile implements v1.Image.
+func (s *sealedImage) ConfigFile() (*v1.ConfigFile, error) {
+	if s.configErr != nil {
+		return nil, s.configErr
+	}
+	return s.configFile.DeepCopy(), nil
+}
+
+// RawConfigFile implements v1.Image.
+func (s *sealedImage) RawConfigFile() ([]byte, error) {
+	return bytes.Clone(s.rawConfig), nil
+}
+
+// Layers implements v1.Image.
+func (s *sealedImage) Layers() ([]v1.Layer, error) {
+	return append([]v1.Layer{}, s.layers...), nil
+}
+
+// LayerByDigest implements v1.Image.
+func (s *sealedImage) LayerByDigest(h v1.Hash) (v1.Layer, error) {
+	if h == s.manifest.Config.Digest {
+		return partial.ConfigLayer(s)
+	}
+	if l, ok := s.byDigest[h]; ok {
+		return l, nil
+	}
+	return s.base.LayerByDigest(h)
+}
+
+// LayerByDiffID implements v1.Image.
+func (s *sealedImage) LayerByDiffID(h v1.Hash) (v1.Layer, error) {
+	if l, ok := s.byDiffID[h]; ok {
+		return l, nil
+	}
+	return s.base.LayerByDiffID(h)
+}
+
+// sealedLayer serves a layer's metadata from memory, deferring to the
+// underlying layer for its contents.
+//
+// Embedding v1.Layer only promotes its methods, so the optional interfaces
+// that callers check for (partial.Descriptor, partial.UncompressedSize and
+// partial.Exists) are forwarded explicitly below, and Unwrap exposes the
+// underlying layer to callers that look for its concrete type, e.g. so that
+// remote.Write can still mount a remote.MountableLayer.
+type sealedLayer struct {
+	v1.Layer
+
+	digest    v1.Hash
+	diffID    v1.Hash
+	diffIDErr error
+	size      int64
+	mediaType types.MediaType
+	desc      *v1.Descriptor
+}
+
+func sealLayer(l v1.Layer) (*sealedLayer, error) {
+	sl := &sealedLayer{Layer: l}
+	var err error
+	if sl.desc, err = partial.Descriptor(l); err != nil {
+		return nil, err
+	}
+	sl.digest, sl.size, sl.mediaType = sl.desc.Digest, sl.desc.Size, sl.desc.MediaType
+	// Foreign and non-tar layers may not have a meaningful diff ID.
+	sl.diffID, sl.diffIDErr = l.DiffID()
+	return sl, nil
+}
+
+// Digest implements v1.Layer.
+func (l *sealedLayer) Digest() (v1.Hash, error) {
+	return l.digest, nil
+}
+
+// DiffID implements v1.Layer.
+func (l *sealedLayer) DiffID() (v1.Hash, error) {
+	return l.diffID, l.diffIDErr
+}
+
+// Size implements v1.Layer.
+func (l *sealedLayer) Size() (int64, error) {
+	return l.size, nil
+}
+
+// MediaType implements v1.Layer.
+func (l *sealedLayer) MediaType() (types.MediaType, error) {
+	return l.mediaType, nil
+}
+
+// Descriptor implements partial.withDescriptor.
+func (l *sealedLayer) Descriptor() (*v1.Descriptor, error) {
+	return l.desc.DeepCopy(), nil
+}
+
+// UncompressedSize implements partial.WithUncompressedSize.
+func (l *sealedLayer) UncompressedSize() (int64, error) {
+	return partial.UncompressedSize(l.Layer)
+}
+
+// Exists implements partial.WithExists.
+func (l *sealedLayer) Exists() (bool, error) {
+	return partial.Exists(l.Layer)
+}
+
+// Unwrap returns the underlying layer.
+func (l *sealedLayer) Unwrap() v1.Layer {
+	return l.Layer
+}
@@ -0,0 +1,113 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mutate_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/stream"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// countingImage counts calls to RawManifest.
+type countingImage struct {
+	v1.Image
+	calls int
+}
+
+func (c *countingImage) RawManifest() ([]byte, error) {
+	c.calls++
+	return c.Image.RawManifest()
+}
+
+func TestSeal(t *testing.T) {
+	base, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.Config(base, v1.Config{Env: []string{"FOO=bar"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counted := &countingImage{Image: img}
+
+	sealed, err := mutate.Seal(counted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(sealed); err != nil {
+		t.Fatalf("validate.Image: %v", err)
+	}
+
+	calls := counted.calls
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		got, err := sealed.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Digest: got %s, want %s", got, want)
+		}
+	}
+	if counted.calls != calls {
+		t.Errorf("sealed image called RawManifest %d more times", counted.calls-calls)
+	}
+
+	// Callers can't modify the snapshot.
+	m, err := sealed.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Layers = nil
+	cf, err := sealed.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cf.Config.Env = nil
+	if err := validate.Image(sealed); err != nil {
+		t.Errorf("validate.Image after modification: %v", err)
+	}
+
+	// Sealing twice is a no-op.
+	again, err := mutate.Seal(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != sealed {
+		t.Errorf("Seal(sealed) returned a new image")
+	}
+}
+
+func TestSealStreamingLayer(t *testing.T) {
+	sl := stream.NewLayer(io.NopCloser(strings.NewReader("hello")))
+	img, err := mutate.AppendLayers(empty.Image, sl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mutate.Seal(img); !errors.Is(err, stream.ErrNotComputed) {
+		t.Errorf("Seal: got %v, want %v", err, stream.ErrNotComputed)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/google/go-containerregistry/internal/and"
 	"github.com/google/go-containerregistry/internal/compression"
 	"github.com/google/go-containerregistry/internal/gzip"
+	"github.com/google/go-containerregistry/internal/verify"
 	"github.com/google/go-containerregistry/internal/zstd"
 	comp "github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -98,6 +99,59 @@ func CompressedToLayer(ul CompressedLayer) (v1.Layer, error) {
 	return &compressedLayerExtender{ul}, nil
 }
 
+// Decompressed returns a v1.Layer for a compressed blob whose diffID is
+// already known, e.g. from the config of the image it came from, so that it
+// needn't be decompressed to compute it. open is called each time the
+// compressed contents are read, and desc gives the blob's digest, size and
+// media type (DockerLayer if empty), and any annotations or URLs.
+//
+// Reading the compressed contents verifies them against desc, so a layer can
+// be copied from one place to another in constant memory. Nothing checks that
+// diffID is right unless Uncompressed is read and hashed.
+func Decompressed(open func() (io.ReadCloser, error), desc v1.Descriptor, diffID v1.Hash) (v1.Layer, error) {
+	if desc.MediaType == "" {
+		desc.MediaType = types.DockerLayer
+	}
+	return CompressedToLayer(&decompressed{open: open, desc: desc, diffID: diffID})
+}
+
+// decompressed implements CompressedLayer, WithDiffID and describable for
+// Decompressed.
+type decompressed struct {
+	open   func() (io.ReadCloser, error)
+	desc   v1.Descriptor
+	diffID v1.Hash
+}
+
+func (d *decompressed) Digest() (v1.Hash, error) {
+	return d.desc.Digest, nil
+}
+
+func (d *decompressed) Compressed() (io.ReadCloser, error) {
+	rc, err := d.open()
+	if err != nil {
+		return nil, err
+	}
+	return verify.ReadCloser(rc, d.desc.Size, d.desc.Digest)
+}
+
+func (d *decompressed) Size() (int64, error) {
+	return d.desc.Size, nil
+}
+
+func (d *decompressed) MediaType() (types.MediaType, error) {
+	return d.desc.MediaType, nil
+}
+
+func (d *decompressed) DiffID() (v1.Hash, error) {
+	return d.diffID, nil
+}
+
+func (d *decompressed) Descriptor() (*v1.Descriptor, error) {
+	desc := d.desc
+	return &desc, nil
+}
+
 // CompressedImageCore represents the base minimum interface a natively
 // compressed image must implement for us to produce a v1.Image.
 type CompressedImageCore interface {
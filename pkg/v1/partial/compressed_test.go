@@ -15,6 +15,7 @@
 package partial_test
 
 import (
+	"bytes"
 	"io"
 	"net/http/httptest"
 	"net/url"
@@ -191,3 +192,67 @@ func TestCompressed(t *testing.T) {
 		t.Fatalf("partial.Descriptor: %v", err)
 	}
 }
+
+func TestDecompressed(t *testing.T) {
+	rl, err := random.Layer(1024, types.OCILayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := partial.Descriptor(rl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc.Annotations = map[string]string{"foo": "bar"}
+	diffID, err := rl.DiffID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := rl.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opens := 0
+	l, err := partial.Decompressed(func() (io.ReadCloser, error) {
+		opens++
+		return io.NopCloser(bytes.NewReader(b)), nil
+	}, *desc, diffID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := l.DiffID(); err != nil || got != diffID {
+		t.Errorf("DiffID() = %s, %v, want %s", got, err, diffID)
+	}
+	if opens != 0 {
+		t.Errorf("DiffID() opened the blob %d times, want 0", opens)
+	}
+	if got, err := partial.Descriptor(l); err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(desc, got); diff != "" {
+		t.Errorf("Descriptor() (-want +got) %s", diff)
+	}
+	if err := validate.Layer(l); err != nil {
+		t.Errorf("validate.Layer() = %v", err)
+	}
+
+	// Contents that don't match desc fail to read.
+	bad, err := partial.Decompressed(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b[:len(b)-1])), nil
+	}, *desc, diffID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err = bad.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if _, err := io.ReadAll(rc); err == nil {
+		t.Error("reading truncated blob succeeded, want error")
+	}
+}
@@ -0,0 +1,514 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partial
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+
+	// maxSymlinks bounds how many symlinks are followed resolving one path.
+	maxSymlinks = 255
+)
+
+// FS returns a read-only view of the filesystem of img, with its layers
+// flattened as a container runtime would: later layers replace files in
+// earlier ones, and whiteouts (including opaque whiteouts) hide them.
+//
+// The layers' headers are read the first time the filesystem is used, but
+// file contents are only read, from the layer that holds them, when an open
+// file is read. Symlinks are followed by Open and Stat, relative to the root
+// of the image. The returned filesystem also implements fs.StatFS,
+// fs.ReadDirFS and fs.ReadLinkFS (whose Lstat and ReadLink don't follow a
+// final symlink), and its files implement io.Seeker, so it can be used with
+// http.FS.
+func FS(img v1.Image) fs.FS {
+	return &imageFS{img: img}
+}
+
+type imageFS struct {
+	img v1.Image
+
+	once     sync.Once
+	layers   []v1.Layer
+	entries  map[string]*fsEntry
+	children map[string][]string
+	err      error
+}
+
+var (
+	_ fs.StatFS    = (*imageFS)(nil)
+	_ fs.ReadDirFS = (*imageFS)(nil)
+)
+
+// fsEntry is a file in the flattened filesystem.
+type fsEntry struct {
+	hdr *tar.Header
+
+	// path is where the entry is in the filesystem. It differs from
+	// hdr.Name only when the entry was reached through a symlink.
+	path string
+
+	// For regular files (including hard links to them), the contents are
+	// those of the entry named data in layers[layer].
+	layer int
+	data  string
+
+	// implied is set for directories that only exist because a layer has a
+	// whiteout in them, which a lower layer's entry for the directory
+	// replaces.
+	implied bool
+}
+
+func (e *fsEntry) Name() string               { return path.Base(e.hdr.Name) }
+func (e *fsEntry) Size() int64                { return e.hdr.Size }
+func (e *fsEntry) Mode() fs.FileMode          { return e.hdr.FileInfo().Mode() }
+func (e *fsEntry) ModTime() time.Time         { return e.hdr.ModTime }
+func (e *fsEntry) IsDir() bool                { return e.hdr.Typeflag == tar.TypeDir }
+func (e *fsEntry) Sys() any                   { return e.hdr }
+func (e *fsEntry) Type() fs.FileMode          { return e.Mode().Type() }
+func (e *fsEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+// cleanName normalizes a tar entry name to an fs.FS path.
+func cleanName(name string) string {
+	if name = strings.TrimPrefix(path.Clean("/"+name), "/"); name == "" {
+		return "."
+	}
+	return name
+}
+
+func (f *imageFS) init() error {
+	f.once.Do(func() {
+		f.err = f.index()
+	})
+	return f.err
+}
+
+// index reads the headers of every layer, from the top down, recording the
+// entries that are visible in the flattened filesystem.
+func (f *imageFS) index() error {
+	layers, err := f.img.Layers()
+	if err != nil {
+		return fmt.Errorf("retrieving image layers: %w", err)
+	}
+	f.layers = layers
+	f.entries = map[string]*fsEntry{
+		".": {hdr: &tar.Header{Name: ".", Typeflag: tar.TypeDir, Mode: 0o755}, path: "."},
+	}
+
+	// whiteouts and opaque hold the paths hidden from, and the directories
+	// whose contents are hidden from, the layers below the ones read so far.
+	whiteouts, opaque := map[string]bool{}, map[string]bool{}
+	hidden := func(name string) bool {
+		for p := name; p != "."; p = path.Dir(p) {
+			if whiteouts[p] {
+				return true
+			}
+			if p == name {
+				continue
+			}
+			if opaque[p] {
+				return true
+			}
+			if e, ok := f.entries[p]; ok && !e.IsDir() {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		var newWhiteouts, newOpaque []string
+		// files are this layer's regular files, for resolving hard links.
+		files := map[string]*tar.Header{}
+
+		if err := func() error {
+			rc, err := layers[i].Uncompressed()
+			if err != nil {
+				return fmt.Errorf("reading layer contents: %w", err)
+			}
+			defer rc.Close()
+
+			tr := tar.NewReader(rc)
+			for {
+				hdr, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("reading tar: %w", err)
+				}
+
+				name := cleanName(hdr.Name)
+				dir, base := path.Dir(name), path.Base(name)
+				if strings.HasPrefix(base, whiteoutPrefix) {
+					if base == opaqueWhiteout {
+						newOpaque = append(newOpaque, dir)
+					} else {
+						newWhiteouts = append(newWhiteouts, path.Join(dir, base[len(whiteoutPrefix):]))
+					}
+					// A whiteout's directory exists in this layer, even if
+					// the layer has no entry for it.
+					if _, ok := f.entries[dir]; !ok && !hidden(dir) {
+						f.entries[dir] = &fsEntry{hdr: &tar.Header{Name: dir, Typeflag: tar.TypeDir, Mode: 0o755}, path: dir, implied: true}
+					}
+					continue
+				}
+
+				e := &fsEntry{hdr: hdr, layer: i, data: name}
+				switch hdr.Typeflag {
+				case tar.TypeReg, tar.TypeRegA:
+					files[name] = hdr
+				case tar.TypeLink:
+					target, ok := files[cleanName(hdr.Linkname)]
+					if !ok {
+						// A link to something that isn't a regular file in
+						// this layer; keep it as is.
+						break
+					}
+					files[name] = target
+					e.data = cleanName(target.Name)
+					linked := *hdr
+					linked.Typeflag = tar.TypeReg
+					linked.Size = target.Size
+					linked.Linkname = ""
+					e.hdr = &linked
+				}
+
+				if name == "." {
+					continue
+				}
+				if old, ok := f.entries[name]; (ok && !(old.implied && hdr.Typeflag == tar.TypeDir)) || hidden(name) {
+					continue
+				}
+				h := *e.hdr
+				h.Name = name
+				e.hdr, e.path = &h, name
+				f.entries[name] = e
+			}
+		}(); err != nil {
+			return err
+		}
+
+		for _, p := range newWhiteouts {
+			whiteouts[p] = true
+		}
+		for _, p := range newOpaque {
+			opaque[p] = true
+		}
+	}
+
+	// Synthesize any parent directories that the layers omit, and list the
+	// children of every directory.
+	f.children = map[string][]string{}
+	names := make([]string, 0, len(f.entries))
+	for name := range f.entries {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		for p := name; p != "."; {
+			dir := path.Dir(p)
+			if _, ok := f.entries[dir]; !ok {
+				f.entries[dir] = &fsEntry{hdr: &tar.Header{Name: dir, Typeflag: tar.TypeDir, Mode: 0o755}, path: dir}
+				names = append(names, dir)
+			}
+			p = dir
+		}
+	}
+	for name := range f.entries {
+		if name != "." {
+			dir := path.Dir(name)
+			f.children[dir] = append(f.children[dir], name)
+		}
+	}
+	for _, c := range f.children {
+		sort.Strings(c)
+	}
+	return nil
+}
+
+// resolve returns the entry for name, following symlinks, including name
+// itself if follow is set.
+func (f *imageFS) resolve(op, name string, follow bool) (*fsEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if err := f.init(); err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	resolved := "."
+	parts := strings.Split(name, "/")
+	for links := 0; len(parts) != 0; {
+		p := parts[0]
+		parts = parts[1:]
+		switch p {
+		case "", ".":
+			continue
+		case "..":
+			resolved = path.Dir(resolved)
+			continue
+		}
+
+		next := path.Join(resolved, p)
+		e, ok := f.entries[next]
+		if !ok {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		if e.hdr.Typeflag == tar.TypeSymlink && (follow || len(parts) != 0) {
+			if links++; links > maxSymlinks {
+				return nil, &fs.PathError{Op: op, Path: name, Err: errors.New("too many levels of symbolic links")}
+			}
+			target := e.hdr.Linkname
+			if strings.HasPrefix(target, "/") {
+				resolved = "."
+			}
+			parts = append(strings.Split(target, "/"), parts...)
+			continue
+		}
+		if len(parts) != 0 && !e.IsDir() {
+			return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		resolved = next
+	}
+	e := f.entries[resolved]
+	if base := path.Base(name); base != path.Base(resolved) {
+		// Report the name that was asked for, not the symlink's target.
+		h := *e.hdr
+		h.Name = base
+		c := *e
+		c.hdr = &h
+		return &c, nil
+	}
+	return e, nil
+}
+
+// Open implements fs.FS.
+func (f *imageFS) Open(name string) (fs.File, error) {
+	e, err := f.resolve("open", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if e.IsDir() {
+		return &fsDir{fs: f, entry: e, path: name}, nil
+	}
+	return &fsFile{fs: f, entry: e, path: name}, nil
+}
+
+// Stat implements fs.StatFS.
+func (f *imageFS) Stat(name string) (fs.FileInfo, error) {
+	e, err := f.resolve("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Lstat implements fs.ReadLinkFS.
+func (f *imageFS) Lstat(name string) (fs.FileInfo, error) {
+	e, err := f.resolve("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ReadLink implements fs.ReadLinkFS.
+func (f *imageFS) ReadLink(name string) (string, error) {
+	e, err := f.resolve("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	if e.hdr.Typeflag != tar.TypeSymlink {
+		return "", &fs.PathError{Op: "readlink", Path: name, Err: fs.ErrInvalid}
+	}
+	return e.hdr.Linkname, nil
+}
+
+// ReadDir implements fs.ReadDirFS.
+func (f *imageFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	e, err := f.resolve("readdir", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsDir() {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return f.dirEntries(e), nil
+}
+
+func (f *imageFS) dirEntries(dir *fsEntry) []fs.DirEntry {
+	children := f.children[dir.path]
+	des := make([]fs.DirEntry, len(children))
+	for i, c := range children {
+		des[i] = f.entries[c]
+	}
+	return des
+}
+
+// fsDir is an open directory.
+type fsDir struct {
+	fs    *imageFS
+	entry *fsEntry
+	path  string
+
+	entries []fs.DirEntry
+	read    bool
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *fsDir) Close() error               { return nil }
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.path, Err: errors.New("is a directory")}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		d.entries, d.read = d.fs.dirEntries(d.entry), true
+	}
+	if n <= 0 {
+		des := d.entries
+		d.entries = nil
+		return des, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(d.entries))
+	des := d.entries[:n]
+	d.entries = d.entries[n:]
+	return des, nil
+}
+
+// fsFile is an open regular file (or other non-directory). Its contents are
+// read from its layer on demand, which means seeking backwards reads the
+// layer again from the start.
+type fsFile struct {
+	fs    *imageFS
+	entry *fsEntry
+	path  string
+
+	// off is where the next Read starts; pos is how far r has been read.
+	off, pos int64
+	rc       io.ReadCloser
+	r        io.Reader
+	closed   bool
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+
+func (f *fsFile) Read(b []byte) (int, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "read", Path: f.path, Err: fs.ErrClosed}
+	}
+	if f.off >= f.entry.hdr.Size {
+		return 0, io.EOF
+	}
+	if f.r == nil || f.pos > f.off {
+		if err := f.open(); err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.path, Err: err}
+		}
+	}
+	if f.pos < f.off {
+		n, err := io.CopyN(io.Discard, f.r, f.off-f.pos)
+		f.pos += n
+		if err != nil {
+			return 0, &fs.PathError{Op: "read", Path: f.path, Err: err}
+		}
+	}
+	n, err := f.r.Read(b)
+	f.pos += int64(n)
+	f.off = f.pos
+	return n, err
+}
+
+// open positions a new reader at the start of the file's contents.
+func (f *fsFile) open() error {
+	if f.rc != nil {
+		f.rc.Close()
+		f.rc, f.r = nil, nil
+	}
+	if f.entry.hdr.Typeflag != tar.TypeReg {
+		return errors.New("not a regular file")
+	}
+
+	rc, err := f.fs.layers[f.entry.layer].Uncompressed()
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			rc.Close()
+			if errors.Is(err, io.EOF) {
+				return fmt.Errorf("%s not found in layer", f.entry.data)
+			}
+			return err
+		}
+		if cleanName(hdr.Name) == f.entry.data && (hdr.Typeflag == tar.TypeReg || hdr.Typeflag == tar.TypeRegA) {
+			f.rc, f.r, f.pos = rc, io.LimitReader(tr, f.entry.hdr.Size), 0
+			return nil
+		}
+	}
+}
+
+// Seek implements io.Seeker.
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, &fs.PathError{Op: "seek", Path: f.path, Err: fs.ErrClosed}
+	}
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += f.entry.hdr.Size
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.path, Err: fs.ErrInvalid}
+	}
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.path, Err: fs.ErrInvalid}
+	}
+	f.off = offset
+	return offset, nil
+}
+
+func (f *fsFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.path, Err: fs.ErrClosed}
+	}
+	f.closed = true
+	if f.rc != nil {
+		return f.rc.Close()
+	}
+	return nil
+}
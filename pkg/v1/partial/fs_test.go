@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package partial_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// fsLayer returns a layer containing hdrs, each of which is a regular file
+// whose contents are its Linkname, unless it has some other type.
+func fsLayer(t *testing.T, hdrs ...tar.Header) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, hdr := range hdrs {
+		var contents []byte
+		if hdr.Typeflag == tar.TypeReg {
+			contents = []byte(hdr.Linkname)
+			hdr.Linkname, hdr.Size = "", int64(len(contents))
+		}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0o644
+		}
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(contents); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestFS(t *testing.T) {
+	img, err := mutate.AppendLayers(empty.Image,
+		fsLayer(t,
+			tar.Header{Name: "etc/", Typeflag: tar.TypeDir, Mode: 0o755},
+			tar.Header{Name: "etc/hostname", Typeflag: tar.TypeReg, Linkname: "old"},
+			tar.Header{Name: "etc/passwd", Typeflag: tar.TypeReg, Linkname: "root"},
+			tar.Header{Name: "etc/group", Typeflag: tar.TypeLink, Linkname: "etc/passwd"},
+			tar.Header{Name: "var/cache/a", Typeflag: tar.TypeReg, Linkname: "a"},
+			tar.Header{Name: "var/cache/b", Typeflag: tar.TypeReg, Linkname: "b"},
+			tar.Header{Name: "tmp/", Typeflag: tar.TypeDir, Mode: 0o1777},
+			tar.Header{Name: "tmp/gone/file", Typeflag: tar.TypeReg, Linkname: "gone"},
+		),
+		fsLayer(t,
+			tar.Header{Name: "./etc/hostname", Typeflag: tar.TypeReg, Linkname: "new"},
+			tar.Header{Name: "etc/.wh.passwd", Typeflag: tar.TypeReg},
+			tar.Header{Name: "var/cache/.wh..wh..opq", Typeflag: tar.TypeReg},
+			tar.Header{Name: "var/cache/c", Typeflag: tar.TypeReg, Linkname: "c"},
+			tar.Header{Name: "tmp/.wh.gone", Typeflag: tar.TypeReg},
+			tar.Header{Name: "cache", Typeflag: tar.TypeSymlink, Linkname: "/var/cache"},
+			tar.Header{Name: "etc/host", Typeflag: tar.TypeSymlink, Linkname: "../etc/./hostname"},
+		),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys := partial.FS(img)
+
+	// fstest doesn't understand symlinks to directories, so skip the root.
+	for dir, files := range map[string][]string{
+		"etc": {"hostname", "group", "host"},
+		"var": {"cache/c"},
+	} {
+		sub, err := fs.Sub(fsys, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := fstest.TestFS(sub, files...); err != nil {
+			t.Errorf("%s: %v", dir, err)
+		}
+	}
+
+	for name, want := range map[string]string{
+		"etc/hostname": "new",
+		"etc/group":    "root",
+		"etc/host":     "new",
+		"cache/c":      "c",
+	} {
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Errorf("ReadFile(%q): %v", name, err)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("ReadFile(%q) = %q, want %q", name, got, want)
+		}
+	}
+
+	for _, name := range []string{"etc/passwd", "var/cache/a", "tmp/gone", "tmp/gone/file", "etc/hostname/x"} {
+		if _, err := fs.Stat(fsys, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("Stat(%q) = %v, want ErrNotExist", name, err)
+		}
+	}
+
+	if fi, err := fs.Stat(fsys, "tmp"); err != nil {
+		t.Error(err)
+	} else if got, want := fi.Mode(), fs.ModeDir|fs.ModeSticky|0o777; got != want {
+		t.Errorf("tmp mode = %v, want %v", got, want)
+	}
+
+	// Lstat and ReadLink don't follow a final symlink. They are asserted
+	// with an interface, since fs.ReadLinkFS is newer than our go directive.
+	rlfs, ok := fsys.(interface {
+		Lstat(string) (fs.FileInfo, error)
+		ReadLink(string) (string, error)
+	})
+	if !ok {
+		t.Fatal("FS() doesn't implement Lstat and ReadLink")
+	}
+	if fi, err := rlfs.Lstat("etc/host"); err != nil {
+		t.Error(err)
+	} else if fi.Mode().Type() != fs.ModeSymlink {
+		t.Errorf("Lstat(etc/host) mode = %v, want a symlink", fi.Mode())
+	}
+	if fi, err := rlfs.Lstat("cache/c"); err != nil {
+		t.Error(err)
+	} else if !fi.Mode().IsRegular() {
+		t.Errorf("Lstat(cache/c) mode = %v, want a regular file", fi.Mode())
+	}
+	if target, err := rlfs.ReadLink("etc/host"); err != nil || target != "../etc/./hostname" {
+		t.Errorf("ReadLink(etc/host) = %q, %v, want %q", target, err, "../etc/./hostname")
+	}
+	if _, err := rlfs.ReadLink("etc/hostname"); !errors.Is(err, fs.ErrInvalid) {
+		t.Errorf("ReadLink(etc/hostname) = %v, want ErrInvalid", err)
+	}
+
+	var got []string
+	if err := fs.WalkDir(fsys, ".", func(path string, _ fs.DirEntry, err error) error {
+		got = append(got, path)
+		return err
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".", "cache", "etc", "etc/group", "etc/host", "etc/hostname", "tmp", "var", "var/cache", "var/cache/c"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WalkDir() (-want +got) = %s", diff)
+	}
+
+	// Serving needs Seek, to sniff the content type and then rewind.
+	s := httptest.NewServer(http.FileServer(http.FS(fsys)))
+	defer s.Close()
+	resp, err := http.Get(s.URL + "/etc/hostname")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || string(b) != "new" {
+		t.Errorf("GET /etc/hostname = %d %q, want 200 %q", resp.StatusCode, b, "new")
+	}
+}
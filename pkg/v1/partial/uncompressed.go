@@ -20,6 +20,7 @@ import (
 	"sync"
 
 	"github.com/google/go-containerregistry/internal/gzip"
+	"github.com/google/go-containerregistry/internal/zstd"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
@@ -54,6 +55,14 @@ func (ule *uncompressedLayerExtender) Compressed() (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	}
+	mt, err := ule.MediaType()
+	if err != nil {
+		u.Close()
+		return nil, err
+	}
+	if mt == types.OCILayerZStd {
+		return zstd.ReadCloser(u), nil
+	}
 	return gzip.ReadCloser(u), nil
 }
 
@@ -19,6 +19,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/google/go-containerregistry/internal/zstd"
 	legacy "github.com/google/go-containerregistry/pkg/legacy/tarball"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -231,3 +232,45 @@ func TestUncompressed(t *testing.T) {
 		t.Errorf("Exists() = %t != %t", got, want)
 	}
 }
+
+// zstdLayer is an uncompressed layer that should be compressed with zstd.
+type zstdLayer struct {
+	wrapped v1.Layer
+}
+
+func (l *zstdLayer) DiffID() (v1.Hash, error) {
+	return l.wrapped.DiffID()
+}
+
+func (l *zstdLayer) Uncompressed() (io.ReadCloser, error) {
+	return l.wrapped.Uncompressed()
+}
+
+func (l *zstdLayer) MediaType() (types.MediaType, error) {
+	return types.OCILayerZStd, nil
+}
+
+func TestUncompressedZstd(t *testing.T) {
+	rnd, err := random.Layer(1024, types.OCILayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, err := partial.UncompressedToLayer(&zstdLayer{rnd})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Layer(layer); err != nil {
+		t.Fatalf("validate.Layer: %v", err)
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if ok, err := zstd.Is(rc); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Error("Compressed() is not zstd")
+	}
+}
@@ -337,9 +337,20 @@ func Descriptor(d Describable) (*v1.Descriptor, error) {
 			mf, _ := Manifest(wrm)
 			// Failing to parse as a manifest should just be ignored.
 			// The manifest might not be valid, and that's okay.
-			if mf != nil && !mf.Config.MediaType.IsConfig() {
+			if mf != nil && mf.ArtifactType != "" {
+				desc.ArtifactType = mf.ArtifactType
+			} else if mf != nil && !mf.Config.MediaType.IsConfig() {
 				desc.ArtifactType = string(mf.Config.MediaType)
 			}
+		} else if ok && desc.MediaType.IsIndex() {
+			b, err := wrm.RawManifest()
+			if err != nil {
+				return nil, err
+			}
+			// As above, an invalid index just has no artifact type.
+			if im, err := v1.ParseIndexManifest(bytes.NewReader(b)); err == nil {
+				desc.ArtifactType = im.ArtifactType
+			}
 		}
 	}
 
@@ -350,18 +361,24 @@ type withArtifactType interface {
 	ArtifactType() (string, error)
 }
 
-type withUncompressedSize interface {
+// WithUncompressedSize is implemented by layers that know the size of their
+// uncompressed contents without reading them.
+//
+// Layers that wrap another v1.Layer should implement it by calling
+// UncompressedSize on the layer they wrap, so as not to hide its
+// implementation.
+type WithUncompressedSize interface {
 	UncompressedSize() (int64, error)
 }
 
 // UncompressedSize returns the size of the Uncompressed layer. If the
-// underlying implementation doesn't implement UncompressedSize directly,
+// underlying implementation doesn't implement WithUncompressedSize directly,
 // this will compute the uncompressedSize by reading everything returned
-// by Compressed(). This is potentially expensive and may consume the contents
-// for streaming layers.
+// by Uncompressed(). This is potentially expensive and may consume the
+// contents for streaming layers.
 func UncompressedSize(l v1.Layer) (int64, error) {
 	// If the layer implements UncompressedSize itself, return that.
-	if wus, ok := unwrap(l).(withUncompressedSize); ok {
+	if wus, ok := unwrap(l).(WithUncompressedSize); ok {
 		return wus.UncompressedSize()
 	}
 
@@ -375,7 +392,12 @@ func UncompressedSize(l v1.Layer) (int64, error) {
 	return io.Copy(io.Discard, rc)
 }
 
-type withExists interface {
+// WithExists is implemented by layers that can check that their contents
+// exist, e.g. with a HEAD request, without opening them.
+//
+// Layers that wrap another v1.Layer should implement it by calling Exists on
+// the layer they wrap, so as not to hide its implementation.
+type WithExists interface {
 	Exists() (bool, error)
 }
 
@@ -383,7 +405,7 @@ type withExists interface {
 // mistakes of the partial package. Don't use this.
 func Exists(l v1.Layer) (bool, error) {
 	// If the layer implements Exists itself, return that.
-	if we, ok := unwrap(l).(withExists); ok {
+	if we, ok := unwrap(l).(WithExists); ok {
 		return we.Exists()
 	}
 
@@ -421,7 +443,8 @@ func unwrap(i any) any {
 // ArtifactType returns the artifact type for the given manifest.
 //
 // If the manifest reports its own artifact type, that's returned, otherwise
-// the manifest is parsed and, if successful, its config.mediaType is returned.
+// the manifest is parsed and, if successful, its artifactType or
+// config.mediaType is returned.
 func ArtifactType(w WithManifest) (string, error) {
 	if wat, ok := w.(withArtifactType); ok {
 		return wat.ArtifactType()
@@ -429,6 +452,9 @@ func ArtifactType(w WithManifest) (string, error) {
 	mf, _ := w.Manifest()
 	// Failing to parse as a manifest should just be ignored.
 	// The manifest might not be valid, and that's okay.
+	if mf != nil && mf.ArtifactType != "" {
+		return mf.ArtifactType, nil
+	}
 	if mf != nil && !mf.Config.MediaType.IsConfig() {
 		return string(mf.Config.MediaType), nil
 	}
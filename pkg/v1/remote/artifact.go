@@ -0,0 +1,239 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// emptyJSON is the content of the OCI empty descriptor.
+// https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidance-for-an-empty-descriptor
+var emptyJSON = []byte("{}")
+
+// OCIArtifact is a generic OCI artifact: an OCI image manifest whose config
+// and layers may have arbitrary media types, e.g. a Helm chart or a WASM
+// module.
+//
+// Unlike a v1.Image, nothing about an OCIArtifact assumes its config is a
+// v1.ConfigFile or that its layers are tarballs.
+type OCIArtifact struct {
+	// ArtifactType is the type of the artifact. It is required if Config
+	// is nil.
+	ArtifactType types.MediaType
+
+	// Config is the config blob. If nil, the OCI empty descriptor is used.
+	Config v1.Layer
+
+	// Layers are the blobs that make up the artifact.
+	Layers []v1.Layer
+
+	// Annotations are set on the manifest.
+	Annotations map[string]string
+
+	// Subject, if set, is the manifest this artifact refers to.
+	Subject *v1.Descriptor
+
+	// raw is the manifest that Artifact read, and fetched the fields it was
+	// read into, so that an artifact that hasn't been changed since keeps
+	// the registry's manifest (and digest).
+	raw     []byte
+	fetched *OCIArtifact
+}
+
+var _ partial.Describable = (*OCIArtifact)(nil)
+
+func (a *OCIArtifact) config() (v1.Layer, error) {
+	if a.Config != nil {
+		return a.Config, nil
+	}
+	if a.ArtifactType == "" {
+		return nil, errors.New("artifact must have an ArtifactType if it has no Config")
+	}
+	return static.NewLayer(emptyJSON, types.OCIEmptyJSON), nil
+}
+
+// unchanged reports whether a was read by Artifact and hasn't been changed
+// since.
+func (a *OCIArtifact) unchanged() bool {
+	f := a.fetched
+	if f == nil || a.ArtifactType != f.ArtifactType || a.Config != f.Config || len(a.Layers) != len(f.Layers) {
+		return false
+	}
+	for i, l := range a.Layers {
+		if l != f.Layers[i] {
+			return false
+		}
+	}
+	return maps.Equal(a.Annotations, f.Annotations) && reflect.DeepEqual(a.Subject, f.Subject)
+}
+
+// Manifest returns the artifact's manifest.
+//
+// The manifest of an artifact read by Artifact is the registry's, unless the
+// artifact has been changed since. Otherwise, it is built from the fields,
+// and an empty config is embedded in it as data.
+func (a *OCIArtifact) Manifest() (*v1.Manifest, error) {
+	if a.unchanged() {
+		return v1.ParseManifest(bytes.NewReader(a.raw))
+	}
+	cl, err := a.config()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := partial.Descriptor(cl)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MediaType == types.OCIEmptyJSON {
+		cfg.Data = emptyJSON
+	}
+
+	m := &v1.Manifest{
+		SchemaVersion: 2,
+		MediaType:     types.OCIManifestSchema1,
+		ArtifactType:  string(a.ArtifactType),
+		Config:        *cfg,
+		Layers:        make([]v1.Descriptor, 0, len(a.Layers)),
+		Annotations:   a.Annotations,
+		Subject:       a.Subject,
+	}
+	for _, l := range a.Layers {
+		desc, err := partial.Descriptor(l)
+		if err != nil {
+			return nil, err
+		}
+		m.Layers = append(m.Layers, *desc)
+	}
+	return m, nil
+}
+
+// RawManifest implements Taggable.
+func (a *OCIArtifact) RawManifest() ([]byte, error) {
+	if a.unchanged() {
+		return bytes.Clone(a.raw), nil
+	}
+	m, err := a.Manifest()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+// MediaType implements partial.Describable.
+func (a *OCIArtifact) MediaType() (types.MediaType, error) {
+	return types.OCIManifestSchema1, nil
+}
+
+// Digest implements partial.Describable.
+func (a *OCIArtifact) Digest() (v1.Hash, error) {
+	b, err := a.RawManifest()
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	h, _, err := v1.SHA256(bytes.NewReader(b))
+	return h, err
+}
+
+// Size implements partial.Describable.
+func (a *OCIArtifact) Size() (int64, error) {
+	b, err := a.RawManifest()
+	if err != nil {
+		return -1, err
+	}
+	return int64(len(b)), nil
+}
+
+// Artifact reads the manifest at ref as a generic OCI artifact.
+//
+// The returned Config and Layers are lazily fetched from the registry.
+func Artifact(ref name.Reference, options ...Option) (*OCIArtifact, error) {
+	desc, err := Get(ref, options...)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MediaType.IsImage() {
+		return nil, fmt.Errorf("%s has media type %q, expected an image manifest", ref, desc.MediaType)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, err
+	}
+	m, err := v1.ParseManifest(bytes.NewReader(desc.Manifest))
+	if err != nil {
+		return nil, err
+	}
+
+	a := &OCIArtifact{
+		ArtifactType: types.MediaType(m.ArtifactType),
+		Annotations:  m.Annotations,
+		Subject:      m.Subject,
+		raw:          desc.Manifest,
+	}
+	// Without an artifactType, the config's media type is the artifact's
+	// type, so keep the config even if it's empty.
+	if m.Config.MediaType != types.OCIEmptyJSON || m.ArtifactType == "" {
+		if a.Config, err = partial.ConfigLayer(img); err != nil {
+			return nil, err
+		}
+	}
+	if a.Layers, err = img.Layers(); err != nil {
+		return nil, err
+	}
+	a.fetched = &OCIArtifact{
+		ArtifactType: a.ArtifactType,
+		Config:       a.Config,
+		Layers:       slices.Clone(a.Layers),
+		Annotations:  maps.Clone(a.Annotations),
+		Subject:      a.Subject.DeepCopy(),
+	}
+	return a, nil
+}
+
+// WriteArtifact pushes the artifact's blobs and manifest to ref.
+func WriteArtifact(ref name.Reference, a *OCIArtifact, options ...Option) error {
+	return Push(ref, a, options...)
+}
+
+// writeArtifactBlobs uploads an artifact's config and layers.
+func (rw *repoWriter) writeArtifactBlobs(pctx context.Context, a *OCIArtifact) error {
+	cl, err := a.config()
+	if err != nil {
+		return err
+	}
+
+	g, ctx := errgroup.WithContext(pctx)
+	g.SetLimit(rw.o.jobs)
+	for _, l := range append([]v1.Layer{cl}, a.Layers...) {
+		l := l
+		g.Go(func() error {
+			return rw.writeLayer(ctx, l)
+		})
+	}
+	return g.Wait()
+}
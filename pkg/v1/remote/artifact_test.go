@@ -0,0 +1,235 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestArtifact(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.WithReferrersSupport(true)))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgRef, err := name.ParseReference(fmt.Sprintf("%s/artifact/test:img", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(imgRef, img); err != nil {
+		t.Fatal(err)
+	}
+	subject, err := partial.Descriptor(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		name     string
+		artifact *OCIArtifact
+	}{{
+		name: "empty config",
+		artifact: &OCIArtifact{
+			ArtifactType: "application/vnd.example.wasm",
+			Layers: []v1.Layer{
+				static.NewLayer([]byte("\x00asm"), "application/wasm"),
+				static.NewLayer([]byte("hello"), "text/plain"),
+			},
+			Annotations: map[string]string{"foo": "bar"},
+			Subject:     subject,
+		},
+	}, {
+		name: "custom config",
+		artifact: &OCIArtifact{
+			Config: static.NewLayer([]byte(`{"name":"chart"}`), "application/vnd.cncf.helm.config.v1+json"),
+			Layers: []v1.Layer{
+				static.NewLayer([]byte("chart"), "application/vnd.cncf.helm.chart.content.v1.tar+gzip"),
+			},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ref, err := name.ParseReference(fmt.Sprintf("%s/artifact/test:%d", u.Host, i))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := WriteArtifact(ref, tc.artifact); err != nil {
+				t.Fatalf("WriteArtifact: %v", err)
+			}
+
+			got, err := Artifact(ref)
+			if err != nil {
+				t.Fatalf("Artifact: %v", err)
+			}
+			wantDigest, err := tc.artifact.Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotDigest, err := got.Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotDigest != wantDigest {
+				t.Errorf("Digest: got %s, want %s", gotDigest, wantDigest)
+			}
+			if got.ArtifactType != tc.artifact.ArtifactType {
+				t.Errorf("ArtifactType: got %q, want %q", got.ArtifactType, tc.artifact.ArtifactType)
+			}
+			if (got.Config == nil) != (tc.artifact.Config == nil) {
+				t.Errorf("Config: got %v, want %v", got.Config, tc.artifact.Config)
+			}
+			if len(got.Layers) != len(tc.artifact.Layers) {
+				t.Fatalf("Layers: got %d, want %d", len(got.Layers), len(tc.artifact.Layers))
+			}
+			for i, l := range got.Layers {
+				wantMT, err := tc.artifact.Layers[i].MediaType()
+				if err != nil {
+					t.Fatal(err)
+				}
+				gotMT, err := l.MediaType()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if gotMT != wantMT {
+					t.Errorf("Layers[%d].MediaType: got %q, want %q", i, gotMT, wantMT)
+				}
+				want := readAll(t, tc.artifact.Layers[i])
+				if d := cmp.Diff(want, readAll(t, l)); d != "" {
+					t.Errorf("Layers[%d] (-want +got): %s", i, d)
+				}
+			}
+
+			if tc.artifact.Subject == nil {
+				return
+			}
+			idx, err := Referrers(ref.Context().Digest(subject.Digest.String()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			im, err := idx.IndexManifest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(im.Manifests) != 1 {
+				t.Fatalf("Referrers: got %d, want 1", len(im.Manifests))
+			}
+			if got, want := im.Manifests[0].ArtifactType, string(tc.artifact.ArtifactType); got != want {
+				t.Errorf("Referrers ArtifactType: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func readAll(t *testing.T, l v1.Layer) []byte {
+	t.Helper()
+	rc, err := l.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// rawArtifact is a manifest pushed as is.
+type rawArtifact struct {
+	rawManifest
+}
+
+func (*rawArtifact) MediaType() (types.MediaType, error) {
+	return types.OCIManifestSchema1, nil
+}
+
+func TestArtifactKeepsManifest(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/artifact/raw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := static.NewLayer(emptyJSON, types.OCIEmptyJSON)
+	if err := WriteLayer(repo, empty); err != nil {
+		t.Fatal(err)
+	}
+	emptyDigest, err := empty.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An empty config without data, and no artifactType: the config's media
+	// type is the artifact's type.
+	raw := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"config":{"mediaType":%q,"digest":%q,"size":2},"layers":[]}`,
+		types.OCIManifestSchema1, types.OCIEmptyJSON, emptyDigest))
+	want, _, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := repo.Tag("raw")
+	if err := Put(ref, &rawArtifact{rawManifest{raw}}); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := Artifact(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := a.Digest(); err != nil || got != want {
+		t.Errorf("Digest() = %v, %v; want %v", got, err, want)
+	}
+	copied := repo.Tag("copied")
+	if err := WriteArtifact(copied, a); err != nil {
+		t.Fatal(err)
+	}
+	if desc, err := Head(copied); err != nil || desc.Digest != want {
+		t.Errorf("Head(copied) = %v, %v; want %v", desc, err, want)
+	}
+
+	// Changing the artifact builds a new manifest.
+	a.Annotations = map[string]string{"foo": "bar"}
+	m, err := a.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Annotations["foo"] != "bar" || m.Config.MediaType != types.OCIEmptyJSON {
+		t.Errorf("Manifest() = %+v, want the annotation and the empty config", m)
+	}
+}
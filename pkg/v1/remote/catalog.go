@@ -86,6 +86,42 @@ func Catalog(ctx context.Context, target name.Registry, options ...Option) ([]st
 	return newPuller(o).catalog(ctx, target, o.pageSize)
 }
 
+// CatalogWalk calls fn with each page of the repositories in the registry's
+// catalog, as a Catalogger returns them, so that registries with too many
+// repositories to hold in memory can be listed. It stops at the first error,
+// from the registry or from fn, and returns it, and it checks ctx before
+// requesting each page. Use WithPageSize to set how many repositories are
+// requested at once.
+func CatalogWalk(ctx context.Context, target name.Registry, fn func(repos []string) error, options ...Option) error {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return err
+	}
+
+	// WithContext overrides the ctx passed directly.
+	if o.context != context.Background() {
+		ctx = o.context
+	}
+
+	catalogger, err := newPuller(o).catalogger(ctx, target, o.pageSize)
+	if err != nil {
+		return err
+	}
+	for catalogger.HasNext() {
+		page, err := catalogger.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if err := fn(page.Repos); err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (f *fetcher) catalogPage(ctx context.Context, reg name.Registry, next string, pageSize int) (*Catalogs, error) {
 	if next == "" {
 		uri := &url.URL{
@@ -147,7 +183,12 @@ type Catalogger struct {
 
 func (l *Catalogger) Next(ctx context.Context) (*Catalogs, error) {
 	if l.needMore {
-		l.page, l.err = l.f.catalogPage(ctx, l.reg, l.page.Next, l.pageSize)
+		next := l.page.Next
+		l.page, l.err = l.f.catalogPage(ctx, l.reg, next, l.pageSize)
+		if l.err == nil && l.page.Next == next {
+			// Rather than paging forever.
+			l.page, l.err = nil, fmt.Errorf("registry %s returned a link to the same page of its catalog: %s", l.reg, next)
+		}
 	} else {
 		l.needMore = true
 	}
@@ -181,3 +181,85 @@ func TestCancelledCatalog(t *testing.T) {
 		t.Errorf("wanted %v got %v", want, got)
 	}
 }
+
+func TestCatalogWalk(t *testing.T) {
+	pages := map[string]string{
+		"/v2/_catalog":       `{"repositories":["a","b"]}`,
+		"/v2/_catalog_two":   `{"repositories":["c"]}`,
+		"/v2/_catalog_three": `{"repositories":["d"]}`,
+		"/v2/_catalog_loop":  `{"repositories":["e"]}`,
+	}
+	links := map[string]string{
+		"/v2/_catalog":      "/v2/_catalog_two",
+		"/v2/_catalog_two":  "/v2/_catalog_three",
+		"/v2/_catalog_loop": "/v2/_catalog_loop",
+	}
+	var requested []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			t.Fatalf("Unexpected path: %v", r.URL.Path)
+		}
+		requested = append(requested, r.URL.Path)
+		if next, ok := links[r.URL.Path]; ok {
+			w.Header().Set("Link", fmt.Sprintf("<%s>", next))
+		}
+		w.Write([]byte(page))
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse(%v) = %v", server.URL, err)
+	}
+	reg, err := name.NewRegistry(u.Host)
+	if err != nil {
+		t.Fatalf("name.NewRegistry(%v) = %v", u.Host, err)
+	}
+
+	var got [][]string
+	if err := CatalogWalk(context.Background(), reg, func(repos []string) error {
+		got = append(got, repos)
+		return nil
+	}); err != nil {
+		t.Fatalf("CatalogWalk() = %v", err)
+	}
+	if diff := cmp.Diff([][]string{{"a", "b"}, {"c"}, {"d"}}, got); diff != "" {
+		t.Errorf("CatalogWalk() wrong pages (-want +got) = %s", diff)
+	}
+
+	// Errors from fn stop the walk.
+	requested = nil
+	errStop := errors.New("stop")
+	if err := CatalogWalk(context.Background(), reg, func([]string) error {
+		return errStop
+	}); !errors.Is(err, errStop) {
+		t.Errorf("CatalogWalk() = %v, want %v", err, errStop)
+	}
+	if len(requested) != 1 {
+		t.Errorf("requested %v after fn failed, want only the first page", requested)
+	}
+
+	// So does cancelling the context between pages.
+	requested = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := CatalogWalk(ctx, reg, func([]string) error {
+		cancel()
+		return nil
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CatalogWalk() = %v, want %v", err, context.Canceled)
+	}
+	if len(requested) != 1 {
+		t.Errorf("requested %v after cancelling, want only the first page", requested)
+	}
+
+	// A page that links to itself is an error, rather than an endless walk.
+	pages["/v2/_catalog"], links["/v2/_catalog"] = `{"repositories":[]}`, "/v2/_catalog_loop"
+	if err := CatalogWalk(context.Background(), reg, func([]string) error { return nil }); err == nil {
+		t.Error("CatalogWalk() of a looping catalog succeeded, want an error")
+	}
+}
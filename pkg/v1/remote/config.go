@@ -0,0 +1,58 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Config fetches the config file of the image ref, returning it parsed along
+// with its raw bytes.
+//
+// This takes one request for the manifest and one for the config blob, plus
+// one more to select a child manifest by platform (see WithPlatform) if ref
+// points to an index. Layers are never accessed.
+func Config(ref name.Reference, options ...Option) (*v1.ConfigFile, []byte, error) {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newPuller(o).Config(o.context, ref)
+}
+
+// Config is like remote.Config, but avoids re-authenticating when possible.
+func (p *Puller) Config(ctx context.Context, ref name.Reference) (*v1.ConfigFile, []byte, error) {
+	desc, err := p.Get(ctx, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := img.RawConfigFile()
+	if err != nil {
+		return nil, nil, err
+	}
+	cf, err := v1.ParseConfigFile(bytes.NewReader(raw))
+	if err != nil {
+		return nil, nil, err
+	}
+	return cf, raw, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestConfig(t *testing.T) {
+	var requests atomic.Int32
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v2/test/") {
+			requests.Add(1)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	platform := v1.Platform{OS: "linux", Architecture: "arm64"}
+	idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add:        img,
+		Descriptor: v1.Descriptor{Platform: &platform},
+	})
+
+	imgRef, err := name.ParseReference(fmt.Sprintf("%s/test:image", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(imgRef, img); err != nil {
+		t.Fatal(err)
+	}
+	idxRef, err := name.ParseReference(fmt.Sprintf("%s/test:index", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteIndex(idxRef, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name         string
+		ref          name.Reference
+		wantRequests int32
+	}{{
+		name:         "image",
+		ref:          imgRef,
+		wantRequests: 2,
+	}, {
+		name:         "index",
+		ref:          idxRef,
+		wantRequests: 3,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			requests.Store(0)
+			cf, raw, err := Config(tc.ref, WithPlatform(platform))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(raw, want) {
+				t.Errorf("Config() raw = %s, want %s", raw, want)
+			}
+			if len(cf.RootFS.DiffIDs) != 3 {
+				t.Errorf("Config() has %d diff ids, want 3", len(cf.RootFS.DiffIDs))
+			}
+			if got := requests.Load(); got != tc.wantRequests {
+				t.Errorf("Config() made %d requests, want %d", got, tc.wantRequests)
+			}
+		})
+	}
+}
@@ -134,6 +134,17 @@ func (d *Descriptor) Image() (v1.Image, error) {
 	}, nil
 }
 
+// Images returns the images in the index the Descriptor describes, in order,
+// without fetching their manifests until they are needed. They share the
+// Descriptor's transport, so reading them doesn't authenticate again.
+// Children that are indexes are skipped.
+func (d *Descriptor) Images() ([]v1.Image, error) {
+	if !d.MediaType.IsIndex() {
+		return nil, fmt.Errorf("%s is not an index: %s", d.ref, d.MediaType)
+	}
+	return d.remoteIndex().Images()
+}
+
 // Schema1 converts the Descriptor into a v1.Image for v2 schema 1 media types.
 //
 // The v1.Image returned by this method does not implement the entire interface because it would be inefficient.
@@ -0,0 +1,189 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// writerAt is an io.WriterAt that isn't an io.ReaderAt.
+type writerAt struct {
+	b []byte
+}
+
+func (w *writerAt) WriteAt(p []byte, off int64) (int, error) {
+	if need := off + int64(len(p)); need > int64(len(w.b)) {
+		w.b = append(w.b, make([]byte, need-int64(len(w.b)))...)
+	}
+	return copy(w.b[off:], p), nil
+}
+
+// corruptWriter flips the first byte written to it.
+type corruptWriter struct {
+	http.ResponseWriter
+	done bool
+}
+
+func (c *corruptWriter) Write(p []byte) (int, error) {
+	if !c.done && len(p) > 0 {
+		c.done = true
+		p = append([]byte{^p[0]}, p[1:]...)
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+func TestDownload(t *testing.T) {
+	var gets, ranges atomic.Int32
+	var noRanges, corrupt atomic.Bool
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") {
+			gets.Add(1)
+			if noRanges.Load() {
+				r.Header.Del("Range")
+			}
+			if r.Header.Get("Range") != "" {
+				ranges.Add(1)
+				if corrupt.Load() && strings.HasPrefix(r.Header.Get("Range"), "bytes=0-") {
+					w = &corruptWriter{ResponseWriter: w}
+				}
+			}
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layer, err := random.Layer(3*minChunkSize, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/test/download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteLayer(repo, layer); err != nil {
+		t.Fatal(err)
+	}
+	h, err := layer.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := layer.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := repo.Digest(h.String())
+
+	download := func(t *testing.T, w io.WriterAt, wantGets, wantRanges int32, options ...Option) error {
+		t.Helper()
+		gets.Store(0)
+		ranges.Store(0)
+		err := Download(ref, w, options...)
+		if got := gets.Load(); got != wantGets {
+			t.Errorf("GET requests: got %d, want %d", got, wantGets)
+		}
+		if got := ranges.Load(); got != wantRanges {
+			t.Errorf("range requests: got %d, want %d", got, wantRanges)
+		}
+		return err
+	}
+	file := func(t *testing.T) *os.File {
+		f, err := os.Create(filepath.Join(t.TempDir(), "blob"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+	check := func(t *testing.T, f *os.File) {
+		t.Helper()
+		got, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("downloaded %d bytes, not the %d bytes of the blob", len(got), len(want))
+		}
+	}
+
+	t.Run("single request", func(t *testing.T) {
+		f := file(t)
+		if err := download(t, f, 1, 0); err != nil {
+			t.Fatal(err)
+		}
+		check(t, f)
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		f := file(t)
+		if err := download(t, f, 4, 4, WithDownloadChunks(8)); err != nil {
+			t.Fatal(err)
+		}
+		check(t, f)
+	})
+
+	t.Run("not a ReaderAt", func(t *testing.T) {
+		w := &writerAt{}
+		if err := download(t, w, 1, 0, WithDownloadChunks(8)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(w.b, want) {
+			t.Error("downloaded blob does not match")
+		}
+	})
+
+	t.Run("ranges unsupported", func(t *testing.T) {
+		noRanges.Store(true)
+		defer noRanges.Store(false)
+		f := file(t)
+		if err := Download(ref, f, WithDownloadChunks(8)); err != nil {
+			t.Fatal(err)
+		}
+		check(t, f)
+	})
+
+	t.Run("corrupt", func(t *testing.T) {
+		corrupt.Store(true)
+		defer corrupt.Store(false)
+		var merr *BlobMismatchError
+		if err := download(t, file(t), 4, 4, WithDownloadChunks(8)); !errors.As(err, &merr) {
+			t.Fatalf("expected *BlobMismatchError, got %v", err)
+		}
+	})
+}
@@ -17,6 +17,7 @@ package remote_test
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
@@ -125,3 +126,59 @@ func TestBlobStatusCodeReturned(t *testing.T) {
 		t.Errorf("Incorrect status code received, got %v, wanted %v", terr.StatusCode, http.StatusTeapot)
 	}
 }
+
+func TestTypedErrors(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u := strings.TrimPrefix(s.URL, "http://")
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.NewTag(u + "/foo:bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		ref       string
+		isUnknown func(error) bool
+	}{{
+		ref:       u + "/foo:missing",
+		isUnknown: transport.IsManifestUnknown,
+	}, {
+		ref:       u + "/missing:bar",
+		isUnknown: transport.IsNameUnknown,
+	}} {
+		r, err := name.ParseReference(tc.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = remote.Image(r)
+		if !errors.Is(err, remote.ErrNotFound) {
+			t.Errorf("remote.Image(%s) = %v, want remote.ErrNotFound", tc.ref, err)
+		}
+		if errors.Is(err, remote.ErrUnauthorized) {
+			t.Errorf("remote.Image(%s) = %v, want not remote.ErrUnauthorized", tc.ref, err)
+		}
+		if !tc.isUnknown(err) {
+			t.Errorf("remote.Image(%s) = %v, want a different code", tc.ref, err)
+		}
+		// HEAD responses have no codes, but still match by status.
+		if _, err := remote.Head(r); !errors.Is(err, remote.ErrNotFound) {
+			t.Errorf("remote.Head(%s) = %v, want remote.ErrNotFound", tc.ref, err)
+		}
+	}
+
+	l, err := remote.Layer(ref.Context().Digest("sha256:" + strings.Repeat("0", 64)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Compressed(); !errors.Is(err, remote.ErrNotFound) || !transport.IsBlobUnknown(err) {
+		t.Errorf("Compressed() = %v, want remote.ErrNotFound with BLOB_UNKNOWN", err)
+	}
+}
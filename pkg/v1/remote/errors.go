@@ -0,0 +1,92 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// Errors returned by this package for failed requests match these with
+// errors.Is, e.g.
+//
+//	if _, err := remote.Image(ref); errors.Is(err, remote.ErrNotFound) {
+//		// ...
+//	}
+//
+// See transport.Error for the details of a failed request, and
+// transport.IsManifestUnknown and friends to tell apart the reasons a
+// registry gives for them.
+var (
+	// ErrNotFound is returned when a blob, manifest or repository doesn't
+	// exist.
+	ErrNotFound = transport.ErrNotFound
+	// ErrUnauthorized is returned when the registry needs credentials, or
+	// rejects the ones it was given.
+	ErrUnauthorized = transport.ErrUnauthorized
+	// ErrDenied is returned when credentials don't allow a request.
+	ErrDenied = transport.ErrDenied
+	// ErrRateLimited is returned when the registry is limiting requests.
+	ErrRateLimited = transport.ErrRateLimited
+	// ErrUnsupported is returned when the registry doesn't support a request.
+	ErrUnsupported = transport.ErrUnsupported
+)
+
+// ErrTagListingUnsupported is returned when listing the tags of a repository
+// fails because the registry doesn't support it there: it answered
+// /tags/list with 405 Method Not Allowed, 501 Not Implemented or the
+// UNSUPPORTED code, as registries that only allow pushing to some
+// repositories do. Such errors also match ErrUnsupported, where the response
+// did, and wrap the *transport.Error.
+//
+// Callers that know which tags they want, e.g. from a file, can fall back to
+// using them instead.
+var ErrTagListingUnsupported = errors.New("tag listing unsupported")
+
+// ManifestRewrittenError describes a registry storing a pushed manifest under
+// a different digest than the one pushed, e.g. because it converted the
+// manifest to another media type. Signatures, attestations and other
+// references to the pushed digest don't match what the registry serves.
+//
+// Pushes return it when digest verification is enabled (see
+// WithDigestVerification), and otherwise log it as a warning.
+type ManifestRewrittenError struct {
+	// Ref is the reference the manifest was pushed to.
+	Ref name.Reference
+	// Pushed and MediaType are the digest and media type of the manifest
+	// that was pushed.
+	Pushed    v1.Hash
+	MediaType types.MediaType
+	// Stored is the digest that the registry says it stored the manifest as.
+	Stored v1.Hash
+	// StoredMediaType is the media type of the stored manifest, if the
+	// registry serves it.
+	StoredMediaType types.MediaType
+}
+
+// Error implements error.
+func (e *ManifestRewrittenError) Error() string {
+	stored := e.Stored.String()
+	if e.StoredMediaType != "" && e.StoredMediaType != e.MediaType {
+		stored = fmt.Sprintf("%s (%s)", stored, e.StoredMediaType)
+	}
+	return fmt.Sprintf("registry rewrote manifest %s (%s) pushed to %s as %s; references to %s, such as signatures, won't match it",
+		e.Pushed, e.MediaType, e.Ref, stored, e.Pushed)
+}
@@ -0,0 +1,121 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// BlobExists reports whether the blob with digest h is in repo, using a
+// single HEAD request with pull access.
+//
+// This is the check that Write does before uploading a blob, so it can be
+// used to skip work that a push would skip anyway. Unlike reads, it ignores
+// any mirrors (see WithMirrors), since they say nothing about repo.
+func BlobExists(repo name.Repository, h v1.Hash, options ...Option) (bool, error) {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return false, err
+	}
+	return newPuller(o).BlobExists(o.context, repo, h)
+}
+
+// Exists reports whether ref, a tag or digest, is in its repository, and if
+// so returns the descriptor (digest, size and media type) it points to. It
+// uses a HEAD request, like Head, so it doesn't download the manifest; unlike
+// Head, it isn't an error for ref not to exist.
+//
+// If WithPlatform is passed and ref is an index, Exists instead reports
+// whether the index has a child for that platform, and returns the child's
+// descriptor. That takes one GET of the index, since its children can't be
+// learned from a HEAD request. Without WithPlatform, indexes aren't
+// inspected.
+func Exists(ref name.Reference, options ...Option) (bool, *v1.Descriptor, error) {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return false, nil, err
+	}
+	var platform *v1.Platform
+	if o.platformSet {
+		platform = &o.platform
+	}
+	return newPuller(o).exists(o.context, ref, platform)
+}
+
+// Exists is like remote.Exists, but avoids re-authenticating when possible.
+func (p *Puller) Exists(ctx context.Context, ref name.Reference) (bool, *v1.Descriptor, error) {
+	var platform *v1.Platform
+	if p.o.platformSet {
+		platform = &p.o.platform
+	}
+	return p.exists(ctx, ref, platform)
+}
+
+// exists is Exists, checking that an index has a child for platform if it
+// isn't nil.
+func (p *Puller) exists(ctx context.Context, ref name.Reference, platform *v1.Platform) (bool, *v1.Descriptor, error) {
+	f, err := p.fetcher(ctx, ref.Context())
+	if err != nil {
+		return false, nil, err
+	}
+	desc, err := f.headManifest(ctx, ref, allManifestMediaTypes)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	if platform == nil || !desc.MediaType.IsIndex() {
+		return true, desc, nil
+	}
+
+	b, _, err := f.fetchManifest(ctx, ref.Context().Digest(desc.Digest.String()), []types.MediaType{desc.MediaType})
+	if err != nil {
+		return false, nil, err
+	}
+	im, err := v1.ParseIndexManifest(bytes.NewReader(b))
+	if err != nil {
+		return false, nil, err
+	}
+	for _, child := range im.Manifests {
+		// If platform is missing from child descriptor, assume it's amd64/linux.
+		cp := defaultPlatform
+		if child.Platform != nil {
+			cp = *child.Platform
+		}
+		if matchesPlatform(cp, *platform) {
+			return true, &child, nil
+		}
+	}
+	return false, nil, nil
+}
+
+// BlobExists is like remote.BlobExists, but avoids re-authenticating when
+// possible.
+func (p *Puller) BlobExists(ctx context.Context, repo name.Repository, h v1.Hash) (bool, error) {
+	f, err := p.fetcher(ctx, repo)
+	if err != nil {
+		return false, err
+	}
+	// This is the same check that Write does, with the fetcher's client.
+	w := &writer{repo: repo, client: f.client}
+	return w.checkExistingBlob(ctx, h)
+}
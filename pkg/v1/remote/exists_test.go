@@ -0,0 +1,214 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+func TestExists(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(fmt.Sprintf("%s/test:exists", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := img.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing := v1.Hash{Algorithm: "sha256", Hex: "0000000000000000000000000000000000000000000000000000000000000000"}
+	repo := ref.Context()
+
+	for _, tc := range []struct {
+		ref  name.Reference
+		want bool
+	}{
+		{ref, true},
+		{repo.Digest(d.String()), true},
+		{repo.Tag("missing"), false},
+		{repo.Digest(missing.String()), false},
+	} {
+		got, _, err := Exists(tc.ref)
+		if err != nil {
+			t.Errorf("Exists(%s): %v", tc.ref, err)
+		} else if got != tc.want {
+			t.Errorf("Exists(%s) = %t, want %t", tc.ref, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		h    v1.Hash
+		want bool
+	}{
+		{cfg, true},
+		{missing, false},
+	} {
+		got, err := BlobExists(repo, tc.h)
+		if err != nil {
+			t.Errorf("BlobExists(%s): %v", tc.h, err)
+		} else if got != tc.want {
+			t.Errorf("BlobExists(%s) = %t, want %t", tc.h, got, tc.want)
+		}
+	}
+
+	// Errors other than 404 are returned.
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer denied.Close()
+	du, err := url.Parse(denied.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drepo, err := name.NewRepository(du.Host + "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var terr *transport.Error
+	if _, err := BlobExists(drepo, cfg); !errors.As(err, &terr) || terr.StatusCode != http.StatusForbidden {
+		t.Errorf("BlobExists() = %v, want 403", err)
+	}
+}
+
+func TestExistsDescriptor(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arm := v1.Platform{OS: "linux", Architecture: "arm64"}
+	idx := mutate.AppendManifests(empty.Index, mutate.IndexAddendum{
+		Add:        img,
+		Descriptor: v1.Descriptor{Platform: &arm},
+	})
+	ref, err := name.ParseReference(fmt.Sprintf("%s/test:index", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+	idxDesc, err := partial.Descriptor(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imgDigest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		desc   string
+		ref    name.Reference
+		opts   []Option
+		want   bool
+		digest v1.Hash
+	}{{
+		desc:   "index",
+		ref:    ref,
+		want:   true,
+		digest: idxDesc.Digest,
+	}, {
+		desc:   "matching platform",
+		ref:    ref,
+		opts:   []Option{WithPlatform(arm)},
+		want:   true,
+		digest: imgDigest,
+	}, {
+		desc: "other platform",
+		ref:  ref,
+		opts: []Option{WithPlatform(v1.Platform{OS: "linux", Architecture: "amd64"})},
+	}, {
+		desc: "missing",
+		ref:  ref.Context().Tag("missing"),
+		opts: []Option{WithPlatform(arm)},
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, desc, err := Exists(tc.ref, tc.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Fatalf("Exists() = %t, want %t", got, tc.want)
+			}
+			if !got {
+				if desc != nil {
+					t.Errorf("Exists() returned descriptor %v for a missing manifest", desc)
+				}
+				return
+			}
+			if desc.Digest != tc.digest {
+				t.Errorf("Exists() digest = %s, want %s", desc.Digest, tc.digest)
+			}
+			if desc.Size == 0 || desc.MediaType == "" {
+				t.Errorf("Exists() = %+v, want size and media type", desc)
+			}
+		})
+	}
+
+	// The Puller method uses the platform it was made with.
+	p, err := NewPuller(WithPlatform(arm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, desc, err := p.Exists(context.Background(), ref); err != nil {
+		t.Fatal(err)
+	} else if !got || desc.Digest != imgDigest {
+		t.Errorf("Puller.Exists() = %t, %v, want true, %s", got, desc, imgDigest)
+	}
+}
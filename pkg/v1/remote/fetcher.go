@@ -16,16 +16,19 @@ package remote
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
+	"sync"
 
 	"github.com/google/go-containerregistry/internal/redact"
-	"github.com/google/go-containerregistry/internal/verify"
 	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
@@ -42,16 +45,27 @@ const (
 type fetcher struct {
 	target resource
 	client *http.Client
+
+	// mirrors are tried in order before target for reads (see WithMirrors).
+	mirrors []*fetcher
+
+	// identity is whether blobs are downloaded with
+	// "Accept-Encoding: identity" (see WithIdentityEncoding).
+	identity bool
+
+	// pool, if set, has a slot for each blob that may be read at once (see
+	// WithFetchPool). It is shared with the mirrors.
+	pool chan struct{}
+
+	// cache, if set, holds the manifests fetched from target (see
+	// WithManifestCache).
+	cache ManifestCache
 }
 
 func makeFetcher(ctx context.Context, target resource, o *options) (*fetcher, error) {
-	auth := o.auth
-	if o.keychain != nil {
-		kauth, err := authn.Resolve(ctx, o.keychain, target)
-		if err != nil {
-			return nil, err
-		}
-		auth = kauth
+	auth, err := resolveAuth(ctx, target, o)
+	if err != nil {
+		return nil, err
 	}
 
 	reg, ok := target.(name.Registry)
@@ -63,20 +77,120 @@ func makeFetcher(ctx context.Context, target resource, o *options) (*fetcher, er
 		reg = repo.Registry
 	}
 
-	tr, err := transport.NewWithContext(ctx, reg, auth, o.transport, []string{target.Scope(transport.PullScope)})
+	tr, err := transport.NewWithContext(ctx, reg, auth, o.transport, []string{target.Scope(transport.PullScope)}, tokenOptions(o.metrics)...)
 	if err != nil {
 		return nil, err
 	}
-	return &fetcher{
-		target: target,
-		client: &http.Client{Transport: tr},
-	}, nil
+	f := &fetcher{
+		target:   target,
+		client:   &http.Client{Transport: tr},
+		cache:    o.manifestCache,
+		identity: matchesRegistry(reg, o.identityEncoding),
+	}
+	if o.fetchPool > 0 {
+		f.pool = make(chan struct{}, o.fetchPool)
+	}
+	if repo, ok := target.(name.Repository); ok && repo.RegistryStr() == name.DefaultRegistry {
+		f.mirrors = makeMirrors(ctx, repo, o)
+		for _, m := range f.mirrors {
+			m.pool = f.pool
+		}
+	}
+	return f, nil
+}
+
+// resolveAuth returns the authenticator for target: o.auth, or whatever
+// o.keychain resolves, giving up after o.authTimeout if one is set.
+func resolveAuth(ctx context.Context, target authn.Resource, o *options) (authn.Authenticator, error) {
+	if o.keychain == nil {
+		return o.auth, nil
+	}
+	return authn.ResolveWithTimeout(ctx, o.keychain, target, o.authTimeout)
+}
+
+// makeMirrors returns fetchers for repo in each of o.mirrors, skipping any
+// mirror we can't set up a transport for.
+func makeMirrors(ctx context.Context, repo name.Repository, o *options) []*fetcher {
+	var mirrors []*fetcher
+	for _, reg := range o.mirrors {
+		mrepo := reg.Repo(repo.RepositoryStr())
+
+		auth := authn.Anonymous
+		if o.keychain != nil {
+			kauth, err := resolveAuth(ctx, mrepo, o)
+			if err != nil {
+				logs.Warn.Printf("skipping mirror %s: %v", reg, err)
+				continue
+			}
+			auth = kauth
+		}
+
+		tr, err := transport.NewWithContext(ctx, reg, auth, o.transport, []string{mrepo.Scope(transport.PullScope)}, tokenOptions(o.metrics)...)
+		if err != nil {
+			logs.Warn.Printf("skipping mirror %s: %v", reg, err)
+			continue
+		}
+		mirrors = append(mirrors, &fetcher{
+			target:   mrepo,
+			client:   &http.Client{Transport: tr},
+			identity: matchesRegistry(reg, o.identityEncoding),
+		})
+	}
+	return mirrors
+}
+
+// mirrorFailed logs a failed read from a mirror and reports whether we should
+// fall back to the next mirror or the origin.
+func mirrorFailed(ctx context.Context, m *fetcher, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	logs.Debug.Printf("mirror %s: %v", m.target.RegistryStr(), err)
+	return true
 }
 
 func (f *fetcher) Do(req *http.Request) (*http.Response, error) {
 	return f.client.Do(req)
 }
 
+// acquire waits for a slot in the fetch pool, if there is one, and returns a
+// func that releases it.
+func (f *fetcher) acquire(ctx context.Context) (func(), error) {
+	if f.pool == nil {
+		return func() {}, nil
+	}
+	select {
+	case f.pool <- struct{}{}:
+		var once sync.Once
+		return func() {
+			once.Do(func() { <-f.pool })
+		}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// pooledReadCloser releases a slot in the fetch pool once it has been read
+// to the end, reading it fails, or it is closed, whichever comes first, so
+// that callers that hold finished readers open don't starve the pool.
+type pooledReadCloser struct {
+	io.ReadCloser
+	release func()
+}
+
+func (p *pooledReadCloser) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	if err != nil {
+		p.release()
+	}
+	return n, err
+}
+
+func (p *pooledReadCloser) Close() error {
+	defer p.release()
+	return p.ReadCloser.Close()
+}
+
 type resource interface {
 	Scheme() string
 	RegistryStr() string
@@ -115,6 +229,23 @@ func (f *fetcher) get(ctx context.Context, ref name.Reference, acceptable []type
 }
 
 func (f *fetcher) fetchManifest(ctx context.Context, ref name.Reference, acceptable []types.MediaType) ([]byte, *v1.Descriptor, error) {
+	cached := f.cached(ref, acceptable)
+	if _, ok := ref.(name.Digest); ok && cached != nil {
+		// Manifests don't change, so there's no need to ask the registry.
+		desc := cached.Descriptor
+		return cached.Manifest, &desc, nil
+	}
+
+	for _, m := range f.mirrors {
+		b, desc, err := m.fetchManifest(ctx, ref, acceptable)
+		if err == nil {
+			return b, desc, nil
+		}
+		if !mirrorFailed(ctx, m, err) {
+			return nil, nil, err
+		}
+	}
+
 	u := f.url("manifests", ref.Identifier())
 	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
@@ -125,6 +256,11 @@ func (f *fetcher) fetchManifest(ctx context.Context, ref name.Reference, accepta
 		accept = append(accept, string(mt))
 	}
 	req.Header.Set("Accept", strings.Join(accept, ","))
+	codes := []int{http.StatusOK}
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+		codes = append(codes, http.StatusNotModified)
+	}
 
 	resp, err := f.client.Do(req.WithContext(ctx))
 	if err != nil {
@@ -132,9 +268,13 @@ func (f *fetcher) fetchManifest(ctx context.Context, ref name.Reference, accepta
 	}
 	defer resp.Body.Close()
 
-	if err := transport.CheckError(resp, http.StatusOK); err != nil {
+	if err := transport.CheckError(resp, codes...); err != nil {
 		return nil, nil, err
 	}
+	if resp.StatusCode == http.StatusNotModified {
+		desc := cached.Descriptor
+		return cached.Manifest, &desc, nil
+	}
 
 	manifest, err := io.ReadAll(io.LimitReader(resp.Body, manifestLimit))
 	if err != nil {
@@ -165,7 +305,9 @@ func (f *fetcher) fetchManifest(ctx context.Context, ref name.Reference, accepta
 	mf, _ := v1.ParseManifest(bytes.NewReader(manifest))
 	// Failing to parse as a manifest should just be ignored.
 	// The manifest might not be valid, and that's okay.
-	if mf != nil && !mf.Config.MediaType.IsConfig() {
+	if mf != nil && mf.ArtifactType != "" {
+		artifactType = mf.ArtifactType
+	} else if mf != nil && !mf.Config.MediaType.IsConfig() {
 		artifactType = string(mf.Config.MediaType)
 	}
 
@@ -185,10 +327,46 @@ func (f *fetcher) fetchManifest(ctx context.Context, ref name.Reference, accepta
 		ArtifactType: artifactType,
 	}
 
+	if f.cache != nil {
+		m := &CachedManifest{Descriptor: desc, Manifest: manifest, ETag: resp.Header.Get("ETag")}
+		if _, ok := ref.(name.Tag); ok {
+			f.cache.Put(ref, m)
+		}
+		f.cache.Put(ref.Context().Digest(digest.String()), m)
+	}
+
 	return manifest, &desc, nil
 }
 
+// cached returns the manifest in f.cache for ref, if there is one and it has
+// an acceptable media type.
+func (f *fetcher) cached(ref name.Reference, acceptable []types.MediaType) *CachedManifest {
+	if f.cache == nil {
+		return nil
+	}
+	m := f.cache.Get(ref)
+	if m == nil {
+		return nil
+	}
+	for _, mt := range acceptable {
+		if m.Descriptor.MediaType == mt {
+			return m
+		}
+	}
+	return nil
+}
+
 func (f *fetcher) headManifest(ctx context.Context, ref name.Reference, acceptable []types.MediaType) (*v1.Descriptor, error) {
+	for _, m := range f.mirrors {
+		desc, err := m.headManifest(ctx, ref, acceptable)
+		if err == nil {
+			return desc, nil
+		}
+		if !mirrorFailed(ctx, m, err) {
+			return nil, err
+		}
+	}
+
 	u := f.url("manifests", ref.Identifier())
 	req, err := http.NewRequest(http.MethodHead, u.String(), nil)
 	if err != nil {
@@ -245,12 +423,40 @@ func (f *fetcher) headManifest(ctx context.Context, ref name.Reference, acceptab
 	}, nil
 }
 
+// fetchBlob fetches the blob h from the mirrors or target, holding a slot in
+// the fetch pool until it has been read.
 func (f *fetcher) fetchBlob(ctx context.Context, size int64, h v1.Hash) (io.ReadCloser, error) {
+	release, err := f.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range f.mirrors {
+		rc, err := m.getBlob(ctx, size, h)
+		if err == nil {
+			return &pooledReadCloser{ReadCloser: rc, release: release}, nil
+		}
+		if !mirrorFailed(ctx, m, err) {
+			release()
+			return nil, err
+		}
+	}
+	rc, err := f.getBlob(ctx, size, h)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &pooledReadCloser{ReadCloser: rc, release: release}, nil
+}
+
+// getBlob fetches the blob h from f.target, without a slot in the fetch
+// pool.
+func (f *fetcher) getBlob(ctx context.Context, size int64, h v1.Hash) (io.ReadCloser, error) {
 	u := f.url("blobs", h.String())
 	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
 		return nil, err
 	}
+	f.setEncoding(req)
 
 	resp, err := f.client.Do(req.WithContext(ctx))
 	if err != nil {
@@ -261,22 +467,74 @@ func (f *fetcher) fetchBlob(ctx context.Context, size int64, h v1.Hash) (io.Read
 		resp.Body.Close()
 		return nil, err
 	}
+	if err := decodeContent(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
 
-	// Do whatever we can.
-	// If we have an expected size and Content-Length doesn't match, return an error.
-	// If we don't have an expected size and we do have a Content-Length, use Content-Length.
-	if hsize := resp.ContentLength; hsize != -1 {
-		if size == verify.SizeUnknown {
-			size = hsize
-		} else if hsize != size {
-			return nil, fmt.Errorf("GET %s: Content-Length header %d does not match expected size %d", u.String(), hsize, size)
-		}
+	return verifyBlob(resp, size, h)
+}
+
+// setEncoding asks for blobs not to be encoded, if f.identity is set.
+func (f *fetcher) setEncoding(req *http.Request) {
+	if f.identity {
+		// Setting Accept-Encoding also stops the transport from asking for
+		// gzip and decoding it.
+		req.Header.Set("Accept-Encoding", "identity")
+	}
+}
+
+// decodeContent decodes a gzip-encoded response that the transport didn't,
+// because we asked for the identity encoding, so that the blob is verified
+// as decoded. Content-Length is the encoded size, so it is dropped.
+func decodeContent(resp *http.Response) error {
+	if resp.Uncompressed || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return nil
 	}
+	zr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("decoding gzip-encoded response: %w", err)
+	}
+	resp.Body = &gzipBody{Reader: zr, body: resp.Body}
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.Uncompressed = true
+	return nil
+}
+
+// gzipBody reads the decoded body, closing the encoded one.
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
 
-	return verify.ReadCloser(resp.Body, size, h)
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
+
+// matchesRegistry reports whether reg matches any of the path.Match patterns.
+func matchesRegistry(reg name.Registry, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, reg.RegistryStr()); ok {
+			return true
+		}
+	}
+	return false
 }
 
 func (f *fetcher) headBlob(ctx context.Context, h v1.Hash) (*http.Response, error) {
+	for _, m := range f.mirrors {
+		resp, err := m.headBlob(ctx, h)
+		if err == nil {
+			return resp, nil
+		}
+		if !mirrorFailed(ctx, m, err) {
+			return nil, err
+		}
+	}
+
 	u := f.url("blobs", h.String())
 	req, err := http.NewRequest(http.MethodHead, u.String(), nil)
 	if err != nil {
@@ -41,7 +41,7 @@ type remoteImage struct {
 	fetcher      fetcher
 	ref          name.Reference
 	ctx          context.Context
-	manifestLock sync.Mutex // Protects manifest
+	manifestLock sync.Mutex // Protects manifest, mediaType and descriptor
 	manifest     []byte
 	configLock   sync.Mutex // Protects config
 	config       []byte
@@ -61,6 +61,10 @@ func (r *remoteImage) ArtifactType() (string, error) {
 var _ partial.CompressedImageCore = (*remoteImage)(nil)
 
 // Image provides access to a remote image reference.
+//
+// The image and its layers are safe for concurrent use by multiple
+// goroutines, which share its transport. See WithFetchPool to bound how many
+// of its layers are read at once.
 func Image(ref name.Reference, options ...Option) (v1.Image, error) {
 	desc, err := Get(ref, options...)
 	if err != nil {
@@ -71,6 +75,9 @@ func Image(ref name.Reference, options ...Option) (v1.Image, error) {
 }
 
 func (r *remoteImage) MediaType() (types.MediaType, error) {
+	// RawManifest sets mediaType when it fetches the manifest.
+	r.manifestLock.Lock()
+	defer r.manifestLock.Unlock()
 	if string(r.mediaType) != "" {
 		return r.mediaType, nil
 	}
@@ -187,9 +194,25 @@ func (rl *remoteImageLayer) Compressed() (io.ReadCloser, error) {
 	// We don't want to log binary layers -- this can break terminals.
 	ctx := redact.NewContext(rl.ctx, "omitting binary blobs from logs")
 
+	release, err := rl.ri.fetcher.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range rl.ri.fetcher.mirrors {
+		rc, err := m.getBlob(ctx, d.Size, rl.digest)
+		if err == nil {
+			return &pooledReadCloser{ReadCloser: rc, release: release}, nil
+		}
+		if !mirrorFailed(ctx, m, err) {
+			release()
+			return nil, err
+		}
+	}
+
 	for _, s := range d.URLs {
 		u, err := url.Parse(s)
 		if err != nil {
+			release()
 			return nil, err
 		}
 		urls = append(urls, *u)
@@ -203,8 +226,10 @@ func (rl *remoteImageLayer) Compressed() (io.ReadCloser, error) {
 	for _, u := range urls {
 		req, err := http.NewRequest(http.MethodGet, u.String(), nil)
 		if err != nil {
+			release()
 			return nil, err
 		}
+		rl.ri.fetcher.setEncoding(req)
 
 		resp, err := rl.ri.fetcher.Do(req.WithContext(ctx))
 		if err != nil {
@@ -217,10 +242,21 @@ func (rl *remoteImageLayer) Compressed() (io.ReadCloser, error) {
 			lastErr = err
 			continue
 		}
+		if err := decodeContent(resp); err != nil {
+			resp.Body.Close()
+			lastErr = err
+			continue
+		}
 
-		return verify.ReadCloser(resp.Body, d.Size, rl.digest)
+		rc, err := verifyBlob(resp, d.Size, rl.digest)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		return &pooledReadCloser{ReadCloser: rc, release: release}, nil
 	}
 
+	release()
 	return nil, lastErr
 }
 
@@ -20,12 +20,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"path"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/authn"
@@ -38,6 +41,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/google/go-containerregistry/pkg/v1/validate"
+	"golang.org/x/sync/errgroup"
 )
 
 const bogusDigest = "sha256:deadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef"
@@ -747,3 +751,212 @@ func TestData(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestImageConcurrentUse(t *testing.T) {
+	img, err := random.Image(1024, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(u.Host + "/foo/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, get := range []struct {
+		name string
+		img  func() (v1.Image, error)
+	}{{
+		name: "Image",
+		img:  func() (v1.Image, error) { return Image(tag) },
+	}, {
+		// Directly instantiated, so that the manifest is fetched lazily.
+		name: "lazy",
+		img: func() (v1.Image, error) {
+			f, err := makeFetcher(context.Background(), tag.Context(), &options{transport: http.DefaultTransport, auth: authn.Anonymous})
+			if err != nil {
+				return nil, err
+			}
+			return partial.CompressedToImage(&remoteImage{fetcher: *f, ref: tag, ctx: context.Background()})
+		},
+	}} {
+		t.Run(get.name, func(t *testing.T) {
+			rmt, err := get.img()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var g errgroup.Group
+			for i := 0; i < 8; i++ {
+				g.Go(func() error {
+					if _, err := rmt.MediaType(); err != nil {
+						return err
+					}
+					if _, err := rmt.Digest(); err != nil {
+						return err
+					}
+					if _, err := rmt.ConfigFile(); err != nil {
+						return err
+					}
+					layers, err := rmt.Layers()
+					if err != nil {
+						return err
+					}
+					for _, l := range layers {
+						if _, err := l.MediaType(); err != nil {
+							return err
+						}
+						rc, err := l.Uncompressed()
+						if err != nil {
+							return err
+						}
+						if _, err := io.Copy(io.Discard, rc); err != nil {
+							return err
+						}
+						if err := rc.Close(); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			}
+			if err := g.Wait(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestFetchPool(t *testing.T) {
+	img, err := random.Image(1024, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	var inflight, most atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.Contains(r.URL.Path, "/blobs/") {
+			reg.ServeHTTP(w, r)
+			return
+		}
+		n := inflight.Add(1)
+		defer inflight.Add(-1)
+		for {
+			m := most.Load()
+			if n <= m || most.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(u.Host + "/foo/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Image(tag, WithFetchPool(0)); err == nil {
+		t.Error("WithFetchPool(0) succeeded, want an error")
+	}
+
+	rmt, err := Image(tag, WithFetchPool(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := rmt.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g errgroup.Group
+	for i := 0; i < 4; i++ {
+		for _, l := range layers {
+			g.Go(func() error {
+				rc, err := l.Compressed()
+				if err != nil {
+					return err
+				}
+				defer rc.Close()
+				_, err = io.Copy(io.Discard, rc)
+				return err
+			})
+		}
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if got := most.Load(); got > 2 {
+		t.Errorf("read %d blobs at once, want at most 2", got)
+	}
+}
+
+func TestFetchPoolExtract(t *testing.T) {
+	img, err := random.Image(1024, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(u.Host + "/foo/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+
+	// Extracting reads more layers than there are slots in the pool.
+	rmt, err := Image(tag, WithFetchPool(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		rc := mutate.Extract(rmt)
+		defer rc.Close()
+		_, err := io.Copy(io.Discard, rc)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Extract didn't finish with a fetch pool smaller than the image")
+	}
+
+	// Layers read to the end don't hold their slots, even if they aren't
+	// closed.
+	layers, err := rmt.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		rc, err := l.Compressed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
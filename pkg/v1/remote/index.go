@@ -37,13 +37,18 @@ type remoteIndex struct {
 	fetcher      fetcher
 	ref          name.Reference
 	ctx          context.Context
-	manifestLock sync.Mutex // Protects manifest
+	manifestLock sync.Mutex // Protects manifest, mediaType and descriptor
 	manifest     []byte
 	mediaType    types.MediaType
 	descriptor   *v1.Descriptor
 }
 
 // Index provides access to a remote index reference.
+//
+// The images and indexes in the returned index share its transport, so
+// reading them doesn't authenticate again. Like them, it is safe for
+// concurrent use by multiple goroutines. See Descriptor.Images to get all of
+// its images without fetching their manifests up front.
 func Index(ref name.Reference, options ...Option) (v1.ImageIndex, error) {
 	desc, err := get(ref, acceptableIndexMediaTypes, options...)
 	if err != nil {
@@ -54,6 +59,9 @@ func Index(ref name.Reference, options ...Option) (v1.ImageIndex, error) {
 }
 
 func (r *remoteIndex) MediaType() (types.MediaType, error) {
+	// RawManifest sets mediaType when it fetches the manifest.
+	r.manifestLock.Lock()
+	defer r.manifestLock.Unlock()
 	if string(r.mediaType) != "" {
 		return r.mediaType, nil
 	}
@@ -109,6 +117,45 @@ func (r *remoteIndex) Image(h v1.Hash) (v1.Image, error) {
 	return desc.Image()
 }
 
+// Images returns the images in the index, in order. Unlike Image, it doesn't
+// fetch their manifests: each is fetched when it is first needed, with the
+// index's transport. Children that are indexes are skipped.
+func (r *remoteIndex) Images() ([]v1.Image, error) {
+	index, err := r.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	var imgs []v1.Image
+	for _, child := range index.Manifests {
+		if !child.MediaType.IsImage() {
+			continue
+		}
+		ref := r.ref.Context().Digest(child.Digest.String())
+		ri := &remoteImage{
+			ref:        ref,
+			ctx:        r.ctx,
+			fetcher:    r.fetcher,
+			mediaType:  child.MediaType,
+			descriptor: &child,
+		}
+		if child.Data != nil {
+			if err := verify.Descriptor(child); err != nil {
+				return nil, err
+			}
+			ri.manifest = child.Data
+		}
+		imgCore, err := partial.CompressedToImage(ri)
+		if err != nil {
+			return nil, err
+		}
+		imgs = append(imgs, &mountableImage{
+			Image:     imgCore,
+			Reference: ref,
+		})
+	}
+	return imgs, nil
+}
+
 // Descriptor retains the original descriptor from an index manifest.
 // See partial.Descriptor.
 func (r *remoteIndex) Descriptor() (*v1.Descriptor, error) {
@@ -225,7 +272,9 @@ func (r *remoteIndex) childDescriptor(child v1.Descriptor, platform v1.Platform)
 		mf, _ := v1.ParseManifest(bytes.NewReader(manifest))
 		// Failing to parse as a manifest should just be ignored.
 		// The manifest might not be valid, and that's okay.
-		if mf != nil && !mf.Config.MediaType.IsConfig() {
+		if mf != nil && mf.ArtifactType != "" {
+			child.ArtifactType = mf.ArtifactType
+		} else if mf != nil && !mf.Config.MediaType.IsConfig() {
 			child.ArtifactType = string(mf.Config.MediaType)
 		}
 	}
@@ -18,12 +18,18 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/random"
 	"github.com/google/go-containerregistry/pkg/v1/types"
@@ -503,3 +509,83 @@ func TestMatchesPlatform(t *testing.T) {
 		}
 	}
 }
+
+func TestIndexImages(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests = map[string]int{}
+	)
+	count := func(kind string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[kind]
+	}
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		switch {
+		case r.URL.Path == "/v2/":
+			requests["ping"]++
+		case r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/manifests/"):
+			requests["manifest"]++
+		}
+		mu.Unlock()
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/test:index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := randomIndex(t)
+	if err := WriteIndex(ref, idx); err != nil {
+		t.Fatalf("WriteIndex() = %v", err)
+	}
+	mu.Lock()
+	clear(requests)
+	mu.Unlock()
+
+	desc, err := Get(ref)
+	if err != nil {
+		t.Fatalf("Get() = %v", err)
+	}
+	imgs, err := desc.Images()
+	if err != nil {
+		t.Fatalf("Images() = %v", err)
+	}
+	m := mustIndexManifest(t, idx)
+	if len(imgs) != len(m.Manifests) {
+		t.Fatalf("Images() returned %d images, want %d", len(imgs), len(m.Manifests))
+	}
+	if got := count("manifest"); got != 1 {
+		t.Errorf("Images() fetched %d manifests, want only the index", got-1)
+	}
+
+	for i, img := range imgs {
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != m.Manifests[i].Digest {
+			t.Errorf("image %d digest = %s, want %s", i, d, m.Manifests[i].Digest)
+		}
+		if _, err := img.ConfigFile(); err != nil {
+			t.Fatalf("ConfigFile() = %v", err)
+		}
+	}
+	if got, want := count("manifest"), 1+len(imgs); got != want {
+		t.Errorf("fetched %d manifests, want %d", got, want)
+	}
+	// The images share the index's transport.
+	if got := count("ping"); got != 1 {
+		t.Errorf("pinged the registry %d times, want 1", got)
+	}
+
+	if _, err := (&Descriptor{Descriptor: v1.Descriptor{MediaType: types.OCIManifestSchema1}}).Images(); err == nil {
+		t.Error("Images() of an image succeeded, want an error")
+	}
+}
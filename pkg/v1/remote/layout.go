@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	imagespec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// WriteLayout pushes the manifests listed in the index.json of the OCI image
+// layout at p to repo, by digest. If m is not nil, only manifests whose
+// descriptors match are pushed.
+//
+// Manifests are pushed as-is and blobs are streamed directly from disk, so
+// nothing is re-marshaled or buffered in memory. Blobs that already exist in
+// repo are skipped, which means an interrupted WriteLayout can be resumed by
+// calling it again.
+func WriteLayout(p layout.Path, repo name.Repository, m match.Matcher, options ...Option) error {
+	return writeLayout(p, repo, m, false, options...)
+}
+
+// WriteLayoutTags is like WriteLayout, but manifests whose descriptors in
+// index.json have an "org.opencontainers.image.ref.name" annotation are
+// pushed to the tag it names rather than by digest, as "crane pull
+// --annotate-ref" and other tools record them.
+//
+// The annotation may be a tag (e.g. "v1.0") or a reference with a tag (e.g.
+// "example.com/app:v1.0"), whose tag is used in repo. Manifests whose
+// annotations name no tag are pushed by digest.
+func WriteLayoutTags(p layout.Path, repo name.Repository, m match.Matcher, options ...Option) error {
+	return writeLayout(p, repo, m, true, options...)
+}
+
+func writeLayout(p layout.Path, repo name.Repository, m match.Matcher, tags bool, options ...Option) error {
+	ii, err := p.ImageIndex()
+	if err != nil {
+		return err
+	}
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return err
+	}
+
+	todo := map[name.Reference]Taggable{}
+	tagged := map[string]v1.Hash{}
+	for _, desc := range im.Manifests {
+		if m != nil && !m(desc) {
+			continue
+		}
+
+		var t Taggable
+		switch {
+		case desc.MediaType.IsIndex():
+			t, err = ii.ImageIndex(desc.Digest)
+		case desc.MediaType.IsImage():
+			t, err = ii.Image(desc.Digest)
+		default:
+			return fmt.Errorf("layout manifest %s has unexpected media type %q", desc.Digest, desc.MediaType)
+		}
+		if err != nil {
+			return err
+		}
+
+		var ref name.Reference = repo.Digest(desc.Digest.String())
+		if tags {
+			if tag, ok := layoutTag(repo, desc.Annotations[imagespec.AnnotationRefName]); ok {
+				if h, ok := tagged[tag.TagStr()]; ok && h != desc.Digest {
+					return fmt.Errorf("layout manifests %s and %s are both tagged %q", h, desc.Digest, tag.TagStr())
+				}
+				tagged[tag.TagStr()] = desc.Digest
+				ref = tag
+			}
+		}
+		todo[ref] = t
+	}
+	if len(todo) == 0 {
+		return nil
+	}
+
+	return MultiWrite(todo, options...)
+}
+
+// layoutTag returns the tag in repo that an
+// "org.opencontainers.image.ref.name" annotation names, if any.
+func layoutTag(repo name.Repository, refName string) (name.Tag, bool) {
+	if refName == "" {
+		return name.Tag{}, false
+	}
+	if !strings.ContainsAny(refName, "/:@") {
+		tag, err := name.NewTag(repo.String()+":"+refName, name.StrictValidation)
+		return tag, err == nil
+	}
+	// A bare repository doesn't name a tag, so don't let it default to latest.
+	if ref, err := name.ParseReference(refName, name.WithDefaultTag("")); err == nil {
+		if tag, ok := ref.(name.Tag); ok && tag.TagStr() != "" {
+			return repo.Tag(tag.TagStr()), true
+		}
+	}
+	return name.Tag{}, false
+}
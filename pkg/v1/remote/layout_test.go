@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestWriteLayout(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(fmt.Sprintf("%s/layout/push", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx, err := random.Index(1024, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skipped, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := layout.Write(t.TempDir(), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(img); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendIndex(idx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(skipped, layout.WithAnnotations(map[string]string{"skip": "true"})); err != nil {
+		t.Fatal(err)
+	}
+
+	skip := match.Annotation("skip", "true")
+	m := func(desc v1.Descriptor) bool { return !skip(desc) }
+	if err := WriteLayout(p, repo, m); err != nil {
+		t.Fatalf("WriteLayout: %v", err)
+	}
+
+	for _, want := range []interface {
+		Digest() (v1.Hash, error)
+	}{img, idx} {
+		d, err := want.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		desc, err := Head(repo.Digest(d.String()))
+		if err != nil {
+			t.Errorf("Head(%s): %v", d, err)
+		} else if desc.Digest != d {
+			t.Errorf("Head(%s): got digest %s", d, desc.Digest)
+		}
+	}
+
+	d, err := skipped.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Head(repo.Digest(d.String())); err == nil {
+		t.Errorf("expected unmatched image %s not to be pushed", d)
+	}
+
+	// Pushing again should be a no-op.
+	if err := WriteLayout(p, repo, m); err != nil {
+		t.Fatalf("WriteLayout (again): %v", err)
+	}
+}
+
+func TestWriteLayoutTags(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(fmt.Sprintf("%s/layout/tags", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := layout.Write(t.TempDir(), empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]v1.Hash{}
+	for _, refName := range []string{"v1", "example.com/app:v2", "example.com/app@sha256:" + strings.Repeat("0", 64), "example.com/app", ""} {
+		img, err := random.Image(1024, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.AppendImage(img, layout.WithAnnotations(map[string]string{
+			"org.opencontainers.image.ref.name": refName,
+		})); err != nil {
+			t.Fatal(err)
+		}
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[refName] = d
+	}
+	idx, err := random.Index(1024, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendIndex(idx, layout.WithAnnotations(map[string]string{
+		"org.opencontainers.image.ref.name": "multi",
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteLayoutTags(p, repo, nil); err != nil {
+		t.Fatalf("WriteLayoutTags: %v", err)
+	}
+
+	tags, err := List(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"multi", "v1", "v2"}, tags); diff != "" {
+		t.Errorf("tags (-want +got): %s", diff)
+	}
+	for tag, refName := range map[string]string{"v1": "v1", "v2": "example.com/app:v2"} {
+		desc, err := Head(repo.Tag(tag))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if desc.Digest != want[refName] {
+			t.Errorf("%s is %s, want %s", tag, desc.Digest, want[refName])
+		}
+	}
+	// Manifests whose annotations don't name tags are pushed by digest.
+	for _, refName := range []string{"example.com/app@sha256:" + strings.Repeat("0", 64), "example.com/app", ""} {
+		if _, err := Head(repo.Digest(want[refName].String())); err != nil {
+			t.Errorf("Head(%s): %v", want[refName], err)
+		}
+	}
+
+	// Two manifests can't have the same tag.
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendImage(img, layout.WithAnnotations(map[string]string{
+		"org.opencontainers.image.ref.name": "v1",
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteLayoutTags(p, repo, nil); err == nil {
+		t.Error("WriteLayoutTags() with a duplicate tag succeeded, want error")
+	}
+}
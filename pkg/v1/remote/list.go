@@ -17,9 +17,11 @@ package remote
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -35,12 +37,15 @@ func ListWithContext(ctx context.Context, repo name.Repository, options ...Optio
 
 // List calls /tags/list for the given repository, returning the list of tags
 // in the "tags" property.
+//
+// List holds every tag in memory; use a Puller's Lister to list repositories
+// with too many tags for that, a page at a time.
 func List(repo name.Repository, options ...Option) ([]string, error) {
 	o, err := makeOptions(options...)
 	if err != nil {
 		return nil, err
 	}
-	return newPuller(o).List(o.context, repo)
+	return newPuller(o).list(o.context, repo, o.pageSize, o.last)
 }
 
 type Tags struct {
@@ -49,16 +54,23 @@ type Tags struct {
 	Next string   `json:"next,omitempty"`
 }
 
-func (f *fetcher) listPage(ctx context.Context, repo name.Repository, next string, pageSize int) (*Tags, error) {
+// listPage fetches the page of tags at next, or the first page of those
+// after last if next is empty.
+func (f *fetcher) listPage(ctx context.Context, repo name.Repository, next string, pageSize int, last string) (*Tags, error) {
 	if next == "" {
 		uri := &url.URL{
 			Scheme: repo.Scheme(),
 			Host:   repo.RegistryStr(),
 			Path:   fmt.Sprintf("/v2/%s/tags/list", repo.RepositoryStr()),
 		}
+		q := url.Values{}
 		if pageSize > 0 {
-			uri.RawQuery = fmt.Sprintf("n=%d", pageSize)
+			q.Set("n", strconv.Itoa(pageSize))
+		}
+		if last != "" {
+			q.Set("last", last)
 		}
+		uri.RawQuery = q.Encode()
 		next = uri.String()
 	}
 
@@ -73,6 +85,9 @@ func (f *fetcher) listPage(ctx context.Context, repo name.Repository, next strin
 	}
 
 	if err := transport.CheckError(resp, http.StatusOK); err != nil {
+		if tagListingUnsupported(err) {
+			return nil, fmt.Errorf("listing tags of %s: %w: %w", repo, ErrTagListingUnsupported, err)
+		}
 		return nil, err
 	}
 
@@ -97,6 +112,18 @@ func (f *fetcher) listPage(ctx context.Context, repo name.Repository, next strin
 	return &parsed, nil
 }
 
+// tagListingUnsupported reports whether err, from listing tags, means that
+// the registry doesn't list tags, rather than that the request failed.
+func tagListingUnsupported(err error) bool {
+	var terr *transport.Error
+	if !errors.As(err, &terr) {
+		return false
+	}
+	return terr.StatusCode == http.StatusMethodNotAllowed ||
+		terr.StatusCode == http.StatusNotImplemented ||
+		transport.HasCode(err, transport.UnsupportedErrorCode)
+}
+
 // getNextPageURL checks if there is a Link header in a http.Response which
 // contains a link to the next page. If yes it returns the url.URL of the next
 // page otherwise it returns nil.
@@ -140,7 +167,7 @@ type Lister struct {
 
 func (l *Lister) Next(ctx context.Context) (*Tags, error) {
 	if l.needMore {
-		l.page, l.err = l.f.listPage(ctx, l.repo, l.page.Next, l.pageSize)
+		l.page, l.err = l.f.listPage(ctx, l.repo, l.page.Next, l.pageSize, "")
 	} else {
 		l.needMore = true
 	}
@@ -16,7 +16,10 @@ package remote
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -25,6 +28,9 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 )
 
 func TestList(t *testing.T) {
@@ -157,3 +163,142 @@ func TestGetNextPageURL(t *testing.T) {
 		t.Errorf("expected scheme to match request, got %s", u.Scheme)
 	}
 }
+
+func TestListerLast(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, err := name.NewRepository(u.Host + "/pager")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{}
+	for i := 0; i < 10; i++ {
+		tag := fmt.Sprintf("v%d", i)
+		if err := Write(repo.Tag(tag), img); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, tag)
+	}
+
+	// Stop after two pages, then resume from the last tag with a new lister.
+	ctx := context.Background()
+	puller, err := NewPuller(WithPageSize(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lister, err := puller.Lister(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for i := 0; i < 2 && lister.HasNext(); i++ {
+		page, err := lister.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, page.Tags...)
+	}
+
+	resumed, err := NewPuller(WithPageSize(3), WithLast(got[len(got)-1]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lister, err = resumed.Lister(ctx, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := 0
+	for lister.HasNext() {
+		page, err := lister.Next(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, page.Tags...)
+		pages++
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Next() (-want +got) = %s", diff)
+	}
+	if pages != 2 {
+		t.Errorf("got %d pages after resuming, want 2", pages)
+	}
+
+	// List takes it too.
+	tags, err := List(repo, WithLast("v7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want[8:], tags); diff != "" {
+		t.Errorf("List() (-want +got) = %s", diff)
+	}
+}
+
+func TestListUnsupported(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		status      int
+		body        string
+		unsupported bool
+	}{{
+		name:        "method not allowed",
+		status:      http.StatusMethodNotAllowed,
+		unsupported: true,
+	}, {
+		name:        "not implemented",
+		status:      http.StatusNotImplemented,
+		unsupported: true,
+	}, {
+		name:        "unsupported code",
+		status:      http.StatusForbidden,
+		body:        `{"errors":[{"code":"UNSUPPORTED","message":"tag listing is disabled"}]}`,
+		unsupported: true,
+	}, {
+		name:   "not found",
+		status: http.StatusNotFound,
+		body:   `{"errors":[{"code":"NAME_UNKNOWN"}]}`,
+	}, {
+		name:   "denied",
+		status: http.StatusForbidden,
+		body:   `{"errors":[{"code":"DENIED"}]}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/v2/" {
+					return
+				}
+				w.WriteHeader(tc.status)
+				io.WriteString(w, tc.body)
+			}))
+			defer server.Close()
+			u, err := url.Parse(server.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			repo, err := name.NewRepository(u.Host + "/write-only")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = List(repo)
+			if err == nil {
+				t.Fatal("List() succeeded")
+			}
+			if got := errors.Is(err, ErrTagListingUnsupported); got != tc.unsupported {
+				t.Errorf("errors.Is(%v, ErrTagListingUnsupported) = %t, want %t", err, got, tc.unsupported)
+			}
+			var terr *transport.Error
+			if !errors.As(err, &terr) || terr.StatusCode != tc.status {
+				t.Errorf("List() = %v, want a *transport.Error with status %d", err, tc.status)
+			}
+		})
+	}
+}
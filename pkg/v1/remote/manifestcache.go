@@ -0,0 +1,80 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// CachedManifest is a manifest held in a ManifestCache.
+type CachedManifest struct {
+	// Descriptor describes the manifest: its digest, size, media type and
+	// artifact type.
+	Descriptor v1.Descriptor
+
+	// Manifest is the contents of the manifest. It must not be modified.
+	Manifest []byte
+
+	// ETag is the entity tag the registry returned with the manifest, if
+	// any, which is sent back to it in If-None-Match when the manifest is
+	// next fetched by tag.
+	ETag string
+}
+
+// ManifestCache caches the manifests fetched from registries, for
+// WithManifestCache.
+//
+// Manifests fetched by digest are put under a name.Digest, and served from
+// the cache from then on. Manifests fetched by tag are put under both the
+// name.Tag and the name.Digest of the manifest; the next fetch of the tag is
+// conditional on the manifest having changed, so the registry needn't send it
+// again if it hasn't.
+//
+// Implementations must be safe for concurrent use. An implementation that
+// can fail, e.g. one on disk, should treat failures as misses.
+type ManifestCache interface {
+	// Get returns the manifest cached for ref, or nil if there isn't one.
+	Get(ref name.Reference) *CachedManifest
+
+	// Put caches m for ref.
+	Put(ref name.Reference, m *CachedManifest)
+}
+
+// NewManifestCache returns a ManifestCache that holds manifests in memory.
+// It never evicts them, so it suits tools that watch a fixed set of tags
+// rather than ones that pull arbitrary images.
+func NewManifestCache() ManifestCache {
+	return &memoryManifestCache{manifests: map[string]*CachedManifest{}}
+}
+
+type memoryManifestCache struct {
+	mu        sync.RWMutex
+	manifests map[string]*CachedManifest
+}
+
+func (c *memoryManifestCache) Get(ref name.Reference) *CachedManifest {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.manifests[ref.Name()]
+}
+
+func (c *memoryManifestCache) Put(ref name.Reference, m *CachedManifest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.manifests[ref.Name()] = m
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+// statusRecorder records the status of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func TestManifestCache(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	var (
+		mu       sync.Mutex
+		statuses []int
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.Contains(r.URL.Path, "/manifests/") {
+			reg.ServeHTTP(w, r)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		reg.ServeHTTP(rec, r)
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, rec.status)
+	}))
+	defer s.Close()
+	requests := func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		got := statuses
+		statuses = nil
+		return got
+	}
+
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(u.Host + "/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Get(tag, WithManifestCache(nil)); err == nil {
+		t.Error("WithManifestCache(nil) succeeded, want an error")
+	}
+
+	cache := NewManifestCache()
+	for i, wantStatuses := range [][]int{
+		{http.StatusOK},
+		// The tag hasn't moved, so the registry doesn't send the manifest.
+		{http.StatusNotModified},
+	} {
+		desc, err := Get(tag, WithManifestCache(cache))
+		if err != nil {
+			t.Fatalf("Get() #%d = %v", i, err)
+		}
+		if desc.Digest != want {
+			t.Errorf("Get() #%d digest = %s, want %s", i, desc.Digest, want)
+		}
+		if diff := cmp.Diff(wantStatuses, requests()); diff != "" {
+			t.Errorf("Get() #%d manifest requests (-want +got): %s", i, diff)
+		}
+	}
+
+	// Fetching by digest doesn't need the registry at all.
+	rmt, err := Image(tag.Context().Digest(want.String()), WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := rmt.Digest(); err != nil || got != want {
+		t.Errorf("Image() digest = %s, %v; want %s", got, err, want)
+	}
+	if got := requests(); len(got) != 0 {
+		t.Errorf("Image() by digest made manifest requests: %v", got)
+	}
+
+	// Once the tag moves, the new manifest is fetched.
+	img, err = random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(tag, img); err != nil {
+		t.Fatal(err)
+	}
+	requests()
+	want, err = img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := Get(tag, WithManifestCache(cache))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest != want {
+		t.Errorf("Get() after the tag moved = %s, want %s", desc.Digest, want)
+	}
+	if diff := cmp.Diff([]int{http.StatusOK}, requests()); diff != "" {
+		t.Errorf("Get() after the tag moved, manifest requests (-want +got): %s", diff)
+	}
+}
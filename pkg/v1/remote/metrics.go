@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Metrics receives measurements of what remote operations do over HTTP, to
+// export to a monitoring system such as Prometheus or OpenTelemetry. Its
+// methods are called concurrently, and should return quickly. See
+// WithMetrics.
+type Metrics interface {
+	// Request is called when each HTTP request, including retries and
+	// token exchanges, gets a response or fails.
+	Request(RequestMetric)
+
+	// Uploaded and Downloaded are called with the number of bytes of
+	// request and response bodies as they are sent and received.
+	Uploaded(n int64)
+	Downloaded(n int64)
+
+	// Retried is called each time a request, or the upload of a blob, is
+	// retried.
+	Retried()
+
+	// Mounted is called after each attempt to mount a blob from another
+	// repository, with whether the registry mounted it.
+	Mounted(hit bool)
+
+	// TokenExchanged is called after each exchange of credentials for a
+	// bearer token, with nil if it succeeded, or a *transport.TokenError
+	// whose Reason says why it failed. See transport.WithTokenExchanged.
+	TokenExchanged(err error)
+}
+
+// RequestMetric describes an HTTP request, for Metrics.
+type RequestMetric struct {
+	Method string
+	// Host is where the request was sent: the registry, or e.g. the blob
+	// storage or token service it sent the client to.
+	Host string
+	// Status is the status code of the response, or 0 if there wasn't one.
+	Status int
+	// Duration is how long it took to get the response's headers.
+	Duration time.Duration
+}
+
+// WithMetrics reports the requests that Pusher, Puller and the functions of
+// this package make, the bytes they transfer, the retries, blob mounts and
+// token exchanges they do, to m.
+//
+// Requests made with a transport.Wrapper given to WithTransport aren't
+// measured, since it is used as is.
+func WithMetrics(m Metrics) Option {
+	return func(o *options) error {
+		o.metrics = m
+		return nil
+	}
+}
+
+// tokenOptions returns the transport options that report token exchanges to
+// m, if it isn't nil.
+func tokenOptions(m Metrics) []transport.Option {
+	if m == nil {
+		return nil
+	}
+	return []transport.Option{transport.WithTokenExchanged(m.TokenExchanged)}
+}
+
+// metricsTransport reports the requests made through it to metrics.
+type metricsTransport struct {
+	inner   http.RoundTripper
+	metrics Metrics
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		req = req.Clone(req.Context())
+		req.Body = &countingBody{ReadCloser: req.Body, count: t.metrics.Uploaded}
+	}
+	start := time.Now()
+	resp, err := t.inner.RoundTrip(req)
+	m := RequestMetric{Method: req.Method, Host: req.URL.Host, Duration: time.Since(start)}
+	if resp != nil {
+		m.Status = resp.StatusCode
+		resp.Body = &countingBody{ReadCloser: resp.Body, count: t.metrics.Downloaded}
+	}
+	t.metrics.Request(m)
+	return resp, err
+}
+
+// countingBody calls count with the size of every read.
+type countingBody struct {
+	io.ReadCloser
+	count func(int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.count(int64(n))
+	}
+	return n, err
+}
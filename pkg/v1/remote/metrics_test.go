@@ -0,0 +1,216 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// recordedMetrics implements Metrics by counting.
+type recordedMetrics struct {
+	mu         sync.Mutex
+	requests   map[string]int // by "METHOD STATUS"
+	uploaded   int64
+	downloaded int64
+	retries    int
+	hits       int
+	misses     int
+	tokens     []error
+}
+
+func (m *recordedMetrics) Request(r RequestMetric) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.requests == nil {
+		m.requests = map[string]int{}
+	}
+	m.requests[r.Method+" "+http.StatusText(r.Status)]++
+}
+
+func (m *recordedMetrics) Uploaded(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploaded += n
+}
+
+func (m *recordedMetrics) Downloaded(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.downloaded += n
+}
+
+func (m *recordedMetrics) Retried() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *recordedMetrics) Mounted(hit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if hit {
+		m.hits++
+	} else {
+		m.misses++
+	}
+}
+
+func (m *recordedMetrics) TokenExchanged(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tokens = append(m.tokens, err)
+}
+
+func TestWithMetrics(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.ProfileHarbor())
+	var failed atomic.Bool
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Blobs are shared between repositories, so hide them from dst to
+		// make the writer mount them.
+		if r.Method == http.MethodHead && strings.HasPrefix(r.URL.Path, "/v2/dst/blobs/") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// Fail the first manifest upload, to be retried.
+		if r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/") && !failed.Swap(true) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	host := strings.TrimPrefix(s.URL, "http://")
+
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layerSize := int64(0)
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		n, err := l.Size()
+		if err != nil {
+			t.Fatal(err)
+		}
+		layerSize += n
+	}
+
+	m := &recordedMetrics{}
+	src := mustNewTag(t, host+"/src:latest")
+	if err := Write(src, img, WithMetrics(m)); err != nil {
+		t.Fatal(err)
+	}
+	if m.uploaded < layerSize {
+		t.Errorf("uploaded %d bytes, want at least the %d of the layers", m.uploaded, layerSize)
+	}
+	if m.retries != 1 {
+		t.Errorf("retries = %d, want 1", m.retries)
+	}
+	if got := m.requests["PUT "+http.StatusText(http.StatusServiceUnavailable)]; got != 1 {
+		t.Errorf("PUT requests that failed = %d, want 1: %v", got, m.requests)
+	}
+	if got := m.requests["PUT "+http.StatusText(http.StatusCreated)]; got < len(layers)+1 {
+		t.Errorf("PUT requests that succeeded = %d, want at least %d: %v", got, len(layers)+1, m.requests)
+	}
+
+	// Copying within the registry mounts the layers, and downloads nothing.
+	m = &recordedMetrics{}
+	pulled, err := Image(src, WithMetrics(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := mustNewTag(t, host+"/dst:latest")
+	if err := Write(dst, pulled, WithMetrics(m)); err != nil {
+		t.Fatal(err)
+	}
+	if m.hits < len(layers) || m.misses != 0 {
+		t.Errorf("mounts: %d hits and %d misses, want %d hits", m.hits, m.misses, len(layers))
+	}
+	if m.downloaded >= layerSize {
+		t.Errorf("downloaded %d bytes, want less than the %d of the layers", m.downloaded, layerSize)
+	}
+
+	m = &recordedMetrics{}
+	got, err := Image(dst, WithMetrics(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Fatal(err)
+	}
+	if m.downloaded < layerSize {
+		t.Errorf("downloaded %d bytes, want at least the %d of the layers", m.downloaded, layerSize)
+	}
+	if m.requests["GET OK"] == 0 {
+		t.Errorf("no successful GETs: %v", m.requests)
+	}
+}
+
+func TestWithMetricsTokenExchanges(t *testing.T) {
+	var realm string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if _, pass, _ := r.BasicAuth(); pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token": "t"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", realm))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+	realm = s.URL + "/token"
+	ref := mustNewTag(t, strings.TrimPrefix(s.URL, "http://")+"/repo:latest")
+
+	m := &recordedMetrics{}
+	if _, _, err := Exists(ref, WithAuth(&authn.Basic{Username: "user", Password: "secret"}), WithMetrics(m)); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.tokens) != 1 || m.tokens[0] != nil {
+		t.Errorf("token exchanges = %v, want one that succeeded", m.tokens)
+	}
+
+	m = &recordedMetrics{}
+	if _, _, err := Exists(ref, WithAuth(&authn.Basic{Username: "user", Password: "wrong"}), WithMetrics(m)); !errors.Is(err, transport.ErrBadCredentials) {
+		t.Errorf("Exists() = %v, want bad credentials", err)
+	}
+	if len(m.tokens) != 1 || !errors.Is(m.tokens[0], transport.ErrBadCredentials) {
+		t.Errorf("token exchanges = %v, want one with bad credentials", m.tokens)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// hubTransport sends requests for Docker Hub to a local server instead.
+type hubTransport struct {
+	host string
+}
+
+func (t hubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Host == name.DefaultRegistry {
+		req = req.Clone(req.Context())
+		req.URL.Scheme = "http"
+		req.URL.Host = t.host
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func mustParseRef(t *testing.T, s string) name.Reference {
+	t.Helper()
+	ref, err := name.ParseReference(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ref
+}
+
+func TestMirrors(t *testing.T) {
+	newServer := func(reads *atomic.Int32) *httptest.Server {
+		reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/library/") {
+				reads.Add(1)
+			}
+			reg.ServeHTTP(w, r)
+		}))
+	}
+
+	var originReads, mirrorReads atomic.Int32
+	origin := newServer(&originReads)
+	defer origin.Close()
+	mirror := newServer(&mirrorReads)
+	defer mirror.Close()
+
+	ou, err := url.Parse(origin.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu, err := url.Parse(mirror.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := hubTransport{host: ou.Host}
+	mreg, err := name.NewRegistry(mu.Host)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mirrored, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(mustParseRef(t, mu.Host+"/library/mirrored"), mirrored); err != nil {
+		t.Fatal(err)
+	}
+	unmirrored, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(name.MustParseReference("unmirrored"), unmirrored, WithTransport(tr)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name                   string
+		ref                    string
+		wantOrigin, wantMirror bool
+	}{{
+		name:       "served by mirror",
+		ref:        "mirrored",
+		wantMirror: true,
+	}, {
+		name:       "falls back to origin",
+		ref:        "unmirrored",
+		wantOrigin: true,
+		wantMirror: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			originReads.Store(0)
+			mirrorReads.Store(0)
+
+			img, err := Image(mustParseRef(t, tc.ref), WithTransport(tr), WithMirrors([]name.Registry{mreg}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validate.Image(img); err != nil {
+				t.Errorf("validate.Image: %v", err)
+			}
+
+			if got := originReads.Load() > 0; got != tc.wantOrigin {
+				t.Errorf("read from origin = %t, want %t", got, tc.wantOrigin)
+			}
+			if got := mirrorReads.Load() > 0; got != tc.wantMirror {
+				t.Errorf("read from mirror = %t, want %t", got, tc.wantMirror)
+			}
+		})
+	}
+
+	// Only Docker Hub is mirrored.
+	if _, err := Image(mustParseRef(t, ou.Host+"/library/mirrored"), WithMirrors([]name.Registry{mreg})); err == nil {
+		t.Error("Image() on a non-Docker Hub registry was served by a mirror")
+	}
+}
@@ -28,6 +28,21 @@ type MountableLayer struct {
 	Reference name.Reference
 }
 
+// asMountable returns l as a MountableLayer, if it is one or wraps one (see
+// mutate.Seal). Wrappers expose the layer they wrap with Unwrap.
+func asMountable(l v1.Layer) (*MountableLayer, bool) {
+	for {
+		if ml, ok := l.(*MountableLayer); ok {
+			return ml, true
+		}
+		u, ok := l.(interface{ Unwrap() v1.Layer })
+		if !ok {
+			return nil, false
+		}
+		l = u.Unwrap()
+	}
+}
+
 // Descriptor retains the original descriptor from an image manifest.
 // See partial.Descriptor.
 func (ml *MountableLayer) Descriptor() (*v1.Descriptor, error) {
@@ -39,6 +54,11 @@ func (ml *MountableLayer) Exists() (bool, error) {
 	return partial.Exists(ml.Layer)
 }
 
+// UncompressedSize implements partial.WithUncompressedSize.
+func (ml *MountableLayer) UncompressedSize() (int64, error) {
+	return partial.UncompressedSize(ml.Layer)
+}
+
 // mountableImage wraps the v1.Layer references returned by the embedded v1.Image
 // in MountableLayer's so that remote.Write might attempt to mount them from their
 // source repository.
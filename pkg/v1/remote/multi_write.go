@@ -21,7 +21,8 @@ import (
 
 // MultiWrite writes the given Images or ImageIndexes to the given refs, as
 // efficiently as possible, by deduping shared layer blobs while uploading them
-// in parallel.
+// in parallel. Blobs that were already written to one repository are mounted
+// into other repositories on the same registry rather than uploaded again.
 func MultiWrite(todo map[name.Reference]Taggable, options ...Option) (rerr error) {
 	o, err := makeOptions(options...)
 	if err != nil {
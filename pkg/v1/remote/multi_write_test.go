@@ -23,6 +23,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/google/go-containerregistry/pkg/authn"
@@ -172,6 +173,88 @@ func TestMultiWrite(t *testing.T) {
 	}
 }
 
+func TestPusherMountsFromSiblingRepos(t *testing.T) {
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal("random.Image:", err)
+	}
+
+	var mu sync.Mutex
+	patches := map[string]int{}
+	mounts := map[string]string{}
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		repo, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/v2/"), "/blobs/")
+		if r.Method == http.MethodHead && repo == "second/repo" && strings.Contains(r.URL.Path, "/blobs/") {
+			// Our registry shares blobs between repositories, so pretend
+			// they're missing to exercise mounting.
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		switch r.Method {
+		case http.MethodPatch:
+			patches[repo]++
+		case http.MethodPost:
+			if from := r.URL.Query().Get("from"); from != "" {
+				mounts[repo+"@"+r.URL.Query().Get("mount")] = from
+				mu.Unlock()
+				// Our registry doesn't implement mounting, but the blob
+				// is already there.
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+		}
+		mu.Unlock()
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPusher()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := p.Push(ctx, mustNewTag(t, u.Host+"/first/repo:tag"), img); err != nil {
+		t.Fatal("Push:", err)
+	}
+	if err := p.Push(ctx, mustNewTag(t, u.Host+"/second/repo:tag"), img); err != nil {
+		t.Fatal("Push:", err)
+	}
+
+	if got, want := patches["first/repo"], 4; got != want {
+		t.Errorf("uploads to first/repo: got %d, want %d", got, want)
+	}
+	if got := patches["second/repo"]; got != 0 {
+		t.Errorf("uploads to second/repo: got %d, want 0", got)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := mounts["second/repo@"+h.String()]; got != "first/repo" {
+			t.Errorf("mount of %s: got from=%q, want %q", h, got, "first/repo")
+		}
+	}
+
+	got, err := Image(mustNewTag(t, u.Host+"/second/repo:tag"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Error("Validate() =", err)
+	}
+}
+
 func TestMultiWriteWithNondistributableLayer(t *testing.T) {
 	// Create a random image.
 	img1, err := random.Image(1024, 2)
@@ -16,16 +16,20 @@ package remote
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"path"
 	"syscall"
 	"time"
 
 	"github.com/google/go-containerregistry/internal/retry"
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 )
@@ -36,6 +40,7 @@ type Option func(*options) error
 type options struct {
 	auth                           authn.Authenticator
 	keychain                       authn.Keychain
+	authTimeout                    time.Duration
 	transport                      http.RoundTripper
 	context                        context.Context
 	jobs                           int
@@ -43,13 +48,29 @@ type options struct {
 	allowNondistributableArtifacts bool
 	progress                       *progress
 	retryBackoff                   Backoff
+	retryBackoffSet                bool
 	retryPredicate                 retry.Predicate
 	retryStatusCodes               []int
+	retryBudget                    *int
+	mountWait                      time.Duration
+	verifyDigests                  bool
+	verifier                       Verifier
+	downloadChunks                 int
+	fetchPool                      int
+	manifestCache                  ManifestCache
+	mirrors                        []name.Registry
+	insecureRegistries             []string
+	identityEncoding               []string
+	metrics                        Metrics
 
 	// Only these options can overwrite Reuse()d options.
 	platform v1.Platform
-	pageSize int
-	filter   map[string]string
+	// platformSet is whether WithPlatform was passed, for Exists, which
+	// only checks the platform when asked to.
+	platformSet bool
+	pageSize    int
+	last        string
+	filter      map[string]string
 
 	// Set by Reuse, we currently store one or the other.
 	puller *Puller
@@ -152,18 +173,56 @@ func makeOptions(opts ...Option) (*options, error) {
 		o.auth = authn.Anonymous
 	}
 
+	if o.retryBudget != nil {
+		o.retryPredicate = retry.NewBudget(*o.retryBudget).Wrap(o.retryPredicate)
+	}
+	if o.metrics != nil {
+		// Predicates are only consulted when there is another attempt left.
+		p := o.retryPredicate
+		o.retryPredicate = func(err error) bool {
+			if p(err) {
+				o.metrics.Retried()
+				return true
+			}
+			return false
+		}
+	}
+
+	if len(o.insecureRegistries) != 0 {
+		t, ok := o.transport.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("WithInsecureRegistries needs an *http.Transport, got %T", o.transport)
+		}
+		rt, err := transport.NewInsecureRegistries(t, o.insecureRegistries...)
+		if err != nil {
+			return nil, err
+		}
+		o.transport = rt
+	}
+
 	// transport.Wrapper is a signal that consumers are opt-ing into providing their own transport without any additional wrapping.
 	// This is to allow consumers full control over the transports logic, such as providing retry logic.
 	if _, ok := o.transport.(*transport.Wrapper); !ok {
+		// Measure each attempt, beneath the retries.
+		if o.metrics != nil {
+			o.transport = &metricsTransport{inner: o.transport, metrics: o.metrics}
+		}
+
 		// Wrap the transport in something that logs requests and responses.
 		// It's expensive to generate the dumps, so skip it if we're writing
 		// to nothing.
-		if logs.Enabled(logs.Debug) {
+		if logs.Enabled(logs.DebugFor(logs.Transport)) {
 			o.transport = transport.NewLogger(o.transport)
 		}
 
 		// Wrap the transport in something that can retry network flakes.
-		o.transport = transport.NewRetry(o.transport, transport.WithRetryPredicate(defaultRetryPredicate), transport.WithRetryStatusCodes(o.retryStatusCodes...))
+		topts := []transport.Option{transport.WithRetryPredicate(o.retryPredicate), transport.WithRetryStatusCodes(o.retryStatusCodes...)}
+		if o.retryBackoffSet {
+			// Otherwise, requests are retried quickly, since uploads are
+			// retried with the slower default backoff anyway.
+			topts = append(topts, transport.WithRetryBackoff(o.retryBackoff))
+		}
+		o.transport = transport.NewRetry(o.transport, topts...)
 
 		// Wrap this last to prevent transport.New from double-wrapping.
 		if o.userAgent != "" {
@@ -187,6 +246,136 @@ func WithTransport(t http.RoundTripper) Option {
 	}
 }
 
+// TransportConfig tunes the *http.Transport used for remote operations.
+//
+// Zero values leave the corresponding DefaultTransport setting unchanged.
+type TransportConfig struct {
+	// MaxIdleConns limits idle connections across all hosts.
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost limits idle connections kept per host. Raise this
+	// along with WithJobs, otherwise parallel requests beyond the limit
+	// will churn connections.
+	MaxIdleConnsPerHost int
+
+	// MaxConnsPerHost limits the total number of connections per host,
+	// including ones in use.
+	MaxConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration
+
+	// DialTimeout bounds how long establishing a TCP connection may take.
+	DialTimeout time.Duration
+
+	// TLSHandshakeTimeout bounds how long a TLS handshake may take.
+	TLSHandshakeTimeout time.Duration
+
+	// ResponseHeaderTimeout bounds how long each request waits for the
+	// response headers after the request has been written. It does not
+	// limit how long reading the body may take.
+	ResponseHeaderTimeout time.Duration
+
+	// TLSClientConfig, if set, replaces the TLS configuration.
+	TLSClientConfig *tls.Config
+
+	// DisableHTTP2 forces HTTP/1.1. Some registries and proxies perform
+	// better with many HTTP/1.1 connections than with a single multiplexed
+	// HTTP/2 connection per host.
+	DisableHTTP2 bool
+}
+
+// NewTransport returns a copy of DefaultTransport with cfg applied.
+func NewTransport(cfg TransportConfig) *http.Transport {
+	base, ok := DefaultTransport.(*http.Transport)
+	if !ok {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	t := base.Clone()
+
+	if cfg.MaxIdleConns != 0 {
+		t.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost != 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost != 0 {
+		t.MaxConnsPerHost = cfg.MaxConnsPerHost
+	}
+	if cfg.IdleConnTimeout != 0 {
+		t.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.DialTimeout != 0 {
+		t.DialContext = (&net.Dialer{
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext
+	}
+	if cfg.TLSHandshakeTimeout != 0 {
+		t.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout != 0 {
+		t.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	if cfg.TLSClientConfig != nil {
+		t.TLSClientConfig = cfg.TLSClientConfig
+	}
+	if cfg.DisableHTTP2 {
+		t.ForceAttemptHTTP2 = false
+		// A non-nil, empty map disables HTTP/2 negotiation via ALPN.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return t
+}
+
+// WithTransportConfig is a functional option for tuning the transport used
+// for remote operations, e.g. to allow more concurrent connections when
+// mirroring many images with a high WithJobs.
+//
+// It is shorthand for WithTransport(NewTransport(cfg)), so it replaces any
+// transport set by an earlier WithTransport.
+func WithTransportConfig(cfg TransportConfig) Option {
+	return WithTransport(NewTransport(cfg))
+}
+
+// WithInsecureRegistries skips verifying the TLS certificates of the given
+// registries, e.g. ones with self-signed certificates, while still verifying
+// any others. Registries may contain path.Match wildcards, e.g. "*.internal".
+// See transport.NewInsecureRegistries.
+//
+// It applies to the transport set by WithTransport, which must be an
+// *http.Transport (as DefaultTransport is); wrap the result of
+// transport.NewInsecureRegistries yourself for other transports.
+func WithInsecureRegistries(registries ...string) Option {
+	return func(o *options) error {
+		o.insecureRegistries = append(o.insecureRegistries, registries...)
+		return nil
+	}
+}
+
+// WithIdentityEncoding makes blob downloads from the given registries ask for
+// "Accept-Encoding: identity", rather than letting Go's transport advertise
+// gzip and transparently decode the response. Some registries and the CDNs
+// they redirect to gzip layers, which are already compressed, again when gzip
+// is advertised, which breaks Content-Length and wastes CPU at both ends.
+// Registries may contain path.Match wildcards, e.g. "*.example.com".
+//
+// This only stops advertising gzip. A response that is gzip-encoded anyway is
+// decoded before the blob's size and digest are verified, so a registry that
+// labels a layer as gzip-encoded when it is only gzip-compressed still fails
+// verification.
+func WithIdentityEncoding(registries ...string) Option {
+	return func(o *options) error {
+		for _, reg := range registries {
+			if _, err := path.Match(reg, ""); err != nil {
+				return fmt.Errorf("invalid registry pattern %q: %w", reg, err)
+			}
+		}
+		o.identityEncoding = append(o.identityEncoding, registries...)
+		return nil
+	}
+}
+
 // WithAuth is a functional option for overriding the default authenticator
 // for remote operations.
 // It is an error to use both WithAuth and WithAuthFromKeychain in the same Option set.
@@ -212,6 +401,26 @@ func WithAuthFromKeychain(keys authn.Keychain) Option {
 	}
 }
 
+// WithAuthTimeout bounds how long the keychain given by WithAuthFromKeychain
+// may take to resolve credentials, e.g. so that a hung cloud metadata server
+// or credential helper fails the operation rather than blocking it forever.
+// The timeout only applies to resolving credentials, not to the transfers
+// that follow, which are still bounded by WithContext alone.
+//
+// Keychains that implement authn.ContextKeychain are cancelled when the
+// timeout passes; others are abandoned to finish in the background.
+//
+// By default, keychains may take as long as the context allows.
+func WithAuthTimeout(d time.Duration) Option {
+	return func(o *options) error {
+		if d <= 0 {
+			return errors.New("auth timeout must be greater than zero")
+		}
+		o.authTimeout = d
+		return nil
+	}
+}
+
 // WithPlatform is a functional option for overriding the default platform
 // that Image and Descriptor.Image use for resolving an index to an image.
 //
@@ -219,6 +428,7 @@ func WithAuthFromKeychain(keys authn.Keychain) Option {
 func WithPlatform(p v1.Platform) Option {
 	return func(o *options) error {
 		o.platform = p
+		o.platformSet = true
 		return nil
 	}
 }
@@ -252,6 +462,36 @@ func WithJobs(jobs int) Option {
 	}
 }
 
+// WithFetchPool bounds the number of blobs read at once from each
+// repository to n, so that many goroutines reading the layers of an image (or
+// of the images of an index) share n connections to the registry rather than
+// each opening their own. The other goroutines wait for a blob to be read to
+// the end, or its reader closed.
+func WithFetchPool(n int) Option {
+	return func(o *options) error {
+		if n <= 0 {
+			return errors.New("fetch pool size must be greater than zero")
+		}
+		o.fetchPool = n
+		return nil
+	}
+}
+
+// WithManifestCache caches the manifests that are fetched in c. Manifests
+// fetched by digest are read from c rather than the registry once they are
+// in it; manifests fetched by tag are fetched with If-None-Match, so that
+// tools polling a tag only download its manifest again when it changes.
+// See NewManifestCache for a cache in memory.
+func WithManifestCache(c ManifestCache) Option {
+	return func(o *options) error {
+		if c == nil {
+			return errors.New("manifest cache must not be nil")
+		}
+		o.manifestCache = c
+		return nil
+	}
+}
+
 // WithUserAgent adds the given string to the User-Agent header for any HTTP
 // requests. This header will also include "go-containerregistry/${version}".
 //
@@ -296,15 +536,30 @@ func WithPageSize(size int) Option {
 	}
 }
 
-// WithRetryBackoff sets the httpBackoff for retry HTTP operations.
+// WithLast makes List and Lister return only the tags that sort after
+// last, so that a listing can be resumed from the last tag it returned.
+//
+// This relies on the registry supporting the "last" query parameter of the
+// distribution spec, which most do.
+func WithLast(last string) Option {
+	return func(o *options) error {
+		o.last = last
+		return nil
+	}
+}
+
+// WithRetryBackoff sets the httpBackoff for retry HTTP operations: each
+// request, including the ping that starts authentication, and each upload.
 func WithRetryBackoff(backoff Backoff) Option {
 	return func(o *options) error {
 		o.retryBackoff = backoff
+		o.retryBackoffSet = true
 		return nil
 	}
 }
 
-// WithRetryPredicate sets the predicate for retry HTTP operations.
+// WithRetryPredicate sets the predicate for retry HTTP operations: each
+// request, including the ping that starts authentication, and each upload.
 func WithRetryPredicate(predicate retry.Predicate) Option {
 	return func(o *options) error {
 		o.retryPredicate = predicate
@@ -312,6 +567,25 @@ func WithRetryPredicate(predicate retry.Predicate) Option {
 	}
 }
 
+// WithRetryBudget limits the number of times the requests and uploads of an
+// operation (e.g. a Write or a Get) are retried, in total, to n. Once the
+// budget is spent, failures are returned without being retried, so that a
+// struggling registry can't hold an operation up for the sum of every
+// request's backoff. A Puller or Pusher spends one budget on all of its
+// operations.
+//
+// By default, retries are only limited by the backoff of each request and
+// upload.
+func WithRetryBudget(n int) Option {
+	return func(o *options) error {
+		if n < 0 {
+			return fmt.Errorf("invalid retry budget %d", n)
+		}
+		o.retryBudget = &n
+		return nil
+	}
+}
+
 // WithRetryStatusCodes sets which http response codes will be retried.
 func WithRetryStatusCodes(codes ...int) Option {
 	return func(o *options) error {
@@ -320,6 +594,52 @@ func WithRetryStatusCodes(codes ...int) Option {
 	}
 }
 
+// WithMountWait sets how long to wait for a blob to appear when a registry
+// responds to a cross-repository mount with 202 Accepted instead of 201 Created.
+//
+// Some registries (e.g. Artifact Registry) copy mounted blobs asynchronously,
+// so a blob that wasn't mounted immediately may appear shortly afterwards.
+// While waiting, the blob is polled with HEAD requests; if it doesn't appear
+// in time, it is uploaded as usual. By default, there is no wait.
+func WithMountWait(d time.Duration) Option {
+	return func(o *options) error {
+		o.mountWait = d
+		return nil
+	}
+}
+
+// WithDigestVerification makes pushes fail unless every manifest lands in the
+// registry with the digest it was pushed as.
+//
+// Manifests are always pushed as their original bytes (RawManifest), never
+// re-marshalled, so copying a Descriptor preserves its digest and any
+// signatures that refer to it. With this option, the pushed bytes are also
+// checked against the digest the manifest claims to have (and against the
+// reference, if it is a digest), and the registry's Docker-Content-Digest
+// response header, if present, is checked against the pushed bytes.
+func WithDigestVerification() Option {
+	return func(o *options) error {
+		o.verifyDigests = true
+		return nil
+	}
+}
+
+// WithMirrors sets registry mirrors to pull Docker Hub images through, like
+// the "registry-mirrors" setting in Docker's daemon.json.
+//
+// Manifest and blob reads from repositories on Docker Hub are tried against
+// each mirror in order, falling back to Docker Hub itself if no mirror can
+// serve them. Other registries are never mirrored, and writes always go to
+// Docker Hub. Mirrors are authenticated with the keychain passed to
+// WithAuthFromKeychain, or anonymously; credentials passed to WithAuth are
+// only ever sent to Docker Hub.
+func WithMirrors(mirrors []name.Registry) Option {
+	return func(o *options) error {
+		o.mirrors = mirrors
+		return nil
+	}
+}
+
 // WithFilter sets the filter querystring for HTTP operations.
 func WithFilter(key string, value string) Option {
 	return func(o *options) error {
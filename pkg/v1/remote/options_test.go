@@ -0,0 +1,328 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func TestNewTransport(t *testing.T) {
+	def := DefaultTransport.(*http.Transport)
+
+	got := NewTransport(TransportConfig{})
+	if got == def {
+		t.Fatal("NewTransport returned DefaultTransport, want a copy")
+	}
+	if got.MaxIdleConnsPerHost != def.MaxIdleConnsPerHost || got.IdleConnTimeout != def.IdleConnTimeout || !got.ForceAttemptHTTP2 {
+		t.Errorf("zero config changed defaults: %+v", got)
+	}
+
+	tc := &tls.Config{ServerName: "example.com"}
+	got = NewTransport(TransportConfig{
+		MaxIdleConns:          500,
+		MaxIdleConnsPerHost:   200,
+		MaxConnsPerHost:       300,
+		IdleConnTimeout:       time.Minute,
+		TLSHandshakeTimeout:   time.Second,
+		ResponseHeaderTimeout: 2 * time.Second,
+		TLSClientConfig:       tc,
+		DisableHTTP2:          true,
+	})
+	if got.MaxIdleConns != 500 || got.MaxIdleConnsPerHost != 200 || got.MaxConnsPerHost != 300 {
+		t.Errorf("connection limits not applied: %+v", got)
+	}
+	if got.IdleConnTimeout != time.Minute || got.TLSHandshakeTimeout != time.Second || got.ResponseHeaderTimeout != 2*time.Second {
+		t.Errorf("timeouts not applied: %+v", got)
+	}
+	if got.TLSClientConfig != tc {
+		t.Errorf("TLSClientConfig not applied")
+	}
+	if got.ForceAttemptHTTP2 || got.TLSNextProto == nil {
+		t.Errorf("HTTP/2 not disabled")
+	}
+	if def.MaxIdleConnsPerHost == 200 {
+		t.Errorf("DefaultTransport was modified")
+	}
+}
+
+func TestWithTransportConfigTimeout(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		time.Sleep(500 * time.Millisecond)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(fmt.Sprintf("%s/slow:latest", u.Host))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = Head(ref, WithTransportConfig(TransportConfig{ResponseHeaderTimeout: 50 * time.Millisecond}), WithRetryBackoff(fastBackoff))
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if d := time.Since(start); d > 400*time.Millisecond {
+		t.Errorf("Head took %s, want the response header timeout to apply", d)
+	}
+}
+
+func TestWithInsecureRegistries(t *testing.T) {
+	if _, err := makeOptions(WithInsecureRegistries("registry.internal")); err != nil {
+		t.Errorf("WithInsecureRegistries() with DefaultTransport: %v", err)
+	}
+	if _, err := makeOptions(WithTransport(http.NewFileTransport(http.Dir("."))), WithInsecureRegistries("registry.internal")); err == nil {
+		t.Error("WithInsecureRegistries() with a wrapped transport succeeded, want error")
+	}
+}
+
+func TestRetryOptions(t *testing.T) {
+	var pings, gets atomic.Int64
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/" {
+			// The first ping fails.
+			if pings.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		gets.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/flaky:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	backoff := Backoff{Duration: time.Millisecond, Factor: 1, Steps: 5}
+
+	for _, tc := range []struct {
+		desc                string
+		opts                []Option
+		wantPings, wantGets int64
+	}{{
+		desc:      "backoff",
+		opts:      []Option{WithRetryBackoff(backoff)},
+		wantPings: 2,
+		wantGets:  5,
+	}, {
+		desc:      "predicate",
+		opts:      []Option{WithRetryBackoff(backoff), WithRetryPredicate(func(error) bool { return false })},
+		wantPings: 1,
+		wantGets:  0,
+	}, {
+		desc:      "budget",
+		opts:      []Option{WithRetryBackoff(backoff), WithRetryBudget(3)},
+		wantPings: 2,
+		wantGets:  3,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			pings.Store(0)
+			gets.Store(0)
+			if _, err := Get(ref, tc.opts...); err == nil {
+				t.Fatal("Get() succeeded, want an error")
+			}
+			if got := pings.Load(); got != tc.wantPings {
+				t.Errorf("pinged %d times, want %d", got, tc.wantPings)
+			}
+			if got := gets.Load(); got != tc.wantGets {
+				t.Errorf("requested the manifest %d times, want %d", got, tc.wantGets)
+			}
+		})
+	}
+
+	if _, err := makeOptions(WithRetryBudget(-1)); err == nil {
+		t.Error("WithRetryBudget(-1) succeeded, want an error")
+	}
+}
+
+// slowKeychain takes delay to resolve anything, ignoring the context unless
+// it is wrapped in contextKeychain.
+type slowKeychain struct{ delay time.Duration }
+
+func (k slowKeychain) Resolve(authn.Resource) (authn.Authenticator, error) {
+	time.Sleep(k.delay)
+	return authn.Anonymous, nil
+}
+
+type contextKeychain struct{ slowKeychain }
+
+func (k contextKeychain) ResolveContext(ctx context.Context, _ authn.Resource) (authn.Authenticator, error) {
+	select {
+	case <-time.After(k.delay):
+		return authn.Anonymous, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestWithAuthTimeout(t *testing.T) {
+	s := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/auth/timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		desc    string
+		kc      authn.Keychain
+		wantErr bool
+	}{{
+		desc: "fast",
+		kc:   slowKeychain{time.Millisecond},
+	}, {
+		desc:    "hung",
+		kc:      slowKeychain{time.Hour},
+		wantErr: true,
+	}, {
+		desc:    "hung with context",
+		kc:      contextKeychain{slowKeychain{time.Hour}},
+		wantErr: true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			opts := []Option{WithAuthFromKeychain(tc.kc), WithAuthTimeout(50 * time.Millisecond)}
+			if _, err := Image(ref, opts...); !tc.wantErr && err != nil {
+				t.Errorf("Image() = %v", err)
+			} else if tc.wantErr && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Image() = %v, want %v", err, context.DeadlineExceeded)
+			}
+			if err := Write(ref, img, opts...); !tc.wantErr && err != nil {
+				t.Errorf("Write() = %v", err)
+			} else if tc.wantErr && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Write() = %v, want %v", err, context.DeadlineExceeded)
+			}
+		})
+	}
+
+	if _, err := makeOptions(WithAuthTimeout(0)); err == nil {
+		t.Error("WithAuthTimeout(0) succeeded, want error")
+	}
+}
+
+func TestWithIdentityEncoding(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	var mode string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.Contains(r.URL.Path, "/blobs/") {
+			reg.ServeHTTP(w, r)
+			return
+		}
+		rec := httptest.NewRecorder()
+		reg.ServeHTTP(rec, r)
+		blob := rec.Body.Bytes()
+		gzipAccepted := strings.Contains(r.Header.Get("Accept-Encoding"), "gzip")
+		switch {
+		case mode == "mislabel" && gzipAccepted:
+			// Claim the (already gzipped) blob is gzip-encoded.
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Write(blob)
+		case mode == "always":
+			// Compress the blob again, whatever was asked for.
+			w.Header().Set("Content-Encoding", "gzip")
+			zw := gzip.NewWriter(w)
+			zw.Write(blob)
+			zw.Close()
+		default:
+			w.Write(blob)
+		}
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/encoding/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	pull := func(opts ...Option) error {
+		got, err := Image(ref, opts...)
+		if err != nil {
+			return err
+		}
+		return validate.Image(got)
+	}
+
+	mode = "mislabel"
+	if err := pull(); err == nil {
+		t.Error("pulling mislabelled blobs succeeded without WithIdentityEncoding")
+	}
+	if err := pull(WithIdentityEncoding("127.0.0.*")); err != nil {
+		t.Errorf("pulling mislabelled blobs: %v", err)
+	}
+	if err := pull(WithIdentityEncoding("example.com")); err == nil {
+		t.Error("WithIdentityEncoding applied to a registry it doesn't match")
+	}
+
+	mode = "always"
+	if err := pull(WithIdentityEncoding(u.Hostname() + ":*")); err != nil {
+		t.Errorf("pulling gzip-encoded blobs: %v", err)
+	}
+
+	if _, err := makeOptions(WithIdentityEncoding("[")); err == nil {
+		t.Error("WithIdentityEncoding([) succeeded, want error")
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"golang.org/x/sync/errgroup"
+)
+
+// Prefetch reads the layers of the image that ref refers to into c, so that
+// later pulls through c (see cache.Image) find them there, e.g. to warm a
+// node-local cache. If ref refers to an index, the layers of each of its
+// images are read, or of only those that match the platform given with
+// WithPlatform.
+//
+// Layers are read WithJobs at a time (four by default). Layers that are
+// already in c, or that are shared between images, are read once; foreign
+// layers are skipped unless WithNondistributable is given.
+func Prefetch(ref name.Reference, c cache.Cache, options ...Option) error {
+	o, err := makeOptions(options...)
+	if err != nil {
+		return err
+	}
+	desc, err := newPuller(o).get(o.context, ref, allManifestMediaTypes, o.platform)
+	if err != nil {
+		return err
+	}
+
+	var imgs []v1.Image
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		if imgs, err = partial.FindImages(idx, func(child v1.Descriptor) bool {
+			return !o.platformSet || (child.Platform != nil && child.Platform.Satisfies(o.platform))
+		}); err != nil {
+			return err
+		}
+		if len(imgs) == 0 {
+			return fmt.Errorf("no images in index %s match platform %s", ref, o.platform)
+		}
+	} else {
+		img, err := desc.Image()
+		if err != nil {
+			return err
+		}
+		imgs = []v1.Image{img}
+	}
+
+	seen := map[v1.Hash]bool{}
+	var layers []v1.Layer
+	for _, img := range imgs {
+		ls, err := img.Layers()
+		if err != nil {
+			return err
+		}
+		for _, l := range ls {
+			digest, err := l.Digest()
+			if err != nil {
+				return err
+			}
+			if seen[digest] {
+				continue
+			}
+			seen[digest] = true
+			if !o.allowNondistributableArtifacts {
+				mt, err := l.MediaType()
+				if err != nil {
+					return err
+				}
+				if !mt.IsDistributable() {
+					continue
+				}
+			}
+			if _, err := c.Get(digest); err == nil {
+				continue
+			} else if !errors.Is(err, cache.ErrNotFound) {
+				return err
+			}
+			layers = append(layers, l)
+		}
+	}
+	logs.Progress.Printf("Prefetching %d layers of %s", len(layers), ref)
+
+	g, ctx := errgroup.WithContext(o.context)
+	g.SetLimit(o.jobs)
+	for _, l := range layers {
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return prefetchLayer(l, c)
+		})
+	}
+	return g.Wait()
+}
+
+// prefetchLayer reads l into c.
+func prefetchLayer(l v1.Layer, c cache.Cache) error {
+	cl, err := c.Put(l)
+	if err != nil {
+		return err
+	}
+	rc, err := cl.Compressed()
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		rc.Close()
+		return err
+	}
+	return rc.Close()
+}
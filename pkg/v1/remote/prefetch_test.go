@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestPrefetch(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	// The number of GETs of layers, rather than configs.
+	var layerGets atomic.Int32
+	layerDigests := map[string]bool{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && layerDigests[path.Base(r.URL.Path)] {
+			layerGets.Add(1)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := name.NewTag(u.Host + "/foo/bar:multi")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	layers := map[string][]v1.Hash{}
+	var adds []mutate.IndexAddendum
+	for _, plat := range []string{"linux/amd64", "linux/arm64"} {
+		p, err := v1.ParsePlatform(plat)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := random.Image(1024, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := img.Manifest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, l := range m.Layers {
+			layers[plat] = append(layers[plat], l.Digest)
+			layerDigests[l.Digest.String()] = true
+		}
+		adds = append(adds, mutate.IndexAddendum{Add: img, Descriptor: v1.Descriptor{Platform: p}})
+	}
+	if err := WriteIndex(tag, mutate.AppendManifests(empty.Index, adds...)); err != nil {
+		t.Fatal(err)
+	}
+	cached := func(c cache.Cache, digests []v1.Hash) bool {
+		for _, h := range digests {
+			if _, err := c.Get(h); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Only the requested platform is prefetched.
+	c := cache.NewFilesystemCache(t.TempDir())
+	if err := Prefetch(tag, c, WithPlatform(v1.Platform{OS: "linux", Architecture: "arm64"}), WithJobs(2)); err != nil {
+		t.Fatalf("Prefetch(arm64) = %v", err)
+	}
+	if !cached(c, layers["linux/arm64"]) {
+		t.Error("the linux/arm64 layers weren't prefetched")
+	}
+	if cached(c, layers["linux/amd64"][:1]) {
+		t.Error("a linux/amd64 layer was prefetched")
+	}
+
+	// Without a platform, everything is, but layers already in the cache
+	// aren't fetched again.
+	layerGets.Store(0)
+	if err := Prefetch(tag, c); err != nil {
+		t.Fatalf("Prefetch() = %v", err)
+	}
+	if !cached(c, layers["linux/amd64"]) {
+		t.Error("the linux/amd64 layers weren't prefetched")
+	}
+	if got, want := layerGets.Load(), int32(len(layers["linux/amd64"])); got != want {
+		t.Errorf("Prefetch() fetched %d layers, want %d", got, want)
+	}
+
+	if err := Prefetch(tag, c, WithPlatform(v1.Platform{OS: "plan9", Architecture: "amd64"})); err == nil {
+		t.Error("Prefetch() of a missing platform succeeded")
+	}
+}
@@ -16,6 +16,7 @@ package remote
 
 import (
 	"context"
+	"io"
 	"sync"
 
 	"github.com/google/go-containerregistry/pkg/name"
@@ -99,7 +100,14 @@ func (p *Puller) get(ctx context.Context, ref name.Reference, acceptable []types
 	if err != nil {
 		return nil, err
 	}
-	return f.get(ctx, ref, acceptable, platform)
+	desc, err := f.get(ctx, ref, acceptable, platform)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.verifyPull(ctx, ref, desc.Descriptor); err != nil {
+		return nil, err
+	}
+	return desc, nil
 }
 
 // Layer is like remote.Layer, but avoids re-authenticating when possible.
@@ -127,9 +135,27 @@ func (p *Puller) Layer(ctx context.Context, ref name.Digest) (v1.Layer, error) {
 	}, nil
 }
 
+// Download is like remote.Download, but avoids re-authenticating when possible.
+func (p *Puller) Download(ctx context.Context, ref name.Digest, w io.WriterAt) error {
+	f, err := p.fetcher(ctx, ref.Context())
+	if err != nil {
+		return err
+	}
+
+	h, err := v1.NewHash(ref.Identifier())
+	if err != nil {
+		return err
+	}
+	return f.download(ctx, h, w, p.o.downloadChunks)
+}
+
 // List lists tags in a repo and handles pagination, returning the full list of tags.
 func (p *Puller) List(ctx context.Context, repo name.Repository) ([]string, error) {
-	lister, err := p.Lister(ctx, repo)
+	return p.list(ctx, repo, p.o.pageSize, p.o.last)
+}
+
+func (p *Puller) list(ctx context.Context, repo name.Repository, pageSize int, last string) ([]string, error) {
+	lister, err := p.lister(ctx, repo, pageSize, last)
 	if err != nil {
 		return nil, err
 	}
@@ -147,16 +173,19 @@ func (p *Puller) List(ctx context.Context, repo name.Repository) ([]string, erro
 }
 
 // Lister lists tags in a repo and returns a Lister for paginating through the results.
+//
+// With WithLast, the listing starts after the given tag, so a listing that
+// was interrupted can be resumed from the last tag it returned.
 func (p *Puller) Lister(ctx context.Context, repo name.Repository) (*Lister, error) {
-	return p.lister(ctx, repo, p.o.pageSize)
+	return p.lister(ctx, repo, p.o.pageSize, p.o.last)
 }
 
-func (p *Puller) lister(ctx context.Context, repo name.Repository, pageSize int) (*Lister, error) {
+func (p *Puller) lister(ctx context.Context, repo name.Repository, pageSize int, last string) (*Lister, error) {
 	f, err := p.fetcher(ctx, repo)
 	if err != nil {
 		return nil, err
 	}
-	page, err := f.listPage(ctx, repo, "", pageSize)
+	page, err := f.listPage(ctx, repo, "", pageSize, last)
 	if err != nil {
 		return nil, err
 	}
@@ -96,6 +96,39 @@ type Pusher struct {
 
 	// map[name.Repository]*repoWriter
 	writers sync.Map
+
+	blobs *blobRepos
+}
+
+// blobRepos remembers a repository that is known to contain each blob, so
+// that later uploads to other repositories on the same registry can try to
+// mount it instead of uploading it again.
+type blobRepos struct {
+	// map[blobKey]name.Repository
+	m sync.Map
+}
+
+type blobKey struct {
+	registry string
+	digest   v1.Hash
+}
+
+func (b *blobRepos) add(repo name.Repository, h v1.Hash) {
+	b.m.Store(blobKey{repo.RegistryStr(), h}, repo)
+}
+
+// find returns a repository other than repo, on the same registry, that
+// contains the blob h.
+func (b *blobRepos) find(repo name.Repository, h v1.Hash) (name.Repository, bool) {
+	v, ok := b.m.Load(blobKey{repo.RegistryStr(), h})
+	if !ok {
+		return name.Repository{}, false
+	}
+	from := v.(name.Repository)
+	if from.String() == repo.String() {
+		return name.Repository{}, false
+	}
+	return from, true
 }
 
 func NewPusher(options ...Option) (*Pusher, error) {
@@ -112,20 +145,25 @@ func newPusher(o *options) *Pusher {
 		return o.pusher
 	}
 	return &Pusher{
-		o: o,
+		o:     o,
+		blobs: &blobRepos{},
 	}
 }
 
 func (p *Pusher) writer(ctx context.Context, repo name.Repository, o *options) (*repoWriter, error) {
 	v, _ := p.writers.LoadOrStore(repo, &repoWriter{
-		repo: repo,
-		o:    o,
+		repo:  repo,
+		o:     o,
+		blobs: p.blobs,
 	})
 	rw := v.(*repoWriter)
 	return rw, rw.init(ctx)
 }
 
 func (p *Pusher) Put(ctx context.Context, ref name.Reference, t Taggable) error {
+	if err := p.verifyDescriptor(ref, t); err != nil {
+		return err
+	}
 	w, err := p.writer(ctx, ref.Context(), p.o)
 	if err != nil {
 		return err
@@ -140,6 +178,9 @@ func (p *Pusher) Put(ctx context.Context, ref name.Reference, t Taggable) error
 }
 
 func (p *Pusher) Push(ctx context.Context, ref name.Reference, t Taggable) error {
+	if err := p.verifyDescriptor(ref, t); err != nil {
+		return err
+	}
 	w, err := p.writer(ctx, ref.Context(), p.o)
 	if err != nil {
 		return err
@@ -147,6 +188,17 @@ func (p *Pusher) Push(ctx context.Context, ref name.Reference, t Taggable) error
 	return w.writeManifest(ctx, ref, t)
 }
 
+// verifyDescriptor checks that a *Descriptor's manifest matches its digest
+// before it is converted to an image or index (which would only know the
+// digest of the manifest itself).
+func (p *Pusher) verifyDescriptor(ref name.Reference, t Taggable) error {
+	d, ok := t.(*Descriptor)
+	if !ok || !p.o.verifyDigests {
+		return nil
+	}
+	return verifyManifestDigest(d, ref, d.Manifest, &d.Descriptor)
+}
+
 func (p *Pusher) Upload(ctx context.Context, repo name.Repository, l v1.Layer) error {
 	w, err := p.writer(ctx, repo, p.o)
 	if err != nil {
@@ -186,9 +238,10 @@ func (p *Pusher) Delete(ctx context.Context, ref name.Reference) error {
 }
 
 type repoWriter struct {
-	repo name.Repository
-	o    *options
-	once sync.Once
+	repo  name.Repository
+	o     *options
+	once  sync.Once
+	blobs *blobRepos
 
 	w   *writer
 	err error
@@ -214,6 +267,10 @@ func (rw *repoWriter) writeDeps(ctx context.Context, m manifest) error {
 		return rw.writeChildren(ctx, idx)
 	}
 
+	if a, ok := m.(*OCIArtifact); ok {
+		return rw.writeArtifactBlobs(ctx, a)
+	}
+
 	// This has no deps, not an error (e.g. something you want to just PUT).
 	return nil
 }
@@ -421,19 +478,16 @@ func (rw *repoWriter) manifestExists(ctx context.Context, ref name.Reference, t
 	}
 	got, err := f.headManifest(ctx, ref, allManifestMediaTypes)
 	if err != nil {
-		var terr *transport.Error
-		if errors.As(err, &terr) {
-			if terr.StatusCode == http.StatusNotFound {
-				return false, nil
-			}
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
 
-			// We treat a 403 here as non-fatal because this existence check is an optimization and
-			// some registries will return a 403 instead of a 404 in certain situations.
-			// E.g. https://jfrog.atlassian.net/browse/RTFACT-13797
-			if terr.StatusCode == http.StatusForbidden {
-				logs.Debug.Printf("manifestExists unexpected 403: %v", err)
-				return false, nil
-			}
+		// We treat a 403 here as non-fatal because this existence check is an optimization and
+		// some registries will return a 403 instead of a 404 in certain situations.
+		// E.g. https://jfrog.atlassian.net/browse/RTFACT-13797
+		if errors.Is(err, ErrDenied) {
+			logs.DebugFor(logs.Write).Printf("manifestExists unexpected 403: %v", err)
+			return false, nil
 		}
 
 		return false, err
@@ -542,10 +596,37 @@ func (rw *repoWriter) writeLayer(ctx context.Context, l v1.Layer) error {
 			}
 			rw.o.progress.total(size)
 		}
-		return rw.w.uploadOne(ctx, l)
+		if err := rw.w.uploadOne(ctx, rw.mountable(l, digest)); err != nil {
+			return err
+		}
+		rw.blobs.add(rw.repo, digest)
+		return nil
 	})
 }
 
+// mountable returns l as a MountableLayer if some other repository we've
+// written to already has it, so that it can be mounted rather than uploaded.
+//
+// Layers that are already mountable from the registry we're writing to are
+// left alone, but those pulled from other registries are mounted from a
+// repository we've written them to on this registry instead, if there is
+// one, since registries generally can't mount across registries.
+func (rw *repoWriter) mountable(l v1.Layer, digest v1.Hash) v1.Layer {
+	ml, ok := asMountable(l)
+	if ok && ml.Reference.Context().RegistryStr() == rw.repo.RegistryStr() {
+		return l
+	}
+	from, found := rw.blobs.find(rw.repo, digest)
+	if !found {
+		// Leave it to the registry to mount across registries, if it can.
+		return l
+	}
+	if l == ml {
+		l = ml.Layer
+	}
+	return &MountableLayer{Layer: l, Reference: from.Digest(digest.String())}
+}
+
 func (rw *repoWriter) lazyWriteLayer(ctx context.Context, l v1.Layer) error {
 	return rw.work.Stream(l, func() error {
 		if err := rw.w.uploadOne(ctx, l); err != nil {
@@ -559,6 +640,7 @@ func (rw *repoWriter) lazyWriteLayer(ctx context.Context, l v1.Layer) error {
 		}
 
 		rw.work.Do(digest, nop)
+		rw.blobs.add(rw.repo, digest)
 
 		if rw.o.progress != nil {
 			size, err := l.Size()
@@ -74,8 +74,7 @@ func (f *fetcher) fetchReferrers(ctx context.Context, filter map[string]string,
 	} else {
 		// The registry doesn't support the Referrers API endpoint, so we'll use the fallback tag scheme.
 		b, _, err = f.fetchManifest(ctx, fallbackTag(d), []types.MediaType{types.OCIImageIndex})
-		var terr *transport.Error
-		if errors.As(err, &terr) && terr.StatusCode == http.StatusNotFound {
+		if errors.Is(err, ErrNotFound) {
 			// Not found just means there are no attachments yet. Start with an empty manifest.
 			return empty.Index, nil
 		} else if err != nil {
@@ -89,6 +88,7 @@ func (f *fetcher) fetchReferrers(ctx context.Context, filter map[string]string,
 	}
 	idx := &remoteIndex{
 		fetcher:   *f,
+		ref:       d,
 		ctx:       ctx,
 		manifest:  b,
 		mediaType: types.OCIImageIndex,
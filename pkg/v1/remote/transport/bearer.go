@@ -25,6 +25,7 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 
 	authchallenge "github.com/docker/distribution/registry/client/auth/challenge"
 
@@ -116,6 +117,8 @@ type bearerTransport struct {
 	scopes  []string
 	// Scheme we should use, determined by ping response.
 	scheme string
+	// Called after each token exchange, if set (see WithTokenExchanged).
+	exchanged func(error)
 }
 
 var _ http.RoundTripper = (*bearerTransport)(nil)
@@ -187,7 +190,15 @@ func (bt *bearerTransport) RoundTrip(in *http.Request) (*http.Response, error) {
 		if err = bt.refresh(in.Context()); err != nil {
 			return nil, err
 		}
-		return sendRequest()
+		res, err := sendRequest()
+		if err == nil && insufficientScope(res) && bt.exchanged != nil {
+			bt.exchanged(&TokenError{
+				Realm:  bt.realm,
+				Reason: ErrInsufficientScope,
+				Err:    fmt.Errorf("%s rejected the token with an insufficient_scope challenge", bt.registry.RegistryStr()),
+			})
+		}
+		return res, err
 	}
 
 	return res, err
@@ -238,6 +249,28 @@ func (bt *bearerTransport) refresh(ctx context.Context) error {
 }
 
 func (bt *bearerTransport) Refresh(ctx context.Context, auth *authn.AuthConfig) (*Token, error) {
+	tok, err := bt.exchange(ctx, auth)
+	reported := err
+	if err == nil {
+		// The registry checks the token against the token server's clock,
+		// not ours, so a skewed token may still work; only warn about it.
+		if skew := tok.CheckTime(time.Now()); skew != nil {
+			var terr *TokenError
+			if errors.As(skew, &terr) {
+				terr.Realm = bt.realm
+			}
+			logs.Warn.Printf("%v", skew)
+			reported = skew
+		}
+	}
+	if bt.exchanged != nil && ctx.Err() == nil {
+		bt.exchanged(reported)
+	}
+	return tok, err
+}
+
+// exchange makes the requests of Refresh.
+func (bt *bearerTransport) exchange(ctx context.Context, auth *authn.AuthConfig) (*Token, error) {
 	var (
 		content []byte
 		err     error
@@ -258,16 +291,16 @@ func (bt *bearerTransport) Refresh(ctx context.Context, auth *authn.AuthConfig)
 		content, err = bt.refreshBasic(ctx)
 	}
 	if err != nil {
-		return nil, err
+		return nil, tokenError(ctx, bt.realm, err)
 	}
 
 	var response Token
 	if err := json.Unmarshal(content, &response); err != nil {
-		return nil, err
+		return nil, tokenError(ctx, bt.realm, err)
 	}
 
 	if response.Token == "" && response.AccessToken == "" {
-		return &response, fmt.Errorf("no token in bearer response:\n%s", content)
+		return &response, tokenError(ctx, bt.realm, fmt.Errorf("no token in bearer response:\n%s", content))
 	}
 
 	return &response, nil
@@ -16,6 +16,7 @@ package transport
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -91,6 +92,111 @@ func (e *Error) Temporary() bool {
 	return true
 }
 
+// Is reports whether e is one of the sentinel errors below, so that callers
+// can branch on common failures with errors.Is rather than string matching.
+//
+// An *Error matches a sentinel if either its status code or the code of any
+// of its diagnostics does, so e.g. a 400 Bad Request whose diagnostics
+// include MANIFEST_UNKNOWN matches ErrNotFound.
+func (e *Error) Is(target error) bool {
+	m, ok := sentinelFor(target)
+	if !ok {
+		return false
+	}
+	if e.StatusCode == m.status {
+		return true
+	}
+	for _, d := range e.Errors {
+		for _, code := range m.codes {
+			if d.Code == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// The sentinel errors that an *Error matches with errors.Is, according to
+// its status code or the codes of its diagnostics. Responses to HEAD
+// requests have no body, so only their status codes are matched.
+var (
+	// ErrNotFound matches 404 Not Found responses, and the codes for unknown
+	// blobs, uploads, manifests and repositories.
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized matches 401 Unauthorized responses and the UNAUTHORIZED
+	// code, e.g. for missing or bad credentials.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrDenied matches 403 Forbidden responses and the DENIED code, e.g. for
+	// credentials without access to a repository.
+	ErrDenied = errors.New("denied")
+	// ErrRateLimited matches 429 Too Many Requests responses and the
+	// TOOMANYREQUESTS code.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnsupported matches 405 Method Not Allowed responses and the
+	// UNSUPPORTED code, e.g. for registries that don't allow deletes.
+	ErrUnsupported = errors.New("unsupported")
+)
+
+type sentinel struct {
+	status int
+	codes  []ErrorCode
+}
+
+// sentinelFor returns what target matches, if it is one of the sentinels.
+// It compares with a switch rather than looking target up in a map, since
+// errors.Is may be called with errors whose types aren't hashable.
+func sentinelFor(target error) (sentinel, bool) {
+	switch target {
+	case ErrNotFound:
+		return sentinel{http.StatusNotFound, []ErrorCode{
+			BlobUnknownErrorCode,
+			BlobUploadUnknownErrorCode,
+			ManifestUnknownErrorCode,
+			NameUnknownErrorCode,
+		}}, true
+	case ErrUnauthorized:
+		return sentinel{http.StatusUnauthorized, []ErrorCode{UnauthorizedErrorCode}}, true
+	case ErrDenied:
+		return sentinel{http.StatusForbidden, []ErrorCode{DeniedErrorCode}}, true
+	case ErrRateLimited:
+		return sentinel{http.StatusTooManyRequests, []ErrorCode{TooManyRequestsErrorCode}}, true
+	case ErrUnsupported:
+		return sentinel{http.StatusMethodNotAllowed, []ErrorCode{UnsupportedErrorCode}}, true
+	}
+	return sentinel{}, false
+}
+
+// HasCode reports whether err is or wraps an *Error with a diagnostic with
+// the given code.
+func HasCode(err error, code ErrorCode) bool {
+	var terr *Error
+	if !errors.As(err, &terr) {
+		return false
+	}
+	for _, d := range terr.Errors {
+		if d.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsManifestUnknown reports whether err says that a manifest doesn't exist
+// in a repository that does.
+func IsManifestUnknown(err error) bool {
+	return HasCode(err, ManifestUnknownErrorCode)
+}
+
+// IsNameUnknown reports whether err says that a repository doesn't exist.
+func IsNameUnknown(err error) bool {
+	return HasCode(err, NameUnknownErrorCode)
+}
+
+// IsBlobUnknown reports whether err says that a blob doesn't exist.
+func IsBlobUnknown(err error) bool {
+	return HasCode(err, BlobUnknownErrorCode)
+}
+
 // Diagnostic represents a single error returned by a Docker registry interaction.
 type Diagnostic struct {
 	Code    ErrorCode `json:"code"`
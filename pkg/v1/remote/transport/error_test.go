@@ -17,6 +17,7 @@ package transport
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
@@ -234,3 +235,81 @@ func (e *errReadCloser) Read(_ []byte) (int, error) {
 func (e *errReadCloser) Close() error {
 	return e.err
 }
+
+func TestErrorIs(t *testing.T) {
+	for _, tc := range []struct {
+		err  *Error
+		want []error
+	}{{
+		err:  &Error{StatusCode: http.StatusNotFound},
+		want: []error{ErrNotFound},
+	}, {
+		err: &Error{
+			StatusCode: http.StatusBadRequest,
+			Errors:     []Diagnostic{{Code: NameUnknownErrorCode}},
+		},
+		want: []error{ErrNotFound},
+	}, {
+		err: &Error{
+			StatusCode: http.StatusUnauthorized,
+			Errors:     []Diagnostic{{Code: DeniedErrorCode}},
+		},
+		want: []error{ErrUnauthorized, ErrDenied},
+	}, {
+		err: &Error{
+			StatusCode: http.StatusTooManyRequests,
+		},
+		want: []error{ErrRateLimited},
+	}, {
+		err: &Error{
+			StatusCode: http.StatusMethodNotAllowed,
+			Errors:     []Diagnostic{{Code: UnsupportedErrorCode}},
+		},
+		want: []error{ErrUnsupported},
+	}, {
+		err:  &Error{StatusCode: http.StatusInternalServerError},
+		want: nil,
+	}} {
+		err := fmt.Errorf("wrapped: %w", tc.err)
+		for _, target := range []error{ErrNotFound, ErrUnauthorized, ErrDenied, ErrRateLimited, ErrUnsupported} {
+			want := false
+			for _, w := range tc.want {
+				want = want || w == target
+			}
+			if got := errors.Is(err, target); got != want {
+				t.Errorf("errors.Is(%v, %v) = %t, want %t", err, target, got, want)
+			}
+		}
+	}
+
+	// Targets whose types aren't hashable don't match, rather than panic.
+	if errors.Is(&Error{StatusCode: http.StatusNotFound}, unhashableError{}) {
+		t.Error("errors.Is(unhashableError) = true, want false")
+	}
+}
+
+type unhashableError struct {
+	causes []error
+}
+
+func (unhashableError) Error() string { return "unhashable" }
+
+func TestHasCode(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &Error{
+		StatusCode: http.StatusNotFound,
+		Errors: []Diagnostic{{
+			Code: ManifestUnknownErrorCode,
+		}, {
+			Code: UnknownErrorCode,
+		}},
+	})
+	if !IsManifestUnknown(err) || !HasCode(err, UnknownErrorCode) {
+		t.Errorf("IsManifestUnknown(%v) = false, want true", err)
+	}
+	if IsNameUnknown(err) || IsBlobUnknown(err) {
+		t.Errorf("IsNameUnknown(%v) or IsBlobUnknown = true, want false", err)
+	}
+	if HasCode(errors.New("MANIFEST_UNKNOWN"), ManifestUnknownErrorCode) {
+		t.Error("HasCode() matched a non-*Error")
+	}
+}
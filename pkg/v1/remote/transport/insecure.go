@@ -0,0 +1,72 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+type insecureTransport struct {
+	registries []string
+	insecure   http.RoundTripper
+	inner      http.RoundTripper
+}
+
+// NewInsecureRegistries returns an http.RoundTripper that doesn't verify the
+// TLS certificates of the given registries, and otherwise uses inner.
+//
+// Registries are hosts, with ports if they aren't the default, as in image
+// references (e.g. "registry.internal:5000"), and may contain path.Match
+// wildcards (e.g. "*.internal"). Only requests to the registries themselves
+// skip verification, not requests they redirect to other hosts.
+func NewInsecureRegistries(inner *http.Transport, registries ...string) (http.RoundTripper, error) {
+	it := &insecureTransport{inner: inner}
+	for _, reg := range registries {
+		if _, err := path.Match(reg, ""); err != nil {
+			return nil, fmt.Errorf("bad registry pattern %q: %w", reg, err)
+		}
+		if r, err := name.NewRegistry(reg); err == nil {
+			// Match requests to Docker Hub, whose host isn't docker.io.
+			reg = r.RegistryStr()
+		}
+		it.registries = append(it.registries, reg)
+	}
+
+	insecure := inner.Clone()
+	if insecure.TLSClientConfig == nil {
+		insecure.TLSClientConfig = &tls.Config{}
+	}
+	insecure.TLSClientConfig.InsecureSkipVerify = true //nolint: gosec
+	it.insecure = insecure
+	return it, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (it *insecureTransport) RoundTrip(in *http.Request) (*http.Response, error) {
+	for _, reg := range it.registries {
+		if ok, _ := path.Match(reg, in.URL.Host); ok {
+			return it.insecure.RoundTrip(in)
+		}
+		if ok, _ := path.Match(reg, in.URL.Hostname()); ok {
+			return it.insecure.RoundTrip(in)
+		}
+	}
+	return it.inner.RoundTrip(in)
+}
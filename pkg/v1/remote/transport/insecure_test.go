@@ -0,0 +1,63 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestInsecureRegistries(t *testing.T) {
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	// Don't log the handshakes that are meant to fail.
+	s.Config.ErrorLog = log.New(io.Discard, "", 0)
+	s.StartTLS()
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		registries []string
+		wantErr    bool
+	}{
+		{registries: []string{u.Host}},
+		{registries: []string{u.Hostname()}},
+		{registries: []string{"127.0.0.*"}},
+		{registries: []string{"example.com", "*.internal"}, wantErr: true},
+		{registries: nil, wantErr: true},
+	} {
+		rt, err := NewInsecureRegistries(http.DefaultTransport.(*http.Transport).Clone(), tc.registries...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := (&http.Client{Transport: rt}).Get(s.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("GET with insecure registries %v: %v, want error %t", tc.registries, err, tc.wantErr)
+		}
+	}
+
+	if _, err := NewInsecureRegistries(http.DefaultTransport.(*http.Transport).Clone(), "["); err == nil {
+		t.Error("NewInsecureRegistries([) succeeded, want error for a bad pattern")
+	}
+}
@@ -15,9 +15,13 @@
 package transport
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httputil"
+	"net/url"
 	"time"
 
 	"github.com/google/go-containerregistry/internal/redact"
@@ -29,48 +33,158 @@ type logTransport struct {
 }
 
 // NewLogger returns a transport that logs requests and responses to
-// github.com/google/go-containerregistry/pkg/logs.Debug.
+// github.com/google/go-containerregistry/pkg/logs.Debug, or to its Transport
+// scope.
+//
+// Credentials are never logged: Authorization, Cookie and Set-Cookie headers
+// are redacted, as are unexpected query parameters (which often carry tokens
+// or signatures) in URLs. If logs.GetFormat() is logs.FormatJSON, each request
+// and response is logged as a single line of JSON.
 func NewLogger(inner http.RoundTripper) http.RoundTripper {
 	return &logTransport{inner}
 }
 
+// sensitiveHeaders are replaced entirely in dumps.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactHeaders returns a copy of h that is safe to log.
+func redactHeaders(h http.Header) http.Header {
+	if h == nil {
+		return nil
+	}
+	h = h.Clone()
+	for _, k := range sensitiveHeaders {
+		if h.Get(k) != "" {
+			h.Set(k, "<redacted>")
+		}
+	}
+	// Redirects to blob storage are often signed URLs.
+	if loc := h.Get("Location"); loc != "" {
+		if u, err := url.Parse(loc); err == nil {
+			h.Set("Location", redact.URL(u))
+		}
+	}
+	return h
+}
+
+// dump is a JSON line logged for FormatJSON.
+type dump struct {
+	Time         time.Time   `json:"time"`
+	Type         string      `json:"type"`
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Status       int         `json:"status,omitempty"`
+	Duration     string      `json:"duration,omitempty"`
+	Error        string      `json:"error,omitempty"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyRedacted string      `json:"bodyRedacted,omitempty"`
+}
+
+// dumpBody returns the body portion of an httputil dump.
+func dumpBody(b []byte) string {
+	if _, body, ok := bytes.Cut(b, []byte("\r\n\r\n")); ok {
+		return string(body)
+	}
+	return ""
+}
+
 func (t *logTransport) RoundTrip(in *http.Request) (out *http.Response, err error) {
+	if sl := logs.GetLogger(); sl != nil && sl.Enabled(in.Context(), slog.LevelDebug) {
+		return t.roundTripStructured(in)
+	}
+
 	// Inspired by: github.com/motemen/go-loghttp
+	l := logs.DebugFor(logs.Transport)
+	asJSON := logs.GetFormat() == logs.FormatJSON
+	logJSON := func(d dump) {
+		b, err := json.Marshal(d)
+		if err != nil {
+			l.Printf("Failed to marshal dump: %v", err)
+			return
+		}
+		// Bypass the logger's prefix so that every line is valid JSON.
+		l.Writer().Write(append(b, '\n'))
+	}
 
 	// We redact token responses and binary blobs in response/request.
 	omitBody, reason := redact.FromContext(in.Context())
-	if omitBody {
-		logs.Debug.Printf("--> %s %s [body redacted: %s]", in.Method, in.URL, reason)
-	} else {
-		logs.Debug.Printf("--> %s %s", in.Method, in.URL)
+	u := redact.URL(in.URL)
+	if !asJSON {
+		if omitBody {
+			l.Printf("--> %s %s [body redacted: %s]", in.Method, u, reason)
+		} else {
+			l.Printf("--> %s %s", in.Method, u)
+		}
 	}
 
-	// Save these headers so we can redact Authorization.
-	savedHeaders := in.Header.Clone()
-	if in.Header != nil && in.Header.Get("authorization") != "" {
-		in.Header.Set("authorization", "<redacted>")
+	// Dump a copy of the request with credentials removed.
+	logged := in.Clone(in.Context())
+	logged.Header = redactHeaders(in.Header)
+	if ru, err := url.Parse(u); err == nil {
+		logged.URL = ru
 	}
-
-	b, err := httputil.DumpRequestOut(in, !omitBody)
-	if err == nil {
-		logs.Debug.Println(string(b))
-	} else {
-		logs.Debug.Printf("Failed to dump request %s %s: %v", in.Method, in.URL, err)
+	b, derr := httputil.DumpRequestOut(logged, !omitBody)
+	if !omitBody {
+		// Dumping consumed the body and replaced it with a copy.
+		in.Body = logged.Body
+	}
+	switch {
+	case derr != nil:
+		l.Printf("Failed to dump request %s %s: %v", in.Method, u, derr)
+	case asJSON:
+		d := dump{Time: time.Now(), Type: "request", Method: in.Method, URL: u, Headers: logged.Header}
+		if omitBody {
+			d.BodyRedacted = reason
+		} else {
+			d.Body = dumpBody(b)
+		}
+		logJSON(d)
+	default:
+		l.Println(string(b))
 	}
-
-	// Restore the non-redacted headers.
-	in.Header = savedHeaders
 
 	start := time.Now()
 	out, err = t.inner.RoundTrip(in)
 	duration := time.Since(start)
 	if err != nil {
-		logs.Debug.Printf("<-- %v %s %s (%s)", err, in.Method, in.URL, duration)
+		logErr := redact.Error(err)
+		if asJSON {
+			logJSON(dump{Time: time.Now(), Type: "response", Method: in.Method, URL: u, Duration: duration.String(), Error: logErr.Error()})
+		} else {
+			l.Printf("<-- %v %s %s (%s)", logErr, in.Method, u, duration)
+		}
 	}
 	if out != nil {
+		ou := u
+		if out.Request != nil {
+			ou = redact.URL(out.Request.URL)
+		}
+
+		// Dump the response with credentials removed, then restore them.
+		headers := out.Header
+		out.Header = redactHeaders(headers)
+		b, derr := httputil.DumpResponse(out, !omitBody)
+		redacted := out.Header
+		out.Header = headers
+
+		if asJSON {
+			d := dump{Time: time.Now(), Type: "response", Method: in.Method, URL: ou, Status: out.StatusCode, Duration: duration.String(), Headers: redacted}
+			switch {
+			case derr != nil:
+				d.Error = fmt.Sprintf("failed to dump response: %v", derr)
+			case omitBody:
+				d.BodyRedacted = reason
+			default:
+				d.Body = dumpBody(b)
+			}
+			logJSON(d)
+			return
+		}
+
 		msg := fmt.Sprintf("<-- %d", out.StatusCode)
 		if out.Request != nil {
-			msg = fmt.Sprintf("%s %s", msg, out.Request.URL)
+			msg = fmt.Sprintf("%s %s", msg, ou)
 		}
 		msg = fmt.Sprintf("%s (%s)", msg, duration)
 
@@ -78,14 +192,35 @@ func (t *logTransport) RoundTrip(in *http.Request) (out *http.Response, err erro
 			msg = fmt.Sprintf("%s [body redacted: %s]", msg, reason)
 		}
 
-		logs.Debug.Print(msg)
+		l.Print(msg)
 
-		b, err := httputil.DumpResponse(out, !omitBody)
-		if err == nil {
-			logs.Debug.Println(string(b))
+		if derr == nil {
+			l.Println(string(b))
 		} else {
-			logs.Debug.Printf("Failed to dump response %s %s: %v", in.Method, in.URL, err)
+			l.Printf("Failed to dump response %s %s: %v", in.Method, u, derr)
 		}
 	}
 	return
 }
+
+// roundTripStructured logs a record for the request and one for its response
+// with logs.Log, rather than dumping them, for a Logger given to
+// logs.SetLogger. Records carry the fields of the request's context, such as
+// the attempt number that the retrying transport adds.
+func (t *logTransport) roundTripStructured(in *http.Request) (*http.Response, error) {
+	ctx := logs.WithFields(in.Context(), "method", in.Method, "url", redact.URL(in.URL))
+	if omitBody, reason := redact.FromContext(in.Context()); omitBody {
+		ctx = logs.WithFields(ctx, "bodyRedacted", reason)
+	}
+	logs.Log(ctx, slog.LevelDebug, "HTTP request", "headers", redactHeaders(in.Header))
+
+	start := time.Now()
+	out, err := t.inner.RoundTrip(in)
+	duration := time.Since(start)
+	if err != nil {
+		logs.Log(ctx, slog.LevelDebug, "HTTP request failed", "duration", duration, "error", redact.Error(err))
+		return out, err
+	}
+	logs.Log(ctx, slog.LevelDebug, "HTTP response", "status", out.StatusCode, "duration", duration, "headers", redactHeaders(out.Header))
+	return out, nil
+}
@@ -17,12 +17,16 @@ package transport
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"log/slog"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-containerregistry/internal/redact"
 	"github.com/google/go-containerregistry/pkg/logs"
 )
@@ -91,3 +95,121 @@ func TestLoggerError(t *testing.T) {
 		t.Errorf("Expected logs to contain %s, got %s", canary, logged)
 	}
 }
+
+func TestLoggerRedactsCredentials(t *testing.T) {
+	token := "tokendonotlog"
+	cookie := "cookiedonotlog"
+
+	for _, format := range []logs.Format{logs.FormatText, logs.FormatJSON} {
+		req, err := http.NewRequest("GET", "http://example.com/v2/foo/blobs/sha256:abc?digest=sha256:abc&X-Amz-Signature="+token, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error during NewRequest: %v", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Cookie", cookie)
+
+		var b bytes.Buffer
+		logs.Debug.SetOutput(&b)
+		logs.SetFormat(format)
+		cannedResponse := http.Response{
+			Status:     http.StatusText(http.StatusTemporaryRedirect),
+			StatusCode: http.StatusTemporaryRedirect,
+			Header: http.Header{
+				"Set-Cookie": []string{cookie},
+				"Location":   []string{"https://storage.example.com/blob?sig=" + token},
+			},
+			Body:    io.NopCloser(strings.NewReader("")),
+			Request: req,
+		}
+		tr := NewLogger(newRecorder(&cannedResponse, nil))
+		if _, err := tr.RoundTrip(req); err != nil {
+			t.Fatalf("Unexpected error during RoundTrip: %v", err)
+		}
+		logs.SetFormat(logs.FormatText)
+
+		logged := b.String()
+		for _, secret := range []string{token, cookie} {
+			if strings.Contains(logged, secret) {
+				t.Errorf("format %d: expected logs NOT to contain %s, got %s", format, secret, logged)
+			}
+		}
+		if !strings.Contains(logged, "digest=sha256") {
+			t.Errorf("format %d: expected logs to contain allowed query parameters, got %s", format, logged)
+		}
+		if req.Header.Get("Authorization") != "Bearer "+token {
+			t.Errorf("format %d: request headers were modified: %v", format, req.Header)
+		}
+
+		if format != logs.FormatJSON {
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(logged), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 JSON lines, got %d: %s", len(lines), logged)
+		}
+		for _, line := range lines {
+			var d dump
+			if err := json.Unmarshal([]byte(line), &d); err != nil {
+				t.Errorf("line is not JSON: %v: %s", err, line)
+			}
+		}
+	}
+	logs.Debug.SetOutput(io.Discard)
+}
+
+func TestLoggerStructured(t *testing.T) {
+	var buf bytes.Buffer
+	logs.SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer logs.SetLogger(nil)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tr := NewRetry(NewLogger(http.DefaultTransport), WithRetryBackoff(Backoff{Steps: 2}), WithRetryStatusCodes(http.StatusServiceUnavailable))
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/v2/?token=secret", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := tr.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("logged credentials: %s", buf.String())
+	}
+	type record struct {
+		Msg     string
+		Method  string
+		Attempt int
+		Status  int
+	}
+	var got []record
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("Unmarshal(%q): %v", line, err)
+		}
+		got = append(got, r)
+	}
+	want := []record{
+		{Msg: "HTTP request", Method: "GET", Attempt: 1},
+		{Msg: "HTTP response", Method: "GET", Attempt: 1, Status: http.StatusServiceUnavailable},
+		{Msg: "Retrying HTTP request", Method: "GET", Attempt: 2},
+		{Msg: "HTTP request", Method: "GET", Attempt: 2},
+		{Msg: "HTTP response", Method: "GET", Attempt: 2, Status: http.StatusOK},
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("records (-want +got): %s", d)
+	}
+}
@@ -123,7 +123,7 @@ func pingParallel(ctx context.Context, reg name.Registry, t http.RoundTripper, s
 		case results <- pingResult{Challenge: pr, error: err, primary: scheme == "https", done: true}:
 		case <-returned:
 			if pr != nil {
-				logs.Debug.Printf("%s lost race", scheme)
+				logs.DebugFor(logs.Transport).Printf("%s lost race", scheme)
 			}
 		}
 	}
@@ -15,10 +15,13 @@
 package transport
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/google/go-containerregistry/internal/redact"
 	"github.com/google/go-containerregistry/internal/retry"
+	"github.com/google/go-containerregistry/pkg/logs"
 )
 
 // Sleep for 0.1 then 0.3 seconds. This should cover networking blips.
@@ -39,13 +42,16 @@ type retryTransport struct {
 	codes     []int
 }
 
-// Option is a functional option for retryTransport.
+// Option is a functional option for NewRetry and NewWithContext.
 type Option func(*options)
 
 type options struct {
 	backoff   retry.Backoff
 	predicate retry.Predicate
 	codes     []int
+
+	// Only used by NewWithContext.
+	tokenExchanged func(error)
 }
 
 // Backoff is an alias of retry.Backoff to expose this configuration option to consumers of this lib
@@ -92,8 +98,20 @@ func NewRetry(inner http.RoundTripper, opts ...Option) http.RoundTripper {
 }
 
 func (t *retryTransport) RoundTrip(in *http.Request) (out *http.Response, err error) {
+	structured := logs.GetLogger() != nil
+	attempt := 0
 	roundtrip := func() error {
-		out, err = t.inner.RoundTrip(in)
+		attempt++
+		req := in
+		if structured {
+			// Tag what's logged for this attempt with its number.
+			ctx := logs.WithFields(in.Context(), "attempt", attempt)
+			if attempt > 1 {
+				logs.Log(ctx, slog.LevelDebug, "Retrying HTTP request", "method", in.Method, "url", redact.URL(in.URL))
+			}
+			req = in.WithContext(ctx)
+		}
+		out, err = t.inner.RoundTrip(req)
 		if !retry.Ever(in.Context()) {
 			return nil
 		}
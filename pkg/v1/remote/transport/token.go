@@ -0,0 +1,179 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	authchallenge "github.com/docker/distribution/registry/client/auth/challenge"
+)
+
+// The reasons a token exchange fails, that a *TokenError matches with
+// errors.Is.
+var (
+	// ErrBadCredentials means the token server rejected the credentials
+	// with 401 Unauthorized.
+	ErrBadCredentials = errors.New("bad credentials")
+	// ErrInsufficientScope means the credentials are valid, but don't grant
+	// the requested access: the token server responded 403 Forbidden, or the
+	// registry rejected the token it issued with an insufficient_scope
+	// challenge.
+	ErrInsufficientScope = errors.New("insufficient scope")
+	// ErrTokenServerUnreachable means the token server couldn't be
+	// connected to, e.g. because its name didn't resolve or it refused the
+	// connection or timed out.
+	ErrTokenServerUnreachable = errors.New("token server unreachable")
+	// ErrClockSkew means the token server issued a token that, by the local
+	// clock, is not yet valid or has already expired.
+	ErrClockSkew = errors.New("clock skew")
+)
+
+// TokenError is a failed exchange of credentials for a bearer token.
+type TokenError struct {
+	// Realm is the URL of the token server.
+	Realm string
+	// Reason is one of the errors above, or nil if the failure wasn't
+	// classified.
+	Reason error
+	// Err is the underlying error.
+	Err error
+}
+
+var _ error = (*TokenError)(nil)
+
+// Error implements error.
+func (e *TokenError) Error() string {
+	msg := "token exchange"
+	if e.Realm != "" {
+		msg += " with " + e.Realm
+	}
+	if e.Reason != nil {
+		msg += ": " + e.Reason.Error()
+	}
+	return fmt.Sprintf("%s: %v", msg, e.Err)
+}
+
+// Unwrap returns the underlying error, e.g. the *Error from the token server.
+func (e *TokenError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is e's Reason.
+func (e *TokenError) Is(target error) bool {
+	return e.Reason != nil && target == e.Reason
+}
+
+// WithTokenExchanged has the transports NewWithContext returns call f after
+// each exchange of credentials for a bearer token, with nil if it succeeded,
+// or the *TokenError it failed with. A token that was issued but fails
+// CheckTime is reported with that error, though it is still used, and one
+// that the registry rejects with an insufficient_scope challenge is reported
+// again, with a *TokenError matching ErrInsufficientScope. Exchanges canceled
+// by their context aren't reported.
+func WithTokenExchanged(f func(error)) Option {
+	return func(o *options) {
+		o.tokenExchanged = f
+	}
+}
+
+// tokenError classifies err, the failure of a token exchange with realm.
+func tokenError(ctx context.Context, realm string, err error) error {
+	if ctx.Err() != nil {
+		// Canceled, not failed.
+		return err
+	}
+	var (
+		reason error
+		opErr  *net.OpError
+		dnsErr *net.DNSError
+	)
+	switch {
+	case errors.Is(err, ErrUnauthorized):
+		reason = ErrBadCredentials
+	case errors.Is(err, ErrDenied):
+		reason = ErrInsufficientScope
+	case errors.As(err, &opErr), errors.As(err, &dnsErr), errors.Is(err, context.DeadlineExceeded):
+		reason = ErrTokenServerUnreachable
+	}
+	return &TokenError{Realm: realm, Reason: reason, Err: err}
+}
+
+// clockSkewLeeway is how far the local clock may be from the token server's
+// before a token is reported as skewed.
+const clockSkewLeeway = time.Minute
+
+// CheckTime returns a *TokenError matching ErrClockSkew if t is a JWT that
+// is not valid at now: its nbf or iat claims are in the future, or its exp
+// claim is in the past, by more than a minute. As the token server just
+// issued t, this means the local clock disagrees with its clock.
+//
+// Tokens that aren't JWTs, or that don't have those claims, are not checked.
+func (t *Token) CheckTime(now time.Time) error {
+	tok := t.Token
+	if tok == "" {
+		tok = t.AccessToken
+	}
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+	var claims struct {
+		NotBefore *float64 `json:"nbf"`
+		IssuedAt  *float64 `json:"iat"`
+		Expires   *float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return nil
+	}
+	unix := func(f float64) time.Time {
+		return time.Unix(int64(f), 0)
+	}
+	now = now.UTC().Truncate(time.Second)
+	var skew error
+	switch {
+	case claims.NotBefore != nil && unix(*claims.NotBefore).After(now.Add(clockSkewLeeway)):
+		skew = fmt.Errorf("token is not valid before %s, but the local time is %s", unix(*claims.NotBefore).UTC(), now)
+	case claims.IssuedAt != nil && unix(*claims.IssuedAt).After(now.Add(clockSkewLeeway)):
+		skew = fmt.Errorf("token was issued at %s, but the local time is %s", unix(*claims.IssuedAt).UTC(), now)
+	case claims.Expires != nil && unix(*claims.Expires).Before(now.Add(-clockSkewLeeway)):
+		skew = fmt.Errorf("token expired at %s, but the local time is %s", unix(*claims.Expires).UTC(), now)
+	default:
+		return nil
+	}
+	return &TokenError{Reason: ErrClockSkew, Err: skew}
+}
+
+// insufficientScope reports whether res rejects the token it was sent with an
+// insufficient_scope challenge.
+func insufficientScope(res *http.Response) bool {
+	for _, c := range authchallenge.ResponseChallenges(res) {
+		if c.Parameters["error"] == "insufficient_scope" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+func TestTokenErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		handler http.HandlerFunc
+		closed  bool
+		want    error
+	}{{
+		name: "bad credentials",
+		handler: func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		},
+		want: ErrBadCredentials,
+	}, {
+		name: "insufficient scope",
+		handler: func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		},
+		want: ErrInsufficientScope,
+	}, {
+		name:   "unreachable",
+		closed: true,
+		want:   ErrTokenServerUnreachable,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			if tc.closed {
+				server.Close()
+			} else {
+				defer server.Close()
+			}
+			registry, err := name.NewRegistry("registry.example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			bt := &bearerTransport{
+				inner:    http.DefaultTransport,
+				basic:    &authn.Basic{Username: "foo", Password: "bar"},
+				registry: registry,
+				realm:    server.URL,
+				scheme:   "http",
+			}
+
+			var reported []error
+			bt.exchanged = func(err error) { reported = append(reported, err) }
+			_, err = bt.Refresh(context.Background(), &authn.AuthConfig{Username: "foo", Password: "bar"})
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("Refresh() = %v, want %v", err, tc.want)
+			}
+			var terr *TokenError
+			if !errors.As(err, &terr) || terr.Realm != server.URL {
+				t.Errorf("Refresh() = %#v, want a *TokenError for %s", err, server.URL)
+			}
+			if len(reported) != 1 || reported[0] != err {
+				t.Errorf("reported %v, want just %v", reported, err)
+			}
+
+			// Canceled exchanges aren't failures.
+			reported = nil
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if _, err := bt.Refresh(ctx, &authn.AuthConfig{Username: "foo", Password: "bar"}); err == nil {
+				t.Error("Refresh() with a canceled context succeeded")
+			}
+			if len(reported) != 0 {
+				t.Errorf("reported %v for a canceled exchange, want nothing", reported)
+			}
+		})
+	}
+}
+
+func TestTokenErrorsUnwrap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	registry, err := name.NewRegistry("registry.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bt := &bearerTransport{
+		inner:    http.DefaultTransport,
+		basic:    authn.Anonymous,
+		registry: registry,
+		realm:    server.URL,
+		scheme:   "http",
+	}
+	_, err = bt.Refresh(context.Background(), &authn.AuthConfig{})
+	var terr *Error
+	if !errors.As(err, &terr) || terr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Refresh() = %v, want it to wrap a 401 *Error", err)
+	}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Refresh() = %v, want it to match ErrUnauthorized", err)
+	}
+}
+
+func jwt(claims string) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	return enc([]byte(`{"alg":"none"}`)) + "." + enc([]byte(claims)) + ".sig"
+}
+
+func TestCheckTime(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	for _, tc := range []struct {
+		name  string
+		token string
+		skew  bool
+	}{
+		{"valid", jwt(fmt.Sprintf(`{"nbf":%d,"iat":%d,"exp":%d}`, now.Unix(), now.Unix(), now.Unix()+300)), false},
+		{"within leeway", jwt(fmt.Sprintf(`{"nbf":%d}`, now.Unix()+30)), false},
+		{"not yet valid", jwt(fmt.Sprintf(`{"nbf":%d}`, now.Unix()+3600)), true},
+		{"issued in the future", jwt(fmt.Sprintf(`{"iat":%d}`, now.Unix()+3600)), true},
+		{"expired", jwt(fmt.Sprintf(`{"exp":%d}`, now.Unix()-3600)), true},
+		{"no claims", jwt(`{}`), false},
+		{"not a jwt", "opaque-token", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&Token{Token: tc.token}).CheckTime(now)
+			if got := errors.Is(err, ErrClockSkew); got != tc.skew {
+				t.Errorf("CheckTime() = %v, want skew: %t", err, tc.skew)
+			}
+		})
+	}
+}
@@ -38,7 +38,9 @@ func New(reg name.Registry, auth authn.Authenticator, t http.RoundTripper, scope
 // In case the RoundTripper is already of the type Wrapper it assumes
 // authentication was already done prior to this call, so it just returns
 // the provided RoundTripper without further action
-func NewWithContext(ctx context.Context, reg name.Registry, auth authn.Authenticator, t http.RoundTripper, scopes []string) (http.RoundTripper, error) {
+//
+// Of the options, only WithTokenExchanged applies.
+func NewWithContext(ctx context.Context, reg name.Registry, auth authn.Authenticator, t http.RoundTripper, scopes []string, opts ...Option) (http.RoundTripper, error) {
 	// When the transport provided is of the type Wrapper this function assumes that the caller already
 	// executed the necessary login and check.
 	switch t.(type) {
@@ -90,6 +92,11 @@ func NewWithContext(ctx context.Context, reg name.Registry, auth authn.Authentic
 		return nil, err
 	}
 	bt.scopes = scopes
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	bt.exchanged = o.tokenExchanged
 
 	if err := bt.refresh(ctx); err != nil {
 		return nil, err
@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Verifier decides whether a manifest may be pulled, e.g. by checking that it
+// has been signed.
+type Verifier interface {
+	// Verify is called with the reference that was pulled, the descriptor of
+	// the manifest the registry returned for it, and the artifacts that refer
+	// to that manifest (see Referrers). The referrers index is backed by the
+	// registry, so the artifacts themselves can be fetched through it, e.g.
+	// with referrers.Image(digest).
+	//
+	// If Verify returns an error, the pull fails with that error.
+	Verify(ctx context.Context, ref name.Reference, desc v1.Descriptor, referrers v1.ImageIndex) error
+}
+
+// VerifierFunc adapts a function to a Verifier.
+type VerifierFunc func(ctx context.Context, ref name.Reference, desc v1.Descriptor, referrers v1.ImageIndex) error
+
+// Verify implements Verifier.
+func (f VerifierFunc) Verify(ctx context.Context, ref name.Reference, desc v1.Descriptor, referrers v1.ImageIndex) error {
+	return f(ctx, ref, desc, referrers)
+}
+
+// WithVerifier sets a Verifier that every manifest fetched by Get, Image and
+// Index (and the equivalent Puller methods) must pass.
+//
+// Only the manifest that ref points to is verified. When Image resolves an
+// index to a platform-specific image, the index is what gets verified.
+func WithVerifier(v Verifier) Option {
+	return func(o *options) error {
+		o.verifier = v
+		return nil
+	}
+}
+
+// verifyPull runs the Verifier, if any, against a freshly fetched manifest.
+func (p *Puller) verifyPull(ctx context.Context, ref name.Reference, desc v1.Descriptor) error {
+	if p.o.verifier == nil {
+		return nil
+	}
+	referrers, err := p.referrers(ctx, ref.Context().Digest(desc.Digest.String()), nil)
+	if err != nil {
+		return fmt.Errorf("fetching referrers of %s: %w", ref, err)
+	}
+	if err := p.o.verifier.Verify(ctx, ref, desc, referrers); err != nil {
+		return fmt.Errorf("verifying %s: %w", ref, err)
+	}
+	return nil
+}
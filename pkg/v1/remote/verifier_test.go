@@ -0,0 +1,127 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+const signatureType = "application/vnd.example.signature"
+
+var errUnsigned = errors.New("unsigned")
+
+// requireSignature only allows manifests that have a signature artifact
+// whose annotation names them.
+var requireSignature = remote.VerifierFunc(func(_ context.Context, _ name.Reference, desc v1.Descriptor, referrers v1.ImageIndex) error {
+	m, err := referrers.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, r := range m.Manifests {
+		if r.ArtifactType != signatureType {
+			continue
+		}
+		sig, err := referrers.Image(r.Digest)
+		if err != nil {
+			return err
+		}
+		sm, err := sig.Manifest()
+		if err != nil {
+			return err
+		}
+		if sm.Annotations["signed"] == desc.Digest.String() {
+			return nil
+		}
+	}
+	return errUnsigned
+})
+
+func TestWithVerifier(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := random.Index(1024, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(u.Host + "/test/verify:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	opt := remote.WithVerifier(requireSignature)
+	if _, err := remote.Index(ref, opt); !errors.Is(err, errUnsigned) {
+		t.Fatalf("Index: got %v, want %v", err, errUnsigned)
+	}
+	if _, err := remote.Get(ref, opt); !errors.Is(err, errUnsigned) {
+		t.Fatalf("Get: got %v, want %v", err, errUnsigned)
+	}
+
+	// Sign the index.
+	desc, err := partial.Descriptor(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := random.Image(10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig = mutate.ConfigMediaType(sig, types.MediaType(signatureType))
+	sig = mutate.Annotations(sig, map[string]string{"signed": desc.Digest.String()}).(v1.Image)
+	sig = mutate.Subject(sig, *desc).(v1.Image)
+	sigDigest, err := sig.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref.Context().Digest(sigDigest.String()), sig); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := remote.Index(ref, opt); err != nil {
+		t.Errorf("Index: %v", err)
+	}
+	img, err := remote.Image(ref, opt)
+	if err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	if _, err := img.Manifest(); err != nil {
+		t.Errorf("Manifest: %v", err)
+	}
+
+	// The signature itself isn't signed.
+	if _, err := remote.Image(ref.Context().Digest(sigDigest.String()), opt); !errors.Is(err, errUnsigned) {
+		t.Errorf("Image: got %v, want %v", err, errUnsigned)
+	}
+}
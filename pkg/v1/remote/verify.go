@@ -0,0 +1,146 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/go-containerregistry/internal/redact"
+	"github.com/google/go-containerregistry/internal/verify"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// BlobMismatchError is returned when the content a registry serves for a
+// blob doesn't match the size or digest we expected, e.g. because a proxy
+// truncated the response.
+type BlobMismatchError struct {
+	// Digest is the digest of the blob that was requested.
+	Digest v1.Hash
+
+	// URL is the (redacted) URL that served the blob.
+	URL string
+
+	// ExpectedSize is the size we expected, or -1 if it was unknown.
+	ExpectedSize int64
+
+	// ActualSize is the number of bytes that were received, or the
+	// Content-Length the server reported if we gave up before reading.
+	ActualSize int64
+
+	// ActualDigest is the digest of the content that was received, if the
+	// sizes matched but the content did not.
+	ActualDigest string
+
+	err error
+}
+
+// Error implements error.
+func (e *BlobMismatchError) Error() string {
+	if e.ActualDigest != "" {
+		return fmt.Sprintf("blob %s from %s: got digest %s after reading %d bytes", e.Digest, e.URL, e.ActualDigest, e.ActualSize)
+	}
+	return fmt.Sprintf("blob %s from %s: got %d bytes, want %d", e.Digest, e.URL, e.ActualSize, e.ExpectedSize)
+}
+
+// Unwrap returns the underlying verification error, if any.
+func (e *BlobMismatchError) Unwrap() error {
+	return e.err
+}
+
+// verifyBlob checks resp's Content-Length against size and wraps its body so
+// that any mismatch in size or digest surfaces as a *BlobMismatchError.
+func verifyBlob(resp *http.Response, size int64, h v1.Hash) (io.ReadCloser, error) {
+	u := ""
+	if resp.Request != nil {
+		u = redact.URL(resp.Request.URL)
+	}
+
+	// Do whatever we can.
+	// If we have an expected size and Content-Length doesn't match, return an error.
+	// If we don't have an expected size and we do have a Content-Length, use Content-Length.
+	if hsize := resp.ContentLength; hsize != -1 {
+		if size == verify.SizeUnknown {
+			size = hsize
+		} else if hsize != size {
+			resp.Body.Close()
+			return nil, &BlobMismatchError{
+				Digest:       h,
+				URL:          u,
+				ExpectedSize: size,
+				ActualSize:   hsize,
+				err:          fmt.Errorf("Content-Length header %d does not match expected size %d", hsize, size),
+			}
+		}
+	}
+
+	rc, err := verify.ReadCloser(resp.Body, size, h)
+	if err != nil {
+		return nil, err
+	}
+	return &blobReader{
+		inner:  rc,
+		digest: h,
+		url:    u,
+		size:   size,
+	}, nil
+}
+
+// blobReader translates verification failures from the wrapped reader into
+// a *BlobMismatchError.
+type blobReader struct {
+	inner  io.ReadCloser
+	digest v1.Hash
+	url    string
+	size   int64
+	read   int64
+}
+
+// Read implements io.Reader.
+func (b *blobReader) Read(p []byte) (int, error) {
+	n, err := b.inner.Read(p)
+	b.read += int64(n)
+	if err == nil || err == io.EOF {
+		return n, err
+	}
+
+	merr := &BlobMismatchError{
+		Digest:       b.digest,
+		URL:          b.url,
+		ExpectedSize: b.size,
+		ActualSize:   b.read,
+		err:          err,
+	}
+	var verr verify.Error
+	var serr verify.SizeError
+	switch {
+	case errors.As(err, &verr):
+		merr.ActualDigest = verr.Got()
+	case errors.As(err, &serr):
+		merr.ActualSize = serr.Got
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		// The connection was closed before Content-Length bytes arrived.
+	default:
+		return n, err
+	}
+	return n, merr
+}
+
+// Close implements io.Closer.
+func (b *blobReader) Close() error {
+	return b.inner.Close()
+}
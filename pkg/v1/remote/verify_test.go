@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestBlobMismatchError(t *testing.T) {
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := layers[0]
+	ld, err := l.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := l.Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := io.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int64(len(blob))
+
+	for _, tc := range []struct {
+		name       string
+		serve      func(w http.ResponseWriter)
+		wantSize   int64
+		wantDigest bool
+	}{{
+		name: "truncated",
+		serve: func(w http.ResponseWriter) {
+			// No Content-Length, so the truncation is only noticed at EOF.
+			w.Write(blob[:size/2])
+			w.(http.Flusher).Flush()
+		},
+		wantSize: size / 2,
+	}, {
+		name: "content-length",
+		serve: func(w http.ResponseWriter) {
+			w.Header().Set("Content-Length", strconv.FormatInt(size-1, 10))
+			w.Write(blob[:size-1])
+		},
+		wantSize: size - 1,
+	}, {
+		name: "corrupted",
+		serve: func(w http.ResponseWriter) {
+			corrupt := bytes.Clone(blob)
+			corrupt[0] ^= 0xff
+			w.Write(corrupt)
+		},
+		wantSize:   size,
+		wantDigest: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := registry.New()
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/blobs/"+ld.String()) {
+					tc.serve(w)
+					return
+				}
+				reg.ServeHTTP(w, r)
+			}))
+			defer s.Close()
+			u, err := url.Parse(s.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ref, err := name.ParseReference(fmt.Sprintf("%s/repo:latest", u.Host))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := Write(ref, img); err != nil {
+				t.Fatal(err)
+			}
+
+			rimg, err := Image(ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rl, err := rimg.LayerByDigest(ld)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rc, err := rl.Compressed()
+			if err == nil {
+				_, err = io.Copy(io.Discard, rc)
+				rc.Close()
+			}
+
+			var merr *BlobMismatchError
+			if !errors.As(err, &merr) {
+				t.Fatalf("expected *BlobMismatchError, got %T: %v", err, err)
+			}
+			if merr.Digest != ld {
+				t.Errorf("Digest: got %s, want %s", merr.Digest, ld)
+			}
+			if merr.ExpectedSize != size {
+				t.Errorf("ExpectedSize: got %d, want %d", merr.ExpectedSize, size)
+			}
+			if merr.ActualSize != tc.wantSize {
+				t.Errorf("ActualSize: got %d, want %d", merr.ActualSize, tc.wantSize)
+			}
+			if (merr.ActualDigest != "") != tc.wantDigest {
+				t.Errorf("ActualDigest: got %q, wantDigest %t", merr.ActualDigest, tc.wantDigest)
+			}
+			if !strings.Contains(merr.URL, u.Host) {
+				t.Errorf("URL: got %q, want it to contain %q", merr.URL, u.Host)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/google/go-containerregistry/internal/redact"
 	"github.com/google/go-containerregistry/internal/retry"
@@ -59,6 +60,9 @@ type writer struct {
 	progress  *progress
 	backoff   Backoff
 	predicate retry.Predicate
+	mountWait time.Duration
+	verify    bool
+	metrics   Metrics
 
 	scopeLock sync.Mutex
 	// Keep track of scopes that we have already requested.
@@ -67,16 +71,12 @@ type writer struct {
 }
 
 func makeWriter(ctx context.Context, repo name.Repository, ls []v1.Layer, o *options) (*writer, error) {
-	auth := o.auth
-	if o.keychain != nil {
-		kauth, err := authn.Resolve(ctx, o.keychain, repo)
-		if err != nil {
-			return nil, err
-		}
-		auth = kauth
+	auth, err := resolveAuth(ctx, repo, o)
+	if err != nil {
+		return nil, err
 	}
 	scopes := scopesForUploadingImage(repo, ls)
-	tr, err := transport.NewWithContext(ctx, repo.Registry, auth, o.transport, scopes)
+	tr, err := transport.NewWithContext(ctx, repo.Registry, auth, o.transport, scopes, tokenOptions(o.metrics)...)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +93,9 @@ func makeWriter(ctx context.Context, repo name.Repository, ls []v1.Layer, o *opt
 		progress:  o.progress,
 		backoff:   o.retryBackoff,
 		predicate: o.retryPredicate,
+		mountWait: o.mountWait,
+		verify:    o.verifyDigests,
+		metrics:   o.metrics,
 		scopes:    scopes,
 		scopeSet:  scopeSet,
 	}, nil
@@ -124,8 +127,8 @@ func (w *writer) maybeUpdateScopes(ctx context.Context, ml *MountableLayer) erro
 		w.scopeSet[scope] = struct{}{}
 		w.scopes = append(w.scopes, scope)
 
-		logs.Debug.Printf("Refreshing token to add scope %q", scope)
-		wt, err := transport.NewWithContext(ctx, w.repo.Registry, w.auth, w.transport, w.scopes)
+		logs.DebugFor(logs.Write).Printf("Refreshing token to add scope %q", scope)
+		wt, err := transport.NewWithContext(ctx, w.repo.Registry, w.auth, w.transport, w.scopes, tokenOptions(w.metrics)...)
 		if err != nil {
 			return err
 		}
@@ -206,6 +209,7 @@ func (w *writer) initiateUpload(ctx context.Context, from, mount, origin string)
 		if from != "" {
 			// https://github.com/google/go-containerregistry/issues/1679
 			logs.Warn.Printf("retrying without mount: %v", err)
+			w.mounted(false)
 			return w.initiateUpload(ctx, "", "", "")
 		}
 		return "", false, err
@@ -216,6 +220,7 @@ func (w *writer) initiateUpload(ctx context.Context, from, mount, origin string)
 		if from != "" {
 			// https://github.com/google/go-containerregistry/issues/1404
 			logs.Warn.Printf("retrying without mount: %v", err)
+			w.mounted(false)
 			return w.initiateUpload(ctx, "", "", "")
 		}
 		return "", false, err
@@ -225,16 +230,88 @@ func (w *writer) initiateUpload(ctx context.Context, from, mount, origin string)
 	switch resp.StatusCode {
 	case http.StatusCreated:
 		// We're done, we were able to fast-path.
+		if mount != "" && from != "" {
+			w.mounted(true)
+		}
 		return "", true, nil
 	case http.StatusAccepted:
 		// Proceed to PATCH, upload has begun.
 		loc, err := w.nextLocation(resp)
-		return loc, false, err
+		if err != nil {
+			return "", false, err
+		}
+		if mount != "" && from != "" {
+			// The registry may have queued an asynchronous copy instead of
+			// refusing the mount, in which case the blob will show up soon.
+			mounted, err := w.awaitMount(ctx, mount, from)
+			if err != nil {
+				return "", false, err
+			}
+			w.mounted(mounted)
+			if mounted {
+				// We don't need the upload the registry started.
+				w.cancelUpload(loc)
+				return "", true, nil
+			}
+		}
+		// Upload the blob to the session the registry started.
+		return loc, false, nil
 	default:
 		panic("Unreachable: initiateUpload")
 	}
 }
 
+// mounted reports the result of a mount to w.metrics.
+func (w *writer) mounted(hit bool) {
+	if w.metrics != nil {
+		w.metrics.Mounted(hit)
+	}
+}
+
+// awaitMount polls for the blob with the given digest for up to w.mountWait
+// after a mount from the repository "from" was accepted but not completed. It
+// returns whether the blob appeared.
+func (w *writer) awaitMount(ctx context.Context, digest, from string) (bool, error) {
+	if w.mountWait <= 0 {
+		logs.Debug.Printf("registry did not mount %s from %s, uploading it", digest, from)
+		return false, nil
+	}
+	h, err := v1.NewHash(digest)
+	if err != nil {
+		return false, err
+	}
+
+	deadline := time.Now().Add(w.mountWait)
+	interval := 100 * time.Millisecond
+	for {
+		exists, err := w.checkExistingBlob(ctx, h)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			logs.Debug.Printf("registry mounted %s from %s asynchronously", digest, from)
+			return true, nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			logs.Debug.Printf("registry did not mount %s from %s within %s, uploading it", digest, from, w.mountWait)
+			return false, nil
+		}
+		if interval > remaining {
+			interval = remaining
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(interval):
+		}
+		if interval < time.Second {
+			interval *= 2
+		}
+	}
+}
+
 // streamBlob streams the contents of the blob to the specified location.
 // On failure, this will return an error.  On success, this will return the location
 // header indicating how to commit the streamed blob.
@@ -354,7 +431,7 @@ func (w *writer) uploadOne(ctx context.Context, l v1.Layer) error {
 
 			mount = h.String()
 		}
-		if ml, ok := l.(*MountableLayer); ok {
+		if ml, ok := asMountable(l); ok {
 			if err := w.maybeUpdateScopes(ctx, ml); err != nil {
 				return err
 			}
@@ -461,6 +538,49 @@ func unpackTaggable(t Taggable) ([]byte, *v1.Descriptor, error) {
 	}, nil
 }
 
+// verifyManifestDigest checks that raw, the bytes about to be pushed for t,
+// hash to the digest that t, desc and ref (if it is a digest) expect.
+func verifyManifestDigest(t Taggable, ref name.Reference, raw []byte, desc *v1.Descriptor) error {
+	h, _, err := v1.SHA256(bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	if desc.Digest != h {
+		return fmt.Errorf("manifest digest mismatch: pushing %s, expected %s", h, desc.Digest)
+	}
+	if d, ok := t.(interface{ Digest() (v1.Hash, error) }); ok {
+		want, err := d.Digest()
+		if err != nil {
+			return err
+		}
+		if want != h {
+			return fmt.Errorf("manifest digest mismatch: pushing %s, expected %s", h, want)
+		}
+	}
+	if d, ok := ref.(name.Digest); ok && d.DigestStr() != h.String() {
+		return fmt.Errorf("manifest digest mismatch: pushing %s to %s", h, d)
+	}
+	return nil
+}
+
+// storedDigest returns the digest that the registry says, in the response to
+// a manifest PUT, that it stored the manifest as.
+func storedDigest(resp *http.Response) (v1.Hash, bool) {
+	if h, err := v1.NewHash(resp.Header.Get("Docker-Content-Digest")); err == nil {
+		return h, true
+	}
+	loc, err := resp.Location()
+	if err != nil {
+		return v1.Hash{}, false
+	}
+	_, dgst, ok := strings.Cut(loc.Path, "/manifests/")
+	if !ok {
+		return v1.Hash{}, false
+	}
+	h, err := v1.NewHash(dgst)
+	return h, err == nil
+}
+
 // commitSubjectReferrers is responsible for updating the fallback tag manifest to track descriptors referring to a subject for registries that don't yet support the Referrers API.
 // TODO: use conditional requests to avoid race conditions
 func (w *writer) commitSubjectReferrers(ctx context.Context, sub name.Digest, add v1.Descriptor) error {
@@ -555,9 +675,10 @@ func (w *writer) commitManifest(ctx context.Context, t Taggable, ref name.Refere
 		return err
 	}
 	var mf struct {
-		MediaType types.MediaType `json:"mediaType"`
-		Subject   *v1.Descriptor  `json:"subject,omitempty"`
-		Config    struct {
+		MediaType    types.MediaType `json:"mediaType"`
+		ArtifactType string          `json:"artifactType,omitempty"`
+		Subject      *v1.Descriptor  `json:"subject,omitempty"`
+		Config       struct {
 			MediaType types.MediaType `json:"mediaType"`
 		} `json:"config"`
 	}
@@ -571,6 +692,11 @@ func (w *writer) commitManifest(ctx context.Context, t Taggable, ref name.Refere
 		if err != nil {
 			return err
 		}
+		if w.verify {
+			if err := verifyManifestDigest(t, ref, raw, desc); err != nil {
+				return err
+			}
+		}
 
 		u := w.url(fmt.Sprintf("/v2/%s/manifests/%s", w.repo.RepositoryStr(), ref.Identifier()))
 
@@ -590,6 +716,23 @@ func (w *writer) commitManifest(ctx context.Context, t Taggable, ref name.Refere
 		if err := transport.CheckError(resp, http.StatusOK, http.StatusCreated, http.StatusAccepted); err != nil {
 			return err
 		}
+		if stored, ok := storedDigest(resp); ok && stored != desc.Digest {
+			rerr := &ManifestRewrittenError{
+				Ref:       ref,
+				Pushed:    desc.Digest,
+				MediaType: desc.MediaType,
+				Stored:    stored,
+			}
+			// Try to learn what the registry rewrote the manifest to.
+			f := &fetcher{target: ref.Context(), client: w.client}
+			if d, err := f.headManifest(ctx, ref.Context().Digest(stored.String()), allManifestMediaTypes); err == nil {
+				rerr.StoredMediaType = d.MediaType
+			}
+			if w.verify {
+				return rerr
+			}
+			logs.Warn.Printf("%v", rerr)
+		}
 
 		// If the manifest referred to a subject, we may need to update the fallback tag manifest.
 		// TODO: If this fails, we'll retry the whole upload. We should retry just this part.
@@ -604,6 +747,9 @@ func (w *writer) commitManifest(ctx context.Context, t Taggable, ref name.Refere
 				Digest:       h,
 				Size:         size,
 			}
+			if mf.ArtifactType != "" {
+				desc.ArtifactType = mf.ArtifactType
+			}
 			if err := w.commitSubjectReferrers(ctx,
 				ref.Context().Digest(mf.Subject.Digest.String()),
 				desc); err != nil {
@@ -625,7 +771,7 @@ func scopesForUploadingImage(repo name.Repository, layers []v1.Layer) []string {
 	scopeSet := map[string]struct{}{}
 
 	for _, l := range layers {
-		if ml, ok := l.(*MountableLayer); ok {
+		if ml, ok := asMountable(l); ok {
 			// we will add push scope for ref.Context() after the loop.
 			// for now we ask pull scope for references of the same registry
 			if ml.Reference.Context().String() != repo.String() && ml.Reference.Context().Registry.String() == repo.Registry.String() {
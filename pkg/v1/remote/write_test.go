@@ -19,19 +19,25 @@ import (
 	"context"
 	"crypto"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-containerregistry/pkg/compression"
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/google/go-containerregistry/pkg/registry"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
@@ -305,6 +311,84 @@ func TestInitiateUploadNoMountsInitiated(t *testing.T) {
 	}
 }
 
+func TestInitiateUploadMountAccepted(t *testing.T) {
+	img := setupImage(t)
+	h := mustConfigName(t, img)
+	expectedRepo := "baz/blah"
+	expectedLocation := "/upload?foo=bar"
+
+	for _, tc := range []struct {
+		name        string
+		wait        time.Duration
+		appearAfter int
+		wantMounted bool
+	}{{
+		name:        "no wait",
+		appearAfter: 0,
+		wantMounted: false,
+	}, {
+		name:        "appears",
+		wait:        10 * time.Second,
+		appearAfter: 2,
+		wantMounted: true,
+	}, {
+		name:        "never appears",
+		wait:        300 * time.Millisecond,
+		appearAfter: 1000,
+		wantMounted: false,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			heads, deletes := 0, 0
+			w, closer, err := setupWriter(expectedRepo, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case http.MethodPost:
+					w.Header().Set("Location", expectedLocation)
+					http.Error(w, "Initiated", http.StatusAccepted)
+				case http.MethodHead:
+					heads++
+					if heads <= tc.appearAfter {
+						http.Error(w, "NotFound", http.StatusNotFound)
+						return
+					}
+					w.WriteHeader(http.StatusOK)
+				case http.MethodDelete:
+					if r.URL.String() != expectedLocation {
+						t.Errorf("DELETE %s, want %s", r.URL, expectedLocation)
+					}
+					deletes++
+					w.WriteHeader(http.StatusNoContent)
+				default:
+					t.Errorf("unexpected %s %s", r.Method, r.URL)
+				}
+			}))
+			if err != nil {
+				t.Fatalf("setupWriter() = %v", err)
+			}
+			defer closer.Close()
+			w.mountWait = tc.wait
+
+			location, mounted, err := w.initiateUpload(context.Background(), "baz/bar", h.String(), "")
+			if err != nil {
+				t.Fatalf("initiateUpload() = %v", err)
+			}
+			if mounted != tc.wantMounted {
+				t.Errorf("initiateUpload() mounted = %t, want %t", mounted, tc.wantMounted)
+			}
+			if !mounted && !strings.HasSuffix(location, expectedLocation) {
+				t.Errorf("initiateUpload(); got %v, want %v", location, expectedLocation)
+			}
+			// The upload the registry started is only used if the blob
+			// wasn't mounted.
+			if want := map[bool]int{true: 1}[mounted]; deletes != want {
+				t.Errorf("got %d DELETE requests, want %d", deletes, want)
+			}
+			if tc.wait == 0 && heads != 0 {
+				t.Errorf("got %d HEAD requests without a mount wait, want 0", heads)
+			}
+		})
+	}
+}
+
 func TestInitiateUploadNoMountsBadStatus(t *testing.T) {
 	img := setupImage(t)
 	h := mustConfigName(t, img)
@@ -1617,3 +1701,356 @@ func BenchmarkWrite(b *testing.B) {
 		}
 	}
 }
+
+// digestRewriter pretends the registry stored a manifest under another digest.
+type digestRewriter struct {
+	http.ResponseWriter
+}
+
+func (d *digestRewriter) WriteHeader(code int) {
+	d.Header().Set("Docker-Content-Digest", "sha256:"+strings.Repeat("0", 64))
+	d.ResponseWriter.WriteHeader(code)
+}
+
+func TestDigestVerification(t *testing.T) {
+	var rewrite atomic.Bool
+	reg := registry.New()
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rewrite.Load() && r.Method == http.MethodPut && strings.Contains(r.URL.Path, "/manifests/") {
+			w = &digestRewriter{w}
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := mustNewTag(t, u.Host+"/test/src:latest")
+	if err := Write(src, img); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-indent the manifest so that re-marshalling it would change its digest.
+	m, err := img.RawManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, m, "", "   "); err != nil {
+		t.Fatal(err)
+	}
+	if err := Put(src, &rawManifest{indented.Bytes()}); err != nil {
+		t.Fatal(err)
+	}
+
+	desc, err := Get(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := mustNewTag(t, u.Host+"/test/dst:latest")
+	if err := Push(dst, desc, WithDigestVerification()); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	got, err := Head(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Digest != desc.Digest {
+		t.Errorf("dst digest = %s, want %s", got.Digest, desc.Digest)
+	}
+
+	t.Run("bad descriptor", func(t *testing.T) {
+		bad := *desc
+		bad.Digest = v1.Hash{Algorithm: "sha256", Hex: strings.Repeat("1", 64)}
+		if err := Push(mustNewTag(t, u.Host+"/test/bad:latest"), &bad, WithDigestVerification()); err == nil {
+			t.Error("Push: expected error for mismatched descriptor")
+		}
+	})
+
+	t.Run("bad digest reference", func(t *testing.T) {
+		ref := dst.Context().Digest("sha256:" + strings.Repeat("2", 64))
+		if err := Put(ref, &rawManifest{m}, WithDigestVerification()); err == nil {
+			t.Error("Put: expected error for mismatched reference")
+		}
+	})
+
+	t.Run("registry rewrites digest", func(t *testing.T) {
+		rewrite.Store(true)
+		defer rewrite.Store(false)
+		rewritten := mustNewTag(t, u.Host+"/test/rewritten:latest")
+		err := Push(rewritten, desc, WithDigestVerification())
+		var rerr *ManifestRewrittenError
+		if !errors.As(err, &rerr) {
+			t.Fatalf("Push: got %v, want *ManifestRewrittenError", err)
+		}
+		if rerr.Pushed != desc.Digest || rerr.Stored.Hex != strings.Repeat("0", 64) || rerr.MediaType != desc.MediaType {
+			t.Errorf("Push: got %+v", rerr)
+		}
+
+		// Without verification, it's a warning.
+		var warnings bytes.Buffer
+		logs.Warn.SetOutput(&warnings)
+		defer logs.Warn.SetOutput(io.Discard)
+		if err := Push(mustNewTag(t, u.Host+"/test/rewritten:warn"), desc); err != nil {
+			t.Errorf("Push: %v", err)
+		}
+		if !strings.Contains(warnings.String(), "registry rewrote manifest "+desc.Digest.String()) {
+			t.Errorf("warnings = %q, want the rewritten manifest", warnings.String())
+		}
+	})
+}
+
+func TestWriteZstdLayer(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst := fmt.Sprintf("%s/test/zstd:latest", u.Host)
+	ref, err := name.ParseReference(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rl, err := random.Layer(1024, types.DockerLayer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := tarball.LayerFromOpener(rl.Uncompressed, tarball.WithCompression(compression.ZStd))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := mutate.AppendLayers(empty.Image, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Image(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Fatalf("validate.Image: %v", err)
+	}
+	layers, err := got.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layers) != 1 {
+		t.Fatalf("got %d layers, want 1", len(layers))
+	}
+	mt, err := layers[0].MediaType()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mt != types.OCILayerZStd {
+		t.Errorf("MediaType() = %s, want %s", mt, types.OCILayerZStd)
+	}
+	gotDiffID, err := layers[0].DiffID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDiffID, err := rl.DiffID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotDiffID != wantDiffID {
+		t.Errorf("DiffID() = %s, want %s", gotDiffID, wantDiffID)
+	}
+}
+
+// relayServers serves src and dst registries, with an image in src whose
+// layer is served by serveLayer. It returns the image's reference in src and
+// the repository to copy it to in dst.
+func relayServers(t *testing.T, serveLayer func(w http.ResponseWriter, body []byte), onDst func(*http.Request)) (name.Reference, name.Repository, v1.Hash) {
+	t.Helper()
+	quiet := registry.Logger(log.New(io.Discard, "", 0))
+	srcReg, dstReg := registry.New(quiet), registry.New(quiet)
+
+	img, err := random.Image(4<<20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer, err := layers[0].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !strings.HasSuffix(r.URL.Path, "/blobs/"+layer.String()) {
+			srcReg.ServeHTTP(w, r)
+			return
+		}
+		rec := httptest.NewRecorder()
+		srcReg.ServeHTTP(rec, r)
+		for k, v := range rec.Header() {
+			w.Header()[k] = v
+		}
+		serveLayer(w, rec.Body.Bytes())
+	}))
+	t.Cleanup(src.Close)
+	dst := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		onDst(r)
+		dstReg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(dst.Close)
+
+	srcRef := mustNewTag(t, strings.TrimPrefix(src.URL, "http://")+"/src:latest")
+	if err := Write(srcRef, img); err != nil {
+		t.Fatal(err)
+	}
+	dstRepo, err := name.NewRepository(strings.TrimPrefix(dst.URL, "http://") + "/dst")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srcRef, dstRepo, layer
+}
+
+func TestWriteStreamsBetweenRegistries(t *testing.T) {
+	patched := make(chan struct{})
+	var once sync.Once
+	srcRef, dstRepo, _ := relayServers(t, func(w http.ResponseWriter, body []byte) {
+		// Hold back the second half of the layer until dst is receiving the
+		// first, which only happens if the blob isn't buffered in between.
+		w.Write(body[:len(body)/2])
+		w.(http.Flusher).Flush()
+		select {
+		case <-patched:
+		case <-time.After(10 * time.Second):
+			t.Error("dst got no upload before src sent the whole layer")
+		}
+		w.Write(body[len(body)/2:])
+	}, func(r *http.Request) {
+		// The config is uploaded too, but it's much smaller than the layer.
+		if r.Method == http.MethodPatch && r.ContentLength > 1<<20 {
+			once.Do(func() { close(patched) })
+		}
+	})
+
+	img, err := Image(srcRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstRef := dstRepo.Tag("latest")
+	if err := Write(dstRef, img); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Image(dstRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := validate.Image(got); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWriteStreamVerifiesDigest(t *testing.T) {
+	srcRef, dstRepo, layer := relayServers(t, func(w http.ResponseWriter, body []byte) {
+		// Same size, different digest.
+		body[len(body)-1] ^= 0xff
+		w.Write(body)
+	}, func(*http.Request) {})
+
+	img, err := Image(srcRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(dstRepo.Tag("latest"), img, WithRetryBackoff(Backoff{Steps: 1})); err == nil {
+		t.Fatal("Write() = nil, want digest mismatch")
+	}
+	u := fmt.Sprintf("http://%s/v2/%s/blobs/%s", dstRepo.RegistryStr(), dstRepo.RepositoryStr(), layer)
+	resp, err := http.Head(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("HEAD %s = %d, want %d: the corrupt layer was committed", u, resp.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestWriteSealedImageMounts(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)), registry.ProfileHarbor())
+	var mu sync.Mutex
+	mounts := map[string]string{}
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The registry stores blobs once for all its repositories, so don't
+		// let the push find blobs that are in the source repository.
+		if r.Method == http.MethodHead && strings.Contains(r.URL.Path, "/blobs/") {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodPost && r.URL.Query().Has("mount") {
+			mu.Lock()
+			mounts[r.URL.Query().Get("mount")] = r.URL.Query().Get("from")
+			mu.Unlock()
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := name.NewTag(u.Host + "/src:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dst, err := name.NewTag(u.Host + "/dst:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(src, img); err != nil {
+		t.Fatal(err)
+	}
+
+	rmt, err := Image(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := mutate.Seal(rmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	mounts = map[string]string{}
+	mu.Unlock()
+	if err := Write(dst, sealed); err != nil {
+		t.Fatal(err)
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, l := range layers {
+		h, err := l.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if from := mounts[h.String()]; from != "src" {
+			t.Errorf("layer %s mounted from %q, want %q", h, from, "src")
+		}
+	}
+}
@@ -66,3 +66,13 @@ func (l *staticLayer) Size() (int64, error) {
 func (l *staticLayer) MediaType() (types.MediaType, error) {
 	return l.mt, nil
 }
+
+// UncompressedSize implements partial.WithUncompressedSize.
+func (l *staticLayer) UncompressedSize() (int64, error) {
+	return int64(len(l.b)), nil
+}
+
+// Exists implements partial.WithExists.
+func (l *staticLayer) Exists() (bool, error) {
+	return true, nil
+}
@@ -46,10 +46,15 @@ type Layer struct {
 	consumed    bool
 	compression int
 
-	mu             sync.Mutex
-	digest, diffID *v1.Hash
-	size           int64
-	mediaType      types.MediaType
+	spoolDir string
+	spooling bool
+	spool    *spool
+
+	mu               sync.Mutex
+	digest, diffID   *v1.Hash
+	size             int64
+	uncompressedSize int64
+	mediaType        types.MediaType
 }
 
 var _ v1.Layer = (*Layer)(nil)
@@ -71,6 +76,22 @@ func WithMediaType(mt types.MediaType) LayerOption {
 	}
 }
 
+// WithSpool makes the layer replayable: when Compressed is first called, the
+// stream is compressed in the background into a temporary file in dir (or the
+// default temporary directory, if dir is empty), and every call to Compressed,
+// including concurrent ones, reads from that file instead of returning
+// ErrConsumed, so the layer can be written to several destinations. Readers
+// wait for the file to be written, but not for each other, and closing one
+// early doesn't affect the others.
+//
+// Callers should call Close once every consumer is done to remove the file.
+func WithSpool(dir string) LayerOption {
+	return func(l *Layer) {
+		l.spooling = true
+		l.spoolDir = dir
+	}
+}
+
 // NewLayer creates a Layer from an io.ReadCloser.
 func NewLayer(rc io.ReadCloser, opts ...LayerOption) *Layer {
 	layer := &Layer{
@@ -118,6 +139,29 @@ func (l *Layer) Size() (int64, error) {
 	return l.size, nil
 }
 
+// UncompressedSize implements partial.WithUncompressedSize. Like Size, it
+// returns ErrNotComputed until the stream has been consumed.
+func (l *Layer) UncompressedSize() (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.diffID == nil {
+		return 0, ErrNotComputed
+	}
+	return l.uncompressedSize, nil
+}
+
+// Exists implements partial.WithExists, so that checking a layer doesn't
+// start consuming the stream. It returns ErrConsumed once the stream has
+// been consumed, unless the layer is spooled.
+func (l *Layer) Exists() (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.consumed && l.spool == nil {
+		return false, ErrConsumed
+	}
+	return true, nil
+}
+
 // MediaType implements v1.Layer
 func (l *Layer) MediaType() (types.MediaType, error) {
 	return l.mediaType, nil
@@ -132,14 +176,75 @@ func (l *Layer) Uncompressed() (io.ReadCloser, error) {
 func (l *Layer) Compressed() (io.ReadCloser, error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	if l.spool != nil {
+		return l.spool.reader(), nil
+	}
 	if l.consumed {
 		return nil, ErrConsumed
 	}
+	if l.spooling {
+		s, err := newSpool(l.spoolDir)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.startSpool(s); err != nil {
+			s.remove()
+			return nil, err
+		}
+		l.spool = s
+		return s.reader(), nil
+	}
 	return newCompressedReader(l)
 }
 
+// startSpool compresses the stream into s in the background, so that its
+// readers don't depend on each other: each can be read in any order, or
+// closed early, without holding up or failing the others.
+func (l *Layer) startSpool(s *spool) error {
+	h := crypto.SHA256.New()
+	zh := crypto.SHA256.New()
+	count := &countWriter{}
+	ucount := &countWriter{}
+
+	bw := bufio.NewWriterSize(io.MultiWriter(s, zh, count), 2<<16)
+	zw, err := gzip.NewWriterLevel(bw, l.compression)
+	if err != nil {
+		return err
+	}
+	go func() {
+		_, err := io.Copy(io.MultiWriter(h, ucount, zw), l.blob)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = bw.Flush()
+		}
+		if cerr := l.blob.Close(); err == nil && !errors.Is(cerr, os.ErrClosed) {
+			err = cerr
+		}
+		if err == nil {
+			// Readers of the spool are only released once the digest is
+			// available.
+			err = l.finalize(h, zh, ucount.n, count.n)
+		}
+		s.finish(err)
+	}()
+	return nil
+}
+
+// Close removes the file created by WithSpool, if any. Readers returned by
+// Compressed must not be used after Close.
+func (l *Layer) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.spool == nil {
+		return nil
+	}
+	return l.spool.remove()
+}
+
 // finalize sets the layer to consumed and computes all hash and size values.
-func (l *Layer) finalize(uncompressed, compressed hash.Hash, size int64) error {
+func (l *Layer) finalize(uncompressed, compressed hash.Hash, uncompressedSize, size int64) error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -156,6 +261,7 @@ func (l *Layer) finalize(uncompressed, compressed hash.Hash, size int64) error {
 	l.digest = &digest
 
 	l.size = size
+	l.uncompressedSize = uncompressedSize
 	l.consumed = true
 	return nil
 }
@@ -166,11 +272,11 @@ type compressedReader struct {
 }
 
 func newCompressedReader(l *Layer) (*compressedReader, error) {
-	// Collect digests of compressed and uncompressed stream and size of
-	// compressed stream.
+	// Collect digests and sizes of compressed and uncompressed stream.
 	h := crypto.SHA256.New()
 	zh := crypto.SHA256.New()
 	count := &countWriter{}
+	ucount := &countWriter{}
 
 	// gzip.Writer writes to the output stream via pipe, a hasher to
 	// capture compressed digest, and a countWriter to capture compressed
@@ -217,13 +323,14 @@ func newCompressedReader(l *Layer) (*compressedReader, error) {
 
 			// Finalize layer with its digest and size values.
 			<-doneDigesting
-			return l.finalize(h, zh, count.n)
+			return l.finalize(h, zh, ucount.n, count.n)
 		},
 	}
 	go func() {
 		// Copy blob into the gzip writer, which also hashes and counts the
-		// size of the compressed output, and hasher of the raw contents.
-		_, copyErr := io.Copy(io.MultiWriter(h, zw), l.blob)
+		// size of the compressed output, and hasher and counter of the raw
+		// contents.
+		_, copyErr := io.Copy(io.MultiWriter(h, ucount, zw), l.blob)
 
 		// Close the gzip writer once copying is done. If this is done in the
 		// Close method of compressedReader instead, then it can cause a panic
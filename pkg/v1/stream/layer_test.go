@@ -25,6 +25,7 @@ import (
 	"testing"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 )
@@ -197,12 +198,19 @@ func TestNotComputed(t *testing.T) {
 	if _, err := l.DiffID(); err == nil {
 		t.Errorf("DiffID: got %v, want %v", err, ErrNotComputed)
 	}
+	if _, err := l.UncompressedSize(); !errors.Is(err, ErrNotComputed) {
+		t.Errorf("UncompressedSize: got %v, want %v", err, ErrNotComputed)
+	}
 }
 
 // TestConsumed tests that Compressed returns ErrConsumed when the stream has
 // already been consumed.
 func TestConsumed(t *testing.T) {
 	l := NewLayer(io.NopCloser(strings.NewReader("hello")))
+	// Checking that the layer exists doesn't consume it.
+	if ok, err := partial.Exists(l); !ok || err != nil {
+		t.Errorf("Exists() = %t, %v", ok, err)
+	}
 	rc, err := l.Compressed()
 	if err != nil {
 		t.Errorf("Compressed: %v", err)
@@ -217,6 +225,12 @@ func TestConsumed(t *testing.T) {
 	if _, err := l.Compressed(); !errors.Is(err, ErrConsumed) {
 		t.Errorf("Compressed() after consuming; got %v, want %v", err, ErrConsumed)
 	}
+	if _, err := partial.Exists(l); !errors.Is(err, ErrConsumed) {
+		t.Errorf("Exists() after consuming; got %v, want %v", err, ErrConsumed)
+	}
+	if n, err := partial.UncompressedSize(l); n != 5 || err != nil {
+		t.Errorf("UncompressedSize() = %d, %v; want 5", n, err)
+	}
 }
 
 func TestCloseTextStreamBeforeConsume(t *testing.T) {
@@ -293,3 +307,120 @@ func TestMediaTypeOption(t *testing.T) {
 		t.Errorf("MediaType(): want %q, got %q", want, got)
 	}
 }
+
+func TestSpool(t *testing.T) {
+	blob := bytes.Repeat([]byte("hello "), 100000)
+	l := NewLayer(io.NopCloser(bytes.NewReader(blob)), WithSpool(t.TempDir()))
+	defer l.Close()
+
+	// Start a second reader before the first has consumed anything.
+	first, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+	second, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+
+	var secondBytes []byte
+	var secondErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		secondBytes, secondErr = io.ReadAll(second)
+	}()
+
+	firstBytes, err := io.ReadAll(first)
+	if err != nil {
+		t.Fatalf("reading first: %v", err)
+	}
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	<-done
+	if secondErr != nil {
+		t.Fatalf("reading second: %v", secondErr)
+	}
+	if !bytes.Equal(firstBytes, secondBytes) {
+		t.Errorf("concurrent reader got %d bytes, want %d", len(secondBytes), len(firstBytes))
+	}
+
+	// Reading again after the stream is consumed replays it.
+	third, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed after consuming: %v", err)
+	}
+	thirdBytes, err := io.ReadAll(third)
+	if err != nil {
+		t.Fatalf("reading third: %v", err)
+	}
+	if !bytes.Equal(firstBytes, thirdBytes) {
+		t.Errorf("replay got %d bytes, want %d", len(thirdBytes), len(firstBytes))
+	}
+
+	h, size, err := v1.SHA256(bytes.NewReader(thirdBytes))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := l.Digest(); err != nil || got != h {
+		t.Errorf("Digest: got %v, %v; want %v", got, err, h)
+	}
+	if got, err := l.Size(); err != nil || got != size {
+		t.Errorf("Size: got %v, %v; want %v", got, err, size)
+	}
+}
+
+func TestSpoolClosedEarly(t *testing.T) {
+	blob := bytes.Repeat([]byte("a"), 1<<20)
+	l := NewLayer(io.NopCloser(bytes.NewReader(blob)), WithSpool(t.TempDir()))
+	defer l.Close()
+
+	first, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+	second, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+	// Closing a reader without reading it, e.g. because the destination
+	// already has the blob, doesn't consume the stream for the others.
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := io.ReadAll(second); err != nil {
+		t.Errorf("reading after first reader closed early: %v", err)
+	}
+	if _, err := l.Digest(); err != nil {
+		t.Errorf("Digest: %v", err)
+	}
+}
+
+func TestSpoolReadOutOfOrder(t *testing.T) {
+	blob := bytes.Repeat([]byte("hello "), 100000)
+	l := NewLayer(io.NopCloser(bytes.NewReader(blob)), WithSpool(t.TempDir()))
+	defer l.Close()
+
+	first, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+	second, err := l.Compressed()
+	if err != nil {
+		t.Fatalf("Compressed: %v", err)
+	}
+
+	// One goroutine reads the second reader before the first.
+	secondBytes, err := io.ReadAll(second)
+	if err != nil {
+		t.Fatalf("reading second: %v", err)
+	}
+	firstBytes, err := io.ReadAll(first)
+	if err != nil {
+		t.Fatalf("reading first: %v", err)
+	}
+	if !bytes.Equal(firstBytes, secondBytes) {
+		t.Errorf("second reader got %d bytes, want %d", len(secondBytes), len(firstBytes))
+	}
+}
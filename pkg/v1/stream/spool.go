@@ -0,0 +1,118 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stream
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// spool is a temporary file holding the compressed contents of a Layer as
+// they are produced. Any number of readers can follow it, blocking until more
+// data is written or the stream is finished.
+type spool struct {
+	f *os.File
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	n    int64
+	done bool
+	err  error
+}
+
+func newSpool(dir string) (*spool, error) {
+	f, err := os.CreateTemp(dir, "stream-layer-*")
+	if err != nil {
+		return nil, err
+	}
+	s := &spool{f: f}
+	s.cond = sync.NewCond(&s.mu)
+	return s, nil
+}
+
+// Write implements io.Writer.
+func (s *spool) Write(p []byte) (int, error) {
+	n, err := s.f.Write(p)
+	s.mu.Lock()
+	s.n += int64(n)
+	s.mu.Unlock()
+	s.cond.Broadcast()
+	return n, err
+}
+
+// finish marks the spool as complete, or as failed if err is non-nil.
+func (s *spool) finish(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if !s.done {
+		s.done = true
+		if err != nil {
+			s.err = errors.Join(ErrConsumed, err)
+		}
+	}
+	s.mu.Unlock()
+	s.cond.Broadcast()
+}
+
+func (s *spool) reader() io.ReadCloser {
+	return &spoolReader{s: s}
+}
+
+func (s *spool) remove() error {
+	s.f.Close()
+	return os.Remove(s.f.Name())
+}
+
+type spoolReader struct {
+	s   *spool
+	off int64
+}
+
+// Read implements io.Reader.
+func (r *spoolReader) Read(p []byte) (int, error) {
+	s := r.s
+	s.mu.Lock()
+	for r.off >= s.n && !s.done {
+		s.cond.Wait()
+	}
+	n, err := s.n, s.err
+	s.mu.Unlock()
+
+	if err != nil {
+		return 0, err
+	}
+	if r.off >= n {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > n-r.off {
+		p = p[:n-r.off]
+	}
+	m, err := s.f.ReadAt(p, r.off)
+	r.off += int64(m)
+	if errors.Is(err, io.EOF) {
+		// We never read past what has been written.
+		err = nil
+	}
+	return m, err
+}
+
+// Close implements io.Closer.
+func (r *spoolReader) Close() error {
+	return nil
+}
@@ -35,7 +35,7 @@ import (
 )
 
 type image struct {
-	opener        Opener
+	files         *tarFiles
 	manifest      *Manifest
 	config        []byte
 	imgDescriptor *Descriptor
@@ -89,8 +89,8 @@ func LoadManifest(opener Opener) (Manifest, error) {
 // Image exposes an image from the tarball at the provided path.
 func Image(opener Opener, tag *name.Tag) (v1.Image, error) {
 	img := &image{
-		opener: opener,
-		tag:    tag,
+		files: &tarFiles{opener: opener},
+		tag:   tag,
 	}
 	if err := img.loadTarDescriptorAndConfig(); err != nil {
 		return nil, err
@@ -161,7 +161,7 @@ func (i *image) areLayersCompressed() (bool, error) {
 		return false, errors.New("0 layers found in image")
 	}
 	layer := i.imgDescriptor.Layers[0]
-	blob, err := extractFileFromTar(i.opener, layer)
+	blob, err := i.files.open(layer)
 	if err != nil {
 		return false, err
 	}
@@ -176,7 +176,7 @@ func (i *image) areLayersCompressed() (bool, error) {
 }
 
 func (i *image) loadTarDescriptorAndConfig() error {
-	m, err := extractFileFromTar(i.opener, "manifest.json")
+	m, err := i.files.open("manifest.json")
 	if err != nil {
 		return err
 	}
@@ -195,7 +195,7 @@ func (i *image) loadTarDescriptorAndConfig() error {
 		return err
 	}
 
-	cfg, err := extractFileFromTar(i.opener, i.imgDescriptor.Config)
+	cfg, err := i.files.open(i.imgDescriptor.Config)
 	if err != nil {
 		return err
 	}
@@ -258,7 +258,7 @@ func extractFileFromTar(opener Opener, filePath string) (io.ReadCloser, error) {
 type uncompressedLayerFromTarball struct {
 	diffID    v1.Hash
 	mediaType types.MediaType
-	opener    Opener
+	files     *tarFiles
 	filePath  string
 }
 
@@ -281,7 +281,7 @@ func (ulft *uncompressedLayerFromTarball) DiffID() (v1.Hash, error) {
 
 // Uncompressed implements partial.UncompressedLayer
 func (ulft *uncompressedLayerFromTarball) Uncompressed() (io.ReadCloser, error) {
-	return extractFileFromTar(ulft.opener, ulft.filePath)
+	return ulft.files.open(ulft.filePath)
 }
 
 func (ulft *uncompressedLayerFromTarball) MediaType() (types.MediaType, error) {
@@ -312,7 +312,7 @@ func (i *uncompressedImage) LayerByDiffID(h v1.Hash) (partial.UncompressedLayer,
 						uncompressedLayerFromTarball: uncompressedLayerFromTarball{
 							diffID:    diffID,
 							mediaType: bd.MediaType,
-							opener:    i.opener,
+							files:     i.files,
 							filePath:  i.imgDescriptor.Layers[idx],
 						},
 						desc: bd,
@@ -325,7 +325,7 @@ func (i *uncompressedImage) LayerByDiffID(h v1.Hash) (partial.UncompressedLayer,
 			return &uncompressedLayerFromTarball{
 				diffID:    diffID,
 				mediaType: mt,
-				opener:    i.opener,
+				files:     i.files,
 				filePath:  i.imgDescriptor.Layers[idx],
 			}, nil
 		}
@@ -371,7 +371,7 @@ func (c *compressedImage) Manifest() (*v1.Manifest, error) {
 			// reading the entire file.
 			c.manifest.Layers = append(c.manifest.Layers, d)
 		} else {
-			l, err := extractFileFromTar(c.opener, p)
+			l, err := c.files.open(p)
 			if err != nil {
 				return nil, err
 			}
@@ -397,7 +397,7 @@ func (c *compressedImage) RawManifest() ([]byte, error) {
 // compressedLayerFromTarball implements partial.CompressedLayer
 type compressedLayerFromTarball struct {
 	desc     v1.Descriptor
-	opener   Opener
+	files    *tarFiles
 	filePath string
 }
 
@@ -408,7 +408,7 @@ func (clft *compressedLayerFromTarball) Digest() (v1.Hash, error) {
 
 // Compressed implements partial.CompressedLayer
 func (clft *compressedLayerFromTarball) Compressed() (io.ReadCloser, error) {
-	return extractFileFromTar(clft.opener, clft.filePath)
+	return clft.files.open(clft.filePath)
 }
 
 // MediaType implements partial.CompressedLayer
@@ -431,7 +431,7 @@ func (c *compressedImage) LayerByDigest(h v1.Hash) (partial.CompressedLayer, err
 			fp := c.imgDescriptor.Layers[i]
 			return &compressedLayerFromTarball{
 				desc:     l,
-				opener:   c.opener,
+				files:    c.files,
 				filePath: fp,
 			}, nil
 		}
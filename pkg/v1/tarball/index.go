@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tarball
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// tarFiles opens files within the tarball returned by opener.
+//
+// If the tarball supports random access (io.ReaderAt and io.Seeker, as
+// *os.File does), the first open records the offset of every entry, which
+// only requires reading the tar headers. Later opens read the entry directly
+// instead of scanning the archive from the start.
+type tarFiles struct {
+	opener Opener
+
+	once  sync.Once
+	index map[string]tarEntry
+	err   error
+}
+
+type tarEntry struct {
+	offset, size int64
+	// link is the target of a symlink or hardlink.
+	link string
+}
+
+// readerAtSeeker is what we need from a tarball to index it.
+type readerAtSeeker interface {
+	io.ReaderAt
+	io.Seeker
+}
+
+func (t *tarFiles) open(filePath string) (io.ReadCloser, error) {
+	t.once.Do(func() {
+		t.index, t.err = t.buildIndex()
+	})
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.index == nil {
+		return extractFileFromTar(t.opener, filePath)
+	}
+
+	// Follow links like extractFileFromTar, but don't loop forever.
+	target := filePath
+	e, ok := t.index[target]
+	for hops := 0; ok && e.link != ""; hops++ {
+		if hops > len(t.index) {
+			return nil, fmt.Errorf("too many links resolving %s in tar", filePath)
+		}
+		target = path.Join(filepath.Dir(target), path.Clean(e.link))
+		e, ok = t.index[target]
+	}
+	if !ok {
+		// Not something we indexed; fall back to scanning.
+		return extractFileFromTar(t.opener, filePath)
+	}
+
+	f, err := t.opener()
+	if err != nil {
+		return nil, err
+	}
+	ra, ok := f.(io.ReaderAt)
+	if !ok {
+		f.Close()
+		return nil, errors.New("tarball no longer supports random access")
+	}
+	return tarFile{
+		Reader: io.NewSectionReader(ra, e.offset, e.size),
+		Closer: f,
+	}, nil
+}
+
+// buildIndex returns the offsets of the entries in the tarball, or nil if the
+// tarball doesn't support random access.
+func (t *tarFiles) buildIndex() (map[string]tarEntry, error) {
+	f, err := t.opener()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rs, ok := f.(readerAtSeeker)
+	if !ok {
+		return nil, nil
+	}
+
+	// tar.Reader seeks past file contents when it can, so this only reads the
+	// headers.
+	index := map[string]tarEntry{}
+	tf := tar.NewReader(f)
+	for {
+		hdr, err := tf.Next()
+		if errors.Is(err, io.EOF) {
+			return index, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := index[hdr.Name]; ok {
+			// extractFileFromTar returns the first match.
+			continue
+		}
+		switch hdr.Typeflag {
+		case tar.TypeSymlink, tar.TypeLink:
+			index[hdr.Name] = tarEntry{link: hdr.Linkname}
+		case tar.TypeReg:
+			offset, err := rs.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, err
+			}
+			index[hdr.Name] = tarEntry{offset: offset, size: hdr.Size}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tarball
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// countingFile counts the bytes read from an *os.File.
+type countingFile struct {
+	*os.File
+	n *int64
+}
+
+func (f countingFile) Read(p []byte) (int, error) {
+	n, err := f.File.Read(p)
+	*f.n += int64(n)
+	return n, err
+}
+
+func (f countingFile) ReadAt(p []byte, off int64) (int, error) {
+	n, err := f.File.ReadAt(p, off)
+	*f.n += int64(n)
+	return n, err
+}
+
+func TestIndexedTarball(t *testing.T) {
+	for _, p := range []string{
+		"testdata/test_image_1.tar",
+		"testdata/hello-world-v25.tar",
+	} {
+		t.Run(p, func(t *testing.T) {
+			var read int64
+			opener := func() (io.ReadCloser, error) {
+				f, err := os.Open(p)
+				if err != nil {
+					return nil, err
+				}
+				return countingFile{File: f, n: &read}, nil
+			}
+
+			img, err := Image(opener, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := validate.Image(img); err != nil {
+				t.Errorf("validate.Image: %v", err)
+			}
+
+			ls, err := img.Layers()
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := ls[len(ls)-1]
+			size, err := l.Size()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// With the index, reading a layer only reads that layer.
+			read = 0
+			rc, err := l.Compressed()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := io.Copy(io.Discard, rc); err != nil {
+				t.Fatal(err)
+			}
+			rc.Close()
+
+			fi, err := os.Stat(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if read >= fi.Size() {
+				t.Errorf("reading a layer read %d bytes, the whole %d byte tarball", read, fi.Size())
+			}
+			t.Logf("read %d bytes for a %d byte layer from a %d byte tarball", read, size, fi.Size())
+		})
+	}
+}
@@ -38,6 +38,7 @@ type layer struct {
 	digest             v1.Hash
 	diffID             v1.Hash
 	size               int64
+	uncompressedSize   int64 // -1 if it isn't known
 	compressedopener   Opener
 	uncompressedopener Opener
 	compression        compression.Compression
@@ -53,12 +54,17 @@ func (l *layer) Descriptor() (*v1.Descriptor, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &v1.Descriptor{
-		Size:        l.size,
-		Digest:      digest,
-		Annotations: l.annotations,
-		MediaType:   l.mediaType,
-	}, nil
+	desc := &v1.Descriptor{
+		Size:      l.size,
+		Digest:    digest,
+		MediaType: l.mediaType,
+	}
+	// Leave Annotations nil rather than empty, as it is once the manifest
+	// is marshaled and parsed.
+	if len(l.annotations) != 0 {
+		desc.Annotations = l.annotations
+	}
+	return desc, nil
 }
 
 // Digest implements v1.Layer
@@ -91,13 +97,30 @@ func (l *layer) MediaType() (types.MediaType, error) {
 	return l.mediaType, nil
 }
 
+// UncompressedSize implements partial.WithUncompressedSize. The size is
+// counted while computing the layer's diffID; if that was given instead,
+// the uncompressed contents are read to count it.
+func (l *layer) UncompressedSize() (int64, error) {
+	if l.uncompressedSize >= 0 {
+		return l.uncompressedSize, nil
+	}
+	rc, err := l.uncompressedopener()
+	if err != nil {
+		return -1, err
+	}
+	defer rc.Close()
+	return io.Copy(io.Discard, rc)
+}
+
 // LayerOption applies options to layer
 type LayerOption func(*layer)
 
 // WithCompression is a functional option for overriding the default
 // compression algorithm used for compressing uncompressed tarballs.
-// Please note that WithCompression(compression.ZStd) should be used
-// in conjunction with WithMediaType(types.OCILayerZStd)
+//
+// With compression.ZStd, a docker or OCI gzip layer media type (including
+// the default) is replaced with types.OCILayerZStd. Tarballs that are
+// already compressed are used as they are.
 func WithCompression(comp compression.Compression) LayerOption {
 	return func(l *layer) {
 		switch comp {
@@ -237,6 +260,7 @@ func LayerFromOpener(opener Opener, opts ...LayerOption) (v1.Layer, error) {
 	layer := &layer{
 		compression:      compression.GZip,
 		compressionLevel: gzip.BestSpeed,
+		uncompressedSize: -1,
 		annotations:      make(map[string]string, 1),
 		mediaType:        types.DockerLayer,
 	}
@@ -285,6 +309,15 @@ func LayerFromOpener(opener Opener, opts ...LayerOption) (v1.Layer, error) {
 		opt(layer)
 	}
 
+	// Layers that are already compressed are used as they are, whatever
+	// WithCompression says.
+	if comp == compression.GZip || comp == compression.ZStd {
+		layer.compression = comp
+	}
+	if layer.compression == compression.ZStd && (layer.mediaType == types.DockerLayer || layer.mediaType == types.OCILayer) {
+		layer.mediaType = types.OCILayerZStd
+	}
+
 	// Warn if media type does not match compression
 	var mediaTypeMismatch = false
 	switch layer.compression {
@@ -308,7 +341,7 @@ func LayerFromOpener(opener Opener, opts ...LayerOption) (v1.Layer, error) {
 
 	empty := v1.Hash{}
 	if layer.diffID == empty {
-		if layer.diffID, err = computeDiffID(layer.uncompressedopener); err != nil {
+		if layer.diffID, layer.uncompressedSize, err = computeDiffID(layer.uncompressedopener); err != nil {
 			return nil, err
 		}
 	}
@@ -342,13 +375,12 @@ func computeDigest(opener Opener) (v1.Hash, int64, error) {
 	return v1.SHA256(rc)
 }
 
-func computeDiffID(opener Opener) (v1.Hash, error) {
+func computeDiffID(opener Opener) (v1.Hash, int64, error) {
 	rc, err := opener()
 	if err != nil {
-		return v1.Hash{}, err
+		return v1.Hash{}, -1, err
 	}
 	defer rc.Close()
 
-	digest, _, err := v1.SHA256(rc)
-	return digest, err
+	return v1.SHA256(rc)
 }
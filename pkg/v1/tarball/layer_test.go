@@ -19,12 +19,16 @@ import (
 	"compress/gzip"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/containerd/stargz-snapshotter/estargz"
+	ggzip "github.com/google/go-containerregistry/internal/gzip"
+	"github.com/google/go-containerregistry/internal/zstd"
 	"github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/compare"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/types"
 	"github.com/google/go-containerregistry/pkg/v1/validate"
 )
@@ -52,7 +56,12 @@ func TestLayerFromFile(t *testing.T) {
 		t.Errorf("compare.Layers: %v", err)
 	}
 
-	if err := compare.Layers(tarLayer, tarZstdLayer); err != nil {
+	zstdTarLayer, err := LayerFromFile("testdata/content.tar", WithCompression(compression.ZStd))
+	if err != nil {
+		t.Fatalf("Unable to create layer from tar file: %v", err)
+	}
+
+	if err := compare.Layers(zstdTarLayer, tarZstdLayer); err != nil {
 		t.Errorf("compare.Layers: %v", err)
 	}
 
@@ -257,8 +266,12 @@ func TestLayerFromOpenerReader(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Unable to create layer from tar file: %v", err)
 	}
+	zstdTarLayer, err := LayerFromOpener(ucOpener, WithCompression(compression.ZStd))
+	if err != nil {
+		t.Fatalf("Unable to create layer from tar file: %v", err)
+	}
 
-	if err := compare.Layers(tarLayer, tarZstdLayer); err != nil {
+	if err := compare.Layers(zstdTarLayer, tarZstdLayer); err != nil {
 		t.Errorf("compare.Layers: %v", err)
 	}
 }
@@ -326,8 +339,12 @@ func TestLayerFromReader(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Unable to create layer from tar file: %v", err)
 	}
+	zstdTarLayer, err := LayerFromReader(bytes.NewReader(ucBytes), WithCompression(compression.ZStd))
+	if err != nil {
+		t.Fatalf("Unable to create layer from tar file: %v", err)
+	}
 
-	if err := compare.Layers(tarLayer, tarZstdLayer); err != nil {
+	if err := compare.Layers(zstdTarLayer, tarZstdLayer); err != nil {
 		t.Errorf("compare.Layers: %v", err)
 	}
 }
@@ -335,9 +352,10 @@ func TestLayerFromReader(t *testing.T) {
 // Compression settings matter in order for the digest, size,
 // compressed assertions to pass
 //
-// Since our gzip.GzipReadCloser uses gzip.BestSpeed
-// we need our fixture to use the same - bazel's pkg_tar doesn't
-// seem to let you control compression settings
+// Since our gzip.GzipReadCloser uses gzip.BestSpeed, and our
+// zstd.ReadCloser uses zstd level 1, we need our fixtures to use the
+// same - bazel's pkg_tar doesn't seem to let you control compression
+// settings
 func setupFixtures(t *testing.T) {
 	t.Helper()
 
@@ -361,10 +379,15 @@ func setupCompressedTar(t *testing.T, fileName string) {
 
 	defer out.Close()
 
-	gw, _ := gzip.NewWriterLevel(out, gzip.BestSpeed)
-	defer gw.Close()
+	var cr io.ReadCloser
+	if strings.HasSuffix(fileName, ".zst") {
+		cr = zstd.ReadCloser(in)
+	} else {
+		cr = ggzip.ReadCloserLevel(in, gzip.BestSpeed)
+	}
+	defer cr.Close()
 
-	_, err = io.Copy(gw, in)
+	_, err = io.Copy(out, cr)
 	if err != nil {
 		t.Errorf("Error setting up fixtures: %v", err)
 	}
@@ -379,3 +402,95 @@ func teardownFixtures(t *testing.T) {
 		t.Errorf("Error tearing down fixtures: %v", err)
 	}
 }
+
+func TestZstdMediaType(t *testing.T) {
+	setupFixtures(t)
+	defer teardownFixtures(t)
+
+	for _, tc := range []struct {
+		name string
+		path string
+		opts []LayerOption
+		want types.MediaType
+	}{{
+		name: "compressed with zstd",
+		path: "testdata/content.tar",
+		opts: []LayerOption{WithCompression(compression.ZStd)},
+		want: types.OCILayerZStd,
+	}, {
+		name: "already zstd",
+		path: "zstd_content.tar.zst",
+		want: types.OCILayerZStd,
+	}, {
+		name: "already zstd, asked for gzip",
+		path: "zstd_content.tar.zst",
+		opts: []LayerOption{WithCompression(compression.GZip)},
+		want: types.OCILayerZStd,
+	}, {
+		name: "already gzip, asked for zstd",
+		path: "gzip_content.tgz",
+		opts: []LayerOption{WithCompression(compression.ZStd)},
+		want: types.DockerLayer,
+	}, {
+		name: "explicit media type",
+		path: "testdata/content.tar",
+		opts: []LayerOption{WithMediaType(types.OCIUncompressedLayer), WithCompression(compression.ZStd)},
+		want: types.OCIUncompressedLayer,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			l, err := LayerFromFile(tc.path, tc.opts...)
+			if err != nil {
+				t.Fatalf("LayerFromFile: %v", err)
+			}
+			got, err := l.MediaType()
+			if err != nil {
+				t.Fatalf("MediaType: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+			if err := validate.Layer(l); err != nil {
+				t.Errorf("validate.Layer: %v", err)
+			}
+		})
+	}
+}
+
+func TestLayerUncompressedSize(t *testing.T) {
+	contents := bytes.Repeat([]byte("hello "), 1000)
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	if _, err := zw.Write(contents); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, b := range map[string][]byte{
+		"uncompressed": contents,
+		"gzip":         zipped.Bytes(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			opens := 0
+			l, err := LayerFromOpener(func() (io.ReadCloser, error) {
+				opens++
+				return io.NopCloser(bytes.NewReader(b)), nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			before := opens
+			size, err := partial.UncompressedSize(l)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := int64(len(contents)); size != want {
+				t.Errorf("UncompressedSize() = %d, want %d", size, want)
+			}
+			if opens != before {
+				t.Errorf("UncompressedSize() opened the layer %d times, want it remembered", opens-before)
+			}
+		})
+	}
+}
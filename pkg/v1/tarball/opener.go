@@ -0,0 +1,214 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tarball
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// OpenerAt opens a file for reading from offset onwards, e.g. with a ranged
+// read from an object store.
+type OpenerAt func(offset int64) (io.ReadCloser, error)
+
+// urlRetries is how many times URLOpener's readers resume failed reads.
+const urlRetries = 3
+
+// ResumableOpener returns an Opener for the file that open reads. Reading
+// the file doesn't have to start at the beginning: the Opener's readers
+// seek by calling open again at the new offset, so Image only fetches the
+// parts of the tarball it needs. If a read fails partway through, it is
+// resumed by calling open again where it failed, up to retries times in
+// all for each reader.
+func ResumableOpener(open OpenerAt, retries int) Opener {
+	return func() (io.ReadCloser, error) {
+		rc, err := open(0)
+		if err != nil {
+			return nil, err
+		}
+		return &resumableReader{open: open, retries: retries, rc: rc}, nil
+	}
+}
+
+// URLOpener returns an Opener for the file at the http or https URL u,
+// fetched with client, or http.DefaultClient if it is nil. See
+// ResumableOpener: reads are resumed, and seeks made, with Range requests.
+func URLOpener(u string, client *http.Client) Opener {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return ResumableOpener(func(offset int64) (io.ReadCloser, error) {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		if offset > 0 {
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case resp.StatusCode == http.StatusPartialContent:
+			return resp.Body, nil
+		case resp.StatusCode == http.StatusOK:
+			// The server ignored the Range, so skip to offset ourselves.
+			if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+				resp.Body.Close()
+				return nil, err
+			}
+			return resp.Body, nil
+		case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+			// offset is the end of the file.
+			resp.Body.Close()
+			return http.NoBody, nil
+		}
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: unexpected status %s", u, resp.Status)
+	}, urlRetries)
+}
+
+// ImageFromURL returns a v1.Image from a tarball at the http or https URL u,
+// e.g. a docker save tarball on a release page. The tarball is fetched as
+// the image is read, so reading the image more than once downloads the
+// tarball more than once; write it to disk first to avoid that.
+func ImageFromURL(u string, tag *name.Tag) (v1.Image, error) {
+	return Image(URLOpener(u, nil), tag)
+}
+
+// seekDiscard is how far ahead a resumableReader reads and discards,
+// rather than opening the file again, to seek.
+const seekDiscard = 64 * 1024
+
+// resumableReader reads the file that open reads. It supports random access
+// so that tarFiles can index the tarball, but is fastest read sequentially.
+type resumableReader struct {
+	open OpenerAt
+	// retries is how many more times reads may be resumed.
+	retries int
+
+	mu sync.Mutex
+	// rc reads the file from pos, and off is the offset of the next Read.
+	rc  io.ReadCloser
+	pos int64
+	off int64
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, err := r.read(p, r.off)
+	r.off += int64(n)
+	return n, err
+}
+
+func (r *resumableReader) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	read := 0
+	for read < len(p) {
+		n, err := r.read(p[read:], off+int64(read))
+		read += n
+		if err != nil {
+			return read, err
+		}
+	}
+	return read, nil
+}
+
+func (r *resumableReader) Seek(offset int64, whence int) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.off
+	default:
+		return 0, errors.New("tarball: seeking from the end is not supported")
+	}
+	if offset < 0 {
+		return 0, errors.New("tarball: negative position")
+	}
+	r.off = offset
+	return offset, nil
+}
+
+func (r *resumableReader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
+
+// read reads into p from off, resuming if reading fails.
+func (r *resumableReader) read(p []byte, off int64) (int, error) {
+	if err := r.seek(off); err != nil {
+		return 0, err
+	}
+	for {
+		n, err := r.rc.Read(p)
+		r.pos += int64(n)
+		if err == nil || errors.Is(err, io.EOF) || r.retries <= 0 {
+			return n, err
+		}
+		r.retries--
+		if rerr := r.reopen(r.pos); rerr != nil {
+			return n, err
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// seek makes r.rc read from off.
+func (r *resumableReader) seek(off int64) error {
+	if r.rc != nil && off == r.pos {
+		return nil
+	}
+	if r.rc != nil && off > r.pos && off-r.pos <= seekDiscard {
+		n, err := io.CopyN(io.Discard, r.rc, off-r.pos)
+		r.pos += n
+		if err == nil {
+			return nil
+		}
+	}
+	return r.reopen(off)
+}
+
+// reopen opens the file again at off.
+func (r *resumableReader) reopen(off int64) error {
+	if r.rc != nil {
+		r.rc.Close()
+		r.rc = nil
+	}
+	rc, err := r.open(off)
+	if err != nil {
+		return err
+	}
+	r.rc, r.pos = rc, off
+	return nil
+}
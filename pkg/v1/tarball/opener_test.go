@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tarball
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+func TestImageFromURL(t *testing.T) {
+	const path = "testdata/test_image_1.tar"
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ImageFromPath(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantDigest, err := want.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		handler func(n int64) http.HandlerFunc
+	}{{
+		name: "ranges",
+		handler: func(int64) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				http.ServeContent(w, r, "image.tar", time.Time{}, bytes.NewReader(b))
+			}
+		},
+	}, {
+		name: "no ranges",
+		handler: func(int64) http.HandlerFunc {
+			return func(w http.ResponseWriter, _ *http.Request) {
+				w.Write(b)
+			}
+		},
+	}, {
+		// Every other response is cut off halfway through.
+		name: "flaky",
+		handler: func(n int64) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				if n%2 == 1 {
+					http.ServeContent(w, r, "image.tar", time.Time{}, bytes.NewReader(b))
+					return
+				}
+				var start int64
+				fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+				rest := b[start:]
+				w.Header().Set("Content-Length", fmt.Sprint(len(rest)))
+				if start > 0 {
+					w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(b)-1, len(b)))
+					w.WriteHeader(http.StatusPartialContent)
+				}
+				w.Write(rest[:len(rest)/2])
+			}
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests atomic.Int64
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tc.handler(requests.Add(1))(w, r)
+			}))
+			defer s.Close()
+
+			img, err := ImageFromURL(s.URL+"/image.tar", nil)
+			if err != nil {
+				t.Fatalf("ImageFromURL() = %v", err)
+			}
+			if err := validate.Image(img); err != nil {
+				t.Errorf("validate.Image() = %v", err)
+			}
+			if got, err := img.Digest(); err != nil || got != wantDigest {
+				t.Errorf("Digest() = %v, %v; want %v", got, err, wantDigest)
+			}
+		})
+	}
+
+	s := httptest.NewServer(http.NotFoundHandler())
+	defer s.Close()
+	if _, err := ImageFromURL(s.URL+"/missing.tar", nil); err == nil {
+		t.Error("ImageFromURL(missing) succeeded")
+	}
+}
+
+// failingReader reads one byte of the file, from off, then fails.
+type failingReader struct {
+	off  int64
+	done bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.done || len(p) == 0 {
+		return 0, errors.New("connection reset")
+	}
+	r.done = true
+	p[0] = byte(r.off)
+	return 1, nil
+}
+
+func TestResumableOpenerRetries(t *testing.T) {
+	opens := 0
+	opener := ResumableOpener(func(off int64) (io.ReadCloser, error) {
+		opens++
+		return io.NopCloser(&failingReader{off: off}), nil
+	}, 2)
+	rc, err := opener()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	// Each Read succeeds after resuming, but the retries are shared between
+	// them, so the reader gives up once they are used up.
+	b, err := io.ReadAll(rc)
+	if err == nil {
+		t.Fatal("ReadAll() succeeded, want an error")
+	}
+	if want := []byte{0, 1, 2}; !bytes.Equal(b, want) {
+		t.Errorf("ReadAll() = %v, want %v", b, want)
+	}
+	if opens != 3 {
+		t.Errorf("opened %d times, want 3", opens)
+	}
+}
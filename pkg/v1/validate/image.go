@@ -41,6 +41,12 @@ func Image(img v1.Image, opt ...Option) error {
 		errs = append(errs, fmt.Sprintf("validating manifest: %v", err))
 	}
 
+	if o := makeOptions(opt...); o.strict {
+		if err := validateImageSchema(img); err != nil {
+			errs = append(errs, fmt.Sprintf("validating schema: %v", err))
+		}
+	}
+
 	if len(errs) != 0 {
 		return errors.New(strings.Join(errs, "\n\n"))
 	}
@@ -114,31 +120,19 @@ func validateLayers(img v1.Image, opt ...Option) error {
 		return layersExist(layers)
 	}
 
-	digests := []v1.Hash{}
-	diffids := []v1.Hash{}
-	udiffids := []v1.Hash{}
-	sizes := []int64{}
+	if o.streaming {
+		return streamLayers(img, layers, o)
+	}
+
+	computed := []*computedLayer{}
 	for i, layer := range layers {
 		cl, err := computeLayer(layer)
-		if errors.Is(err, io.ErrUnexpectedEOF) {
-			// Errored while reading tar content of layer because a header or
-			// content section was not the correct length. This is most likely
-			// due to an incomplete download or otherwise interrupted process.
-			m, err := img.Manifest()
-			if err != nil {
-				return fmt.Errorf("undersized layer[%d] content", i)
-			}
-			return fmt.Errorf("undersized layer[%d] content: Manifest.Layers[%d].Size=%d", i, i, m.Layers[i].Size)
-		}
 		if err != nil {
-			return err
+			return undersized(img, i, err)
 		}
 		// Compute all of these first before we call Config() and Manifest() to allow
 		// for lazy access e.g. for stream.Layer.
-		digests = append(digests, cl.digest)
-		diffids = append(diffids, cl.diffid)
-		udiffids = append(udiffids, cl.uncompressedDiffid)
-		sizes = append(sizes, cl.size)
+		computed = append(computed, cl)
 	}
 
 	cf, err := img.ConfigFile()
@@ -153,68 +147,130 @@ func validateLayers(img v1.Image, opt ...Option) error {
 
 	errs := []string{}
 	for i, layer := range layers {
-		digest, err := layer.Digest()
-		if err != nil {
-			return err
-		}
-		diffid, err := layer.DiffID()
+		lerrs, err := checkLayer(img, i, layer, computed[i], m, cf)
 		if err != nil {
 			return err
 		}
-		size, err := layer.Size()
+		o.report(i, computed[i], lerrs)
+		errs = append(errs, lerrs...)
+	}
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+
+	return nil
+}
+
+// streamLayers validates layers one at a time, reading each of them once.
+func streamLayers(img v1.Image, layers []v1.Layer, o options) error {
+	cf, err := img.ConfigFile()
+	if err != nil {
+		return err
+	}
+
+	m, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+
+	errs := []string{}
+	for i, layer := range layers {
+		cl, err := streamLayer(layer)
 		if err != nil {
+			err = undersized(img, i, err)
+			o.report(i, nil, []string{err.Error()})
 			return err
 		}
-		mediaType, err := layer.MediaType()
+		lerrs, err := checkLayer(img, i, layer, cl, m, cf)
 		if err != nil {
 			return err
 		}
+		o.report(i, cl, lerrs)
+		errs = append(errs, lerrs...)
+	}
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
 
-		if _, err := img.LayerByDigest(digest); err != nil {
-			return err
-		}
+	return nil
+}
 
-		if _, err := img.LayerByDiffID(diffid); err != nil {
-			return err
-		}
+// undersized explains io.ErrUnexpectedEOF errors from reading layer i.
+func undersized(img v1.Image, i int, err error) error {
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	// Errored while reading tar content of layer because a header or
+	// content section was not the correct length. This is most likely
+	// due to an incomplete download or otherwise interrupted process.
+	m, err := img.Manifest()
+	if err != nil || i >= len(m.Layers) {
+		return fmt.Errorf("undersized layer[%d] content", i)
+	}
+	return fmt.Errorf("undersized layer[%d] content: Manifest.Layers[%d].Size=%d", i, i, m.Layers[i].Size)
+}
 
-		if digest != digests[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] digest: Digest()=%s, SHA256(Compressed())=%s", i, digest, digests[i]))
-		}
+// checkLayer compares what layer i of img claims about itself with cl, what
+// was computed from its contents.
+func checkLayer(img v1.Image, i int, layer v1.Layer, cl *computedLayer, m *v1.Manifest, cf *v1.ConfigFile) ([]string, error) {
+	digest, err := layer.Digest()
+	if err != nil {
+		return nil, err
+	}
+	diffid, err := layer.DiffID()
+	if err != nil {
+		return nil, err
+	}
+	size, err := layer.Size()
+	if err != nil {
+		return nil, err
+	}
+	mediaType, err := layer.MediaType()
+	if err != nil {
+		return nil, err
+	}
 
-		if m.Layers[i].Digest != digests[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] digest: Manifest.Layers[%d].Digest=%s, SHA256(Compressed())=%s", i, i, m.Layers[i].Digest, digests[i]))
-		}
+	if _, err := img.LayerByDigest(digest); err != nil {
+		return nil, err
+	}
 
-		if diffid != diffids[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: DiffID()=%s, SHA256(Gunzip(Compressed()))=%s", i, diffid, diffids[i]))
-		}
+	if _, err := img.LayerByDiffID(diffid); err != nil {
+		return nil, err
+	}
 
-		if diffid != udiffids[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: DiffID()=%s, SHA256(Uncompressed())=%s", i, diffid, udiffids[i]))
-		}
+	errs := []string{}
+	if digest != cl.digest {
+		errs = append(errs, fmt.Sprintf("mismatched layer[%d] digest: Digest()=%s, SHA256(Compressed())=%s", i, digest, cl.digest))
+	}
 
-		if cf.RootFS.DiffIDs[i] != diffids[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: ConfigFile.RootFS.DiffIDs[%d]=%s, SHA256(Gunzip(Compressed()))=%s", i, i, cf.RootFS.DiffIDs[i], diffids[i]))
-		}
+	if m.Layers[i].Digest != cl.digest {
+		errs = append(errs, fmt.Sprintf("mismatched layer[%d] digest: Manifest.Layers[%d].Digest=%s, SHA256(Compressed())=%s", i, i, m.Layers[i].Digest, cl.digest))
+	}
 
-		if size != sizes[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] size: Size()=%d, len(Compressed())=%d", i, size, sizes[i]))
-		}
+	if diffid != cl.diffid {
+		errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: DiffID()=%s, SHA256(Gunzip(Compressed()))=%s", i, diffid, cl.diffid))
+	}
 
-		if m.Layers[i].Size != sizes[i] {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] size: Manifest.Layers[%d].Size=%d, len(Compressed())=%d", i, i, m.Layers[i].Size, sizes[i]))
-		}
+	if !cl.streamed && diffid != cl.uncompressedDiffid {
+		errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: DiffID()=%s, SHA256(Uncompressed())=%s", i, diffid, cl.uncompressedDiffid))
+	}
 
-		if m.Layers[i].MediaType != mediaType {
-			errs = append(errs, fmt.Sprintf("mismatched layer[%d] mediaType: Manifest.Layers[%d].MediaType=%s, layer.MediaType()=%s", i, i, m.Layers[i].MediaType, mediaType))
-		}
+	if cf.RootFS.DiffIDs[i] != cl.diffid {
+		errs = append(errs, fmt.Sprintf("mismatched layer[%d] diffid: ConfigFile.RootFS.DiffIDs[%d]=%s, SHA256(Gunzip(Compressed()))=%s", i, i, cf.RootFS.DiffIDs[i], cl.diffid))
 	}
-	if len(errs) != 0 {
-		return errors.New(strings.Join(errs, "\n"))
+
+	if size != cl.size {
+		errs = append(errs, fmt.Sprintf("mismatched layer[%d] size: Size()=%d, len(Compressed())=%d", i, size, cl.size))
 	}
 
-	return nil
+	if m.Layers[i].Size != cl.size {
+		errs = append(errs, fmt.Sprintf("mismatched layer[%d] size: Manifest.Layers[%d].Size=%d, len(Compressed())=%d", i, i, m.Layers[i].Size, cl.size))
+	}
+
+	if m.Layers[i].MediaType != mediaType {
+		errs = append(errs, fmt.Sprintf("mismatched layer[%d] mediaType: Manifest.Layers[%d].MediaType=%s, layer.MediaType()=%s", i, i, m.Layers[i].MediaType, mediaType))
+	}
+	return errs, nil
 }
 
 func validateManifest(img v1.Image) error {
@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate_test
+
+import (
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/validate"
+)
+
+// wrongSize is a layer that misreports its size.
+type wrongSize struct {
+	v1.Layer
+}
+
+func (l wrongSize) Size() (int64, error) {
+	n, err := l.Layer.Size()
+	return n + 1, err
+}
+
+func TestStreaming(t *testing.T) {
+	img, err := random.Image(1024, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opt := range [][]validate.Option{nil, {validate.Streaming}} {
+		results := []validate.LayerResult{}
+		opt := append(opt, validate.WithLayerResults(func(r validate.LayerResult) {
+			results = append(results, r)
+		}))
+		if err := validate.Image(img, opt...); err != nil {
+			t.Fatalf("Image: %v", err)
+		}
+		layers, err := img.Layers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != len(layers) {
+			t.Fatalf("got %d results, want %d", len(results), len(layers))
+		}
+		for i, r := range results {
+			want, err := layers[i].Digest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Index != i || r.Digest != want || r.Err != nil {
+				t.Errorf("result %d: got %+v, want digest %s", i, r, want)
+			}
+		}
+	}
+
+	layer, err := random.Layer(1024, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad, err := mutate.AppendLayers(empty.Image, wrongSize{layer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []validate.LayerResult
+	err = validate.Image(bad, validate.Streaming, validate.WithLayerResults(func(r validate.LayerResult) {
+		got = append(got, r)
+	}))
+	if err == nil {
+		t.Fatal("Image: expected error for layer with the wrong size")
+	}
+	if len(got) != 1 || got[0].Err == nil {
+		t.Errorf("results: got %+v, want one error", got)
+	}
+}
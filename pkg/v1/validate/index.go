@@ -38,6 +38,12 @@ func Index(idx v1.ImageIndex, opt ...Option) error {
 		errs = append(errs, fmt.Sprintf("validating index manifest: %v", err))
 	}
 
+	if o := makeOptions(opt...); o.strict {
+		if err := validateIndexSchema(idx); err != nil {
+			errs = append(errs, fmt.Sprintf("validating schema: %v", err))
+		}
+	}
+
 	if len(errs) != 0 {
 		return errors.New(strings.Join(errs, "\n\n"))
 	}
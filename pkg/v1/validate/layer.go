@@ -24,6 +24,9 @@ import (
 	"io"
 	"strings"
 
+	"github.com/google/go-containerregistry/internal/compression"
+	"github.com/google/go-containerregistry/internal/zstd"
+	comp "github.com/google/go-containerregistry/pkg/compression"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/partial"
 )
@@ -95,6 +98,10 @@ type computedLayer struct {
 	// Calculated from Uncompressed stream.
 	uncompressedDiffid v1.Hash
 	uncompressedSize   int64
+
+	// streamed is true if only the Compressed stream was read, so the
+	// Uncompressed fields are unset.
+	streamed bool
 }
 
 func computeLayer(layer v1.Layer) (*computedLayer, error) {
@@ -127,8 +134,8 @@ func computeLayer(layer v1.Layer) (*computedLayer, error) {
 		pw.CloseWithError(compressed.Close())
 	}()
 
-	// Read the bytes through gzip.Reader to compute the DiffID.
-	uncompressed, err := gzip.NewReader(pr)
+	// Read the bytes through a decompressor to compute the DiffID.
+	uncompressed, err := decompress(pr)
 	if err != nil {
 		return nil, err
 	}
@@ -189,3 +196,66 @@ func computeLayer(layer v1.Layer) (*computedLayer, error) {
 		uncompressedSize:   usize,
 	}, nil
 }
+
+// streamLayer is like computeLayer, but reads only the Compressed stream, and
+// only once, without checking the tar contents.
+func streamLayer(layer v1.Layer) (*computedLayer, error) {
+	compressed, err := layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	defer compressed.Close()
+
+	digester := crypto.SHA256.New()
+	cr := &countingReader{r: io.TeeReader(compressed, digester)}
+
+	uncompressed, err := decompress(cr)
+	if err != nil {
+		return nil, err
+	}
+	diffid, _, err := v1.SHA256(uncompressed)
+	if err != nil {
+		return nil, err
+	}
+	// Include anything after the compressed stream in the digest and size.
+	if _, err := io.Copy(io.Discard, cr); err != nil {
+		return nil, err
+	}
+	if err := compressed.Close(); err != nil {
+		return nil, err
+	}
+
+	return &computedLayer{
+		digest: v1.Hash{
+			Algorithm: "sha256",
+			Hex:       hex.EncodeToString(digester.Sum(make([]byte, 0, digester.Size()))),
+		},
+		diffid:   diffid,
+		size:     cr.n,
+		streamed: true,
+	}, nil
+}
+
+// decompress returns a reader of the decompressed contents of r, which may
+// be compressed with gzip or zstd.
+func decompress(r io.Reader) (io.ReadCloser, error) {
+	cp, pr, err := compression.PeekCompression(r)
+	if err != nil {
+		return nil, err
+	}
+	if cp == comp.ZStd {
+		return zstd.UnzipReadCloser(io.NopCloser(pr))
+	}
+	return gzip.NewReader(pr)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
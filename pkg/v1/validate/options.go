@@ -14,11 +14,21 @@
 
 package validate
 
+import (
+	"errors"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
 // Option is a functional option for validate.
 type Option func(*options)
 
 type options struct {
-	fast bool
+	fast      bool
+	strict    bool
+	streaming bool
+	progress  func(LayerResult)
 }
 
 func makeOptions(opts ...Option) options {
@@ -35,3 +45,64 @@ func makeOptions(opts ...Option) options {
 func Fast(o *options) {
 	o.fast = true
 }
+
+// Strict causes validate to also check manifests, indexes, and config files
+// against the OCI image-spec JSON schemas, reporting type errors, invalid
+// values, and fields that the schemas don't define.
+//
+// Only OCI media types are checked; there are no schemas for Docker media
+// types.
+func Strict(o *options) {
+	o.strict = true
+}
+
+// Streaming causes validate to read each layer only once, as a stream, using
+// a bounded amount of memory. This is much faster for large images (especially
+// remote ones), but unlike the default, it doesn't compare Uncompressed() with
+// Compressed() or check the layers' tar contents.
+//
+// Since the manifest and config file are read first, this doesn't work for
+// images whose layers must be read before they can be described, e.g. those
+// containing a stream.Layer.
+func Streaming(o *options) {
+	o.streaming = true
+}
+
+// LayerResult is the result of validating one layer of an image.
+type LayerResult struct {
+	// Index is the position of the layer in the manifest.
+	Index int
+
+	// Digest, DiffID and Size were computed from the layer's contents. They
+	// are zero if the layer couldn't be read.
+	Digest v1.Hash
+	DiffID v1.Hash
+	Size   int64
+
+	// Err describes how the layer is invalid, or is nil if it is valid.
+	Err error
+}
+
+// WithLayerResults calls f with the result of validating each layer of an
+// image, which can be used to show progress. With Streaming, f is called as
+// soon as each layer has been read; otherwise, after all of them have been.
+func WithLayerResults(f func(LayerResult)) Option {
+	return func(o *options) {
+		o.progress = f
+	}
+}
+
+// report calls the progress callback, if any, for layer i.
+func (o options) report(i int, cl *computedLayer, errs []string) {
+	if o.progress == nil {
+		return
+	}
+	r := LayerResult{Index: i}
+	if cl != nil {
+		r.Digest, r.DiffID, r.Size = cl.digest, cl.diffid, cl.size
+	}
+	if len(errs) != 0 {
+		r.Err = errors.New(strings.Join(errs, "\n"))
+	}
+	o.progress(r)
+}
@@ -0,0 +1,413 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+//go:embed schema/*.json
+var schemaFS embed.FS
+
+// schemas maps media types to the schema file that describes them.
+//
+// Docker media types are deliberately absent: they have no official schemas,
+// and Docker config files have many fields that the OCI schema doesn't know.
+var schemas = map[types.MediaType]string{
+	types.OCIManifestSchema1: "image-manifest-schema.json",
+	types.OCIImageIndex:      "image-index-schema.json",
+	types.OCIConfigJSON:      "config-schema.json",
+}
+
+// validateSchema checks b against the OCI JSON schema for mt, if there is
+// one. Beyond what the schemas require, fields that the schemas don't define
+// are reported as errors.
+func validateSchema(mt types.MediaType, b []byte) error {
+	file, ok := schemas[mt]
+	if !ok {
+		return nil
+	}
+	root, err := loadSchema(file)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeJSON(b)
+	if err != nil {
+		return err
+	}
+
+	s := &schemaState{}
+	s.check(file, root, doc, "")
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s does not match schema:\n%s", mt, strings.Join(s.errs, "\n"))
+}
+
+func validateImageSchema(img v1.Image) error {
+	mt, err := img.MediaType()
+	if err != nil {
+		return err
+	}
+	rm, err := img.RawManifest()
+	if err != nil {
+		return err
+	}
+	m, err := img.Manifest()
+	if err != nil {
+		return err
+	}
+	rc, err := img.RawConfigFile()
+	if err != nil {
+		return err
+	}
+
+	errs := []string{}
+	if err := validateSchema(mt, rm); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := validateSchema(m.Config.MediaType, rc); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func validateIndexSchema(idx v1.ImageIndex) error {
+	mt, err := idx.MediaType()
+	if err != nil {
+		return err
+	}
+	rm, err := idx.RawManifest()
+	if err != nil {
+		return err
+	}
+	return validateSchema(mt, rm)
+}
+
+var (
+	schemaOnce  sync.Once
+	schemaDocs  map[string]any
+	schemaErr   error
+	patternLock sync.Mutex
+	patterns    = map[string]*regexp.Regexp{}
+)
+
+func loadSchema(file string) (any, error) {
+	schemaOnce.Do(func() {
+		entries, err := schemaFS.ReadDir("schema")
+		if err != nil {
+			schemaErr = err
+			return
+		}
+		schemaDocs = map[string]any{}
+		for _, e := range entries {
+			b, err := schemaFS.ReadFile("schema/" + e.Name())
+			if err != nil {
+				schemaErr = err
+				return
+			}
+			if schemaDocs[e.Name()], err = decodeJSON(b); err != nil {
+				schemaErr = fmt.Errorf("parsing schema %s: %w", e.Name(), err)
+				return
+			}
+		}
+	})
+	if schemaErr != nil {
+		return nil, schemaErr
+	}
+	s, ok := schemaDocs[file]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %q", file)
+	}
+	return s, nil
+}
+
+func decodeJSON(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func compilePattern(p string) (*regexp.Regexp, error) {
+	patternLock.Lock()
+	defer patternLock.Unlock()
+	if re, ok := patterns[p]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(p)
+	if err != nil {
+		return nil, err
+	}
+	patterns[p] = re
+	return re, nil
+}
+
+// schemaState accumulates errors while checking a document.
+//
+// It implements the subset of JSON Schema draft-04 used by the OCI schemas.
+type schemaState struct {
+	errs []string
+}
+
+func (s *schemaState) errorf(path, format string, args ...any) {
+	if path == "" {
+		path = "/"
+	}
+	s.errs = append(s.errs, path+": "+fmt.Sprintf(format, args...))
+}
+
+// resolve follows a $ref relative to file.
+func resolve(file, ref string) (string, any, error) {
+	target, pointer, _ := strings.Cut(ref, "#")
+	if target != "" {
+		file = target
+	}
+	node, err := loadSchema(file)
+	if err != nil {
+		return "", nil, err
+	}
+	if pointer == "" {
+		return file, node, nil
+	}
+	for _, tok := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return "", nil, fmt.Errorf("bad $ref %q", ref)
+		}
+		if node, ok = m[tok]; !ok {
+			return "", nil, fmt.Errorf("bad $ref %q", ref)
+		}
+	}
+	return file, node, nil
+}
+
+// check validates v, found at path, against schema, which was read from file.
+func (s *schemaState) check(file string, schema any, v any, path string) {
+	sm, ok := schema.(map[string]any)
+	if !ok {
+		return
+	}
+
+	if ref, ok := sm["$ref"].(string); ok {
+		rfile, rschema, err := resolve(file, ref)
+		if err != nil {
+			s.errorf(path, "%v", err)
+			return
+		}
+		s.check(rfile, rschema, v, path)
+		return
+	}
+
+	if t, ok := sm["type"]; ok && !s.checkType(t, v, path) {
+		// Nothing else is meaningful if the type is wrong.
+		return
+	}
+
+	if enum, ok := sm["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.errorf(path, "%v is not one of %v", v, enum)
+		}
+	}
+
+	if oneOf, ok := sm["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range oneOf {
+			ss := &schemaState{}
+			ss.check(file, sub, v, path)
+			if len(ss.errs) == 0 {
+				matched++
+			}
+		}
+		if matched != 1 {
+			s.errorf(path, "matches %d of the allowed schemas, want exactly 1", matched)
+		}
+	}
+
+	switch v := v.(type) {
+	case string:
+		s.checkString(sm, v, path)
+	case json.Number:
+		s.checkNumber(sm, v, path)
+	case []any:
+		if min, ok := sm["minItems"].(json.Number); ok {
+			if n, err := min.Int64(); err == nil && int64(len(v)) < n {
+				s.errorf(path, "has %d items, want at least %d", len(v), n)
+			}
+		}
+		if items, ok := sm["items"]; ok {
+			for i, item := range v {
+				s.check(file, items, item, path+"/"+strconv.Itoa(i))
+			}
+		}
+	case map[string]any:
+		s.checkObject(file, sm, v, path)
+	}
+}
+
+func (s *schemaState) checkType(t any, v any, path string) bool {
+	var want []string
+	switch t := t.(type) {
+	case string:
+		want = []string{t}
+	case []any:
+		for _, tt := range t {
+			if ts, ok := tt.(string); ok {
+				want = append(want, ts)
+			}
+		}
+	}
+	got := jsonType(v)
+	for _, w := range want {
+		if w == got || (w == "number" && got == "integer") {
+			return true
+		}
+	}
+	s.errorf(path, "has type %s, want %s", got, strings.Join(want, " or "))
+	return false
+}
+
+func jsonType(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if f, err := v.Float64(); err == nil && f == math.Trunc(f) && !strings.ContainsAny(v.String(), ".eE") {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func (s *schemaState) checkString(sm map[string]any, v, path string) {
+	if p, ok := sm["pattern"].(string); ok {
+		re, err := compilePattern(p)
+		if err != nil {
+			s.errorf(path, "bad pattern %q: %v", p, err)
+		} else if !re.MatchString(v) {
+			s.errorf(path, "%q does not match %q", v, p)
+		}
+	}
+	switch sm["format"] {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			s.errorf(path, "%q is not an RFC 3339 date-time", v)
+		}
+	case "uri":
+		if u, err := url.Parse(v); err != nil || !u.IsAbs() {
+			s.errorf(path, "%q is not an absolute URI", v)
+		}
+	}
+}
+
+func (s *schemaState) checkNumber(sm map[string]any, v json.Number, path string) {
+	f, err := v.Float64()
+	if err != nil {
+		s.errorf(path, "%v", err)
+		return
+	}
+	if min, ok := sm["minimum"].(json.Number); ok {
+		if m, err := min.Float64(); err == nil && f < m {
+			s.errorf(path, "%s is less than the minimum %s", v, min)
+		}
+	}
+	if max, ok := sm["maximum"].(json.Number); ok {
+		if m, err := max.Float64(); err == nil && f > m {
+			s.errorf(path, "%s is greater than the maximum %s", v, max)
+		}
+	}
+}
+
+func (s *schemaState) checkObject(file string, sm map[string]any, v map[string]any, path string) {
+	if required, ok := sm["required"].([]any); ok {
+		for _, r := range required {
+			if k, ok := r.(string); ok {
+				if _, ok := v[k]; !ok {
+					s.errorf(path, "missing required field %q", k)
+				}
+			}
+		}
+	}
+
+	props, hasProps := sm["properties"].(map[string]any)
+	patternProps, hasPatterns := sm["patternProperties"].(map[string]any)
+
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		child := path + "/" + strings.ReplaceAll(strings.ReplaceAll(k, "~", "~0"), "/", "~1")
+		matched := false
+		if sub, ok := props[k]; ok {
+			matched = true
+			s.check(file, sub, v[k], child)
+		}
+		for p, sub := range patternProps {
+			re, err := compilePattern(p)
+			if err != nil {
+				s.errorf(path, "bad pattern %q: %v", p, err)
+				continue
+			}
+			if re.MatchString(k) {
+				matched = true
+				s.check(file, sub, v[k], child)
+			}
+		}
+		if !matched && (hasProps || hasPatterns) {
+			s.errorf(child, "unknown field")
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validate
+
+import (
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+func TestValidateSchema(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		mt   types.MediaType
+		doc  string
+		want []string
+	}{{
+		name: "valid manifest",
+		mt:   types.OCIManifestSchema1,
+		doc:  `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","size":2,"digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"},"layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar+gzip","size":10,"digest":"sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a","annotations":{"a":"b"}}]}`,
+	}, {
+		name: "invalid manifest",
+		mt:   types.OCIManifestSchema1,
+		doc:  `{"schemaVersion":"2","config":{"mediaType":"nope","size":1.5,"digest":"sha256:x"},"layers":[{"mediaType":"a/b","digest":"sha256:abc","colour":"blue"}],"annotations":{"a":1}}`,
+		want: []string{
+			`/schemaVersion: has type string, want integer`,
+			`/config/mediaType: "nope" does not match`,
+			`/config/size: has type number, want integer`,
+			`/layers/0: missing required field "size"`,
+			`/layers/0/colour: unknown field`,
+			`/annotations/a: has type integer, want string`,
+		},
+	}, {
+		name: "invalid index",
+		mt:   types.OCIImageIndex,
+		doc:  `{"schemaVersion":3,"manifests":[{"mediaType":"a/b","size":1,"digest":"sha256:abc","platform":{"os":"linux"}}]}`,
+		want: []string{
+			`/schemaVersion: 3 is greater than the maximum 2`,
+			`/manifests/0/platform: missing required field "architecture"`,
+		},
+	}, {
+		name: "invalid config",
+		mt:   types.OCIConfigJSON,
+		doc:  `{"architecture":"amd64","os":"linux","created":"yesterday","rootfs":{"type":"tarballs","diff_ids":[]},"config":{"Cmd":"sh","Labels":null}}`,
+		want: []string{
+			`/created: "yesterday" is not an RFC 3339 date-time`,
+			`/rootfs/type: tarballs is not one of [layers]`,
+			`/config/Cmd: matches 0 of the allowed schemas`,
+		},
+	}, {
+		name: "docker media types are not checked",
+		mt:   types.DockerManifestSchema2,
+		doc:  `{"whatever":true}`,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSchema(tc.mt, []byte(tc.doc))
+			if len(tc.want) == 0 {
+				if err != nil {
+					t.Fatalf("validateSchema: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("validateSchema: expected error")
+			}
+			for _, want := range tc.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error does not contain %q:\n%v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStrict(t *testing.T) {
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img = mutate.ConfigMediaType(mutate.MediaType(img, types.OCIManifestSchema1), types.OCIConfigJSON)
+	if err := Image(img, Fast, Strict); err != nil {
+		t.Errorf("Image: %v", err)
+	}
+
+	idx := mutate.AppendManifests(mutate.IndexMediaType(empty.Index, types.OCIImageIndex), mutate.IndexAddendum{Add: img})
+	if err := Index(idx, Fast, Strict); err != nil {
+		t.Errorf("Index: %v", err)
+	}
+
+	bad := mutate.Annotations(img, map[string]string{"": "empty keys are not allowed"}).(v1.Image)
+	if err := Image(bad, Fast, Strict); err == nil {
+		t.Error("Image: expected error for invalid annotations")
+	}
+}
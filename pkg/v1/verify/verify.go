@@ -0,0 +1,193 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package verify checks that blobs read from anywhere, e.g. object storage
+// or a cache, have the digest and size they should, as the remote package
+// does for blobs it pulls from registries.
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sync"
+
+	"github.com/google/go-containerregistry/internal/verify"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// SizeUnknown is the size to pass to ReadCloser when it isn't known ahead of
+// time.
+const SizeUnknown = verify.SizeUnknown
+
+// Error is returned at the end of a blob whose digest doesn't match. Errors
+// from the remote package match it with errors.As too.
+type Error = verify.Error
+
+// SizeError is returned at the end of a blob whose size doesn't match.
+type SizeError = verify.SizeError
+
+// ErrSizeLimit is returned by readers that read more than the limit set by
+// WithMaxSize.
+var ErrSizeLimit = errors.New("blob exceeds size limit")
+
+// Option is a functional option for ReadCloser.
+type Option func(*options)
+
+type options struct {
+	hasher   func() hash.Hash
+	maxSize  int64
+	progress func(v1.Update)
+	ctx      context.Context
+}
+
+// WithHasher hashes the blob with newHash, for digests whose algorithm
+// v1.Hasher doesn't support, e.g. "sha512" with crypto.SHA512.New.
+func WithHasher(newHash func() hash.Hash) Option {
+	return func(o *options) {
+		o.hasher = newHash
+	}
+}
+
+// WithMaxSize fails reads of more than n bytes with ErrSizeLimit, to bound
+// what is read from blobs whose size isn't known. Blobs whose size is known
+// to be larger fail up front.
+func WithMaxSize(n int64) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
+
+// WithProgress calls f after every read with the number of bytes read so far
+// and, if the blob's size is known, its size. The last call has the error, if
+// the blob fails verification.
+func WithProgress(f func(v1.Update)) Option {
+	return func(o *options) {
+		o.progress = f
+	}
+}
+
+// WithContext stops reading when ctx is done: the underlying reader is
+// closed, to interrupt reads that are blocked, and reads return ctx.Err().
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// ReadCloser wraps rc to verify that its contents have the digest h and,
+// unless it is SizeUnknown, size. At most size bytes are read from rc.
+//
+// The error is returned by the Read that would otherwise return io.EOF, so
+// callers must read to the end, and not use the contents until then.
+func ReadCloser(rc io.ReadCloser, size int64, h v1.Hash, opts ...Option) (io.ReadCloser, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var w hash.Hash
+	if o.hasher != nil {
+		w = o.hasher()
+	} else {
+		var err error
+		if w, err = v1.Hasher(h.Algorithm); err != nil {
+			return nil, err
+		}
+	}
+	if o.maxSize > 0 && size > o.maxSize {
+		return nil, fmt.Errorf("%s is %d bytes, more than %d: %w", h, size, o.maxSize, ErrSizeLimit)
+	}
+
+	r := &reader{rc: rc, size: size, max: o.maxSize, progress: o.progress, ctx: o.ctx}
+	if o.ctx != nil {
+		r.stop = context.AfterFunc(o.ctx, func() { r.closeInner() })
+	}
+	r.verified = verify.HashReadCloser(io.NopCloser(readFunc(r.readInner)), size, h, w)
+	return r, nil
+}
+
+type readFunc func([]byte) (int, error)
+
+func (f readFunc) Read(p []byte) (int, error) { return f(p) }
+
+// reader adds the options to a reader from the internal verify package.
+type reader struct {
+	rc       io.ReadCloser
+	verified io.ReadCloser
+	size     int64
+	max      int64
+	progress func(v1.Update)
+	ctx      context.Context
+	stop     func() bool
+
+	read      int64 // by readInner, from rc
+	complete  int64 // by Read, to the caller
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// readInner reads from rc, enforcing the size limit and the context.
+func (r *reader) readInner(p []byte) (int, error) {
+	if r.ctx != nil && r.ctx.Err() != nil {
+		return 0, r.ctx.Err()
+	}
+	if r.max > 0 && int64(len(p)) > r.max-r.read+1 {
+		// Read at most one byte more than the limit, to find out if there is one.
+		p = p[:r.max-r.read+1]
+	}
+	n, err := r.rc.Read(p)
+	r.read += int64(n)
+	if r.max > 0 && r.read > r.max {
+		return n, fmt.Errorf("read more than %d bytes: %w", r.max, ErrSizeLimit)
+	}
+	if err != nil && r.ctx != nil && r.ctx.Err() != nil {
+		err = r.ctx.Err()
+	}
+	return n, err
+}
+
+// Read implements io.Reader.
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.verified.Read(p)
+	r.complete += int64(n)
+	if r.progress != nil {
+		u := v1.Update{Complete: r.complete}
+		if r.size != SizeUnknown {
+			u.Total = r.size
+		}
+		if !errors.Is(err, io.EOF) {
+			u.Error = err
+		}
+		r.progress(u)
+	}
+	return n, err
+}
+
+func (r *reader) closeInner() {
+	r.closeOnce.Do(func() {
+		r.closeErr = r.rc.Close()
+	})
+}
+
+// Close implements io.Closer.
+func (r *reader) Close() error {
+	if r.stop != nil {
+		r.stop()
+	}
+	r.closeInner()
+	return r.closeErr
+}
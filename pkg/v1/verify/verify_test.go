@@ -0,0 +1,132 @@
+// Copyright 2026 Google LLC All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package verify_test
+
+import (
+	"context"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/verify"
+)
+
+const blob = "hello, world"
+
+func digest(t *testing.T, s string) v1.Hash {
+	t.Helper()
+	h, _, err := v1.SHA256(strings.NewReader(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func read(t *testing.T, s string, size int64, h v1.Hash, opts ...verify.Option) error {
+	t.Helper()
+	rc, err := verify.ReadCloser(io.NopCloser(strings.NewReader(s)), size, h, opts...)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	_, err = io.Copy(io.Discard, rc)
+	return err
+}
+
+func TestReadCloser(t *testing.T) {
+	h := digest(t, blob)
+	if err := read(t, blob, int64(len(blob)), h); err != nil {
+		t.Errorf("known size: %v", err)
+	}
+	if err := read(t, blob, verify.SizeUnknown, h); err != nil {
+		t.Errorf("unknown size: %v", err)
+	}
+
+	var verr verify.Error
+	if err := read(t, "goodbye", verify.SizeUnknown, h); !errors.As(err, &verr) {
+		t.Errorf("wrong content: got %v, want a verify.Error", err)
+	}
+	var serr verify.SizeError
+	if err := read(t, blob, int64(len(blob))+1, h); !errors.As(err, &serr) {
+		t.Errorf("wrong size: got %v, want a verify.SizeError", err)
+	}
+}
+
+func TestWithHasher(t *testing.T) {
+	sum := sha512.Sum512([]byte(blob))
+	h := v1.Hash{Algorithm: "sha512", Hex: hex.EncodeToString(sum[:])}
+	if err := read(t, blob, verify.SizeUnknown, h); err == nil {
+		t.Error("sha512 without WithHasher: got nil, want an error")
+	}
+	if err := read(t, blob, verify.SizeUnknown, h, verify.WithHasher(sha512.New)); err != nil {
+		t.Errorf("sha512 WithHasher: %v", err)
+	}
+}
+
+func TestWithMaxSize(t *testing.T) {
+	h := digest(t, blob)
+	if err := read(t, blob, verify.SizeUnknown, h, verify.WithMaxSize(int64(len(blob)))); err != nil {
+		t.Errorf("at the limit: %v", err)
+	}
+	if err := read(t, blob, verify.SizeUnknown, h, verify.WithMaxSize(5)); !errors.Is(err, verify.ErrSizeLimit) {
+		t.Errorf("unknown size over the limit: got %v, want ErrSizeLimit", err)
+	}
+	if err := read(t, blob, int64(len(blob)), h, verify.WithMaxSize(5)); !errors.Is(err, verify.ErrSizeLimit) {
+		t.Errorf("known size over the limit: got %v, want ErrSizeLimit", err)
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	var updates []v1.Update
+	progress := verify.WithProgress(func(u v1.Update) { updates = append(updates, u) })
+
+	if err := read(t, blob, int64(len(blob)), digest(t, blob), progress); err != nil {
+		t.Fatal(err)
+	}
+	last := updates[len(updates)-1]
+	if last.Complete != int64(len(blob)) || last.Total != int64(len(blob)) || last.Error != nil {
+		t.Errorf("last update = %+v, want all %d bytes and no error", last, len(blob))
+	}
+
+	updates = nil
+	if err := read(t, "goodbye", verify.SizeUnknown, digest(t, blob), progress); err == nil {
+		t.Fatal("wrong content: got nil, want an error")
+	}
+	if last := updates[len(updates)-1]; last.Error == nil {
+		t.Errorf("last update = %+v, want the error", last)
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	// Nothing is ever written, so reads block until the pipe is closed.
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	rc, err := verify.ReadCloser(pr, verify.SizeUnknown, digest(t, blob), verify.WithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	if _, err := io.Copy(io.Discard, rc); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}